@@ -45,7 +45,11 @@ func main() {
 		categories      = flag.String("categories", "all", "Test categories (comma-separated or 'all')")
 		verbose         = flag.Bool("verbose", false, "Enable verbose output")
 		report          = flag.String("report", "", "Report file path (json/html/md/txt)")
+		reportURL       = flag.String("report-url", "", "Upload the report to this URL via HTTP PUT instead of writing it locally")
 		stopOnFirstFail = flag.Bool("stop-on-fail", false, "Stop on first failure")
+		replay          = flag.String("replay", "", "Replay a recorded defect by id (seed:iteration)")
+		resultBuffer    = flag.Int("result-buffer", 0, "Finished results that may queue before workers block (0 = 2 per worker)")
+		maxResults      = flag.Int("max-results", 0, "Maximum results kept in the report, failures first (0 = all)")
 	)
 
 	flag.Usage = func() {
@@ -67,6 +71,7 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  %s -iterations=1000 -parallel=10\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -categories=rfc9421,crypto -report=report.html\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -iterations=100 -stop-on-fail -verbose\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -categories=session,hpke -replay=1393:17\n", os.Args[0])
 	}
 
 	flag.Parse()
@@ -106,6 +111,19 @@ func main() {
 		VerboseMode:     *verbose,
 		ReportPath:      reportPath,
 		StopOnFirstFail: *stopOnFirstFail,
+		ResultBuffer:    *resultBuffer,
+		MaxResults:      *maxResults,
+	}
+	if *replay != "" {
+		id, err := random.ParseDefectID(*replay)
+		if err != nil {
+			log.Fatalf("Invalid -replay value: %v", err)
+		}
+		config.Seed = id.Seed
+		config.ReplayDefect = &id
+	}
+	if *reportURL != "" {
+		config.ReportSink = random.HTTPSink{URL: *reportURL}
 	}
 
 	// Print configuration
@@ -119,6 +137,9 @@ func main() {
 	fmt.Printf("Report Path:      %s\n", config.ReportPath)
 	fmt.Printf("Verbose Mode:     %v\n", config.VerboseMode)
 	fmt.Printf("Stop on Fail:     %v\n", config.StopOnFirstFail)
+	if config.MaxResults > 0 {
+		fmt.Printf("Max Results:      %d\n", config.MaxResults)
+	}
 	fmt.Println()
 
 	// Create fuzzer
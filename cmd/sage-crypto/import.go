@@ -0,0 +1,97 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keystore"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importDir string
+	importID  string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import legacy key files into storage",
+	Long: `Import the legacy per-file key layout into key storage.
+
+The directory may contain any of:
+  - ecdsa.key:   ECDSA private key (PEM)
+  - ed25519.key: Ed25519 private key (raw)
+  - x25519.key:  X25519 private key (raw)
+
+Every key found is validated and stored as <id>.<type>, e.g. my-agent.ed25519.
+Missing files are skipped; an existing key with the same ID aborts the import.`,
+	Example: `  # Import ./keys into storage under the ID my-agent
+  sage-crypto import --dir ./keys --id my-agent --storage-dir ./keystore`,
+	RunE: runImport,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVarP(&importDir, "dir", "d", "", "Legacy key directory (required)")
+	importCmd.Flags().StringVar(&importID, "id", "", "Keystore ID for the imported keys (required)")
+	importCmd.Flags().StringVarP(&storageDir, "storage-dir", "s", "", "Storage directory (required)")
+
+	if err := importCmd.MarkFlagRequired("dir"); err != nil {
+		panic(fmt.Sprintf("failed to mark flag required: %v", err))
+	}
+	if err := importCmd.MarkFlagRequired("id"); err != nil {
+		panic(fmt.Sprintf("failed to mark flag required: %v", err))
+	}
+	if err := importCmd.MarkFlagRequired("storage-dir"); err != nil {
+		panic(fmt.Sprintf("failed to mark flag required: %v", err))
+	}
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	imported, err := keystore.ImportLegacyDir(importDir)
+	if err != nil {
+		return fmt.Errorf("failed to import legacy keys: %w", err)
+	}
+
+	keyStorage, err := storage.NewFileKeyStorage(storageDir)
+	if err != nil {
+		return fmt.Errorf("failed to create key storage: %w", err)
+	}
+
+	ids, err := imported.Store(keyStorage, importID)
+	if err != nil {
+		return fmt.Errorf("failed to store keys: %w", err)
+	}
+
+	fmt.Printf("Imported %d key(s) into %s:\n", len(ids), storageDir)
+	for i, id := range ids {
+		kp := imported.Keys[i]
+		fmt.Printf("  %-12s -> %s (%s, fingerprint %s)\n", imported.Files[i], id, kp.Type(), kp.ID())
+	}
+	for _, name := range imported.Missing {
+		fmt.Printf("  %-12s    not found, skipped\n", name)
+	}
+	fmt.Printf("Storage location: %s\n", filepath.Join(storageDir, importID+".*.key"))
+
+	return nil
+}
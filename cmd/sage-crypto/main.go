@@ -61,4 +61,5 @@ func init() {
 	// - list.go: listCmd
 	// - rotate.go: rotateCmd
 	// - address.go: addressCmd
+	// - import.go: importCmd
 }
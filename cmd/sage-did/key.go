@@ -74,6 +74,7 @@ var (
 	keyPrivateKey   string
 	keyType         string
 	keyOutputFormat string
+	keyAllowLegacy  bool
 )
 
 // Key add command
@@ -185,6 +186,10 @@ VERIFICATION CHECKS:
   - Signatures match the registered public keys
   - X25519 keys are skipped (key agreement only)
 
+Ed25519 proofs must be bound to the registration signing context. Keys
+registered with an older release carry plain Ed25519 proofs; pass
+--allow-legacy to accept those until the keys are re-registered.
+
 EXAMPLES:
   # Verify all keys for an agent
   sage-did key verify-pop did:sage:ethereum:0x1234567890abcdef \
@@ -229,6 +234,9 @@ func init() {
 		panic(fmt.Sprintf("failed to mark flag required: %v", err))
 	}
 
+	// Verify-pop command specific flags
+	keyVerifyPopCmd.Flags().BoolVar(&keyAllowLegacy, "allow-legacy", false, "Also accept plain Ed25519 proofs from before registration-context signing")
+
 	// Approve command specific flags
 	keyApproveCmd.Flags().StringVar(&keyPrivateKey, "private-key", "", "Registry owner private key")
 	if err := keyApproveCmd.MarkFlagRequired("private-key"); err != nil {
@@ -607,7 +615,9 @@ func runKeyVerifyPop(cmd *cobra.Command, args []string) error {
 		}
 
 		// Verify PoP
-		err := did.VerifyKeyProofOfPossession(metadataV4.DID, &metadataV4.Keys[i])
+		err := did.VerifyKeyProofOfPossessionWithOptions(metadataV4.DID, &metadataV4.Keys[i], did.PoPVerifyOptions{
+			AllowLegacyEd25519: keyAllowLegacy,
+		})
 		if err != nil {
 			failedKeys++
 			errMsg := fmt.Sprintf("PoP verification failed: %v", err)
@@ -645,6 +655,9 @@ func runKeyVerifyPop(cmd *cobra.Command, args []string) error {
 		}
 		fmt.Printf("\nℹ Note: Failed PoP verification may indicate:\n")
 		fmt.Printf("  - Keys registered without proper signature\n")
+		if !keyAllowLegacy {
+			fmt.Printf("  - Ed25519 keys registered before registration-context proofs (retry with --allow-legacy)\n")
+		}
 		fmt.Printf("  - Mismatched public/private key pairs\n")
 		fmt.Printf("  - Corrupted key data on-chain\n")
 		return fmt.Errorf("proof-of-possession verification failed for %d keys", failedKeys)
@@ -53,7 +53,7 @@ func TestGenerateAgentDIDWithAddress(t *testing.T) {
 			name:          "Solana chain",
 			chain:         did.ChainSolana,
 			ownerAddress:  "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK",
-			expectedDID:   "did:sage:solana:dyw8jctfwhnrjhhmfcbxvvdtqwmevfbx6zkumg5cnskk",
+			expectedDID:   "did:sage:solana:DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK",
 			expectContain: "solana",
 		},
 	}
@@ -70,8 +70,8 @@ func TestGenerateAgentDIDWithAddress(t *testing.T) {
 				t.Errorf("DID should contain %s, got %v", tt.expectContain, result)
 			}
 
-			// Verify lowercase
-			if strings.ToLower(string(result)) != string(result) {
+			// Ethereum DIDs are lowercased; Solana base58 is case-sensitive
+			if tt.chain == did.ChainEthereum && strings.ToLower(string(result)) != string(result) {
 				t.Errorf("DID should be lowercase, got %v", result)
 			}
 		})
@@ -0,0 +1,367 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	"github.com/spf13/cobra"
+)
+
+var revokeCmd = &cobra.Command{
+	Use:   "revoke <did>",
+	Short: "Revoke a compromised agent key and kill its sessions",
+	Long: `Incident response for a compromised agent key. In one step this command:
+
+  1. Revokes the key on-chain (the durable record)
+  2. Adds the key to a signed revocation list file, if --revocation-list is set
+  3. Closes the agent's live sessions on a running session manager, if
+     --session-endpoint is set
+
+The key is identified by its on-chain key hash (see 'sage-did key list').
+If any step fails, including the on-chain revocation, the remaining steps
+still run and all failures are reported together, so one unreachable service does not leave the key usable
+elsewhere.
+
+EXAMPLES:
+  # Revoke on-chain only
+  sage-did revoke did:sage:ethereum:0x1234... \
+    --key 0xabcd... --reason "laptop stolen" \
+    --private-key <owner-key>
+
+  # Full incident workflow
+  sage-did revoke did:sage:ethereum:0x1234... \
+    --key 0xabcd... --reason "key leaked in CI logs" \
+    --private-key <owner-key> \
+    --revocation-list ./revocations.json --issuer-key ./issuer.key \
+    --session-endpoint https://agent.example.com/admin/sessions/revoke \
+    --session-token $SAGE_SESSION_ADMIN_TOKEN --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRevoke,
+}
+
+var (
+	// Revoke flags
+	revokeKeyHash         string
+	revokeReason          string
+	revokeRPCEndpoint     string
+	revokeContractAddr    string
+	revokePrivateKey      string
+	revokeListFile        string
+	revokeIssuerKeyFile   string
+	revokeSessionEndpoint string
+	revokeSessionToken    string
+	revokeConfirm         bool
+)
+
+// keyRevoker is the part of did.Manager the revoke command uses
+type keyRevoker interface {
+	ResolveAgent(ctx context.Context, agentDID did.AgentDID) (*did.AgentMetadata, error)
+	RevokeKey(ctx context.Context, chain did.Chain, agentDID did.AgentDID, keyHash string) error
+}
+
+// newKeyRevoker builds the registry client; tests replace it
+var newKeyRevoker = func(chain did.Chain, config *did.RegistryConfig) (keyRevoker, error) {
+	manager := did.NewManager()
+	if err := manager.Configure(chain, config); err != nil {
+		return nil, fmt.Errorf("failed to configure DID manager: %w", err)
+	}
+	return manager, nil
+}
+
+func init() {
+	rootCmd.AddCommand(revokeCmd)
+
+	revokeCmd.Flags().StringVar(&revokeKeyHash, "key", "", "On-chain hash of the key to revoke")
+	revokeCmd.Flags().StringVar(&revokeReason, "reason", "", "Reason for the revocation (recorded with the session kill request)")
+
+	// Blockchain connection flags
+	revokeCmd.Flags().StringVar(&revokeRPCEndpoint, "rpc", "", "Blockchain RPC endpoint")
+	revokeCmd.Flags().StringVar(&revokeContractAddr, "contract", "", "DID registry contract address")
+	revokeCmd.Flags().StringVar(&revokePrivateKey, "private-key", "", "Agent owner private key")
+
+	// Revocation list flags
+	revokeCmd.Flags().StringVar(&revokeListFile, "revocation-list", "", "Revocation list file to update (created if missing)")
+	revokeCmd.Flags().StringVar(&revokeIssuerKeyFile, "issuer-key", "", "File with the hex Ed25519 key that signs the revocation list")
+
+	// Session manager flags
+	revokeCmd.Flags().StringVar(&revokeSessionEndpoint, "session-endpoint", "", "URL of a running session manager's revoke endpoint")
+	revokeCmd.Flags().StringVar(&revokeSessionToken, "session-token", "", "Bearer token for the session endpoint (default $SAGE_SESSION_ADMIN_TOKEN)")
+
+	revokeCmd.Flags().BoolVarP(&revokeConfirm, "yes", "y", false, "Skip confirmation prompt")
+
+	for _, name := range []string{"key", "reason", "private-key"} {
+		if err := revokeCmd.MarkFlagRequired(name); err != nil {
+			panic(fmt.Sprintf("failed to mark flag required: %v", err))
+		}
+	}
+}
+
+func runRevoke(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	agentDID := did.AgentDID(args[0])
+
+	chain, _, err := did.ParseDID(agentDID)
+	if err != nil {
+		return fmt.Errorf("invalid DID: %w", err)
+	}
+
+	// Fail before touching the chain if a later step cannot run
+	var issuer ed25519.PrivateKey
+	if revokeListFile != "" {
+		if revokeIssuerKeyFile == "" {
+			return fmt.Errorf("--issuer-key is required with --revocation-list")
+		}
+		if issuer, err = loadIssuerKey(revokeIssuerKeyFile); err != nil {
+			return err
+		}
+	}
+	sessionToken := revokeSessionToken
+	if sessionToken == "" {
+		sessionToken = os.Getenv("SAGE_SESSION_ADMIN_TOKEN")
+	}
+	if revokeSessionEndpoint != "" && sessionToken == "" {
+		return fmt.Errorf("--session-token or SAGE_SESSION_ADMIN_TOKEN is required with --session-endpoint")
+	}
+
+	if !revokeConfirm {
+		fmt.Printf(" WARNING: You are about to revoke key %s from agent %s\n", revokeKeyHash, agentDID)
+		fmt.Printf("Reason: %s\n", revokeReason)
+		fmt.Print("Are you sure you want to continue? (yes/no): ")
+		var confirmation string
+		if _, err := fmt.Scanln(&confirmation); err != nil || strings.ToLower(confirmation) != "yes" {
+			fmt.Println("Operation cancelled")
+			return nil
+		}
+	}
+
+	config := &did.RegistryConfig{
+		RPCEndpoint:     revokeRPCEndpoint,
+		ContractAddress: revokeContractAddr,
+		PrivateKey:      revokePrivateKey,
+	}
+	if config.RPCEndpoint == "" {
+		config.RPCEndpoint = getDefaultRPCEndpoint(chain)
+	}
+	if config.ContractAddress == "" {
+		config.ContractAddress = getDefaultContractAddress(chain)
+	}
+
+	// Every step runs even if an earlier one fails; failures are joined
+	var errs []error
+	var listKeyHash string
+	revoker, err := newKeyRevoker(chain, config)
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		// Look the key up before revoking it; afterwards the registry no
+		// longer returns it and the revocation list entry cannot be derived.
+		if revokeListFile != "" {
+			if listKeyHash, err = listHashForKey(ctx, revoker, agentDID, revokeKeyHash); err != nil {
+				errs = append(errs, fmt.Errorf("failed to update revocation list: %w", err))
+			}
+		}
+
+		fmt.Printf("Revoking key %s on-chain...\n", revokeKeyHash)
+		if err := revoker.RevokeKey(ctx, chain, agentDID, revokeKeyHash); err != nil {
+			errs = append(errs, fmt.Errorf("failed to revoke key: %w", err))
+		} else {
+			fmt.Println(" Key revoked on-chain")
+		}
+	}
+
+	if listKeyHash != "" {
+		version, err := addToRevocationList(revokeListFile, issuer, listKeyHash)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to update revocation list: %w", err))
+		} else {
+			fmt.Printf(" Revocation list %s updated (version %d)\n", revokeListFile, version)
+		}
+	}
+	if revokeSessionEndpoint != "" {
+		n, err := revokeRemoteSessions(ctx, revokeSessionEndpoint, sessionToken, agentDID, revokeReason)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to revoke sessions: %w", err))
+		} else {
+			fmt.Printf(" %d active session(s) closed\n", n)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// listHashForKey resolves agentDID and returns the revocation list hash of
+// its key with on-chain hash keyHash
+func listHashForKey(ctx context.Context, revoker keyRevoker, agentDID did.AgentDID, keyHash string) (string, error) {
+	metadata, err := revoker.ResolveAgent(ctx, agentDID)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve agent: %w", err)
+	}
+	return revocationHashForKey(metadata, keyHash)
+}
+
+// revocationHashForKey finds the agent key whose on-chain hash is keyHash and
+// returns its did.RevocationKeyHash
+func revocationHashForKey(metadata *did.AgentMetadata, keyHash string) (string, error) {
+	keyHash = strings.ToLower(keyHash)
+	for _, key := range did.FromAgentMetadata(metadata).Keys {
+		if ethcrypto.Keccak256Hash(key.KeyData).Hex() != keyHash {
+			continue
+		}
+		keyType := "secp256k1"
+		switch key.Type {
+		case did.KeyTypeEd25519:
+			keyType = "ed25519"
+		case did.KeyTypeX25519:
+			keyType = "x25519"
+		}
+		pub, err := did.UnmarshalPublicKey(key.KeyData, keyType)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse key %s: %w", keyHash, err)
+		}
+		return did.RevocationKeyHash(pub)
+	}
+
+	// Resolvers that return parsed keys are matched on their registry encoding
+	if metadata.PublicKey != nil {
+		if raw, err := did.MarshalPublicKey(metadata.PublicKey); err == nil &&
+			ethcrypto.Keccak256Hash(raw).Hex() == keyHash {
+			return did.RevocationKeyHash(metadata.PublicKey)
+		}
+	}
+	return "", fmt.Errorf("key %s not found on agent %s", keyHash, metadata.DID)
+}
+
+// loadIssuerKey reads a hex-encoded Ed25519 seed or private key
+func loadIssuerKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read issuer key: %w", err)
+	}
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("issuer key is not hex: %w", err)
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	default:
+		return nil, fmt.Errorf("invalid issuer key size: %d", len(raw))
+	}
+}
+
+// addToRevocationList adds keyHash to the list at path, bumps its version and
+// re-signs it. An existing list must verify under the issuer key.
+func addToRevocationList(path string, issuer ed25519.PrivateKey, keyHash string) (uint64, error) {
+	list := &did.RevocationList{}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, list); err != nil {
+			return 0, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if err := list.Verify(issuer.Public().(ed25519.PublicKey)); err != nil {
+			return 0, err
+		}
+	case !os.IsNotExist(err):
+		return 0, err
+	}
+
+	found := false
+	for _, k := range list.RevokedKeys {
+		if k == keyHash {
+			found = true
+			break
+		}
+	}
+	if !found {
+		list.RevokedKeys = append(list.RevokedKeys, keyHash)
+	}
+	list.Version++
+	list.IssuedAt = time.Now().UTC()
+	if err := list.Sign(issuer); err != nil {
+		return 0, err
+	}
+
+	out, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".revocation-*.json")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(append(out, '\n')); err != nil {
+		_ = tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+	return list.Version, nil
+}
+
+// revokeRemoteSessions calls a session manager's revoke endpoint (see
+// session.NewRevokeHandler) and returns how many sessions it closed
+func revokeRemoteSessions(ctx context.Context, endpoint, token string, agentDID did.AgentDID, reason string) (int, error) {
+	body, err := json.Marshal(session.RevokeRequest{DID: string(agentDID), Reason: reason})
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("session endpoint returned %s", resp.Status)
+	}
+	var out session.RevokeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return 0, fmt.Errorf("invalid session endpoint response: %w", err)
+	}
+	return out.Revoked, nil
+}
@@ -0,0 +1,199 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/session"
+)
+
+type revokeCall struct {
+	chain   did.Chain
+	did     did.AgentDID
+	keyHash string
+}
+
+type fakeKeyRevoker struct {
+	metadata  *did.AgentMetadata
+	revokeErr error
+	calls     []revokeCall
+}
+
+func (f *fakeKeyRevoker) ResolveAgent(ctx context.Context, agentDID did.AgentDID) (*did.AgentMetadata, error) {
+	return f.metadata, nil
+}
+
+func (f *fakeKeyRevoker) RevokeKey(ctx context.Context, chain did.Chain, agentDID did.AgentDID, keyHash string) error {
+	f.calls = append(f.calls, revokeCall{chain: chain, did: agentDID, keyHash: keyHash})
+	return f.revokeErr
+}
+
+// setupRevoke installs a fake registry client and resets the revoke flags
+func setupRevoke(t *testing.T, fake *fakeKeyRevoker) {
+	t.Helper()
+	var gotConfig *did.RegistryConfig
+	orig := newKeyRevoker
+	newKeyRevoker = func(chain did.Chain, config *did.RegistryConfig) (keyRevoker, error) {
+		gotConfig = config
+		return fake, nil
+	}
+	t.Cleanup(func() {
+		newKeyRevoker = orig
+		revokeKeyHash, revokeReason, revokePrivateKey = "", "", ""
+		revokeRPCEndpoint, revokeContractAddr = "", ""
+		revokeListFile, revokeIssuerKeyFile = "", ""
+		revokeSessionEndpoint, revokeSessionToken = "", ""
+		revokeConfirm = false
+		if gotConfig != nil && gotConfig.PrivateKey != "owner-key" {
+			t.Errorf("registry configured with private key %q", gotConfig.PrivateKey)
+		}
+	})
+	revokeConfirm = true
+	revokePrivateKey = "owner-key"
+	revokeReason = "key leaked"
+}
+
+func TestRunRevoke_OnChainCall(t *testing.T) {
+	fake := &fakeKeyRevoker{}
+	setupRevoke(t, fake)
+
+	const agentDID = "did:sage:ethereum:0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+	revokeKeyHash = "0x" + hex.EncodeToString(make([]byte, 32))
+
+	if err := runRevoke(revokeCmd, []string{agentDID}); err != nil {
+		t.Fatalf("runRevoke failed: %v", err)
+	}
+
+	if len(fake.calls) != 1 {
+		t.Fatalf("expected 1 RevokeKey call, got %d", len(fake.calls))
+	}
+	want := revokeCall{chain: did.ChainEthereum, did: agentDID, keyHash: revokeKeyHash}
+	if fake.calls[0] != want {
+		t.Errorf("RevokeKey called with %+v, want %+v", fake.calls[0], want)
+	}
+}
+
+func TestRunRevoke_ListAndSessions(t *testing.T) {
+	runListAndSessions(t, nil)
+}
+
+func TestRunRevoke_OnChainFailureStillRunsOtherSteps(t *testing.T) {
+	chainErr := errors.New("rpc unavailable")
+	err := runListAndSessions(t, chainErr)
+	if !errors.Is(err, chainErr) {
+		t.Fatalf("expected the on-chain failure to be reported, got %v", err)
+	}
+}
+
+// runListAndSessions runs the full revoke workflow against a fake registry
+// whose RevokeKey returns revokeErr, checks that the revocation list and
+// session steps ran, and returns runRevoke's error.
+func runListAndSessions(t *testing.T, revokeErr error) error {
+	t.Helper()
+	priv, err := ethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := did.UnmarshalPublicKey(ethcrypto.FromECDSAPub(&priv.PublicKey), "secp256k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyData, err := did.MarshalPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const agentDID = "did:sage:ethereum:0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+	fake := &fakeKeyRevoker{metadata: &did.AgentMetadata{DID: agentDID, PublicKey: keyData}, revokeErr: revokeErr}
+	setupRevoke(t, fake)
+
+	// Session manager endpoint
+	var gotReq session.RevokeRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer admin-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&gotReq); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(session.RevokeResponse{DID: gotReq.DID, Revoked: 2})
+	}))
+	defer srv.Close()
+
+	// Revocation list issuer
+	dir := t.TempDir()
+	_, issuer, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuerFile := filepath.Join(dir, "issuer.key")
+	if err := os.WriteFile(issuerFile, []byte(hex.EncodeToString(issuer.Seed())), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	revokeKeyHash = ethcrypto.Keccak256Hash(keyData).Hex()
+	revokeListFile = filepath.Join(dir, "revocations.json")
+	revokeIssuerKeyFile = issuerFile
+	revokeSessionEndpoint = srv.URL
+	revokeSessionToken = "admin-token"
+
+	runErr := runRevoke(revokeCmd, []string{agentDID})
+	if revokeErr == nil && runErr != nil {
+		t.Fatalf("runRevoke failed: %v", runErr)
+	}
+
+	if len(fake.calls) != 1 || fake.calls[0].keyHash != revokeKeyHash {
+		t.Errorf("unexpected RevokeKey calls: %+v", fake.calls)
+	}
+	if gotReq.DID != agentDID || gotReq.Reason != "key leaked" {
+		t.Errorf("session endpoint got %+v", gotReq)
+	}
+
+	data, err := os.ReadFile(revokeListFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list did.RevocationList
+	if err := json.Unmarshal(data, &list); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Verify(issuer.Public().(ed25519.PublicKey)); err != nil {
+		t.Errorf("revocation list does not verify: %v", err)
+	}
+	wantHash, err := did.RevocationKeyHash(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Version != 1 || len(list.RevokedKeys) != 1 || list.RevokedKeys[0] != wantHash {
+		t.Errorf("unexpected revocation list: version=%d keys=%v", list.Version, list.RevokedKeys)
+	}
+	return runErr
+}
@@ -79,27 +79,15 @@ func printUsage() {
 	fmt.Println("Environment Variables:")
 	fmt.Println("  SAGE_NETWORK   - Network to connect to (default: local)")
 	fmt.Println("  SAGE_RPC_URL   - Override blockchain RPC URL")
+	fmt.Println("  SAGE_REGISTRY_ADDRESS - Override registry contract address")
+	fmt.Println("  SAGE_CHAIN_ID  - Override chain ID")
 }
 
 func runHealthCheck() {
 	jsonOutput := hasJSONFlag()
 
 	// Load network configuration
-	network := os.Getenv("SAGE_NETWORK")
-	if network == "" {
-		network = "local"
-	}
-
-	rpcURL := os.Getenv("SAGE_RPC_URL")
-	if rpcURL == "" {
-		// Try to load from config
-		cfg, err := config.LoadConfig(network)
-		if err == nil && cfg.NetworkRPC != "" {
-			rpcURL = cfg.NetworkRPC
-		} else {
-			rpcURL = "http://localhost:8545" // Default
-		}
-	}
+	network, rpcURL := loadNetworkConfig()
 
 	// Run health check
 	checker := health.NewChecker(rpcURL)
@@ -124,20 +112,7 @@ func runHealthCheck() {
 func runBlockchainCheck() {
 	jsonOutput := hasJSONFlag()
 
-	network := os.Getenv("SAGE_NETWORK")
-	if network == "" {
-		network = "local"
-	}
-
-	rpcURL := os.Getenv("SAGE_RPC_URL")
-	if rpcURL == "" {
-		cfg, err := config.LoadConfig(network)
-		if err == nil && cfg.NetworkRPC != "" {
-			rpcURL = cfg.NetworkRPC
-		} else {
-			rpcURL = "http://localhost:8545"
-		}
-	}
+	network, rpcURL := loadNetworkConfig()
 
 	blockchainStatus := health.CheckBlockchain(rpcURL)
 
@@ -180,6 +155,21 @@ func runBlockchainCheck() {
 	}
 }
 
+// loadNetworkConfig resolves the network and RPC URL to check.
+// Configuration problems are reported on stderr; only a missing or
+// unusable RPC URL is fatal since the checks need nothing else.
+func loadNetworkConfig() (network, rpcURL string) {
+	cfg, err := config.LoadNetworkConfig("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration problems:\n%v\n\n", err)
+	}
+	if cfg.RPCURL == "" {
+		fmt.Fprintln(os.Stderr, "No RPC URL configured; set SAGE_RPC_URL or SAGE_NETWORK")
+		os.Exit(1)
+	}
+	return cfg.Network, cfg.RPCURL
+}
+
 func runSystemCheck() {
 	jsonOutput := hasJSONFlag()
 
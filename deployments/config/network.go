@@ -0,0 +1,169 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultNetwork is the network used when SAGE_NETWORK is not set
+const DefaultNetwork = "local"
+
+// LocalRegistryAddress is the address SageRegistryV4 receives when deployed
+// first on a fresh Hardhat node with the default deployer account.
+const LocalRegistryAddress = "0xDc64a140Aa3E981100a9becA4E685f962f0cF6C9"
+
+// NetworkConfig is the minimal, validated set of settings a tool or agent
+// needs to talk to a SAGE registry.
+type NetworkConfig struct {
+	Network         string   `json:"network"`
+	RPCURL          string   `json:"rpc_url"`
+	RegistryAddress string   `json:"registry_address"`
+	ChainID         *big.Int `json:"chain_id"`
+}
+
+// Error implements the error interface so validation problems can be joined
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// LoadNetworkConfig builds a NetworkConfig for the given network.
+//
+// Values come from the network preset and deployment files and are then
+// overridden by environment variables:
+//
+//	SAGE_NETWORK           - network name, used when network is empty
+//	SAGE_RPC_URL           - RPC endpoint (legacy: RPC_URL)
+//	SAGE_REGISTRY_ADDRESS  - registry contract (legacy: SAGE_CONTRACT_ADDRESS, REGISTRY_ADDRESS)
+//	SAGE_CHAIN_ID          - decimal chain ID
+//
+// The returned config is always non-nil so callers can still report what was
+// loaded. The error joins every problem found, including malformed
+// environment values and the result of Validate.
+func LoadNetworkConfig(network string) (*NetworkConfig, error) {
+	if network == "" {
+		network = os.Getenv("SAGE_NETWORK")
+	}
+	if network == "" {
+		network = DefaultNetwork
+	}
+	network = strings.ToLower(network)
+
+	cfg := &NetworkConfig{Network: network}
+	if preset, ok := NetworkPresets[network]; ok {
+		cfg.RPCURL = preset.NetworkRPC
+		cfg.ChainID = new(big.Int).Set(preset.ChainID)
+	}
+
+	var errs []error
+
+	if rpc := firstEnv("SAGE_RPC_URL", "RPC_URL"); rpc != "" {
+		cfg.RPCURL = rpc
+	}
+
+	if addr := firstEnv("SAGE_REGISTRY_ADDRESS", "SAGE_CONTRACT_ADDRESS", "REGISTRY_ADDRESS"); addr != "" {
+		cfg.RegistryAddress = addr
+	} else if addr, err := GetContractAddress(network); err == nil {
+		cfg.RegistryAddress = addr
+	} else if network == "local" {
+		cfg.RegistryAddress = LocalRegistryAddress
+	}
+
+	if chainID := os.Getenv("SAGE_CHAIN_ID"); chainID != "" {
+		id, ok := new(big.Int).SetString(chainID, 10)
+		if !ok {
+			errs = append(errs, ValidationError{
+				Field:   "ChainID",
+				Message: fmt.Sprintf("SAGE_CHAIN_ID is not a decimal integer: %q", chainID),
+				Level:   "error",
+			})
+		} else {
+			cfg.ChainID = id
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return cfg, errors.Join(errs...)
+}
+
+// Validate checks every field and reports all problems at once.
+// The returned error wraps one ValidationError per problem.
+func (c *NetworkConfig) Validate() error {
+	var errs []error
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{
+			Field:   field,
+			Message: fmt.Sprintf(format, args...),
+			Level:   "error",
+		})
+	}
+
+	if c.Network == "" {
+		fail("Network", "network name is required")
+	}
+
+	if c.RPCURL == "" {
+		fail("RPCURL", "RPC URL is required")
+	} else if u, err := url.Parse(c.RPCURL); err != nil {
+		fail("RPCURL", "invalid RPC URL: %v", err)
+	} else {
+		switch u.Scheme {
+		case "http", "https", "ws", "wss":
+		default:
+			fail("RPCURL", "unsupported RPC URL scheme %q", u.Scheme)
+		}
+		if u.Host == "" {
+			fail("RPCURL", "RPC URL has no host")
+		}
+	}
+
+	if c.RegistryAddress == "" {
+		fail("RegistryAddress", "registry address is required")
+	} else if !common.IsHexAddress(c.RegistryAddress) {
+		fail("RegistryAddress", "invalid registry address: %s", c.RegistryAddress)
+	}
+
+	if c.ChainID == nil {
+		fail("ChainID", "chain ID is required")
+	} else if c.ChainID.Sign() <= 0 {
+		fail("ChainID", "chain ID must be positive, got %s", c.ChainID)
+	}
+
+	return errors.Join(errs...)
+}
+
+// firstEnv returns the value of the first non-empty environment variable
+func firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
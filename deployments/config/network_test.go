@@ -0,0 +1,176 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validationFields unwraps a joined validation error into its field names
+func validationFields(t *testing.T, err error) []string {
+	t.Helper()
+	var fields []string
+	var walk func(error)
+	walk = func(e error) {
+		if joined, ok := e.(interface{ Unwrap() []error }); ok {
+			for _, inner := range joined.Unwrap() {
+				walk(inner)
+			}
+			return
+		}
+		var ve ValidationError
+		require.True(t, errors.As(e, &ve), "unexpected error type: %T", e)
+		fields = append(fields, ve.Field)
+	}
+	walk(err)
+	return fields
+}
+
+func clearNetworkEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{
+		"SAGE_NETWORK", "SAGE_RPC_URL", "RPC_URL", "SAGE_REGISTRY_ADDRESS",
+		"SAGE_CONTRACT_ADDRESS", "REGISTRY_ADDRESS", "SAGE_CHAIN_ID",
+	} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestNetworkConfigValidate(t *testing.T) {
+	valid := func() *NetworkConfig {
+		return &NetworkConfig{
+			Network:         "local",
+			RPCURL:          "http://localhost:8545",
+			RegistryAddress: LocalRegistryAddress,
+			ChainID:         big.NewInt(31337),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*NetworkConfig)
+		fields []string
+	}{
+		{
+			name:   "Valid config",
+			modify: func(c *NetworkConfig) {},
+		},
+		{
+			name: "All required fields missing",
+			modify: func(c *NetworkConfig) {
+				*c = NetworkConfig{}
+			},
+			fields: []string{"Network", "RPCURL", "RegistryAddress", "ChainID"},
+		},
+		{
+			name: "Invalid values reported together",
+			modify: func(c *NetworkConfig) {
+				c.RPCURL = "ftp://"
+				c.RegistryAddress = "0x1234"
+				c.ChainID = big.NewInt(-1)
+			},
+			fields: []string{"RPCURL", "RPCURL", "RegistryAddress", "ChainID"},
+		},
+		{
+			name: "Websocket RPC accepted",
+			modify: func(c *NetworkConfig) {
+				c.RPCURL = "wss://node.example.com/ws"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if len(tt.fields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.fields, validationFields(t, err))
+		})
+	}
+}
+
+func TestLoadNetworkConfig(t *testing.T) {
+	t.Run("Local defaults", func(t *testing.T) {
+		clearNetworkEnv(t)
+
+		cfg, err := LoadNetworkConfig("")
+		require.NoError(t, err)
+		assert.Equal(t, "local", cfg.Network)
+		assert.Equal(t, "http://localhost:8545", cfg.RPCURL)
+		assert.Equal(t, LocalRegistryAddress, cfg.RegistryAddress)
+		assert.Equal(t, int64(31337), cfg.ChainID.Int64())
+	})
+
+	t.Run("Environment overrides", func(t *testing.T) {
+		clearNetworkEnv(t)
+		t.Setenv("SAGE_NETWORK", "kairos")
+		t.Setenv("SAGE_RPC_URL", "https://rpc.example.com")
+		t.Setenv("SAGE_REGISTRY_ADDRESS", "0x5FbDB2315678afecb367f032d93F642f64180aa3")
+		t.Setenv("SAGE_CHAIN_ID", "999")
+
+		cfg, err := LoadNetworkConfig("")
+		require.NoError(t, err)
+		assert.Equal(t, "kairos", cfg.Network)
+		assert.Equal(t, "https://rpc.example.com", cfg.RPCURL)
+		assert.Equal(t, "0x5FbDB2315678afecb367f032d93F642f64180aa3", cfg.RegistryAddress)
+		assert.Equal(t, int64(999), cfg.ChainID.Int64())
+	})
+
+	t.Run("Legacy variables", func(t *testing.T) {
+		clearNetworkEnv(t)
+		t.Setenv("RPC_URL", "http://legacy:8545")
+		t.Setenv("REGISTRY_ADDRESS", "0x5FbDB2315678afecb367f032d93F642f64180aa3")
+
+		cfg, err := LoadNetworkConfig("local")
+		require.NoError(t, err)
+		assert.Equal(t, "http://legacy:8545", cfg.RPCURL)
+		assert.Equal(t, "0x5FbDB2315678afecb367f032d93F642f64180aa3", cfg.RegistryAddress)
+	})
+
+	t.Run("Problems are aggregated", func(t *testing.T) {
+		clearNetworkEnv(t)
+		t.Setenv("SAGE_RPC_URL", "not a url")
+		t.Setenv("SAGE_REGISTRY_ADDRESS", "registry")
+		t.Setenv("SAGE_CHAIN_ID", "abc")
+
+		cfg, err := LoadNetworkConfig("local")
+		require.Error(t, err)
+		require.NotNil(t, cfg)
+		assert.Equal(t, []string{"ChainID", "RPCURL", "RPCURL", "RegistryAddress"}, validationFields(t, err))
+	})
+
+	t.Run("Unknown network without overrides", func(t *testing.T) {
+		clearNetworkEnv(t)
+
+		cfg, err := LoadNetworkConfig("unknown-net")
+		require.Error(t, err)
+		assert.Equal(t, "unknown-net", cfg.Network)
+		assert.Equal(t, []string{"RPCURL", "RegistryAddress", "ChainID"}, validationFields(t, err))
+	})
+}
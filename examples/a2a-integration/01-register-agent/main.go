@@ -26,6 +26,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/sage-x-project/sage/deployments/config"
 	_ "github.com/sage-x-project/sage/internal/cryptoinit"
 	"github.com/sage-x-project/sage/pkg/agent/crypto"
 	"github.com/sage-x-project/sage/pkg/agent/did"
@@ -50,16 +51,15 @@ func main() {
 	fmt.Println()
 
 	// Get configuration from environment
-	registryAddress := os.Getenv("REGISTRY_ADDRESS")
-	rpcURL := os.Getenv("RPC_URL")
+	netCfg, err := config.LoadNetworkConfig("")
+	if err != nil {
+		fmt.Printf(" Invalid network configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+	registryAddress := netCfg.RegistryAddress
+	rpcURL := netCfg.RPCURL
 	privateKeyHex := os.Getenv("PRIVATE_KEY")
 
-	if registryAddress == "" {
-		registryAddress = "0xDc64a140Aa3E981100a9becA4E685f962f0cF6C9" // Default local deployment
-	}
-	if rpcURL == "" {
-		rpcURL = "http://localhost:8545"
-	}
 	if privateKeyHex == "" {
 		fmt.Println(" Error: PRIVATE_KEY environment variable not set")
 		fmt.Println("   export PRIVATE_KEY=0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
@@ -167,12 +167,14 @@ func main() {
 			{
 				Type:    did.KeyTypeEd25519,
 				KeyData: ed25519PubKey,
-				// Signature will be empty for Ed25519 on Ethereum (requires approval)
+				// Signature may be left empty (the owner approves the key) or set to
+				// did.BuildKeyOwnershipProof(domain, senderKey, ed25519KeyPair)
 			},
 			{
 				Type:    did.KeyTypeX25519,
 				KeyData: x25519PubKey,
-				// Signature will be empty for X25519 (no verification needed for KEM keys)
+				// Signature: did.BuildKeyOwnershipProof(domain, senderKey, x25519KeyPair),
+				// signed by the account that sends the registration transaction
 			},
 		},
 	}
@@ -202,6 +204,9 @@ func main() {
 	fmt.Println("Transaction Hash:", result.TransactionHash)
 	fmt.Println("Block Number:    ", result.BlockNumber)
 	fmt.Println("Gas Used:        ", result.GasUsed)
+	if result.AgentID != "" {
+		fmt.Println("Agent ID:        ", result.AgentID)
+	}
 	fmt.Println()
 
 	// Step 5: Verify registration
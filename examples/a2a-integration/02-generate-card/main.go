@@ -27,6 +27,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/sage-x-project/sage/deployments/config"
 	_ "github.com/sage-x-project/sage/internal/cryptoinit"
 	"github.com/sage-x-project/sage/pkg/agent/did"
 )
@@ -55,16 +56,15 @@ func main() {
 	fmt.Println()
 
 	// Get configuration from environment
-	registryAddress := os.Getenv("REGISTRY_ADDRESS")
-	rpcURL := os.Getenv("RPC_URL")
+	netCfg, err := config.LoadNetworkConfig("")
+	if err != nil {
+		fmt.Printf(" Invalid network configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+	registryAddress := netCfg.RegistryAddress
+	rpcURL := netCfg.RPCURL
 	agentDIDStr := os.Getenv("AGENT_DID")
 
-	if registryAddress == "" {
-		registryAddress = "0xDc64a140Aa3E981100a9becA4E685f962f0cF6C9"
-	}
-	if rpcURL == "" {
-		rpcURL = "http://localhost:8545"
-	}
 	if agentDIDStr == "" {
 		fmt.Println(" Error: AGENT_DID environment variable not set")
 		fmt.Println("   Please run example 01 first to register an agent")
@@ -95,7 +95,7 @@ func main() {
 		ConfirmationBlocks: 0,
 	}
 
-	err := manager.Configure(did.ChainEthereum, config)
+	err = manager.Configure(did.ChainEthereum, config)
 	if err != nil {
 		fmt.Printf(" Failed to configure manager: %v\n", err)
 		os.Exit(1)
@@ -27,6 +27,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/sage-x-project/sage/deployments/config"
 	_ "github.com/sage-x-project/sage/internal/cryptoinit"
 	"github.com/sage-x-project/sage/pkg/agent/crypto"
 	"github.com/sage-x-project/sage/pkg/agent/did"
@@ -49,16 +50,15 @@ func main() {
 	fmt.Println()
 
 	// Get configuration
-	registryAddress := os.Getenv("REGISTRY_ADDRESS")
-	rpcURL := os.Getenv("RPC_URL")
+	netCfg, err := config.LoadNetworkConfig("")
+	if err != nil {
+		fmt.Printf(" Invalid network configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+	registryAddress := netCfg.RegistryAddress
+	rpcURL := netCfg.RPCURL
 	privateKey := os.Getenv("PRIVATE_KEY")
 
-	if registryAddress == "" {
-		registryAddress = "0xDc64a140Aa3E981100a9becA4E685f962f0cF6C9"
-	}
-	if rpcURL == "" {
-		rpcURL = "http://localhost:8545"
-	}
 	if privateKey == "" {
 		privateKey = "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
 	}
@@ -80,7 +80,7 @@ func main() {
 		ConfirmationBlocks: 0,
 	}
 
-	err := manager.Configure(did.ChainEthereum, config)
+	err = manager.Configure(did.ChainEthereum, config)
 	if err != nil {
 		fmt.Printf(" Failed to configure manager: %v\n", err)
 		os.Exit(1)
@@ -130,7 +130,8 @@ func main() {
 	fmt.Println("═════════════════════════════════════════════════════════")
 	fmt.Println()
 
-	// In a real scenario, this would be received via HTTP/HTTPS
+	// In a real scenario, Agent A serves the card at did.CardWellKnownPath
+	// (see did.CardHandler) and Agent B retrieves it with did.FetchCard
 	// For this example, we load it from the file
 	receivedCardData, err := os.ReadFile("agent-a-card.json")
 	if err != nil {
@@ -280,7 +281,7 @@ func main() {
 	fmt.Println()
 	fmt.Println("Next steps:")
 	fmt.Println("  1. Run example 04 for secure messaging between A and B")
-	fmt.Println("  2. Implement card exchange over HTTP/HTTPS")
+	fmt.Println("  2. Serve cards with did.CardHandler and fetch them with did.FetchCard")
 	fmt.Println("  3. Build agent discovery services")
 	fmt.Println()
 }
@@ -27,9 +27,11 @@ import (
 	"os"
 	"time"
 
+	"github.com/sage-x-project/sage/deployments/config"
 	_ "github.com/sage-x-project/sage/internal/cryptoinit"
 	"github.com/sage-x-project/sage/pkg/agent/crypto"
 	"github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/hpke"
 )
 
 // Example 04: Secure Message Exchange
@@ -46,12 +48,22 @@ import (
 // - Multi-key agents for maximum security
 
 type SecureMessage struct {
-	From      string `json:"from"`      // Sender's DID
-	To        string `json:"to"`        // Recipient's DID
-	Timestamp string `json:"timestamp"` // Message timestamp
-	Content   []byte `json:"content"`   // Encrypted content
-	Signature []byte `json:"signature"` // Ed25519 signature of content
-	Nonce     []byte `json:"nonce"`     // HPKE nonce/encapsulated key
+	From      string             `json:"from"`      // Sender's DID
+	To        string             `json:"to"`        // Recipient's DID
+	Timestamp string             `json:"timestamp"` // Message timestamp
+	Envelope  *hpke.HPKEEnvelope `json:"envelope"`  // HPKE-encrypted content
+	Signature []byte             `json:"signature"` // Ed25519 signature of enc || ciphertext
+}
+
+// messageInfo binds an envelope to its sender and recipient so it cannot be
+// replayed between other agents.
+func messageInfo(from, to string) []byte {
+	return []byte("sage-example-04|" + from + "|" + to)
+}
+
+// signedBytes is what the sender signs: the encapsulated key and ciphertext.
+func signedBytes(env *hpke.HPKEEnvelope) []byte {
+	return append(append([]byte{}, env.Enc...), env.Ciphertext...)
 }
 
 func main() {
@@ -60,16 +72,15 @@ func main() {
 	fmt.Println("╚═══════════════════════════════════════════════════════════╝")
 	fmt.Println()
 
-	registryAddress := os.Getenv("REGISTRY_ADDRESS")
-	rpcURL := os.Getenv("RPC_URL")
+	netCfg, err := config.LoadNetworkConfig("")
+	if err != nil {
+		fmt.Printf(" Invalid network configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+	registryAddress := netCfg.RegistryAddress
+	rpcURL := netCfg.RPCURL
 	privateKey := os.Getenv("PRIVATE_KEY")
 
-	if registryAddress == "" {
-		registryAddress = "0xDc64a140Aa3E981100a9becA4E685f962f0cF6C9"
-	}
-	if rpcURL == "" {
-		rpcURL = "http://localhost:8545"
-	}
 	if privateKey == "" {
 		privateKey = "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
 	}
@@ -85,7 +96,7 @@ func main() {
 		ConfirmationBlocks: 0,
 	}
 
-	err := manager.Configure(did.ChainEthereum, config)
+	err = manager.Configure(did.ChainEthereum, config)
 	if err != nil {
 		fmt.Printf(" Failed to configure manager: %v\n", err)
 		os.Exit(1)
@@ -193,24 +204,23 @@ func main() {
 	fmt.Println(" Encrypting message with HPKE...")
 	fmt.Println("   Using Agent B's X25519 public key")
 
-	// Note: In a real implementation, you would use HPKE encryption here
-	// For this example, we'll demonstrate the key exchange and signing workflow
-	// The actual HPKE implementation would use crypto.EncryptHPKE(agentBX25519.PublicKey(), plaintext)
-
-	// For demonstration: we'll use a simplified encryption (in production, use HPKE)
-	ciphertext := make([]byte, len(plaintext))
-	copy(ciphertext, plaintext)
-	// In production: ciphertext, nonce, err := hpke.Seal(agentBX25519PublicKey, plaintext, nil)
+	envelope, err := hpke.SealToPublicKey(agentBX25519.PublicKey(), plaintext, messageInfo(string(agentADID), string(agentBDID)))
+	if err != nil {
+		fmt.Printf(" Failed to encrypt message: %v\n", err)
+		os.Exit(1)
+	}
 
 	fmt.Println(" Message encrypted")
-	fmt.Printf("  Ciphertext length: %d bytes\n", len(ciphertext))
+	fmt.Printf("  Suite: %s\n", envelope.Suite)
+	fmt.Printf("  Encapsulated key length: %d bytes\n", len(envelope.Enc))
+	fmt.Printf("  Ciphertext length: %d bytes\n", len(envelope.Ciphertext))
 	fmt.Println()
 
 	// Sign the ciphertext with Agent A's Ed25519 key
 	fmt.Println("  Signing encrypted message...")
 	fmt.Println("   Using Agent A's Ed25519 private key")
 
-	signature, err := agentAEd25519.Sign(ciphertext)
+	signature, err := agentAEd25519.Sign(signedBytes(envelope))
 	if err != nil {
 		fmt.Printf(" Failed to sign message: %v\n", err)
 		os.Exit(1)
@@ -225,9 +235,8 @@ func main() {
 		From:      string(agentADID),
 		To:        string(agentBDID),
 		Timestamp: time.Now().Format(time.RFC3339),
-		Content:   ciphertext,
+		Envelope:  envelope,
 		Signature: signature,
-		Nonce:     []byte{}, // In production, this would be the HPKE nonce
 	}
 
 	// Serialize to JSON for transport
@@ -266,7 +275,7 @@ func main() {
 	fmt.Println(" Verifying signature...")
 	fmt.Println("   Using Agent A's Ed25519 public key")
 
-	valid, err := agentAEd25519.PublicKey().Verify(receivedMsg.Content, receivedMsg.Signature)
+	valid, err := agentAEd25519.PublicKey().Verify(signedBytes(receivedMsg.Envelope), receivedMsg.Signature)
 	if err != nil || !valid {
 		fmt.Printf(" Signature verification failed: %v\n", err)
 		os.Exit(1)
@@ -280,9 +289,11 @@ func main() {
 	fmt.Println(" Decrypting message...")
 	fmt.Println("   Using Agent B's X25519 private key")
 
-	// In production: decrypted, err := hpke.Open(agentBX25519PrivateKey, receivedMsg.Content, receivedMsg.Nonce, nil)
-	// For demonstration:
-	decrypted := receivedMsg.Content
+	decrypted, err := hpke.OpenWithPrivateKey(agentBX25519.PrivateKey(), receivedMsg.Envelope, messageInfo(receivedMsg.From, receivedMsg.To))
+	if err != nil {
+		fmt.Printf(" Failed to decrypt message: %v\n", err)
+		os.Exit(1)
+	}
 
 	fmt.Println(" Message decrypted!")
 	fmt.Println()
@@ -33,6 +33,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/sage-x-project/sage/deployments/config"
 	_ "github.com/sage-x-project/sage/internal/cryptoinit"
 	"github.com/sage-x-project/sage/pkg/agent/crypto"
 	"github.com/sage-x-project/sage/pkg/agent/did"
@@ -363,6 +364,9 @@ func (a *Agent) RegisterOnBlockchain(registryAddress, rpcURL, privateKeyHex stri
 	fmt.Printf("   Transaction Hash: %s\n", result.TransactionHash)
 	fmt.Printf("   Block Number: %d\n", result.BlockNumber)
 	fmt.Printf("   Gas Used: %d\n", result.GasUsed)
+	if result.AgentID != "" {
+		fmt.Printf("   Agent ID: %s\n", result.AgentID)
+	}
 
 	return nil
 }
@@ -397,17 +401,16 @@ func main() {
 	// Configuration
 	agentName := "my-agent"
 	keyDir := "./keys"
-	registryAddress := os.Getenv("REGISTRY_ADDRESS")
-	rpcURL := os.Getenv("RPC_URL")
+	netCfg, err := config.LoadNetworkConfig("")
+	if err != nil {
+		fmt.Printf(" Invalid network configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+	registryAddress := netCfg.RegistryAddress
+	rpcURL := netCfg.RPCURL
 	privateKeyHex := os.Getenv("PRIVATE_KEY")
 
 	// Set defaults for local development
-	if registryAddress == "" {
-		registryAddress = "0xDc64a140Aa3E981100a9becA4E685f962f0cF6C9"
-	}
-	if rpcURL == "" {
-		rpcURL = "http://localhost:8545"
-	}
 	if privateKeyHex == "" {
 		privateKeyHex = "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
 	}
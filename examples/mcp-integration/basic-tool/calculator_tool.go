@@ -19,6 +19,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -137,6 +138,58 @@ func (t *CalculatorTool) HandleRequest(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(response)
 }
 
+// BatchToolResponse is the result of one item of a batched tool call
+type BatchToolResponse struct {
+	ID         string      `json:"id"`
+	Authorized bool        `json:"authorized"`
+	Result     interface{} `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// HandleBatchRequest processes a batch of tool calls, each signed by its own
+// agent. Every item is verified and authorized on its own: valid items are
+// executed and invalid ones are rejected without failing the whole batch.
+func (t *CalculatorTool) HandleBatchRequest(w http.ResponseWriter, r *http.Request) {
+	var batch struct {
+		Items []core.BatchItem `json:"items"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&batch); err != nil {
+		http.Error(w, "Invalid batch body", http.StatusBadRequest)
+		return
+	}
+
+	// Only agents holding the calculator capability may call the tool
+	authorize := func(_ context.Context, agent *did.AgentMetadata, _ core.BatchItem) error {
+		if !t.hasCapability(agent.Capabilities, "calculator") {
+			return fmt.Errorf("agent lacks calculator capability")
+		}
+		return nil
+	}
+	results := t.sage.GetVerificationService().VerifyBatch(r.Context(), batch.Items, authorize)
+
+	responses := make([]BatchToolResponse, len(results))
+	for i, res := range results {
+		responses[i] = BatchToolResponse{ID: res.ID, Authorized: res.Authorized, Error: res.Error}
+		if !res.Authorized {
+			continue
+		}
+		var req ToolRequest
+		if err := json.Unmarshal(batch.Items[i].Payload, &req); err != nil {
+			responses[i].Error = "invalid tool request"
+			continue
+		}
+		result, err := t.calculate(req.Operation, req.Arguments)
+		if err != nil {
+			responses[i].Error = err.Error()
+			continue
+		}
+		responses[i].Result = result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"results": responses})
+}
+
 // calculate performs the actual calculation
 func (t *CalculatorTool) calculate(operation string, args map[string]interface{}) (float64, error) {
 	switch operation {
@@ -46,6 +46,7 @@ func main() {
 	})
 
 	http.HandleFunc("/tools/calculator/execute", tool.HandleRequest)
+	http.HandleFunc("/tools/calculator/batch", tool.HandleBatchRequest)
 
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -58,6 +59,7 @@ func main() {
 	fmt.Println("Available endpoints:")
 	fmt.Println("  GET  /tools                    - List available tools")
 	fmt.Println("  POST /tools/calculator/execute - Execute calculation (requires SAGE signature)")
+	fmt.Println("  POST /tools/calculator/batch   - Execute a batch of individually signed calls")
 	fmt.Println("  GET  /health                   - Health check")
 	fmt.Println("")
 	fmt.Println("Security features:")
@@ -0,0 +1,95 @@
+//go:build ignore
+
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"fmt"
+	"log"
+
+	"github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/relay"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+	sagehttp "github.com/sage-x-project/sage/pkg/agent/transport/http"
+)
+
+// Example: Agent-to-Agent Relay
+//
+// Agent B cannot be reached directly, so Agent A sends through a relay:
+// 1. A resolves B's KEM key and seals the message to it with HPKE
+// 2. The relay forwards the envelope by recipient DID without decrypting it
+// 3. B opens the envelope with its KEM private key
+//
+// Everything runs in one process over in-process HTTP connections.
+
+const (
+	agentA = "did:sage:ethereum:0xagent-a"
+	agentB = "did:sage:ethereum:0xagent-b"
+)
+
+// staticKEMResolver stands in for the on-chain resolver.
+type staticKEMResolver map[did.AgentDID]*ecdh.PublicKey
+
+func (r staticKEMResolver) ResolveKEMKey(_ context.Context, agentDID did.AgentDID) (interface{}, error) {
+	if key, ok := r[agentDID]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("no KEM key for %s", agentDID)
+}
+
+func main() {
+	ctx := context.Background()
+
+	// Agent B's KEM key pair; the public half is what B publishes on chain
+	bKEM, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Agent B: receives forwarded envelopes
+	receiver, err := relay.NewReceiver(agentB, bKEM, func(_ context.Context, sender string, plaintext []byte) error {
+		fmt.Printf("[B]     from %s: %q\n", sender, plaintext)
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	toB, stopB := sagehttp.NewInProcessTransport(receiver.HandleMessage)
+	defer stopB()
+
+	// Relay: knows how to reach B, but has no keys
+	relayServer := relay.NewServer()
+	relayServer.Register(agentB, toB)
+	toRelay, stopRelay := sagehttp.NewInProcessTransport(func(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
+		fmt.Printf("[relay] forwarding %d opaque bytes to %s\n", len(msg.Payload), msg.Metadata[relay.MetadataRecipient])
+		return relayServer.HandleMessage(ctx, msg)
+	})
+	defer stopRelay()
+
+	// Agent A: only talks to the relay
+	a := relay.NewClient(toRelay, staticKEMResolver{agentB: bKEM.PublicKey()}, agentA)
+	if _, err := a.Send(ctx, agentB, []byte("hello from A, via the relay")); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("[A]     delivered")
+}
@@ -5,25 +5,23 @@
 // - Checks if keys exist on startup
 // - Loads existing keys if found
 // - Generates new keys if not found
-// - Saves keys for future use
+// - Saves keys for future use as one encrypted identity bundle
 
 package main
 
 import (
-	"context"
+	"crypto/ecdh"
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
-	"crypto/rand"
-	"crypto/x509"
-	"encoding/pem"
 	"fmt"
 	"os"
 	"path/filepath"
-	"time"
 
 	_ "github.com/sage-x-project/sage/internal/cryptoinit"
+	sageagent "github.com/sage-x-project/sage/pkg/agent"
 	"github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
 	"github.com/sage-x-project/sage/pkg/agent/did"
 )
 
@@ -37,8 +35,11 @@ type SimpleAgent struct {
 	X25519Key  []byte
 }
 
+// bundleFile is the single encrypted file holding the agent's whole identity
+const bundleFile = "agent.bundle"
+
 // NewSimpleAgent creates a new agent with automatic key management
-func NewSimpleAgent(name, keyDir string) (*SimpleAgent, error) {
+func NewSimpleAgent(name, keyDir string, passphrase []byte) (*SimpleAgent, error) {
 	agent := &SimpleAgent{
 		Name:   name,
 		KeyDir: keyDir,
@@ -49,140 +50,84 @@ func NewSimpleAgent(name, keyDir string) (*SimpleAgent, error) {
 		return nil, fmt.Errorf("failed to create key directory: %w", err)
 	}
 
-	// Initialize keys (load existing or generate new)
-	if err := agent.initializeKeys(); err != nil {
+	// Initialize identity (load existing bundle or generate new)
+	if err := agent.initializeIdentity(passphrase); err != nil {
 		return nil, fmt.Errorf("failed to initialize keys: %w", err)
 	}
 
-	// Generate DID
-	agent.DID = did.GenerateDID(did.ChainEthereum, name)
-
 	return agent, nil
 }
 
-// initializeKeys loads existing keys or generates new ones
-func (a *SimpleAgent) initializeKeys() error {
+// initializeIdentity loads the identity bundle or generates and saves a new one
+func (a *SimpleAgent) initializeIdentity(passphrase []byte) error {
 	fmt.Println(" Initializing cryptographic keys...")
+	bundlePath := filepath.Join(a.KeyDir, bundleFile)
 
-	// ECDSA Key
-	if err := a.loadOrGenerateECDSAKey(); err != nil {
-		return fmt.Errorf("ECDSA key error: %w", err)
-	}
-
-	// Ed25519 Key
-	if err := a.loadOrGenerateEd25519Key(); err != nil {
-		return fmt.Errorf("Ed25519 key error: %w", err)
-	}
-
-	// X25519 Key
-	if err := a.loadOrGenerateX25519Key(); err != nil {
-		return fmt.Errorf("X25519 key error: %w", err)
-	}
-
-	fmt.Println(" All keys initialized successfully")
-	return nil
-}
-
-// loadOrGenerateECDSAKey loads ECDSA key or generates a new one
-func (a *SimpleAgent) loadOrGenerateECDSAKey() error {
-	keyPath := filepath.Join(a.KeyDir, "ecdsa.key")
-
-	// Try to load existing key
-	if keyData, err := os.ReadFile(keyPath); err == nil {
-		block, _ := pem.Decode(keyData)
-		if block != nil {
-			if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
-				a.ECDSAKey = key
-				fmt.Println("   ECDSA key loaded from file")
-				return nil
-			}
+	// Try to load existing bundle
+	if data, err := os.ReadFile(bundlePath); err == nil {
+		id, err := sageagent.ImportBundle(data, passphrase)
+		if err != nil {
+			return fmt.Errorf("failed to open identity bundle: %w", err)
+		}
+		if err := a.useIdentity(id); err != nil {
+			return err
 		}
+		fmt.Println("   Identity loaded from bundle")
+		return nil
 	}
 
-	// Generate new key
-	fmt.Println("   Generating new ECDSA key...")
-	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	// Generate new keys
+	fmt.Println("   Generating new ECDSA, Ed25519 and X25519 keys...")
+	ecdsaKP, err := keys.GenerateP256KeyPair()
 	if err != nil {
 		return fmt.Errorf("failed to generate ECDSA key: %w", err)
 	}
-
-	// Save key
-	keyBytes, err := x509.MarshalECPrivateKey(key)
+	ed25519KP, err := keys.GenerateEd25519KeyPair()
 	if err != nil {
-		return fmt.Errorf("failed to marshal ECDSA key: %w", err)
+		return fmt.Errorf("failed to generate Ed25519 key: %w", err)
 	}
-
-	keyPEM := pem.EncodeToMemory(&pem.Block{
-		Type:  "EC PRIVATE KEY",
-		Bytes: keyBytes,
-	})
-
-	if err := os.WriteFile(keyPath, keyPEM, 0600); err != nil {
-		return fmt.Errorf("failed to save ECDSA key: %w", err)
+	x25519KP, err := keys.GenerateX25519KeyPair()
+	if err != nil {
+		return fmt.Errorf("failed to generate X25519 key: %w", err)
 	}
 
-	a.ECDSAKey = key
-	fmt.Println("   ECDSA key generated and saved")
-	return nil
-}
-
-// loadOrGenerateEd25519Key loads Ed25519 key or generates a new one
-func (a *SimpleAgent) loadOrGenerateEd25519Key() error {
-	keyPath := filepath.Join(a.KeyDir, "ed25519.key")
-
-	// Try to load existing key
-	if keyData, err := os.ReadFile(keyPath); err == nil {
-		if len(keyData) == ed25519.PrivateKeySize {
-			a.Ed25519Key = ed25519.PrivateKey(keyData)
-			fmt.Println("   Ed25519 key loaded from file")
-			return nil
-		}
+	id := &sageagent.Identity{
+		DID:  did.GenerateDID(did.ChainEthereum, a.Name),
+		Keys: []crypto.KeyPair{ecdsaKP, ed25519KP, x25519KP},
 	}
 
-	// Generate new key
-	fmt.Println("   Generating new Ed25519 key...")
-	_, key, err := ed25519.GenerateKey(rand.Reader)
+	// Save everything as one encrypted bundle
+	data, err := id.ExportBundle(passphrase)
 	if err != nil {
-		return fmt.Errorf("failed to generate Ed25519 key: %w", err)
+		return fmt.Errorf("failed to export identity bundle: %w", err)
 	}
-
-	// Save key
-	if err := os.WriteFile(keyPath, key, 0600); err != nil {
-		return fmt.Errorf("failed to save Ed25519 key: %w", err)
+	if err := os.WriteFile(bundlePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to save identity bundle: %w", err)
 	}
 
-	a.Ed25519Key = key
-	fmt.Println("   Ed25519 key generated and saved")
+	if err := a.useIdentity(id); err != nil {
+		return err
+	}
+	fmt.Println("   Identity generated and saved to", bundlePath)
 	return nil
 }
 
-// loadOrGenerateX25519Key loads X25519 key or generates a new one
-func (a *SimpleAgent) loadOrGenerateX25519Key() error {
-	keyPath := filepath.Join(a.KeyDir, "x25519.key")
-
-	// Try to load existing key
-	if keyData, err := os.ReadFile(keyPath); err == nil {
-		if len(keyData) == 32 {
-			a.X25519Key = keyData
-			fmt.Println("   X25519 key loaded from file")
-			return nil
+// useIdentity copies the bundle's DID and keys onto the agent
+func (a *SimpleAgent) useIdentity(id *sageagent.Identity) error {
+	a.DID = string(id.DID)
+	for _, kp := range id.Keys {
+		switch priv := kp.PrivateKey().(type) {
+		case *ecdsa.PrivateKey:
+			a.ECDSAKey = priv
+		case ed25519.PrivateKey:
+			a.Ed25519Key = priv
+		case *ecdh.PrivateKey:
+			a.X25519Key = priv.Bytes()
 		}
 	}
-
-	// Generate new key
-	fmt.Println("   Generating new X25519 key...")
-	key := make([]byte, 32)
-	if _, err := rand.Read(key); err != nil {
-		return fmt.Errorf("failed to generate X25519 key: %w", err)
-	}
-
-	// Save key
-	if err := os.WriteFile(keyPath, key, 0600); err != nil {
-		return fmt.Errorf("failed to save X25519 key: %w", err)
+	if a.ECDSAKey == nil || a.Ed25519Key == nil || a.X25519Key == nil {
+		return fmt.Errorf("identity bundle is missing a key")
 	}
-
-	a.X25519Key = key
-	fmt.Println("   X25519 key generated and saved")
 	return nil
 }
 
@@ -195,10 +140,11 @@ func (a *SimpleAgent) GetPublicKeys() ([]byte, []byte, []byte, error) {
 	ed25519PubKey := a.Ed25519Key.Public().(ed25519.PublicKey)
 
 	// X25519 public key
-	x25519PubKey, err := crypto.X25519PublicKeyFromPrivate(a.X25519Key)
+	x25519Priv, err := ecdh.X25519().NewPrivateKey(a.X25519Key)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to derive X25519 public key: %w", err)
 	}
+	x25519PubKey := x25519Priv.PublicKey().Bytes()
 
 	return ecdsaPubKey, ed25519PubKey, x25519PubKey, nil
 }
@@ -233,8 +179,8 @@ func (a *SimpleAgent) PrintInfo() {
 
 	fmt.Println("Keys:")
 	fmt.Printf("  ECDSA:        %d bytes (public key)\n", len(ecdsaPub))
-	fmt.Printf("  Ed25519:      %d bytes (private key)\n", len(a.Ed25519Key))
-	fmt.Printf("  X25519:       %d bytes (private key)\n", len(a.X25519Key))
+	fmt.Printf("  Ed25519:      %d bytes (public key)\n", len(ed25519Pub))
+	fmt.Printf("  X25519:       %d bytes (public key)\n", len(x25519Pub))
 	fmt.Println()
 }
 
@@ -280,7 +226,12 @@ func main() {
 
 	// Create agent
 	fmt.Println(" Creating Agent...")
-	agent, err := NewSimpleAgent(agentName, keyDir)
+	passphrase := []byte(os.Getenv("SAGE_BUNDLE_PASSPHRASE"))
+	if len(passphrase) == 0 {
+		fmt.Println(" SAGE_BUNDLE_PASSPHRASE not set, using an insecure demo passphrase")
+		passphrase = []byte("sage-demo-passphrase")
+	}
+	agent, err := NewSimpleAgent(agentName, keyDir, passphrase)
 	if err != nil {
 		fmt.Printf(" Failed to create agent: %v\n", err)
 		os.Exit(1)
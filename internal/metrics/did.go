@@ -0,0 +1,59 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// DIDResolverBreakerState tracks the circuit breaker state per resolver
+	DIDResolverBreakerState = promauto.With(Registry).NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "did_resolver",
+			Name:      "breaker_state",
+			Help:      "Circuit breaker state (0=closed, 1=open, 2=half-open)",
+		},
+		[]string{"resolver"},
+	)
+
+	// DIDResolverBreakerTransitions tracks circuit breaker state changes
+	DIDResolverBreakerTransitions = promauto.With(Registry).NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "did_resolver",
+			Name:      "breaker_transitions_total",
+			Help:      "Total number of circuit breaker state transitions",
+		},
+		[]string{"resolver", "from", "to"},
+	)
+
+	// DIDResolverFastFails tracks calls rejected while the breaker was open
+	DIDResolverFastFails = promauto.With(Registry).NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "did_resolver",
+			Name:      "fast_fail_total",
+			Help:      "Total number of resolve calls rejected by an open circuit breaker",
+		},
+		[]string{"resolver"},
+	)
+)
@@ -0,0 +1,35 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// RequestIDExemplarLabel is the exemplar label that carries a request ID
+const RequestIDExemplarLabel = "request_id"
+
+// ObserveWithRequestID records v on o, attaching requestID as an exemplar so
+// a slow bucket can be traced back to the request that landed in it. Without
+// an ID, or if o does not support exemplars, it is a plain Observe.
+func ObserveWithRequestID(o prometheus.Observer, v float64, requestID string) {
+	if eo, ok := o.(prometheus.ExemplarObserver); ok && requestID != "" {
+		eo.ObserveWithExemplar(v, prometheus.Labels{RequestIDExemplarLabel: requestID})
+		return
+	}
+	o.Observe(v)
+}
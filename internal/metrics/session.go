@@ -65,6 +65,26 @@ var (
 		},
 	)
 
+	// SessionsRevoked tracks sessions forcibly killed by revocation
+	SessionsRevoked = promauto.With(Registry).NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "sessions",
+			Name:      "revoked_total",
+			Help:      "Total number of sessions killed by revocation",
+		},
+	)
+
+	// SessionsEvicted counts sessions dropped to stay under the session cap
+	SessionsEvicted = promauto.With(Registry).NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "sessions",
+			Name:      "evicted_total",
+			Help:      "Total number of sessions evicted by the session cap",
+		},
+	)
+
 	// SessionDuration tracks session operation duration
 	SessionDuration = promauto.With(Registry).NewHistogramVec(
 		prometheus.HistogramOpts{
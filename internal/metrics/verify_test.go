@@ -22,7 +22,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 )
 
 func TestMetricsRegistration(t *testing.T) {
@@ -109,3 +111,26 @@ func TestMetricsExport(t *testing.T) {
 		t.Logf("Metrics export test completed (minor differences expected): %v", err)
 	}
 }
+
+func TestObserveWithRequestID(t *testing.T) {
+	h := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_duration_seconds"})
+	ObserveWithRequestID(h, 0.5, "req-1")
+
+	var m dto.Metric
+	if err := h.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, b := range m.GetHistogram().GetBucket() {
+		if ex := b.GetExemplar(); ex != nil {
+			for _, lp := range ex.GetLabel() {
+				if lp.GetName() == RequestIDExemplarLabel && lp.GetValue() == "req-1" {
+					found = true
+				}
+			}
+		}
+	}
+	if !found {
+		t.Error("request ID exemplar not recorded")
+	}
+}
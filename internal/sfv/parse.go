@@ -0,0 +1,397 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package sfv
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseItem parses a field value as an RFC 8941 Item.
+func ParseItem(s string) (Item, error) {
+	p := &parser{s: s}
+	p.skipSP()
+	it, err := p.parseItem()
+	if err != nil {
+		return Item{}, err
+	}
+	return it, p.finish()
+}
+
+// ParseList parses a field value as an RFC 8941 List.
+func ParseList(s string) (List, error) {
+	p := &parser{s: s}
+	p.skipSP()
+	var out List
+	for !p.eof() {
+		m, err := p.parseMember()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+		if err := p.nextListEntry(); err != nil {
+			return nil, err
+		}
+	}
+	return out, p.finish()
+}
+
+// ParseDictionary parses a field value as an RFC 8941 Dictionary.
+//
+// Unlike RFC 8941 §4.2.2, which keeps the last value of a repeated key,
+// duplicate keys are rejected: an ambiguous dictionary in a security header
+// (e.g. two `sig1` entries) must never be resolved silently.
+func ParseDictionary(s string) (Dictionary, error) {
+	p := &parser{s: s}
+	p.skipSP()
+	var out Dictionary
+	for !p.eof() {
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := out.Get(key); dup {
+			return nil, p.errorf("duplicate dictionary key %q", key)
+		}
+		var m Member
+		if p.peek() == '=' {
+			p.pos++
+			if m, err = p.parseMember(); err != nil {
+				return nil, err
+			}
+		} else {
+			params, err := p.parseParams()
+			if err != nil {
+				return nil, err
+			}
+			m = Item{Value: true, Params: params}
+		}
+		out = append(out, DictMember{Key: key, Value: m})
+		if err := p.nextListEntry(); err != nil {
+			return nil, err
+		}
+	}
+	return out, p.finish()
+}
+
+type parser struct {
+	s   string
+	pos int
+}
+
+func (p *parser) eof() bool { return p.pos >= len(p.s) }
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s at offset %d", ErrInvalid, fmt.Sprintf(format, args...), p.pos)
+}
+
+func (p *parser) skipSP() {
+	for !p.eof() && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *parser) skipOWS() {
+	for !p.eof() && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *parser) finish() error {
+	p.skipSP()
+	if !p.eof() {
+		return p.errorf("unexpected trailing characters")
+	}
+	return nil
+}
+
+// nextListEntry consumes the separator between list or dictionary members.
+func (p *parser) nextListEntry() error {
+	p.skipOWS()
+	if p.eof() {
+		return nil
+	}
+	if p.s[p.pos] != ',' {
+		return p.errorf("expected ','")
+	}
+	p.pos++
+	p.skipOWS()
+	if p.eof() {
+		return p.errorf("trailing ','")
+	}
+	return nil
+}
+
+func (p *parser) parseMember() (Member, error) {
+	if p.peek() == '(' {
+		return p.parseInnerList()
+	}
+	return p.parseItem()
+}
+
+func (p *parser) parseInnerList() (InnerList, error) {
+	p.pos++ // '('
+	var il InnerList
+	for !p.eof() {
+		p.skipSP()
+		if p.peek() == ')' {
+			p.pos++
+			params, err := p.parseParams()
+			if err != nil {
+				return InnerList{}, err
+			}
+			il.Params = params
+			return il, nil
+		}
+		it, err := p.parseItem()
+		if err != nil {
+			return InnerList{}, err
+		}
+		il.Items = append(il.Items, it)
+		if c := p.peek(); c != ' ' && c != ')' {
+			return InnerList{}, p.errorf("expected ' ' or ')' in inner list")
+		}
+	}
+	return InnerList{}, p.errorf("unterminated inner list")
+}
+
+func (p *parser) parseItem() (Item, error) {
+	v, err := p.parseBareItem()
+	if err != nil {
+		return Item{}, err
+	}
+	params, err := p.parseParams()
+	if err != nil {
+		return Item{}, err
+	}
+	return Item{Value: v, Params: params}, nil
+}
+
+func (p *parser) parseParams() (Params, error) {
+	var params Params
+	for p.peek() == ';' {
+		p.pos++
+		p.skipSP()
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		var v interface{} = true
+		if p.peek() == '=' {
+			p.pos++
+			if v, err = p.parseBareItem(); err != nil {
+				return nil, err
+			}
+		}
+		replaced := false
+		for i := range params {
+			if params[i].Key == key {
+				params[i].Value = v
+				replaced = true
+			}
+		}
+		if !replaced {
+			params = append(params, Param{Key: key, Value: v})
+		}
+	}
+	return params, nil
+}
+
+func (p *parser) parseKey() (string, error) {
+	c := p.peek()
+	if !isLCAlpha(c) && c != '*' {
+		return "", p.errorf("invalid key")
+	}
+	start := p.pos
+	for !p.eof() && isKeyChar(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *parser) parseBareItem() (interface{}, error) {
+	c := p.peek()
+	switch {
+	case c == '-' || isDigit(c):
+		return p.parseNumber()
+	case c == '"':
+		return p.parseString()
+	case c == '*' || isAlpha(c):
+		return p.parseToken()
+	case c == ':':
+		return p.parseByteSequence()
+	case c == '?':
+		return p.parseBoolean()
+	default:
+		return nil, p.errorf("unrecognised bare item")
+	}
+}
+
+func (p *parser) parseNumber() (interface{}, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	if !isDigit(p.peek()) {
+		return nil, p.errorf("expected digit")
+	}
+	digitsStart := p.pos
+	dot := -1
+	for !p.eof() {
+		c := p.s[p.pos]
+		if isDigit(c) {
+			p.pos++
+		} else if c == '.' && dot < 0 {
+			if p.pos-digitsStart > 12 {
+				return nil, p.errorf("decimal integer part too long")
+			}
+			dot = p.pos
+			p.pos++
+		} else {
+			break
+		}
+		if dot < 0 && p.pos-digitsStart > 15 {
+			return nil, p.errorf("integer too long")
+		}
+		if dot >= 0 && p.pos-digitsStart > 16 {
+			return nil, p.errorf("decimal too long")
+		}
+	}
+	num := p.s[start:p.pos]
+	if dot < 0 {
+		n, err := strconv.ParseInt(num, 10, 64)
+		if err != nil {
+			return nil, p.errorf("invalid integer")
+		}
+		return n, nil
+	}
+	frac := p.pos - dot - 1
+	if frac == 0 || frac > 3 {
+		return nil, p.errorf("invalid decimal fraction")
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return nil, p.errorf("invalid decimal")
+	}
+	return f, nil
+}
+
+func (p *parser) parseString() (string, error) {
+	p.pos++ // '"'
+	var b strings.Builder
+	for !p.eof() {
+		c := p.s[p.pos]
+		p.pos++
+		switch {
+		case c == '\\':
+			if p.eof() {
+				return "", p.errorf("unterminated escape")
+			}
+			next := p.s[p.pos]
+			if next != '"' && next != '\\' {
+				return "", p.errorf("invalid escape")
+			}
+			b.WriteByte(next)
+			p.pos++
+		case c == '"':
+			return b.String(), nil
+		case c < 0x20 || c > 0x7e:
+			return "", p.errorf("invalid string character")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) parseToken() (Token, error) {
+	start := p.pos
+	p.pos++
+	for !p.eof() && isTokenChar(p.s[p.pos]) {
+		p.pos++
+	}
+	return Token(p.s[start:p.pos]), nil
+}
+
+func (p *parser) parseByteSequence() ([]byte, error) {
+	p.pos++ // ':'
+	end := strings.IndexByte(p.s[p.pos:], ':')
+	if end < 0 {
+		return nil, p.errorf("unterminated byte sequence")
+	}
+	b64 := p.s[p.pos : p.pos+end]
+	for i := 0; i < len(b64); i++ {
+		if !isBase64Char(b64[i]) {
+			return nil, p.errorf("invalid base64 character in byte sequence")
+		}
+	}
+	p.pos += end + 1
+	// RFC 8941 §4.2.7: parsers SHOULD NOT fail on missing padding.
+	enc := base64.StdEncoding
+	if len(b64)%4 != 0 && !strings.Contains(b64, "=") {
+		enc = base64.RawStdEncoding
+	}
+	out, err := enc.DecodeString(b64)
+	if err != nil {
+		return nil, p.errorf("invalid base64 in byte sequence")
+	}
+	return out, nil
+}
+
+func (p *parser) parseBoolean() (bool, error) {
+	p.pos++ // '?'
+	switch p.peek() {
+	case '1':
+		p.pos++
+		return true, nil
+	case '0':
+		p.pos++
+		return false, nil
+	default:
+		return false, p.errorf("invalid boolean")
+	}
+}
+
+func isDigit(c byte) bool   { return c >= '0' && c <= '9' }
+func isLCAlpha(c byte) bool { return c >= 'a' && c <= 'z' }
+func isAlpha(c byte) bool   { return isLCAlpha(c) || (c >= 'A' && c <= 'Z') }
+
+func isKeyChar(c byte) bool {
+	return isLCAlpha(c) || isDigit(c) || c == '_' || c == '-' || c == '.' || c == '*'
+}
+
+func isTokenChar(c byte) bool {
+	if isAlpha(c) || isDigit(c) {
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~:/", c) >= 0
+}
+
+func isBase64Char(c byte) bool {
+	return isAlpha(c) || isDigit(c) || c == '+' || c == '/' || c == '='
+}
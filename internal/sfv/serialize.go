@@ -0,0 +1,231 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package sfv
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MarshalItem serializes an Item.
+func MarshalItem(it Item) (string, error) {
+	var b strings.Builder
+	if err := writeItem(&b, it); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// MarshalInnerList serializes an InnerList.
+func MarshalInnerList(il InnerList) (string, error) {
+	var b strings.Builder
+	if err := writeInnerList(&b, il); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// MarshalList serializes a List.
+func MarshalList(l List) (string, error) {
+	var b strings.Builder
+	for i, m := range l {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if err := writeMember(&b, m); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// MarshalDictionary serializes a Dictionary.
+func MarshalDictionary(d Dictionary) (string, error) {
+	var b strings.Builder
+	for i, m := range d {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if err := writeKey(&b, m.Key); err != nil {
+			return "", err
+		}
+		// A boolean true item is serialized as the bare key plus parameters.
+		if it, ok := m.Value.(Item); ok {
+			if v, ok := it.Value.(bool); ok && v {
+				if err := writeParams(&b, it.Params); err != nil {
+					return "", err
+				}
+				continue
+			}
+		}
+		b.WriteByte('=')
+		if err := writeMember(&b, m.Value); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+func writeMember(b *strings.Builder, m Member) error {
+	switch v := m.(type) {
+	case Item:
+		return writeItem(b, v)
+	case InnerList:
+		return writeInnerList(b, v)
+	default:
+		return fmt.Errorf("%w: unsupported member type %T", ErrInvalid, m)
+	}
+}
+
+func writeInnerList(b *strings.Builder, il InnerList) error {
+	b.WriteByte('(')
+	for i, it := range il.Items {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		if err := writeItem(b, it); err != nil {
+			return err
+		}
+	}
+	b.WriteByte(')')
+	return writeParams(b, il.Params)
+}
+
+func writeItem(b *strings.Builder, it Item) error {
+	if err := writeBareItem(b, it.Value); err != nil {
+		return err
+	}
+	return writeParams(b, it.Params)
+}
+
+func writeParams(b *strings.Builder, params Params) error {
+	for _, p := range params {
+		b.WriteByte(';')
+		if err := writeKey(b, p.Key); err != nil {
+			return err
+		}
+		if v, ok := p.Value.(bool); ok && v {
+			continue
+		}
+		b.WriteByte('=')
+		if err := writeBareItem(b, p.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeKey(b *strings.Builder, key string) error {
+	if key == "" || (!isLCAlpha(key[0]) && key[0] != '*') {
+		return fmt.Errorf("%w: invalid key %q", ErrInvalid, key)
+	}
+	for i := 1; i < len(key); i++ {
+		if !isKeyChar(key[i]) {
+			return fmt.Errorf("%w: invalid key %q", ErrInvalid, key)
+		}
+	}
+	b.WriteString(key)
+	return nil
+}
+
+func writeBareItem(b *strings.Builder, v interface{}) error {
+	switch x := v.(type) {
+	case int:
+		return writeInteger(b, int64(x))
+	case int64:
+		return writeInteger(b, x)
+	case float64:
+		return writeDecimal(b, x)
+	case string:
+		return writeString(b, x)
+	case Token:
+		return writeToken(b, x)
+	case []byte:
+		b.WriteByte(':')
+		b.WriteString(base64.StdEncoding.EncodeToString(x))
+		b.WriteByte(':')
+		return nil
+	case bool:
+		if x {
+			b.WriteString("?1")
+		} else {
+			b.WriteString("?0")
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unsupported bare item type %T", ErrInvalid, v)
+	}
+}
+
+func writeInteger(b *strings.Builder, n int64) error {
+	if n > 999_999_999_999_999 || n < -999_999_999_999_999 {
+		return fmt.Errorf("%w: integer out of range", ErrInvalid)
+	}
+	b.WriteString(strconv.FormatInt(n, 10))
+	return nil
+}
+
+func writeDecimal(b *strings.Builder, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("%w: decimal is not finite", ErrInvalid)
+	}
+	r := math.RoundToEven(f*1000) / 1000
+	if math.Abs(r) >= 1e12 {
+		return fmt.Errorf("%w: decimal out of range", ErrInvalid)
+	}
+	s := strconv.FormatFloat(r, 'f', 3, 64)
+	s = strings.TrimRight(s, "0")
+	if strings.HasSuffix(s, ".") {
+		s += "0"
+	}
+	b.WriteString(s)
+	return nil
+}
+
+func writeString(b *strings.Builder, s string) error {
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c > 0x7e {
+			return fmt.Errorf("%w: invalid string character 0x%02x", ErrInvalid, c)
+		}
+		if c == '"' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte('"')
+	return nil
+}
+
+func writeToken(b *strings.Builder, t Token) error {
+	if t == "" || (!isAlpha(t[0]) && t[0] != '*') {
+		return fmt.Errorf("%w: invalid token %q", ErrInvalid, string(t))
+	}
+	for i := 1; i < len(t); i++ {
+		if !isTokenChar(t[i]) {
+			return fmt.Errorf("%w: invalid token %q", ErrInvalid, string(t))
+		}
+	}
+	b.WriteString(string(t))
+	return nil
+}
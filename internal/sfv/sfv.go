@@ -0,0 +1,113 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+// Package sfv implements the Structured Field Values for HTTP (RFC 8941)
+// data model together with its parsing and serialization algorithms.
+//
+// It is used by the RFC 9421 signing code for the Signature-Input, Signature
+// and Content-Digest fields so that every producer and consumer in SAGE agrees
+// on a single canonical encoding (e.g. byte sequences are always `:base64:`
+// with the standard, padded alphabet).
+//
+// Bare item values are represented with the following Go types:
+//
+//	Integer       int64
+//	Decimal       float64
+//	String        string
+//	Token         Token
+//	Byte Sequence []byte
+//	Boolean       bool
+package sfv
+
+import "errors"
+
+// ErrInvalid is wrapped by every parse and serialization error.
+var ErrInvalid = errors.New("sfv: invalid structured field")
+
+// Token is an RFC 8941 token (an unquoted identifier such as `sha-256`).
+type Token string
+
+// Param is a single key/value parameter attached to an item or inner list.
+type Param struct {
+	Key   string
+	Value interface{}
+}
+
+// Params is an ordered set of parameters.
+type Params []Param
+
+// Get returns the value of the parameter with the given key.
+func (p Params) Get(key string) (interface{}, bool) {
+	for _, kv := range p {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Member is either an Item or an InnerList.
+type Member interface {
+	isMember()
+}
+
+// Item is a bare item with parameters.
+type Item struct {
+	Value  interface{}
+	Params Params
+}
+
+// InnerList is a parenthesised list of items with parameters.
+type InnerList struct {
+	Items  []Item
+	Params Params
+}
+
+func (Item) isMember()      {}
+func (InnerList) isMember() {}
+
+// List is an ordered list of members.
+type List []Member
+
+// DictMember is a single keyed entry of a Dictionary.
+type DictMember struct {
+	Key   string
+	Value Member
+}
+
+// Dictionary is an ordered map of keys to members.
+type Dictionary []DictMember
+
+// Get returns the member stored under key.
+func (d Dictionary) Get(key string) (Member, bool) {
+	for _, m := range d {
+		if m.Key == key {
+			return m.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Keys returns the dictionary keys in order.
+func (d Dictionary) Keys() []string {
+	keys := make([]string, len(d))
+	for i, m := range d {
+		keys[i] = m.Key
+	}
+	return keys
+}
@@ -0,0 +1,157 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package sfv
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestByteSequence(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		in := []byte{0x00, 0x01, 0xfe, 0xff, 'h', 'i'}
+		out, err := MarshalItem(Item{Value: in})
+		require.NoError(t, err)
+		assert.Equal(t, ":AAH+/2hp:", out)
+
+		it, err := ParseItem(out)
+		require.NoError(t, err)
+		assert.Equal(t, in, it.Value)
+	})
+
+	t.Run("missing padding is tolerated", func(t *testing.T) {
+		it, err := ParseItem(":aGVsbG8:")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("hello"), it.Value)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, in := range []string{":aGVsbG8=", ":not-base64!:", ":aGVsbG8=:x"} {
+			_, err := ParseItem(in)
+			assert.ErrorIs(t, err, ErrInvalid, in)
+		}
+	})
+}
+
+func TestParameterizedList(t *testing.T) {
+	in := `("@method" "@query-param";name="id" "content-digest");keyid="did:sage:ethereum:0xabc";created=1719234000, sha-256;q=0.5, ?0`
+
+	l, err := ParseList(in)
+	require.NoError(t, err)
+	require.Len(t, l, 3)
+
+	il, ok := l[0].(InnerList)
+	require.True(t, ok)
+	require.Len(t, il.Items, 3)
+	assert.Equal(t, "@method", il.Items[0].Value)
+	name, ok := il.Items[1].Params.Get("name")
+	require.True(t, ok)
+	assert.Equal(t, "id", name)
+	created, _ := il.Params.Get("created")
+	assert.Equal(t, int64(1719234000), created)
+
+	tok, ok := l[1].(Item)
+	require.True(t, ok)
+	assert.Equal(t, Token("sha-256"), tok.Value)
+	q, _ := tok.Params.Get("q")
+	assert.Equal(t, 0.5, q)
+
+	assert.Equal(t, false, l[2].(Item).Value)
+
+	out, err := MarshalList(l)
+	require.NoError(t, err)
+	assert.Equal(t, in, out)
+}
+
+func TestDictionary(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		in := `sig1=("@method");alg="ed25519", sha-512=:AQID:, flag;x=1`
+		d, err := ParseDictionary(in)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sig1", "sha-512", "flag"}, d.Keys())
+
+		v, ok := d.Get("flag")
+		require.True(t, ok)
+		assert.Equal(t, true, v.(Item).Value)
+
+		out, err := MarshalDictionary(d)
+		require.NoError(t, err)
+		assert.Equal(t, in, out)
+	})
+
+	t.Run("duplicate keys rejected", func(t *testing.T) {
+		_, err := ParseDictionary(`sig1=:AQID:, sig1=:BAUG:`)
+		assert.ErrorIs(t, err, ErrInvalid)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, in := range []string{
+			`Sig1=:AQID:`,
+			`sig1=:AQID:,`,
+			`sig1=("@method"`,
+			`sig1 = ("@method")`,
+			`sig1=("a""b")`,
+			`sig1="unterminated`,
+			`sig1="bad\escape"`,
+		} {
+			_, err := ParseDictionary(in)
+			assert.ErrorIs(t, err, ErrInvalid, in)
+		}
+	})
+}
+
+func TestSerializeRejectsInvalid(t *testing.T) {
+	cases := []Item{
+		{Value: "non-ascii é"},
+		{Value: Token("1abc")},
+		{Value: int64(1_000_000_000_000_000)},
+		{Value: struct{}{}},
+		{Value: "ok", Params: Params{{Key: "Upper", Value: true}}},
+	}
+	for _, it := range cases {
+		_, err := MarshalItem(it)
+		assert.True(t, errors.Is(err, ErrInvalid), "%#v", it)
+	}
+
+	out, err := MarshalItem(Item{Value: `quote " and \ slash`})
+	require.NoError(t, err)
+	assert.Equal(t, `"quote \" and \\ slash"`, out)
+}
+
+func TestNumbers(t *testing.T) {
+	it, err := ParseItem("-42")
+	require.NoError(t, err)
+	assert.Equal(t, int64(-42), it.Value)
+
+	it, err = ParseItem("3.140")
+	require.NoError(t, err)
+	assert.Equal(t, 3.14, it.Value)
+
+	out, err := MarshalItem(Item{Value: 2.0})
+	require.NoError(t, err)
+	assert.Equal(t, "2.0", out)
+
+	for _, in := range []string{"1234567890123456", "1.2345", "1.", "-"} {
+		_, err := ParseItem(in)
+		assert.ErrorIs(t, err, ErrInvalid, in)
+	}
+}
@@ -0,0 +1,374 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+// Package agent holds helpers that operate on an agent's identity as a whole
+// rather than on a single key or document.
+package agent
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/formats"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Bundle layout constants.
+const (
+	bundleMagic      = "SAGEBNDL"
+	bundleVersion    = 1
+	bundleSaltSize   = 32
+	bundleIterations = 600000
+
+	// bundleHeaderSize is magic | version | iterations | salt | nonce.
+	bundleHeaderSize = len(bundleMagic) + 1 + 4 + bundleSaltSize + 12
+
+	manifestFile = "manifest.json"
+	didFile      = "did.txt"
+	cardFile     = "card.json"
+	keysDir      = "keys"
+
+	// maxBundleEntrySize bounds a single archive entry when importing.
+	maxBundleEntrySize = 1 << 20
+)
+
+var (
+	// ErrInvalidBundle is returned when data is not a SAGE identity bundle.
+	ErrInvalidBundle = errors.New("invalid identity bundle")
+
+	// ErrBundleDecryption is returned when a bundle cannot be decrypted,
+	// either because the passphrase is wrong or the bundle was modified.
+	ErrBundleDecryption = errors.New("failed to decrypt identity bundle: wrong passphrase or corrupted data")
+
+	// ErrEmptyPassphrase is returned when no passphrase is supplied.
+	ErrEmptyPassphrase = errors.New("passphrase must not be empty")
+)
+
+// Identity is the complete identity of an agent: its DID, every keypair it
+// holds and, optionally, its A2A card.
+type Identity struct {
+	DID  did.AgentDID
+	Keys []sagecrypto.KeyPair
+	Card *did.A2AAgentCard
+}
+
+// bundleManifest describes the archive contents.
+type bundleManifest struct {
+	Version   int         `json:"version"`
+	DID       string      `json:"did"`
+	Keys      []bundleKey `json:"keys"`
+	HasCard   bool        `json:"hasCard"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+type bundleKey struct {
+	ID   string             `json:"id"`
+	Type sagecrypto.KeyType `json:"type"`
+	File string             `json:"file"`
+}
+
+// ExportBundle serializes the identity into a single encrypted archive.
+//
+// The archive is a gzipped tarball holding a manifest, the DID string, the
+// A2A card JSON and one PEM file per private key. The whole tarball is
+// sealed with AES-256-GCM under a key derived from passphrase with
+// PBKDF2-SHA256; the bundle header is bound as additional data.
+func (id *Identity) ExportBundle(passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrEmptyPassphrase
+	}
+	if id.DID == "" {
+		return nil, fmt.Errorf("identity has no DID")
+	}
+
+	archive, err := id.marshalArchive()
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, bundleHeaderSize)
+	off := copy(header, bundleMagic)
+	header[off] = bundleVersion
+	off++
+	binary.BigEndian.PutUint32(header[off:], bundleIterations)
+	off += 4
+	if _, err := rand.Read(header[off:]); err != nil {
+		return nil, fmt.Errorf("failed to generate salt and nonce: %w", err)
+	}
+
+	aead, err := bundleAEAD(passphrase, header)
+	if err != nil {
+		return nil, err
+	}
+	nonce := header[bundleHeaderSize-aead.NonceSize():]
+
+	return aead.Seal(header, nonce, archive, header), nil
+}
+
+// ImportBundle decrypts a bundle produced by ExportBundle and restores the
+// identity. Key IDs are preserved.
+func ImportBundle(data, passphrase []byte) (*Identity, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrEmptyPassphrase
+	}
+	if len(data) < bundleHeaderSize || string(data[:len(bundleMagic)]) != bundleMagic {
+		return nil, ErrInvalidBundle
+	}
+	if v := data[len(bundleMagic)]; v != bundleVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidBundle, v)
+	}
+
+	header := data[:bundleHeaderSize]
+	aead, err := bundleAEAD(passphrase, header)
+	if err != nil {
+		return nil, err
+	}
+	nonce := header[bundleHeaderSize-aead.NonceSize():]
+
+	archive, err := aead.Open(nil, nonce, data[bundleHeaderSize:], header)
+	if err != nil {
+		return nil, ErrBundleDecryption
+	}
+
+	return unmarshalArchive(archive)
+}
+
+// bundleAEAD derives the bundle key from the passphrase and the header's
+// iteration count and salt.
+func bundleAEAD(passphrase, header []byte) (cipher.AEAD, error) {
+	off := len(bundleMagic) + 1
+	iterations := binary.BigEndian.Uint32(header[off:])
+	if iterations == 0 || iterations > 10*bundleIterations {
+		return nil, fmt.Errorf("%w: unreasonable iteration count %d", ErrInvalidBundle, iterations)
+	}
+	salt := header[off+4 : off+4+bundleSaltSize]
+
+	key := pbkdf2.Key(passphrase, salt, int(iterations), 32, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return aead, nil
+}
+
+// marshalArchive writes the identity as a gzipped tarball.
+func (id *Identity) marshalArchive() ([]byte, error) {
+	manifest := bundleManifest{
+		Version:   bundleVersion,
+		DID:       string(id.DID),
+		HasCard:   id.Card != nil,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	files := make(map[string][]byte)
+	exporter := formats.NewPEMExporter()
+	for i, kp := range id.Keys {
+		if kp == nil {
+			return nil, fmt.Errorf("key %d is nil", i)
+		}
+		pemData, err := exporter.Export(kp, sagecrypto.KeyFormatPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s key %s: %w", kp.Type(), kp.ID(), err)
+		}
+		name := path.Join(keysDir, fmt.Sprintf("%02d-%s.pem", i, kp.Type()))
+		files[name] = pemData
+		manifest.Keys = append(manifest.Keys, bundleKey{ID: kp.ID(), Type: kp.Type(), File: name})
+	}
+
+	if id.Card != nil {
+		cardJSON, err := json.MarshalIndent(id.Card, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal card: %w", err)
+		}
+		files[cardFile] = cardJSON
+	}
+	files[didFile] = []byte(id.DID)
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	// Manifest first, then the remaining entries in manifest order
+	order := []string{manifestFile, didFile}
+	if id.Card != nil {
+		order = append(order, cardFile)
+	}
+	for _, k := range manifest.Keys {
+		order = append(order, k.File)
+	}
+	files[manifestFile] = manifestJSON
+
+	for _, name := range order {
+		content := files[name]
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    0600,
+			Size:    int64(len(content)),
+			ModTime: manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalArchive reads an identity from a gzipped tarball.
+func unmarshalArchive(archive []byte) (*Identity, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if hdr.Size > maxBundleEntrySize {
+			return nil, fmt.Errorf("%w: entry %s too large", ErrInvalidBundle, hdr.Name)
+		}
+		content, err := io.ReadAll(io.LimitReader(tr, maxBundleEntrySize))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+		files[hdr.Name] = content
+	}
+
+	manifestJSON, ok := files[manifestFile]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidBundle, manifestFile)
+	}
+	var manifest bundleManifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: bad manifest: %v", ErrInvalidBundle, err)
+	}
+
+	didStr := string(files[didFile])
+	if didStr == "" || didStr != manifest.DID {
+		return nil, fmt.Errorf("%w: DID missing or inconsistent with manifest", ErrInvalidBundle)
+	}
+
+	id := &Identity{DID: did.AgentDID(didStr)}
+
+	importer := formats.NewPEMImporter()
+	for _, k := range manifest.Keys {
+		pemData, ok := files[k.File]
+		if !ok {
+			return nil, fmt.Errorf("%w: missing key file %s", ErrInvalidBundle, k.File)
+		}
+		kp, err := importer.Import(pemData, sagecrypto.KeyFormatPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import key %s: %w", k.ID, err)
+		}
+		if kp.Type() != k.Type {
+			return nil, fmt.Errorf("%w: key %s is %s, manifest says %s", ErrInvalidBundle, k.ID, kp.Type(), k.Type)
+		}
+		if kp, err = withKeyID(kp, k.ID); err != nil {
+			return nil, err
+		}
+		id.Keys = append(id.Keys, kp)
+	}
+
+	if manifest.HasCard {
+		cardJSON, ok := files[cardFile]
+		if !ok {
+			return nil, fmt.Errorf("%w: missing %s", ErrInvalidBundle, cardFile)
+		}
+		var card did.A2AAgentCard
+		if err := json.Unmarshal(cardJSON, &card); err != nil {
+			return nil, fmt.Errorf("%w: bad card: %v", ErrInvalidBundle, err)
+		}
+		id.Card = &card
+	}
+
+	return id, nil
+}
+
+// withKeyID rebuilds kp with the given ID; the PEM importer always derives
+// IDs from the public key, which would lose custom IDs.
+func withKeyID(kp sagecrypto.KeyPair, id string) (sagecrypto.KeyPair, error) {
+	if id == "" || kp.ID() == id {
+		return kp, nil
+	}
+	switch priv := kp.PrivateKey().(type) {
+	case ed25519.PrivateKey:
+		return keys.NewEd25519KeyPair(priv, id)
+	case *ecdh.PrivateKey:
+		return keys.NewX25519KeyPair(priv, id)
+	case *rsa.PrivateKey:
+		return keys.NewRSAKeyPair(priv, id)
+	case *ecdsa.PrivateKey:
+		if kp.Type() == sagecrypto.KeyTypeSecp256k1 {
+			var scalar [32]byte
+			priv.D.FillBytes(scalar[:])
+			return keys.NewSecp256k1KeyPair(secp256k1.PrivKeyFromBytes(scalar[:]), id)
+		}
+		return keys.NewP256KeyPair(priv, id)
+	default:
+		return nil, fmt.Errorf("cannot restore ID for %s key", kp.Type())
+	}
+}
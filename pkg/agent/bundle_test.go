@@ -0,0 +1,140 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package agent
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIdentity(t *testing.T) *Identity {
+	t.Helper()
+
+	ed, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	k1, err := keys.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+	x, err := keys.GenerateX25519KeyPair()
+	require.NoError(t, err)
+	p, err := keys.GenerateP256KeyPair()
+	require.NoError(t, err)
+	// Custom IDs must survive the round trip
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	custom, err := keys.NewEd25519KeyPair(edPriv, "signing-2025")
+	require.NoError(t, err)
+
+	agentDID := did.AgentDID("did:sage:ethereum:0x1234567890abcdef")
+	now := time.Now().UTC().Truncate(time.Second)
+	return &Identity{
+		DID:  agentDID,
+		Keys: []sagecrypto.KeyPair{ed, k1, x, p, custom},
+		Card: &did.A2AAgentCard{
+			Context: []string{"https://www.w3.org/ns/did/v1"},
+			ID:      string(agentDID),
+			Type:    []string{"Agent"},
+			Name:    "backup-agent",
+			Endpoints: []did.A2AEndpoint{
+				{Type: "HTTPS", URI: "https://agent.example.com"},
+			},
+			Created: now,
+			Updated: now,
+		},
+	}
+}
+
+func TestBundle_RoundTrip(t *testing.T) {
+	orig := newTestIdentity(t)
+	pass := []byte("correct horse battery staple")
+
+	data, err := orig.ExportBundle(pass)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), string(orig.DID), "bundle must be encrypted")
+
+	restored, err := ImportBundle(data, pass)
+	require.NoError(t, err)
+
+	assert.Equal(t, orig.DID, restored.DID)
+	assert.Equal(t, orig.Card, restored.Card)
+	require.Len(t, restored.Keys, len(orig.Keys))
+	for i, kp := range orig.Keys {
+		got := restored.Keys[i]
+		assert.Equal(t, kp.Type(), got.Type())
+		assert.Equal(t, kp.ID(), got.ID())
+		assert.Equal(t, kp.PublicKey(), got.PublicKey())
+
+		if kp.Type() == sagecrypto.KeyTypeX25519 {
+			continue
+		}
+		sig, err := got.Sign([]byte("backup"))
+		require.NoError(t, err)
+		assert.NoError(t, kp.Verify([]byte("backup"), sig))
+	}
+
+	t.Run("without card", func(t *testing.T) {
+		id := &Identity{DID: orig.DID, Keys: orig.Keys[:1]}
+		data, err := id.ExportBundle(pass)
+		require.NoError(t, err)
+
+		restored, err := ImportBundle(data, pass)
+		require.NoError(t, err)
+		assert.Nil(t, restored.Card)
+		assert.Len(t, restored.Keys, 1)
+	})
+}
+
+func TestBundle_WrongPassphrase(t *testing.T) {
+	data, err := newTestIdentity(t).ExportBundle([]byte("right"))
+	require.NoError(t, err)
+
+	_, err = ImportBundle(data, []byte("wrong"))
+	assert.ErrorIs(t, err, ErrBundleDecryption)
+
+	t.Run("tampered ciphertext", func(t *testing.T) {
+		tampered := append([]byte(nil), data...)
+		tampered[len(tampered)-1] ^= 0x01
+		_, err := ImportBundle(tampered, []byte("right"))
+		assert.ErrorIs(t, err, ErrBundleDecryption)
+	})
+
+	t.Run("tampered header", func(t *testing.T) {
+		tampered := append([]byte(nil), data...)
+		tampered[len(bundleMagic)+10] ^= 0x01 // salt byte
+		_, err := ImportBundle(tampered, []byte("right"))
+		assert.ErrorIs(t, err, ErrBundleDecryption)
+	})
+
+	t.Run("not a bundle", func(t *testing.T) {
+		_, err := ImportBundle([]byte("hello"), []byte("right"))
+		assert.ErrorIs(t, err, ErrInvalidBundle)
+	})
+
+	t.Run("empty passphrase", func(t *testing.T) {
+		_, err := ImportBundle(data, nil)
+		assert.ErrorIs(t, err, ErrEmptyPassphrase)
+	})
+}
@@ -0,0 +1,123 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sage-x-project/sage/pkg/agent/did"
+)
+
+// AuthzRequest describes an authenticated request for an authorization
+// decision. DID has already been proven by the request signature.
+type AuthzRequest struct {
+	DID    did.AgentDID
+	Agent  *did.AgentMetadata // Resolved agent metadata
+	Method string
+	Path   string
+	Header http.Header
+	Time   time.Time // When the request was received
+	// RequestID correlates the request across logs and services
+	RequestID string
+}
+
+// AuthzDecision is the outcome of an AuthzPolicy
+type AuthzDecision struct {
+	Allow  bool
+	Reason string // Why the request was denied; returned to the caller
+}
+
+// Allow permits a request
+func Allow() AuthzDecision {
+	return AuthzDecision{Allow: true}
+}
+
+// Deny rejects a request with reason
+func Deny(reason string) AuthzDecision {
+	return AuthzDecision{Reason: reason}
+}
+
+// AuthzPolicy decides whether an authenticated agent may perform a request.
+// It runs after signature verification, so a denial means the caller is
+// known but not permitted (HTTP 403), as opposed to unauthenticated (401).
+//
+// Policies can express rules beyond capabilities, e.g. business hours:
+//
+//	core.AuthzPolicyFunc(func(ctx context.Context, req *core.AuthzRequest) core.AuthzDecision {
+//	    if h := req.Time.Hour(); h < 9 || h >= 18 {
+//	        return core.Deny("outside business hours")
+//	    }
+//	    return core.Allow()
+//	})
+type AuthzPolicy interface {
+	Authorize(ctx context.Context, req *AuthzRequest) AuthzDecision
+}
+
+// AuthzPolicyFunc adapts a function to AuthzPolicy
+type AuthzPolicyFunc func(ctx context.Context, req *AuthzRequest) AuthzDecision
+
+// Authorize calls f
+func (f AuthzPolicyFunc) Authorize(ctx context.Context, req *AuthzRequest) AuthzDecision {
+	return f(ctx, req)
+}
+
+// AllowAll returns a policy that permits every authenticated request
+func AllowAll() AuthzPolicy {
+	return AuthzPolicyFunc(func(context.Context, *AuthzRequest) AuthzDecision {
+		return Allow()
+	})
+}
+
+// AllowListPolicy permits each DID to call only the listed paths. A path
+// ending in "/" matches it and everything below it and "*" matches any path.
+// Paths are compared after path.Clean, on whole segments, so "/tools/"
+// matches "/tools/search" but neither "/toolsx" nor "/tools/../admin". DIDs
+// without an entry are denied.
+//
+// HTTPMiddleware requires signatures to cover "@method" and "@path" when a
+// policy is set, so an allowed request cannot be replayed to another route.
+type AllowListPolicy struct {
+	Rules map[did.AgentDID][]string
+}
+
+// Authorize permits req if its path is on the DID's allow list
+func (p *AllowListPolicy) Authorize(_ context.Context, req *AuthzRequest) AuthzDecision {
+	paths, ok := p.Rules[req.DID]
+	if !ok {
+		return Deny("agent is not on the allow list")
+	}
+	reqPath := path.Clean("/" + req.Path)
+	for _, allowed := range paths {
+		if allowed == "*" {
+			return Allow()
+		}
+		prefix := path.Clean("/" + allowed)
+		switch {
+		case reqPath == prefix:
+			return Allow()
+		case strings.HasSuffix(allowed, "/") && strings.HasPrefix(reqPath, strings.TrimSuffix(prefix, "/")+"/"):
+			return Allow()
+		}
+	}
+	return Deny("agent may not call " + req.Path)
+}
@@ -0,0 +1,143 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+)
+
+const batchItemLabel = "sage/batch-item/v1"
+
+// BatchItem is one independently signed entry of a batched request, such as
+// a single tool call in an MCP batch. Each item names its own agent, so one
+// request may carry calls from several agents.
+type BatchItem struct {
+	ID        string          `json:"id"`
+	AgentDID  string          `json:"agent_did"`
+	Payload   json.RawMessage `json:"payload"`
+	Signature []byte          `json:"signature"` // see SignBatchItem
+}
+
+// BatchItemResult is the outcome for one BatchItem. Only items with
+// Authorized set should be executed.
+type BatchItemResult struct {
+	ID         string `json:"id"`
+	AgentDID   string `json:"agent_did"`
+	Authorized bool   `json:"authorized"`
+	Error      string `json:"error,omitempty"`
+}
+
+// BatchAuthorizer decides whether a verified agent may perform item, e.g. by
+// checking its capabilities. A nil BatchAuthorizer authorizes every item with
+// a valid signature from an active agent.
+type BatchAuthorizer func(ctx context.Context, agent *did.AgentMetadata, item BatchItem) error
+
+// SignBatchItem signs item with the agent's key and sets item.Signature. The
+// signature covers the agent DID, the item ID and the payload, so it cannot
+// be moved to another item or attributed to another agent.
+func SignBatchItem(kp crypto.KeyPair, item *BatchItem) error {
+	if item == nil {
+		return errors.New("batch item is required")
+	}
+	sig, err := signDIDMessage(kp, batchItemMessage(*item), keys.SigningContextBatchItem)
+	if err != nil {
+		return fmt.Errorf("sign batch item %q: %w", item.ID, err)
+	}
+	item.Signature = sig
+	return nil
+}
+
+// VerifyBatch verifies every item of a batch on its own and returns one result
+// per item, in order. A failing item never affects the others: valid items
+// proceed and invalid ones are rejected individually. Each agent DID is
+// resolved once per batch, and duplicate item IDs are rejected after the first.
+func (s *VerificationService) VerifyBatch(ctx context.Context, items []BatchItem, authorize BatchAuthorizer) []BatchItemResult {
+	type resolved struct {
+		agent *did.AgentMetadata
+		err   error
+	}
+	agents := make(map[string]resolved)
+	seen := make(map[string]bool, len(items))
+	results := make([]BatchItemResult, len(items))
+
+	for i, item := range items {
+		results[i] = BatchItemResult{ID: item.ID, AgentDID: item.AgentDID}
+		reject := func(err error) { results[i].Error = err.Error() }
+
+		switch {
+		case item.ID == "":
+			reject(errors.New("missing item id"))
+			continue
+		case seen[item.ID]:
+			reject(fmt.Errorf("duplicate item id %q", item.ID))
+			continue
+		case item.AgentDID == "":
+			reject(errors.New("missing agent DID"))
+			continue
+		}
+		seen[item.ID] = true
+
+		r, ok := agents[item.AgentDID]
+		if !ok {
+			r.agent, r.err = s.didResolver.ResolveAgent(ctx, did.AgentDID(item.AgentDID))
+			agents[item.AgentDID] = r
+		}
+		if r.err != nil {
+			reject(fmt.Errorf("failed to resolve agent DID: %w", r.err))
+			continue
+		}
+		if !r.agent.IsActive {
+			reject(errors.New("agent is deactivated"))
+			continue
+		}
+		if err := verifyDIDMessage(r.agent.PublicKey, batchItemMessage(item), item.Signature, keys.SigningContextBatchItem); err != nil {
+			reject(fmt.Errorf("invalid signature: %w", err))
+			continue
+		}
+		if authorize != nil {
+			if err := authorize(ctx, r.agent, item); err != nil {
+				reject(fmt.Errorf("not authorized: %w", err))
+				continue
+			}
+		}
+		results[i].Authorized = true
+	}
+	return results
+}
+
+// batchItemMessage builds the bytes signed for a batch item.
+func batchItemMessage(item BatchItem) []byte {
+	var b bytes.Buffer
+	b.WriteString(batchItemLabel)
+	b.WriteByte(0)
+	b.WriteString(item.AgentDID)
+	b.WriteByte(0)
+	b.WriteString(item.ID)
+	b.WriteByte(0)
+	b.Write(item.Payload)
+	return b.Bytes()
+}
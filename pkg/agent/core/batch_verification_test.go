@@ -0,0 +1,119 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+)
+
+func TestVerifyBatch(t *testing.T) {
+	ctx := context.Background()
+
+	trustedKey, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	secpKey, err := keys.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+	untrustedKey, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+
+	const (
+		trustedDID   = "did:sage:ethereum:trusted"
+		secpDID      = "did:sage:ethereum:secp-agent"
+		untrustedDID = "did:sage:ethereum:untrusted"
+	)
+	service := NewVerificationService(staticKeyResolver{
+		trustedDID: trustedKey.PublicKey(),
+		secpDID:    secpKey.PublicKey(),
+	})
+
+	signed := func(t *testing.T, kp crypto.KeyPair, agentDID, id, payload string) BatchItem {
+		item := BatchItem{ID: id, AgentDID: agentDID, Payload: json.RawMessage(payload)}
+		require.NoError(t, SignBatchItem(kp, &item))
+		return item
+	}
+
+	t.Run("Mixed batch yields per-item outcomes", func(t *testing.T) {
+		items := []BatchItem{
+			signed(t, trustedKey, trustedDID, "1", `{"tool":"calculator","operation":"add"}`),
+			signed(t, untrustedKey, untrustedDID, "2", `{"tool":"calculator","operation":"add"}`),
+			signed(t, secpKey, secpDID, "3", `{"tool":"calculator","operation":"divide"}`),
+		}
+
+		results := service.VerifyBatch(ctx, items, nil)
+		require.Len(t, results, 3)
+
+		assert.True(t, results[0].Authorized)
+		assert.Empty(t, results[0].Error)
+		assert.Equal(t, "2", results[1].ID)
+		assert.Equal(t, untrustedDID, results[1].AgentDID)
+		assert.False(t, results[1].Authorized)
+		assert.Contains(t, results[1].Error, "failed to resolve agent DID")
+		assert.True(t, results[2].Authorized)
+	})
+
+	t.Run("Items cannot be tampered, moved or replayed", func(t *testing.T) {
+		tampered := signed(t, trustedKey, trustedDID, "a", `{"operation":"add"}`)
+		tampered.Payload = json.RawMessage(`{"operation":"delete"}`)
+
+		moved := signed(t, trustedKey, trustedDID, "b", `{"operation":"add"}`)
+		moved.ID = "c"
+
+		impersonated := signed(t, untrustedKey, trustedDID, "d", `{"operation":"add"}`)
+
+		ok := signed(t, trustedKey, trustedDID, "e", `{"operation":"add"}`)
+		replayed := ok
+
+		results := service.VerifyBatch(ctx, []BatchItem{tampered, moved, impersonated, ok, replayed}, nil)
+		for _, i := range []int{0, 1, 2} {
+			assert.False(t, results[i].Authorized, results[i].ID)
+			assert.Contains(t, results[i].Error, "invalid signature", results[i].ID)
+		}
+		assert.True(t, results[3].Authorized)
+		assert.False(t, results[4].Authorized)
+		assert.Contains(t, results[4].Error, "duplicate item id")
+	})
+
+	t.Run("Authorizer rejects individual items", func(t *testing.T) {
+		items := []BatchItem{
+			signed(t, trustedKey, trustedDID, "1", `{"operation":"add"}`),
+			signed(t, secpKey, secpDID, "2", `{"operation":"add"}`),
+		}
+		onlyTrusted := func(_ context.Context, agent *did.AgentMetadata, _ BatchItem) error {
+			if agent.DID != trustedDID {
+				return errors.New("agent lacks calculator capability")
+			}
+			return nil
+		}
+
+		results := service.VerifyBatch(ctx, items, onlyTrusted)
+		assert.True(t, results[0].Authorized)
+		assert.False(t, results[1].Authorized)
+		assert.Contains(t, results[1].Error, "calculator capability")
+	})
+}
@@ -0,0 +1,269 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+)
+
+// ControlChallengePath is appended to an agent endpoint to reach its
+// control-proof handler.
+const ControlChallengePath = "/.well-known/sage/control"
+
+const (
+	controlProofLabel   = "sage/control-proof/v1"
+	controlNonceSize    = 32
+	maxControlBodyBytes = 4096
+)
+
+// controlChallenge is the request body sent to ControlChallengePath.
+type controlChallenge struct {
+	DID      string `json:"did"`
+	Endpoint string `json:"endpoint"`
+	Nonce    string `json:"nonce"` // base64url, 32 random bytes
+}
+
+// controlResponse carries the agent's signature over the challenge.
+type controlResponse struct {
+	Signature string `json:"signature"` // base64url
+}
+
+// ControlProver checks that the agent answering at an endpoint holds the
+// private key registered for a DID, not merely that the key is registered.
+type ControlProver struct {
+	resolver DIDResolver
+	client   *http.Client
+}
+
+// NewControlProver creates a prover that resolves keys through resolver.
+// A nil client uses a client with a 10 second timeout.
+func NewControlProver(resolver DIDResolver, client *http.Client) *ControlProver {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &ControlProver{resolver: resolver, client: client}
+}
+
+// ProveControl sends a fresh random nonce to endpoint and verifies the
+// returned signature against the public key registered for expectedDID.
+//
+// It returns false with a nil error when the agent answered but the proof
+// does not verify (e.g. a cloned card pointing at an impostor endpoint), and
+// a non-nil error when the check could not be completed.
+//
+// The challenge names endpoint and the genuine agent's handler refuses to
+// sign for any endpoint it is not configured with, so an impostor relaying
+// the challenge to it gets no usable proof.
+func (p *ControlProver) ProveControl(ctx context.Context, endpoint, expectedDID string) (bool, error) {
+	if endpoint == "" || expectedDID == "" {
+		return false, fmt.Errorf("endpoint and expected DID are required")
+	}
+
+	pub, err := p.resolver.ResolvePublicKey(ctx, did.AgentDID(expectedDID))
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve public key: %w", err)
+	}
+
+	nonce := make([]byte, controlNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return false, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	challenge := controlChallenge{
+		DID:      expectedDID,
+		Endpoint: endpoint,
+		Nonce:    base64.RawURLEncoding.EncodeToString(nonce),
+	}
+
+	sig, err := p.requestSignature(ctx, endpoint, challenge)
+	if err != nil {
+		return false, err
+	}
+
+	if err := verifyControlSignature(pub, controlMessage(challenge.DID, challenge.Endpoint, nonce), sig); err != nil {
+		if errors.Is(err, crypto.ErrInvalidSignature) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// ProveControl checks that the agent at endpoint controls expectedDID's key.
+// See ControlProver.ProveControl.
+func (c *Core) ProveControl(ctx context.Context, endpoint, expectedDID string) (bool, error) {
+	return NewControlProver(c.didManager, nil).ProveControl(ctx, endpoint, expectedDID)
+}
+
+func (p *ControlProver) requestSignature(ctx context.Context, endpoint string, challenge controlChallenge) ([]byte, error) {
+	body, err := json.Marshal(challenge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal challenge: %w", err)
+	}
+
+	url := strings.TrimRight(endpoint, "/") + ControlChallengePath
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create challenge request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("challenge request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("challenge request failed: HTTP %d", resp.StatusCode)
+	}
+
+	var out controlResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxControlBodyBytes)).Decode(&out); err != nil {
+		return nil, fmt.Errorf("invalid challenge response: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(out.Signature)
+	if err != nil || len(sig) == 0 {
+		return nil, fmt.Errorf("invalid challenge response signature encoding")
+	}
+	return sig, nil
+}
+
+// ControlChallengeHandler answers ProveControl challenges for agentDID by
+// signing them with kp. Mount it at ControlChallengePath under each of the
+// agent's endpoints and list those endpoints here, as they appear in the
+// agent card. Challenges naming another DID or endpoint are rejected with 403,
+// so an impostor cannot relay a challenge for its own endpoint to the agent
+// and pass the proof off as its own.
+//
+// Signatures are domain-separated (Ed25519ctx for Ed25519 keys, a labelled
+// message for secp256k1), so the handler cannot be used as a signing oracle
+// for handshakes, registrations or other SAGE messages.
+func ControlChallengeHandler(kp crypto.KeyPair, agentDID string, endpoints ...string) http.Handler {
+	own := make(map[string]bool, len(endpoints))
+	for _, e := range endpoints {
+		own[strings.TrimRight(e, "/")] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var challenge controlChallenge
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxControlBodyBytes)).Decode(&challenge); err != nil {
+			http.Error(w, "invalid challenge", http.StatusBadRequest)
+			return
+		}
+		nonce, err := base64.RawURLEncoding.DecodeString(challenge.Nonce)
+		if err != nil || len(nonce) != controlNonceSize || challenge.DID == "" {
+			http.Error(w, "invalid challenge", http.StatusBadRequest)
+			return
+		}
+		if challenge.DID != agentDID || !own[strings.TrimRight(challenge.Endpoint, "/")] {
+			http.Error(w, "challenge is not for this agent", http.StatusForbidden)
+			return
+		}
+
+		sig, err := signControl(kp, controlMessage(challenge.DID, challenge.Endpoint, nonce))
+		if err != nil {
+			http.Error(w, "signing failed", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(controlResponse{
+			Signature: base64.RawURLEncoding.EncodeToString(sig),
+		})
+	})
+}
+
+// controlMessage builds the bytes signed for a control proof.
+func controlMessage(agentDID, endpoint string, nonce []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString(controlProofLabel)
+	b.WriteByte(0)
+	b.WriteString(agentDID)
+	b.WriteByte(0)
+	b.WriteString(endpoint)
+	b.WriteByte(0)
+	b.Write(nonce)
+	return b.Bytes()
+}
+
+func signControl(kp crypto.KeyPair, msg []byte) ([]byte, error) {
+	return signDIDMessage(kp, msg, keys.SigningContextControlProof)
+}
+
+// verifyControlSignature verifies sig with a resolved DID public key. It
+// returns crypto.ErrInvalidSignature when the signature does not match.
+func verifyControlSignature(pub interface{}, msg, sig []byte) error {
+	return verifyDIDMessage(pub, msg, sig, keys.SigningContextControlProof)
+}
+
+// signDIDMessage signs msg with a DID key. Ed25519 keys sign under the
+// Ed25519ctx context so the signature is bound to one protocol.
+func signDIDMessage(kp crypto.KeyPair, msg []byte, context string) ([]byte, error) {
+	if kp.Type() == crypto.KeyTypeEd25519 {
+		return keys.SignWithContext(kp, msg, []byte(context))
+	}
+	return kp.Sign(msg)
+}
+
+// verifyDIDMessage verifies a signature made by signDIDMessage with a
+// resolved DID public key.
+func verifyDIDMessage(pub interface{}, msg, sig []byte, context string) error {
+	if kp, ok := pub.(crypto.KeyPair); ok {
+		pub = kp.PublicKey()
+	}
+
+	switch pk := pub.(type) {
+	case ed25519.PublicKey:
+		return keys.VerifyWithContext(pk, msg, sig, []byte(context))
+	case *ecdsa.PublicKey:
+		// secp256k1 KeyPair.Sign produces an Ethereum-style R||S||V over Keccak256
+		if len(sig) == 65 {
+			sig = sig[:64]
+		}
+		if len(sig) != 64 || !ethcrypto.VerifySignature(ethcrypto.FromECDSAPub(pk), ethcrypto.Keccak256(msg), sig) {
+			return crypto.ErrInvalidSignature
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
@@ -0,0 +1,185 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+)
+
+// staticKeyResolver resolves DIDs from a fixed map of registered keys.
+type staticKeyResolver map[did.AgentDID]interface{}
+
+func (r staticKeyResolver) ResolveAgent(_ context.Context, agentDID did.AgentDID) (*did.AgentMetadata, error) {
+	pub, ok := r[agentDID]
+	if !ok {
+		return nil, did.ErrDIDNotFound
+	}
+	return &did.AgentMetadata{DID: agentDID, PublicKey: pub, IsActive: true}, nil
+}
+
+func (r staticKeyResolver) ResolvePublicKey(_ context.Context, agentDID did.AgentDID) (interface{}, error) {
+	pub, ok := r[agentDID]
+	if !ok {
+		return nil, did.ErrDIDNotFound
+	}
+	return pub, nil
+}
+
+// newControlAgent serves ControlChallengeHandler for kp and agentDID at its
+// own URL and returns that endpoint.
+func newControlAgent(t *testing.T, kp crypto.KeyPair, agentDID string) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	mux.Handle(ControlChallengePath, ControlChallengeHandler(kp, agentDID, srv.URL))
+	return srv.URL
+}
+
+func TestProveControl(t *testing.T) {
+	ctx := context.Background()
+
+	edKey, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	secpKey, err := keys.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+	impostorKey, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+
+	const (
+		edDID   = "did:sage:ethereum:ed-agent"
+		secpDID = "did:sage:ethereum:secp-agent"
+	)
+	prover := NewControlProver(staticKeyResolver{
+		edDID:   edKey.PublicKey(),
+		secpDID: secpKey.PublicKey(),
+	}, nil)
+
+	t.Run("Ed25519 agent controls its key", func(t *testing.T) {
+		ok, err := prover.ProveControl(ctx, newControlAgent(t, edKey, edDID), edDID)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("Secp256k1 agent controls its key", func(t *testing.T) {
+		ok, err := prover.ProveControl(ctx, newControlAgent(t, secpKey, secpDID)+"/", secpDID)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("Impostor endpoint fails the proof", func(t *testing.T) {
+		// A cloned card for edDID pointing at an endpoint holding another key
+		ok, err := prover.ProveControl(ctx, newControlAgent(t, impostorKey, edDID), edDID)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("Key type mismatch fails the proof", func(t *testing.T) {
+		ok, err := prover.ProveControl(ctx, newControlAgent(t, secpKey, edDID), edDID)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("Relayed challenge fails the proof", func(t *testing.T) {
+		// An impostor endpoint forwarding challenges to the genuine agent
+		genuine := newControlAgent(t, edKey, edDID)
+		relay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			resp, err := http.Post(genuine+ControlChallengePath, "application/json", r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			defer resp.Body.Close()
+			w.WriteHeader(resp.StatusCode)
+			_, _ = io.Copy(w, resp.Body)
+		}))
+		defer relay.Close()
+
+		ok, err := prover.ProveControl(ctx, relay.URL, edDID)
+		assert.False(t, ok)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "HTTP 403")
+	})
+
+	t.Run("Unknown DID", func(t *testing.T) {
+		_, err := prover.ProveControl(ctx, newControlAgent(t, edKey, edDID), "did:sage:ethereum:unknown")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to resolve public key")
+	})
+
+	t.Run("Endpoint without handler", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		defer srv.Close()
+
+		_, err := prover.ProveControl(ctx, srv.URL, edDID)
+		require.Error(t, err)
+	})
+
+	t.Run("Handler signatures are domain separated", func(t *testing.T) {
+		// A control proof must not double as a plain Ed25519 signature
+		nonce := make([]byte, controlNonceSize)
+		msg := controlMessage(edDID, "https://agent.example", nonce)
+		sig, err := signControl(edKey, msg)
+		require.NoError(t, err)
+		assert.Error(t, edKey.Verify(msg, sig))
+		assert.NoError(t, verifyControlSignature(edKey, msg, sig))
+	})
+}
+
+func TestControlChallengeHandler_RejectsBadRequests(t *testing.T) {
+	kp, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	endpoint := newControlAgent(t, kp, "did:sage:ethereum:agent")
+
+	resp, err := http.Get(endpoint + ControlChallengePath)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	for _, body := range []string{`not json`, `{"did":"did:x","nonce":"AAAA"}`, `{"nonce":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}`} {
+		resp, err := http.Post(endpoint+ControlChallengePath, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, body)
+	}
+
+	// Well-formed challenges for another DID or endpoint are refused
+	const nonce = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+	for _, body := range []string{
+		`{"did":"did:sage:ethereum:other","endpoint":"` + endpoint + `","nonce":"` + nonce + `"}`,
+		`{"did":"did:sage:ethereum:agent","endpoint":"https://impostor.example","nonce":"` + nonce + `"}`,
+	} {
+		resp, err := http.Post(endpoint+ControlChallengePath, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode, body)
+	}
+}
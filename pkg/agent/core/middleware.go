@@ -0,0 +1,211 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sage-x-project/sage/pkg/agent/core/rfc9421"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+)
+
+// AgentDIDHeader carries the DID a signed HTTP request claims to come from
+const AgentDIDHeader = "X-SAGE-DID"
+
+// DefaultDecodedBodyLimit caps a decoded request body when
+// HTTPMiddlewareConfig.DecodedBodyLimit is left at zero
+const DefaultDecodedBodyLimit = 10 << 20
+
+// HTTPMiddlewareConfig configures VerificationService.HTTPMiddleware
+type HTTPMiddlewareConfig struct {
+	// Options are passed to VerifyHTTPRequest; nil uses the defaults
+	Options *rfc9421.HTTPVerificationOptions
+	// Policy authorizes authenticated requests; nil allows all. When set,
+	// signatures must cover "@method" and "@path"
+	Policy AuthzPolicy
+	// AgentDID extracts the claimed DID; nil reads AgentDIDHeader
+	AgentDID func(*http.Request) string
+	// VerificationCacheTTL, if positive, caches successful signature checks
+	// for that long so byte-identical retries skip the public-key operation.
+	// Freshness, digest and key resolution still run on every request; see
+	// rfc9421.VerificationCache
+	VerificationCacheTTL time.Duration
+	// DecodeContentEncoding hands next a decoded body for requests sent with
+	// Content-Encoding gzip or deflate. Content-Digest is always checked over
+	// the body as sent, i.e. the encoded bytes (RFC 9530); only after the
+	// request is authenticated and authorized is req.Body replaced with a
+	// decoding reader and Content-Encoding removed. The encoded bytes stay
+	// available from rfc9421.BufferedBody when the signature covers
+	// content-digest. Other encodings are passed through untouched
+	DecodeContentEncoding bool
+	// DecodedBodyLimit caps the decoded body size; reads beyond it fail.
+	// Zero means DefaultDecodedBodyLimit
+	DecodedBodyLimit int64
+}
+
+type verifiedAgentKey struct{}
+
+// VerifiedAgentFromContext returns the agent authenticated by HTTPMiddleware
+func VerifiedAgentFromContext(ctx context.Context) (*did.AgentMetadata, bool) {
+	agent, ok := ctx.Value(verifiedAgentKey{}).(*did.AgentMetadata)
+	return agent, ok
+}
+
+// HTTPMiddleware authenticates RFC 9421 signed requests and then authorizes
+// them with cfg.Policy before calling next.
+//
+// Requests without a DID, with a signature that does not verify against the
+// agent's keys, or from an agent that cannot be resolved are rejected with
+// 401. Authenticated requests the policy denies are rejected with 403 and
+// the policy's reason. Allowed requests carry the resolved agent in their
+// context (see VerifiedAgentFromContext).
+//
+// Every response echoes the request ID from transport.RequestIDHeader, or a
+// fresh one when the caller sent none; it is also placed in the request
+// context and passed to the policy.
+func (s *VerificationService) HTTPMiddleware(cfg HTTPMiddlewareConfig) func(http.Handler) http.Handler {
+	policy := cfg.Policy
+	if policy == nil {
+		policy = AllowAll()
+	}
+	agentDID := cfg.AgentDID
+	if agentDID == nil {
+		agentDID = func(r *http.Request) string { return r.Header.Get(AgentDIDHeader) }
+	}
+	opts := cfg.Options
+	if cfg.VerificationCacheTTL > 0 || cfg.Policy != nil {
+		if opts == nil {
+			opts = rfc9421.DefaultHTTPVerificationOptions()
+		} else {
+			cp := *opts
+			opts = &cp
+		}
+	}
+	if cfg.VerificationCacheTTL > 0 {
+		opts.Cache = rfc9421.NewVerificationCache(cfg.VerificationCacheTTL, 0)
+	}
+	if cfg.Policy != nil {
+		// The policy decides on method and path, so both must be signed
+		required := append([]string(nil), opts.RequiredComponents...)
+		opts.RequiredComponents = append(required, "@method", "@path")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(transport.RequestIDHeader)
+			if requestID == "" {
+				requestID = transport.NewRequestID()
+			}
+			w.Header().Set(transport.RequestIDHeader, requestID)
+			// Attach the request ID to r itself so the context BufferBody
+			// stores the raw body in during verification reaches next
+			r = r.WithContext(transport.WithRequestID(r.Context(), requestID))
+			ctx := r.Context()
+
+			claimed := agentDID(r)
+			if claimed == "" {
+				writeAuthError(w, http.StatusUnauthorized, "missing agent DID")
+				return
+			}
+			if err := s.VerifyHTTPRequest(ctx, r, claimed, opts); err != nil {
+				writeAuthError(w, http.StatusUnauthorized, "signature verification failed")
+				return
+			}
+			agent, err := s.didResolver.ResolveAgent(ctx, did.AgentDID(claimed))
+			if err != nil {
+				writeAuthError(w, http.StatusUnauthorized, "failed to resolve agent")
+				return
+			}
+
+			decision := policy.Authorize(ctx, &AuthzRequest{
+				DID:       did.AgentDID(claimed),
+				Agent:     agent,
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Header:    r.Header,
+				Time:      time.Now(),
+				RequestID: requestID,
+			})
+			if !decision.Allow {
+				reason := decision.Reason
+				if reason == "" {
+					reason = "forbidden"
+				}
+				writeAuthError(w, http.StatusForbidden, reason)
+				return
+			}
+
+			if cfg.DecodeContentEncoding {
+				if err := decodeRequestBody(w, r, cfg.DecodedBodyLimit); err != nil {
+					writeAuthError(w, http.StatusBadRequest, err.Error())
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), verifiedAgentKey{}, agent)))
+		})
+	}
+}
+
+// decodeRequestBody replaces r.Body with a reader decoding its gzip or
+// deflate Content-Encoding, capped at limit decoded bytes
+func decodeRequestBody(w http.ResponseWriter, r *http.Request, limit int64) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	if limit <= 0 {
+		limit = DefaultDecodedBodyLimit
+	}
+
+	var decoded io.ReadCloser
+	var err error
+	switch strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		decoded, err = gzip.NewReader(r.Body)
+	case "deflate":
+		// HTTP deflate is the zlib format (RFC 9110 Section 8.4.1.2)
+		decoded, err = zlib.NewReader(r.Body)
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("malformed %s body", r.Header.Get("Content-Encoding"))
+	}
+
+	r.Body = http.MaxBytesReader(w, decoded, limit)
+	r.Header.Del("Content-Encoding")
+	r.ContentLength = -1
+	r.GetBody = nil
+	return nil
+}
+
+func writeAuthError(w http.ResponseWriter, status int, reason string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": reason})
+}
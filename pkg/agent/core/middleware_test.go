@@ -0,0 +1,296 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sage-x-project/sage/pkg/agent/core/rfc9421"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+)
+
+// agentsResolver serves one verification key and metadata per DID
+type agentsResolver struct {
+	*MockDIDManager
+	keys map[did.AgentDID]did.VerificationKey
+}
+
+func (r *agentsResolver) ResolvePublicKeys(_ context.Context, agentDID did.AgentDID) ([]did.VerificationKey, error) {
+	key, ok := r.keys[agentDID]
+	if !ok {
+		return nil, did.ErrDIDNotFound
+	}
+	return []did.VerificationKey{key}, nil
+}
+
+func (r *agentsResolver) ResolveAgent(_ context.Context, agentDID did.AgentDID) (*did.AgentMetadata, error) {
+	if _, ok := r.keys[agentDID]; !ok {
+		return nil, did.ErrDIDNotFound
+	}
+	return &did.AgentMetadata{DID: agentDID, IsActive: true}, nil
+}
+
+func TestVerificationService_HTTPMiddleware(t *testing.T) {
+	allowed := did.AgentDID("did:sage:ethereum:allowed")
+	other := did.AgentDID("did:sage:ethereum:other")
+
+	resolver := &agentsResolver{MockDIDManager: new(MockDIDManager), keys: map[did.AgentDID]did.VerificationKey{}}
+	privs := map[did.AgentDID]ed25519.PrivateKey{}
+	for _, d := range []did.AgentDID{allowed, other} {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		key, err := did.NewVerificationKey(d, pub)
+		require.NoError(t, err)
+		resolver.keys[d] = key
+		privs[d] = priv
+	}
+
+	service := NewVerificationService(resolver)
+	var seen *did.AgentMetadata
+	var seenRequestID string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = VerifiedAgentFromContext(r.Context())
+		seenRequestID = transport.RequestIDFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := service.HTTPMiddleware(HTTPMiddlewareConfig{
+		Policy: &AllowListPolicy{Rules: map[did.AgentDID][]string{
+			allowed: {"/tools/search"},
+			other:   {"/tools/weather"},
+		}},
+	})(next)
+
+	serve := func(t *testing.T, h http.Handler, agentDID did.AgentDID, path string, priv ed25519.PrivateKey) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set(AgentDIDHeader, string(agentDID))
+		require.NoError(t, rfc9421.NewHTTPVerifier().SignRequest(req, "sig1", &rfc9421.SignatureInputParams{
+			CoveredComponents: []string{`"@method"`, `"@path"`},
+			KeyID:             resolver.keys[agentDID].ID,
+			Algorithm:         "ed25519",
+			Created:           time.Now().Unix(),
+		}, priv))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("allowed agent reaches the handler", func(t *testing.T) {
+		seen = nil
+		rec := serve(t, handler, allowed, "/tools/search", privs[allowed])
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		require.NotNil(t, seen)
+		assert.Equal(t, allowed, seen.DID)
+	})
+
+	t.Run("authenticated but unauthorized agent gets 403", func(t *testing.T) {
+		seen = nil
+		rec := serve(t, handler, other, "/tools/search", privs[other])
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Nil(t, seen)
+
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "agent may not call /tools/search", body["error"])
+	})
+
+	t.Run("bad signature gets 401", func(t *testing.T) {
+		// Claims to be the allowed agent but signs with another key
+		rec := serve(t, handler, allowed, "/tools/search", privs[other])
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("signature not covering the path gets 401", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/tools/search", nil)
+		req.Header.Set(AgentDIDHeader, string(allowed))
+		require.NoError(t, rfc9421.NewHTTPVerifier().SignRequest(req, "sig1", &rfc9421.SignatureInputParams{
+			CoveredComponents: []string{`"@method"`},
+			KeyID:             resolver.keys[allowed].ID,
+			Algorithm:         "ed25519",
+			Created:           time.Now().Unix(),
+		}, privs[allowed]))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("missing DID gets 401", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tools/search", nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("request ID is propagated and echoed", func(t *testing.T) {
+		rec := serve(t, handler, allowed, "/tools/search", privs[allowed])
+		assert.NotEmpty(t, rec.Header().Get(transport.RequestIDHeader), "generated when absent")
+		assert.Equal(t, rec.Header().Get(transport.RequestIDHeader), seenRequestID)
+
+		withID := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Set(transport.RequestIDHeader, "req-42")
+			handler.ServeHTTP(w, r)
+		})
+		rec = serve(t, withID, other, "/tools/search", privs[other])
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Equal(t, "req-42", rec.Header().Get(transport.RequestIDHeader))
+	})
+
+	t.Run("nil policy allows all", func(t *testing.T) {
+		open := service.HTTPMiddleware(HTTPMiddlewareConfig{})(next)
+		rec := serve(t, open, other, "/tools/search", privs[other])
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+}
+
+func TestHTTPMiddleware_ContentEncoding(t *testing.T) {
+	agentDID := did.AgentDID("did:sage:ethereum:gzip")
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := did.NewVerificationKey(agentDID, pub)
+	require.NoError(t, err)
+	service := NewVerificationService(&agentsResolver{
+		MockDIDManager: new(MockDIDManager),
+		keys:           map[did.AgentDID]did.VerificationKey{agentDID: key},
+	})
+
+	plain := []byte(`{"query":"weather in Seoul"}`)
+	var gz, zl bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	_, err = gw.Write(plain)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	zw := zlib.NewWriter(&zl)
+	_, err = zw.Write(plain)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	// signed builds a request whose Content-Digest is computed over digested
+	// and whose body on the wire is body
+	signed := func(t *testing.T, encoding string, body, digested []byte) *http.Request {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/tools/search", bytes.NewReader(body))
+		req.Header.Set(AgentDIDHeader, string(agentDID))
+		req.Header.Set("Content-Encoding", encoding)
+		req.Header.Set("Content-Digest", rfc9421.ComputeContentDigest(digested))
+		require.NoError(t, rfc9421.NewHTTPVerifier().SignRequest(req, "sig1", &rfc9421.SignatureInputParams{
+			CoveredComponents: []string{`"@method"`, `"@path"`, `"content-encoding"`, `"content-digest"`},
+			KeyID:             key.ID,
+			Algorithm:         "ed25519",
+			Created:           time.Now().Unix(),
+		}, priv))
+		return req
+	}
+
+	var gotBody, gotRaw []byte
+	var gotEncoding string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRaw, _ = rfc9421.BufferedBody(r)
+		gotEncoding = r.Header.Get("Content-Encoding")
+		var err error
+		gotBody, err = io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	decoding := service.HTTPMiddleware(HTTPMiddlewareConfig{DecodeContentEncoding: true})(next)
+
+	t.Run("gzip body verifies and handler sees decoded content", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		decoding.ServeHTTP(rec, signed(t, "gzip", gz.Bytes(), gz.Bytes()))
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		assert.Equal(t, plain, gotBody)
+		assert.Equal(t, gz.Bytes(), gotRaw, "encoded bytes stay available")
+		assert.Empty(t, gotEncoding)
+	})
+
+	t.Run("deflate body is decoded", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		decoding.ServeHTTP(rec, signed(t, "deflate", zl.Bytes(), zl.Bytes()))
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		assert.Equal(t, plain, gotBody)
+	})
+
+	t.Run("digest over decoded bytes is rejected", func(t *testing.T) {
+		gotBody = nil
+		rec := httptest.NewRecorder()
+		decoding.ServeHTTP(rec, signed(t, "gzip", gz.Bytes(), plain))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Nil(t, gotBody)
+	})
+
+	t.Run("without decoding the handler sees encoded bytes", func(t *testing.T) {
+		raw := service.HTTPMiddleware(HTTPMiddlewareConfig{})(next)
+		rec := httptest.NewRecorder()
+		raw.ServeHTTP(rec, signed(t, "gzip", gz.Bytes(), gz.Bytes()))
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		assert.Equal(t, gz.Bytes(), gotBody)
+		assert.Equal(t, "gzip", gotEncoding)
+	})
+
+	t.Run("malformed gzip gets 400", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		decoding.ServeHTTP(rec, signed(t, "gzip", plain, plain))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("decoded body limit", func(t *testing.T) {
+		limited := service.HTTPMiddleware(HTTPMiddlewareConfig{DecodeContentEncoding: true, DecodedBodyLimit: 8})(next)
+		rec := httptest.NewRecorder()
+		limited.ServeHTTP(rec, signed(t, "gzip", gz.Bytes(), gz.Bytes()))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+}
+
+func TestAllowListPolicy(t *testing.T) {
+	ctx := context.Background()
+	policy := &AllowListPolicy{Rules: map[did.AgentDID][]string{
+		"did:sage:ethereum:a": {"/tools/"},
+		"did:sage:ethereum:b": {"*"},
+	}}
+
+	assert.True(t, policy.Authorize(ctx, &AuthzRequest{DID: "did:sage:ethereum:a", Path: "/tools/search"}).Allow)
+	assert.False(t, policy.Authorize(ctx, &AuthzRequest{DID: "did:sage:ethereum:a", Path: "/admin"}).Allow)
+	assert.True(t, policy.Authorize(ctx, &AuthzRequest{DID: "did:sage:ethereum:b", Path: "/admin"}).Allow)
+
+	// Matching is on cleaned paths and whole segments
+	assert.True(t, policy.Authorize(ctx, &AuthzRequest{DID: "did:sage:ethereum:a", Path: "/tools"}).Allow)
+	assert.True(t, policy.Authorize(ctx, &AuthzRequest{DID: "did:sage:ethereum:a", Path: "/tools//search"}).Allow)
+	assert.False(t, policy.Authorize(ctx, &AuthzRequest{DID: "did:sage:ethereum:a", Path: "/tools/../admin"}).Allow)
+	assert.False(t, policy.Authorize(ctx, &AuthzRequest{DID: "did:sage:ethereum:a", Path: "/toolsx"}).Allow)
+
+	decision := policy.Authorize(ctx, &AuthzRequest{DID: "did:sage:ethereum:c", Path: "/tools/search"})
+	assert.False(t, decision.Allow)
+	assert.Equal(t, "agent is not on the allow list", decision.Reason)
+}
@@ -0,0 +1,73 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package rfc9421
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// bufferedBodyKey is the request context key holding the buffered body.
+type bufferedBodyKey struct{}
+
+// BufferBody reads the request body exactly once and stores the bytes in the
+// request context, so the verifier and downstream handlers can all consume it.
+//
+// On the first call the body is read, req.Body is replaced with a reader over
+// the buffer, ContentLength and GetBody are set, and req is updated in place
+// to carry the buffer in its context. Later calls return the stored bytes
+// without touching req.Body, so a verifier that runs after a handler has
+// read the body still sees the full content, and a handler that runs after
+// the verifier still finds req.Body unread.
+//
+// Note: the whole body is held in memory; limit request sizes upstream
+// (e.g. http.MaxBytesReader) for untrusted clients.
+func BufferBody(req *http.Request) ([]byte, error) {
+	if body, ok := BufferedBody(req); ok {
+		return body, nil
+	}
+
+	body := []byte{}
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		_ = req.Body.Close()
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	*req = *req.WithContext(context.WithValue(req.Context(), bufferedBodyKey{}, body))
+
+	return body, nil
+}
+
+// BufferedBody returns the body stored by BufferBody, if any.
+func BufferedBody(req *http.Request) ([]byte, bool) {
+	body, ok := req.Context().Value(bufferedBodyKey{}).([]byte)
+	return body, ok
+}
@@ -0,0 +1,127 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package rfc9421
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingReader records how many bytes were read from the underlying body.
+type countingReader struct {
+	io.Reader
+	reads int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.reads += n
+	return n, err
+}
+
+func (c *countingReader) Close() error { return nil }
+
+func TestBufferBody_VerifierAndHandlerBothConsume(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	const body = `{"operation":"add","arguments":[1,2]}`
+	newSignedRequest := func(t *testing.T) (*http.Request, *countingReader) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "https://sage.dev/tools/calculator", nil)
+		src := &countingReader{Reader: strings.NewReader(body)}
+		req.Body = src
+		req.Header.Set("Content-Digest", ComputeContentDigest([]byte(body)))
+		require.NoError(t, NewHTTPVerifier().SignRequest(req, "sig1", &SignatureInputParams{
+			CoveredComponents: []string{`"@method"`, `"content-digest"`},
+			KeyID:             "test-key",
+			Created:           time.Now().Unix(),
+		}, priv))
+		return req, src
+	}
+
+	verify := func(r *http.Request) error {
+		return NewHTTPVerifier().VerifyRequest(r, pub, nil)
+	}
+
+	t.Run("verifier then handler", func(t *testing.T) {
+		req, src := newSignedRequest(t)
+
+		var handlerBody, buffered []byte
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := verify(r); err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			handlerBody, _ = io.ReadAll(r.Body)
+			buffered, _ = BufferedBody(r)
+		})
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, body, string(handlerBody))
+		assert.Equal(t, body, string(buffered))
+		assert.Equal(t, len(body), src.reads, "body must be read from the wire exactly once")
+	})
+
+	t.Run("handler then verifier", func(t *testing.T) {
+		req, src := newSignedRequest(t)
+
+		// A handler that buffers and drains the body before verification runs
+		got, err := BufferBody(req)
+		require.NoError(t, err)
+		drained, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		assert.Equal(t, body, string(got))
+		assert.Equal(t, body, string(drained))
+
+		require.NoError(t, verify(req))
+		assert.Equal(t, len(body), src.reads)
+	})
+
+	t.Run("tampered buffer detected", func(t *testing.T) {
+		req, _ := newSignedRequest(t)
+		req.Body = io.NopCloser(strings.NewReader(`{"operation":"sub"}`))
+
+		assert.Error(t, verify(req))
+	})
+}
+
+func TestBufferBody_EmptyBody(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://sage.dev/", nil)
+
+	body, err := BufferBody(req)
+	require.NoError(t, err)
+	assert.Empty(t, body)
+
+	stored, ok := BufferedBody(req)
+	assert.True(t, ok)
+	assert.Empty(t, stored)
+	assert.Equal(t, int64(0), req.ContentLength)
+}
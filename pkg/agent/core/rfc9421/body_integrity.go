@@ -21,13 +21,36 @@ package rfc9421
 import (
 	"bytes"
 	"crypto/sha256"
-	"encoding/base64"
+	"crypto/sha512"
 	"fmt"
+	"hash"
 	"io"
 	"net/http"
+	"slices"
 	"strings"
+
+	"github.com/sage-x-project/sage/internal/sfv"
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+)
+
+// Content-Digest algorithm identifiers (RFC 9530 Hash Algorithms registry).
+const (
+	DigestAlgSHA256 = "sha-256"
+	DigestAlgSHA512 = "sha-512"
 )
 
+// digestFuncs maps supported Content-Digest algorithms to their hash constructors.
+var digestFuncs = map[string]func() hash.Hash{
+	DigestAlgSHA256: sha256.New,
+	DigestAlgSHA512: sha512.New,
+}
+
+// DefaultDigestAlgorithms returns the Content-Digest algorithms trusted when
+// no explicit allow-list is configured.
+func DefaultDigestAlgorithms() []string {
+	return []string{DigestAlgSHA256, DigestAlgSHA512}
+}
+
 // BodyIntegrityValidator validates HTTP body integrity using Content-Digest header.
 // This prevents body tampering attacks where an attacker modifies the body but leaves
 // the Content-Digest header unchanged.
@@ -36,12 +59,21 @@ import (
 // - Sole responsibility: Validate that Content-Digest header matches actual body content
 // - Separated from HTTP signature verification logic
 type BodyIntegrityValidator struct {
-	// Future: Injectable hasher for testing and algorithm flexibility
+	allowedAlgs []string // trusted digest algorithms (RFC 9530 identifiers)
 }
 
 // NewBodyIntegrityValidator creates a new body integrity validator.
-func NewBodyIntegrityValidator() *BodyIntegrityValidator {
-	return &BodyIntegrityValidator{}
+// allowedAlgs restricts which Content-Digest algorithms are trusted;
+// when empty, DefaultDigestAlgorithms is used.
+func NewBodyIntegrityValidator(allowedAlgs ...string) *BodyIntegrityValidator {
+	if len(allowedAlgs) == 0 {
+		allowedAlgs = DefaultDigestAlgorithms()
+	}
+	normalized := make([]string, 0, len(allowedAlgs))
+	for _, alg := range allowedAlgs {
+		normalized = append(normalized, strings.ToLower(strings.TrimSpace(alg)))
+	}
+	return &BodyIntegrityValidator{allowedAlgs: normalized}
 }
 
 // ValidateContentDigest validates that the Content-Digest header matches the actual request body.
@@ -53,9 +85,9 @@ func NewBodyIntegrityValidator() *BodyIntegrityValidator {
 // Algorithm:
 // 1. Check if content-digest is in covered components (case-insensitive)
 // 2. If not covered, skip validation (no body integrity guarantee needed)
-// 3. Read and restore the request body
-// 4. Compute SHA-256 digest of body
-// 5. Compare with Content-Digest header value
+// 3. Read the request body via BufferBody
+// 4. Parse the Content-Digest header (may carry several algorithms)
+// 5. Verify every trusted algorithm present; require at least one
 //
 // Parameters:
 //   - req: HTTP request to validate
@@ -70,24 +102,41 @@ func (v *BodyIntegrityValidator) ValidateContentDigest(req *http.Request, covere
 		return nil
 	}
 
-	// Step 2: Read body and restore it for later use
-	body, err := readBodyAndRestore(req)
+	// Step 2: Read body once; handlers can still consume it afterwards
+	body, err := BufferBody(req)
 	if err != nil {
 		return fmt.Errorf("failed to read body for content-digest validation: %w", err)
 	}
 
-	// Step 3: Compute expected Content-Digest
-	expectedDigest := ComputeContentDigest(body)
-
-	// Step 4: Get actual Content-Digest from header
+	// Step 3: Get actual Content-Digest from header
 	actualDigest := strings.TrimSpace(req.Header.Get("Content-Digest"))
 	if actualDigest == "" {
 		return fmt.Errorf("content-digest header missing while covered by signature")
 	}
 
-	// Step 5: Compare digests (supports multiple algorithms in header)
-	if !equalDigestHeader(actualDigest, expectedDigest) {
-		return fmt.Errorf("content-digest mismatch: actual=%q expected=%q (body tampering detected)", actualDigest, expectedDigest)
+	// Step 4: Compare every trusted digest carried by the header
+	digests := parseDigestHeader(actualDigest)
+	verified := 0
+	for _, alg := range v.allowedAlgs {
+		got, ok := digests[alg]
+		if !ok {
+			continue
+		}
+		newHash, supported := digestFuncs[alg]
+		if !supported {
+			continue
+		}
+		h := newHash()
+		h.Write(body)
+		if !sagecrypto.SecureCompare(got, h.Sum(nil)) {
+			return fmt.Errorf("content-digest mismatch for %s: actual=%q (body tampering detected)", alg, actualDigest)
+		}
+		verified++
+	}
+
+	// Step 5: At least one trusted algorithm must have been checked
+	if verified == 0 {
+		return fmt.Errorf("content-digest mismatch: no trusted algorithm (allowed: %s) in %q", strings.Join(v.allowedAlgs, ", "), actualDigest)
 	}
 
 	return nil
@@ -123,8 +172,7 @@ func IsComponentCovered(coveredComponents []string, componentName string) bool {
 //
 // Format: sha-256=:<base64-encoded-hash>:
 // - Uses SHA-256 algorithm (most widely supported)
-// - Base64 encoding with standard alphabet
-// - Wrapped with colons per RFC 9421 spec
+// - Serialized as an RFC 8941 dictionary with a byte-sequence value
 //
 // Parameters:
 //   - body: Raw body bytes
@@ -132,79 +180,80 @@ func IsComponentCovered(coveredComponents []string, componentName string) bool {
 // Returns:
 //   - string: Content-Digest header value
 func ComputeContentDigest(body []byte) string {
-	hash := sha256.Sum256(body)
-	encoded := base64.StdEncoding.EncodeToString(hash[:])
-	return "sha-256=:" + encoded + ":"
+	// sha-256 is always supported, so this cannot fail.
+	out, _ := ComputeContentDigestWithAlgs(body, DigestAlgSHA256)
+	return out
 }
 
-// readBodyAndRestore reads the entire request body and restores it for later reads.
-//
-// Design: Non-destructive read
-// - Reads body into memory
-// - Creates new reader for Body field
-// - Sets ContentLength for proper handling
-// - Provides GetBody function for retries
-//
-// Note: This holds the entire body in memory. For very large bodies (>10MB),
-// consider streaming validation or size limits.
-//
-// Parameters:
-//   - req: HTTP request with body
-//
-// Returns:
-//   - []byte: Body content
-//   - error: Read error if any
-func readBodyAndRestore(req *http.Request) ([]byte, error) {
-	// Handle nil body (e.g., GET requests)
-	if req.Body == nil {
-		return []byte{}, nil
-	}
+// ComputeContentDigestWithAlgs computes a Content-Digest header value carrying
+// one digest per requested algorithm, e.g. "sha-256=:...:, sha-512=:...:".
+func ComputeContentDigestWithAlgs(body []byte, algs ...string) (string, error) {
+	return ComputeContentDigestFromReader(bytes.NewReader(body), algs...)
+}
 
-	// Read entire body
-	bodyBytes, err := io.ReadAll(req.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read request body: %w", err)
+// ComputeContentDigestFromReader is ComputeContentDigestWithAlgs for a body
+// read from r. The body is hashed as it is read, so memory use does not grow
+// with its size.
+func ComputeContentDigestFromReader(r io.Reader, algs ...string) (string, error) {
+	if len(algs) == 0 {
+		return "", fmt.Errorf("no digest algorithm specified")
 	}
-
-	// Restore body for subsequent reads
-	req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
-	req.ContentLength = int64(len(bodyBytes))
-
-	// Provide GetBody for HTTP client retries
-	req.GetBody = func() (io.ReadCloser, error) {
-		return io.NopCloser(bytes.NewReader(bodyBytes)), nil
+	names := make([]string, 0, len(algs))
+	hashes := make([]hash.Hash, 0, len(algs))
+	writers := make([]io.Writer, 0, len(algs))
+	for _, alg := range algs {
+		alg = strings.ToLower(strings.TrimSpace(alg))
+		newHash, ok := digestFuncs[alg]
+		if !ok {
+			return "", fmt.Errorf("unsupported digest algorithm: %s", alg)
+		}
+		if slices.Contains(names, alg) {
+			continue
+		}
+		h := newHash()
+		names = append(names, alg)
+		hashes = append(hashes, h)
+		writers = append(writers, h)
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), r); err != nil {
+		return "", fmt.Errorf("failed to read body for content-digest: %w", err)
 	}
 
-	return bodyBytes, nil
+	dict := make(sfv.Dictionary, 0, len(names))
+	for i, alg := range names {
+		dict = append(dict, sfv.DictMember{Key: alg, Value: sfv.Item{Value: hashes[i].Sum(nil)}})
+	}
+	return sfv.MarshalDictionary(dict)
 }
 
-// equalDigestHeader checks if actual Content-Digest header matches expected value.
+// parseDigestHeader decodes a Content-Digest dictionary into algorithm -> digest.
 //
-// Design: Flexible matching for multiple algorithms
-// - Supports single algorithm: "sha-256=:hash:"
-// - Supports multiple algorithms: "sha-512=:hash1:, sha-256=:hash2:"
-// - Extracts and compares only sha-256 value
-//
-// Parameters:
-//   - actual: Content-Digest header value from request
-//   - expected: Expected sha-256 digest value
-//
-// Returns:
-//   - bool: true if digests match, false otherwise
-func equalDigestHeader(actual, expected string) bool {
-	// Direct match (single algorithm case)
-	if actual == expected {
-		return true
+// Members are decoded one at a time so that a malformed entry for an algorithm
+// the verifier does not check cannot mask a valid entry for one it does.
+// Entries that are not non-empty byte sequences are ignored, and an algorithm
+// listed more than once is dropped as ambiguous.
+func parseDigestHeader(header string) map[string][]byte {
+	out := make(map[string][]byte)
+	seen := make(map[string]int)
+	for _, part := range strings.Split(header, ",") {
+		dict, err := sfv.ParseDictionary(strings.TrimSpace(part))
+		if err != nil || len(dict) != 1 {
+			continue
+		}
+		alg := dict[0].Key
+		seen[alg]++
+		item, ok := dict[0].Value.(sfv.Item)
+		if !ok {
+			continue
+		}
+		if digest, ok := item.Value.([]byte); ok && len(digest) > 0 {
+			out[alg] = digest
+		}
 	}
-
-	// Multiple algorithms case: parse comma-separated list
-	parts := strings.Split(actual, ",")
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if strings.HasPrefix(strings.ToLower(part), "sha-256=") {
-			return part == expected
+	for alg, n := range seen {
+		if n > 1 {
+			delete(out, alg)
 		}
 	}
-
-	return false
+	return out
 }
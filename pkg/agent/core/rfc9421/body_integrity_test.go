@@ -20,11 +20,14 @@ package rfc9421
 
 import (
 	"bytes"
+	"crypto/ed25519"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/base64"
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/sage-x-project/sage/tests/helpers"
 	"github.com/stretchr/testify/assert"
@@ -193,6 +196,87 @@ func TestBodyIntegrityValidator_ValidateContentDigest_MultipleAlgorithms(t *test
 	helpers.LogSuccess(t, "Multiple algorithm header parsed correctly")
 }
 
+func TestBodyIntegrityValidator_DigestAlgorithmAgility(t *testing.T) {
+	// 사양 요구사항: RFC 9530 다중 digest 알고리즘 (sha-512 포함)
+	helpers.LogTestSection(t, "15.1.14", "RFC9421 Body Integrity - Digest Algorithm Agility")
+
+	body := []byte(`{"message": "agility"}`)
+	sum512 := sha512.Sum512(body)
+	sha512Digest := "sha-512=:" + base64.StdEncoding.EncodeToString(sum512[:]) + ":"
+	covered := []string{"content-digest"}
+
+	newReq := func(digest string) *http.Request {
+		req, err := http.NewRequest("POST", "https://example.com", bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Digest", digest)
+		return req
+	}
+
+	t.Run("sha-512 only", func(t *testing.T) {
+		digest, err := ComputeContentDigestWithAlgs(body, DigestAlgSHA512)
+		require.NoError(t, err)
+		assert.Equal(t, sha512Digest, digest)
+		assert.NoError(t, NewBodyIntegrityValidator().ValidateContentDigest(newReq(digest), covered))
+	})
+
+	t.Run("sha-256 and sha-512 in one header", func(t *testing.T) {
+		digest, err := ComputeContentDigestWithAlgs(body, DigestAlgSHA256, DigestAlgSHA512)
+		require.NoError(t, err)
+		assert.Equal(t, ComputeContentDigest(body)+", "+sha512Digest, digest)
+
+		assert.NoError(t, NewBodyIntegrityValidator().ValidateContentDigest(newReq(digest), covered))
+		assert.NoError(t, NewBodyIntegrityValidator(DigestAlgSHA256).ValidateContentDigest(newReq(digest), covered))
+		assert.NoError(t, NewBodyIntegrityValidator(DigestAlgSHA512).ValidateContentDigest(newReq(digest), covered))
+	})
+
+	t.Run("tampered trusted digest fails even if another matches", func(t *testing.T) {
+		other, err := ComputeContentDigestWithAlgs([]byte("other"), DigestAlgSHA512)
+		require.NoError(t, err)
+		err = NewBodyIntegrityValidator().ValidateContentDigest(newReq(ComputeContentDigest(body)+", "+other), covered)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "sha-512")
+	})
+
+	t.Run("untrusted algorithm only is rejected", func(t *testing.T) {
+		err := NewBodyIntegrityValidator(DigestAlgSHA512).ValidateContentDigest(newReq(ComputeContentDigest(body)), covered)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no trusted algorithm")
+	})
+
+	t.Run("unsupported algorithm cannot be computed", func(t *testing.T) {
+		_, err := ComputeContentDigestWithAlgs(body, "md5")
+		assert.Error(t, err)
+	})
+
+	t.Run("VerifyRequest honours AllowedDigestAlgs", func(t *testing.T) {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		require.NoError(t, err)
+
+		digest, err := ComputeContentDigestWithAlgs(body, DigestAlgSHA512)
+		require.NoError(t, err)
+		req := newReq(digest)
+		req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+
+		verifier := NewHTTPVerifier()
+		params := &SignatureInputParams{
+			CoveredComponents: []string{`"@method"`, `"content-digest"`},
+			KeyID:             "test-key",
+			Algorithm:         "ed25519",
+			Created:           time.Now().Unix(),
+		}
+		require.NoError(t, verifier.SignRequest(req, "sig1", params, priv))
+
+		assert.NoError(t, verifier.VerifyRequest(req, pub, nil))
+
+		opts := DefaultHTTPVerificationOptions()
+		opts.AllowedDigestAlgs = []string{DigestAlgSHA256}
+		err = verifier.VerifyRequest(req, pub, opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "body integrity")
+	})
+	helpers.LogSuccess(t, "Digest algorithm agility validated")
+}
+
 func TestIsComponentCovered(t *testing.T) {
 	// 사양 요구사항: Case-insensitive 컴포넌트 매칭
 	helpers.LogTestSection(t, "15.1.12", "RFC9421 Body Integrity - Component Matching")
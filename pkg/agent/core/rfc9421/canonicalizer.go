@@ -19,11 +19,34 @@
 package rfc9421
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+
+	"github.com/sage-x-project/sage/internal/sfv"
 )
 
+// BuildSignatureBase returns the RFC 9421 signature base for r over the
+// components and parameters in params. It is the single builder behind both
+// HTTPVerifier.SignRequest and request verification, so a signer and a
+// verifier looking at the same message produce byte-identical bases whether r
+// is an outgoing client request or one received by an http.Server.
+func BuildSignatureBase(r *http.Request, params *SignatureInputParams) ([]byte, error) {
+	if r == nil {
+		return nil, errors.New("request is required")
+	}
+	if params == nil {
+		return nil, errors.New("signature input params are required")
+	}
+	base, err := NewCanonicalizer().BuildSignatureBase(r, "", params)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(base), nil
+}
+
 // Canonicalizer builds signature base strings according to RFC 9421
 type Canonicalizer struct{}
 
@@ -46,7 +69,10 @@ func (c *Canonicalizer) BuildSignatureBase(req *http.Request, sigName string, pa
 	}
 
 	// Add signature parameters as the last line
-	sigParams := c.buildSignatureParams(sigName, params)
+	sigParams, err := c.buildSignatureParams(params)
+	if err != nil {
+		return "", err
+	}
 	lines = append(lines, sigParams)
 
 	return strings.Join(lines, "\n"), nil
@@ -61,6 +87,21 @@ func (c *Canonicalizer) canonicalizeComponent(req *http.Request, component strin
 		return c.canonicalizeQueryParam(req, component)
 	}
 
+	// Handle parameterized field components (sf, bs, key)
+	if item, err := sfv.ParseItem(component); err == nil && len(item.Params) > 0 {
+		comp, err := ParseComponent(component)
+		if err != nil {
+			return "", err
+		}
+		if !strings.HasPrefix(comp.Name, "@") {
+			identifier, err := sfv.MarshalItem(item)
+			if err != nil {
+				return "", fmt.Errorf("invalid component %s: %w", component, err)
+			}
+			return c.canonicalizeFieldComponent(req, comp, identifier)
+		}
+	}
+
 	// Remove quotes if present for lookup
 	lookupComponent := strings.Trim(component, `"`)
 
@@ -150,20 +191,81 @@ func (c *Canonicalizer) canonicalizeHTTPComponent(req *http.Request, component s
 
 // canonicalizeHeader handles regular HTTP headers
 func (c *Canonicalizer) canonicalizeHeader(req *http.Request, headerName string) (string, error) {
-	// Headers are case-insensitive
-	values := req.Header[http.CanonicalHeaderKey(headerName)]
+	values, err := fieldValues(req, Field(headerName))
+	if err != nil {
+		return "", err
+	}
+
+	// Format as lowercase header name
+	return fmt.Sprintf(`"%s": %s`, strings.ToLower(headerName), combineFieldValues(values)), nil
+}
+
+// fieldValues returns every value of the field named by comp, in message
+// order. With the "tr" parameter the value is read from req.Trailer, which
+// an http.Server only fills in once the body has been read to EOF.
+func fieldValues(req *http.Request, comp Component) ([]string, error) {
+	key := http.CanonicalHeaderKey(comp.Name)
+	if comp.HasParam(ComponentParamTr) {
+		values := req.Trailer[key]
+		if len(values) == 0 {
+			return nil, fmt.Errorf("component not found: trailer %s", comp.Name)
+		}
+		return values, nil
+	}
+
+	values := req.Header[key]
+	if len(values) == 0 {
+		values = messageFieldFallback(req, comp.Name)
+	}
 	if len(values) == 0 {
-		return "", fmt.Errorf("component not found: header %s", headerName)
+		return nil, fmt.Errorf("component not found: header %s", comp.Name)
 	}
+	return values, nil
+}
 
-	// Join multiple values with comma and space
-	value := strings.Join(values, ", ")
+// combineFieldValues canonicalizes a field per RFC 9421 Section 2.1: obsolete
+// line folding is replaced by a single space, each field line value is
+// stripped of leading and trailing whitespace, and the values are joined with
+// ", " in the order they appear in the message.
+func combineFieldValues(values []string) string {
+	cleaned := make([]string, len(values))
+	for i, v := range values {
+		cleaned[i] = trimFieldValue(v)
+	}
+	return strings.Join(cleaned, ", ")
+}
 
-	// Trim leading and trailing whitespace
-	value = strings.TrimSpace(value)
+// trimFieldValue unfolds and trims a single field line value.
+func trimFieldValue(v string) string {
+	if strings.ContainsAny(v, "\r\n") {
+		lines := strings.FieldsFunc(v, func(r rune) bool { return r == '\r' || r == '\n' })
+		for i := range lines {
+			lines[i] = strings.Trim(lines[i], " \t")
+		}
+		v = strings.Join(lines, " ")
+	}
+	return strings.Trim(v, " \t")
+}
 
-	// Format as lowercase header name
-	return fmt.Sprintf(`"%s": %s`, strings.ToLower(headerName), value), nil
+// messageFieldFallback returns the value net/http keeps outside req.Header
+// for fields that are still part of the message on the wire: a server moves
+// Host into req.Host, and a client sends Content-Length from req.ContentLength.
+func messageFieldFallback(req *http.Request, headerName string) []string {
+	switch strings.ToLower(headerName) {
+	case "host":
+		host := req.Host
+		if host == "" && req.URL != nil {
+			host = req.URL.Host
+		}
+		if host != "" {
+			return []string{host}
+		}
+	case "content-length":
+		if req.ContentLength > 0 {
+			return []string{strconv.FormatInt(req.ContentLength, 10)}
+		}
+	}
+	return nil
 }
 
 // canonicalizeQueryParam handles @query-param components
@@ -189,30 +291,14 @@ func (c *Canonicalizer) canonicalizeQueryParam(req *http.Request, component stri
 }
 
 // buildSignatureParams creates the @signature-params line
-func (c *Canonicalizer) buildSignatureParams(sigName string, params *SignatureInputParams) string {
-	var parts []string
-
-	// Add covered components
-	components := make([]string, len(params.CoveredComponents))
-	copy(components, params.CoveredComponents)
-	parts = append(parts, "("+strings.Join(components, " ")+")")
-
-	// Add parameters
-	if params.KeyID != "" {
-		parts = append(parts, fmt.Sprintf(`keyid="%s"`, params.KeyID))
-	}
-	if params.Algorithm != "" {
-		parts = append(parts, fmt.Sprintf(`alg="%s"`, params.Algorithm))
-	}
-	if params.Created > 0 {
-		parts = append(parts, fmt.Sprintf(`created=%d`, params.Created))
-	}
-	if params.Expires > 0 {
-		parts = append(parts, fmt.Sprintf(`expires=%d`, params.Expires))
+func (c *Canonicalizer) buildSignatureParams(params *SignatureInputParams) (string, error) {
+	il, err := signatureParamsInnerList(params)
+	if err != nil {
+		return "", err
 	}
-	if params.Nonce != "" {
-		parts = append(parts, fmt.Sprintf(`nonce="%s"`, params.Nonce))
+	value, err := sfv.MarshalInnerList(il)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize signature params: %w", err)
 	}
-
-	return fmt.Sprintf(`"@signature-params": %s`, strings.Join(parts, ";"))
+	return `"@signature-params": ` + value, nil
 }
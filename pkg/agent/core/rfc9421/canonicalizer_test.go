@@ -20,7 +20,11 @@ package rfc9421
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -476,3 +480,144 @@ func TestHTTPFields(t *testing.T) {
 		assert.Contains(t, result, `"content-length": 9`)
 	})
 }
+
+// TestBuildSignatureBase_SignerVerifierIdentical sends a request through a
+// real HTTP round trip and checks that the base computed on the outgoing
+// client request matches the one computed from the server's view of it.
+func TestBuildSignatureBase_SignerVerifierIdentical(t *testing.T) {
+	body := []byte(`{"action":"transfer","amount":10}`)
+	params := &SignatureInputParams{
+		CoveredComponents: []string{
+			`"@method"`, `"@target-uri"`, `"@authority"`, `"@scheme"`,
+			`"@request-target"`, `"@path"`, `"@query"`, `"@query-param";name="id"`,
+			`"host"`, `"content-type"`, `"content-length"`, `"content-digest"`, `"date"`,
+		},
+		KeyID:     "did:sage:ethereum:agent001",
+		Algorithm: "ed25519",
+		Created:   1719234000,
+		Nonce:     "n-123",
+	}
+
+	received := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		base, err := BuildSignatureBase(r, params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		received <- base
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/v1/tools?id=42&mode=fast", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Digest", ComputeContentDigest(body))
+	req.Header.Set("Date", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC).Format(http.TimeFormat))
+
+	signerBase, err := BuildSignatureBase(req, params)
+	require.NoError(t, err)
+
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	verifierBase := <-received
+	assert.Equal(t, string(signerBase), string(verifierBase))
+	assert.Contains(t, string(signerBase), fmt.Sprintf(`"content-length": %d`, len(body)))
+	assert.Contains(t, string(signerBase), `"host": `+strings.TrimPrefix(srv.URL, "http://"))
+}
+
+func TestBuildSignatureBase_MatchesCanonicalizer(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.com/foo?bar=baz", nil)
+	require.NoError(t, err)
+	params := &SignatureInputParams{
+		CoveredComponents: []string{`"@method"`, `"@authority"`, `"@path"`, `"@query"`},
+		KeyID:             "test-key",
+		Algorithm:         "ed25519",
+		Created:           1719234000,
+	}
+
+	base, err := BuildSignatureBase(req, params)
+	require.NoError(t, err)
+	legacy, err := NewCanonicalizer().BuildSignatureBase(req, "sig1", params)
+	require.NoError(t, err)
+	assert.Equal(t, legacy, string(base))
+
+	_, err = BuildSignatureBase(nil, params)
+	assert.Error(t, err)
+	_, err = BuildSignatureBase(req, nil)
+	assert.Error(t, err)
+}
+
+func TestCanonicalizer_MultiValueAndTrailers(t *testing.T) {
+	newRequest := func(t *testing.T) *http.Request {
+		req, err := http.NewRequest(http.MethodPost, "https://example.com/upload", strings.NewReader("chunk"))
+		require.NoError(t, err)
+		// Two field lines, as a proxy that does not merge them forwards them
+		req.Header.Add("Accept", "text/html ")
+		req.Header.Add("Accept", "  application/json;q=0.9")
+		req.Header.Add("X-Obs-Fold-Header", "Obsolete\r\n    line folding.")
+		req.Trailer = http.Header{"Expires": {"Wed, 9 Nov 2022 07:28:00 GMT"}}
+		return req
+	}
+
+	t.Run("combines repeated headers per RFC 9421 Section 2.1", func(t *testing.T) {
+		params := &SignatureInputParams{
+			CoveredComponents: []string{`"accept"`, `"x-obs-fold-header"`, `"expires";tr`},
+			Created:           1618884473,
+			KeyID:             "test-key-ed25519",
+		}
+		base, err := BuildSignatureBase(newRequest(t), params)
+		require.NoError(t, err)
+
+		want := strings.Join([]string{
+			`"accept": text/html, application/json;q=0.9`,
+			`"x-obs-fold-header": Obsolete line folding.`,
+			`"expires";tr: Wed, 9 Nov 2022 07:28:00 GMT`,
+			`"@signature-params": ("accept" "x-obs-fold-header" "expires";tr);keyid="test-key-ed25519";created=1618884473`,
+		}, "\n")
+		assert.Equal(t, want, string(base))
+	})
+
+	t.Run("trailer is not read from headers", func(t *testing.T) {
+		req := newRequest(t)
+		req.Trailer = nil
+		req.Header.Set("Expires", "Thu, 10 Nov 2022 07:28:00 GMT")
+		_, err := BuildSignatureBase(req, &SignatureInputParams{CoveredComponents: []string{`"expires";tr`}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "trailer expires")
+
+		// The header itself is still covered without tr
+		base, err := BuildSignatureBase(req, &SignatureInputParams{CoveredComponents: []string{`"expires"`}})
+		require.NoError(t, err)
+		assert.Contains(t, string(base), `"expires": Thu, 10 Nov 2022 07:28:00 GMT`)
+	})
+
+	t.Run("builder emits the tr flag", func(t *testing.T) {
+		ids, err := NewComponentsBuilder().Fields("accept").Trailers("expires").Build()
+		require.NoError(t, err)
+		assert.Equal(t, []string{`"accept"`, `"expires";tr`}, ids)
+	})
+
+	t.Run("signature over repeated headers and trailers verifies", func(t *testing.T) {
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		verifier := NewHTTPVerifier()
+
+		req := newRequest(t)
+		params := &SignatureInputParams{
+			CoveredComponents: []string{`"@method"`, `"accept"`, `"expires";tr`},
+			KeyID:             "test-key",
+			Algorithm:         "ed25519",
+			Created:           time.Now().Unix(),
+		}
+		require.NoError(t, verifier.SignRequest(req, "sig1", params, privateKey))
+		require.NoError(t, verifier.VerifyRequest(req, publicKey, nil))
+
+		// Dropping the second Accept line breaks the signature
+		req.Header["Accept"] = req.Header["Accept"][:1]
+		assert.Error(t, verifier.VerifyRequest(req, publicKey, nil))
+	})
+}
@@ -0,0 +1,328 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package rfc9421
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/sage-x-project/sage/internal/sfv"
+)
+
+// Component parameter names defined by RFC 9421 Section 2.1 and 2.2.8
+const (
+	ComponentParamName = "name" // @query-param parameter name
+	ComponentParamKey  = "key"  // dictionary member key
+	ComponentParamSF   = "sf"   // strict structured field serialization
+	ComponentParamBS   = "bs"   // byte sequence wrapping
+	ComponentParamReq  = "req"  // component taken from the related request
+	ComponentParamTr   = "tr"   // field taken from the trailers
+)
+
+// Component is a structured covered component identifier. Name is a derived
+// component ("@method") or a field name ("content-type"). Params holds the
+// component parameters; an empty value renders as a boolean flag (";sf"),
+// any other value as a string parameter (`;name="foo"`).
+type Component struct {
+	Name   string
+	Params map[string]string
+}
+
+// Derived returns a derived component such as "@method" or "@path".
+func Derived(name string) Component {
+	if !strings.HasPrefix(name, "@") {
+		name = "@" + name
+	}
+	return Component{Name: name}
+}
+
+// Field returns an HTTP field component; field names are lower-cased.
+func Field(name string) Component {
+	return Component{Name: strings.ToLower(name)}
+}
+
+// QueryParam returns an "@query-param" component for the named parameter.
+func QueryParam(name string) Component {
+	return Component{Name: "@query-param", Params: map[string]string{ComponentParamName: name}}
+}
+
+// DictionaryMember returns a component covering a single member of a
+// dictionary structured field.
+func DictionaryMember(field, key string) Component {
+	return Field(field).WithParam(ComponentParamKey, key)
+}
+
+// WithParam returns a copy of c with the string parameter key set to value.
+// An empty value sets a boolean flag.
+func (c Component) WithParam(key, value string) Component {
+	params := make(map[string]string, len(c.Params)+1)
+	for k, v := range c.Params {
+		params[k] = v
+	}
+	params[key] = value
+	c.Params = params
+	return c
+}
+
+// StructuredField returns a copy of c with the "sf" flag set.
+func (c Component) StructuredField() Component {
+	return c.WithParam(ComponentParamSF, "")
+}
+
+// ByteSequence returns a copy of c with the "bs" flag set.
+func (c Component) ByteSequence() Component {
+	return c.WithParam(ComponentParamBS, "")
+}
+
+// Trailer returns a copy of c with the "tr" flag set, covering the trailer
+// field of that name instead of the header.
+func (c Component) Trailer() Component {
+	return c.WithParam(ComponentParamTr, "")
+}
+
+// HasParam reports whether the parameter key is present.
+func (c Component) HasParam(key string) bool {
+	_, ok := c.Params[key]
+	return ok
+}
+
+// Identifier renders the canonical component identifier, e.g.
+// `"@query-param";name="foo"`. Parameters are emitted in sorted key order.
+func (c Component) Identifier() (string, error) {
+	if c.Name == "" {
+		return "", fmt.Errorf("component name is required")
+	}
+	if c.Name == "@query-param" && c.Params[ComponentParamName] == "" {
+		return "", fmt.Errorf("@query-param requires a name parameter")
+	}
+	if c.HasParam(ComponentParamSF) && c.HasParam(ComponentParamBS) {
+		return "", fmt.Errorf("component %s cannot use both sf and bs", c.Name)
+	}
+
+	keys := make([]string, 0, len(c.Params))
+	for k := range c.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	item := sfv.Item{Value: c.Name}
+	for _, k := range keys {
+		var value interface{} = true
+		if v := c.Params[k]; v != "" {
+			value = v
+		}
+		item.Params = append(item.Params, sfv.Param{Key: k, Value: value})
+	}
+
+	id, err := sfv.MarshalItem(item)
+	if err != nil {
+		return "", fmt.Errorf("invalid component %s: %w", c.Name, err)
+	}
+	return id, nil
+}
+
+// String returns the identifier, or the bare name if c is invalid.
+func (c Component) String() string {
+	id, err := c.Identifier()
+	if err != nil {
+		return c.Name
+	}
+	return id
+}
+
+// ParseComponent parses a component identifier such as `"example-dict";key="a"`.
+func ParseComponent(identifier string) (Component, error) {
+	item, err := sfv.ParseItem(strings.TrimSpace(identifier))
+	if err != nil {
+		return Component{}, fmt.Errorf("invalid component %s: %w", identifier, err)
+	}
+	name, ok := item.Value.(string)
+	if !ok {
+		return Component{}, fmt.Errorf("invalid component %s: must be a quoted string", identifier)
+	}
+
+	c := Component{Name: name}
+	for _, p := range item.Params {
+		if c.Params == nil {
+			c.Params = make(map[string]string, len(item.Params))
+		}
+		switch v := p.Value.(type) {
+		case bool:
+			if !v {
+				return Component{}, fmt.Errorf("invalid component %s: parameter %s must not be false", identifier, p.Key)
+			}
+			c.Params[p.Key] = ""
+		case string:
+			c.Params[p.Key] = v
+		default:
+			return Component{}, fmt.Errorf("invalid component %s: unsupported value for parameter %s", identifier, p.Key)
+		}
+	}
+	return c, nil
+}
+
+// Components parses the covered components of params.
+func (p *SignatureInputParams) Components() ([]Component, error) {
+	components := make([]Component, 0, len(p.CoveredComponents))
+	for _, id := range p.CoveredComponents {
+		c, err := ParseComponent(id)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, c)
+	}
+	return components, nil
+}
+
+// ComponentsBuilder assembles a covered components list programmatically.
+type ComponentsBuilder struct {
+	components []Component
+}
+
+// NewComponentsBuilder creates an empty components builder
+func NewComponentsBuilder() *ComponentsBuilder {
+	return &ComponentsBuilder{}
+}
+
+// Add appends components in order
+func (b *ComponentsBuilder) Add(components ...Component) *ComponentsBuilder {
+	b.components = append(b.components, components...)
+	return b
+}
+
+// Derived appends derived components such as "@method" or "@path"
+func (b *ComponentsBuilder) Derived(names ...string) *ComponentsBuilder {
+	for _, name := range names {
+		b.components = append(b.components, Derived(name))
+	}
+	return b
+}
+
+// Fields appends HTTP field components
+func (b *ComponentsBuilder) Fields(names ...string) *ComponentsBuilder {
+	for _, name := range names {
+		b.components = append(b.components, Field(name))
+	}
+	return b
+}
+
+// Trailers appends trailer field components
+func (b *ComponentsBuilder) Trailers(names ...string) *ComponentsBuilder {
+	for _, name := range names {
+		b.components = append(b.components, Field(name).Trailer())
+	}
+	return b
+}
+
+// QueryParam appends an "@query-param" component
+func (b *ComponentsBuilder) QueryParam(name string) *ComponentsBuilder {
+	return b.Add(QueryParam(name))
+}
+
+// DictionaryMember appends a component for one member of a dictionary field
+func (b *ComponentsBuilder) DictionaryMember(field, key string) *ComponentsBuilder {
+	return b.Add(DictionaryMember(field, key))
+}
+
+// Build renders the canonical identifiers for SignatureInputParams.CoveredComponents.
+// Duplicate components are rejected as required by RFC 9421 Section 2.
+func (b *ComponentsBuilder) Build() ([]string, error) {
+	ids := make([]string, 0, len(b.components))
+	seen := make(map[string]bool, len(b.components))
+	for _, c := range b.components {
+		id, err := c.Identifier()
+		if err != nil {
+			return nil, err
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("duplicate component %s", id)
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// canonicalizeFieldComponent handles HTTP field components carrying sf, bs
+// or key parameters (RFC 9421 Section 2.1).
+func (c *Canonicalizer) canonicalizeFieldComponent(req *http.Request, comp Component, identifier string) (string, error) {
+	values, err := fieldValues(req, comp)
+	if err != nil {
+		return "", err
+	}
+
+	var value string
+	switch {
+	case comp.HasParam(ComponentParamBS):
+		encoded := make([]string, len(values))
+		for i, v := range values {
+			encoded[i] = ":" + ByteSequenceEncoding.EncodeToString([]byte(trimFieldValue(v))) + ":"
+		}
+		value = strings.Join(encoded, ", ")
+
+	case comp.HasParam(ComponentParamKey):
+		dict, err := sfv.ParseDictionary(combineFieldValues(values))
+		if err != nil {
+			return "", fmt.Errorf("component %s is not a dictionary: %w", comp.Name, err)
+		}
+		member, ok := dict.Get(comp.Params[ComponentParamKey])
+		if !ok {
+			return "", fmt.Errorf("component not found: %s", identifier)
+		}
+		value, err = marshalMember(member)
+		if err != nil {
+			return "", err
+		}
+
+	case comp.HasParam(ComponentParamSF):
+		joined := combineFieldValues(values)
+		if dict, err := sfv.ParseDictionary(joined); err == nil {
+			value, err = sfv.MarshalDictionary(dict)
+			if err != nil {
+				return "", err
+			}
+		} else if list, err := sfv.ParseList(joined); err == nil {
+			value, err = sfv.MarshalList(list)
+			if err != nil {
+				return "", err
+			}
+		} else {
+			return "", fmt.Errorf("component %s is not a structured field: %w", comp.Name, err)
+		}
+
+	default:
+		value = combineFieldValues(values)
+	}
+
+	return fmt.Sprintf(`%s: %s`, identifier, value), nil
+}
+
+// marshalMember serializes a dictionary member value
+func marshalMember(m sfv.Member) (string, error) {
+	switch v := m.(type) {
+	case sfv.Item:
+		return sfv.MarshalItem(v)
+	case sfv.InnerList:
+		return sfv.MarshalInnerList(v)
+	default:
+		return "", fmt.Errorf("unsupported dictionary member %T", m)
+	}
+}
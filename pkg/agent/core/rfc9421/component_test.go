@@ -0,0 +1,169 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package rfc9421
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentIdentifier(t *testing.T) {
+	tests := []struct {
+		name      string
+		component Component
+		expected  string
+	}{
+		{"derived", Derived("method"), `"@method"`},
+		{"field lower-cased", Field("Content-Type"), `"content-type"`},
+		{"query param", QueryParam("foo"), `"@query-param";name="foo"`},
+		{"dictionary member", DictionaryMember("Example-Dict", "a"), `"example-dict";key="a"`},
+		{"structured field", Field("example-dict").StructuredField(), `"example-dict";sf`},
+		{"byte sequence", Field("example-header").ByteSequence(), `"example-header";bs`},
+		{"params sorted", DictionaryMember("example-dict", "b").StructuredField(), `"example-dict";key="b";sf`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := tt.component.Identifier()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, id)
+
+			parsed, err := ParseComponent(id)
+			require.NoError(t, err)
+			assert.Equal(t, tt.component, parsed)
+		})
+	}
+
+	t.Run("invalid components", func(t *testing.T) {
+		_, err := Component{}.Identifier()
+		assert.Error(t, err)
+		_, err = Component{Name: "@query-param"}.Identifier()
+		assert.Error(t, err)
+		_, err = Field("x").StructuredField().ByteSequence().Identifier()
+		assert.Error(t, err)
+		_, err = Field("x").WithParam("Bad Key", "v").Identifier()
+		assert.Error(t, err)
+	})
+
+	t.Run("WithParam does not mutate the original", func(t *testing.T) {
+		base := Field("example-dict")
+		_ = base.WithParam(ComponentParamKey, "a")
+		assert.Nil(t, base.Params)
+	})
+}
+
+func TestParseComponent(t *testing.T) {
+	c, err := ParseComponent(` "@query-param";name="pet" `)
+	require.NoError(t, err)
+	assert.Equal(t, QueryParam("pet"), c)
+
+	_, err = ParseComponent(`@method`)
+	assert.Error(t, err, "unquoted identifiers are not components")
+	_, err = ParseComponent(`"x";sf=?0`)
+	assert.Error(t, err, "false flags are rejected")
+	_, err = ParseComponent(`"x";key=1`)
+	assert.Error(t, err, "non-string parameters are rejected")
+
+	params := &SignatureInputParams{CoveredComponents: []string{`"@method"`, `"example-dict";key="a"`}}
+	components, err := params.Components()
+	require.NoError(t, err)
+	assert.Equal(t, []Component{Derived("@method"), DictionaryMember("example-dict", "a")}, components)
+}
+
+func TestComponentsBuilder(t *testing.T) {
+	ids, err := NewComponentsBuilder().
+		Derived("@method", "@path").
+		QueryParam("foo").
+		Fields("Content-Digest").
+		DictionaryMember("example-dict", "a").
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`"@method"`,
+		`"@path"`,
+		`"@query-param";name="foo"`,
+		`"content-digest"`,
+		`"example-dict";key="a"`,
+	}, ids)
+
+	_, err = NewComponentsBuilder().Fields("date", "Date").Build()
+	assert.Error(t, err, "duplicate components are rejected")
+}
+
+func TestCanonicalizeParameterizedComponents(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://example.com/foo?param=value&foo=bar", nil)
+	require.NoError(t, err)
+	req.Header.Set("Example-Dict", " a=1,    b=2;x=1;y=2, c=(a   b   c)")
+	req.Header.Set("Example-Header", "value, with, lots")
+
+	components, err := NewComponentsBuilder().
+		QueryParam("foo").
+		DictionaryMember("example-dict", "a").
+		DictionaryMember("example-dict", "b").
+		DictionaryMember("example-dict", "c").
+		Add(Field("example-dict").StructuredField()).
+		Add(Field("example-header").ByteSequence()).
+		Build()
+	require.NoError(t, err)
+
+	base, err := BuildSignatureBase(req, &SignatureInputParams{CoveredComponents: components})
+	require.NoError(t, err)
+
+	lines := strings.Split(string(base), "\n")
+	assert.Equal(t, []string{
+		`"@query-param";name="foo": bar`,
+		`"example-dict";key="a": 1`,
+		`"example-dict";key="b": 2;x=1;y=2`,
+		`"example-dict";key="c": (a b c)`,
+		`"example-dict";sf: a=1, b=2;x=1;y=2, c=(a b c)`,
+		`"example-header";bs: :dmFsdWUsIHdpdGgsIGxvdHM=:`,
+	}, lines[:len(lines)-1])
+
+	t.Run("missing dictionary member", func(t *testing.T) {
+		_, err := BuildSignatureBase(req, &SignatureInputParams{
+			CoveredComponents: []string{DictionaryMember("example-dict", "z").String()},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("sign and verify", func(t *testing.T) {
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		verifier := NewHTTPVerifier()
+		params := &SignatureInputParams{
+			CoveredComponents: components,
+			KeyID:             "test-key",
+			Algorithm:         "ed25519",
+			Created:           time.Now().Unix(),
+		}
+		require.NoError(t, verifier.SignRequest(req, "sig1", params, privateKey))
+		assert.NoError(t, verifier.VerifyRequest(req, publicKey, nil))
+
+		req.Header.Set("Example-Dict", "a=2, b=2;x=1;y=2, c=(a b c)")
+		assert.Error(t, verifier.VerifyRequest(req, publicKey, nil))
+	})
+}
@@ -0,0 +1,76 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package rfc9421
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrMalformedDate is returned when a Date header matches none of the
+// formats accepted by ParseHTTPDate.
+var ErrMalformedDate = errors.New("malformed Date header")
+
+// DefaultMaxClockSkew is how far in the future a covered Date header may be
+// when HTTPVerificationOptions.MaxClockSkew is left at zero.
+const DefaultMaxClockSkew = 5 * time.Minute
+
+// httpDateLayouts are the Date forms clients are known to send: IMF-fixdate
+// (http.TimeFormat, what net/http writes), time.RFC1123 with a zone name and
+// time.RFC1123Z with a numeric offset.
+var httpDateLayouts = []string{
+	http.TimeFormat,
+	time.RFC1123,
+	time.RFC1123Z,
+}
+
+// ParseHTTPDate parses a Date header value in any accepted format and
+// returns it in UTC. Values in no accepted format fail with ErrMalformedDate.
+func ParseHTTPDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range httpDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %q", ErrMalformedDate, value)
+}
+
+// checkDate enforces opts.MaxAge and opts.MaxClockSkew on the request's Date
+// header. It only runs when the signature covers "date"; an uncovered Date
+// header could be rewritten in transit, so it proves nothing.
+func checkDate(req *http.Request, params *SignatureInputParams, opts *HTTPVerificationOptions, now time.Time) error {
+	if !coversComponent(params, "date") {
+		return nil
+	}
+	date, err := ParseHTTPDate(req.Header.Get("Date"))
+	if err != nil {
+		return err
+	}
+	if skew := opts.maxClockSkew(); skew >= 0 && date.Sub(now) > skew {
+		return fmt.Errorf("date %s is more than %s in the future", date.Format(http.TimeFormat), skew)
+	}
+	if opts.MaxAge > 0 && now.Sub(date) > opts.MaxAge {
+		return fmt.Errorf("date expired: %s is older than %s", date.Format(http.TimeFormat), opts.MaxAge)
+	}
+	return nil
+}
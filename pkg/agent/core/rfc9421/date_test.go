@@ -0,0 +1,104 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package rfc9421
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHTTPDate(t *testing.T) {
+	want := time.Date(2025, time.March, 4, 5, 6, 7, 0, time.UTC)
+
+	accepted := map[string]string{
+		"IMF-fixdate": "Tue, 04 Mar 2025 05:06:07 GMT",
+		"RFC1123":     "Tue, 04 Mar 2025 05:06:07 UTC",
+		"RFC1123Z":    "Tue, 04 Mar 2025 14:06:07 +0900",
+	}
+	for name, value := range accepted {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseHTTPDate(value)
+			require.NoError(t, err)
+			assert.True(t, want.Equal(got), "got %s", got)
+			assert.Equal(t, time.UTC, got.Location())
+		})
+	}
+
+	t.Run("Rejects unparseable values", func(t *testing.T) {
+		_, err := ParseHTTPDate("2025-03-04T05:06:07Z")
+		assert.ErrorIs(t, err, ErrMalformedDate)
+	})
+}
+
+func TestVerifyRequest_Date(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	verifier := NewHTTPVerifier()
+
+	sign := func(t *testing.T, date string) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, "https://api.example.com/v1", nil)
+		require.NoError(t, err)
+		req.Header.Set("Date", date)
+		params := &SignatureInputParams{
+			CoveredComponents: []string{`"@method"`, `"date"`},
+			KeyID:             "test-key",
+			Algorithm:         "ed25519",
+			Created:           time.Now().Unix(),
+		}
+		require.NoError(t, verifier.SignRequest(req, "sig1", params, privateKey))
+		return req
+	}
+
+	now := time.Now()
+	for name, layout := range map[string]string{
+		"IMF-fixdate": http.TimeFormat,
+		"RFC1123":     time.RFC1123,
+		"RFC1123Z":    time.RFC1123Z,
+	} {
+		t.Run("Accepts "+name, func(t *testing.T) {
+			req := sign(t, now.UTC().Format(layout))
+			assert.NoError(t, verifier.VerifyRequest(req, publicKey, nil))
+		})
+	}
+
+	t.Run("Rejects a malformed date", func(t *testing.T) {
+		req := sign(t, now.UTC().Format(time.RFC3339))
+		assert.ErrorIs(t, verifier.VerifyRequest(req, publicKey, nil), ErrMalformedDate)
+	})
+
+	t.Run("Rejects a stale date", func(t *testing.T) {
+		req := sign(t, now.Add(-10*time.Minute).UTC().Format(http.TimeFormat))
+		assert.Error(t, verifier.VerifyRequest(req, publicKey, nil))
+	})
+
+	t.Run("Bounds future dates by MaxClockSkew", func(t *testing.T) {
+		req := sign(t, now.Add(10*time.Minute).UTC().Format(http.TimeFormat))
+		assert.Error(t, verifier.VerifyRequest(req, publicKey, nil))
+
+		opts := DefaultHTTPVerificationOptions()
+		opts.MaxClockSkew = 15 * time.Minute
+		assert.NoError(t, verifier.VerifyRequest(req, publicKey, opts))
+	})
+}
@@ -0,0 +1,27 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package rfc9421
+
+import "encoding/base64"
+
+// ByteSequenceEncoding is the base64 variant of RFC 8941 byte sequences
+// (":...:"), which carry signatures, Content-Digest values and bs-encoded
+// components. RFC 8941 requires padded standard base64 here; session-layer
+// values use session.Encoding (unpadded base64url) instead.
+var ByteSequenceEncoding = base64.StdEncoding
@@ -19,12 +19,17 @@
 package rfc9421
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -548,3 +553,142 @@ func TestQueryParamProtection(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestCoveredComponentsLimit(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	verifier := NewHTTPVerifier()
+
+	t.Run("pathological Signature-Input rejected quickly", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "https://example.com/test", nil)
+		require.NoError(t, err)
+
+		var b strings.Builder
+		b.WriteString("sig1=(")
+		for i := 0; i < 10000; i++ {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(`"x-h`)
+			b.WriteString(strconv.Itoa(i))
+			b.WriteByte('"')
+		}
+		b.WriteString(");created=")
+		b.WriteString(strconv.FormatInt(time.Now().Unix(), 10))
+		req.Header.Set("Signature-Input", b.String())
+		req.Header.Set("Signature", "sig1=:"+strings.Repeat("A", 86)+"==:")
+
+		start := time.Now()
+		err = verifier.VerifyRequest(req, publicKey, nil)
+		elapsed := time.Since(start)
+
+		var tooMany *TooManyComponentsError
+		require.True(t, errors.As(err, &tooMany), "unexpected error: %v", err)
+		assert.Equal(t, 10000, tooMany.Count)
+		assert.Equal(t, DefaultMaxCoveredComponents, tooMany.Max)
+		assert.Less(t, elapsed, time.Second)
+	})
+
+	t.Run("configurable limit", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "https://example.com/test", nil)
+		require.NoError(t, err)
+
+		params := &SignatureInputParams{
+			CoveredComponents: []string{`"@method"`, `"@path"`, `"@authority"`},
+			Created:           time.Now().Unix(),
+		}
+		require.NoError(t, verifier.SignRequest(req, "sig1", params, privateKey))
+
+		err = verifier.VerifyRequest(req, publicKey, &HTTPVerificationOptions{MaxCoveredComponents: 2})
+		var tooMany *TooManyComponentsError
+		assert.True(t, errors.As(err, &tooMany))
+
+		assert.NoError(t, verifier.VerifyRequest(req, publicKey, &HTTPVerificationOptions{MaxCoveredComponents: 3}))
+		assert.NoError(t, verifier.VerifyRequest(req, publicKey, &HTTPVerificationOptions{MaxCoveredComponents: -1}))
+	})
+}
+
+// recordingSigner wraps a key and records every Sign call, standing in for a
+// remote KMS/HSM signer that never exposes its private key.
+type recordingSigner struct {
+	inner   crypto.Signer
+	digests [][]byte
+	opts    []crypto.SignerOpts
+}
+
+func (s *recordingSigner) Public() crypto.PublicKey { return s.inner.Public() }
+
+func (s *recordingSigner) Sign(r io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.digests = append(s.digests, append([]byte(nil), digest...))
+	s.opts = append(s.opts, opts)
+	return s.inner.Sign(r, digest, opts)
+}
+
+func TestSignRequestWithSigner(t *testing.T) {
+	newRequest := func(t *testing.T) (*http.Request, *SignatureInputParams) {
+		req, err := http.NewRequest("GET", "https://sage.dev/resource/123", nil)
+		require.NoError(t, err)
+		req.Header.Set("Date", time.Now().Format(http.TimeFormat))
+		return req, &SignatureInputParams{
+			CoveredComponents: []string{`"@method"`, `"date"`, `"@path"`},
+			KeyID:             "kms-key",
+			Created:           time.Now().Unix(),
+		}
+	}
+
+	// signedBase rebuilds the signature base from the Signature-Input that
+	// was sent, which carries the expires the verifier added
+	signedBase := func(t *testing.T, req *http.Request) []byte {
+		signed, err := ParseSignatureInput(req.Header.Get("Signature-Input"))
+		require.NoError(t, err)
+		require.Contains(t, signed, "sig1")
+		base, err := BuildSignatureBase(req, signed["sig1"])
+		require.NoError(t, err)
+		return base
+	}
+
+	t.Run("Ed25519 signer receives the raw signature base", func(t *testing.T) {
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		signer := &recordingSigner{inner: privateKey}
+
+		req, params := newRequest(t)
+		params.Algorithm = "ed25519"
+
+		verifier := NewHTTPVerifier()
+		require.NoError(t, verifier.SignRequestWithSigner(req, "sig1", params, signer))
+
+		base := signedBase(t, req)
+		require.Len(t, signer.digests, 1)
+		assert.Equal(t, base, signer.digests[0])
+		assert.Equal(t, crypto.Hash(0), signer.opts[0].HashFunc())
+
+		assert.NoError(t, verifier.VerifyRequest(req, publicKey, nil))
+	})
+
+	t.Run("ECDSA signer receives a SHA-256 digest", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		signer := &recordingSigner{inner: privateKey}
+
+		req, params := newRequest(t)
+		params.Algorithm = "" // inferred from key type
+
+		verifier := NewHTTPVerifier()
+		require.NoError(t, verifier.SignRequestWithSigner(req, "sig1", params, signer))
+
+		base := signedBase(t, req)
+		digest := sha256.Sum256(base)
+		require.Len(t, signer.digests, 1)
+		assert.Equal(t, digest[:], signer.digests[0])
+		assert.Equal(t, crypto.SHA256, signer.opts[0].HashFunc())
+
+		assert.NoError(t, verifier.VerifyRequest(req, &privateKey.PublicKey, nil))
+	})
+
+	t.Run("Nil signer rejected", func(t *testing.T) {
+		req, params := newRequest(t)
+		err := NewHTTPVerifier().SignRequestWithSigner(req, "sig1", params, nil)
+		assert.Error(t, err)
+	})
+}
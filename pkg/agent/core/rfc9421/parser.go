@@ -19,11 +19,12 @@
 package rfc9421
 
 import (
-	"encoding/base64"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/sage-x-project/sage/internal/sfv"
 )
 
 // SignatureInputParams represents the parameters from a Signature-Input header
@@ -36,8 +37,118 @@ type SignatureInputParams struct {
 	Nonce             string
 }
 
-// ParseSignatureInput parses the Signature-Input header according to RFC 9421
+// ParseSignatureInput parses the Signature-Input header according to RFC 9421.
+// The header is decoded as an RFC 8941 dictionary; values that are not strictly
+// canonical (extra whitespace, upper-case parameter names) are accepted through
+// a lenient fallback for compatibility with older peers.
 func ParseSignatureInput(input string) (map[string]*SignatureInputParams, error) {
+	dict, err := sfv.ParseDictionary(strings.TrimSpace(input))
+	if err != nil {
+		return parseSignatureInputLenient(input)
+	}
+
+	result := make(map[string]*SignatureInputParams, len(dict))
+	for _, member := range dict {
+		params, err := signatureInputFromMember(member.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signature '%s': %w", member.Key, err)
+		}
+		result[member.Key] = params
+	}
+	return result, nil
+}
+
+// signatureInputFromMember converts a structured Signature-Input member.
+func signatureInputFromMember(m sfv.Member) (*SignatureInputParams, error) {
+	il, ok := m.(sfv.InnerList)
+	if !ok {
+		return nil, fmt.Errorf("invalid component list format")
+	}
+
+	params := &SignatureInputParams{}
+	for _, item := range il.Items {
+		if _, ok := item.Value.(string); !ok {
+			return nil, fmt.Errorf("invalid component format: component identifiers must be strings")
+		}
+		comp, err := sfv.MarshalItem(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid component format: %w", err)
+		}
+		params.CoveredComponents = append(params.CoveredComponents, comp)
+	}
+
+	for _, p := range il.Params {
+		var err error
+		switch p.Key {
+		case "keyid":
+			params.KeyID, err = stringParam(p)
+		case "alg":
+			params.Algorithm, err = stringParam(p)
+		case "nonce":
+			params.Nonce, err = stringParam(p)
+		case "created":
+			params.Created, err = intParam(p)
+		case "expires":
+			params.Expires, err = intParam(p)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse parameters: %w", err)
+		}
+	}
+	return params, nil
+}
+
+func stringParam(p sfv.Param) (string, error) {
+	v, ok := p.Value.(string)
+	if !ok {
+		return "", fmt.Errorf("parameter %s must be a string", p.Key)
+	}
+	return v, nil
+}
+
+func intParam(p sfv.Param) (int64, error) {
+	v, ok := p.Value.(int64)
+	if !ok {
+		return 0, fmt.Errorf("invalid %s timestamp: must be an integer", p.Key)
+	}
+	return v, nil
+}
+
+// signatureParamsInnerList builds the structured inner list shared by the
+// Signature-Input header and the "@signature-params" line of the signature base.
+func signatureParamsInnerList(params *SignatureInputParams) (sfv.InnerList, error) {
+	var il sfv.InnerList
+	for _, comp := range params.CoveredComponents {
+		item, err := sfv.ParseItem(strings.TrimSpace(comp))
+		if err != nil {
+			return sfv.InnerList{}, fmt.Errorf("invalid component %s: %w", comp, err)
+		}
+		if _, ok := item.Value.(string); !ok {
+			return sfv.InnerList{}, fmt.Errorf("invalid component %s: must be a quoted string", comp)
+		}
+		il.Items = append(il.Items, item)
+	}
+
+	if params.KeyID != "" {
+		il.Params = append(il.Params, sfv.Param{Key: "keyid", Value: params.KeyID})
+	}
+	if params.Algorithm != "" {
+		il.Params = append(il.Params, sfv.Param{Key: "alg", Value: params.Algorithm})
+	}
+	if params.Created > 0 {
+		il.Params = append(il.Params, sfv.Param{Key: "created", Value: params.Created})
+	}
+	if params.Expires > 0 {
+		il.Params = append(il.Params, sfv.Param{Key: "expires", Value: params.Expires})
+	}
+	if params.Nonce != "" {
+		il.Params = append(il.Params, sfv.Param{Key: "nonce", Value: params.Nonce})
+	}
+	return il, nil
+}
+
+// parseSignatureInputLenient is the pre-RFC 8941 parser kept for non-canonical input.
+func parseSignatureInputLenient(input string) (map[string]*SignatureInputParams, error) {
 	result := make(map[string]*SignatureInputParams)
 
 	// Split by comma to handle multiple signatures
@@ -58,6 +169,10 @@ func ParseSignatureInput(input string) (map[string]*SignatureInputParams, error)
 		sigName := strings.TrimSpace(parts[0])
 		sigValue := strings.TrimSpace(parts[1])
 
+		if _, dup := result[sigName]; dup {
+			return nil, fmt.Errorf("duplicate signature label: %s", sigName)
+		}
+
 		params, err := parseSignatureValue(sigValue)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse signature '%s': %w", sigName, err)
@@ -69,43 +184,38 @@ func ParseSignatureInput(input string) (map[string]*SignatureInputParams, error)
 	return result, nil
 }
 
-// ParseSignature parses the Signature header containing base64-encoded signatures
+// ParseSignature parses the Signature header containing base64-encoded signatures.
+// The header is decoded as an RFC 8941 dictionary whose members must be byte
+// sequences (:base64:) keyed by label; member parameters are accepted and ignored.
+// Returns the raw signature bytes by label.
 func ParseSignature(input string) (map[string][]byte, error) {
-	result := make(map[string][]byte)
-
-	// Split by comma to handle multiple signatures
-	signatures := splitSignatures(input)
+	if strings.TrimSpace(input) == "" {
+		return nil, fmt.Errorf("empty signature header")
+	}
 
-	for _, sig := range signatures {
-		sig = strings.TrimSpace(sig)
-		if sig == "" {
-			continue
-		}
+	dict, err := sfv.ParseDictionary(strings.TrimSpace(input))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature header: %w", err)
+	}
 
-		// Parse signature name and value
-		parts := strings.SplitN(sig, "=", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid signature format: %s", sig)
+	result := make(map[string][]byte, len(dict))
+	for _, member := range dict {
+		item, ok := member.Value.(sfv.Item)
+		if !ok {
+			return nil, fmt.Errorf("invalid byte sequence format for signature '%s'", member.Key)
 		}
-
-		sigName := strings.TrimSpace(parts[0])
-		sigValue := strings.TrimSpace(parts[1])
-
-		// RFC 8941 byte sequence format: :base64:
-		if !strings.HasPrefix(sigValue, ":") || !strings.HasSuffix(sigValue, ":") {
-			return nil, fmt.Errorf("invalid byte sequence format for signature '%s'", sigName)
+		sig, ok := item.Value.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("invalid byte sequence format for signature '%s'", member.Key)
 		}
-
-		// Extract base64 content
-		b64Content := sigValue[1 : len(sigValue)-1]
-
-		// Decode base64
-		decoded, err := base64.StdEncoding.DecodeString(b64Content)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decode base64 for signature '%s': %w", sigName, err)
+		if len(sig) == 0 {
+			return nil, fmt.Errorf("empty byte sequence for signature '%s'", member.Key)
 		}
+		result[member.Key] = sig
+	}
 
-		result[sigName] = decoded
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no signatures found")
 	}
 
 	return result, nil
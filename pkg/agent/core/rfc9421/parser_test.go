@@ -266,6 +266,56 @@ func TestParseSignature(t *testing.T) {
 		assert.NotEmpty(t, result["sig1"])
 		assert.NotEmpty(t, result["sig2"])
 	})
+
+	t.Run("member parameters are ignored", func(t *testing.T) {
+		input := `sig1=:AQIDBAU=:;tag="x", sig-b=:BgcI:`
+
+		result, err := ParseSignature(input)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1, 2, 3, 4, 5}, result["sig1"])
+		assert.Equal(t, []byte{6, 7, 8}, result["sig-b"])
+	})
+
+	t.Run("malformed headers", func(t *testing.T) {
+		malformed := map[string]string{
+			"empty header":         ``,
+			"missing colons":       `sig1=AQIDBAU=`,
+			"unterminated":         `sig1=:AQIDBAU=`,
+			"empty byte sequence":  `sig1=::`,
+			"trailing garbage":     `sig1=:AQIDBAU=:junk`,
+			"duplicate label":      `sig1=:AQIDBAU=:, sig1=:BgcI:`,
+			"invalid label":        `Sig1=:AQIDBAU=:`,
+			"missing label":        `=:AQIDBAU=:`,
+			"no dictionary member": `sig1`,
+		}
+		for name, input := range malformed {
+			t.Run(name, func(t *testing.T) {
+				_, err := ParseSignature(input)
+				assert.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestSignatureInputStructuredRoundTrip(t *testing.T) {
+	params := &SignatureInputParams{
+		CoveredComponents: []string{`"@method"`, `"@query-param";name="id"`, `"content-digest"`},
+		KeyID:             `key "with" quotes`,
+		Algorithm:         "ed25519",
+		Created:           1719234000,
+		Nonce:             "n-1",
+	}
+
+	header, err := NewHTTPVerifier().formatSignatureInput("sig1", params)
+	require.NoError(t, err)
+	assert.Equal(t, `sig1=("@method" "@query-param";name="id" "content-digest");keyid="key \"with\" quotes";alg="ed25519";created=1719234000;nonce="n-1"`, header)
+
+	parsed, err := ParseSignatureInput(header)
+	require.NoError(t, err)
+	assert.Equal(t, params, parsed["sig1"])
+
+	_, err = NewHTTPVerifier().formatSignatureInput("sig1", &SignatureInputParams{CoveredComponents: []string{"@method"}})
+	assert.Error(t, err, "unquoted component identifiers are not valid structured strings")
 }
 
 func TestParseQueryParam(t *testing.T) {
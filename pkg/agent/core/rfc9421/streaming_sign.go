@@ -0,0 +1,104 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package rfc9421
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrBodyNotRewindable is returned by SignStreamingRequest when the request
+// body can be read only once.
+var ErrBodyNotRewindable = errors.New("streaming sign requires a rewindable body (GetBody or io.Seeker)")
+
+// SignStreamingRequest signs a request whose body is too large to hold in
+// memory. The body is read once to compute Content-Digest on the fly, then
+// rewound, and the request is signed with "content-digest" added to the
+// covered components; req.Body is left positioned where it started, ready
+// to be sent.
+//
+// The body must be rewindable: either req.GetBody is set or req.Body
+// implements io.Seeker (an *os.File passed to http.NewRequest does).
+// Otherwise ErrBodyNotRewindable is returned and nothing is modified.
+//
+// Trade-off: the source is read twice, once to hash it and once to send
+// it, in exchange for constant memory. The alternative of sending the
+// digest and signature as HTTP trailers would read the body only once, but
+// the verifier could then not authenticate the request until the whole
+// body had arrived, and many proxies drop trailers, so it is not offered.
+// Bodies that can only be read once must be spooled (e.g. to a temporary
+// file) or signed with SignRequest after setting Content-Digest themselves.
+//
+// digestAlgs selects the Content-Digest algorithms; sha-256 when empty.
+func (v *HTTPVerifier) SignStreamingRequest(req *http.Request, sigName string, params *SignatureInputParams, signer crypto.Signer, digestAlgs ...string) error {
+	if params == nil {
+		return fmt.Errorf("signature parameters are required")
+	}
+	if len(digestAlgs) == 0 {
+		digestAlgs = []string{DigestAlgSHA256}
+	}
+
+	digest, err := streamDigest(req, digestAlgs)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Digest", digest)
+	if !IsComponentCovered(params.CoveredComponents, "content-digest") {
+		params.CoveredComponents = append(params.CoveredComponents, `"content-digest"`)
+	}
+	return v.SignRequestWithSigner(req, sigName, params, signer)
+}
+
+// streamDigest hashes the request body without buffering it and leaves
+// req.Body where it started.
+func streamDigest(req *http.Request, algs []string) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return ComputeContentDigestWithAlgs(nil, algs...)
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", fmt.Errorf("failed to reopen body: %w", err)
+		}
+		defer body.Close()
+		return ComputeContentDigestFromReader(body, algs...)
+	}
+
+	seeker, ok := req.Body.(io.Seeker)
+	if !ok {
+		return "", ErrBodyNotRewindable
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrBodyNotRewindable, err)
+	}
+	digest, err := ComputeContentDigestFromReader(req.Body, algs...)
+	if _, seekErr := seeker.Seek(start, io.SeekStart); seekErr != nil && err == nil {
+		err = fmt.Errorf("failed to rewind body: %w", seekErr)
+	}
+	if err != nil {
+		return "", err
+	}
+	return digest, nil
+}
@@ -0,0 +1,137 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package rfc9421
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// patternBody is a seekable body of size bytes generated on the fly, so a
+// large upload never exists in memory on the client side.
+type patternBody struct {
+	size, off int64
+}
+
+func (b *patternBody) Read(p []byte) (int, error) {
+	if b.off >= b.size {
+		return 0, io.EOF
+	}
+	if rem := b.size - b.off; int64(len(p)) > rem {
+		p = p[:rem]
+	}
+	for i := range p {
+		p[i] = byte((b.off + int64(i)) * 31)
+	}
+	b.off += int64(len(p))
+	return len(p), nil
+}
+
+func (b *patternBody) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		b.off = offset
+	case io.SeekCurrent:
+		b.off += offset
+	case io.SeekEnd:
+		b.off = b.size + offset
+	}
+	return b.off, nil
+}
+
+func (b *patternBody) Close() error { return nil }
+
+func TestSignStreamingRequest(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	verifier := NewHTTPVerifier()
+	newParams := func() *SignatureInputParams {
+		return &SignatureInputParams{
+			CoveredComponents: []string{`"@method"`, `"@path"`},
+			KeyID:             "stream-key",
+			Algorithm:         "ed25519",
+			Created:           time.Now().Unix(),
+		}
+	}
+
+	t.Run("large body signs in bounded memory and verifies server-side", func(t *testing.T) {
+		const size = 32 << 20
+		var verifyErr error
+		var received int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			verifyErr = verifier.VerifyRequest(r, publicKey, nil)
+			body, _ := BufferedBody(r)
+			received = len(body)
+		}))
+		defer srv.Close()
+
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/upload", &patternBody{size: size})
+		require.NoError(t, err)
+		params := newParams()
+
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		require.NoError(t, verifier.SignStreamingRequest(req, "sig1", params, privateKey))
+		runtime.ReadMemStats(&after)
+
+		assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20), "signing must not buffer the body")
+		assert.True(t, IsComponentCovered(params.CoveredComponents, "content-digest"))
+		assert.NotEmpty(t, req.Header.Get("Content-Digest"))
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.NoError(t, verifyErr)
+		assert.Equal(t, size, received)
+	})
+
+	t.Run("GetBody body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("small"))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader("small")), nil }
+		require.NoError(t, verifier.SignStreamingRequest(req, "sig1", newParams(), privateKey, DigestAlgSHA256, DigestAlgSHA512))
+		assert.Contains(t, req.Header.Get("Content-Digest"), DigestAlgSHA512)
+		assert.NoError(t, verifier.VerifyRequest(req, publicKey, nil))
+	})
+
+	t.Run("tampered body fails", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/upload", nil)
+		req.Body = &patternBody{size: 1024}
+		require.NoError(t, verifier.SignStreamingRequest(req, "sig1", newParams(), privateKey))
+		req.Body = &patternBody{size: 1023}
+		assert.Error(t, verifier.VerifyRequest(req, publicKey, nil))
+	})
+
+	t.Run("read-once body is rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/upload", nil)
+		req.Body = io.NopCloser(strings.NewReader("once"))
+		err := verifier.SignStreamingRequest(req, "sig1", newParams(), privateKey)
+		assert.ErrorIs(t, err, ErrBodyNotRewindable)
+		assert.Empty(t, req.Header.Get("Signature"))
+	})
+}
@@ -0,0 +1,154 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package rfc9421
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultVerificationCacheSize bounds a VerificationCache created with a
+// non-positive size.
+const DefaultVerificationCacheSize = 10000
+
+// VerificationCache remembers signatures that verified recently, so a
+// byte-identical request skips the public-key operation when it is sent
+// again, as idempotent clients do on retry.
+//
+// Only the signature check itself is cached. Every other check of
+// HTTPVerifier (created/expires, Date, required components, algorithm
+// allow-list and Content-Digest against the actual body) still runs on each
+// request, and so do any replay or nonce checks of the caller. Entries are
+// keyed on the signature base, the signature, the key ID and the
+// verification key, so a changed body, header or rotated key is verified
+// afresh. A VerificationCache is safe for concurrent use.
+type VerificationCache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]time.Time
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// NewVerificationCache returns a cache whose entries live for ttl, or until
+// the signature expires if that is sooner. It holds at most maxEntries
+// signatures; non-positive means DefaultVerificationCacheSize.
+func NewVerificationCache(ttl time.Duration, maxEntries int) *VerificationCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultVerificationCacheSize
+	}
+	return &VerificationCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[[sha256.Size]byte]time.Time),
+	}
+}
+
+// Stats returns how many verifications were answered from the cache and
+// how many had to check the signature.
+func (c *VerificationCache) Stats() (hits, misses uint64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+// Len returns the number of cached signatures, including expired ones not
+// yet evicted.
+func (c *VerificationCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// lookup reports whether key verified and has not expired.
+func (c *VerificationCache) lookup(key [sha256.Size]byte) bool {
+	c.mu.Lock()
+	expires, ok := c.entries[key]
+	if ok && !c.now().Before(expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return ok
+}
+
+// store records key as verified. sigExpires is the signature's expires
+// parameter (Unix seconds, zero if absent) and caps the entry's lifetime.
+func (c *VerificationCache) store(key [sha256.Size]byte, sigExpires int64) {
+	if c.ttl <= 0 {
+		return
+	}
+	now := c.now()
+	expires := now.Add(c.ttl)
+	if sigExpires > 0 {
+		if limit := time.Unix(sigExpires, 0); limit.Before(expires) {
+			expires = limit
+		}
+	}
+	if !now.Before(expires) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.maxEntries {
+		for k, e := range c.entries {
+			if !now.Before(e) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			return
+		}
+	}
+	c.entries[key] = expires
+}
+
+// verificationCacheKey hashes everything a positive result depends on. It
+// reports false for keys that cannot be serialized, which are not cached.
+func verificationCacheKey(publicKey crypto.PublicKey, params *SignatureInputParams, signatureBase, signature []byte) ([sha256.Size]byte, bool) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return [sha256.Size]byte{}, false
+	}
+	h := sha256.New()
+	for _, part := range [][]byte{signatureBase, signature, []byte(params.KeyID), []byte(params.Algorithm), der} {
+		var n [8]byte
+		binary.BigEndian.PutUint64(n[:], uint64(len(part)))
+		h.Write(n[:])
+		h.Write(part)
+	}
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+	return key, true
+}
@@ -0,0 +1,176 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package rfc9421
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signedRequestFactory signs a POST over body once and returns a function
+// that rebuilds the same request, with the original signature headers, as
+// many times as needed. body and digest override the signed ones when set.
+func signedRequestFactory(tb testing.TB, signer crypto.Signer, alg string, body string) func(body, digest string) *http.Request {
+	tb.Helper()
+	const target = "https://api.example.com/v1/messages"
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader([]byte(body)))
+	require.NoError(tb, err)
+	req.Header.Set("Content-Digest", ComputeContentDigest([]byte(body)))
+	require.NoError(tb, NewHTTPVerifier().SignRequest(req, "sig1", &SignatureInputParams{
+		CoveredComponents: []string{`"@method"`, `"@target-uri"`, `"content-digest"`},
+		KeyID:             "test-key",
+		Algorithm:         alg,
+		Created:           time.Now().Unix(),
+	}, signer))
+	signed := req.Header.Clone()
+
+	return func(b, digest string) *http.Request {
+		if b == "" {
+			b = body
+		}
+		r, err := http.NewRequest(http.MethodPost, target, bytes.NewReader([]byte(b)))
+		require.NoError(tb, err)
+		r.Header = signed.Clone()
+		if digest != "" {
+			r.Header.Set("Content-Digest", digest)
+		}
+		return r
+	}
+}
+
+func TestVerificationCache(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	verifier := NewHTTPVerifier()
+	build := signedRequestFactory(t, privateKey, "ed25519", `{"op":"ping"}`)
+
+	newOpts := func() (*HTTPVerificationOptions, *VerificationCache) {
+		opts := DefaultHTTPVerificationOptions()
+		opts.Cache = NewVerificationCache(time.Minute, 0)
+		return opts, opts.Cache
+	}
+
+	t.Run("repeated request skips re-verification", func(t *testing.T) {
+		opts, cache := newOpts()
+		require.NoError(t, verifier.VerifyRequest(build("", ""), publicKey, opts))
+		require.NoError(t, verifier.VerifyRequest(build("", ""), publicKey, opts))
+
+		hits, misses := cache.Stats()
+		assert.Equal(t, uint64(1), hits)
+		assert.Equal(t, uint64(1), misses)
+		assert.Equal(t, 1, cache.Len())
+	})
+
+	t.Run("changed body forces re-verification", func(t *testing.T) {
+		opts, cache := newOpts()
+		require.NoError(t, verifier.VerifyRequest(build("", ""), publicKey, opts))
+
+		// Same signed headers, different body: the digest check still runs
+		err := verifier.VerifyRequest(build(`{"op":"drop"}`, ""), publicKey, opts)
+		assert.ErrorContains(t, err, "content-digest mismatch")
+
+		// Body and digest both changed: new signature base, checked afresh
+		tampered := `{"op":"drop"}`
+		err = verifier.VerifyRequest(build(tampered, ComputeContentDigest([]byte(tampered))), publicKey, opts)
+		assert.Error(t, err)
+
+		hits, misses := cache.Stats()
+		assert.Zero(t, hits)
+		assert.Equal(t, uint64(2), misses)
+	})
+
+	t.Run("different key is not a hit", func(t *testing.T) {
+		opts, cache := newOpts()
+		require.NoError(t, verifier.VerifyRequest(build("", ""), publicKey, opts))
+
+		otherKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		assert.Error(t, verifier.VerifyRequest(build("", ""), otherKey, opts))
+		hits, _ := cache.Stats()
+		assert.Zero(t, hits)
+	})
+
+	t.Run("freshness checks still apply to hits", func(t *testing.T) {
+		opts, cache := newOpts()
+		require.NoError(t, verifier.VerifyRequest(build("", ""), publicKey, opts))
+
+		strict := *opts
+		strict.MaxAge = time.Nanosecond
+		time.Sleep(1100 * time.Millisecond)
+		assert.ErrorContains(t, verifier.VerifyRequest(build("", ""), publicKey, &strict), "signature expired")
+		hits, _ := cache.Stats()
+		assert.Zero(t, hits)
+	})
+
+	t.Run("entries expire", func(t *testing.T) {
+		opts, cache := newOpts()
+		require.NoError(t, verifier.VerifyRequest(build("", ""), publicKey, opts))
+
+		cache.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		require.NoError(t, verifier.VerifyRequest(build("", ""), publicKey, opts))
+		hits, misses := cache.Stats()
+		assert.Zero(t, hits)
+		assert.Equal(t, uint64(2), misses)
+	})
+
+	t.Run("size is bounded", func(t *testing.T) {
+		cache := NewVerificationCache(time.Minute, 1)
+		cache.store([32]byte{1}, 0)
+		cache.store([32]byte{2}, 0)
+		assert.Equal(t, 1, cache.Len())
+	})
+}
+
+// BenchmarkVerifyRequest_Repeated compares verifying the same signed request
+// with and without a VerificationCache.
+func BenchmarkVerifyRequest_Repeated(b *testing.B) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(b, err)
+	verifier := NewHTTPVerifier()
+	build := signedRequestFactory(b, key, "", `{"op":"ping"}`)
+
+	for _, bc := range []struct {
+		name  string
+		cache *VerificationCache
+	}{
+		{"uncached", nil},
+		{"cached", NewVerificationCache(time.Minute, 0)},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			opts := DefaultHTTPVerificationOptions()
+			opts.Cache = bc.cache
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := verifier.VerifyRequest(build("", ""), &key.PublicKey, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
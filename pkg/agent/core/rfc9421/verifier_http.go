@@ -25,90 +25,184 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
-	"encoding/base64"
+	"encoding/asn1"
+	"errors"
 	"fmt"
 	"math/big"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
+	"github.com/sage-x-project/sage/internal/sfv"
 	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
 	_ "github.com/sage-x-project/sage/pkg/agent/crypto/keys" // Import to register algorithms
+	"github.com/sage-x-project/sage/pkg/sagechaos"
 )
 
+var (
+	// ErrAlgorithmNotAllowed is returned when a signature's alg is not in
+	// HTTPVerificationOptions.AllowedAlgorithms.
+	ErrAlgorithmNotAllowed = errors.New("signature algorithm not allowed")
+
+	// ErrAlgorithmMismatch is returned when a signature's alg does not match
+	// the type of the verification key.
+	ErrAlgorithmMismatch = errors.New("signature algorithm does not match key type")
+)
+
+// ForwardedHostHeader carries the client-facing host set by a reverse proxy
+const ForwardedHostHeader = "X-Forwarded-Host"
+
+// DefaultSignatureTTL is the lifetime signers give a signature when it sets
+// created but not expires. It matches the default verification MaxAge.
+const DefaultSignatureTTL = 5 * time.Minute
+
 // HTTPVerifier provides RFC-9421 HTTP message signature verification
 type HTTPVerifier struct {
-	canonicalizer *Canonicalizer
+	signatureTTL time.Duration
 }
 
 // NewHTTPVerifier creates a new HTTP signature verifier
 func NewHTTPVerifier() *HTTPVerifier {
-	return &HTTPVerifier{
-		canonicalizer: NewCanonicalizer(),
-	}
+	return &HTTPVerifier{signatureTTL: DefaultSignatureTTL}
+}
+
+// WithSignatureTTL sets how long signatures made by this verifier stay
+// valid: a signature with created but no expires is signed with expires =
+// created + ttl, which verifiers enforce even when it is shorter than their
+// MaxAge. The params passed in are not modified. Zero or negative leaves
+// expires unset.
+func (v *HTTPVerifier) WithSignatureTTL(ttl time.Duration) *HTTPVerifier {
+	v.signatureTTL = ttl
+	return v
 }
 
-// SignRequest signs an HTTP request according to RFC 9421
+// SignRequest signs an HTTP request according to RFC 9421.
+// It is equivalent to SignRequestWithSigner; in-memory keys such as
+// ed25519.PrivateKey and *ecdsa.PrivateKey already implement crypto.Signer.
 func (v *HTTPVerifier) SignRequest(req *http.Request, sigName string, params *SignatureInputParams, privateKey crypto.Signer) error {
+	return v.SignRequestWithSigner(req, sigName, params, privateKey)
+}
+
+// SignRequestWithSigner signs an HTTP request according to RFC 9421 using any
+// crypto.Signer, so keys held by a remote KMS or HSM never have to be exported.
+// The signing scheme is chosen from signer.Public():
+//   - Ed25519 signers are handed the raw signature base (crypto.Hash(0))
+//   - ECDSA signers are handed a SHA-256 digest and must return ASN.1 DER,
+//     which is re-encoded as fixed-size r||s
+//   - any other signer is handed a SHA-256 digest with crypto.SHA256
+func (v *HTTPVerifier) SignRequestWithSigner(req *http.Request, sigName string, params *SignatureInputParams, signer crypto.Signer) error {
+	if signer == nil {
+		return fmt.Errorf("signer is required")
+	}
+	if params == nil {
+		return fmt.Errorf("signature parameters are required")
+	}
+
+	// Bound the signature's lifetime unless the caller already did, on a
+	// copy so params can be reused for further requests
+	if params.Created > 0 && params.Expires == 0 && v.signatureTTL > 0 {
+		bounded := *params
+		bounded.Expires = params.Created + int64(v.signatureTTL/time.Second)
+		params = &bounded
+	}
+
 	// Build signature base
-	signatureBase, err := v.canonicalizer.BuildSignatureBase(req, sigName, params)
+	signatureBase, err := BuildSignatureBase(req, params)
 	if err != nil {
 		return fmt.Errorf("failed to build signature base: %w", err)
 	}
 
-	// Sign the signature base differently based on key type
-	var signature []byte
+	signature, err := signWithSigner(signer, signatureBase)
+	if err != nil {
+		return err
+	}
+
+	// Set Signature-Input header
+	inputHeader, err := v.formatSignatureInput(sigName, params)
+	if err != nil {
+		return fmt.Errorf("failed to format Signature-Input: %w", err)
+	}
+	req.Header.Set("Signature-Input", inputHeader)
+
+	// Set Signature header
+	sigHeader, err := sfv.MarshalDictionary(sfv.Dictionary{
+		{Key: sigName, Value: sfv.Item{Value: signature}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to format Signature: %w", err)
+	}
+	req.Header.Set("Signature", sigHeader)
+
+	return nil
+}
 
-	switch key := privateKey.(type) {
-	case ed25519.PrivateKey:
+// signWithSigner signs the signature base with signer, dispatching on the
+// signer's public key type.
+func signWithSigner(signer crypto.Signer, signatureBase []byte) ([]byte, error) {
+	switch pub := signer.Public().(type) {
+	case ed25519.PublicKey:
 		// Ed25519 signs the message directly, not a hash
-		signature = ed25519.Sign(key, []byte(signatureBase))
+		signature, err := signer.Sign(rand.Reader, signatureBase, crypto.Hash(0))
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign with Ed25519: %w", err)
+		}
+		return signature, nil
 
-	case *ecdsa.PrivateKey:
+	case *ecdsa.PublicKey:
 		// ECDSA requires hashed signing
-		h := sha256.New()
-		h.Write([]byte(signatureBase))
-		digest := h.Sum(nil)
-
-		r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+		digest := sha256.Sum256(signatureBase)
+		der, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
 		if err != nil {
-			return fmt.Errorf("failed to sign with ECDSA: %w", err)
+			return nil, fmt.Errorf("failed to sign with ECDSA: %w", err)
 		}
 
-		// Convert to fixed-size byte arrays (P-256 = 32 bytes each)
-		signature = make([]byte, 64)
-		rBytes := r.Bytes()
-		sBytes := s.Bytes()
+		var sig struct{ R, S *big.Int }
+		if rest, err := asn1.Unmarshal(der, &sig); err != nil || len(rest) > 0 {
+			return nil, fmt.Errorf("ECDSA signer returned a malformed ASN.1 signature")
+		}
 
-		// Pad with zeros if necessary
-		copy(signature[32-len(rBytes):32], rBytes)
-		copy(signature[64-len(sBytes):64], sBytes)
+		// Convert to fixed-size byte arrays (P-256 = 32 bytes each)
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if sig.R.Sign() <= 0 || sig.S.Sign() <= 0 || len(sig.R.Bytes()) > size || len(sig.S.Bytes()) > size {
+			return nil, fmt.Errorf("ECDSA signer returned an out-of-range signature")
+		}
+		signature := make([]byte, 2*size)
+		sig.R.FillBytes(signature[:size])
+		sig.S.FillBytes(signature[size:])
+		return signature, nil
 
 	default:
 		// Other algorithms use the standard crypto.Signer interface
-		h := sha256.New()
-		h.Write([]byte(signatureBase))
-		digest := h.Sum(nil)
-
-		signature, err = privateKey.Sign(rand.Reader, digest, crypto.SHA256)
+		digest := sha256.Sum256(signatureBase)
+		signature, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
 		if err != nil {
-			return fmt.Errorf("failed to sign: %w", err)
+			return nil, fmt.Errorf("failed to sign: %w", err)
 		}
+		return signature, nil
 	}
+}
 
-	// Set Signature-Input header
-	inputHeader := v.formatSignatureInput(sigName, params)
-	req.Header.Set("Signature-Input", inputHeader)
+// VerifyRequest verifies an HTTP request signature
+func (v *HTTPVerifier) VerifyRequest(req *http.Request, publicKey crypto.PublicKey, opts *HTTPVerificationOptions) error {
+	return v.verifyRequest(req, func(string) (crypto.PublicKey, error) { return publicKey, nil }, opts)
+}
 
-	// Set Signature header
-	sigHeader := fmt.Sprintf("%s=:%s:", sigName, base64.StdEncoding.EncodeToString(signature))
-	req.Header.Set("Signature", sigHeader)
+// KeySelector returns the public key named by a signature's keyid parameter
+// (which may be empty). It should fail for unknown or revoked keys.
+type KeySelector func(keyID string) (crypto.PublicKey, error)
 
-	return nil
+// VerifyRequestWithKeySelector verifies an HTTP request signature against the
+// key selectKey returns for the signature's keyid. This lets a verifier accept
+// any of an agent's currently valid keys, e.g. both keys during a rollover.
+func (v *HTTPVerifier) VerifyRequestWithKeySelector(req *http.Request, selectKey KeySelector, opts *HTTPVerificationOptions) error {
+	if selectKey == nil {
+		return fmt.Errorf("key selector is required")
+	}
+	return v.verifyRequest(req, selectKey, opts)
 }
 
-// VerifyRequest verifies an HTTP request signature
-func (v *HTTPVerifier) VerifyRequest(req *http.Request, publicKey crypto.PublicKey, opts *HTTPVerificationOptions) error {
+func (v *HTTPVerifier) verifyRequest(req *http.Request, selectKey KeySelector, opts *HTTPVerificationOptions) error {
 	if opts == nil {
 		opts = DefaultHTTPVerificationOptions()
 	}
@@ -157,6 +251,11 @@ func (v *HTTPVerifier) VerifyRequest(req *http.Request, publicKey crypto.PublicK
 		return fmt.Errorf("signature '%s' not found in Signature header", sigName)
 	}
 
+	// Bound the work done per signature before touching the body or base
+	if limit := opts.maxCoveredComponents(); limit > 0 && len(params.CoveredComponents) > limit {
+		return &TooManyComponentsError{Count: len(params.CoveredComponents), Max: limit}
+	}
+
 	// Check created/expires if present
 	now := time.Now().Unix()
 	if params.Created > 0 && opts.MaxAge > 0 {
@@ -170,29 +269,176 @@ func (v *HTTPVerifier) VerifyRequest(req *http.Request, publicKey crypto.PublicK
 		return fmt.Errorf("signature expired at %d (now %d)", params.Expires, now)
 	}
 
+	if err := checkDate(req, params, opts, time.Now()); err != nil {
+		return err
+	}
+
+	for _, required := range opts.RequiredComponents {
+		if !coversComponent(params, required) {
+			return fmt.Errorf("signature does not cover required component %s", required)
+		}
+	}
+
+	base := req
+	if opts.TargetURI != "" {
+		if !coversComponent(params, "@target-uri") {
+			return fmt.Errorf("signature does not cover required component @target-uri")
+		}
+		base, err = withTargetURI(req, opts.TargetURI)
+		if err != nil {
+			return err
+		}
+	} else if authority, err := externalAuthority(req, opts); err != nil {
+		return err
+	} else if authority != "" {
+		base = withAuthority(req, authority)
+	}
+
+	publicKey, err := selectKey(params.KeyID)
+	if err != nil {
+		return fmt.Errorf("failed to select verification key: %w", err)
+	}
+
+	if err := checkAllowedAlgorithm(publicKey, params.Algorithm, opts.AllowedAlgorithms); err != nil {
+		return err
+	}
+
 	// Validate body integrity if Content-Digest is covered by signature
 	// This prevents body tampering attacks where the body is modified but
 	// the Content-Digest header remains unchanged (PR #118 security fix)
-	bodyValidator := NewBodyIntegrityValidator()
+	bodyValidator := NewBodyIntegrityValidator(opts.AllowedDigestAlgs...)
 	if err := bodyValidator.ValidateContentDigest(req, params.CoveredComponents); err != nil {
 		return fmt.Errorf("body integrity validation failed: %w", err)
 	}
 
 	// Build signature base
-	signatureBase, err := v.canonicalizer.BuildSignatureBase(req, sigName, params)
+	signatureBase, err := BuildSignatureBase(base, params)
 	if err != nil {
 		return fmt.Errorf("failed to build signature base: %w", err)
 	}
 
-	// Verify signature
-	return v.verifySignature(publicKey, []byte(signatureBase), signature, params.Algorithm)
+	// Verify signature, unless this exact signature verified recently
+	var cacheKey [sha256.Size]byte
+	cacheable := false
+	if opts.Cache != nil {
+		if cacheKey, cacheable = verificationCacheKey(publicKey, params, signatureBase, signature); cacheable && opts.Cache.lookup(cacheKey) {
+			return nil
+		}
+	}
+	if err := v.verifySignature(publicKey, signatureBase, signature, params.Algorithm); err != nil {
+		return err
+	}
+	if cacheable {
+		opts.Cache.store(cacheKey, params.Expires)
+	}
+	return nil
+}
+
+// checkAllowedAlgorithm rejects alg unless it is in allowed. An empty alg is
+// checked as the algorithm implied by publicKey, so omitting alg cannot be
+// used to sidestep the allow-list. An empty allowed list permits any alg.
+func checkAllowedAlgorithm(publicKey crypto.PublicKey, alg string, allowed []string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	if alg == "" {
+		keyType, err := sagecrypto.GetKeyTypeFromPublicKey(publicKey)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrAlgorithmNotAllowed, err)
+		}
+		if alg, err = sagecrypto.GetRFC9421AlgorithmName(keyType); err != nil {
+			return fmt.Errorf("%w: %w", ErrAlgorithmNotAllowed, err)
+		}
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(a, alg) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrAlgorithmNotAllowed, alg)
+}
+
+// coversComponent reports whether params covers the component named name
+func coversComponent(params *SignatureInputParams, name string) bool {
+	name = strings.Trim(strings.TrimSpace(name), `"`)
+	for _, c := range params.CoveredComponents {
+		if strings.Trim(strings.TrimSpace(c), `"`) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// withTargetURI returns a shallow copy of req addressed to targetURI, which
+// must be an absolute URI without a path, query or fragment
+func withTargetURI(req *http.Request, targetURI string) (*http.Request, error) {
+	u, err := url.Parse(targetURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target URI: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid target URI %q: scheme and host are required", targetURI)
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("invalid target URI %q: must not include a path, query or fragment", targetURI)
+	}
+
+	out := req.Clone(req.Context())
+	out.URL.Scheme = strings.ToLower(u.Scheme)
+	out.URL.Host = u.Host
+	out.Host = u.Host
+	return out, nil
+}
+
+// externalAuthority returns the authority clients used to reach this server,
+// from opts.Authority or, if enabled, the X-Forwarded-Host header. It
+// returns "" when neither applies and req.Host should be used.
+func externalAuthority(req *http.Request, opts *HTTPVerificationOptions) (string, error) {
+	if opts.Authority != "" {
+		return validAuthority(opts.Authority)
+	}
+	if !opts.AuthorityFromForwardedHost {
+		return "", nil
+	}
+	values := req.Header.Values(ForwardedHostHeader)
+	if len(values) == 0 {
+		return "", nil
+	}
+	if len(values) > 1 || strings.Contains(values[0], ",") {
+		return "", fmt.Errorf("ambiguous %s: proxy must replace, not append, the header", ForwardedHostHeader)
+	}
+	return validAuthority(values[0])
+}
+
+// validAuthority checks that authority is a bare host[:port].
+func validAuthority(authority string) (string, error) {
+	authority = strings.TrimSpace(authority)
+	u, err := url.Parse("//" + authority)
+	if err != nil || authority == "" || u.Host != authority || u.User != nil {
+		return "", fmt.Errorf("invalid authority %q: expected host[:port]", authority)
+	}
+	return authority, nil
+}
+
+// withAuthority returns a shallow copy of req whose Host, and therefore
+// @authority, host and the authority of @target-uri, is authority
+func withAuthority(req *http.Request, authority string) *http.Request {
+	out := req.Clone(req.Context())
+	out.Header.Del("Host")
+	out.URL.Host = authority
+	out.Host = authority
+	return out
 }
 
 // verifySignature verifies the actual cryptographic signature
 func (v *HTTPVerifier) verifySignature(publicKey crypto.PublicKey, message, signature []byte, algorithm string) error {
+	if err := sagechaos.Fail(sagechaos.SignatureVerify); err != nil {
+		return err
+	}
+
 	// Validate algorithm compatibility with public key using the registry
 	if err := sagecrypto.ValidateAlgorithmForPublicKey(publicKey, algorithm); err != nil {
-		return fmt.Errorf("algorithm validation failed: %w", err)
+		return fmt.Errorf("algorithm validation failed: %w: %w", ErrAlgorithmMismatch, err)
 	}
 
 	// Hash the message
@@ -232,29 +478,12 @@ func (v *HTTPVerifier) verifySignature(publicKey crypto.PublicKey, message, sign
 }
 
 // formatSignatureInput formats the Signature-Input header value
-func (v *HTTPVerifier) formatSignatureInput(sigName string, params *SignatureInputParams) string {
-	components := make([]string, len(params.CoveredComponents))
-	copy(components, params.CoveredComponents)
-
-	result := fmt.Sprintf("%s=(%s)", sigName, strings.Join(components, " "))
-
-	if params.KeyID != "" {
-		result += fmt.Sprintf(`;keyid="%s"`, params.KeyID)
-	}
-	if params.Algorithm != "" {
-		result += fmt.Sprintf(`;alg="%s"`, params.Algorithm)
-	}
-	if params.Created > 0 {
-		result += fmt.Sprintf(`;created=%d`, params.Created)
-	}
-	if params.Expires > 0 {
-		result += fmt.Sprintf(`;expires=%d`, params.Expires)
-	}
-	if params.Nonce != "" {
-		result += fmt.Sprintf(`;nonce="%s"`, params.Nonce)
+func (v *HTTPVerifier) formatSignatureInput(sigName string, params *SignatureInputParams) (string, error) {
+	il, err := signatureParamsInnerList(params)
+	if err != nil {
+		return "", err
 	}
-
-	return result
+	return sfv.MarshalDictionary(sfv.Dictionary{{Key: sigName, Value: il}})
 }
 
 // HTTPVerificationOptions contains options for HTTP signature verification
@@ -262,18 +491,96 @@ type HTTPVerificationOptions struct {
 	// SignatureName specifies which signature to verify (if multiple exist)
 	SignatureName string
 
-	// MaxAge specifies the maximum age for created timestamps
+	// MaxAge specifies the maximum age for created timestamps and, when the
+	// signature covers "date", for the Date header
 	MaxAge time.Duration
 
+	// MaxClockSkew is how far in the future a covered Date header may be.
+	// Zero means DefaultMaxClockSkew; negative disables the check.
+	MaxClockSkew time.Duration
+
 	// RequiredComponents specifies components that must be included
 	RequiredComponents []string
+
+	// AllowedDigestAlgs lists the Content-Digest algorithms the verifier trusts
+	// (e.g. "sha-256", "sha-512"). Empty means DefaultDigestAlgorithms.
+	AllowedDigestAlgs []string
+
+	// MaxCoveredComponents caps the number of covered components a signature
+	// may list. Zero means DefaultMaxCoveredComponents; negative disables the cap.
+	MaxCoveredComponents int
+
+	// TargetURI is the scheme and authority clients use to reach this server,
+	// e.g. "https://api.example.com". When set, the signature must cover
+	// "@target-uri", and "@target-uri", "@authority" and "@scheme" are derived
+	// from TargetURI and the request target rather than from the request's
+	// Host and TLS state. Behind a proxy this still binds the signature to the
+	// public URI, and a request signed for another host, scheme or path is
+	// rejected.
+	TargetURI string
+
+	// Authority is the host[:port] clients use to reach this server, e.g.
+	// "api.example.com". When set, "@authority", the "host" field and the
+	// authority of "@target-uri" are reconstructed from it instead of from
+	// the request's Host, which behind a reverse proxy names the backend.
+	// Unlike TargetURI it does not require any component to be covered.
+	// TargetURI takes precedence when both are set.
+	Authority string
+
+	// AuthorityFromForwardedHost takes the authority from the
+	// X-Forwarded-Host header when Authority is empty. Enable it only when
+	// every request passes through a proxy that overwrites that header;
+	// a header with several values is rejected as ambiguous.
+	AuthorityFromForwardedHost bool
+
+	// AllowedAlgorithms lists the signature algorithms the verifier accepts
+	// (e.g. "ed25519"). A signature declaring any other alg, or omitting alg
+	// for a key of another type, fails with ErrAlgorithmNotAllowed. Empty
+	// accepts every algorithm the key type supports.
+	AllowedAlgorithms []string
+
+	// Cache, if set, skips the signature check for a signature that
+	// verified recently. All other checks still run; see VerificationCache.
+	Cache *VerificationCache
+}
+
+// DefaultMaxCoveredComponents is the covered-components cap applied when
+// HTTPVerificationOptions.MaxCoveredComponents is left at zero.
+const DefaultMaxCoveredComponents = 32
+
+// TooManyComponentsError is returned when a signature covers more components
+// than the verifier is configured to accept.
+type TooManyComponentsError struct {
+	Count int
+	Max   int
+}
+
+func (e *TooManyComponentsError) Error() string {
+	return fmt.Sprintf("signature covers %d components (max %d)", e.Count, e.Max)
 }
 
 // DefaultHTTPVerificationOptions returns default verification options
 func DefaultHTTPVerificationOptions() *HTTPVerificationOptions {
 	return &HTTPVerificationOptions{
-		MaxAge: 5 * time.Minute,
+		MaxAge:               5 * time.Minute,
+		MaxCoveredComponents: DefaultMaxCoveredComponents,
+	}
+}
+
+// maxCoveredComponents resolves the effective covered-components cap.
+func (o *HTTPVerificationOptions) maxCoveredComponents() int {
+	if o.MaxCoveredComponents == 0 {
+		return DefaultMaxCoveredComponents
+	}
+	return o.MaxCoveredComponents
+}
+
+// maxClockSkew resolves the effective clock-skew allowance.
+func (o *HTTPVerificationOptions) maxClockSkew() time.Duration {
+	if o.MaxClockSkew == 0 {
+		return DefaultMaxClockSkew
 	}
+	return o.MaxClockSkew
 }
 
 // parseECDSASignature parses an ECDSA signature
@@ -0,0 +1,331 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package rfc9421
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyRequest_TargetURI(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	verifier := NewHTTPVerifier()
+
+	// sign returns a client request for target signed over the given components
+	sign := func(t *testing.T, target string, components ...string) *http.Request {
+		req, err := http.NewRequest(http.MethodPost, target, nil)
+		require.NoError(t, err)
+		params := &SignatureInputParams{
+			CoveredComponents: components,
+			KeyID:             "test-key",
+			Algorithm:         "ed25519",
+			Created:           time.Now().Unix(),
+		}
+		require.NoError(t, verifier.SignRequest(req, "sig1", params, privateKey))
+		return req
+	}
+
+	// received rebuilds req as a server behind a proxy sees it: the request
+	// target is kept but Host names the internal backend and TLS is gone
+	received := func(req *http.Request, path string) *http.Request {
+		if path == "" {
+			path = req.URL.RequestURI()
+		}
+		in := httptest.NewRequest(req.Method, path, nil)
+		in.Host = "backend.internal:8080"
+		in.Header = req.Header.Clone()
+		return in
+	}
+
+	const signedFor = "https://api.example.com/v1/messages?limit=10"
+
+	t.Run("Verifies against the server's public URI", func(t *testing.T) {
+		req := sign(t, signedFor, `"@method"`, `"@target-uri"`)
+		opts := DefaultHTTPVerificationOptions()
+		opts.TargetURI = "https://api.example.com"
+		assert.NoError(t, verifier.VerifyRequest(received(req, ""), publicKey, opts))
+
+		// Without TargetURI the proxied request no longer matches
+		assert.Error(t, verifier.VerifyRequest(received(req, ""), publicKey, nil))
+	})
+
+	t.Run("Rejects replay against another target", func(t *testing.T) {
+		req := sign(t, signedFor, `"@method"`, `"@target-uri"`)
+
+		for name, tc := range map[string]struct{ targetURI, path string }{
+			"other host":   {"https://other.example.com", ""},
+			"other scheme": {"http://api.example.com", ""},
+			"other path":   {"https://api.example.com", "/v1/admin?limit=10"},
+			"other query":  {"https://api.example.com", "/v1/messages?limit=1000"},
+		} {
+			opts := DefaultHTTPVerificationOptions()
+			opts.TargetURI = tc.targetURI
+			err := verifier.VerifyRequest(received(req, tc.path), publicKey, opts)
+			assert.Error(t, err, name)
+		}
+	})
+
+	t.Run("Requires @target-uri to be covered", func(t *testing.T) {
+		req := sign(t, signedFor, `"@method"`, `"@path"`)
+		opts := DefaultHTTPVerificationOptions()
+		opts.TargetURI = "https://api.example.com"
+		err := verifier.VerifyRequest(received(req, ""), publicKey, opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "@target-uri")
+	})
+
+	t.Run("Rejects malformed TargetURI", func(t *testing.T) {
+		req := sign(t, signedFor, `"@target-uri"`)
+		for _, target := range []string{"api.example.com", "https://api.example.com/v1"} {
+			opts := DefaultHTTPVerificationOptions()
+			opts.TargetURI = target
+			assert.Error(t, verifier.VerifyRequest(received(req, ""), publicKey, opts), target)
+		}
+	})
+
+	t.Run("Enforces RequiredComponents", func(t *testing.T) {
+		req := sign(t, signedFor, `"@method"`, `"@target-uri"`)
+		opts := DefaultHTTPVerificationOptions()
+		opts.RequiredComponents = []string{"@method", "content-digest"}
+		err := verifier.VerifyRequest(req, publicKey, opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "content-digest")
+
+		opts.RequiredComponents = []string{`"@method"`}
+		assert.NoError(t, verifier.VerifyRequest(req, publicKey, opts))
+	})
+}
+
+func TestVerifyRequest_Authority(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	verifier := NewHTTPVerifier()
+
+	// sign returns a client request to api.example.com signed over components
+	sign := func(t *testing.T, components ...string) *http.Request {
+		req, err := http.NewRequest(http.MethodPost, "https://api.example.com/v1/messages", nil)
+		require.NoError(t, err)
+		params := &SignatureInputParams{
+			CoveredComponents: components,
+			KeyID:             "test-key",
+			Algorithm:         "ed25519",
+			Created:           time.Now().Unix(),
+		}
+		require.NoError(t, verifier.SignRequest(req, "sig1", params, privateKey))
+		return req
+	}
+
+	// proxied rebuilds req as the backend sees it behind a reverse proxy
+	proxied := func(req *http.Request, forwardedHost ...string) *http.Request {
+		in := httptest.NewRequest(req.Method, req.URL.RequestURI(), nil)
+		in.Host = "backend.internal:8080"
+		in.Header = req.Header.Clone()
+		for _, h := range forwardedHost {
+			in.Header.Add(ForwardedHostHeader, h)
+		}
+		return in
+	}
+
+	for _, component := range []string{`"@authority"`, `"host"`} {
+		t.Run("Configured authority for "+component, func(t *testing.T) {
+			req := sign(t, `"@method"`, component)
+			assert.Error(t, verifier.VerifyRequest(proxied(req), publicKey, nil), "internal host must not match")
+
+			opts := DefaultHTTPVerificationOptions()
+			opts.Authority = "api.example.com"
+			assert.NoError(t, verifier.VerifyRequest(proxied(req), publicKey, opts))
+
+			opts.Authority = "other.example.com"
+			assert.Error(t, verifier.VerifyRequest(proxied(req), publicKey, opts))
+		})
+	}
+
+	t.Run("Authority also rebuilds @target-uri", func(t *testing.T) {
+		req := sign(t, `"@target-uri"`)
+		// The proxy terminated TLS, so the scheme must come from TargetURI
+		opts := DefaultHTTPVerificationOptions()
+		opts.Authority = "api.example.com"
+		in := proxied(req)
+		in.URL.Scheme = "https"
+		assert.NoError(t, verifier.VerifyRequest(in, publicKey, opts))
+	})
+
+	t.Run("Authority from X-Forwarded-Host", func(t *testing.T) {
+		req := sign(t, `"@method"`, `"@authority"`)
+		opts := DefaultHTTPVerificationOptions()
+		opts.AuthorityFromForwardedHost = true
+		assert.NoError(t, verifier.VerifyRequest(proxied(req, "api.example.com"), publicKey, opts))
+		assert.Error(t, verifier.VerifyRequest(proxied(req, "evil.example.com"), publicKey, opts))
+
+		err := verifier.VerifyRequest(proxied(req, "evil.example.com", "api.example.com"), publicKey, opts)
+		assert.ErrorContains(t, err, "ambiguous")
+		err = verifier.VerifyRequest(proxied(req, "evil.example.com, api.example.com"), publicKey, opts)
+		assert.ErrorContains(t, err, "ambiguous")
+
+		// The header is ignored unless enabled
+		assert.Error(t, verifier.VerifyRequest(proxied(req, "api.example.com"), publicKey, nil))
+		// A configured Authority wins over the header
+		opts.Authority = "api.example.com"
+		assert.NoError(t, verifier.VerifyRequest(proxied(req, "evil.example.com"), publicKey, opts))
+	})
+
+	t.Run("Rejects malformed authority", func(t *testing.T) {
+		req := sign(t, `"@authority"`)
+		for _, authority := range []string{"https://api.example.com", "api.example.com/v1", "user@api.example.com"} {
+			opts := DefaultHTTPVerificationOptions()
+			opts.Authority = authority
+			assert.ErrorContains(t, verifier.VerifyRequest(proxied(req), publicKey, opts), "invalid authority", authority)
+		}
+	})
+}
+
+func TestVerifyRequest_AllowedAlgorithms(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	verifier := NewHTTPVerifier()
+
+	// sign returns a request signed with privateKey that declares alg
+	sign := func(t *testing.T, alg string) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, "https://api.example.com/v1/messages", nil)
+		require.NoError(t, err)
+		params := &SignatureInputParams{
+			CoveredComponents: []string{`"@method"`, `"@target-uri"`},
+			KeyID:             "test-key",
+			Algorithm:         alg,
+			Created:           time.Now().Unix(),
+		}
+		require.NoError(t, verifier.SignRequest(req, "sig1", params, privateKey))
+		return req
+	}
+
+	opts := DefaultHTTPVerificationOptions()
+	opts.AllowedAlgorithms = []string{"ed25519"}
+
+	t.Run("Accepts an allowed algorithm", func(t *testing.T) {
+		assert.NoError(t, verifier.VerifyRequest(sign(t, "ed25519"), publicKey, opts))
+		assert.NoError(t, verifier.VerifyRequest(sign(t, ""), publicKey, opts))
+	})
+
+	t.Run("Rejects a disallowed algorithm", func(t *testing.T) {
+		err := verifier.VerifyRequest(sign(t, "hmac-sha256"), publicKey, opts)
+		assert.ErrorIs(t, err, ErrAlgorithmNotAllowed)
+
+		err = verifier.VerifyRequest(sign(t, "es256k"), publicKey, opts)
+		assert.ErrorIs(t, err, ErrAlgorithmNotAllowed)
+	})
+
+	t.Run("Rejects an omitted alg for a disallowed key type", func(t *testing.T) {
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodGet, "https://api.example.com/v1/messages", nil)
+		require.NoError(t, err)
+		params := &SignatureInputParams{
+			CoveredComponents: []string{`"@method"`},
+			Created:           time.Now().Unix(),
+		}
+		require.NoError(t, verifier.SignRequest(req, "sig1", params, ecKey))
+
+		assert.NoError(t, verifier.VerifyRequest(req, &ecKey.PublicKey, nil))
+		err = verifier.VerifyRequest(req, &ecKey.PublicKey, opts)
+		assert.ErrorIs(t, err, ErrAlgorithmNotAllowed)
+	})
+
+	t.Run("Rejects alg that does not match the key", func(t *testing.T) {
+		err := verifier.VerifyRequest(sign(t, "ecdsa-p256-sha256"), publicKey, nil)
+		assert.ErrorIs(t, err, ErrAlgorithmMismatch)
+
+		mixed := DefaultHTTPVerificationOptions()
+		mixed.AllowedAlgorithms = []string{"ed25519", "ecdsa-p256-sha256"}
+		err = verifier.VerifyRequest(sign(t, "ecdsa-p256-sha256"), publicKey, mixed)
+		assert.ErrorIs(t, err, ErrAlgorithmMismatch)
+	})
+}
+
+func TestSignRequest_SignatureTTL(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	// sign returns the request and the params it was signed with, as parsed
+	// back from Signature-Input
+	sign := func(t *testing.T, verifier *HTTPVerifier, created time.Time) (*http.Request, *SignatureInputParams) {
+		req, err := http.NewRequest(http.MethodGet, "https://api.example.com/v1", nil)
+		require.NoError(t, err)
+		params := &SignatureInputParams{
+			CoveredComponents: []string{`"@method"`, `"@path"`},
+			KeyID:             "test-key",
+			Algorithm:         "ed25519",
+			Created:           created.Unix(),
+		}
+		require.NoError(t, verifier.SignRequest(req, "sig1", params, privateKey))
+		assert.Zero(t, params.Expires, "caller's params must not be modified")
+
+		signed, err := ParseSignatureInput(req.Header.Get("Signature-Input"))
+		require.NoError(t, err)
+		require.Contains(t, signed, "sig1")
+		return req, signed["sig1"]
+	}
+
+	t.Run("Sets expires from the default TTL", func(t *testing.T) {
+		now := time.Now()
+		req, params := sign(t, NewHTTPVerifier(), now)
+		assert.Equal(t, now.Add(DefaultSignatureTTL).Unix(), params.Expires)
+		assert.Contains(t, req.Header.Get("Signature-Input"), "expires=")
+		assert.NoError(t, NewHTTPVerifier().VerifyRequest(req, publicKey, nil))
+	})
+
+	t.Run("Rejects a signature past its own expiry within MaxAge", func(t *testing.T) {
+		signer := NewHTTPVerifier().WithSignatureTTL(time.Second)
+		req, _ := sign(t, signer, time.Now().Add(-30*time.Second))
+
+		opts := DefaultHTTPVerificationOptions()
+		require.Greater(t, opts.MaxAge, 30*time.Second)
+		err := NewHTTPVerifier().VerifyRequest(req, publicKey, opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "signature expired at")
+	})
+
+	t.Run("Keeps an explicit expires", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "https://api.example.com/v1", nil)
+		require.NoError(t, err)
+		created := time.Now().Unix()
+		params := &SignatureInputParams{
+			CoveredComponents: []string{`"@method"`},
+			Created:           created,
+			Expires:           created + 60,
+		}
+		require.NoError(t, NewHTTPVerifier().SignRequest(req, "sig1", params, privateKey))
+		assert.Equal(t, created+60, params.Expires)
+	})
+
+	t.Run("Zero TTL leaves expires unset", func(t *testing.T) {
+		req, params := sign(t, NewHTTPVerifier().WithSignatureTTL(0), time.Now())
+		assert.Zero(t, params.Expires)
+		assert.NotContains(t, req.Header.Get("Signature-Input"), "expires=")
+	})
+}
@@ -20,7 +20,9 @@ package core
 
 import (
 	"context"
+	"crypto"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/sage-x-project/sage/pkg/agent/core/rfc9421"
@@ -136,7 +138,17 @@ func (s *VerificationService) VerifyMessageFromHeaders(
 	return s.VerifyAgentMessage(ctx, message, opts)
 }
 
-// QuickVerify performs a quick signature verification without full metadata checks
+// didMethod returns the DID method of the resolver, for resolvers that
+// report one such as *did.Manager, or did.DefaultMethod.
+func (s *VerificationService) didMethod() string {
+	if m, ok := s.didResolver.(interface{ Method() string }); ok && m.Method() != "" {
+		return m.Method()
+	}
+	return did.DefaultMethod
+}
+
+// QuickVerify performs a quick signature verification without full metadata
+// checks. The DID must use the resolver's method.
 func (s *VerificationService) QuickVerify(
 	ctx context.Context,
 	agentDID string,
@@ -150,7 +162,7 @@ func (s *VerificationService) QuickVerify(
 	}
 
 	// Determine algorithm based on DID chain
-	chain, _, err := did.ParseDID(did.AgentDID(agentDID))
+	chain, _, err := did.ParseDIDWithMethod(did.AgentDID(agentDID), s.didMethod())
 	if err != nil {
 		return fmt.Errorf("failed to parse DID: %w", err)
 	}
@@ -182,6 +194,30 @@ func (s *VerificationService) QuickVerify(
 	return s.verifier.VerifySignature(publicKey, msg, opts)
 }
 
+// VerifyHTTPRequest verifies an RFC 9421 signed HTTP request from agentDID.
+// The key is selected by the signature's keyid among the agent's currently
+// valid keys, so during a key rollover requests signed with either the old
+// or the new key verify until the old key is revoked.
+func (s *VerificationService) VerifyHTTPRequest(
+	ctx context.Context,
+	req *http.Request,
+	agentDID string,
+	opts *rfc9421.HTTPVerificationOptions,
+) error {
+	keys, err := did.ResolveVerificationKeys(ctx, s.didResolver, did.AgentDID(agentDID))
+	if err != nil {
+		return fmt.Errorf("failed to resolve verification keys: %w", err)
+	}
+
+	return rfc9421.NewHTTPVerifier().VerifyRequestWithKeySelector(req, func(keyID string) (crypto.PublicKey, error) {
+		key, err := did.SelectVerificationKey(keys, keyID)
+		if err != nil {
+			return nil, err
+		}
+		return key.PublicKey, nil
+	}, opts)
+}
+
 // VerificationResult contains the result of agent message verification
 type VerificationResult struct {
 	Valid        bool                   `json:"valid"`
@@ -23,6 +23,7 @@ import (
 	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/hex"
+	"net/http"
 	"testing"
 	"time"
 
@@ -32,7 +33,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/sage-x-project/sage/pkg/agent/core/rfc9421"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/formats"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
 	"github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/did/didtest"
 )
 
 // MockDIDManager is a mock implementation of DID Manager
@@ -392,3 +396,113 @@ func TestVerificationService(t *testing.T) {
 		mockDIDManager.AssertExpectations(t)
 	})
 }
+
+func TestVerificationService_QuickVerify_CustomMethod(t *testing.T) {
+	ctx := context.Background()
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	manager := didtest.NewFakeManager()
+	require.NoError(t, manager.SetMethod("sagecorp"))
+	kp, err := keys.NewEd25519KeyPair(privateKey, "")
+	require.NoError(t, err)
+	agentDID := did.AgentDID("did:sagecorp:solana:agent005")
+	_, err = manager.RegisterAgent(ctx, did.ChainSolana, &did.RegistrationRequest{DID: agentDID, Name: "corp", KeyPair: kp})
+	require.NoError(t, err)
+
+	message := []byte("test message")
+	msg := &rfc9421.Message{
+		Body:         message,
+		Algorithm:    string(rfc9421.AlgorithmEdDSA),
+		SignedFields: []string{"body"},
+	}
+	signature := ed25519.Sign(privateKey, []byte(rfc9421.NewVerifier().ConstructSignatureBase(msg)))
+
+	service := NewVerificationService(manager)
+	assert.NoError(t, service.QuickVerify(ctx, string(agentDID), message, signature))
+}
+
+// rolloverResolver serves a mutable key set, mimicking an agent that adds a
+// new key before revoking the old one.
+type rolloverResolver struct {
+	*MockDIDManager
+	keys []did.VerificationKey
+}
+
+func (r *rolloverResolver) ResolvePublicKeys(ctx context.Context, agentDID did.AgentDID) ([]did.VerificationKey, error) {
+	return r.keys, nil
+}
+
+func (r *rolloverResolver) revoke(keyID string) {
+	kept := r.keys[:0]
+	for _, k := range r.keys {
+		if k.ID != keyID {
+			kept = append(kept, k)
+		}
+	}
+	r.keys = kept
+}
+
+func TestVerificationService_KeyRollover(t *testing.T) {
+	ctx := context.Background()
+	agentDID := did.AgentDID("did:sage:ethereum:rollover")
+
+	oldPub, oldPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	newPub, newPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	oldKey, err := did.NewVerificationKey(agentDID, oldPub)
+	require.NoError(t, err)
+	newKey, err := did.NewVerificationKey(agentDID, newPub)
+	require.NoError(t, err)
+
+	resolver := &rolloverResolver{MockDIDManager: new(MockDIDManager), keys: []did.VerificationKey{oldKey, newKey}}
+	service := NewVerificationService(resolver)
+
+	signed := func(t *testing.T, keyID string, priv ed25519.PrivateKey) *http.Request {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, "https://agent.example/messages", nil)
+		require.NoError(t, err)
+		require.NoError(t, rfc9421.NewHTTPVerifier().SignRequest(req, "sig1", &rfc9421.SignatureInputParams{
+			CoveredComponents: []string{`"@method"`, `"@path"`},
+			KeyID:             keyID,
+			Algorithm:         "ed25519",
+			Created:           time.Now().Unix(),
+		}, priv))
+		return req
+	}
+
+	t.Run("both keys verify during overlap", func(t *testing.T) {
+		assert.NoError(t, service.VerifyHTTPRequest(ctx, signed(t, oldKey.ID, oldPriv), string(agentDID), nil))
+		assert.NoError(t, service.VerifyHTTPRequest(ctx, signed(t, newKey.ID, newPriv), string(agentDID), nil))
+	})
+
+	t.Run("keyid must match the signing key", func(t *testing.T) {
+		assert.Error(t, service.VerifyHTTPRequest(ctx, signed(t, newKey.ID, oldPriv), string(agentDID), nil))
+	})
+
+	t.Run("keyid required while several keys are valid", func(t *testing.T) {
+		assert.Error(t, service.VerifyHTTPRequest(ctx, signed(t, "", newPriv), string(agentDID), nil))
+	})
+
+	t.Run("keyid may be a JWK thumbprint", func(t *testing.T) {
+		oldThumbprint, err := formats.JWKThumbprint(oldPub)
+		require.NoError(t, err)
+		newThumbprint, err := formats.JWKThumbprint(newPub)
+		require.NoError(t, err)
+
+		assert.NoError(t, service.VerifyHTTPRequest(ctx, signed(t, oldThumbprint, oldPriv), string(agentDID), nil))
+		assert.NoError(t, service.VerifyHTTPRequest(ctx, signed(t, newThumbprint, newPriv), string(agentDID), nil))
+		assert.Error(t, service.VerifyHTTPRequest(ctx, signed(t, newThumbprint, oldPriv), string(agentDID), nil))
+	})
+
+	t.Run("old key rejected after revocation", func(t *testing.T) {
+		resolver.revoke(oldKey.ID)
+
+		err := service.VerifyHTTPRequest(ctx, signed(t, oldKey.ID, oldPriv), string(agentDID), nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a currently valid key")
+		assert.NoError(t, service.VerifyHTTPRequest(ctx, signed(t, newKey.ID, newPriv), string(agentDID), nil))
+	})
+}
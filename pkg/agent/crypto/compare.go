@@ -0,0 +1,129 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// SecureCompare reports whether a and b are equal in time that depends only
+// on their lengths, not their contents. Use it for every comparison of MACs,
+// tags, digests and other secret-derived values; bytes.Equal returns at the
+// first differing byte and leaks how much of a guess was right.
+func SecureCompare(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// PublicKeysEqual reports whether a and b are the same public key. KeyPairs
+// are compared by their public keys, and secp256k1 keys match across the
+// decred and crypto/ecdsa representations. ECDSA keys are compared by curve
+// domain parameters and point, so keys on equivalent curve instances from
+// different libraries compare equal. Keys of different types, or nil keys,
+// are never equal.
+func PublicKeysEqual(a, b crypto.PublicKey) bool {
+	a, b = normalizePublicKey(a), normalizePublicKey(b)
+	if a == nil || b == nil {
+		return false
+	}
+	switch ka := a.(type) {
+	case ed25519.PublicKey:
+		kb, ok := b.(ed25519.PublicKey)
+		return ok && len(ka) == ed25519.PublicKeySize && bytes.Equal(ka, kb)
+	case *ecdsa.PublicKey:
+		kb, ok := b.(*ecdsa.PublicKey)
+		return ok && sameCurve(ka.Curve, kb.Curve) &&
+			ka.X != nil && ka.Y != nil && kb.X != nil && kb.Y != nil &&
+			ka.X.Cmp(kb.X) == 0 && ka.Y.Cmp(kb.Y) == 0
+	case *ecdh.PublicKey:
+		kb, ok := b.(*ecdh.PublicKey)
+		return ok && ka.Equal(kb)
+	case *rsa.PublicKey:
+		kb, ok := b.(*rsa.PublicKey)
+		return ok && ka.Equal(kb)
+	case []byte:
+		// Raw keys, such as X25519 keys from DID metadata
+		kb, ok := b.([]byte)
+		return ok && len(ka) > 0 && bytes.Equal(ka, kb)
+	default:
+		if eq, ok := a.(interface{ Equal(crypto.PublicKey) bool }); ok {
+			return eq.Equal(b)
+		}
+		return false
+	}
+}
+
+// normalizePublicKey unwraps KeyPairs and maps equivalent representations
+// onto one type; it returns nil for nil keys.
+func normalizePublicKey(k crypto.PublicKey) crypto.PublicKey {
+	if kp, ok := k.(KeyPair); ok {
+		k = kp.PublicKey()
+	}
+	switch v := k.(type) {
+	case nil:
+		return nil
+	case *ed25519.PublicKey:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case ecdsa.PublicKey:
+		return &v
+	case *ecdsa.PublicKey:
+		if v == nil {
+			return nil
+		}
+	case *secp256k1.PublicKey:
+		if v == nil {
+			return nil
+		}
+		return v.ToECDSA()
+	case *ecdh.PublicKey:
+		if v == nil {
+			return nil
+		}
+	case *rsa.PublicKey:
+		if v == nil {
+			return nil
+		}
+	}
+	return k
+}
+
+// sameCurve compares curves by domain parameters rather than identity
+func sameCurve(a, b elliptic.Curve) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if a == b {
+		return true
+	}
+	pa, pb := a.Params(), b.Params()
+	return pa.BitSize == pb.BitSize &&
+		pa.P.Cmp(pb.P) == 0 && pa.N.Cmp(pb.N) == 0 && pa.B.Cmp(pb.B) == 0 &&
+		pa.Gx.Cmp(pb.Gx) == 0 && pa.Gy.Cmp(pb.Gy) == 0
+}
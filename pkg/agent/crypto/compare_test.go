@@ -0,0 +1,159 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package crypto_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+)
+
+func TestSecureCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []byte
+		want bool
+	}{
+		{"equal", []byte("mac-value"), []byte("mac-value"), true},
+		{"both empty", []byte{}, nil, true},
+		{"last byte differs", []byte("mac-value"), []byte("mac-valuf"), false},
+		{"first byte differs", []byte("mac-value"), []byte("nac-value"), false},
+		{"prefix", []byte("mac"), []byte("mac-value"), false},
+		{"one empty", nil, []byte("mac"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sagecrypto.SecureCompare(tt.a, tt.b))
+			assert.Equal(t, tt.want, sagecrypto.SecureCompare(tt.b, tt.a))
+		})
+	}
+}
+
+// TestSecretPaths_UseSecureCompare guards the packages that verify MACs,
+// ack tags and digests against non-constant-time comparisons creeping back in.
+func TestSecretPaths_UseSecureCompare(t *testing.T) {
+	forbidden := map[string]bool{"bytes.Equal": true, "hmac.Equal": true}
+	dirs := []string{"../session", "../hpke", "../core/rfc9421"}
+
+	for _, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		require.NoError(t, err)
+		require.NotEmpty(t, files, dir)
+
+		fset := token.NewFileSet()
+		for _, file := range files {
+			if strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			f, err := parser.ParseFile(fset, file, nil, 0)
+			require.NoError(t, err)
+			ast.Inspect(f, func(n ast.Node) bool {
+				sel, ok := n.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				pkg, ok := sel.X.(*ast.Ident)
+				if ok && forbidden[pkg.Name+"."+sel.Sel.Name] {
+					t.Errorf("%s: use crypto.SecureCompare instead of %s.%s", fset.Position(sel.Pos()), pkg.Name, sel.Sel.Name)
+				}
+				return true
+			})
+		}
+	}
+}
+
+func TestPublicKeysEqual(t *testing.T) {
+	ed1, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	ed2, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	k1, err := keys.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+	k2, err := keys.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+	p1, err := keys.GenerateP256KeyPair()
+	require.NoError(t, err)
+	x1, err := keys.GenerateX25519KeyPair()
+	require.NoError(t, err)
+	x2, err := keys.GenerateX25519KeyPair()
+	require.NoError(t, err)
+
+	k1Pub := k1.PublicKey().(*ecdsa.PublicKey)
+	// Same secp256k1 point via the decred representation and a copied curve
+	decredK1, err := secp256k1.ParsePubKey(append([]byte{0x04}, append(k1Pub.X.FillBytes(make([]byte, 32)), k1Pub.Y.FillBytes(make([]byte, 32))...)...))
+	require.NoError(t, err)
+	// P-256 point with the same coordinates as the secp256k1 key
+	wrongCurve := &ecdsa.PublicKey{Curve: elliptic.P256(), X: k1Pub.X, Y: k1Pub.Y}
+	edPub := ed1.PublicKey().(ed25519.PublicKey)
+
+	tests := []struct {
+		name string
+		a, b crypto.PublicKey
+		want bool
+	}{
+		{"ed25519 same", ed1.PublicKey(), ed1.PublicKey(), true},
+		{"ed25519 copy", ed1.PublicKey(), ed25519.PublicKey(append([]byte(nil), edPub...)), true},
+		{"ed25519 different", ed1.PublicKey(), ed2.PublicKey(), false},
+		{"key pair vs public key", ed1, ed1.PublicKey(), true},
+		{"secp256k1 same", k1.PublicKey(), k1.PublicKey(), true},
+		{"secp256k1 value vs pointer", *k1Pub, k1Pub, true},
+		{"secp256k1 decred vs ecdsa", decredK1, k1Pub, true},
+		{"secp256k1 different", k1.PublicKey(), k2.PublicKey(), false},
+		{"same point on different curve", k1Pub, wrongCurve, false},
+		{"p256 same", p1.PublicKey(), p1.PublicKey(), true},
+		{"x25519 same", x1.PublicKey(), x1.PublicKey(), true},
+		{"x25519 different", x1.PublicKey(), x2.PublicKey(), false},
+		{"ed25519 vs secp256k1", ed1.PublicKey(), k1.PublicKey(), false},
+		{"secp256k1 vs p256", k1.PublicKey(), p1.PublicKey(), false},
+		{"ed25519 vs x25519", ed1.PublicKey(), x1.PublicKey(), false},
+		{"ed25519 vs raw bytes", ed1.PublicKey(), []byte(edPub), false},
+		{"nil", nil, nil, false},
+		{"nil vs key", nil, ed1.PublicKey(), false},
+		{"unsupported type", "key", "key", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sagecrypto.PublicKeysEqual(tt.a, tt.b))
+			assert.Equal(t, tt.want, sagecrypto.PublicKeysEqual(tt.b, tt.a))
+		})
+	}
+
+	t.Run("KeyPair.PublicKeyEqual", func(t *testing.T) {
+		for _, kp := range []sagecrypto.KeyPair{ed1, k1, p1, x1} {
+			assert.True(t, kp.PublicKeyEqual(kp.PublicKey()), kp.Type())
+			assert.True(t, kp.PublicKeyEqual(kp), kp.Type())
+			assert.False(t, kp.PublicKeyEqual(ed2.PublicKey()), kp.Type())
+			assert.False(t, kp.PublicKeyEqual(nil), kp.Type())
+		}
+	})
+}
@@ -482,6 +482,12 @@ func (jwk JWK) ComputeKeyIDRFC9421() (string, error) {
 	if jwk.Y != "" {
 		m["y"] = jwk.Y
 	}
+	if jwk.N != "" {
+		m["n"] = jwk.N
+	}
+	if jwk.E != "" {
+		m["e"] = jwk.E
+	}
 
 	keys := make([]string, 0, len(m))
 	for k := range m {
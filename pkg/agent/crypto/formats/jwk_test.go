@@ -19,6 +19,8 @@
 package formats
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"encoding/json"
 	"testing"
 
@@ -444,3 +446,40 @@ func TestJWKImporter(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestJWKThumbprint(t *testing.T) {
+	t.Run("RFC 8037 Ed25519 vector", func(t *testing.T) {
+		x, err := base64.RawURLEncoding.DecodeString("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")
+		require.NoError(t, err)
+		thumbprint, err := JWKThumbprint(ed25519.PublicKey(x))
+		require.NoError(t, err)
+		assert.Equal(t, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", thumbprint)
+	})
+
+	t.Run("Matches exported key IDs", func(t *testing.T) {
+		for _, gen := range []func() (crypto.KeyPair, error){
+			keys.GenerateEd25519KeyPair, keys.GenerateSecp256k1KeyPair, keys.GenerateP256KeyPair,
+		} {
+			keyPair, err := gen()
+			require.NoError(t, err)
+			exported, err := NewJWKExporter().ExportPublic(keyPair, crypto.KeyFormatJWK)
+			require.NoError(t, err)
+			var jwk JWK
+			require.NoError(t, json.Unmarshal(exported, &jwk))
+			want, err := jwk.ComputeKeyIDRFC9421()
+			require.NoError(t, err)
+
+			got, err := JWKThumbprint(keyPair.PublicKey())
+			require.NoError(t, err)
+			// The exporter does not pad coordinates with leading zero bytes
+			if len(jwk.X) == 43 && (jwk.Y == "" || len(jwk.Y) == 43) {
+				assert.Equal(t, want, got, keyPair.Type())
+			}
+		}
+	})
+
+	t.Run("Unsupported key", func(t *testing.T) {
+		_, err := JWKThumbprint("not a key")
+		assert.Error(t, err)
+	})
+}
@@ -20,6 +20,7 @@ package formats
 
 import (
 	"crypto"
+	"crypto/ecdh"
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/rsa"
@@ -119,6 +120,25 @@ func (e *pemExporter) Export(keyPair sagecrypto.KeyPair, format sagecrypto.KeyFo
 
 		return pem.EncodeToMemory(block), nil
 
+	case sagecrypto.KeyTypeX25519:
+		privateKey, ok := keyPair.PrivateKey().(*ecdh.PrivateKey)
+		if !ok {
+			return nil, errors.New("invalid X25519 private key type")
+		}
+
+		// X25519 has a standard PKCS8 encoding (RFC 8410)
+		derBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal X25519 private key: %w", err)
+		}
+
+		block := &pem.Block{
+			Type:  "PRIVATE KEY",
+			Bytes: derBytes,
+		}
+
+		return pem.EncodeToMemory(block), nil
+
 	case sagecrypto.KeyTypeRSA:
 		privateKey, ok := keyPair.PrivateKey().(*rsa.PrivateKey)
 		if !ok {
@@ -268,6 +288,11 @@ func (i *pemImporter) Import(data []byte, format sagecrypto.KeyFormat) (sagecryp
 				return keys.NewP256KeyPair(privateKey, "")
 			}
 			return nil, fmt.Errorf("unsupported ECDSA curve: %s", privateKey.Curve.Params().Name)
+		case *ecdh.PrivateKey:
+			if privateKey.Curve() != ecdh.X25519() {
+				return nil, errors.New("unsupported ECDH curve")
+			}
+			return keys.NewX25519KeyPair(privateKey, "")
 		default:
 			return nil, fmt.Errorf("unsupported private key type: %T", privateKey)
 		}
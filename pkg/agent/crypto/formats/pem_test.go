@@ -224,6 +224,21 @@ func TestPEMImporter(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("ImportX25519KeyPair", func(t *testing.T) {
+		// Generate and export a key pair
+		originalKeyPair, err := keys.GenerateX25519KeyPair()
+		require.NoError(t, err)
+
+		exported, err := exporter.Export(originalKeyPair, crypto.KeyFormatPEM)
+		require.NoError(t, err)
+
+		// Import the key pair
+		importedKeyPair, err := importer.Import(exported, crypto.KeyFormatPEM)
+		require.NoError(t, err)
+		assert.Equal(t, crypto.KeyTypeX25519, importedKeyPair.Type())
+		assert.Equal(t, originalKeyPair.ID(), importedKeyPair.ID())
+	})
+
 	t.Run("ImportEd25519PublicKey", func(t *testing.T) {
 		// Generate and export a public key
 		originalKeyPair, err := keys.GenerateEd25519KeyPair()
@@ -0,0 +1,87 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package formats
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+)
+
+// PublicKeyJWK returns the public JWK of a raw public key (or a KeyPair's
+// public key). EC coordinates are padded to the curve size as RFC 7518
+// requires, so the result is suitable for thumbprints.
+func PublicKeyJWK(pub crypto.PublicKey) (*JWK, error) {
+	if kp, ok := pub.(sagecrypto.KeyPair); ok {
+		pub = kp.PublicKey()
+	}
+
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return &JWK{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(k)}, nil
+	case *ecdh.PublicKey:
+		if k.Curve() != ecdh.X25519() {
+			return nil, fmt.Errorf("unsupported ECDH curve for JWK")
+		}
+		return &JWK{Kty: "OKP", Crv: "X25519", X: base64.RawURLEncoding.EncodeToString(k.Bytes())}, nil
+	case *ecdsa.PublicKey:
+		var crv string
+		switch {
+		case k.Curve == elliptic.P256():
+			crv = "P-256"
+		case k.Curve.Params().P.Cmp(secp256k1.S256().Params().P) == 0:
+			crv = "secp256k1"
+		default:
+			return nil, fmt.Errorf("unsupported ECDSA curve %s for JWK", k.Curve.Params().Name)
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return &JWK{
+			Kty: "EC",
+			Crv: crv,
+			X:   base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case *rsa.PublicKey:
+		return &JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T for JWK", pub)
+}
+
+// JWKThumbprint returns the RFC 7638 SHA-256 thumbprint of pub, base64url
+// encoded without padding, as used for RFC 9421 keyid values.
+func JWKThumbprint(pub crypto.PublicKey) (string, error) {
+	jwk, err := PublicKeyJWK(pub)
+	if err != nil {
+		return "", err
+	}
+	return jwk.ComputeKeyIDRFC9421()
+}
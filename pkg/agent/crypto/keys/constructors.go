@@ -140,6 +140,11 @@ func (pk *publicKeyOnlyEd25519) ID() string {
 	return pk.id
 }
 
+// PublicKeyEqual reports whether other is this key pair's public key
+func (pk *publicKeyOnlyEd25519) PublicKeyEqual(other crypto.PublicKey) bool {
+	return sagecrypto.PublicKeysEqual(pk.PublicKey(), other)
+}
+
 // PublicKeyOnlyRSA wraps an RSA public key for verification only
 //
 //nolint:unused // Reserved for future verification-only scenarios
@@ -181,3 +186,8 @@ func (pk *publicKeyOnlyRSA) Verify(message, signature []byte) error {
 func (pk *publicKeyOnlyRSA) ID() string {
 	return pk.id
 }
+
+// PublicKeyEqual reports whether other is this key pair's public key
+func (pk *publicKeyOnlyRSA) PublicKeyEqual(other crypto.PublicKey) bool {
+	return sagecrypto.PublicKeysEqual(pk.PublicKey(), other)
+}
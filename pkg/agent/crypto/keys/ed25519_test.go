@@ -1303,3 +1303,53 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+func TestEd25519SignWithContext(t *testing.T) {
+	keyPair, err := GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	pub := keyPair.PublicKey().(ed25519.PublicKey)
+
+	msg := []byte(`{"initDid":"did:sage:ethereum:alice","nonce":"n-1"}`)
+	handshake := []byte(SigningContextHandshake)
+	registration := []byte(SigningContextRegistration)
+
+	sig, err := SignWithContext(keyPair, msg, handshake)
+	require.NoError(t, err)
+	require.Len(t, sig, ed25519.SignatureSize)
+
+	t.Run("verifies under the same context", func(t *testing.T) {
+		assert.NoError(t, VerifyWithContext(pub, msg, sig, handshake))
+	})
+
+	t.Run("rejected under another context", func(t *testing.T) {
+		assert.ErrorIs(t, VerifyWithContext(pub, msg, sig, registration), crypto.ErrInvalidSignature)
+
+		regSig, err := SignWithContext(keyPair, msg, registration)
+		require.NoError(t, err)
+		assert.NoError(t, VerifyWithContext(pub, msg, regSig, registration))
+		assert.ErrorIs(t, VerifyWithContext(pub, msg, regSig, handshake), crypto.ErrInvalidSignature)
+	})
+
+	t.Run("not interchangeable with plain Ed25519", func(t *testing.T) {
+		assert.False(t, ed25519.Verify(pub, msg, sig))
+		assert.Error(t, keyPair.Verify(msg, sig))
+
+		plain, err := keyPair.Sign(msg)
+		require.NoError(t, err)
+		assert.Error(t, VerifyWithContext(pub, msg, plain, handshake))
+	})
+
+	t.Run("invalid contexts and keys", func(t *testing.T) {
+		_, err := SignWithContext(keyPair, msg, nil)
+		assert.Error(t, err)
+		_, err = SignWithContext(keyPair, msg, make([]byte, 256))
+		assert.Error(t, err)
+
+		secpKey, err := GenerateSecp256k1KeyPair()
+		require.NoError(t, err)
+		_, err = SignWithContext(secpKey, msg, handshake)
+		assert.ErrorIs(t, err, crypto.ErrSignNotSupported)
+
+		assert.Error(t, VerifyWithContext(pub[:16], msg, sig, handshake))
+	})
+}
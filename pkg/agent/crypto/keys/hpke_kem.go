@@ -0,0 +1,201 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/cloudflare/circl/hpke"
+)
+
+// HPKE suites by KEM curve. X25519 keeps the original SAGE suite; P-256 uses
+// the FIPS-approved pairing of DHKEM(P-256) with AES-128-GCM for contexts
+// (HSMs, FIPS 140 modules) that cannot use X25519.
+var hpkeSuites = map[ecdh.Curve]struct {
+	kem    hpke.KEM
+	aead   hpke.AEAD
+	encLen int
+}{
+	ecdh.X25519(): {hpke.KEM_X25519_HKDF_SHA256, hpke.AEAD_ChaCha20Poly1305, 32},
+	ecdh.P256():   {hpke.KEM_P256_HKDF_SHA256, hpke.AEAD_AES128GCM, 65},
+}
+
+// HPKEEncLen returns the length of the HPKE encapsulated key for a KEM curve.
+func HPKEEncLen(curve ecdh.Curve) (int, error) {
+	s, ok := hpkeSuites[curve]
+	if !ok {
+		return 0, fmt.Errorf("unsupported KEM curve: %v", curve)
+	}
+	return s.encLen, nil
+}
+
+// ECDHPublicKey normalizes a KEM public key to *ecdh.PublicKey. X25519 and
+// P-256 keys are accepted; P-256 keys may also be given as *ecdsa.PublicKey.
+func ECDHPublicKey(pub crypto.PublicKey) (*ecdh.PublicKey, error) {
+	switch k := pub.(type) {
+	case *ecdh.PublicKey:
+		if _, ok := hpkeSuites[k.Curve()]; !ok {
+			return nil, fmt.Errorf("unsupported KEM curve: %v", k.Curve())
+		}
+		return k, nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported KEM curve: %s", k.Curve.Params().Name)
+		}
+		return k.ECDH()
+	default:
+		return nil, fmt.Errorf("expected *ecdh.PublicKey, got %T", pub)
+	}
+}
+
+// ECDHPrivateKey normalizes a KEM private key to *ecdh.PrivateKey. X25519 and
+// P-256 keys are accepted; P-256 keys may also be given as *ecdsa.PrivateKey.
+func ECDHPrivateKey(priv crypto.PrivateKey) (*ecdh.PrivateKey, error) {
+	switch k := priv.(type) {
+	case *ecdh.PrivateKey:
+		if _, ok := hpkeSuites[k.Curve()]; !ok {
+			return nil, fmt.Errorf("unsupported KEM curve: %v", k.Curve())
+		}
+		return k, nil
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported KEM curve: %s", k.Curve.Params().Name)
+		}
+		return k.ECDH()
+	default:
+		return nil, fmt.Errorf("expected *ecdh.PrivateKey, got %T", priv)
+	}
+}
+
+func hpkeSuiteFor(curve ecdh.Curve) (hpke.KEM, hpke.Suite, error) {
+	s, ok := hpkeSuites[curve]
+	if !ok {
+		return 0, hpke.Suite{}, fmt.Errorf("unsupported KEM curve: %v", curve)
+	}
+	return s.kem, hpke.NewSuite(s.kem, hpke.KDF_HKDF_SHA256, s.aead), nil
+}
+
+func hpkeSender(peer *ecdh.PublicKey, info []byte) ([]byte, hpke.Sealer, error) {
+	kemID, suite, err := hpkeSuiteFor(peer.Curve())
+	if err != nil {
+		return nil, nil, err
+	}
+	rp, err := kemID.Scheme().UnmarshalBinaryPublicKey(peer.Bytes())
+	if err != nil {
+		return nil, nil, fmt.Errorf("hpke unmarshal pub: %w", err)
+	}
+	sender, err := suite.NewSender(rp, info)
+	if err != nil {
+		return nil, nil, fmt.Errorf("hpke new sender: %w", err)
+	}
+	enc, sealer, err := sender.Setup(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("hpke setup: %w", err)
+	}
+	return enc, sealer, nil
+}
+
+func hpkeReceiver(priv *ecdh.PrivateKey, enc, info []byte) (hpke.Opener, error) {
+	kemID, suite, err := hpkeSuiteFor(priv.Curve())
+	if err != nil {
+		return nil, err
+	}
+	skR, err := kemID.Scheme().UnmarshalBinaryPrivateKey(priv.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("hpke unmarshal priv: %w", err)
+	}
+	receiver, err := suite.NewReceiver(skR, info)
+	if err != nil {
+		return nil, fmt.Errorf("hpke new receiver: %w", err)
+	}
+	opener, err := receiver.Setup(enc)
+	if err != nil {
+		return nil, fmt.Errorf("hpke receiver setup: %w", err)
+	}
+	return opener, nil
+}
+
+func hpkeDeriveToPeer(peer *ecdh.PublicKey, info, exportCtx []byte, exportLen int) ([]byte, []byte, error) {
+	// Export a shared secret without necessarily encrypting application data.
+	if exportLen < 0 {
+		return nil, nil, fmt.Errorf("exportLen must be non-negative: %d", exportLen)
+	}
+	enc, sealer, err := hpkeSender(peer, info)
+	if err != nil {
+		return nil, nil, err
+	}
+	secret := sealer.Export(exportCtx, uint(exportLen)) // #nosec G115 - negative check above
+	return enc, secret, nil
+}
+
+func hpkeOpenWithPriv(priv *ecdh.PrivateKey, enc, info, exportCtx []byte, exportLen int) ([]byte, error) {
+	if exportLen < 0 {
+		return nil, fmt.Errorf("exportLen must be non-negative: %d", exportLen)
+	}
+	opener, err := hpkeReceiver(priv, enc, info)
+	if err != nil {
+		return nil, err
+	}
+	secret := opener.Export(exportCtx, uint(exportLen)) // #nosec G115 - negative check above
+	return secret, nil
+}
+
+func hpkeSealAndExport(peer *ecdh.PublicKey, plaintext, info, exportCtx []byte, exportLen int) ([]byte, []byte, error) {
+	if exportLen < 0 {
+		return nil, nil, fmt.Errorf("exportLen must be non-negative: %d", exportLen)
+	}
+	enc, sealer, err := hpkeSender(peer, info)
+	if err != nil {
+		return nil, nil, err
+	}
+	ct, err := sealer.Seal(plaintext, info) // aad = info
+	if err != nil {
+		return nil, nil, fmt.Errorf("hpke seal: %w", err)
+	}
+	secret := sealer.Export(exportCtx, uint(exportLen)) // #nosec G115 - negative check above
+	return append(append([]byte{}, enc...), ct...), secret, nil
+}
+
+func hpkeOpenAndExport(priv *ecdh.PrivateKey, packet, info, exportCtx []byte, exportLen int) ([]byte, []byte, error) {
+	if exportLen < 0 {
+		return nil, nil, fmt.Errorf("exportLen must be non-negative: %d", exportLen)
+	}
+	encLen, err := HPKEEncLen(priv.Curve())
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(packet) < encLen {
+		return nil, nil, fmt.Errorf("packet too short: %d", len(packet))
+	}
+	opener, err := hpkeReceiver(priv, packet[:encLen], info)
+	if err != nil {
+		return nil, nil, err
+	}
+	pt, err := opener.Open(packet[encLen:], info) // aad = info
+	if err != nil {
+		return nil, nil, fmt.Errorf("hpke open: %w", err)
+	}
+	secret := opener.Export(exportCtx, uint(exportLen)) // #nosec G115 - negative check above
+	return pt, secret, nil
+}
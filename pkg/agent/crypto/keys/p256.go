@@ -159,6 +159,11 @@ func (kp *p256KeyPair) ID() string {
 	return kp.id
 }
 
+// PublicKeyEqual reports whether other is this key pair's public key
+func (kp *p256KeyPair) PublicKeyEqual(other crypto.PublicKey) bool {
+	return sagecrypto.PublicKeysEqual(kp.PublicKey(), other)
+}
+
 // deserializeP256Signature deserializes a P-256 ECDSA signature
 //
 // Supports both 64-byte raw format (32 bytes R + 32 bytes S) and
@@ -99,3 +99,8 @@ func (kp *rsaKeyPair) Verify(message, signature []byte) error {
 func (kp *rsaKeyPair) ID() string {
 	return kp.id
 }
+
+// PublicKeyEqual reports whether other is this key pair's public key
+func (kp *rsaKeyPair) PublicKeyEqual(other crypto.PublicKey) bool {
+	return sagecrypto.PublicKeysEqual(kp.PublicKey(), other)
+}
@@ -23,9 +23,11 @@ import (
 	"crypto/ecdsa"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"math/big"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	dcrecdsa "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
 )
@@ -120,6 +122,11 @@ func (kp *secp256k1KeyPair) ID() string {
 	return kp.id
 }
 
+// PublicKeyEqual reports whether other is this key pair's public key
+func (kp *secp256k1KeyPair) PublicKeyEqual(other crypto.PublicKey) bool {
+	return sagecrypto.PublicKeysEqual(kp.PublicKey(), other)
+}
+
 // deserializeSignature deserializes an ECDSA signature
 func deserializeSignature(data []byte) (*big.Int, *big.Int, error) {
 	if len(data) != 64 {
@@ -131,3 +138,59 @@ func deserializeSignature(data []byte) (*big.Int, *big.Int, error) {
 
 	return r, s, nil
 }
+
+// Secp256k1RecoverableSignatureSize is the length of an [R || S || V] signature
+const Secp256k1RecoverableSignatureSize = 65
+
+// SignSecp256k1Recoverable signs a 32-byte digest and returns a 65-byte
+// recoverable signature in Ethereum's [R || S || V] layout, with V in {0, 1}.
+// S is always in the lower half of the curve order (EIP-2).
+//
+// Unlike KeyPair.Sign, the digest is signed as-is, so callers can supply
+// EIP-191 or EIP-712 hashes computed elsewhere.
+func SignSecp256k1Recoverable(kp sagecrypto.KeyPair, hash []byte) ([]byte, error) {
+	k, ok := kp.(*secp256k1KeyPair)
+	if !ok {
+		return nil, fmt.Errorf("%w: recoverable signatures require a secp256k1 key", sagecrypto.ErrInvalidKeyType)
+	}
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("hash must be 32 bytes, got %d", len(hash))
+	}
+
+	// SignCompact returns [V || R || S] with V = 27 + recid (+4 when compressed)
+	compact := dcrecdsa.SignCompact(k.privateKey, hash, false)
+
+	sig := make([]byte, Secp256k1RecoverableSignatureSize)
+	copy(sig, compact[1:])
+	sig[64] = compact[0] - 27
+	return sig, nil
+}
+
+// RecoverSecp256k1 recovers the public key that produced a recoverable
+// signature over hash. V may be encoded as 0/1 or 27/28.
+func RecoverSecp256k1(hash, sig []byte) (*ecdsa.PublicKey, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("hash must be 32 bytes, got %d", len(hash))
+	}
+	if len(sig) != Secp256k1RecoverableSignatureSize {
+		return nil, sagecrypto.ErrInvalidSignature
+	}
+
+	v := sig[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return nil, sagecrypto.ErrInvalidSignature
+	}
+
+	compact := make([]byte, Secp256k1RecoverableSignatureSize)
+	compact[0] = 27 + v
+	copy(compact[1:], sig[:64])
+
+	pub, _, err := dcrecdsa.RecoverCompact(compact, hash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", sagecrypto.ErrInvalidSignature, err)
+	}
+	return pub.ToECDSA(), nil
+}
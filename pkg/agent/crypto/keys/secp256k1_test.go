@@ -25,6 +25,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/sage-x-project/sage/pkg/agent/crypto"
 	"github.com/sage-x-project/sage/pkg/agent/crypto/vault"
@@ -723,3 +724,69 @@ func TestSecp256k1KeyPairEncrypted(t *testing.T) {
 	}
 	helpers.SaveTestData(t, "keys/secp256k1_encrypted_storage.json", testData)
 }
+
+func TestSecp256k1RecoverableSignature(t *testing.T) {
+	keyPair, err := GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+	pubKey := keyPair.PublicKey().(*ecdsa.PublicKey)
+	address := ethcrypto.PubkeyToAddress(*pubKey)
+
+	// EIP-191 personal_sign digest
+	msg := []byte("register agent did:sage:ethereum:test")
+	hash := accounts.TextHash(msg)
+
+	sig, err := SignSecp256k1Recoverable(keyPair, hash)
+	require.NoError(t, err)
+	require.Len(t, sig, Secp256k1RecoverableSignatureSize)
+	assert.LessOrEqual(t, sig[64], byte(1), "V must be 0 or 1")
+
+	t.Run("matches go-ethereum", func(t *testing.T) {
+		ethPub, err := ethcrypto.SigToPub(hash, sig)
+		require.NoError(t, err)
+		assert.Equal(t, address, ethcrypto.PubkeyToAddress(*ethPub))
+		assert.True(t, ethcrypto.VerifySignature(ethcrypto.FromECDSAPub(pubKey), hash, sig[:64]))
+
+		ethSig, err := ethcrypto.Sign(hash, keyPair.PrivateKey().(*ecdsa.PrivateKey))
+		require.NoError(t, err)
+		assert.Equal(t, ethSig, sig, "deterministic signatures must be byte-identical")
+	})
+
+	t.Run("recover", func(t *testing.T) {
+		recovered, err := RecoverSecp256k1(hash, sig)
+		require.NoError(t, err)
+		assert.Equal(t, address, ethcrypto.PubkeyToAddress(*recovered))
+
+		// Legacy 27/28 encoding is accepted too
+		legacy := append([]byte(nil), sig...)
+		legacy[64] += 27
+		recovered, err = RecoverSecp256k1(hash, legacy)
+		require.NoError(t, err)
+		assert.Equal(t, address, ethcrypto.PubkeyToAddress(*recovered))
+	})
+
+	t.Run("wrong hash recovers a different signer", func(t *testing.T) {
+		other := ethcrypto.Keccak256([]byte("other"))
+		recovered, err := RecoverSecp256k1(other, sig)
+		if err == nil {
+			assert.NotEqual(t, address, ethcrypto.PubkeyToAddress(*recovered))
+		}
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := SignSecp256k1Recoverable(keyPair, []byte("short"))
+		assert.Error(t, err)
+
+		edKey, err := GenerateEd25519KeyPair()
+		require.NoError(t, err)
+		_, err = SignSecp256k1Recoverable(edKey, hash)
+		assert.ErrorIs(t, err, crypto.ErrInvalidKeyType)
+
+		_, err = RecoverSecp256k1(hash, sig[:64])
+		assert.ErrorIs(t, err, crypto.ErrInvalidSignature)
+
+		badV := append([]byte(nil), sig...)
+		badV[64] = 5
+		_, err = RecoverSecp256k1(hash, badV)
+		assert.ErrorIs(t, err, crypto.ErrInvalidSignature)
+	})
+}
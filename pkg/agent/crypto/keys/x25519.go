@@ -36,8 +36,6 @@ import (
 	"filippo.io/edwards25519"
 	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
 	"golang.org/x/crypto/hkdf"
-
-	"github.com/cloudflare/circl/hpke"
 )
 
 // X25519KeyPair holds an X25519 private key and its corresponding public key bytes.
@@ -68,6 +66,39 @@ func GenerateX25519KeyPair() (sagecrypto.KeyPair, error) {
 	}, nil
 }
 
+// X25519KeyPairFromEd25519 derives the X25519 key pair birationally
+// equivalent to an Ed25519 key pair (RFC 7748 §4.1). Its public key equals
+// Ed25519PublicKeyToX25519 of the Ed25519 public key, so a peer holding only
+// the signing key can still encrypt to it. Prefer a dedicated KEM key; reusing
+// one key for signing and key agreement weakens their separation.
+func X25519KeyPairFromEd25519(kp sagecrypto.KeyPair) (sagecrypto.KeyPair, error) {
+	if kp == nil || kp.Type() != sagecrypto.KeyTypeEd25519 {
+		return nil, fmt.Errorf("expected Ed25519 key pair")
+	}
+	scalar, err := convertEd25519PrivToX25519(kp.PrivateKey())
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := ecdh.X25519().NewPrivateKey(scalar)
+	if err != nil {
+		return nil, fmt.Errorf("x25519 from ed25519: %w", err)
+	}
+	publicKey := privateKey.PublicKey()
+	hash := sha256.Sum256(publicKey.Bytes())
+
+	return &X25519KeyPair{
+		privateKey: privateKey,
+		publicKey:  publicKey,
+		id:         hex.EncodeToString(hash[:8]),
+	}, nil
+}
+
+// Ed25519PublicKeyToX25519 maps an Ed25519 public key to its raw 32-byte
+// X25519 counterpart.
+func Ed25519PublicKeyToX25519(pub crypto.PublicKey) ([]byte, error) {
+	return convertEd25519PubToX25519(pub)
+}
+
 // PublicKey returns the public key
 func (kp *X25519KeyPair) PublicKey() crypto.PublicKey {
 	return kp.publicKey
@@ -93,6 +124,11 @@ func (kp *X25519KeyPair) ID() string {
 	return kp.id
 }
 
+// PublicKeyEqual reports whether other is this key pair's public key
+func (kp *X25519KeyPair) PublicKeyEqual(other crypto.PublicKey) bool {
+	return sagecrypto.PublicKeysEqual(kp.PublicKey(), other)
+}
+
 // Sign returns an error as X25519 is a key agreement algorithm and does not support signing operations.
 // X25519 keys are designed exclusively for Elliptic Curve Diffie-Hellman (ECDH) key exchange.
 // For digital signatures, use Ed25519 keys instead.
@@ -358,34 +394,10 @@ func HPKEDeriveSharedSecretToX25519Peer(
 	exportCtx []byte,
 	exportLen int,
 ) (enc []byte, exporterSecret []byte, err error) {
-	suite := hpke.NewSuite(
-		hpke.KEM_X25519_HKDF_SHA256,
-		hpke.KDF_HKDF_SHA256,
-		hpke.AEAD_ChaCha20Poly1305,
-	)
-
-	kem := hpke.KEM_X25519_HKDF_SHA256.Scheme()
-	rp, err := kem.UnmarshalBinaryPublicKey(peer.Bytes())
-	if err != nil {
-		return nil, nil, fmt.Errorf("hpke unmarshal pub: %w", err)
-	}
-
-	sender, err := suite.NewSender(rp, info)
-	if err != nil {
-		return nil, nil, fmt.Errorf("hpke new sender: %w", err)
-	}
-
-	enc, sealer, err := sender.Setup(rand.Reader)
-	if err != nil {
-		return nil, nil, fmt.Errorf("hpke setup: %w", err)
-	}
-
-	// Export a shared secret without necessarily encrypting application data.
-	if exportLen < 0 {
-		return nil, nil, fmt.Errorf("exportLen must be non-negative: %d", exportLen)
+	if peer.Curve() != ecdh.X25519() {
+		return nil, nil, fmt.Errorf("unsupported KEM curve: want X25519")
 	}
-	secret := sealer.Export(exportCtx, uint(exportLen)) // #nosec G115 - negative check above
-	return enc, secret, nil
+	return hpkeDeriveToPeer(peer, info, exportCtx, exportLen)
 }
 
 // HPKEOpenSharedSecretWithX25519Priv takes the recipient's X25519 private key and the 'enc'
@@ -398,37 +410,15 @@ func HPKEOpenSharedSecretWithX25519Priv(
 	exportCtx []byte,
 	exportLen int,
 ) (exporterSecret []byte, err error) {
-	suite := hpke.NewSuite(
-		hpke.KEM_X25519_HKDF_SHA256,
-		hpke.KDF_HKDF_SHA256,
-		hpke.AEAD_ChaCha20Poly1305,
-	)
-
-	kem := hpke.KEM_X25519_HKDF_SHA256.Scheme()
-	skR, err := kem.UnmarshalBinaryPrivateKey(priv.Bytes())
-	if err != nil {
-		return nil, fmt.Errorf("hpke unmarshal priv: %w", err)
-	}
-
-	receiver, err := suite.NewReceiver(skR, info)
-	if err != nil {
-		return nil, fmt.Errorf("hpke new receiver: %w", err)
-	}
-
-	opener, err := receiver.Setup(enc)
-	if err != nil {
-		return nil, fmt.Errorf("hpke receiver setup: %w", err)
-	}
-
-	if exportLen < 0 {
-		return nil, fmt.Errorf("exportLen must be non-negative: %d", exportLen)
+	if priv.Curve() != ecdh.X25519() {
+		return nil, fmt.Errorf("unsupported KEM curve: want X25519")
 	}
-	secret := opener.Export(exportCtx, uint(exportLen)) // #nosec G115 - negative check above
-	return secret, nil
+	return hpkeOpenWithPriv(priv, enc, info, exportCtx, exportLen)
 }
 
-// Convenience wrappers that accept crypto.PublicKey / crypto.PrivateKey
-// and do type assertions to *ecdh.PublicKey / *ecdh.PrivateKey.
+// Convenience wrappers that accept crypto.PublicKey / crypto.PrivateKey.
+// Both X25519 and P-256 KEM keys are accepted (see HPKEKEMCurve); P-256
+// keys may also be given as *ecdsa keys.
 
 func HPKEDeriveSharedSecretToPeer(
 	pub crypto.PublicKey,
@@ -436,15 +426,11 @@ func HPKEDeriveSharedSecretToPeer(
 	exportCtx []byte,
 	exportLen int,
 ) (enc []byte, exporterSecret []byte, err error) {
-	p, ok := pub.(*ecdh.PublicKey)
-	if !ok {
-		return nil, nil, fmt.Errorf("expected *ecdh.PublicKey, got %T", pub)
-	}
-
-	if p.Curve() != ecdh.X25519() {
-		return nil, nil, fmt.Errorf("unsupported KEM curve: want X25519")
+	p, err := ECDHPublicKey(pub)
+	if err != nil {
+		return nil, nil, err
 	}
-	return HPKEDeriveSharedSecretToX25519Peer(p, info, exportCtx, exportLen)
+	return hpkeDeriveToPeer(p, info, exportCtx, exportLen)
 }
 
 func HPKEOpenSharedSecretWithPriv(
@@ -454,15 +440,11 @@ func HPKEOpenSharedSecretWithPriv(
 	exportCtx []byte,
 	exportLen int,
 ) (exporterSecret []byte, err error) {
-	p, ok := priv.(*ecdh.PrivateKey)
-	if !ok {
-		return nil, fmt.Errorf("expected *ecdh.PrivateKey, got %T", priv)
-	}
-
-	if p.Curve() != ecdh.X25519() {
-		return nil, fmt.Errorf("unsupported KEM curve: want X25519")
+	p, err := ECDHPrivateKey(priv)
+	if err != nil {
+		return nil, err
 	}
-	return HPKEOpenSharedSecretWithX25519Priv(p, enc, info, exportCtx, exportLen)
+	return hpkeOpenWithPriv(p, enc, info, exportCtx, exportLen)
 }
 
 // OPTIONAL: If you still want to encrypt a handshake payload while also deriving the shared secret,
@@ -475,43 +457,14 @@ func HPKESealAndExportToX25519Peer(
 	exportCtx []byte,
 	exportLen int,
 ) (packet []byte, exporterSecret []byte, err error) {
-
 	pubKey, ok := peer.(*ecdh.PublicKey)
 	if !ok {
 		return nil, nil, fmt.Errorf("hpke: invalid key type, expected ECDH but got %T", peer)
 	}
-	suite := hpke.NewSuite(
-		hpke.KEM_X25519_HKDF_SHA256,
-		hpke.KDF_HKDF_SHA256,
-		hpke.AEAD_ChaCha20Poly1305,
-	)
-
-	kem := hpke.KEM_X25519_HKDF_SHA256.Scheme()
-	rp, err := kem.UnmarshalBinaryPublicKey(pubKey.Bytes())
-	if err != nil {
-		return nil, nil, fmt.Errorf("hpke unmarshal pub: %w", err)
-	}
-
-	sender, err := suite.NewSender(rp, info)
-	if err != nil {
-		return nil, nil, fmt.Errorf("hpke new sender: %w", err)
-	}
-
-	enc, sealer, err := sender.Setup(rand.Reader)
-	if err != nil {
-		return nil, nil, fmt.Errorf("hpke setup: %w", err)
-	}
-
-	ct, err := sealer.Seal(plaintext, info) // aad = info
-	if err != nil {
-		return nil, nil, fmt.Errorf("hpke seal: %w", err)
-	}
-
-	if exportLen < 0 {
-		return nil, nil, fmt.Errorf("exportLen must be non-negative: %d", exportLen)
+	if pubKey.Curve() != ecdh.X25519() {
+		return nil, nil, fmt.Errorf("unsupported KEM curve: want X25519")
 	}
-	secret := sealer.Export(exportCtx, uint(exportLen)) // #nosec G115 - negative check above
-	return append(append([]byte{}, enc...), ct...), secret, nil
+	return hpkeSealAndExport(pubKey, plaintext, info, exportCtx, exportLen)
 }
 
 func HPKEOpenAndExportWithX25519Priv(
@@ -525,44 +478,40 @@ func HPKEOpenAndExportWithX25519Priv(
 	if !ok {
 		return nil, nil, fmt.Errorf("hpke: invalid key type, expected ECDH but got %T", priv)
 	}
-
-	const encLen = 32 // X25519 KEM enc length
-	if len(packet) < encLen {
-		return nil, nil, fmt.Errorf("packet too short: %d", len(packet))
-	}
-	enc := packet[:encLen]
-	ct := packet[encLen:]
-
-	suite := hpke.NewSuite(
-		hpke.KEM_X25519_HKDF_SHA256,
-		hpke.KDF_HKDF_SHA256,
-		hpke.AEAD_ChaCha20Poly1305,
-	)
-
-	kem := hpke.KEM_X25519_HKDF_SHA256.Scheme()
-	skR, err := kem.UnmarshalBinaryPrivateKey(privKey.Bytes())
-	if err != nil {
-		return nil, nil, fmt.Errorf("hpke unmarshal priv: %w", err)
-	}
-
-	receiver, err := suite.NewReceiver(skR, info)
-	if err != nil {
-		return nil, nil, fmt.Errorf("hpke new receiver: %w", err)
+	if privKey.Curve() != ecdh.X25519() {
+		return nil, nil, fmt.Errorf("unsupported KEM curve: want X25519")
 	}
+	return hpkeOpenAndExport(privKey, packet, info, exportCtx, exportLen)
+}
 
-	opener, err := receiver.Setup(enc)
+// HPKESealAndExportToPeer is HPKESealAndExportToX25519Peer for any supported
+// KEM curve (X25519 or P-256).
+func HPKESealAndExportToPeer(
+	peer crypto.PublicKey,
+	plaintext []byte,
+	info []byte,
+	exportCtx []byte,
+	exportLen int,
+) (packet []byte, exporterSecret []byte, err error) {
+	pubKey, err := ECDHPublicKey(peer)
 	if err != nil {
-		return nil, nil, fmt.Errorf("hpke receiver setup: %w", err)
+		return nil, nil, err
 	}
+	return hpkeSealAndExport(pubKey, plaintext, info, exportCtx, exportLen)
+}
 
-	pt, err := opener.Open(ct, info) // aad = info
+// HPKEOpenAndExportWithPriv is HPKEOpenAndExportWithX25519Priv for any
+// supported KEM curve (X25519 or P-256).
+func HPKEOpenAndExportWithPriv(
+	priv crypto.PrivateKey,
+	packet []byte,
+	info []byte,
+	exportCtx []byte,
+	exportLen int,
+) (plaintext []byte, exporterSecret []byte, err error) {
+	privKey, err := ECDHPrivateKey(priv)
 	if err != nil {
-		return nil, nil, fmt.Errorf("hpke open: %w", err)
-	}
-
-	if exportLen < 0 {
-		return nil, nil, fmt.Errorf("exportLen must be non-negative: %d", exportLen)
+		return nil, nil, err
 	}
-	secret := opener.Export(exportCtx, uint(exportLen)) // #nosec G115 - negative check above
-	return pt, secret, nil
+	return hpkeOpenAndExport(privKey, packet, info, exportCtx, exportLen)
 }
@@ -0,0 +1,190 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+// Package keystore migrates keys into a sagecrypto.KeyStorage.
+//
+// ImportLegacyDir reads the per-file layout written by earlier agents (see
+// examples/agent-initialization):
+//
+//	keys/
+//	├── ecdsa.key      # ECDSA private key (PEM)
+//	├── ed25519.key    # Ed25519 private key (raw)
+//	└── x25519.key     # X25519 private key (raw)
+//
+// and LegacyImport.Store saves the keys under one keystore id.
+package keystore
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/formats"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+)
+
+// Legacy key file names.
+const (
+	LegacyECDSAFile   = "ecdsa.key"
+	LegacyEd25519File = "ed25519.key"
+	LegacyX25519File  = "x25519.key"
+)
+
+// ErrNoLegacyKeys is returned when a directory holds none of the legacy files.
+var ErrNoLegacyKeys = errors.New("keystore: no legacy key files found")
+
+var legacyFiles = []struct {
+	name  string
+	parse func([]byte) (sagecrypto.KeyPair, error)
+}{
+	{LegacyECDSAFile, parseLegacyECDSA},
+	{LegacyEd25519File, parseLegacyEd25519},
+	{LegacyX25519File, parseLegacyX25519},
+}
+
+// LegacyImport is the result of ImportLegacyDir.
+type LegacyImport struct {
+	Keys    []sagecrypto.KeyPair // Parsed keys, in legacy file order
+	Files   []string             // File each key was read from, parallel to Keys
+	Missing []string             // Legacy files not present in the directory
+}
+
+// Types returns the key types that were imported.
+func (li *LegacyImport) Types() []sagecrypto.KeyType {
+	types := make([]sagecrypto.KeyType, len(li.Keys))
+	for i, kp := range li.Keys {
+		types[i] = kp.Type()
+	}
+	return types
+}
+
+// ImportLegacyDir reads and validates the legacy key files in dir. Missing
+// files are skipped and reported in Missing; a file that exists but does not
+// hold a valid key fails the import. ErrNoLegacyKeys is returned when no
+// legacy file is present.
+func ImportLegacyDir(dir string) (*LegacyImport, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", dir)
+	}
+
+	li := &LegacyImport{}
+	for _, f := range legacyFiles {
+		data, err := os.ReadFile(filepath.Join(dir, f.name))
+		if errors.Is(err, os.ErrNotExist) {
+			li.Missing = append(li.Missing, f.name)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.name, err)
+		}
+		kp, err := f.parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", f.name, err)
+		}
+		li.Keys = append(li.Keys, kp)
+		li.Files = append(li.Files, f.name)
+	}
+	if len(li.Keys) == 0 {
+		return nil, fmt.Errorf("%w in %s", ErrNoLegacyKeys, dir)
+	}
+	return li, nil
+}
+
+// StorageID is the id a key of keyType is stored under for keystore id.
+func StorageID(id string, keyType sagecrypto.KeyType) string {
+	return id + "." + strings.ToLower(string(keyType))
+}
+
+// Store saves every imported key in store under StorageID(id, type) and
+// returns the ids written. Nothing is written if any of the ids is taken.
+func (li *LegacyImport) Store(store sagecrypto.KeyStorage, id string) ([]string, error) {
+	if id == "" {
+		return nil, errors.New("keystore id is required")
+	}
+	ids := make([]string, len(li.Keys))
+	for i, kp := range li.Keys {
+		ids[i] = StorageID(id, kp.Type())
+		if store.Exists(ids[i]) {
+			return nil, fmt.Errorf("key %s already exists", ids[i])
+		}
+	}
+	for i, kp := range li.Keys {
+		if err := store.Store(ids[i], kp); err != nil {
+			return ids[:i], fmt.Errorf("failed to store %s: %w", ids[i], err)
+		}
+	}
+	return ids, nil
+}
+
+// parseLegacyECDSA accepts the SEC1 P-256 "EC PRIVATE KEY" the legacy layout
+// wrote, and otherwise anything the PEM importer understands (PKCS#8, SAGE's
+// secp256k1 encoding).
+func parseLegacyECDSA(data []byte) (sagecrypto.KeyPair, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block")
+	}
+	if block.Type == "EC PRIVATE KEY" && block.Headers["Curve"] == "" {
+		priv, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse EC private key: %w", err)
+		}
+		if priv.Curve.Params().Name != "P-256" {
+			return nil, fmt.Errorf("unsupported ECDSA curve: %s", priv.Curve.Params().Name)
+		}
+		return keys.NewP256KeyPair(priv, "")
+	}
+	return formats.NewPEMImporter().Import(data, sagecrypto.KeyFormatPEM)
+}
+
+// parseLegacyEd25519 accepts a raw 64-byte private key or 32-byte seed.
+func parseLegacyEd25519(data []byte) (sagecrypto.KeyPair, error) {
+	switch len(data) {
+	case ed25519.PrivateKeySize:
+		priv := ed25519.PrivateKey(data)
+		// The trailing half must be the public key of the seed
+		if !priv.Equal(ed25519.NewKeyFromSeed(priv.Seed())) {
+			return nil, errors.New("public key half does not match the Ed25519 seed")
+		}
+		return keys.NewEd25519KeyPair(priv, "")
+	case ed25519.SeedSize:
+		return keys.NewEd25519KeyPair(ed25519.NewKeyFromSeed(data), "")
+	default:
+		return nil, fmt.Errorf("invalid Ed25519 key size: expected %d, got %d", ed25519.PrivateKeySize, len(data))
+	}
+}
+
+// parseLegacyX25519 accepts a raw 32-byte private key.
+func parseLegacyX25519(data []byte) (sagecrypto.KeyPair, error) {
+	priv, err := ecdh.X25519().NewPrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid X25519 key: %w", err)
+	}
+	return keys.NewX25519KeyPair(priv, "")
+}
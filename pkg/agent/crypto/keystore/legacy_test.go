@@ -0,0 +1,134 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeLegacyDir writes keys the way examples/agent-initialization does.
+func writeLegacyDir(t *testing.T, files ...string) (string, *ecdsa.PrivateKey, ed25519.PrivateKey, *ecdh.PrivateKey) {
+	t.Helper()
+	dir := t.TempDir()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	xKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	for _, name := range files {
+		var data []byte
+		switch name {
+		case LegacyECDSAFile:
+			der, err := x509.MarshalECPrivateKey(ecKey)
+			require.NoError(t, err)
+			data = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+		case LegacyEd25519File:
+			data = edKey
+		case LegacyX25519File:
+			data = xKey.Bytes()
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0600))
+	}
+	return dir, ecKey, edKey, xKey
+}
+
+func TestImportLegacyDir(t *testing.T) {
+	dir, ecKey, edKey, xKey := writeLegacyDir(t, LegacyECDSAFile, LegacyEd25519File, LegacyX25519File)
+
+	li, err := ImportLegacyDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, li.Missing)
+	assert.Equal(t, []string{LegacyECDSAFile, LegacyEd25519File, LegacyX25519File}, li.Files)
+	assert.Equal(t, []sagecrypto.KeyType{sagecrypto.KeyTypeP256, sagecrypto.KeyTypeEd25519, sagecrypto.KeyTypeX25519}, li.Types())
+	assert.True(t, li.Keys[0].PublicKeyEqual(&ecKey.PublicKey))
+	assert.True(t, li.Keys[1].PublicKeyEqual(edKey.Public()))
+	assert.True(t, li.Keys[2].PublicKeyEqual(xKey.PublicKey()))
+
+	store, err := storage.NewFileKeyStorage(t.TempDir())
+	require.NoError(t, err)
+	ids, err := li.Store(store, "my-agent")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"my-agent.p256", "my-agent.ed25519", "my-agent.x25519"}, ids)
+
+	for i, id := range ids {
+		kp, err := store.Load(id)
+		require.NoError(t, err)
+		assert.Equal(t, li.Keys[i].Type(), kp.Type())
+		assert.True(t, kp.PublicKeyEqual(li.Keys[i].PublicKey()), id)
+	}
+
+	t.Run("existing id is not overwritten", func(t *testing.T) {
+		_, err := li.Store(store, "my-agent")
+		assert.Error(t, err)
+	})
+}
+
+func TestImportLegacyDir_Partial(t *testing.T) {
+	dir, _, _, _ := writeLegacyDir(t, LegacyEd25519File)
+
+	li, err := ImportLegacyDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []sagecrypto.KeyType{sagecrypto.KeyTypeEd25519}, li.Types())
+	assert.Equal(t, []string{LegacyECDSAFile, LegacyX25519File}, li.Missing)
+}
+
+func TestImportLegacyDir_Errors(t *testing.T) {
+	t.Run("empty directory", func(t *testing.T) {
+		_, err := ImportLegacyDir(t.TempDir())
+		assert.ErrorIs(t, err, ErrNoLegacyKeys)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := ImportLegacyDir(filepath.Join(t.TempDir(), "nope"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("invalid key file", func(t *testing.T) {
+		dir, _, _, _ := writeLegacyDir(t, LegacyECDSAFile)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, LegacyX25519File), []byte("short"), 0600))
+		_, err := ImportLegacyDir(dir)
+		assert.ErrorContains(t, err, LegacyX25519File)
+	})
+
+	t.Run("corrupt Ed25519 public half", func(t *testing.T) {
+		dir, _, edKey, _ := writeLegacyDir(t)
+		bad := append(ed25519.PrivateKey(nil), edKey...)
+		bad[len(bad)-1] ^= 0x01
+		require.NoError(t, os.WriteFile(filepath.Join(dir, LegacyEd25519File), bad, 0600))
+		_, err := ImportLegacyDir(dir)
+		assert.ErrorContains(t, err, LegacyEd25519File)
+	})
+}
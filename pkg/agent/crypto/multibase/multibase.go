@@ -0,0 +1,154 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+// Package multibase implements the multibase and multicodec encodings used for
+// publicKeyMultibase values in DID documents and A2A Agent Cards.
+//
+// Spec: https://www.w3.org/TR/controller-document/#multibase-0
+package multibase
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/mr-tron/base58"
+)
+
+// Encoding identifies a multibase base encoding by its prefix character.
+type Encoding byte
+
+// Supported multibase encodings.
+const (
+	Base58BTC Encoding = 'z' // base58 with the Bitcoin alphabet
+	Base64URL Encoding = 'u' // base64url without padding
+)
+
+// Codec is a multicodec identifier prepended to key material.
+type Codec uint64
+
+// Supported multicodec key identifiers.
+const (
+	CodecEd25519Pub Codec = 0xed
+	CodecX25519Pub  Codec = 0xec
+)
+
+var (
+	// ErrUnsupportedEncoding is returned for unknown multibase prefixes.
+	ErrUnsupportedEncoding = errors.New("unsupported multibase encoding")
+
+	// ErrUnsupportedCodec is returned for unknown multicodec prefixes.
+	ErrUnsupportedCodec = errors.New("unsupported multicodec")
+)
+
+// keySizes lists the expected raw key length for each supported codec.
+var keySizes = map[Codec]int{
+	CodecEd25519Pub: 32,
+	CodecX25519Pub:  32,
+}
+
+// String returns the multicodec table name of the codec.
+func (c Codec) String() string {
+	switch c {
+	case CodecEd25519Pub:
+		return "ed25519-pub"
+	case CodecX25519Pub:
+		return "x25519-pub"
+	default:
+		return fmt.Sprintf("multicodec(0x%x)", uint64(c))
+	}
+}
+
+// Encode encodes data with the given multibase encoding, including the prefix.
+func Encode(enc Encoding, data []byte) (string, error) {
+	switch enc {
+	case Base58BTC:
+		return string(enc) + base58.Encode(data), nil
+	case Base64URL:
+		return string(enc) + base64.RawURLEncoding.EncodeToString(data), nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedEncoding, rune(enc))
+	}
+}
+
+// Decode decodes a multibase string and reports which encoding it used.
+func Decode(s string) (Encoding, []byte, error) {
+	if len(s) < 2 {
+		return 0, nil, fmt.Errorf("multibase string too short")
+	}
+
+	enc := Encoding(s[0])
+	var (
+		data []byte
+		err  error
+	)
+	switch enc {
+	case Base58BTC:
+		data, err = base58.Decode(s[1:])
+	case Base64URL:
+		data, err = base64.RawURLEncoding.DecodeString(s[1:])
+	default:
+		return 0, nil, fmt.Errorf("%w: %q", ErrUnsupportedEncoding, rune(enc))
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid multibase data: %w", err)
+	}
+	return enc, data, nil
+}
+
+// EncodeMultibase encodes a public key as a multibase string with its
+// multicodec prefix, e.g. "z6Mk..." for an Ed25519 key in base58btc.
+func EncodeMultibase(enc Encoding, codec Codec, key []byte) (string, error) {
+	if err := checkKeySize(codec, key); err != nil {
+		return "", err
+	}
+	prefixed := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64+len(key)), uint64(codec))
+	return Encode(enc, append(prefixed, key...))
+}
+
+// DecodeMultibase decodes a multibase, multicodec-prefixed public key and
+// returns its codec and raw key bytes.
+func DecodeMultibase(s string) (Codec, []byte, error) {
+	_, data, err := Decode(s)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	code, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, nil, fmt.Errorf("invalid multicodec prefix")
+	}
+	codec := Codec(code)
+	key := data[n:]
+	if err := checkKeySize(codec, key); err != nil {
+		return 0, nil, err
+	}
+	return codec, key, nil
+}
+
+func checkKeySize(codec Codec, key []byte) error {
+	size, ok := keySizes[codec]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedCodec, codec)
+	}
+	if len(key) != size {
+		return fmt.Errorf("invalid %s key size: expected %d bytes, got %d", codec, size, len(key))
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package multibase
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecodeMultibase_RoundTrip(t *testing.T) {
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	xPriv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keys := []struct {
+		name  string
+		codec Codec
+		key   []byte
+	}{
+		{"ed25519", CodecEd25519Pub, edPub},
+		{"x25519", CodecX25519Pub, xPriv.PublicKey().Bytes()},
+	}
+	encodings := []struct {
+		name string
+		enc  Encoding
+	}{
+		{"base58btc", Base58BTC},
+		{"base64url", Base64URL},
+	}
+
+	for _, k := range keys {
+		for _, e := range encodings {
+			t.Run(k.name+"/"+e.name, func(t *testing.T) {
+				s, err := EncodeMultibase(e.enc, k.codec, k.key)
+				require.NoError(t, err)
+				assert.Equal(t, byte(e.enc), s[0])
+
+				codec, key, err := DecodeMultibase(s)
+				require.NoError(t, err)
+				assert.Equal(t, k.codec, codec)
+				assert.Equal(t, k.key, key)
+			})
+		}
+	}
+}
+
+func TestEncodeMultibase_WellKnownPrefixes(t *testing.T) {
+	key := make([]byte, 32)
+
+	// did:key identifiers start with these prefixes for the respective key types
+	s, err := EncodeMultibase(Base58BTC, CodecEd25519Pub, key)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(s, "z6Mk"), s)
+
+	s, err = EncodeMultibase(Base58BTC, CodecX25519Pub, key)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(s, "z6LS"), s)
+}
+
+func TestDecodeMultibase_Vector(t *testing.T) {
+	// Example from the W3C Controlled Identifiers specification
+	codec, key, err := DecodeMultibase("z6MkmM42vxfqZQsv4ehtTjFFxQ4sQKS2w6WR7emozFAn5cxu")
+	require.NoError(t, err)
+	assert.Equal(t, CodecEd25519Pub, codec)
+	assert.Len(t, key, ed25519.PublicKeySize)
+}
+
+func TestMultibase_Errors(t *testing.T) {
+	t.Run("unsupported encoding", func(t *testing.T) {
+		_, err := Encode(Encoding('f'), []byte{1})
+		assert.ErrorIs(t, err, ErrUnsupportedEncoding)
+
+		_, _, err = Decode("fdeadbeef")
+		assert.ErrorIs(t, err, ErrUnsupportedEncoding)
+	})
+
+	t.Run("unsupported codec", func(t *testing.T) {
+		_, err := EncodeMultibase(Base58BTC, Codec(0xe7), make([]byte, 33))
+		assert.ErrorIs(t, err, ErrUnsupportedCodec)
+	})
+
+	t.Run("wrong key size", func(t *testing.T) {
+		_, err := EncodeMultibase(Base58BTC, CodecEd25519Pub, make([]byte, 31))
+		assert.Error(t, err)
+
+		s, err := Encode(Base58BTC, []byte{0xed, 0x01, 0x00})
+		require.NoError(t, err)
+		_, _, err = DecodeMultibase(s)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		_, _, err := Decode("z0OIl")
+		assert.Error(t, err)
+
+		_, _, err = Decode("z")
+		assert.Error(t, err)
+	})
+}
@@ -62,6 +62,10 @@ type KeyPair interface {
 
 	// ID returns a unique identifier for this key pair
 	ID() string
+
+	// PublicKeyEqual reports whether other is this key pair's public key
+	// (see PublicKeysEqual)
+	PublicKeyEqual(other crypto.PublicKey) bool
 }
 
 // KeyExporter handles key export operations
@@ -47,6 +47,9 @@ func (m *mockKeyPair) PublicKey() crypto.PublicKey            { return m.publicK
 func (m *mockKeyPair) PrivateKey() crypto.PrivateKey          { return m.privateKey }
 func (m *mockKeyPair) Sign(message []byte) ([]byte, error)    { return nil, nil }
 func (m *mockKeyPair) Verify(message, signature []byte) error { return nil }
+func (m *mockKeyPair) PublicKeyEqual(other crypto.PublicKey) bool {
+	return sagecrypto.PublicKeysEqual(m.publicKey, other)
+}
 
 type mockKeyStorage struct {
 	data map[string]sagecrypto.KeyPair
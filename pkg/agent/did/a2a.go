@@ -20,10 +20,13 @@ package did
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"sort"
 
 	"github.com/mr-tron/base58"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/multibase"
 )
 
 // GenerateA2ACard creates a Google A2A protocol Agent Card from AgentMetadataV4
@@ -37,23 +40,25 @@ func GenerateA2ACard(metadata *AgentMetadataV4) (*A2AAgentCard, error) {
 		return nil, fmt.Errorf("metadata cannot be nil")
 	}
 
-	// Convert keys to A2A format
+	// Convert keys to A2A format in canonical order
 	publicKeys := make([]A2APublicKey, 0, len(metadata.Keys))
-	for i, key := range metadata.Keys {
-		if !key.Verified {
-			// Only include verified keys in the Agent Card
-			continue
-		}
-
-		keyID := fmt.Sprintf("%s#key-%d", metadata.DID, i+1)
+	for _, ck := range canonicalCardKeys(metadata.Keys) {
+		key := ck.key
+		keyID := fmt.Sprintf("%s#key-%d", metadata.DID, ck.index+1)
 		keyType := mapKeyTypeToA2A(key.Type)
 
+		multibaseKey, err := encodeKeyMultibase(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", keyID, err)
+		}
+
 		publicKeys = append(publicKeys, A2APublicKey{
-			ID:              keyID,
-			Type:            keyType,
-			Controller:      string(metadata.DID),
-			PublicKeyBase58: base58.Encode(key.KeyData),
-			PublicKeyHex:    hex.EncodeToString(key.KeyData),
+			ID:                 keyID,
+			Type:               keyType,
+			Controller:         string(metadata.DID),
+			PublicKeyBase58:    base58.Encode(key.KeyData),
+			PublicKeyHex:       hex.EncodeToString(key.KeyData),
+			PublicKeyMultibase: multibaseKey,
 		})
 	}
 
@@ -104,6 +109,66 @@ func GenerateA2ACard(metadata *AgentMetadataV4) (*A2AAgentCard, error) {
 	return card, nil
 }
 
+// cardKey is a metadata key with its position in AgentMetadataV4.Keys,
+// which numbers its card key ID
+type cardKey struct {
+	index int
+	key   AgentKey
+}
+
+// canonicalCardKeys returns the verified keys ordered by purpose (signing
+// keys before key-agreement keys) and then by key ID, dropping exact
+// duplicates (same type and key bytes) after their first occurrence. This
+// keeps card output byte-identical for the same metadata.
+func canonicalCardKeys(keys []AgentKey) []cardKey {
+	out := make([]cardKey, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for i, key := range keys {
+		if !key.Verified {
+			// Only include verified keys in the Agent Card
+			continue
+		}
+		dedupKey := fmt.Sprintf("%d:%x", key.Type, key.KeyData)
+		if seen[dedupKey] {
+			continue
+		}
+		seen[dedupKey] = true
+		out = append(out, cardKey{index: i, key: key})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		pi, pj := keyPurposeOrder(out[i].key.Type), keyPurposeOrder(out[j].key.Type)
+		if pi != pj {
+			return pi < pj
+		}
+		return out[i].index < out[j].index
+	})
+	return out
+}
+
+// keyPurposeOrder ranks signing keys before key-agreement keys
+func keyPurposeOrder(keyType KeyType) int {
+	if keyType == KeyTypeX25519 {
+		return 1
+	}
+	return 0
+}
+
+// encodeKeyMultibase returns the base58btc publicKeyMultibase value for keys
+// that have a multicodec identifier, or "" for key types without one.
+func encodeKeyMultibase(key AgentKey) (string, error) {
+	var codec multibase.Codec
+	switch key.Type {
+	case KeyTypeEd25519:
+		codec = multibase.CodecEd25519Pub
+	case KeyTypeX25519:
+		codec = multibase.CodecX25519Pub
+	default:
+		return "", nil
+	}
+	return multibase.EncodeMultibase(multibase.Base58BTC, codec, key.KeyData)
+}
+
 // mapKeyTypeToA2A converts SAGE KeyType to A2A key type string
 func mapKeyTypeToA2A(keyType KeyType) string {
 	switch keyType {
@@ -178,8 +243,8 @@ func ValidateA2ACard(card *A2AAgentCard) error {
 		if key.Controller == "" {
 			return fmt.Errorf("public key %d: controller is required", i)
 		}
-		if key.PublicKeyBase58 == "" && key.PublicKeyHex == "" {
-			return fmt.Errorf("public key %d: either publicKeyBase58 or publicKeyHex is required", i)
+		if key.PublicKeyBase58 == "" && key.PublicKeyHex == "" && key.PublicKeyMultibase == "" {
+			return fmt.Errorf("public key %d: either publicKeyBase58 or publicKeyHex is required (or publicKeyMultibase)", i)
 		}
 	}
 
@@ -197,6 +262,16 @@ func ValidateA2ACard(card *A2AAgentCard) error {
 		}
 	}
 
+	// Sealed extras are opaque here; only their shape is checked
+	if card.Extras != nil {
+		if card.Extras.Alg == "" {
+			return fmt.Errorf("extras: alg is required")
+		}
+		if _, err := base64.RawURLEncoding.DecodeString(card.Extras.Ciphertext); err != nil || card.Extras.Ciphertext == "" {
+			return fmt.Errorf("extras: ciphertext must be non-empty base64url")
+		}
+	}
+
 	return nil
 }
 
@@ -257,7 +332,8 @@ func MergeA2ACard(metadata *AgentMetadataV4, card *A2AAgentCard) error {
 //
 // This function performs cross-validation between the A2A card and the blockchain:
 //  1. Resolves the DID from the blockchain
-//  2. Verifies that all public keys in the card exist on-chain
+//  2. Verifies that all public keys in the card exist on-chain (with a
+//     KeySetResolver, every key valid during a rollover is accepted)
 //  3. Checks that all keys are marked as verified
 //  4. Validates endpoint consistency
 //
@@ -287,6 +363,10 @@ func ValidateA2ACardWithDID(ctx context.Context, card *A2AAgentCard, resolver Re
 
 	// Convert to V4 metadata for key comparison
 	metadataV4 := FromAgentMetadata(metadata)
+	onChainKeys, err := onChainCardKeys(ctx, resolver, did, metadataV4)
+	if err != nil {
+		return fmt.Errorf("failed to resolve on-chain keys: %w", err)
+	}
 
 	// Verify all public keys in card exist on-chain
 	for _, cardKey := range card.PublicKeys {
@@ -302,13 +382,18 @@ func ValidateA2ACardWithDID(ctx context.Context, card *A2AAgentCard, resolver Re
 			if err != nil {
 				return fmt.Errorf("invalid hex key data in card for key %s: %w", cardKey.ID, err)
 			}
+		} else if cardKey.PublicKeyMultibase != "" {
+			_, cardKeyData, err = multibase.DecodeMultibase(cardKey.PublicKeyMultibase)
+			if err != nil {
+				return fmt.Errorf("invalid multibase key data in card for key %s: %w", cardKey.ID, err)
+			}
 		} else {
 			return fmt.Errorf("key %s has no public key data", cardKey.ID)
 		}
 
 		// Check if this key exists on-chain
 		found := false
-		for _, onChainKey := range metadataV4.Keys {
+		for _, onChainKey := range onChainKeys {
 			// Compare key data
 			if hex.EncodeToString(onChainKey.KeyData) == hex.EncodeToString(cardKeyData) {
 				// Key found - check if verified
@@ -341,3 +426,33 @@ func ValidateA2ACardWithDID(ctx context.Context, card *A2AAgentCard, resolver Re
 
 	return nil
 }
+
+// onChainCardKeys lists the keys a card may reference. When the resolver can
+// return the full signing key set it replaces the single legacy signing key,
+// so both keys of an in-progress rollover are accepted and revoked keys are not.
+func onChainCardKeys(ctx context.Context, resolver Resolver, did AgentDID, metadata *AgentMetadataV4) ([]AgentKey, error) {
+	ks, ok := resolver.(KeySetResolver)
+	if !ok {
+		return metadata.Keys, nil
+	}
+
+	keys, err := ks.ResolvePublicKeys(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]AgentKey, 0, len(keys)+1)
+	for _, k := range keys {
+		raw, err := MarshalPublicKey(k.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", k.ID, err)
+		}
+		out = append(out, AgentKey{Type: k.Type, KeyData: raw, Verified: true, CreatedAt: k.CreatedAt})
+	}
+	for _, k := range metadata.Keys {
+		if k.Type == KeyTypeX25519 {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
@@ -19,9 +19,11 @@
 package did
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/sage-x-project/sage/pkg/agent/crypto/multibase"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -97,6 +99,17 @@ func TestGenerateA2ACard(t *testing.T) {
 	assert.True(t, keyTypes["EcdsaSecp256k1VerificationKey2019"])
 	assert.True(t, keyTypes["X25519KeyAgreementKey2019"])
 
+	// Ed25519 and X25519 keys carry publicKeyMultibase; secp256k1 has no multicodec here
+	for i, pk := range card.PublicKeys {
+		if metadata.Keys[i].Type == KeyTypeECDSA {
+			assert.Empty(t, pk.PublicKeyMultibase)
+			continue
+		}
+		_, key, err := multibase.DecodeMultibase(pk.PublicKeyMultibase)
+		require.NoError(t, err)
+		assert.Equal(t, metadata.Keys[i].KeyData, key)
+	}
+
 	// Verify endpoints
 	require.Len(t, card.Endpoints, 1)
 	assert.Equal(t, "MessageService", card.Endpoints[0].Type)
@@ -204,6 +217,51 @@ func TestGenerateA2ACard_WithAdditionalEndpoints(t *testing.T) {
 	assert.Equal(t, "wss://ws.example.com", endpointTypes["websocket"])
 }
 
+func TestGenerateA2ACard_CanonicalKeys(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	edKey := make([]byte, 32)
+	xKey := make([]byte, 32)
+	for i := range edKey {
+		edKey[i] = byte(i + 1)
+		xKey[i] = byte(i + 66)
+	}
+	metadata := &AgentMetadataV4{
+		DID:      "did:sage:ethereum:0x123",
+		Name:     "Test Agent",
+		Endpoint: "https://api.example.com",
+		Keys: []AgentKey{
+			{Type: KeyTypeX25519, KeyData: xKey, Verified: true, CreatedAt: now},
+			{Type: KeyTypeEd25519, KeyData: edKey, Verified: true, CreatedAt: now},
+			{Type: KeyTypeEd25519, KeyData: edKey, Verified: true, CreatedAt: now}, // duplicate
+			{Type: KeyTypeECDSA, KeyData: []byte{33, 34, 35}, Verified: true, CreatedAt: now},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	first, err := GenerateA2ACard(metadata)
+	require.NoError(t, err)
+	second, err := GenerateA2ACard(metadata)
+	require.NoError(t, err)
+
+	firstJSON, err := json.Marshal(first)
+	require.NoError(t, err)
+	secondJSON, err := json.Marshal(second)
+	require.NoError(t, err)
+	assert.Equal(t, string(firstJSON), string(secondJSON))
+
+	// Signing keys first, then key agreement; the duplicate is dropped
+	ids := make([]string, 0, len(first.PublicKeys))
+	for _, pk := range first.PublicKeys {
+		ids = append(ids, pk.ID)
+	}
+	assert.Equal(t, []string{
+		"did:sage:ethereum:0x123#key-2",
+		"did:sage:ethereum:0x123#key-4",
+		"did:sage:ethereum:0x123#key-1",
+	}, ids)
+}
+
 func TestMapKeyTypeToA2A(t *testing.T) {
 	tests := []struct {
 		name     string
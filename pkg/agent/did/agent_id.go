@@ -0,0 +1,44 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// AgentID derives the on-chain agent ID the same way AgentCardRegistry does
+// when an agent is registered:
+//
+//	keccak256(abi.encodePacked(did, owner, block.timestamp))
+//
+// The ID depends on the registering account and block time, not just the
+// DID, so it cannot be computed before registration. Use it to correlate
+// AgentRegistered events (which carry owner and timestamp) with a DID; to
+// address an existing agent by DID, read the registry's didToAgentId mapping.
+func AgentID(did AgentDID, owner common.Address, registeredAt *big.Int) [32]byte {
+	ts := registeredAt
+	if ts == nil {
+		ts = new(big.Int)
+	}
+	// encodePacked: string bytes, 20-byte address, 32-byte big-endian uint256
+	return crypto.Keccak256Hash([]byte(did), owner.Bytes(), common.LeftPadBytes(ts.Bytes(), 32))
+}
@@ -0,0 +1,42 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgentID(t *testing.T) {
+	owner := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	ts := big.NewInt(1700000000)
+
+	// keccak256(abi.encodePacked("did:sage:ethereum:agent001", owner, uint256(1700000000)))
+	// as computed by AgentCardRegistry.registerAgentWithParams
+	want := common.HexToHash("0x850ec101eacfb4740b8abf71ef9853effe3ba95520f759a47b5ca3024c8566f0")
+	assert.Equal(t, [32]byte(want), AgentID("did:sage:ethereum:agent001", owner, ts))
+
+	// Every input contributes to the ID
+	assert.NotEqual(t, [32]byte(want), AgentID("did:sage:ethereum:agent002", owner, ts))
+	assert.NotEqual(t, [32]byte(want), AgentID("did:sage:ethereum:agent001", common.Address{}, ts))
+	assert.NotEqual(t, [32]byte(want), AgentID("did:sage:ethereum:agent001", owner, big.NewInt(1700000001)))
+}
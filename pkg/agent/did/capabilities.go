@@ -0,0 +1,183 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrUnsupportedCapabilityValue is returned when a capability value is not a
+// bool, a string or a list of strings.
+var ErrUnsupportedCapabilityValue = errors.New("unsupported capability value type")
+
+// Capabilities is a typed view of the capabilities map stored on chain as a
+// JSON string (RegistrationRequest.Capabilities, AgentMetadata.Capabilities).
+// Values are limited to bool, string and []string so capability checks
+// compare like with like; convert an existing map with Capabilities(m) and
+// call Validate.
+type Capabilities map[string]interface{}
+
+// ParseCapabilities decodes the on-chain capabilities string. Lists decode
+// to []string; any other value type fails with ErrUnsupportedCapabilityValue.
+func ParseCapabilities(data string) (Capabilities, error) {
+	c := Capabilities{}
+	if data == "" {
+		return c, nil
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, fmt.Errorf("invalid capabilities JSON: %w", err)
+	}
+	for key, value := range raw {
+		if err := c.Set(key, value); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Set stores value under key. A []interface{} holding only strings, as
+// produced by encoding/json, is stored as []string.
+func (c Capabilities) Set(key string, value interface{}) error {
+	if key == "" {
+		return fmt.Errorf("capability key cannot be empty")
+	}
+	normalized, err := normalizeCapabilityValue(key, value)
+	if err != nil {
+		return err
+	}
+	c[key] = normalized
+	return nil
+}
+
+// Get returns the value stored under key.
+func (c Capabilities) Get(key string) (interface{}, bool) {
+	value, ok := c[key]
+	return value, ok
+}
+
+// Has reports whether key is set. A bool capability set to false counts as
+// absent, so {"streaming": false} does not grant "streaming".
+func (c Capabilities) Has(key string) bool {
+	value, ok := c[key]
+	if !ok {
+		return false
+	}
+	if b, isBool := value.(bool); isBool {
+		return b
+	}
+	return true
+}
+
+// Validate checks that every value is a bool, a string or a list of strings.
+func (c Capabilities) Validate() error {
+	for key, value := range c {
+		if _, err := normalizeCapabilityValue(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalCanonical returns the JSON form stored on chain. The output is
+// stable for equal capability sets: keys are sorted, string lists are sorted
+// and de-duplicated, there is no insignificant whitespace and HTML
+// characters are not escaped.
+func (c Capabilities) MarshalCanonical() ([]byte, error) {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range keys {
+		value, err := normalizeCapabilityValue(key, c[key])
+		if err != nil {
+			return nil, err
+		}
+		if list, ok := value.([]string); ok {
+			value = sortedUnique(list)
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := writeCanonicalJSON(&buf, key); err != nil {
+			return nil, err
+		}
+		buf.WriteByte(':')
+		if err := writeCanonicalJSON(&buf, value); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// normalizeCapabilityValue checks value's type and converts string lists to
+// []string.
+func normalizeCapabilityValue(key string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case bool, string:
+		return v, nil
+	case []string:
+		return append([]string{}, v...), nil
+	case []interface{}:
+		list := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: capability %q has list element of type %T", ErrUnsupportedCapabilityValue, key, item)
+			}
+			list[i] = s
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("%w: capability %q has type %T", ErrUnsupportedCapabilityValue, key, value)
+	}
+}
+
+// writeCanonicalJSON encodes v without HTML escaping or a trailing newline.
+func writeCanonicalJSON(buf *bytes.Buffer, v interface{}) error {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
+
+func sortedUnique(list []string) []string {
+	out := append([]string(nil), list...)
+	sort.Strings(out)
+	n := 0
+	for i, s := range out {
+		if i == 0 || s != out[n-1] {
+			out[n] = s
+			n++
+		}
+	}
+	return out[:n]
+}
@@ -0,0 +1,88 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities(t *testing.T) {
+	t.Run("Set, Get and Has", func(t *testing.T) {
+		c := Capabilities{}
+		require.NoError(t, c.Set("streaming", true))
+		require.NoError(t, c.Set("batch", false))
+		require.NoError(t, c.Set("version", "1.0"))
+		require.NoError(t, c.Set("skills", []interface{}{"chat", "search"}))
+
+		v, ok := c.Get("skills")
+		require.True(t, ok)
+		assert.Equal(t, []string{"chat", "search"}, v)
+		assert.True(t, c.Has("streaming"))
+		assert.True(t, c.Has("version"))
+		assert.False(t, c.Has("batch"), "false bool does not grant a capability")
+		assert.False(t, c.Has("missing"))
+	})
+
+	t.Run("MarshalCanonical is stable", func(t *testing.T) {
+		a := Capabilities{}
+		require.NoError(t, a.Set("skills", []string{"search", "chat", "search"}))
+		require.NoError(t, a.Set("note", "a<b"))
+		require.NoError(t, a.Set("streaming", true))
+
+		b := Capabilities{
+			"streaming": true,
+			"note":      "a<b",
+			"skills":    []interface{}{"chat", "search"},
+		}
+
+		want := `{"note":"a<b","skills":["chat","search"],"streaming":true}`
+		for i := 0; i < 10; i++ {
+			got, err := a.MarshalCanonical()
+			require.NoError(t, err)
+			assert.Equal(t, want, string(got))
+			got, err = b.MarshalCanonical()
+			require.NoError(t, err)
+			assert.Equal(t, want, string(got))
+		}
+
+		parsed, err := ParseCapabilities(want)
+		require.NoError(t, err)
+		got, err := parsed.MarshalCanonical()
+		require.NoError(t, err)
+		assert.Equal(t, want, string(got))
+	})
+
+	t.Run("Rejects unsupported value types", func(t *testing.T) {
+		c := Capabilities{}
+		for _, v := range []interface{}{42, 1.5, nil, map[string]interface{}{"a": "b"}, []interface{}{"ok", 1}, []int{1}} {
+			assert.ErrorIs(t, c.Set("bad", v), ErrUnsupportedCapabilityValue, "%T", v)
+		}
+		assert.Empty(t, c)
+
+		_, err := Capabilities{"endpoints": []map[string]interface{}{{"uri": "x"}}}.MarshalCanonical()
+		assert.ErrorIs(t, err, ErrUnsupportedCapabilityValue)
+		assert.ErrorIs(t, Capabilities{"limit": 10}.Validate(), ErrUnsupportedCapabilityValue)
+
+		_, err = ParseCapabilities(`{"limit":10}`)
+		assert.ErrorIs(t, err, ErrUnsupportedCapabilityValue)
+	})
+}
@@ -0,0 +1,114 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+)
+
+// cardExtrasInfo prefixes the HPKE info for sealed card extras. The card ID
+// is appended so a blob cannot be moved to another card.
+const cardExtrasInfo = "sage/card-extras/v1|"
+
+// SealedExtras is private card metadata encrypted with HPKE (base mode) to
+// a recipient's KEM key. Only the holder of the matching private key can
+// read it; everyone else sees an opaque blob.
+type SealedExtras struct {
+	Alg        string `json:"alg"`        // HPKE suite, e.g. "HPKE-X25519-SHA256-ChaCha20Poly1305"
+	Ciphertext string `json:"ciphertext"` // base64url(enc || ct)
+}
+
+// SealCardExtras encrypts extras to recipientKEMKey (X25519 or P-256) and
+// stores the result in card.Extras, replacing any previous value. Public
+// card fields stay in plaintext. Seal before signing the card so the proof
+// covers the sealed blob.
+func SealCardExtras(card *A2AAgentCard, recipientKEMKey crypto.PublicKey, extras map[string]interface{}) error {
+	if card == nil {
+		return fmt.Errorf("card cannot be nil")
+	}
+	if card.ID == "" {
+		return fmt.Errorf("card ID is required")
+	}
+	pub, err := keys.ECDHPublicKey(recipientKEMKey)
+	if err != nil {
+		return fmt.Errorf("invalid recipient KEM key: %w", err)
+	}
+
+	plaintext, err := json.Marshal(extras)
+	if err != nil {
+		return fmt.Errorf("failed to marshal extras: %w", err)
+	}
+	packet, _, err := keys.HPKESealAndExportToPeer(pub, plaintext, []byte(cardExtrasInfo+card.ID), nil, 0)
+	if err != nil {
+		return fmt.Errorf("failed to seal extras: %w", err)
+	}
+
+	card.Extras = &SealedExtras{
+		Alg:        cardExtrasAlg(pub.Curve()),
+		Ciphertext: base64.RawURLEncoding.EncodeToString(packet),
+	}
+	return nil
+}
+
+// OpenCardExtras decrypts card.Extras with the recipient's KEM private key.
+// It fails if the card has no extras, the key is not the recipient's, or
+// the blob was sealed for a different card.
+func OpenCardExtras(card *A2AAgentCard, recipientKEMPriv crypto.PrivateKey) (map[string]interface{}, error) {
+	if card == nil {
+		return nil, fmt.Errorf("card cannot be nil")
+	}
+	if card.Extras == nil {
+		return nil, fmt.Errorf("card has no sealed extras")
+	}
+	priv, err := keys.ECDHPrivateKey(recipientKEMPriv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KEM private key: %w", err)
+	}
+	if alg := cardExtrasAlg(priv.Curve()); card.Extras.Alg != alg {
+		return nil, fmt.Errorf("extras sealed with %s, key is for %s", card.Extras.Alg, alg)
+	}
+
+	packet, err := base64.RawURLEncoding.DecodeString(card.Extras.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extras ciphertext: %w", err)
+	}
+	plaintext, _, err := keys.HPKEOpenAndExportWithPriv(priv, packet, []byte(cardExtrasInfo+card.ID), nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open extras: %w", err)
+	}
+
+	var extras map[string]interface{}
+	if err := json.Unmarshal(plaintext, &extras); err != nil {
+		return nil, fmt.Errorf("invalid extras: %w", err)
+	}
+	return extras, nil
+}
+
+func cardExtrasAlg(curve ecdh.Curve) string {
+	if curve == ecdh.P256() {
+		return "HPKE-P256-SHA256-AES128GCM"
+	}
+	return "HPKE-X25519-SHA256-ChaCha20Poly1305"
+}
@@ -0,0 +1,91 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealCardExtras(t *testing.T) {
+	recipient, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	outsider, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	cardWithProof := signedTestCard(t)
+	card := &cardWithProof.A2AAgentCard
+	extras := map[string]interface{}{
+		"pricing":       "enterprise",
+		"internalTools": []interface{}{"billing", "crm"},
+	}
+	require.NoError(t, SealCardExtras(card, recipient.PublicKey(), extras))
+
+	// Public fields stay readable and the card still validates
+	require.NoError(t, ValidateA2ACard(card))
+	data, err := json.Marshal(card)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), card.Name)
+	assert.NotContains(t, string(data), "enterprise")
+	assert.NotContains(t, string(data), "billing")
+
+	t.Run("recipient can open", func(t *testing.T) {
+		opened, err := OpenCardExtras(card, recipient)
+		require.NoError(t, err)
+		assert.Equal(t, extras, opened)
+	})
+
+	t.Run("non-recipient cannot open", func(t *testing.T) {
+		_, err := OpenCardExtras(card, outsider)
+		assert.Error(t, err)
+	})
+
+	t.Run("extras are bound to the card", func(t *testing.T) {
+		moved := *card
+		moved.ID = "did:sage:ethereum:0xother"
+		_, err := OpenCardExtras(&moved, recipient)
+		assert.Error(t, err)
+	})
+
+	t.Run("P-256 recipient", func(t *testing.T) {
+		p256, err := ecdh.P256().GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		sealed := *card
+		require.NoError(t, SealCardExtras(&sealed, p256.PublicKey(), extras))
+		assert.Equal(t, "HPKE-P256-SHA256-AES128GCM", sealed.Extras.Alg)
+
+		opened, err := OpenCardExtras(&sealed, p256)
+		require.NoError(t, err)
+		assert.Equal(t, extras, opened)
+
+		_, err = OpenCardExtras(&sealed, recipient)
+		assert.ErrorContains(t, err, "sealed with HPKE-P256")
+	})
+
+	t.Run("malformed extras fail validation", func(t *testing.T) {
+		broken := *card
+		broken.Extras = &SealedExtras{Alg: card.Extras.Alg, Ciphertext: "not base64url!"}
+		assert.Error(t, ValidateA2ACard(&broken))
+	})
+}
@@ -0,0 +1,175 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CardWellKnownPath is where an agent publishes its A2A card. FetchCard
+// appends it to URLs that have no path.
+const CardWellKnownPath = "/.well-known/sage-agent-card.json"
+
+// DefaultMaxCardSize bounds the size of a fetched card (64 KiB)
+const DefaultMaxCardSize = 64 << 10
+
+// CardFetchOptions configures FetchCardWithOptions
+type CardFetchOptions struct {
+	// HTTPClient performs the request; nil uses a client with a 10 second timeout
+	HTTPClient *http.Client
+	// MaxSize bounds the response body in bytes (default DefaultMaxCardSize)
+	MaxSize int64
+	// RequireProof rejects cards without a proof. A proof that is present is
+	// always verified.
+	RequireProof bool
+}
+
+// FetchCard retrieves an agent card over HTTP(S) with default options.
+// See FetchCardWithOptions.
+func FetchCard(ctx context.Context, cardURL string) (*A2AAgentCard, error) {
+	return FetchCardWithOptions(ctx, cardURL, CardFetchOptions{})
+}
+
+// FetchCardWithOptions retrieves an agent card from cardURL. A URL without a
+// path (e.g. "https://agent.example.com") is resolved to CardWellKnownPath.
+//
+// The response must be 200 with a JSON content type and at most MaxSize
+// bytes. The card is checked with ValidateA2ACard and, if it carries a
+// proof, with VerifyA2ACardProof. The card's keys are not checked against
+// the chain; use ValidateA2ACardWithDID before trusting them.
+func FetchCardWithOptions(ctx context.Context, cardURL string, opts CardFetchOptions) (*A2AAgentCard, error) {
+	target, err := cardLocation(cardURL)
+	if err != nil {
+		return nil, err
+	}
+
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxCardSize
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create card request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("card request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("card request failed: HTTP %d", resp.StatusCode)
+	}
+	if err := checkCardContentType(resp.Header.Get("Content-Type")); err != nil {
+		return nil, err
+	}
+	if resp.ContentLength > maxSize {
+		return nil, fmt.Errorf("card too large: %d bytes exceeds limit of %d", resp.ContentLength, maxSize)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read card: %w", err)
+	}
+	if int64(len(body)) > maxSize {
+		return nil, fmt.Errorf("card too large: exceeds limit of %d bytes", maxSize)
+	}
+
+	var card A2AAgentCardWithProof
+	if err := json.Unmarshal(body, &card); err != nil {
+		return nil, fmt.Errorf("invalid card JSON: %w", err)
+	}
+	if err := ValidateA2ACard(&card.A2AAgentCard); err != nil {
+		return nil, fmt.Errorf("invalid card: %w", err)
+	}
+
+	switch {
+	case card.Proof != nil:
+		if _, err := VerifyA2ACardProof(&card); err != nil {
+			return nil, fmt.Errorf("card proof verification failed: %w", err)
+		}
+	case opts.RequireProof:
+		return nil, fmt.Errorf("card has no proof")
+	}
+
+	return &card.A2AAgentCard, nil
+}
+
+// CardHandler serves card as JSON. Mount it at CardWellKnownPath.
+func CardHandler(card interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		data, err := json.Marshal(card)
+		if err != nil {
+			http.Error(w, "failed to encode card", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	})
+}
+
+// cardLocation validates cardURL and applies the well-known path
+func cardLocation(cardURL string) (string, error) {
+	u, err := url.Parse(cardURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid card URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("card URL must use http or https scheme, got: %s", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("card URL host cannot be empty")
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = CardWellKnownPath
+	}
+	return u.String(), nil
+}
+
+// checkCardContentType accepts application/json and application/*+json
+func checkCardContentType(contentType string) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("invalid card content type %q", contentType)
+	}
+	if mediaType == "application/json" ||
+		(strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json")) {
+		return nil
+	}
+	return fmt.Errorf("unexpected card content type %q", mediaType)
+}
@@ -0,0 +1,156 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedTestCard(t *testing.T) *A2AAgentCardWithProof {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	card, err := GenerateA2ACardWithProof(&AgentMetadataV4{
+		DID:      "did:sage:ethereum:0x1234567890abcdef",
+		Name:     "Card Agent",
+		Endpoint: "https://card.agent.example",
+		Keys: []AgentKey{
+			{Type: KeyTypeEd25519, KeyData: pub, Verified: true, CreatedAt: now},
+		},
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, priv, KeyTypeEd25519)
+	require.NoError(t, err)
+	return card
+}
+
+func TestFetchCard(t *testing.T) {
+	ctx := context.Background()
+	card := signedTestCard(t)
+
+	t.Run("Fetches from the well-known path", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.Handle(CardWellKnownPath, CardHandler(card))
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		fetched, err := FetchCard(ctx, server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, card.ID, fetched.ID)
+		assert.Equal(t, card.PublicKeys, fetched.PublicKeys)
+	})
+
+	t.Run("Explicit path", func(t *testing.T) {
+		server := httptest.NewServer(CardHandler(card))
+		defer server.Close()
+
+		fetched, err := FetchCardWithOptions(ctx, server.URL+"/cards/agent.json", CardFetchOptions{RequireProof: true})
+		require.NoError(t, err)
+		assert.Equal(t, card.Name, fetched.Name)
+	})
+
+	t.Run("Tampered card fails proof verification", func(t *testing.T) {
+		tampered := *card
+		tampered.Name = "Impostor"
+		server := httptest.NewServer(CardHandler(&tampered))
+		defer server.Close()
+
+		_, err := FetchCard(ctx, server.URL)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "proof verification failed")
+	})
+
+	t.Run("RequireProof rejects unsigned cards", func(t *testing.T) {
+		server := httptest.NewServer(CardHandler(card.A2AAgentCard))
+		defer server.Close()
+
+		_, err := FetchCard(ctx, server.URL)
+		require.NoError(t, err, "unsigned cards are accepted by default")
+
+		_, err = FetchCardWithOptions(ctx, server.URL, CardFetchOptions{RequireProof: true})
+		assert.Error(t, err)
+	})
+
+	t.Run("Oversized responses are rejected", func(t *testing.T) {
+		padded := *card
+		padded.Description = strings.Repeat("x", 2048)
+		server := httptest.NewServer(CardHandler(&padded))
+		defer server.Close()
+
+		_, err := FetchCardWithOptions(ctx, server.URL, CardFetchOptions{MaxSize: 1024})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "too large")
+
+		// Without a Content-Length the body limit still applies
+		chunked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			for i := 0; i < 4; i++ {
+				_, _ = w.Write([]byte(strings.Repeat(" ", 512)))
+				w.(http.Flusher).Flush()
+			}
+		}))
+		defer chunked.Close()
+
+		_, err = FetchCardWithOptions(ctx, chunked.URL, CardFetchOptions{MaxSize: 1024})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "too large")
+	})
+
+	t.Run("Rejects bad responses", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/html":
+				w.Header().Set("Content-Type", "text/html")
+				_, _ = w.Write([]byte("<html></html>"))
+			case "/invalid":
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"id":"did:sage:ethereum:0x1"}`))
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		defer server.Close()
+
+		for _, path := range []string{"/html", "/invalid", "/missing"} {
+			_, err := FetchCard(ctx, server.URL+path)
+			assert.Error(t, err, path)
+		}
+	})
+
+	t.Run("Rejects non-HTTP URLs", func(t *testing.T) {
+		_, err := FetchCard(ctx, "file:///etc/passwd")
+		assert.Error(t, err)
+		_, err = FetchCard(ctx, "https://")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,150 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+)
+
+// ChainFeature names an operation a chain's client supports
+type ChainFeature string
+
+const (
+	// FeatureRegister covers Register, Update and Deactivate
+	FeatureRegister ChainFeature = "register"
+	// FeatureResolve covers Resolve, VerifyMetadata, Search and ListAgentsByOwner
+	FeatureResolve ChainFeature = "resolve"
+	// FeatureKeyManagement covers AddKey and RevokeKey
+	FeatureKeyManagement ChainFeature = "key-management"
+	// FeatureKeyApproval covers ApproveEd25519Key
+	FeatureKeyApproval ChainFeature = "key-approval"
+	// FeatureKeySets means every valid signing key can be resolved (KeySetResolver)
+	FeatureKeySets ChainFeature = "key-sets"
+	// FeatureKEMKeySets means every KEM key can be resolved (KEMKeySetResolver)
+	FeatureKEMKeySets ChainFeature = "kem-key-sets"
+	// FeatureSafeRegistration covers PrepareSafeRegistration (SafeRegistry)
+	FeatureSafeRegistration ChainFeature = "safe-registration"
+)
+
+// KnownChains lists every chain a Manager can be configured for
+var KnownChains = []Chain{ChainEthereum, ChainSolana}
+
+// ChainInfo reports a chain's configuration and the features its client supports
+type ChainInfo struct {
+	Chain   Chain   `json:"chain"`
+	Network Network `json:"network,omitempty"`
+	// Configured is true once Configure (or ConfigureWithBackend) succeeded
+	Configured bool `json:"configured"`
+	// ClientReady is true when a client is installed; a configured chain
+	// without a client supports no features until SetClient is called
+	ClientReady bool           `json:"clientReady"`
+	Features    []ChainFeature `json:"features"`
+}
+
+// Supports reports whether the chain supports feature
+func (i ChainInfo) Supports(feature ChainFeature) bool {
+	for _, f := range i.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// keyManager and keyApprover split RegistryV4 so a client can support key
+// management without key approval (or the reverse)
+type keyManager interface {
+	AddKey(ctx context.Context, did AgentDID, key AgentKey) (keyHash string, err error)
+	RevokeKey(ctx context.Context, did AgentDID, keyHash string) error
+}
+
+type keyApprover interface {
+	ApproveEd25519Key(ctx context.Context, keyHash string) error
+}
+
+// ListChains reports every known chain in KnownChains order, followed by any
+// other configured chain, with the features its installed client supports.
+// Tools can check ChainInfo.Supports before calling an operation.
+func (m *Manager) ListChains() []ChainInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	chains := append([]Chain(nil), KnownChains...)
+	for chain := range m.configs {
+		known := false
+		for _, c := range KnownChains {
+			if c == chain {
+				known = true
+				break
+			}
+		}
+		if !known {
+			chains = append(chains, chain)
+		}
+	}
+
+	infos := make([]ChainInfo, 0, len(chains))
+	for _, chain := range chains {
+		infos = append(infos, m.chainInfoLocked(chain))
+	}
+	return infos
+}
+
+// GetChainInfo reports the configuration and features of a single chain
+func (m *Manager) GetChainInfo(chain Chain) ChainInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.chainInfoLocked(chain)
+}
+
+func (m *Manager) chainInfoLocked(chain Chain) ChainInfo {
+	info := ChainInfo{Chain: chain, Features: []ChainFeature{}}
+	if config, ok := m.configs[chain]; ok {
+		info.Configured = true
+		info.Network = config.Network
+	}
+
+	registry := m.registry.GetRegistry(chain)
+	resolver := m.resolver.resolvers[chain]
+	info.ClientReady = registry != nil && resolver != nil
+
+	if registry != nil {
+		info.Features = append(info.Features, FeatureRegister)
+	}
+	if resolver != nil {
+		info.Features = append(info.Features, FeatureResolve)
+	}
+	if _, ok := registry.(keyManager); ok {
+		info.Features = append(info.Features, FeatureKeyManagement)
+	}
+	if _, ok := registry.(keyApprover); ok {
+		info.Features = append(info.Features, FeatureKeyApproval)
+	}
+	if _, ok := resolver.(KeySetResolver); ok {
+		info.Features = append(info.Features, FeatureKeySets)
+	}
+	if _, ok := resolver.(KEMKeySetResolver); ok {
+		info.Features = append(info.Features, FeatureKEMKeySets)
+	}
+	if _, ok := registry.(SafeRegistry); ok {
+		info.Features = append(info.Features, FeatureSafeRegistration)
+	}
+	return info
+}
@@ -0,0 +1,121 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// basicClient implements only Registry and Resolver
+type basicClient struct {
+	MockRegistry
+	MockResolver
+}
+
+// keyManagingClient additionally manages and approves keys and resolves key sets
+type keyManagingClient struct {
+	basicClient
+}
+
+func (c *keyManagingClient) AddKey(ctx context.Context, did AgentDID, key AgentKey) (string, error) {
+	return "0xkey", nil
+}
+
+func (c *keyManagingClient) RevokeKey(ctx context.Context, did AgentDID, keyHash string) error {
+	return nil
+}
+
+func (c *keyManagingClient) ApproveEd25519Key(ctx context.Context, keyHash string) error {
+	return nil
+}
+
+func (c *keyManagingClient) ResolvePublicKeys(ctx context.Context, did AgentDID) ([]VerificationKey, error) {
+	return nil, nil
+}
+
+func TestManager_ListChains(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager()
+
+	t.Run("Nothing configured", func(t *testing.T) {
+		chains := manager.ListChains()
+		require.Len(t, chains, len(KnownChains))
+		for _, info := range chains {
+			assert.False(t, info.Configured)
+			assert.False(t, info.ClientReady)
+			assert.Empty(t, info.Features)
+		}
+	})
+
+	require.NoError(t, manager.Configure(ChainEthereum, &RegistryConfig{
+		Network:         NetworkEthereumSepolia,
+		ContractAddress: "0x1234567890123456789012345678901234567890",
+		RPCEndpoint:     "http://localhost:8545",
+	}))
+	require.NoError(t, manager.Configure(ChainSolana, &RegistryConfig{
+		ContractAddress: "SageRegistry11111111111111111111111111111111",
+		RPCEndpoint:     "http://localhost:8899",
+	}))
+
+	t.Run("Configured without clients", func(t *testing.T) {
+		info := manager.GetChainInfo(ChainSolana)
+		assert.True(t, info.Configured)
+		assert.False(t, info.ClientReady)
+		assert.False(t, info.Supports(FeatureRegister))
+	})
+
+	require.NoError(t, manager.SetClient(ChainEthereum, &keyManagingClient{}))
+	require.NoError(t, manager.SetClient(ChainSolana, &basicClient{}))
+
+	t.Run("Capabilities differ per chain", func(t *testing.T) {
+		chains := manager.ListChains()
+		require.Len(t, chains, 2)
+
+		eth, sol := chains[0], chains[1]
+		assert.Equal(t, ChainEthereum, eth.Chain)
+		assert.Equal(t, NetworkEthereumSepolia, eth.Network)
+		assert.Equal(t, ChainSolana, sol.Chain)
+
+		assert.True(t, eth.Configured && eth.ClientReady)
+		assert.True(t, sol.Configured && sol.ClientReady)
+
+		assert.Equal(t, []ChainFeature{
+			FeatureRegister, FeatureResolve, FeatureKeyManagement, FeatureKeyApproval, FeatureKeySets,
+		}, eth.Features)
+		assert.Equal(t, []ChainFeature{FeatureRegister, FeatureResolve}, sol.Features)
+
+		assert.True(t, eth.Supports(FeatureKeyApproval))
+		assert.False(t, sol.Supports(FeatureKeyApproval))
+	})
+
+	t.Run("Unsupported operations fail as reported", func(t *testing.T) {
+		assert.NoError(t, manager.ApproveEd25519Key(ctx, ChainEthereum, "0xkey"))
+
+		err := manager.ApproveEd25519Key(ctx, ChainSolana, "0xkey")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not support key approval")
+
+		_, err = manager.AddKey(ctx, ChainSolana, "did:sage:solana:agent", AgentKey{})
+		assert.Error(t, err)
+	})
+}
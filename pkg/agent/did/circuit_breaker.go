@@ -0,0 +1,270 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sage-x-project/sage/internal/metrics"
+)
+
+// ErrResolverUnavailable is returned without contacting the underlying
+// resolver while the circuit breaker is open.
+var ErrResolverUnavailable = DIDError{Code: "RESOLVER_UNAVAILABLE", Message: "DID resolver unavailable: circuit breaker open"}
+
+// BreakerState is the state of a CircuitBreakerResolver
+type BreakerState int
+
+const (
+	// BreakerClosed passes every call through to the resolver
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails fast until the cooldown elapses
+	BreakerOpen
+	// BreakerHalfOpen lets a single probe through to test recovery
+	BreakerHalfOpen
+)
+
+// String returns the state name
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig configures a CircuitBreakerResolver
+type CircuitBreakerConfig struct {
+	// Name labels the breaker's metrics (default "default")
+	Name string
+	// FailureThreshold is the number of consecutive failures that opens the breaker (default 5)
+	FailureThreshold int
+	// Cooldown is how long the breaker stays open before probing (default 30s)
+	Cooldown time.Duration
+	// OnStateChange is called synchronously on every transition and must
+	// not call back into the breaker
+	OnStateChange func(from, to BreakerState)
+	// IsFailure decides whether an error counts against the resolver.
+	// Default: DID domain errors (not found, inactive, ...) and caller
+	// cancellation are healthy answers; everything else is a failure.
+	IsFailure func(error) bool
+}
+
+// CircuitBreakerResolver wraps a Resolver so a degraded RPC endpoint fails
+// fast instead of stalling every caller on timeouts.
+//
+// After FailureThreshold consecutive failures the breaker opens and all calls
+// return ErrResolverUnavailable for Cooldown. The next call after the cooldown
+// is sent as a single probe (half-open); concurrent calls keep failing fast.
+// A successful probe closes the breaker, a failed one re-opens it.
+type CircuitBreakerResolver struct {
+	inner Resolver
+	cfg   CircuitBreakerConfig
+	now   func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+var (
+	_ Resolver          = (*CircuitBreakerResolver)(nil)
+	_ KeySetResolver    = (*CircuitBreakerResolver)(nil)
+	_ KEMKeySetResolver = (*CircuitBreakerResolver)(nil)
+)
+
+// NewCircuitBreakerResolver wraps inner with a circuit breaker
+func NewCircuitBreakerResolver(inner Resolver, cfg CircuitBreakerConfig) *CircuitBreakerResolver {
+	if cfg.Name == "" {
+		cfg.Name = "default"
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = defaultIsResolverFailure
+	}
+
+	metrics.DIDResolverBreakerState.WithLabelValues(cfg.Name).Set(float64(BreakerClosed))
+
+	return &CircuitBreakerResolver{
+		inner: inner,
+		cfg:   cfg,
+		now:   time.Now,
+	}
+}
+
+// State returns the current breaker state
+func (b *CircuitBreakerResolver) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Resolve retrieves agent metadata through the breaker
+func (b *CircuitBreakerResolver) Resolve(ctx context.Context, did AgentDID) (*AgentMetadata, error) {
+	return guard(b, func() (*AgentMetadata, error) { return b.inner.Resolve(ctx, did) })
+}
+
+// ResolvePublicKey retrieves the public key through the breaker
+func (b *CircuitBreakerResolver) ResolvePublicKey(ctx context.Context, did AgentDID) (interface{}, error) {
+	return guard(b, func() (interface{}, error) { return b.inner.ResolvePublicKey(ctx, did) })
+}
+
+// ResolvePublicKeys retrieves all currently valid signing keys through the breaker
+func (b *CircuitBreakerResolver) ResolvePublicKeys(ctx context.Context, did AgentDID) ([]VerificationKey, error) {
+	return guard(b, func() ([]VerificationKey, error) { return ResolveVerificationKeys(ctx, b.inner, did) })
+}
+
+// ResolveKEMKey retrieves the KEM key through the breaker
+func (b *CircuitBreakerResolver) ResolveKEMKey(ctx context.Context, did AgentDID) (interface{}, error) {
+	return guard(b, func() (interface{}, error) { return b.inner.ResolveKEMKey(ctx, did) })
+}
+
+// ResolveKEMKeys retrieves all KEM keys through the breaker
+func (b *CircuitBreakerResolver) ResolveKEMKeys(ctx context.Context, did AgentDID) ([]KEMKeyEntry, error) {
+	return guard(b, func() ([]KEMKeyEntry, error) { return ResolveKEMKeys(ctx, b.inner, did) })
+}
+
+// VerifyMetadata verifies metadata through the breaker
+func (b *CircuitBreakerResolver) VerifyMetadata(ctx context.Context, did AgentDID, metadata *AgentMetadata) (*VerificationResult, error) {
+	return guard(b, func() (*VerificationResult, error) { return b.inner.VerifyMetadata(ctx, did, metadata) })
+}
+
+// ListAgentsByOwner lists agents through the breaker
+func (b *CircuitBreakerResolver) ListAgentsByOwner(ctx context.Context, ownerAddress string) ([]*AgentMetadata, error) {
+	return guard(b, func() ([]*AgentMetadata, error) { return b.inner.ListAgentsByOwner(ctx, ownerAddress) })
+}
+
+// Search searches agents through the breaker
+func (b *CircuitBreakerResolver) Search(ctx context.Context, criteria SearchCriteria) ([]*AgentMetadata, error) {
+	return guard(b, func() ([]*AgentMetadata, error) { return b.inner.Search(ctx, criteria) })
+}
+
+// guard runs call if the breaker admits it and records the outcome
+func guard[T any](b *CircuitBreakerResolver, call func() (T, error)) (T, error) {
+	probe, err := b.admit()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	result, err := call()
+	b.record(probe, err != nil && b.cfg.IsFailure(err))
+	return result, err
+}
+
+// admit decides whether a call may proceed and whether it is the half-open probe
+func (b *CircuitBreakerResolver) admit() (probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.Cooldown {
+			metrics.DIDResolverFastFails.WithLabelValues(b.cfg.Name).Inc()
+			return false, ErrResolverUnavailable
+		}
+		b.transitionLocked(BreakerHalfOpen)
+		b.probing = true
+		return true, nil
+	case BreakerHalfOpen:
+		if b.probing {
+			metrics.DIDResolverFastFails.WithLabelValues(b.cfg.Name).Inc()
+			return false, ErrResolverUnavailable
+		}
+		b.probing = true
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// record updates the breaker with a call outcome
+func (b *CircuitBreakerResolver) record(probe, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+		if failed {
+			b.openedAt = b.now()
+			b.transitionLocked(BreakerOpen)
+			return
+		}
+		b.failures = 0
+		b.transitionLocked(BreakerClosed)
+		return
+	}
+
+	// Outcomes of calls admitted before the breaker opened do not move it further
+	if b.state != BreakerClosed {
+		return
+	}
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.cfg.FailureThreshold {
+		b.openedAt = b.now()
+		b.transitionLocked(BreakerOpen)
+	}
+}
+
+// transitionLocked changes state, notifying metrics and the callback; the caller holds b.mu
+func (b *CircuitBreakerResolver) transitionLocked(to BreakerState) {
+	from := b.state
+	if from == to {
+		return
+	}
+	b.state = to
+
+	metrics.DIDResolverBreakerState.WithLabelValues(b.cfg.Name).Set(float64(to))
+	metrics.DIDResolverBreakerTransitions.WithLabelValues(b.cfg.Name, from.String(), to.String()).Inc()
+	if b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(from, to)
+	}
+}
+
+// defaultIsResolverFailure treats DID domain answers and caller cancellation
+// as healthy responses; transport errors and timeouts count as failures.
+func defaultIsResolverFailure(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var didErr DIDError
+	if errors.As(err, &didErr) {
+		return didErr.Code == ErrResolverUnavailable.Code
+	}
+	return true
+}
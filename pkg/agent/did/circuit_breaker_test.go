@@ -0,0 +1,172 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyResolver fails Resolve with err while it is set
+type flakyResolver struct {
+	MockResolver
+	err   atomic.Pointer[error]
+	calls atomic.Int32
+}
+
+func (f *flakyResolver) setErr(err error) {
+	if err == nil {
+		f.err.Store(nil)
+		return
+	}
+	f.err.Store(&err)
+}
+
+func (f *flakyResolver) Resolve(_ context.Context, did AgentDID) (*AgentMetadata, error) {
+	f.calls.Add(1)
+	if p := f.err.Load(); p != nil {
+		return nil, *p
+	}
+	return &AgentMetadata{DID: did, IsActive: true}, nil
+}
+
+func TestCircuitBreakerResolver(t *testing.T) {
+	ctx := context.Background()
+	did := AgentDID("did:sage:ethereum:breaker")
+
+	newBreaker := func(inner Resolver) (*CircuitBreakerResolver, *time.Time, *[]string) {
+		now := time.Unix(1700000000, 0)
+		var transitions []string
+		b := NewCircuitBreakerResolver(inner, CircuitBreakerConfig{
+			Name:             "test",
+			FailureThreshold: 3,
+			Cooldown:         10 * time.Second,
+			OnStateChange: func(from, to BreakerState) {
+				transitions = append(transitions, from.String()+"->"+to.String())
+			},
+		})
+		b.now = func() time.Time { return now }
+		return b, &now, &transitions
+	}
+
+	t.Run("opens, fails fast, then recovers", func(t *testing.T) {
+		inner := &flakyResolver{}
+		b, now, transitions := newBreaker(inner)
+
+		inner.setErr(errors.New("dial tcp: i/o timeout"))
+		for i := 0; i < 3; i++ {
+			_, err := b.Resolve(ctx, did)
+			require.Error(t, err)
+			assert.NotErrorIs(t, err, ErrResolverUnavailable)
+		}
+		assert.Equal(t, BreakerOpen, b.State())
+
+		// Open: calls never reach the RPC
+		for i := 0; i < 5; i++ {
+			_, err := b.Resolve(ctx, did)
+			assert.Equal(t, ErrResolverUnavailable, err)
+		}
+		assert.Equal(t, int32(3), inner.calls.Load())
+
+		// Cooldown elapsed but endpoint still down: probe fails and re-opens
+		*now = now.Add(11 * time.Second)
+		_, err := b.Resolve(ctx, did)
+		require.Error(t, err)
+		assert.Equal(t, BreakerOpen, b.State())
+		assert.Equal(t, int32(4), inner.calls.Load())
+
+		_, err = b.Resolve(ctx, did)
+		assert.Equal(t, ErrResolverUnavailable, err)
+
+		// Endpoint healthy again: probe succeeds and closes the breaker
+		inner.setErr(nil)
+		*now = now.Add(11 * time.Second)
+		md, err := b.Resolve(ctx, did)
+		require.NoError(t, err)
+		assert.Equal(t, did, md.DID)
+		assert.Equal(t, BreakerClosed, b.State())
+
+		assert.Equal(t, []string{
+			"closed->open",
+			"open->half-open",
+			"half-open->open",
+			"open->half-open",
+			"half-open->closed",
+		}, *transitions)
+	})
+
+	t.Run("single probe while half-open", func(t *testing.T) {
+		inner := &flakyResolver{}
+		b, now, _ := newBreaker(inner)
+
+		inner.setErr(errors.New("connection refused"))
+		for i := 0; i < 3; i++ {
+			_, _ = b.Resolve(ctx, did)
+		}
+		*now = now.Add(11 * time.Second)
+
+		// Simulate an in-flight probe
+		probe, err := b.admit()
+		require.NoError(t, err)
+		require.True(t, probe)
+
+		_, err = b.Resolve(ctx, did)
+		assert.Equal(t, ErrResolverUnavailable, err)
+
+		b.record(true, false)
+		assert.Equal(t, BreakerClosed, b.State())
+	})
+
+	t.Run("domain errors do not trip the breaker", func(t *testing.T) {
+		inner := &flakyResolver{}
+		b, _, _ := newBreaker(inner)
+
+		inner.setErr(ErrDIDNotFound)
+		for i := 0; i < 10; i++ {
+			_, err := b.Resolve(ctx, did)
+			assert.Equal(t, ErrDIDNotFound, err)
+		}
+		inner.setErr(context.Canceled)
+		for i := 0; i < 10; i++ {
+			_, _ = b.Resolve(ctx, did)
+		}
+		assert.Equal(t, BreakerClosed, b.State())
+	})
+
+	t.Run("success resets the failure count", func(t *testing.T) {
+		inner := &flakyResolver{}
+		b, _, _ := newBreaker(inner)
+
+		for i := 0; i < 5; i++ {
+			inner.setErr(errors.New("timeout"))
+			_, _ = b.Resolve(ctx, did)
+			_, _ = b.Resolve(ctx, did)
+			inner.setErr(nil)
+			_, err := b.Resolve(ctx, did)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, BreakerClosed, b.State())
+	})
+}
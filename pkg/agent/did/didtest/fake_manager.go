@@ -0,0 +1,282 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+// Package didtest provides in-memory fakes of the did package for testing
+// code that embeds SAGE without a blockchain or RPC endpoint.
+package didtest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+)
+
+// FakeEpoch is the block time of the fake chain's genesis. Block n is mined
+// n seconds later, so results are the same on every run.
+var FakeEpoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// FakeManager is an in-memory stand-in for did.Manager's register, resolve,
+// update and deactivate operations. Every write mines one fake block, and
+// RegistrationResults are derived only from the request and the block
+// number, so tests can assert on them.
+//
+// Like the registry contract, Update and Deactivate must be signed by the
+// key the agent registered with. FakeManager satisfies core.DIDResolver and
+// is safe for concurrent use.
+type FakeManager struct {
+	mu     sync.RWMutex
+	agents map[did.AgentDID]*did.AgentMetadata
+	txs    map[string]*did.RegistrationResult
+	block  uint64
+	method string // DID method; empty means did.DefaultMethod
+}
+
+// NewFakeManager returns an empty FakeManager.
+func NewFakeManager() *FakeManager {
+	return &FakeManager{
+		agents: make(map[did.AgentDID]*did.AgentMetadata),
+		txs:    make(map[string]*did.RegistrationResult),
+	}
+}
+
+// SetMethod sets the DID method registered DIDs must use, like
+// did.Manager.SetMethod. The default is did.DefaultMethod.
+func (f *FakeManager) SetMethod(method string) error {
+	if err := did.ValidateMethod(method); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.method = method
+	return nil
+}
+
+// Method returns the DID method registered DIDs must use
+func (f *FakeManager) Method() string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.method == "" {
+		return did.DefaultMethod
+	}
+	return f.method
+}
+
+// RegisterAgent records req as a new active agent on chain. The first
+// X25519 entry of req.Keys becomes the agent's PublicKEMKey.
+func (f *FakeManager) RegisterAgent(ctx context.Context, chain did.Chain, req *did.RegistrationRequest) (*did.RegistrationResult, error) {
+	if req == nil || req.DID == "" || req.Name == "" || req.KeyPair == nil {
+		return nil, fmt.Errorf("registration request needs a DID, name and key pair")
+	}
+	didChain, _, err := did.ParseDIDWithMethod(req.DID, f.Method())
+	if err != nil {
+		return nil, err
+	}
+	if didChain != chain {
+		return nil, fmt.Errorf("DID %s is not on chain %s", req.DID, chain)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, exists := f.agents[req.DID]; exists {
+		return nil, did.ErrDIDAlreadyExists
+	}
+
+	at := f.mineLocked()
+	var owner common.Address
+	if pub, ok := req.KeyPair.PublicKey().(*ecdsa.PublicKey); ok {
+		owner = ethcrypto.PubkeyToAddress(*pub)
+	}
+	agentID := did.AgentID(req.DID, owner, big.NewInt(at.Unix()))
+	var kemKey interface{}
+	for _, k := range req.Keys {
+		if k.Type == did.KeyTypeX25519 {
+			kemKey = append([]byte(nil), k.KeyData...)
+			break
+		}
+	}
+
+	f.agents[req.DID] = &did.AgentMetadata{
+		DID:          req.DID,
+		Name:         req.Name,
+		Description:  req.Description,
+		Endpoint:     req.Endpoint,
+		PublicKey:    req.KeyPair.PublicKey(),
+		PublicKEMKey: kemKey,
+		Capabilities: copyCapabilities(req.Capabilities),
+		Owner:        owner.Hex(),
+		IsActive:     true,
+		CreatedAt:    at,
+		UpdatedAt:    at,
+	}
+	result := &did.RegistrationResult{
+		TransactionHash: f.txHashLocked("register", req.DID),
+		BlockNumber:     f.block,
+		Timestamp:       at,
+		AgentID:         "0x" + hex.EncodeToString(agentID[:]),
+		RegisteredAt:    at,
+	}
+	f.txs[result.TransactionHash] = result
+	cp := *result
+	return &cp, nil
+}
+
+// ResolveAgent returns a copy of the agent's metadata, including
+// deactivated agents.
+func (f *FakeManager) ResolveAgent(ctx context.Context, agentDID did.AgentDID) (*did.AgentMetadata, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	agent, ok := f.agents[agentDID]
+	if !ok {
+		return nil, did.ErrDIDNotFound
+	}
+	cp := *agent
+	cp.Capabilities = copyCapabilities(agent.Capabilities)
+	return &cp, nil
+}
+
+// ResolvePublicKey returns the agent's signing key. Deactivated agents fail
+// with did.ErrInactiveAgent.
+func (f *FakeManager) ResolvePublicKey(ctx context.Context, agentDID did.AgentDID) (interface{}, error) {
+	agent, err := f.ResolveAgent(ctx, agentDID)
+	if err != nil {
+		return nil, err
+	}
+	if !agent.IsActive {
+		return nil, did.ErrInactiveAgent
+	}
+	return agent.PublicKey, nil
+}
+
+// ResolvePublicKeys returns the agent's signing key as a verification key.
+func (f *FakeManager) ResolvePublicKeys(ctx context.Context, agentDID did.AgentDID) ([]did.VerificationKey, error) {
+	pub, err := f.ResolvePublicKey(ctx, agentDID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := did.NewVerificationKey(agentDID, pub)
+	if err != nil {
+		return nil, err
+	}
+	return []did.VerificationKey{key}, nil
+}
+
+// UpdateAgent applies the "name", "description", "endpoint" and
+// "capabilities" entries of updates, as the registry does.
+func (f *FakeManager) UpdateAgent(ctx context.Context, agentDID did.AgentDID, updates map[string]interface{}, keyPair crypto.KeyPair) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	agent, err := f.ownedLocked(agentDID, keyPair)
+	if err != nil {
+		return err
+	}
+
+	if name, ok := updates["name"].(string); ok {
+		agent.Name = name
+	}
+	if description, ok := updates["description"].(string); ok {
+		agent.Description = description
+	}
+	if endpoint, ok := updates["endpoint"].(string); ok {
+		agent.Endpoint = endpoint
+	}
+	if capabilities, ok := updates["capabilities"].(map[string]interface{}); ok {
+		agent.Capabilities = copyCapabilities(capabilities)
+	}
+	agent.UpdatedAt = f.mineLocked()
+	return nil
+}
+
+// DeactivateAgent marks the agent inactive.
+func (f *FakeManager) DeactivateAgent(ctx context.Context, agentDID did.AgentDID, keyPair crypto.KeyPair) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	agent, err := f.ownedLocked(agentDID, keyPair)
+	if err != nil {
+		return err
+	}
+	agent.IsActive = false
+	agent.UpdatedAt = f.mineLocked()
+	return nil
+}
+
+// GetRegistrationStatus returns the result of an earlier RegisterAgent by
+// its transaction hash.
+func (f *FakeManager) GetRegistrationStatus(ctx context.Context, chain did.Chain, txHash string) (*did.RegistrationResult, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	result, ok := f.txs[txHash]
+	if !ok {
+		return nil, fmt.Errorf("transaction %s not found", txHash)
+	}
+	cp := *result
+	return &cp, nil
+}
+
+// ownedLocked returns the active agent registered with keyPair's key.
+// Callers must hold f.mu.
+func (f *FakeManager) ownedLocked(agentDID did.AgentDID, keyPair crypto.KeyPair) (*did.AgentMetadata, error) {
+	agent, ok := f.agents[agentDID]
+	if !ok {
+		return nil, did.ErrDIDNotFound
+	}
+	if !agent.IsActive {
+		return nil, did.ErrInactiveAgent
+	}
+	if keyPair == nil {
+		return nil, did.ErrUnauthorized
+	}
+	if !keyPair.PublicKeyEqual(agent.PublicKey) {
+		return nil, did.ErrUnauthorized
+	}
+	return agent, nil
+}
+
+// mineLocked advances the fake chain by one block and returns its time.
+func (f *FakeManager) mineLocked() time.Time {
+	f.block++
+	return FakeEpoch.Add(time.Duration(f.block) * time.Second)
+}
+
+// txHashLocked derives the hash of the current block's transaction.
+// Callers must hold f.mu.
+func (f *FakeManager) txHashLocked(op string, agentDID did.AgentDID) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d", op, agentDID, f.block)))
+	return "0x" + hex.EncodeToString(sum[:])
+}
+
+func copyCapabilities(in map[string]interface{}) map[string]interface{} {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
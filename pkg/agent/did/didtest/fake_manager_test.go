@@ -0,0 +1,148 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package didtest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sage-x-project/sage/pkg/agent/core"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/did/didtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// FakeManager can stand in wherever a DID resolver is needed
+var _ core.DIDResolver = (*didtest.FakeManager)(nil)
+
+// TestFakeManager_RegisterAndResolve shows the intended use: register an
+// agent and resolve it back without any RPC endpoint.
+func TestFakeManager_RegisterAndResolve(t *testing.T) {
+	ctx := context.Background()
+	manager := didtest.NewFakeManager()
+
+	keyPair, err := keys.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+	agentDID := did.GenerateDID(did.ChainEthereum, "agent001")
+
+	result, err := manager.RegisterAgent(ctx, did.ChainEthereum, &did.RegistrationRequest{
+		DID:          agentDID,
+		Name:         "Test Agent",
+		Endpoint:     "https://agent.example.com",
+		Capabilities: map[string]interface{}{"chat": true},
+		KeyPair:      keyPair,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), result.BlockNumber)
+	assert.Equal(t, didtest.FakeEpoch.Add(time.Second), result.Timestamp)
+	assert.NotEmpty(t, result.TransactionHash)
+	assert.Len(t, result.AgentID, 66)
+
+	agent, err := manager.ResolveAgent(ctx, agentDID)
+	require.NoError(t, err)
+	assert.Equal(t, "Test Agent", agent.Name)
+	assert.Equal(t, "https://agent.example.com", agent.Endpoint)
+	assert.True(t, agent.IsActive)
+	assert.NotEqual(t, "0x0000000000000000000000000000000000000000", agent.Owner)
+
+	pub, err := manager.ResolvePublicKey(ctx, agentDID)
+	require.NoError(t, err)
+	assert.Equal(t, keyPair.PublicKey(), pub)
+
+	status, err := manager.GetRegistrationStatus(ctx, did.ChainEthereum, result.TransactionHash)
+	require.NoError(t, err)
+	assert.Equal(t, result, status)
+}
+
+func TestFakeManager_Deterministic(t *testing.T) {
+	ctx := context.Background()
+	keyPair, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+
+	register := func() *did.RegistrationResult {
+		result, err := didtest.NewFakeManager().RegisterAgent(ctx, did.ChainEthereum, &did.RegistrationRequest{
+			DID:     did.GenerateDID(did.ChainEthereum, "agent001"),
+			Name:    "Test Agent",
+			KeyPair: keyPair,
+		})
+		require.NoError(t, err)
+		return result
+	}
+	assert.Equal(t, register(), register())
+}
+
+func TestFakeManager_WritePath(t *testing.T) {
+	ctx := context.Background()
+	manager := didtest.NewFakeManager()
+	owner, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	other, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	agentDID := did.GenerateDID(did.ChainEthereum, "agent001")
+
+	req := &did.RegistrationRequest{DID: agentDID, Name: "Test Agent", KeyPair: owner}
+	_, err = manager.RegisterAgent(ctx, did.ChainEthereum, req)
+	require.NoError(t, err)
+
+	t.Run("Duplicate registration", func(t *testing.T) {
+		_, err := manager.RegisterAgent(ctx, did.ChainEthereum, req)
+		assert.ErrorIs(t, err, did.ErrDIDAlreadyExists)
+	})
+
+	t.Run("Wrong chain", func(t *testing.T) {
+		_, err := manager.RegisterAgent(ctx, did.ChainSolana, &did.RegistrationRequest{
+			DID: did.GenerateDID(did.ChainEthereum, "agent002"), Name: "Other", KeyPair: owner,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		err := manager.UpdateAgent(ctx, agentDID, map[string]interface{}{"name": "Stolen"}, other)
+		assert.ErrorIs(t, err, did.ErrUnauthorized)
+
+		require.NoError(t, manager.UpdateAgent(ctx, agentDID, map[string]interface{}{
+			"name":     "Renamed",
+			"endpoint": "https://new.example.com",
+		}, owner))
+		agent, err := manager.ResolveAgent(ctx, agentDID)
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", agent.Name)
+		assert.Equal(t, "https://new.example.com", agent.Endpoint)
+		assert.True(t, agent.UpdatedAt.After(agent.CreatedAt))
+	})
+
+	t.Run("Deactivate", func(t *testing.T) {
+		require.NoError(t, manager.DeactivateAgent(ctx, agentDID, owner))
+
+		agent, err := manager.ResolveAgent(ctx, agentDID)
+		require.NoError(t, err)
+		assert.False(t, agent.IsActive)
+		_, err = manager.ResolvePublicKey(ctx, agentDID)
+		assert.ErrorIs(t, err, did.ErrInactiveAgent)
+		assert.ErrorIs(t, manager.DeactivateAgent(ctx, agentDID, owner), did.ErrInactiveAgent)
+	})
+
+	t.Run("Unknown DID", func(t *testing.T) {
+		_, err := manager.ResolveAgent(ctx, did.GenerateDID(did.ChainEthereum, "missing"))
+		assert.ErrorIs(t, err, did.ErrDIDNotFound)
+	})
+}
@@ -22,6 +22,7 @@ import (
 	"crypto/rand"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -130,31 +131,17 @@ func BenchmarkCommitmentHashWithMultipleKeys(b *testing.B) {
 	}
 }
 
-// BenchmarkAgentIDComputation measures agent ID derivation from DID
+// BenchmarkAgentIDComputation measures agent ID derivation for event correlation
 func BenchmarkAgentIDComputation(b *testing.B) {
-	key, err := crypto.GenerateKey()
-	if err != nil {
-		b.Fatal(err)
-	}
-
-	config := &did.RegistryConfig{
-		RPCEndpoint:     "http://localhost:8545",
-		ContractAddress: "0x1234567890123456789012345678901234567890",
-		PrivateKey:      common.Bytes2Hex(crypto.FromECDSA(key)),
-	}
-
-	client, err := NewAgentCardClient(config)
-	if err != nil {
-		b.Fatal(err)
-	}
-
-	testDID := "did:sage:ethereum:benchmark-agent-id"
+	testDID := did.AgentDID("did:sage:ethereum:benchmark-agent-id")
+	owner := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	registeredAt := big.NewInt(1700000000)
 
 	b.ResetTimer()
 	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
-		_ = client.computeAgentID(testDID)
+		_ = did.AgentID(testDID, owner, registeredAt)
 	}
 }
 
@@ -22,6 +22,7 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -205,7 +206,7 @@ func (c *AgentCardClient) RegisterAgent(ctx context.Context, status *did.Commitm
 	}
 
 	// Extract agent ID from logs (TODO: parse AgentRegistered event)
-	agentID, regTs, err := c.extractRegisteredIDAndTs(receipt)
+	agentID, regTs, err := c.extractRegisteredIDAndTs(receipt, did.AgentDID(status.Params.DID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract agent ID: %w", err)
 	}
@@ -364,6 +365,66 @@ func (c *AgentCardClient) GetAgent(ctx context.Context, agentID [32]byte) (*did.
 	return agent, nil
 }
 
+// WatchKeyRevoked subscribes to on-chain KeyRevoked events and calls onRevoked
+// with the DID of the agent whose key was revoked. RevokeSessionsOnKeyRevoked
+// uses it to end the agent's sessions.
+// Blocks until ctx is cancelled, the subscription fails or the agent of a
+// revoked key cannot be looked up; requires a subscription-capable
+// (websocket/IPC) RPC endpoint. A failed lookup is returned rather than
+// skipped so the revocation is not silently lost: callers should restart
+// the watch and reconcile their sessions against the registry.
+func (c *AgentCardClient) WatchKeyRevoked(ctx context.Context, onRevoked func(did.AgentDID)) error {
+	if onRevoked == nil {
+		return fmt.Errorf("onRevoked callback is required")
+	}
+
+	sink := make(chan *agentcardregistry.AgentCardRegistryKeyRevoked, 16)
+	sub, err := c.contract.WatchKeyRevoked(&bind.WatchOpts{Context: ctx}, sink, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to watch KeyRevoked events: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-sink:
+			md, err := c.contract.GetAgent(&bind.CallOpts{Context: ctx}, ev.AgentId)
+			if err != nil {
+				return fmt.Errorf("failed to look up agent 0x%x of revoked key 0x%x: %w", ev.AgentId, ev.KeyHash, err)
+			}
+			if md.Did == "" {
+				continue // Unknown agent; nothing to revoke locally
+			}
+			onRevoked(did.AgentDID(md.Did))
+		case err := <-sub.Err():
+			if err != nil {
+				return fmt.Errorf("KeyRevoked subscription failed: %w", err)
+			}
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// SessionRevoker ends every session bound to a peer DID; *session.Manager
+// implements it.
+type SessionRevoker interface {
+	RevokeSessionsByDID(did string) int
+}
+
+// RevokeSessionsOnKeyRevoked runs WatchKeyRevoked and revokes the sessions
+// bound to each agent whose key is revoked on-chain. It returns when
+// WatchKeyRevoked does; see there for restarting after an error.
+func (c *AgentCardClient) RevokeSessionsOnKeyRevoked(ctx context.Context, sessions SessionRevoker) error {
+	if sessions == nil {
+		return fmt.Errorf("session revoker is required")
+	}
+	return c.WatchKeyRevoked(ctx, func(agent did.AgentDID) {
+		sessions.RevokeSessionsByDID(string(agent))
+	})
+}
+
 // GetAgentByDID retrieves agent metadata by DID string
 func (c *AgentCardClient) GetAgentByDID(ctx context.Context, didStr string) (*did.AgentMetadataV4, error) {
 	// 1) Call on-chain view
@@ -495,6 +556,95 @@ func (c *AgentCardClient) DeactivateAgent(ctx context.Context, agentID [32]byte)
 	return nil
 }
 
+// ResolvePublicKeys returns every verified Ed25519/ECDSA key currently bound
+// to the agent. Revoked keys are removed from the agent on-chain, so during a
+// rollover (AddKey, then RevokeKey) both keys are returned until revocation.
+func (c *AgentCardClient) ResolvePublicKeys(ctx context.Context, agentDID did.AgentDID) ([]did.VerificationKey, error) {
+	agent, err := c.GetAgentByDID(ctx, string(agentDID))
+	if err != nil {
+		return nil, err
+	}
+	if !agent.IsActive {
+		return nil, did.ErrInactiveAgent
+	}
+
+	keys := agent.VerificationKeys()
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("agent %s has no valid signing keys", agentDID)
+	}
+	return keys, nil
+}
+
+// AddKey binds an additional key to the agent and returns its key hash.
+// To roll a key over without downtime, add the new key first and revoke the
+// old one (RevokeKey) once peers have picked it up.
+func (c *AgentCardClient) AddKey(ctx context.Context, agentDID did.AgentDID, key did.AgentKey) (string, error) {
+	if len(key.KeyData) == 0 {
+		return "", fmt.Errorf("key data is required")
+	}
+
+	auth, err := c.getTransactor(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create transactor: %w", err)
+	}
+
+	agentID, err := c.AgentIDByDID(ctx, agentDID)
+	if err != nil {
+		return "", err
+	}
+	tx, err := c.contract.AddKey(auth, agentID, key.KeyData, uint8(key.Type), key.Signature)
+	if err != nil {
+		return "", fmt.Errorf("failed to add key: %w", err)
+	}
+
+	receipt, err := bind.WaitMined(ctx, c.client, tx)
+	if err != nil {
+		return "", fmt.Errorf("failed to wait for key addition: %w", err)
+	}
+
+	if receipt.Status != 1 {
+		return "", fmt.Errorf("key addition transaction failed")
+	}
+
+	// Must match Solidity: keccak256(keyData)
+	return crypto.Keccak256Hash(key.KeyData).Hex(), nil
+}
+
+// RevokeKey removes a key from the agent. The contract refuses to revoke the
+// agent's last key, so a replacement must be added first.
+func (c *AgentCardClient) RevokeKey(ctx context.Context, agentDID did.AgentDID, keyHash string) error {
+	raw, err := hex.DecodeString(strings.TrimPrefix(keyHash, "0x"))
+	if err != nil || len(raw) != 32 {
+		return fmt.Errorf("invalid key hash: %s", keyHash)
+	}
+
+	agentID, err := c.AgentIDByDID(ctx, agentDID)
+	if err != nil {
+		return err
+	}
+
+	auth, err := c.getTransactor(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create transactor: %w", err)
+	}
+
+	tx, err := c.contract.RevokeKey(auth, agentID, [32]byte(raw))
+	if err != nil {
+		return fmt.Errorf("failed to revoke key: %w", err)
+	}
+
+	receipt, err := bind.WaitMined(ctx, c.client, tx)
+	if err != nil {
+		return fmt.Errorf("failed to wait for key revocation: %w", err)
+	}
+
+	if receipt.Status != 1 {
+		return fmt.Errorf("key revocation transaction failed")
+	}
+
+	return nil
+}
+
 // GetKEMKey retrieves the KME (Key Management Encryption) public key for an agent
 // Returns the X25519 public key used for HPKE (RFC 9180) encryption
 func (c *AgentCardClient) GetKEMKey(ctx context.Context, agentID [32]byte) ([]byte, error) {
@@ -587,8 +737,18 @@ func (c *AgentCardClient) computeCommitmentHash(params *did.RegistrationParams)
 	return hash, nil
 }
 
-func (c *AgentCardClient) computeAgentID(didStr string) [32]byte {
-	return crypto.Keccak256Hash([]byte(didStr))
+// AgentIDByDID returns the registry's ID for agentDID. IDs are assigned at
+// registration from the owner and block time (see did.AgentID), so they are
+// read from the didToAgentId mapping rather than derived from the DID.
+func (c *AgentCardClient) AgentIDByDID(ctx context.Context, agentDID did.AgentDID) ([32]byte, error) {
+	agentID, err := c.contract.DidToAgentId(&bind.CallOpts{Context: ctx}, string(agentDID))
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("didToAgentId: %w", err)
+	}
+	if agentID == ([32]byte{}) {
+		return [32]byte{}, did.ErrDIDNotFound
+	}
+	return agentID, nil
 }
 
 func (c *AgentCardClient) toContractParams(params *did.RegistrationParams) (*agentcardregistry.AgentCardStorageRegistrationParams, error) {
@@ -648,15 +808,23 @@ func (c *AgentCardClient) getTransactor(ctx context.Context, value *big.Int) (*b
 	return auth, nil
 }
 
-func (c *AgentCardClient) extractRegisteredIDAndTs(receipt *types.Receipt) ([32]byte, *big.Int, error) {
+// extractRegisteredIDAndTs finds the AgentRegistered event for agentDID in
+// receipt. The event's agent ID must match did.AgentID for its owner and
+// timestamp, which guards against correlating the wrong registration.
+func (c *AgentCardClient) extractRegisteredIDAndTs(receipt *types.Receipt, agentDID did.AgentDID) ([32]byte, *big.Int, error) {
+	didTopic := crypto.Keccak256Hash([]byte(agentDID)) // indexed strings are logged as their hash
 	for _, lg := range receipt.Logs {
 		if lg.Address != c.contractAddress {
 			continue
 		}
 		ev, err := c.contract.ParseAgentRegistered(*lg) // event AgentRegistered(bytes32 agentId, string did, address owner, uint256 timestamp)
-		if err == nil {
-			return ev.AgentId, ev.Timestamp, nil
+		if err != nil || ev.Did != didTopic {
+			continue
+		}
+		if ev.AgentId != did.AgentID(agentDID, ev.Owner, ev.Timestamp) {
+			return [32]byte{}, nil, fmt.Errorf("AgentRegistered event has unexpected agent ID %x", ev.AgentId)
 		}
+		return ev.AgentId, ev.Timestamp, nil
 	}
 	return [32]byte{}, nil, fmt.Errorf("AgentRegistered event not found")
 }
@@ -0,0 +1,222 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	"github.com/sage-x-project/sage/pkg/blockchain/ethereum/contracts/agentcardregistry"
+)
+
+// revocationBackend serves AgentCardRegistry KeyRevoked subscriptions and
+// getAgent calls without a chain. Other backend methods are not used.
+type revocationBackend struct {
+	bind.ContractBackend
+
+	abi        abi.ABI
+	address    common.Address
+	agents     map[[32]byte]string // agent ID -> DID
+	callErr    error
+	subscribed chan chan<- types.Log
+	sub        *fakeSubscription
+}
+
+func newRevocationBackend(t *testing.T, agents map[[32]byte]string) *revocationBackend {
+	t.Helper()
+	parsed, err := agentcardregistry.AgentCardRegistryMetaData.GetAbi()
+	require.NoError(t, err)
+	return &revocationBackend{
+		abi:        *parsed,
+		address:    common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3"),
+		agents:     agents,
+		subscribed: make(chan chan<- types.Log, 1),
+		sub:        &fakeSubscription{err: make(chan error, 1)},
+	}
+}
+
+// client returns an AgentCardClient bound to the backend.
+func (b *revocationBackend) client(t *testing.T) *AgentCardClient {
+	t.Helper()
+	contract, err := agentcardregistry.NewAgentCardRegistry(b.address, b)
+	require.NoError(t, err)
+	return &AgentCardClient{contract: contract, contractAddress: b.address}
+}
+
+func (b *revocationBackend) SubscribeFilterLogs(_ context.Context, _ ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	b.subscribed <- ch
+	return b.sub, nil
+}
+
+func (b *revocationBackend) CodeAt(context.Context, common.Address, *big.Int) ([]byte, error) {
+	return []byte{0x00}, nil
+}
+
+func (b *revocationBackend) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	if b.callErr != nil {
+		return nil, b.callErr
+	}
+	method, err := b.abi.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	if method.Name != "getAgent" {
+		return nil, errors.New("unexpected call to " + method.Name)
+	}
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	return method.Outputs.Pack(agentcardregistry.AgentCardStorageAgentMetadata{
+		Did:          b.agents[args[0].([32]byte)],
+		RegisteredAt: new(big.Int),
+		UpdatedAt:    new(big.Int),
+		ChainId:      new(big.Int),
+	})
+}
+
+// keyRevokedLog ABI-encodes a KeyRevoked event as the contract would emit it.
+func (b *revocationBackend) keyRevokedLog(t *testing.T, agentID, keyHash [32]byte) types.Log {
+	t.Helper()
+	event := b.abi.Events["KeyRevoked"]
+	data, err := event.Inputs.NonIndexed().Pack(big.NewInt(time.Now().Unix()))
+	require.NoError(t, err)
+	return types.Log{
+		Address: b.address,
+		Topics:  []common.Hash{event.ID, agentID, keyHash},
+		Data:    data,
+	}
+}
+
+type fakeSubscription struct {
+	err  chan error
+	once sync.Once
+}
+
+func (s *fakeSubscription) Unsubscribe() { s.once.Do(func() { close(s.err) }) }
+
+func (s *fakeSubscription) Err() <-chan error { return s.err }
+
+func TestAgentCardClient_RevokeSessionsOnKeyRevoked(t *testing.T) {
+	alice := [32]byte{0xa1}
+	bob := [32]byte{0xb0}
+	unknown := [32]byte{0xff}
+	agents := map[[32]byte]string{
+		alice: "did:sage:ethereum:alice",
+		bob:   "did:sage:ethereum:bob",
+	}
+
+	// watch starts RevokeSessionsOnKeyRevoked and returns the subscription's
+	// log channel and the watcher's result.
+	watch := func(t *testing.T, b *revocationBackend, mgr *session.Manager) (context.CancelFunc, chan<- types.Log, <-chan error) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		done := make(chan error, 1)
+		go func() { done <- b.client(t).RevokeSessionsOnKeyRevoked(ctx, mgr) }()
+		select {
+		case logs := <-b.subscribed:
+			return cancel, logs, done
+		case err := <-done:
+			t.Fatalf("watch returned before subscribing: %v", err)
+		}
+		return nil, nil, nil
+	}
+	newSession := func(t *testing.T, mgr *session.Manager, id, peer string) {
+		_, err := mgr.CreateSession(id, []byte("shared secret for "+id))
+		require.NoError(t, err)
+		mgr.BindDID(peer, id)
+	}
+
+	t.Run("Revoked key ends the agent's sessions", func(t *testing.T) {
+		mgr := session.NewManager()
+		t.Cleanup(func() { _ = mgr.Close() })
+		newSession(t, mgr, "alice-1", agents[alice])
+		newSession(t, mgr, "alice-2", agents[alice])
+		newSession(t, mgr, "bob-1", agents[bob])
+
+		b := newRevocationBackend(t, agents)
+		cancel, logs, done := watch(t, b, mgr)
+		logs <- b.keyRevokedLog(t, unknown, [32]byte{0x01})
+		logs <- b.keyRevokedLog(t, alice, [32]byte{0x02})
+
+		require.Eventually(t, func() bool {
+			_, ok := mgr.GetSession("alice-2")
+			return !ok
+		}, time.Second, 5*time.Millisecond)
+		_, ok := mgr.GetSession("alice-1")
+		require.False(t, ok)
+		_, ok = mgr.GetSession("bob-1")
+		require.True(t, ok)
+
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+	})
+
+	t.Run("Lookup failure is returned", func(t *testing.T) {
+		mgr := session.NewManager()
+		t.Cleanup(func() { _ = mgr.Close() })
+		newSession(t, mgr, "alice-1", agents[alice])
+
+		b := newRevocationBackend(t, agents)
+		b.callErr = errors.New("rpc unavailable")
+		_, logs, done := watch(t, b, mgr)
+		logs <- b.keyRevokedLog(t, alice, [32]byte{0x02})
+
+		select {
+		case err := <-done:
+			require.ErrorContains(t, err, "failed to look up agent")
+			require.ErrorContains(t, err, "rpc unavailable")
+		case <-time.After(time.Second):
+			t.Fatal("watch did not return after a failed lookup")
+		}
+		_, ok := mgr.GetSession("alice-1")
+		require.True(t, ok)
+	})
+
+	t.Run("Subscription failure is returned", func(t *testing.T) {
+		mgr := session.NewManager()
+		t.Cleanup(func() { _ = mgr.Close() })
+		b := newRevocationBackend(t, agents)
+		_, _, done := watch(t, b, mgr)
+		b.sub.err <- errors.New("connection reset")
+
+		select {
+		case err := <-done:
+			require.ErrorContains(t, err, "KeyRevoked subscription failed")
+		case <-time.After(time.Second):
+			t.Fatal("watch did not return after the subscription failed")
+		}
+	})
+
+	t.Run("Revoker is required", func(t *testing.T) {
+		b := newRevocationBackend(t, agents)
+		require.Error(t, b.client(t).RevokeSessionsOnKeyRevoked(context.Background(), nil))
+	})
+}
@@ -52,20 +52,40 @@ import (
 //
 // Migration: Replace NewEthereumClient() calls with NewEthereumClientV4().
 type EthereumClient struct {
-	client          *ethclient.Client
+	client          Backend
 	contract        *bind.BoundContract
 	contractABI     abi.ABI
+	registerABI     abi.ABI
 	contractAddress common.Address
 	privateKey      *ecdsa.PrivateKey
 	chainID         *big.Int
 	config          *did.RegistryConfig
+	filterer        *RegistryV2Filterer
 }
 
-// init registers the Ethereum client creator with the factory
+// Backend is the chain access EthereumClient needs. Both *ethclient.Client and
+// go-ethereum's simulated backend client satisfy it.
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	ethereum.BlockNumberReader
+	ethereum.ChainReader
+	ethereum.ChainStateReader
+	ethereum.ChainIDReader
+}
+
+// init registers the Ethereum client creators with the factory
 func init() {
 	did.RegisterEthereumClientCreator(func(config *did.RegistryConfig) (did.Client, error) {
 		return NewEthereumClient(config)
 	})
+	did.RegisterEthereumBackendClientCreator(func(config *did.RegistryConfig, backend bind.ContractBackend) (interface{}, error) {
+		b, ok := backend.(Backend)
+		if !ok {
+			return nil, fmt.Errorf("contract backend %T lacks receipt, block and chain ID access", backend)
+		}
+		return NewEthereumClientWithBackend(config, b)
+	})
 }
 
 // NewEthereumClient creates a new Ethereum DID client for V2 contracts.
@@ -83,7 +103,30 @@ func NewEthereumClient(config *did.RegistryConfig) (*EthereumClient, error) {
 		return nil, fmt.Errorf("failed to get network ID: %w", err)
 	}
 
-	var privateKey *ecdsa.PrivateKey
+	return newEthereumClient(config, client, chainID)
+}
+
+// NewEthereumClientWithBackend creates a V2 Ethereum DID client on top of an
+// existing backend instead of dialing config.RPCEndpoint, e.g. a custom-tuned
+// *ethclient.Client or a simulated backend in tests.
+func NewEthereumClientWithBackend(config *did.RegistryConfig, backend Backend) (*EthereumClient, error) {
+	if backend == nil {
+		return nil, fmt.Errorf("backend is required")
+	}
+
+	chainID, err := backend.ChainID(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	return newEthereumClient(config, backend, chainID)
+}
+
+func newEthereumClient(config *did.RegistryConfig, client Backend, chainID *big.Int) (*EthereumClient, error) {
+	var (
+		privateKey *ecdsa.PrivateKey
+		err        error
+	)
 	if config.PrivateKey != "" {
 		privateKey, err = crypto.HexToECDSA(config.PrivateKey)
 		if err != nil {
@@ -99,21 +142,81 @@ func NewEthereumClient(config *did.RegistryConfig) (*EthereumClient, error) {
 		return nil, fmt.Errorf("failed to parse contract ABI: %w", err)
 	}
 
+	// registerAgent keeps the V2 signature, matching the AgentRegistered event
+	// decoded by the filterer
+	registerABI, err := abi.JSON(strings.NewReader(SageRegistryABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SageRegistryV2 ABI: %w", err)
+	}
+
 	contract := bind.NewBoundContract(contractAddress, contractABI, client, client, client)
 
+	filterer, err := NewRegistryV2Filterer(contractAddress)
+	if err != nil {
+		return nil, err
+	}
+
 	return &EthereumClient{
 		client:          client,
 		contract:        contract,
 		contractABI:     contractABI,
+		registerABI:     registerABI,
 		contractAddress: contractAddress,
 		privateKey:      privateKey,
 		chainID:         chainID,
 		config:          config,
+		filterer:        filterer,
 	}, nil
 }
 
 // Register registers a new agent on Ethereum
 func (c *EthereumClient) Register(ctx context.Context, req *did.RegistrationRequest) (*did.RegistrationResult, error) {
+	input, err := c.packRegistration(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare transaction options
+	auth, err := c.getTransactOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject conflicting registrations before spending gas
+	if err := c.checkDIDAvailable(ctx, req.DID, auth.From); err != nil {
+		return nil, err
+	}
+
+	// Call the contract
+	tx, err := c.contract.RawTransact(auth, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register agent: %w", err)
+	}
+
+	// Wait for transaction confirmation
+	receipt, err := c.waitForTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &did.RegistrationResult{
+		TransactionHash: tx.Hash().Hex(),
+		BlockNumber:     receipt.BlockNumber.Uint64(),
+		Timestamp:       time.Now(),
+		GasUsed:         receipt.GasUsed,
+	}
+
+	// Extract the agent ID so callers don't have to re-resolve by DID
+	if err := c.applyRegistrationEvent(result, receipt); err != nil {
+		return nil, fmt.Errorf("failed to extract agent ID: %w", err)
+	}
+
+	return result, nil
+}
+
+// packRegistration signs the registration with the agent key and returns the
+// registerAgent calldata, shared by direct and Safe-routed registration.
+func (c *EthereumClient) packRegistration(req *did.RegistrationRequest) ([]byte, error) {
 	// Validate key type first (before checking client initialization)
 	if req.KeyPair.Type() != sagecrypto.KeyTypeSecp256k1 {
 		return nil, fmt.Errorf("ethereum requires Secp256k1 keys")
@@ -164,14 +267,7 @@ func (c *EthereumClient) Register(ctx context.Context, req *did.RegistrationRequ
 		publicKeyBytes = prefixedKey
 	}
 
-	// Prepare transaction options
-	auth, err := c.getTransactOpts(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	// Call the contract
-	tx, err := c.contract.Transact(auth, "registerAgent",
+	input, err := c.registerABI.Pack("registerAgent",
 		string(req.DID),
 		req.Name,
 		req.Description,
@@ -181,21 +277,50 @@ func (c *EthereumClient) Register(ctx context.Context, req *did.RegistrationRequ
 		signature,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to register agent: %w", err)
+		return nil, fmt.Errorf("failed to pack registerAgent: %w", err)
+	}
+	return input, nil
+}
+
+// checkDIDAvailable looks the DID up with getAgentByDID and fails with
+// did.ErrDIDAlreadyRegistered when it is already registered to an owner other
+// than owner. Lookup failures are not fatal: the registry contract still
+// enforces uniqueness, this check only avoids sending a doomed transaction.
+func (c *EthereumClient) checkDIDAvailable(ctx context.Context, agentDID did.AgentDID, owner common.Address) error {
+	existing, err := c.Resolve(ctx, agentDID)
+	if err != nil || existing == nil || existing.Owner == "" {
+		return nil
+	}
+	if common.HexToAddress(existing.Owner) == owner {
+		return nil
+	}
+	return did.DIDError{
+		Code:    did.ErrDIDAlreadyRegistered.Code,
+		Message: fmt.Sprintf("DID %s already registered to %s", agentDID, existing.Owner),
+		Details: map[string]interface{}{
+			"did":   string(agentDID),
+			"owner": existing.Owner,
+		},
 	}
+}
 
-	// Wait for transaction confirmation
-	receipt, err := c.waitForTransaction(ctx, tx)
+// applyRegistrationEvent populates AgentID and RegisteredAt from the
+// AgentRegistered event in receipt.
+func (c *EthereumClient) applyRegistrationEvent(result *did.RegistrationResult, receipt *types.Receipt) error {
+	if c.filterer == nil {
+		return fmt.Errorf("event filterer not initialized")
+	}
+	event, err := c.filterer.FindAgentRegistered(receipt)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	return &did.RegistrationResult{
-		TransactionHash: tx.Hash().Hex(),
-		BlockNumber:     receipt.BlockNumber.Uint64(),
-		Timestamp:       time.Now(),
-		GasUsed:         receipt.GasUsed,
-	}, nil
+	ts, err := registeredAt(event.Timestamp)
+	if err != nil {
+		return err
+	}
+	result.AgentID = common.Hash(event.AgentId).Hex()
+	result.RegisteredAt = ts
+	return nil
 }
 
 // Resolve retrieves agent metadata from Ethereum
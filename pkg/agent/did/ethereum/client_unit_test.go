@@ -498,6 +498,10 @@ func (m *mockKeyPairForType) ID() string {
 	return "mock-key-id"
 }
 
+func (m *mockKeyPairForType) PublicKeyEqual(other crypto.PublicKey) bool {
+	return false
+}
+
 func mustGenerateKey(t *testing.T) *ecdsa.PrivateKey {
 	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	require.NoError(t, err)
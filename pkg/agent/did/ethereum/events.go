@@ -0,0 +1,94 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// RegistryV2AgentRegistered represents an AgentRegistered event raised by the
+// SageRegistryV2 contract.
+type RegistryV2AgentRegistered struct {
+	AgentId   [32]byte
+	Owner     common.Address
+	Did       string
+	Timestamp *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// RegistryV2Filterer decodes SageRegistryV2 event logs.
+type RegistryV2Filterer struct {
+	address  common.Address
+	contract *bind.BoundContract
+	abi      abi.ABI
+}
+
+// NewRegistryV2Filterer creates a log decoder for the SageRegistryV2 contract
+// deployed at address.
+func NewRegistryV2Filterer(address common.Address) (*RegistryV2Filterer, error) {
+	parsed, err := abi.JSON(strings.NewReader(SageRegistryABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SageRegistryV2 ABI: %w", err)
+	}
+	return &RegistryV2Filterer{
+		address:  address,
+		contract: bind.NewBoundContract(address, parsed, nil, nil, nil),
+		abi:      parsed,
+	}, nil
+}
+
+// ParseAgentRegistered is a log parse operation binding the contract event
+// AgentRegistered(bytes32 indexed agentId, address indexed owner, string did, uint256 timestamp).
+func (f *RegistryV2Filterer) ParseAgentRegistered(log types.Log) (*RegistryV2AgentRegistered, error) {
+	event := new(RegistryV2AgentRegistered)
+	if err := f.contract.UnpackLog(event, "AgentRegistered", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// FindAgentRegistered returns the first AgentRegistered event emitted by the
+// filterer's contract in receipt. Logs from other contracts are ignored.
+func (f *RegistryV2Filterer) FindAgentRegistered(receipt *types.Receipt) (*RegistryV2AgentRegistered, error) {
+	eventID := f.abi.Events["AgentRegistered"].ID
+	for _, lg := range receipt.Logs {
+		if lg == nil || lg.Address != f.address || len(lg.Topics) == 0 || lg.Topics[0] != eventID {
+			continue
+		}
+		return f.ParseAgentRegistered(*lg)
+	}
+	return nil, fmt.Errorf("AgentRegistered event not found")
+}
+
+// registeredAt converts an on-chain registration timestamp to time.Time.
+func registeredAt(ts *big.Int) (time.Time, error) {
+	if ts == nil || !ts.IsInt64() {
+		return time.Time{}, fmt.Errorf("invalid registration timestamp: %v", ts)
+	}
+	return time.Unix(ts.Int64(), 0), nil
+}
@@ -0,0 +1,100 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package ethereum
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// simulatedAgentRegisteredLog ABI-encodes an AgentRegistered event as the
+// SageRegistryV2 contract would emit it.
+func simulatedAgentRegisteredLog(t *testing.T, contract common.Address, agentID common.Hash, owner common.Address, agentDID string, ts int64) *types.Log {
+	t.Helper()
+
+	parsed, err := abi.JSON(strings.NewReader(SageRegistryABI))
+	require.NoError(t, err)
+	event := parsed.Events["AgentRegistered"]
+
+	data, err := event.Inputs.NonIndexed().Pack(agentDID, big.NewInt(ts))
+	require.NoError(t, err)
+
+	return &types.Log{
+		Address: contract,
+		Topics: []common.Hash{
+			event.ID,
+			agentID,
+			common.BytesToHash(owner.Bytes()),
+		},
+		Data: data,
+	}
+}
+
+func TestRegistryV2Filterer_FindAgentRegistered(t *testing.T) {
+	contract := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	owner := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	agentID := crypto.Keccak256Hash([]byte("did:sage:ethereum:agent001"))
+	registered := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	filterer, err := NewRegistryV2Filterer(contract)
+	require.NoError(t, err)
+
+	receipt := &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		BlockNumber: big.NewInt(42),
+		Logs: []*types.Log{
+			// Same event from an unrelated contract must be ignored
+			simulatedAgentRegisteredLog(t, common.HexToAddress("0x01"), common.Hash{0x01}, owner, "did:sage:ethereum:other", 1),
+			simulatedAgentRegisteredLog(t, contract, agentID, owner, "did:sage:ethereum:agent001", registered.Unix()),
+		},
+	}
+
+	t.Run("Decode event", func(t *testing.T) {
+		event, err := filterer.FindAgentRegistered(receipt)
+		require.NoError(t, err)
+		assert.Equal(t, [32]byte(agentID), event.AgentId)
+		assert.Equal(t, owner, event.Owner)
+		assert.Equal(t, "did:sage:ethereum:agent001", event.Did)
+		assert.Equal(t, registered.Unix(), event.Timestamp.Int64())
+	})
+
+	t.Run("Populate registration result", func(t *testing.T) {
+		client := &EthereumClient{contractAddress: contract, filterer: filterer}
+		result := &did.RegistrationResult{}
+
+		require.NoError(t, client.applyRegistrationEvent(result, receipt))
+		assert.Equal(t, agentID.Hex(), result.AgentID)
+		assert.True(t, registered.Equal(result.RegisteredAt))
+	})
+
+	t.Run("Missing event", func(t *testing.T) {
+		_, err := filterer.FindAgentRegistered(&types.Receipt{Logs: receipt.Logs[:1]})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,431 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+package ethereum
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/sage-x-project/sage/internal/cryptoinit" // Initialize crypto wrappers
+	"github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+)
+
+// autoMineClient commits a block after every transaction so calls that wait
+// for receipts return immediately on the simulated chain.
+type autoMineClient struct {
+	simulated.Client
+	backend *simulated.Backend
+}
+
+func (c *autoMineClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := c.Client.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	c.backend.Commit()
+	return nil
+}
+
+// evmAsm is a minimal EVM assembler with PUSH2 label references. Data segments
+// are appended after the code and referenced by label like jump targets.
+type evmAsm struct {
+	code   []byte
+	labels map[string]int
+	fixups map[int]string
+	data   []struct {
+		label string
+		blob  []byte
+	}
+}
+
+func newEVMAsm() *evmAsm {
+	return &evmAsm{labels: map[string]int{}, fixups: map[int]string{}}
+}
+
+func (a *evmAsm) op(b ...byte) { a.code = append(a.code, b...) }
+
+func (a *evmAsm) push2(label string) {
+	a.op(0x61, 0, 0)
+	a.fixups[len(a.code)-2] = label
+}
+
+func (a *evmAsm) push32(h common.Hash) { a.op(append([]byte{0x7f}, h[:]...)...) }
+
+func (a *evmAsm) jumpdest(label string) {
+	a.labels[label] = len(a.code)
+	a.op(0x5b)
+}
+
+// copyData copies a data segment to memory offset 0.
+func (a *evmAsm) copyData(label string, blob []byte) {
+	a.data = append(a.data, struct {
+		label string
+		blob  []byte
+	}{label, blob})
+	a.op(0x61, byte(len(blob)>>8), byte(len(blob))) // size
+	a.push2(label)                                  // code offset
+	a.op(0x60, 0x00, 0x39)                          // dest=0, CODECOPY
+}
+
+// returnData returns a data segment as call output.
+func (a *evmAsm) returnData(label string, blob []byte) {
+	a.copyData(label, blob)
+	a.op(0x61, byte(len(blob)>>8), byte(len(blob)), 0x60, 0x00, 0xf3) // RETURN(0, size)
+}
+
+func (a *evmAsm) assemble(t *testing.T) []byte {
+	t.Helper()
+	out := append([]byte(nil), a.code...)
+	for _, d := range a.data {
+		a.labels[d.label] = len(out)
+		out = append(out, d.blob...)
+	}
+	for pos, label := range a.fixups {
+		target, ok := a.labels[label]
+		require.True(t, ok, "undefined label %s", label)
+		out[pos], out[pos+1] = byte(target>>8), byte(target)
+	}
+	return out
+}
+
+// stubRegistry describes the canned registry state served by the stub contract.
+type stubRegistry struct {
+	did          string
+	name         string
+	description  string
+	endpoint     string
+	capabilities string
+	owner        common.Address
+	agentKey     []byte // 65-byte uncompressed secp256k1
+	kemKey       []byte
+	registeredAt int64
+}
+
+// deployCode builds creation code for a contract that stands in for the
+// registry: registerAgent (V2 signature) flips a storage flag and emits the
+// V2 AgentRegistered event, getAgentByDID returns an empty record until then
+// and the canned record afterwards, and getKey returns the agent's key.
+func (s *stubRegistry) deployCode(t *testing.T) []byte {
+	t.Helper()
+
+	cardABI, err := abi.JSON(strings.NewReader(AgentCardRegistryABI))
+	require.NoError(t, err)
+	v2ABI, err := abi.JSON(strings.NewReader(SageRegistryABI))
+	require.NoError(t, err)
+
+	type agentRecord struct {
+		Did          string         `abi:"did"`
+		Name         string         `abi:"name"`
+		Description  string         `abi:"description"`
+		Endpoint     string         `abi:"endpoint"`
+		KeyHashes    [][32]byte     `abi:"keyHashes"`
+		Capabilities string         `abi:"capabilities"`
+		Owner        common.Address `abi:"owner"`
+		RegisteredAt *big.Int       `abi:"registeredAt"`
+		UpdatedAt    *big.Int       `abi:"updatedAt"`
+		Active       bool           `abi:"active"`
+		ChainId      *big.Int       `abi:"chainId"`
+		KemPublicKey []byte         `abi:"kemPublicKey"`
+	}
+	type agentKey struct {
+		KeyType      uint8    `abi:"keyType"`
+		KeyData      []byte   `abi:"keyData"`
+		Signature    []byte   `abi:"signature"`
+		Verified     bool     `abi:"verified"`
+		RegisteredAt *big.Int `abi:"registeredAt"`
+	}
+
+	ts := big.NewInt(s.registeredAt)
+	found, err := cardABI.Methods["getAgentByDID"].Outputs.Pack(agentRecord{
+		Did:          s.did,
+		Name:         s.name,
+		Description:  s.description,
+		Endpoint:     s.endpoint,
+		KeyHashes:    [][32]byte{ethcrypto.Keccak256Hash(s.agentKey)},
+		Capabilities: s.capabilities,
+		Owner:        s.owner,
+		RegisteredAt: ts,
+		UpdatedAt:    ts,
+		Active:       true,
+		ChainId:      big.NewInt(1337),
+		KemPublicKey: s.kemKey,
+	})
+	require.NoError(t, err)
+	missing, err := cardABI.Methods["getAgentByDID"].Outputs.Pack(agentRecord{
+		KeyHashes:    [][32]byte{},
+		RegisteredAt: new(big.Int),
+		UpdatedAt:    new(big.Int),
+		ChainId:      new(big.Int),
+	})
+	require.NoError(t, err)
+	key, err := cardABI.Methods["getKey"].Outputs.Pack(agentKey{
+		KeyData:      s.agentKey,
+		Signature:    []byte{},
+		Verified:     true,
+		RegisteredAt: ts,
+	})
+	require.NoError(t, err)
+
+	event := v2ABI.Events["AgentRegistered"]
+	logData, err := event.Inputs.NonIndexed().Pack(s.did, ts)
+	require.NoError(t, err)
+
+	selector := func(m abi.Method) []byte { return m.ID }
+
+	rt := newEVMAsm()
+	rt.op(0x60, 0x00, 0x35, 0x60, 0xe0, 0x1c) // selector = calldata[0:4]
+	for _, entry := range []struct {
+		sel   []byte
+		label string
+	}{
+		{selector(v2ABI.Methods["registerAgent"]), "register"},
+		{selector(cardABI.Methods["getAgentByDID"]), "get"},
+		{selector(cardABI.Methods["getKey"]), "key"},
+	} {
+		rt.op(0x80, 0x63) // DUP1, PUSH4
+		rt.op(entry.sel...)
+		rt.op(0x14) // EQ
+		rt.push2(entry.label)
+		rt.op(0x57) // JUMPI
+	}
+	rt.op(0x60, 0x00, 0x80, 0xfd) // REVERT(0, 0)
+
+	rt.jumpdest("register")
+	rt.op(0x60, 0x01, 0x60, 0x00, 0x55) // SSTORE(0, 1)
+	rt.copyData("log", logData)
+	rt.push32(common.BytesToHash(s.owner.Bytes()))
+	rt.push32(ethcrypto.Keccak256Hash([]byte(s.did)))
+	rt.push32(event.ID)
+	rt.op(0x61, byte(len(logData)>>8), byte(len(logData)), 0x60, 0x00, 0xa3) // LOG3(0, size, ...)
+	rt.op(0x00)                                                              // STOP
+
+	rt.jumpdest("get")
+	rt.op(0x60, 0x00, 0x54) // SLOAD(0)
+	rt.push2("found")
+	rt.op(0x57)
+	rt.returnData("missing", missing)
+	rt.jumpdest("found")
+	rt.returnData("record", found)
+
+	rt.jumpdest("key")
+	rt.returnData("keydata", key)
+
+	return creationCode(rt.assemble(t))
+}
+
+// creationCode wraps runtime bytecode in init code that deploys it:
+// CODECOPY(0, len(init), len(runtime)); RETURN(0, len(runtime)).
+func creationCode(runtime []byte) []byte {
+	n := len(runtime)
+	init := []byte{0x61, byte(n >> 8), byte(n), 0x80, 0x60, 14, 0x60, 0x00, 0x39, 0x60, 0x00, 0xf3}
+	init = append(init, make([]byte, 14-len(init))...)
+	return append(init, runtime...)
+}
+
+func TestManager_ConfigureWithBackend_Simulated(t *testing.T) {
+	payer, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	payerAddr := ethcrypto.PubkeyToAddress(payer.PublicKey)
+
+	sim := simulated.NewBackend(types.GenesisAlloc{
+		payerAddr: {Balance: new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))},
+	})
+	t.Cleanup(func() { _ = sim.Close() })
+	backend := &autoMineClient{Client: sim.Client(), backend: sim}
+
+	agentKeyPair, err := crypto.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+	agentPub, ok := agentKeyPair.PublicKey().(*ecdsa.PublicKey)
+	require.True(t, ok)
+
+	stub := &stubRegistry{
+		did:          "did:sage:ethereum:sim-agent",
+		name:         "Simulated Agent",
+		description:  "registered on a simulated chain",
+		endpoint:     "https://agent.example.com",
+		capabilities: `{"chat":true}`,
+		owner:        payerAddr,
+		agentKey:     ethcrypto.FromECDSAPub(agentPub),
+		kemKey:       make([]byte, 32),
+		registeredAt: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC).Unix(),
+	}
+
+	ctx := context.Background()
+	chainID, err := backend.ChainID(ctx)
+	require.NoError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(payer, chainID)
+	require.NoError(t, err)
+	contractAddr, _, _, err := bind.DeployContract(auth, abi.ABI{}, stub.deployCode(t), backend)
+	require.NoError(t, err)
+
+	manager := did.NewManager()
+	config := &did.RegistryConfig{
+		ContractAddress: contractAddr.Hex(),
+		PrivateKey:      hex.EncodeToString(ethcrypto.FromECDSA(payer)),
+		MaxRetries:      3,
+	}
+	require.NoError(t, manager.ConfigureWithBackend(did.ChainEthereum, backend, config))
+	assert.True(t, manager.IsChainConfigured(did.ChainEthereum))
+
+	agentDID := did.AgentDID(stub.did)
+
+	t.Run("Not found before registration", func(t *testing.T) {
+		_, err := manager.ResolveAgent(ctx, agentDID)
+		assert.Equal(t, did.ErrDIDNotFound, err)
+	})
+
+	t.Run("Register", func(t *testing.T) {
+		var caps map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(stub.capabilities), &caps))
+
+		result, err := manager.RegisterAgent(ctx, did.ChainEthereum, &did.RegistrationRequest{
+			DID:          agentDID,
+			Name:         stub.name,
+			Description:  stub.description,
+			Endpoint:     stub.endpoint,
+			Capabilities: caps,
+			KeyPair:      agentKeyPair,
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, result.TransactionHash)
+		assert.NotZero(t, result.BlockNumber)
+		assert.NotZero(t, result.GasUsed)
+		assert.Equal(t, ethcrypto.Keccak256Hash([]byte(stub.did)).Hex(), result.AgentID)
+		assert.Equal(t, stub.registeredAt, result.RegisteredAt.Unix())
+	})
+
+	t.Run("Resolve after registration", func(t *testing.T) {
+		metadata, err := manager.ResolveAgent(ctx, agentDID)
+		require.NoError(t, err)
+		assert.Equal(t, agentDID, metadata.DID)
+		assert.Equal(t, stub.name, metadata.Name)
+		assert.Equal(t, stub.endpoint, metadata.Endpoint)
+		assert.Equal(t, payerAddr.Hex(), metadata.Owner)
+		assert.True(t, metadata.IsActive)
+		assert.Equal(t, true, metadata.Capabilities["chat"])
+
+		pub, ok := metadata.PublicKey.(*ecdsa.PublicKey)
+		require.True(t, ok, "expected *ecdsa.PublicKey, got %T", metadata.PublicKey)
+		assert.Equal(t, ethcrypto.PubkeyToAddress(*agentPub), ethcrypto.PubkeyToAddress(*pub))
+	})
+}
+
+func TestManager_ConfigureWithBackend_Validation(t *testing.T) {
+	sim := simulated.NewBackend(types.GenesisAlloc{})
+	t.Cleanup(func() { _ = sim.Close() })
+
+	manager := did.NewManager()
+	config := &did.RegistryConfig{ContractAddress: "0x5FbDB2315678afecb367f032d93F642f64180aa3"}
+
+	assert.Error(t, manager.ConfigureWithBackend(did.ChainEthereum, nil, config))
+	assert.Error(t, manager.ConfigureWithBackend(did.ChainEthereum, sim.Client(), &did.RegistryConfig{}))
+	assert.Error(t, manager.ConfigureWithBackend(did.ChainSolana, sim.Client(), config))
+	assert.False(t, manager.IsChainConfigured(did.ChainEthereum))
+
+	// RPCEndpoint is not needed when the backend is injected
+	require.NoError(t, manager.ConfigureWithBackend(did.ChainEthereum, sim.Client(), config))
+	assert.True(t, manager.IsChainConfigured(did.ChainEthereum))
+}
+
+func TestManager_RegisterAgent_RejectsDuplicateDID(t *testing.T) {
+	payer, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	payerAddr := ethcrypto.PubkeyToAddress(payer.PublicKey)
+
+	sim := simulated.NewBackend(types.GenesisAlloc{
+		payerAddr: {Balance: new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))},
+	})
+	t.Cleanup(func() { _ = sim.Close() })
+	backend := &autoMineClient{Client: sim.Client(), backend: sim}
+
+	agentKeyPair, err := crypto.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+	agentPub, ok := agentKeyPair.PublicKey().(*ecdsa.PublicKey)
+	require.True(t, ok)
+
+	// The stub reports the DID as owned by another account once registered
+	rival := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	stub := &stubRegistry{
+		did:          "did:sage:ethereum:contested-agent",
+		name:         "Contested Agent",
+		endpoint:     "https://agent.example.com",
+		capabilities: `{}`,
+		owner:        rival,
+		agentKey:     ethcrypto.FromECDSAPub(agentPub),
+		kemKey:       make([]byte, 32),
+		registeredAt: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC).Unix(),
+	}
+
+	ctx := context.Background()
+	chainID, err := backend.ChainID(ctx)
+	require.NoError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(payer, chainID)
+	require.NoError(t, err)
+	contractAddr, _, _, err := bind.DeployContract(auth, abi.ABI{}, stub.deployCode(t), backend)
+	require.NoError(t, err)
+
+	manager := did.NewManager()
+	require.NoError(t, manager.ConfigureWithBackend(did.ChainEthereum, backend, &did.RegistryConfig{
+		ContractAddress: contractAddr.Hex(),
+		PrivateKey:      hex.EncodeToString(ethcrypto.FromECDSA(payer)),
+		MaxRetries:      3,
+	}))
+
+	newRequest := func() *did.RegistrationRequest {
+		return &did.RegistrationRequest{
+			DID:      did.AgentDID(stub.did),
+			Name:     stub.name,
+			Endpoint: stub.endpoint,
+			KeyPair:  agentKeyPair,
+		}
+	}
+
+	_, err = manager.RegisterAgent(ctx, did.ChainEthereum, newRequest())
+	require.NoError(t, err)
+
+	nonceBefore, err := backend.NonceAt(ctx, payerAddr, nil)
+	require.NoError(t, err)
+
+	_, err = manager.RegisterAgent(ctx, did.ChainEthereum, newRequest())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, did.ErrDIDAlreadyRegistered)
+
+	var didErr did.DIDError
+	require.ErrorAs(t, err, &didErr)
+	assert.Equal(t, rival.Hex(), didErr.Details["owner"])
+	assert.Equal(t, stub.did, didErr.Details["did"])
+
+	nonceAfter, err := backend.NonceAt(ctx, payerAddr, nil)
+	require.NoError(t, err)
+	assert.Equal(t, nonceBefore, nonceAfter, "no transaction should be sent for a conflicting DID")
+}
@@ -205,12 +205,18 @@ func (c *EthereumClient) GetRegistrationStatus(ctx context.Context, txHash strin
 		return nil, fmt.Errorf("block timestamp overflow: %d exceeds maximum int64 value", blockTime)
 	}
 
-	return &did.RegistrationResult{
+	result := &did.RegistrationResult{
 		TransactionHash: txHash,
 		BlockNumber:     receipt.BlockNumber.Uint64(),
 		Timestamp:       time.Unix(int64(blockTime), 0), // #nosec G115 - overflow checked above
 		GasUsed:         receipt.GasUsed,
-	}, nil
+	}
+
+	// Not every transaction is a registration; leave AgentID empty when the
+	// receipt carries no AgentRegistered event.
+	_ = c.applyRegistrationEvent(result, receipt)
+
+	return result, nil
 }
 
 // Helper function to compare capabilities
@@ -243,7 +249,7 @@ type DIDDocument struct {
 // ParsedDID represents a parsed DID
 type ParsedDID struct {
 	Scheme  string // "did"
-	Method  string // "sage"
+	Method  string // "sage" unless the resolver was given another method
 	Network string // "ethereum"
 	Address string // Ethereum address
 }
@@ -252,6 +258,7 @@ type ParsedDID struct {
 type Resolver struct {
 	cache    *DIDCache
 	useCache bool
+	method   string // DID method; empty means did.DefaultMethod
 }
 
 // DIDCache provides caching for DID resolution
@@ -286,9 +293,28 @@ func NewResolverWithCache(maxItems int, ttl time.Duration) *Resolver {
 	}
 }
 
+// SetMethod sets the DID method this resolver accepts, for private
+// registries that issue e.g. did:sagecorp:ethereum:0x... identifiers. The
+// default is did.DefaultMethod. Call it before the resolver is shared.
+func (r *Resolver) SetMethod(method string) error {
+	if err := did.ValidateMethod(method); err != nil {
+		return err
+	}
+	r.method = method
+	return nil
+}
+
+// Method returns the DID method this resolver accepts
+func (r *Resolver) Method() string {
+	if r.method == "" {
+		return did.DefaultMethod
+	}
+	return r.method
+}
+
 // ParseDID parses a DID string into its components
 func (r *Resolver) ParseDID(did string) (*ParsedDID, error) {
-	// DID format: did:sage:ethereum:0x...
+	// DID format: did:<method>:ethereum:0x...
 	parts := strings.Split(did, ":")
 	if len(parts) != 4 {
 		return nil, fmt.Errorf("invalid DID format: expected 4 parts, got %d", len(parts))
@@ -298,8 +324,8 @@ func (r *Resolver) ParseDID(did string) (*ParsedDID, error) {
 		return nil, fmt.Errorf("invalid DID: scheme must be 'did'")
 	}
 
-	if parts[1] != "sage" {
-		return nil, fmt.Errorf("invalid DID: method must be 'sage'")
+	if parts[1] != r.Method() {
+		return nil, fmt.Errorf("invalid DID: method must be '%s'", r.Method())
 	}
 
 	if parts[2] != "ethereum" && parts[2] != "eth" {
@@ -181,6 +181,21 @@ func TestParseDID(t *testing.T) {
 	}
 }
 
+func TestParseDID_CustomMethod(t *testing.T) {
+	const address = "0x742d35Cc6634C0532925a3b844Bc9e7595f0aEbb"
+	resolver := NewResolver()
+	assert.Error(t, resolver.SetMethod("Sage-Corp"))
+	require.NoError(t, resolver.SetMethod("sagecorp"))
+
+	result, err := resolver.ParseDID("did:sagecorp:ethereum:" + address)
+	require.NoError(t, err)
+	assert.Equal(t, "sagecorp", result.Method)
+	assert.Equal(t, address, result.Address)
+
+	_, err = resolver.ParseDID("did:sage:ethereum:" + address)
+	assert.Error(t, err)
+}
+
 // TestCompareCapabilitiesEdgeCases tests additional edge cases for capability comparison
 func TestCompareCapabilitiesEdgeCases(t *testing.T) {
 	tests := []struct {
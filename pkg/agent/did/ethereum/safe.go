@@ -0,0 +1,188 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+)
+
+// safeABI covers the Safe (v1.3+) view functions used to prepare and verify
+// Safe-routed registrations.
+const safeABI = `[
+	{"type":"function","name":"nonce","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"isOwner","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"bool"}]}
+]`
+
+var (
+	// safeDomainTypeHash is keccak256("EIP712Domain(uint256 chainId,address verifyingContract)")
+	safeDomainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(uint256 chainId,address verifyingContract)"))
+	// safeTxTypeHash is the EIP-712 type hash of a Safe transaction
+	safeTxTypeHash = crypto.Keccak256Hash([]byte("SafeTx(address to,uint256 value,bytes data,uint8 operation,uint256 safeTxGas,uint256 baseGas,uint256 gasPrice,address gasToken,address refundReceiver,uint256 nonce)"))
+
+	parsedSafeABI = mustParseABI(safeABI)
+)
+
+func mustParseABI(def string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(def))
+	if err != nil {
+		panic(fmt.Sprintf("invalid ABI: %v", err))
+	}
+	return parsed
+}
+
+// PrepareSafeRegistration builds a registerAgent transaction for the Safe at
+// safeAddress to execute. The registration is still signed by req.KeyPair;
+// only msg.sender, and therefore the recorded owner, becomes the Safe.
+func (c *EthereumClient) PrepareSafeRegistration(ctx context.Context, req *did.RegistrationRequest, safeAddress string) (*did.SafeTransaction, error) {
+	if !common.IsHexAddress(safeAddress) {
+		return nil, fmt.Errorf("invalid Safe address: %s", safeAddress)
+	}
+	safe := common.HexToAddress(safeAddress)
+
+	code, err := c.client.CodeAt(ctx, safe, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Safe code: %w", err)
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("no contract deployed at Safe address %s", safe.Hex())
+	}
+
+	input, err := c.packRegistration(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.checkDIDAvailable(ctx, req.DID, safe); err != nil {
+		return nil, err
+	}
+
+	nonce, err := c.safeNonce(ctx, safe)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &did.SafeTransaction{
+		Chain:          did.ChainEthereum,
+		ChainID:        new(big.Int).Set(c.chainID),
+		Safe:           safe.Hex(),
+		To:             c.contractAddress.Hex(),
+		Value:          new(big.Int),
+		Data:           input,
+		Operation:      did.SafeOperationCall,
+		SafeTxGas:      new(big.Int),
+		BaseGas:        new(big.Int),
+		GasPrice:       new(big.Int),
+		GasToken:       common.Address{}.Hex(),
+		RefundReceiver: common.Address{}.Hex(),
+		Nonce:          nonce,
+	}
+	tx.SafeTxHash = safeTxHash(tx).Hex()
+	return tx, nil
+}
+
+// IsSafeOwner reports whether account is an owner of the Safe at safeAddress.
+// Accounts without code and contracts that do not answer isOwner are not Safes.
+func (c *EthereumClient) IsSafeOwner(ctx context.Context, safeAddress, account string) (bool, error) {
+	if !common.IsHexAddress(safeAddress) || !common.IsHexAddress(account) {
+		return false, nil
+	}
+	safe := common.HexToAddress(safeAddress)
+
+	code, err := c.client.CodeAt(ctx, safe, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to read Safe code: %w", err)
+	}
+	if len(code) == 0 {
+		return false, nil
+	}
+
+	out, err := c.callSafe(ctx, safe, "isOwner", common.HexToAddress(account))
+	if err != nil {
+		return false, err
+	}
+	values, err := parsedSafeABI.Unpack("isOwner", out)
+	if err != nil || len(values) != 1 {
+		return false, nil
+	}
+	isOwner, _ := values[0].(bool)
+	return isOwner, nil
+}
+
+func (c *EthereumClient) safeNonce(ctx context.Context, safe common.Address) (*big.Int, error) {
+	out, err := c.callSafe(ctx, safe, "nonce")
+	if err != nil {
+		return nil, err
+	}
+	values, err := parsedSafeABI.Unpack("nonce", out)
+	if err != nil || len(values) != 1 {
+		return nil, fmt.Errorf("contract at %s is not a Safe: invalid nonce response", safe.Hex())
+	}
+	nonce, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("contract at %s is not a Safe: invalid nonce response", safe.Hex())
+	}
+	return nonce, nil
+}
+
+func (c *EthereumClient) callSafe(ctx context.Context, safe common.Address, method string, args ...interface{}) ([]byte, error) {
+	input, err := parsedSafeABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack Safe %s: %w", method, err)
+	}
+	out, err := c.client.CallContract(ctx, ethereum.CallMsg{To: &safe, Data: input}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Safe %s: %w", method, err)
+	}
+	return out, nil
+}
+
+// safeTxHash computes the EIP-712 hash that Safe owners sign for tx, matching
+// the Safe contract's getTransactionHash.
+func safeTxHash(tx *did.SafeTransaction) common.Hash {
+	word := func(x *big.Int) []byte { return common.LeftPadBytes(x.Bytes(), 32) }
+	addr := func(s string) []byte { return common.LeftPadBytes(common.HexToAddress(s).Bytes(), 32) }
+
+	domain := crypto.Keccak256(
+		safeDomainTypeHash.Bytes(),
+		word(tx.ChainID),
+		addr(tx.Safe),
+	)
+	message := crypto.Keccak256(
+		safeTxTypeHash.Bytes(),
+		addr(tx.To),
+		word(tx.Value),
+		crypto.Keccak256(tx.Data),
+		word(big.NewInt(int64(tx.Operation))),
+		word(tx.SafeTxGas),
+		word(tx.BaseGas),
+		word(tx.GasPrice),
+		addr(tx.GasToken),
+		addr(tx.RefundReceiver),
+		word(tx.Nonce),
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain, message)
+}
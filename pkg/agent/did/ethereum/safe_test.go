@@ -0,0 +1,252 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+package ethereum
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+)
+
+// mockSafeCode builds creation code for a contract standing in for a Safe:
+// nonce() returns a fixed nonce and isOwner(address) is true only for owner.
+func mockSafeCode(t *testing.T, nonce int64, owner common.Address) []byte {
+	t.Helper()
+
+	nonceOut, err := parsedSafeABI.Methods["nonce"].Outputs.Pack(big.NewInt(nonce))
+	require.NoError(t, err)
+
+	rt := newEVMAsm()
+	rt.op(0x60, 0x00, 0x35, 0x60, 0xe0, 0x1c) // selector = calldata[0:4]
+	for _, entry := range []struct {
+		method string
+		label  string
+	}{
+		{"nonce", "nonce"},
+		{"isOwner", "isOwner"},
+	} {
+		rt.op(0x80, 0x63) // DUP1, PUSH4
+		rt.op(parsedSafeABI.Methods[entry.method].ID...)
+		rt.op(0x14) // EQ
+		rt.push2(entry.label)
+		rt.op(0x57) // JUMPI
+	}
+	rt.op(0x60, 0x00, 0x80, 0xfd) // REVERT(0, 0)
+
+	rt.jumpdest("nonce")
+	rt.returnData("nonceOut", nonceOut)
+
+	rt.jumpdest("isOwner")
+	rt.op(0x60, 0x04, 0x35) // CALLDATALOAD(4)
+	rt.push32(common.BytesToHash(owner.Bytes()))
+	rt.op(0x14)                         // EQ
+	rt.op(0x60, 0x00, 0x52)             // MSTORE(0, eq)
+	rt.op(0x60, 0x20, 0x60, 0x00, 0xf3) // RETURN(0, 32)
+
+	return creationCode(rt.assemble(t))
+}
+
+// referenceSafeTxHash hashes tx with go-ethereum's generic EIP-712 encoder.
+func referenceSafeTxHash(t *testing.T, tx *did.SafeTransaction) string {
+	t.Helper()
+
+	typed := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"SafeTx": {
+				{Name: "to", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+				{Name: "operation", Type: "uint8"},
+				{Name: "safeTxGas", Type: "uint256"},
+				{Name: "baseGas", Type: "uint256"},
+				{Name: "gasPrice", Type: "uint256"},
+				{Name: "gasToken", Type: "address"},
+				{Name: "refundReceiver", Type: "address"},
+				{Name: "nonce", Type: "uint256"},
+			},
+		},
+		PrimaryType: "SafeTx",
+		Domain: apitypes.TypedDataDomain{
+			ChainId:           (*math.HexOrDecimal256)(tx.ChainID),
+			VerifyingContract: tx.Safe,
+		},
+		Message: apitypes.TypedDataMessage{
+			"to":             tx.To,
+			"value":          tx.Value.String(),
+			"data":           hexutil.Encode(tx.Data),
+			"operation":      "0",
+			"safeTxGas":      tx.SafeTxGas.String(),
+			"baseGas":        tx.BaseGas.String(),
+			"gasPrice":       tx.GasPrice.String(),
+			"gasToken":       tx.GasToken,
+			"refundReceiver": tx.RefundReceiver,
+			"nonce":          tx.Nonce.String(),
+		},
+	}
+	hash, _, err := apitypes.TypedDataAndHash(typed)
+	require.NoError(t, err)
+	return hexutil.Encode(hash)
+}
+
+func TestManager_RegisterViaSafe(t *testing.T) {
+	payer, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	payerAddr := ethcrypto.PubkeyToAddress(payer.PublicKey)
+	safeOwner := common.HexToAddress("0x00000000000000000000000000000000000a11ce")
+	outsider := common.HexToAddress("0x0000000000000000000000000000000000000b0b")
+
+	sim := simulated.NewBackend(types.GenesisAlloc{
+		payerAddr: {Balance: new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))},
+	})
+	t.Cleanup(func() { _ = sim.Close() })
+	backend := &autoMineClient{Client: sim.Client(), backend: sim}
+
+	ctx := context.Background()
+	chainID, err := backend.ChainID(ctx)
+	require.NoError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(payer, chainID)
+	require.NoError(t, err)
+
+	safeAddr, _, _, err := bind.DeployContract(auth, abi.ABI{}, mockSafeCode(t, 7, safeOwner), backend)
+	require.NoError(t, err)
+
+	agentKeyPair, err := crypto.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+	agentPub, ok := agentKeyPair.PublicKey().(*ecdsa.PublicKey)
+	require.True(t, ok)
+
+	stub := &stubRegistry{
+		did:          "did:sage:ethereum:safe-agent",
+		name:         "Treasury Agent",
+		description:  "controlled by a multisig",
+		endpoint:     "https://treasury.example.com",
+		capabilities: `{}`,
+		owner:        safeAddr,
+		agentKey:     ethcrypto.FromECDSAPub(agentPub),
+		kemKey:       make([]byte, 32),
+		registeredAt: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC).Unix(),
+	}
+	registryAddr, _, _, err := bind.DeployContract(auth, abi.ABI{}, stub.deployCode(t), backend)
+	require.NoError(t, err)
+
+	manager := did.NewManager()
+	require.NoError(t, manager.ConfigureWithBackend(did.ChainEthereum, backend, &did.RegistryConfig{
+		ContractAddress: registryAddr.Hex(),
+		PrivateKey:      hex.EncodeToString(ethcrypto.FromECDSA(payer)),
+		MaxRetries:      3,
+	}))
+
+	req := &did.RegistrationRequest{
+		DID:          did.AgentDID(stub.did),
+		Name:         stub.name,
+		Description:  stub.description,
+		Endpoint:     stub.endpoint,
+		Capabilities: map[string]interface{}{},
+		KeyPair:      agentKeyPair,
+	}
+
+	var prepared *did.SafeTransaction
+
+	t.Run("Prepares registerAgent for the Safe", func(t *testing.T) {
+		prepared, err = manager.RegisterViaSafe(ctx, did.ChainEthereum, req, safeAddr.Hex())
+		require.NoError(t, err)
+
+		assert.Equal(t, did.ChainEthereum, prepared.Chain)
+		assert.Equal(t, chainID, prepared.ChainID)
+		assert.Equal(t, safeAddr.Hex(), prepared.Safe)
+		assert.Equal(t, registryAddr.Hex(), prepared.To)
+		assert.Equal(t, did.SafeOperationCall, prepared.Operation)
+		assert.Zero(t, prepared.Value.Sign())
+		assert.Equal(t, int64(7), prepared.Nonce.Int64())
+
+		registerABI, err := abi.JSON(strings.NewReader(SageRegistryABI))
+		require.NoError(t, err)
+		method := registerABI.Methods["registerAgent"]
+		require.Equal(t, method.ID, prepared.Data[:4])
+		args, err := method.Inputs.Unpack(prepared.Data[4:])
+		require.NoError(t, err)
+		assert.Equal(t, stub.did, args[0])
+		assert.Equal(t, stub.name, args[1])
+		assert.Equal(t, stub.description, args[2])
+		assert.Equal(t, stub.endpoint, args[3])
+		assert.Len(t, args[4], 65)
+		assert.NotEmpty(t, args[6])
+
+		assert.Equal(t, referenceSafeTxHash(t, prepared), prepared.SafeTxHash)
+	})
+
+	t.Run("Nothing is sent on-chain", func(t *testing.T) {
+		_, err := manager.ResolveAgent(ctx, req.DID)
+		assert.Equal(t, did.ErrDIDNotFound, err)
+	})
+
+	t.Run("Rejects non-contract Safe", func(t *testing.T) {
+		_, err := manager.RegisterViaSafe(ctx, did.ChainEthereum, req, outsider.Hex())
+		assert.Error(t, err)
+		_, err = manager.RegisterViaSafe(ctx, did.ChainEthereum, req, "not-an-address")
+		assert.Error(t, err)
+		_, err = manager.RegisterViaSafe(ctx, did.ChainSolana, req, safeAddr.Hex())
+		assert.Error(t, err)
+	})
+
+	t.Run("Ownership recognizes Safe owners", func(t *testing.T) {
+		// Execute the prepared call as the Safe would
+		registry := bind.NewBoundContract(registryAddr, abi.ABI{}, backend, backend, backend)
+		_, err := registry.RawTransact(auth, prepared.Data)
+		require.NoError(t, err)
+
+		metadata, err := manager.ResolveAgent(ctx, req.DID)
+		require.NoError(t, err)
+		assert.Equal(t, safeAddr.Hex(), metadata.Owner)
+
+		ok, err := manager.VerifyOwnership(ctx, req.DID, safeOwner.Hex())
+		require.NoError(t, err)
+		assert.True(t, ok, "Safe owner should control the agent")
+
+		ok, err = manager.VerifyOwnership(ctx, req.DID, safeAddr.Hex())
+		require.NoError(t, err)
+		assert.True(t, ok, "the Safe itself is the owner")
+
+		ok, err = manager.VerifyOwnership(ctx, req.DID, outsider.Hex())
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,215 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/sage-x-project/sage/pkg/agent/crypto"
+)
+
+// GrantTokenType is the typ header of capability grant tokens; it keeps a
+// grant from being accepted as an agent JWT and vice versa.
+const GrantTokenType = "sage-grant+jwt"
+
+var (
+	// ErrInvalidGrant is returned when a grant fails verification.
+	ErrInvalidGrant = errors.New("invalid capability grant")
+	// ErrGrantSubjectMismatch is returned when a grant is presented by an
+	// agent other than its subject.
+	ErrGrantSubjectMismatch = errors.New("capability grant issued to another agent")
+	// ErrGrantOutOfScope is returned when the requested action is not in
+	// the grant's scope.
+	ErrGrantOutOfScope = errors.New("action not in capability grant scope")
+)
+
+// Grant is a signed, time-bounded delegation by which the issuer agent
+// authorizes the subject agent to act on its behalf for a set of actions.
+// Token is its compact JWT form, which is what travels between agents.
+type Grant struct {
+	Token string
+}
+
+// GrantClaims are the verified contents of a Grant.
+type GrantClaims struct {
+	Issuer    AgentDID // iss: the delegating agent
+	Subject   AgentDID // sub: the agent allowed to act
+	Scope     []string // actions the subject may perform
+	ID        string   // jti, unique per grant for audit and revocation lists
+	KeyID     string   // kid header: "<issuer did>#<key fingerprint>"
+	IssuedAt  time.Time
+	NotBefore time.Time
+	ExpiresAt time.Time
+}
+
+// IssueGrant creates a grant signed with the issuer agent's Ed25519 key,
+// valid from now for ttl, allowing subjectDID to perform the actions in
+// scope on issuerDID's behalf.
+func IssueGrant(issuer crypto.KeyPair, issuerDID, subjectDID AgentDID, scope []string, ttl time.Duration) (*Grant, error) {
+	if issuer == nil {
+		return nil, fmt.Errorf("issuer key pair is required")
+	}
+	priv, ok := issuer.PrivateKey().(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: grants require an Ed25519 key, got %s", crypto.ErrSignNotSupported, issuer.Type())
+	}
+	if issuerDID == "" || subjectDID == "" {
+		return nil, fmt.Errorf("issuer and subject DIDs are required")
+	}
+	if len(scope) == 0 {
+		return nil, fmt.Errorf("scope must name at least one action")
+	}
+	for _, action := range scope {
+		if strings.TrimSpace(action) == "" {
+			return nil, fmt.Errorf("scope contains an empty action")
+		}
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("ttl must be positive")
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+		"iss":   string(issuerDID),
+		"sub":   string(subjectDID),
+		"scope": slices.Clone(scope),
+		"jti":   uuid.NewString(),
+		"iat":   jwt.NewNumericDate(now),
+		"nbf":   jwt.NewNumericDate(now),
+		"exp":   jwt.NewNumericDate(now.Add(ttl)),
+	})
+	token.Header["typ"] = GrantTokenType
+	token.Header["kid"] = agentJWTKeyID(issuerDID, priv.Public().(ed25519.PublicKey))
+
+	signed, err := token.SignedString(priv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign grant: %w", err)
+	}
+	return &Grant{Token: signed}, nil
+}
+
+// VerifyGrant verifies a grant issued by IssueGrant. See
+// VerifyGrantWithContext.
+func VerifyGrant(grant *Grant, resolver Resolver) (*GrantClaims, error) {
+	return VerifyGrantWithContext(context.Background(), grant, resolver)
+}
+
+// VerifyGrantWithContext resolves the issuer DID, checks that kid names the
+// issuer's registered Ed25519 key and that the issuer is active, then
+// verifies the signature and the exp, nbf and iat claims. It does not
+// check who presents the grant or for what; use GrantClaims.Permits.
+func VerifyGrantWithContext(ctx context.Context, grant *Grant, resolver Resolver) (*GrantClaims, error) {
+	if grant == nil || grant.Token == "" {
+		return nil, fmt.Errorf("%w: empty grant", ErrInvalidGrant)
+	}
+	if resolver == nil {
+		return nil, fmt.Errorf("resolver is required")
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(AgentJWTLeeway),
+	)
+
+	var kid string
+	parsed, err := parser.Parse(grant.Token, func(t *jwt.Token) (interface{}, error) {
+		if typ, _ := t.Header["typ"].(string); typ != GrantTokenType {
+			return nil, fmt.Errorf("typ %q is not %s", typ, GrantTokenType)
+		}
+		kid, _ = t.Header["kid"].(string)
+		iss, err := t.Claims.GetIssuer()
+		if err != nil || iss == "" {
+			return nil, fmt.Errorf("missing iss")
+		}
+		if sub, err := t.Claims.GetSubject(); err != nil || sub == "" {
+			return nil, fmt.Errorf("missing sub")
+		}
+		keyDID, _, found := strings.Cut(kid, "#")
+		if !found || keyDID != iss {
+			return nil, fmt.Errorf("kid %q does not reference issuer %s", kid, iss)
+		}
+		return issuerJWTKey(ctx, resolver, AgentDID(iss), kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGrant, err)
+	}
+
+	mc, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("%w: unexpected claims type", ErrInvalidGrant)
+	}
+	return grantClaimsFromMap(mc, kid)
+}
+
+// Permits reports whether subject may perform action under the grant:
+// subject must be the grant's subject and action one of its scope entries,
+// compared exactly. It returns ErrGrantSubjectMismatch or
+// ErrGrantOutOfScope otherwise.
+func (c *GrantClaims) Permits(subject AgentDID, action string) error {
+	if subject != c.Subject {
+		return fmt.Errorf("%w: granted to %s, presented by %s", ErrGrantSubjectMismatch, c.Subject, subject)
+	}
+	if !slices.Contains(c.Scope, action) {
+		return fmt.Errorf("%w: %q", ErrGrantOutOfScope, action)
+	}
+	return nil
+}
+
+func grantClaimsFromMap(mc jwt.MapClaims, kid string) (*GrantClaims, error) {
+	out := &GrantClaims{KeyID: kid}
+
+	iss, _ := mc.GetIssuer()
+	out.Issuer = AgentDID(iss)
+	sub, _ := mc.GetSubject()
+	out.Subject = AgentDID(sub)
+	out.ID, _ = mc["jti"].(string)
+
+	scope, ok := mc["scope"].([]interface{})
+	if !ok || len(scope) == 0 {
+		return nil, fmt.Errorf("%w: missing scope", ErrInvalidGrant)
+	}
+	for _, v := range scope {
+		action, ok := v.(string)
+		if !ok || action == "" {
+			return nil, fmt.Errorf("%w: malformed scope", ErrInvalidGrant)
+		}
+		out.Scope = append(out.Scope, action)
+	}
+
+	if nd, _ := mc.GetIssuedAt(); nd != nil {
+		out.IssuedAt = nd.Time
+	}
+	if nd, _ := mc.GetNotBefore(); nd != nil {
+		out.NotBefore = nd.Time
+	}
+	if nd, _ := mc.GetExpirationTime(); nd != nil {
+		out.ExpiresAt = nd.Time
+	}
+	return out, nil
+}
@@ -0,0 +1,132 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilityGrant(t *testing.T) {
+	const (
+		issuerDID  = AgentDID("did:sage:ethereum:0xalice")
+		subjectDID = AgentDID("did:sage:ethereum:0xbob")
+	)
+
+	kp, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	resolver := newJWTTestResolver(issuerDID, kp.PublicKey(), true)
+
+	t.Run("Valid grant", func(t *testing.T) {
+		grant, err := IssueGrant(kp, issuerDID, subjectDID, []string{"calendar:read", "mail:send"}, time.Hour)
+		require.NoError(t, err)
+
+		claims, err := VerifyGrant(grant, resolver)
+		require.NoError(t, err)
+		assert.Equal(t, issuerDID, claims.Issuer)
+		assert.Equal(t, subjectDID, claims.Subject)
+		assert.Equal(t, []string{"calendar:read", "mail:send"}, claims.Scope)
+		assert.NotEmpty(t, claims.ID)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), claims.ExpiresAt, 2*time.Second)
+
+		assert.NoError(t, claims.Permits(subjectDID, "mail:send"))
+		assert.ErrorIs(t, claims.Permits(issuerDID, "mail:send"), ErrGrantSubjectMismatch)
+	})
+
+	t.Run("Out-of-scope action", func(t *testing.T) {
+		grant, err := IssueGrant(kp, issuerDID, subjectDID, []string{"calendar:read"}, time.Hour)
+		require.NoError(t, err)
+
+		claims, err := VerifyGrant(grant, resolver)
+		require.NoError(t, err)
+		assert.ErrorIs(t, claims.Permits(subjectDID, "calendar:write"), ErrGrantOutOfScope)
+		assert.ErrorIs(t, claims.Permits(subjectDID, "calendar"), ErrGrantOutOfScope)
+	})
+
+	t.Run("Expired grant", func(t *testing.T) {
+		now := time.Now()
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+			"iss":   string(issuerDID),
+			"sub":   string(subjectDID),
+			"scope": []string{"calendar:read"},
+			"iat":   jwt.NewNumericDate(now.Add(-2 * time.Hour)),
+			"nbf":   jwt.NewNumericDate(now.Add(-2 * time.Hour)),
+			"exp":   jwt.NewNumericDate(now.Add(-time.Hour)),
+		})
+		token.Header["typ"] = GrantTokenType
+		token.Header["kid"] = agentJWTKeyID(issuerDID, kp.PublicKey().(ed25519.PublicKey))
+		signed, err := token.SignedString(kp.PrivateKey())
+		require.NoError(t, err)
+
+		_, err = VerifyGrant(&Grant{Token: signed}, resolver)
+		require.ErrorIs(t, err, ErrInvalidGrant)
+		assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+	})
+
+	t.Run("Signed by another key", func(t *testing.T) {
+		other, err := keys.GenerateEd25519KeyPair()
+		require.NoError(t, err)
+		grant, err := IssueGrant(other, issuerDID, subjectDID, []string{"calendar:read"}, time.Hour)
+		require.NoError(t, err)
+
+		_, err = VerifyGrant(grant, resolver)
+		assert.ErrorIs(t, err, ErrInvalidGrant)
+	})
+
+	t.Run("Inactive issuer", func(t *testing.T) {
+		grant, err := IssueGrant(kp, issuerDID, subjectDID, []string{"calendar:read"}, time.Hour)
+		require.NoError(t, err)
+
+		_, err = VerifyGrant(grant, newJWTTestResolver(issuerDID, kp.PublicKey(), false))
+		assert.ErrorIs(t, err, ErrInactiveAgent)
+	})
+
+	t.Run("Agent JWTs and grants are not interchangeable", func(t *testing.T) {
+		token, err := IssueAgentJWT(kp, issuerDID, nil, time.Minute)
+		require.NoError(t, err)
+		_, err = VerifyGrant(&Grant{Token: token}, resolver)
+		assert.ErrorIs(t, err, ErrInvalidGrant)
+
+		grant, err := IssueGrant(kp, issuerDID, subjectDID, []string{"calendar:read"}, time.Hour)
+		require.NoError(t, err)
+		_, err = VerifyAgentJWT(grant.Token, resolver)
+		assert.ErrorIs(t, err, ErrInvalidAgentJWT)
+	})
+
+	t.Run("Issue rejects bad input", func(t *testing.T) {
+		secp, err := keys.GenerateSecp256k1KeyPair()
+		require.NoError(t, err)
+		_, err = IssueGrant(secp, issuerDID, subjectDID, []string{"a"}, time.Hour)
+		assert.Error(t, err)
+		_, err = IssueGrant(kp, issuerDID, "", []string{"a"}, time.Hour)
+		assert.Error(t, err)
+		_, err = IssueGrant(kp, issuerDID, subjectDID, nil, time.Hour)
+		assert.Error(t, err)
+		_, err = IssueGrant(kp, issuerDID, subjectDID, []string{"a"}, 0)
+		assert.Error(t, err)
+		_, err = VerifyGrant(nil, resolver)
+		assert.ErrorIs(t, err, ErrInvalidGrant)
+	})
+}
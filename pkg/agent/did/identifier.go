@@ -0,0 +1,153 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+package did
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mr-tron/base58"
+)
+
+// ErrInvalidIdentifier is returned when a DID identifier does not satisfy the
+// format rules of its chain.
+var ErrInvalidIdentifier = errors.New("invalid DID identifier")
+
+const (
+	// solanaPubkeySize is the length of a decoded Solana public key.
+	solanaPubkeySize = 32
+	// solanaMinAddressLen and solanaMaxAddressLen bound the base58 encoding
+	// of a 32-byte public key.
+	solanaMinAddressLen = 32
+	solanaMaxAddressLen = 44
+	// base58Alphabet is the Bitcoin alphabet used by Solana addresses.
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+)
+
+// ValidateIdentifier checks the identifier part of a did:sage DID against the
+// rules of the given chain.
+//
+// Every identifier is one or more ':'-separated segments made of ASCII letters,
+// digits, '.', '_' and '-'. On top of that, address-form identifiers (an
+// owner address optionally followed by a decimal nonce, as produced by
+// GenerateAgentDIDWithAddress and GenerateAgentDIDWithNonce) must carry a
+// well-formed address:
+//   - Ethereum: a segment starting with "0x" must be a 20-byte hex address;
+//     mixed-case addresses must match their EIP-55 checksum.
+//   - Solana: a segment that looks like a base58 address (32-44 base58
+//     characters) must decode to a 32-byte public key.
+//
+// Name-style identifiers such as "agent001" or a UUID remain valid.
+func ValidateIdentifier(chain Chain, identifier string) error {
+	if identifier == "" {
+		return fmt.Errorf("%w: identifier is empty", ErrInvalidIdentifier)
+	}
+
+	segments := strings.Split(identifier, ":")
+	for _, seg := range segments {
+		if err := validateSegment(seg); err != nil {
+			return err
+		}
+	}
+
+	var (
+		isAddress bool
+		err       error
+	)
+	switch chain {
+	case ChainEthereum:
+		isAddress, err = validateEthereumAddress(segments[0])
+	case ChainSolana:
+		isAddress, err = validateSolanaAddress(segments[0])
+	default:
+		return fmt.Errorf("%w: unsupported chain %q", ErrInvalidIdentifier, chain)
+	}
+	if err != nil {
+		return err
+	}
+
+	if isAddress && len(segments) > 1 {
+		if len(segments) > 2 || !isDecimal(segments[1]) {
+			return fmt.Errorf("%w: %s address may only be followed by a decimal nonce", ErrInvalidIdentifier, chain)
+		}
+	}
+	return nil
+}
+
+// validateSegment enforces the character set shared by all chains.
+func validateSegment(seg string) error {
+	if seg == "" {
+		return fmt.Errorf("%w: empty segment", ErrInvalidIdentifier)
+	}
+	for _, c := range seg {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '.', c == '_', c == '-':
+		default:
+			return fmt.Errorf("%w: unexpected character %q", ErrInvalidIdentifier, c)
+		}
+	}
+	return nil
+}
+
+// validateEthereumAddress reports whether seg is an Ethereum address and, if
+// so, whether it is well formed.
+func validateEthereumAddress(seg string) (bool, error) {
+	if !strings.HasPrefix(seg, "0x") && !strings.HasPrefix(seg, "0X") {
+		return false, nil
+	}
+	if !common.IsHexAddress(seg) {
+		return true, fmt.Errorf("%w: %q is not a 20-byte hex address", ErrInvalidIdentifier, seg)
+	}
+	hexPart := seg[2:]
+	if hexPart != strings.ToLower(hexPart) && hexPart != strings.ToUpper(hexPart) {
+		if common.HexToAddress(seg).Hex() != "0x"+hexPart {
+			return true, fmt.Errorf("%w: %q fails EIP-55 checksum", ErrInvalidIdentifier, seg)
+		}
+	}
+	return true, nil
+}
+
+// validateSolanaAddress reports whether seg looks like a Solana address and,
+// if so, whether it decodes to a 32-byte public key.
+func validateSolanaAddress(seg string) (bool, error) {
+	if len(seg) < solanaMinAddressLen || len(seg) > solanaMaxAddressLen {
+		return false, nil
+	}
+	for _, c := range seg {
+		if !strings.ContainsRune(base58Alphabet, c) {
+			return false, nil
+		}
+	}
+	pub, err := base58.Decode(seg)
+	if err != nil || len(pub) != solanaPubkeySize {
+		return true, fmt.Errorf("%w: %q is not a base58 %d-byte public key", ErrInvalidIdentifier, seg, solanaPubkeySize)
+	}
+	return true, nil
+}
+
+func isDecimal(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
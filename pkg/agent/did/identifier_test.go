@@ -0,0 +1,99 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+package did
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateIdentifier(t *testing.T) {
+	tests := []struct {
+		name       string
+		chain      Chain
+		identifier string
+		valid      bool
+	}{
+		// Ethereum
+		{"Ethereum name identifier", ChainEthereum, "agent001", true},
+		{"Ethereum UUID identifier", ChainEthereum, "6f1c1f9e-2b0e-4a43-9d55-1c0f7b7d2a11", true},
+		{"Ethereum nested identifier", ChainEthereum, "org:department:agent003", true},
+		{"Ethereum lowercase address", ChainEthereum, "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266", true},
+		{"Ethereum uppercase address", ChainEthereum, "0xF39FD6E51AAD88F6F4CE6AB8827279CFFFB92266", true},
+		{"Ethereum checksummed address", ChainEthereum, "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", true},
+		{"Ethereum address with nonce", ChainEthereum, "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266:7", true},
+		{"Ethereum bad checksum", ChainEthereum, "0xF39fd6e51aad88F6F4ce6aB8827279cffFb92266", false},
+		{"Ethereum short address", ChainEthereum, "0xf39fd6e51aad88f6f4ce6ab8827279cfffb922", false},
+		{"Ethereum non-hex address", ChainEthereum, "0xg39fd6e51aad88f6f4ce6ab8827279cfffb92266", false},
+		{"Ethereum address with non-numeric nonce", ChainEthereum, "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266:abc", false},
+		{"Ethereum address with extra segments", ChainEthereum, "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266:1:2", false},
+
+		// Solana
+		{"Solana name identifier", ChainSolana, "agent002", true},
+		{"Solana pubkey", ChainSolana, "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK", true},
+		{"Solana pubkey with nonce", ChainSolana, "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK:0", true},
+		{"Solana system program", ChainSolana, "11111111111111111111111111111111", true},
+		{"Solana lowercased pubkey", ChainSolana, "dyw8jctfwhnrjhhmfcbxvvdtqwmevfbx6zkumg5cnskk", false},
+		{"Solana oversized pubkey", ChainSolana, "zzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzz", false},
+
+		// Shared rules
+		{"Empty identifier", ChainEthereum, "", false},
+		{"Empty segment", ChainSolana, "org::agent", false},
+		{"Whitespace", ChainEthereum, "agent 001", false},
+		{"Path separator", ChainSolana, "agent/001", false},
+		{"Unsupported chain", Chain("bitcoin"), "agent001", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateIdentifier(tt.chain, tt.identifier)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidIdentifier)
+			}
+		})
+	}
+}
+
+func TestParseDID_RejectsMalformedIdentifier(t *testing.T) {
+	for _, d := range []AgentDID{
+		"did:sage:ethereum:0xF39fd6e51aad88F6F4ce6aB8827279cffFb92266",
+		"did:sage:eth:0x1234",
+		"did:sage:solana:dyw8jctfwhnrjhhmfcbxvvdtqwmevfbx6zkumg5cnskk",
+		"did:sage:ethereum:",
+	} {
+		chain, identifier, err := ParseDID(d)
+		assert.ErrorIs(t, err, ErrInvalidIdentifier, "DID %s", d)
+		assert.Empty(t, chain)
+		assert.Empty(t, identifier)
+	}
+}
+
+func TestGeneratedAddressDIDsParse(t *testing.T) {
+	for _, d := range []AgentDID{
+		GenerateAgentDIDWithAddress(ChainEthereum, "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"),
+		GenerateAgentDIDWithNonce(ChainEthereum, "f39Fd6e51aad88F6F4ce6aB8827279cffFb92266", 3),
+		GenerateAgentDIDWithAddress(ChainSolana, "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK"),
+		GenerateAgentDIDWithNonce(ChainSolana, "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK", 1),
+	} {
+		_, _, err := ParseDID(d)
+		assert.NoError(t, err, "DID %s", d)
+	}
+}
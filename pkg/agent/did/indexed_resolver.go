@@ -0,0 +1,200 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// IndexPage is one page of agents returned by an IndexClient
+type IndexPage struct {
+	Agents []*AgentMetadata
+	// NextCursor continues the query; empty when this is the last page
+	NextCursor string
+}
+
+// IndexClient queries an off-chain index of the registry, such as a
+// subgraph or a team's own indexer built from AgentRegistered events.
+// An empty cursor requests the first page.
+type IndexClient interface {
+	// SearchAgents returns agents matching the Name, Capabilities and
+	// ActiveOnly filters of criteria. Limit and Offset are applied by the
+	// IndexedResolver and may be used as hints only.
+	SearchAgents(ctx context.Context, criteria SearchCriteria, cursor string) (*IndexPage, error)
+
+	// AgentsByOwner returns the agents owned by ownerAddress
+	AgentsByOwner(ctx context.Context, ownerAddress string, cursor string) (*IndexPage, error)
+}
+
+// IndexedResolverConfig configures an IndexedResolver
+type IndexedResolverConfig struct {
+	// MaxPages bounds the pages fetched per query (default 100)
+	MaxPages int
+	// OnFallback is called when the index fails and the on-chain resolver
+	// answers instead
+	OnFallback func(op string, err error)
+}
+
+// IndexedResolver answers Search and ListAgentsByOwner from an IndexClient
+// and falls back to the on-chain resolver when the index is unavailable.
+// All other lookups go to the on-chain resolver, which stays authoritative:
+// index results are not re-verified, so callers that act on a result should
+// Resolve the DID before trusting its keys.
+type IndexedResolver struct {
+	onChain Resolver
+	index   IndexClient
+	cfg     IndexedResolverConfig
+}
+
+var (
+	_ Resolver          = (*IndexedResolver)(nil)
+	_ KeySetResolver    = (*IndexedResolver)(nil)
+	_ KEMKeySetResolver = (*IndexedResolver)(nil)
+)
+
+// NewIndexedResolver wraps onChain so discovery queries use index
+func NewIndexedResolver(onChain Resolver, index IndexClient, cfg IndexedResolverConfig) *IndexedResolver {
+	if cfg.MaxPages <= 0 {
+		cfg.MaxPages = 100
+	}
+	return &IndexedResolver{
+		onChain: onChain,
+		index:   index,
+		cfg:     cfg,
+	}
+}
+
+// Resolve retrieves agent metadata from the on-chain resolver
+func (r *IndexedResolver) Resolve(ctx context.Context, did AgentDID) (*AgentMetadata, error) {
+	return r.onChain.Resolve(ctx, did)
+}
+
+// ResolvePublicKey retrieves the public key from the on-chain resolver
+func (r *IndexedResolver) ResolvePublicKey(ctx context.Context, did AgentDID) (interface{}, error) {
+	return r.onChain.ResolvePublicKey(ctx, did)
+}
+
+// ResolvePublicKeys retrieves all currently valid signing keys from the on-chain resolver
+func (r *IndexedResolver) ResolvePublicKeys(ctx context.Context, did AgentDID) ([]VerificationKey, error) {
+	return ResolveVerificationKeys(ctx, r.onChain, did)
+}
+
+// ResolveKEMKey retrieves the KEM key from the on-chain resolver
+func (r *IndexedResolver) ResolveKEMKey(ctx context.Context, did AgentDID) (interface{}, error) {
+	return r.onChain.ResolveKEMKey(ctx, did)
+}
+
+// ResolveKEMKeys retrieves all KEM keys from the on-chain resolver
+func (r *IndexedResolver) ResolveKEMKeys(ctx context.Context, did AgentDID) ([]KEMKeyEntry, error) {
+	return ResolveKEMKeys(ctx, r.onChain, did)
+}
+
+// VerifyMetadata verifies metadata against the on-chain resolver
+func (r *IndexedResolver) VerifyMetadata(ctx context.Context, did AgentDID, metadata *AgentMetadata) (*VerificationResult, error) {
+	return r.onChain.VerifyMetadata(ctx, did, metadata)
+}
+
+// ListAgentsByOwner lists the owner's agents from the index, falling back
+// to the on-chain resolver if the index fails
+func (r *IndexedResolver) ListAgentsByOwner(ctx context.Context, ownerAddress string) ([]*AgentMetadata, error) {
+	agents, err := r.collect(ctx, 0, 0, func(cursor string) (*IndexPage, error) {
+		return r.index.AgentsByOwner(ctx, ownerAddress, cursor)
+	})
+	if err == nil {
+		return agents, nil
+	}
+	if ctx.Err() != nil {
+		return nil, err
+	}
+	r.fallback("ListAgentsByOwner", err)
+	return r.onChain.ListAgentsByOwner(ctx, ownerAddress)
+}
+
+// Search finds agents through the index, falling back to the on-chain
+// resolver if the index fails. Offset and Limit apply across pages.
+func (r *IndexedResolver) Search(ctx context.Context, criteria SearchCriteria) ([]*AgentMetadata, error) {
+	agents, err := r.collect(ctx, criteria.Offset, criteria.Limit, func(cursor string) (*IndexPage, error) {
+		return r.index.SearchAgents(ctx, criteria, cursor)
+	})
+	if err == nil {
+		return agents, nil
+	}
+	if ctx.Err() != nil {
+		return nil, err
+	}
+	r.fallback("Search", err)
+	return r.onChain.Search(ctx, criteria)
+}
+
+// collect pages through an index query, skipping offset results and
+// stopping once limit results (0 means unlimited) have been gathered
+func (r *IndexedResolver) collect(ctx context.Context, offset, limit int, fetch func(cursor string) (*IndexPage, error)) ([]*AgentMetadata, error) {
+	if r.index == nil {
+		return nil, errors.New("no index configured")
+	}
+
+	var (
+		agents  []*AgentMetadata
+		cursor  string
+		skipped int
+	)
+	for page := 0; page < r.cfg.MaxPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result, err := fetch(cursor)
+		if err != nil {
+			return nil, fmt.Errorf("index query failed: %w", err)
+		}
+		if result == nil {
+			return nil, errors.New("index returned no page")
+		}
+
+		for _, agent := range result.Agents {
+			if agent == nil {
+				continue
+			}
+			if skipped < offset {
+				skipped++
+				continue
+			}
+			agents = append(agents, agent)
+			if limit > 0 && len(agents) >= limit {
+				return agents, nil
+			}
+		}
+
+		if result.NextCursor == "" {
+			return agents, nil
+		}
+		if result.NextCursor == cursor {
+			return nil, fmt.Errorf("index returned a repeating cursor %q", cursor)
+		}
+		cursor = result.NextCursor
+	}
+	return nil, fmt.Errorf("index query exceeded %d pages", r.cfg.MaxPages)
+}
+
+func (r *IndexedResolver) fallback(op string, err error) {
+	if r.cfg.OnFallback != nil {
+		r.cfg.OnFallback(op, err)
+	}
+}
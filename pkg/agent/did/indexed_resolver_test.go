@@ -0,0 +1,171 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIndex serves agents in fixed-size pages using numeric cursors
+type fakeIndex struct {
+	agents   []*AgentMetadata
+	pageSize int
+	err      error
+	cursors  []string
+	loop     bool
+}
+
+func (f *fakeIndex) page(cursor string) (*IndexPage, error) {
+	f.cursors = append(f.cursors, cursor)
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.loop {
+		return &IndexPage{Agents: f.agents[:1], NextCursor: "same"}, nil
+	}
+	start := 0
+	if cursor != "" {
+		n, err := strconv.Atoi(cursor)
+		if err != nil {
+			return nil, err
+		}
+		start = n
+	}
+	end := start + f.pageSize
+	if end > len(f.agents) {
+		end = len(f.agents)
+	}
+	page := &IndexPage{Agents: f.agents[start:end]}
+	if end < len(f.agents) {
+		page.NextCursor = strconv.Itoa(end)
+	}
+	return page, nil
+}
+
+func (f *fakeIndex) SearchAgents(_ context.Context, _ SearchCriteria, cursor string) (*IndexPage, error) {
+	return f.page(cursor)
+}
+
+func (f *fakeIndex) AgentsByOwner(_ context.Context, _ string, cursor string) (*IndexPage, error) {
+	return f.page(cursor)
+}
+
+func indexedAgents(n int) []*AgentMetadata {
+	agents := make([]*AgentMetadata, n)
+	for i := range agents {
+		agents[i] = &AgentMetadata{DID: AgentDID(fmt.Sprintf("did:sage:ethereum:agent-%d", i)), IsActive: true}
+	}
+	return agents
+}
+
+func TestIndexedResolver(t *testing.T) {
+	ctx := context.Background()
+	owner := "0x1234567890123456789012345678901234567890"
+
+	t.Run("Search pages through the index", func(t *testing.T) {
+		index := &fakeIndex{agents: indexedAgents(7), pageSize: 3}
+		onChain := &MockResolver{}
+		r := NewIndexedResolver(onChain, index, IndexedResolverConfig{})
+
+		agents, err := r.Search(ctx, SearchCriteria{})
+		require.NoError(t, err)
+		assert.Len(t, agents, 7)
+		assert.Equal(t, []string{"", "3", "6"}, index.cursors)
+		onChain.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Search applies offset and limit across pages", func(t *testing.T) {
+		index := &fakeIndex{agents: indexedAgents(10), pageSize: 3}
+		r := NewIndexedResolver(&MockResolver{}, index, IndexedResolverConfig{})
+
+		agents, err := r.Search(ctx, SearchCriteria{Offset: 2, Limit: 4})
+		require.NoError(t, err)
+		require.Len(t, agents, 4)
+		assert.Equal(t, AgentDID("did:sage:ethereum:agent-2"), agents[0].DID)
+		assert.Equal(t, AgentDID("did:sage:ethereum:agent-5"), agents[3].DID)
+		assert.Equal(t, []string{"", "3"}, index.cursors, "no pages fetched past the limit")
+	})
+
+	t.Run("ListAgentsByOwner uses the index", func(t *testing.T) {
+		index := &fakeIndex{agents: indexedAgents(5), pageSize: 2}
+		onChain := &MockResolver{}
+		r := NewIndexedResolver(onChain, index, IndexedResolverConfig{})
+
+		agents, err := r.ListAgentsByOwner(ctx, owner)
+		require.NoError(t, err)
+		assert.Len(t, agents, 5)
+		onChain.AssertNotCalled(t, "ListAgentsByOwner", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Falls back to on-chain when the index fails", func(t *testing.T) {
+		index := &fakeIndex{err: errors.New("subgraph unavailable")}
+		onChain := &MockResolver{}
+		chainAgents := indexedAgents(1)
+		onChain.On("Search", ctx, SearchCriteria{Name: "x"}).Return(chainAgents, nil)
+		onChain.On("ListAgentsByOwner", ctx, owner).Return(chainAgents, nil)
+
+		var fallbacks []string
+		r := NewIndexedResolver(onChain, index, IndexedResolverConfig{
+			OnFallback: func(op string, err error) { fallbacks = append(fallbacks, op) },
+		})
+
+		agents, err := r.Search(ctx, SearchCriteria{Name: "x"})
+		require.NoError(t, err)
+		assert.Equal(t, chainAgents, agents)
+
+		agents, err = r.ListAgentsByOwner(ctx, owner)
+		require.NoError(t, err)
+		assert.Equal(t, chainAgents, agents)
+
+		assert.Equal(t, []string{"Search", "ListAgentsByOwner"}, fallbacks)
+		onChain.AssertExpectations(t)
+	})
+
+	t.Run("Repeating cursor falls back", func(t *testing.T) {
+		index := &fakeIndex{agents: indexedAgents(2), loop: true}
+		onChain := &MockResolver{}
+		onChain.On("Search", ctx, SearchCriteria{}).Return([]*AgentMetadata{}, nil)
+		r := NewIndexedResolver(onChain, index, IndexedResolverConfig{MaxPages: 5})
+
+		_, err := r.Search(ctx, SearchCriteria{})
+		require.NoError(t, err)
+		assert.Len(t, index.cursors, 2)
+		onChain.AssertExpectations(t)
+	})
+
+	t.Run("Resolve stays on-chain", func(t *testing.T) {
+		did := AgentDID("did:sage:ethereum:agent-0")
+		onChain := &MockResolver{}
+		onChain.On("Resolve", ctx, did).Return(&AgentMetadata{DID: did}, nil)
+		r := NewIndexedResolver(onChain, &fakeIndex{}, IndexedResolverConfig{})
+
+		metadata, err := r.Resolve(ctx, did)
+		require.NoError(t, err)
+		assert.Equal(t, did, metadata.DID)
+		onChain.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,201 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"container/list"
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultAccessLogSize is the number of DIDs an InstrumentedResolver tracks
+// when no capacity is given
+const DefaultAccessLogSize = 10000
+
+// ResolveAccess summarizes how often one DID has been resolved
+type ResolveAccess struct {
+	DID AgentDID
+	// Count is the number of resolutions, successful or not
+	Count uint64
+	// Failures is the number of resolutions that returned an error; many
+	// DIDs with only failures suggest someone enumerating the registry
+	Failures  uint64
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// InstrumentedResolver wraps a Resolver and records which DIDs are resolved
+// and how often, so operators can spot reconnaissance such as enumeration
+// of the registry. It keeps its own bounded record, independent of
+// Prometheus: at most capacity DIDs are tracked and the least recently
+// resolved one is forgotten, counts included, to make room for a new one.
+//
+// Every per-DID lookup (Resolve, ResolvePublicKey(s), ResolveKEMKey(s) and
+// VerifyMetadata) is recorded; ListAgentsByOwner and Search pass through.
+type InstrumentedResolver struct {
+	inner    Resolver
+	capacity int
+	now      func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // of *ResolveAccess, most recently resolved first
+	entries map[AgentDID]*list.Element
+}
+
+var (
+	_ Resolver          = (*InstrumentedResolver)(nil)
+	_ KeySetResolver    = (*InstrumentedResolver)(nil)
+	_ KEMKeySetResolver = (*InstrumentedResolver)(nil)
+)
+
+// NewInstrumentedResolver wraps inner, tracking up to capacity DIDs
+// (DefaultAccessLogSize when capacity <= 0)
+func NewInstrumentedResolver(inner Resolver, capacity int) *InstrumentedResolver {
+	if capacity <= 0 {
+		capacity = DefaultAccessLogSize
+	}
+	return &InstrumentedResolver{
+		inner:    inner,
+		capacity: capacity,
+		now:      time.Now,
+		order:    list.New(),
+		entries:  make(map[AgentDID]*list.Element),
+	}
+}
+
+// TopResolved returns the n most frequently resolved DIDs, most frequent
+// first; ties go to the most recently resolved. n <= 0 returns every
+// tracked DID.
+func (r *InstrumentedResolver) TopResolved(n int) []ResolveAccess {
+	r.mu.Lock()
+	out := make([]ResolveAccess, 0, r.order.Len())
+	for e := r.order.Front(); e != nil; e = e.Next() {
+		out = append(out, *e.Value.(*ResolveAccess))
+	}
+	r.mu.Unlock()
+
+	// order is most recent first, so a stable sort keeps recency for ties
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Count > out[j].Count })
+	if n > 0 && n < len(out) {
+		out = out[:n]
+	}
+	return out
+}
+
+// Access returns the record for one DID, if it is tracked
+func (r *InstrumentedResolver) Access(did AgentDID) (ResolveAccess, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[did]
+	if !ok {
+		return ResolveAccess{}, false
+	}
+	return *e.Value.(*ResolveAccess), true
+}
+
+// Reset forgets every recorded access
+func (r *InstrumentedResolver) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.order.Init()
+	r.entries = make(map[AgentDID]*list.Element)
+}
+
+// Resolve retrieves agent metadata and records the access
+func (r *InstrumentedResolver) Resolve(ctx context.Context, did AgentDID) (*AgentMetadata, error) {
+	metadata, err := r.inner.Resolve(ctx, did)
+	r.record(did, err)
+	return metadata, err
+}
+
+// ResolvePublicKey retrieves the public key and records the access
+func (r *InstrumentedResolver) ResolvePublicKey(ctx context.Context, did AgentDID) (interface{}, error) {
+	key, err := r.inner.ResolvePublicKey(ctx, did)
+	r.record(did, err)
+	return key, err
+}
+
+// ResolvePublicKeys retrieves all currently valid signing keys and records the access
+func (r *InstrumentedResolver) ResolvePublicKeys(ctx context.Context, did AgentDID) ([]VerificationKey, error) {
+	keys, err := ResolveVerificationKeys(ctx, r.inner, did)
+	r.record(did, err)
+	return keys, err
+}
+
+// ResolveKEMKey retrieves the KEM key and records the access
+func (r *InstrumentedResolver) ResolveKEMKey(ctx context.Context, did AgentDID) (interface{}, error) {
+	key, err := r.inner.ResolveKEMKey(ctx, did)
+	r.record(did, err)
+	return key, err
+}
+
+// ResolveKEMKeys retrieves all KEM keys and records the access
+func (r *InstrumentedResolver) ResolveKEMKeys(ctx context.Context, did AgentDID) ([]KEMKeyEntry, error) {
+	keys, err := ResolveKEMKeys(ctx, r.inner, did)
+	r.record(did, err)
+	return keys, err
+}
+
+// VerifyMetadata verifies metadata and records the access
+func (r *InstrumentedResolver) VerifyMetadata(ctx context.Context, did AgentDID, metadata *AgentMetadata) (*VerificationResult, error) {
+	result, err := r.inner.VerifyMetadata(ctx, did, metadata)
+	r.record(did, err)
+	return result, err
+}
+
+// ListAgentsByOwner lists agents without recording access
+func (r *InstrumentedResolver) ListAgentsByOwner(ctx context.Context, ownerAddress string) ([]*AgentMetadata, error) {
+	return r.inner.ListAgentsByOwner(ctx, ownerAddress)
+}
+
+// Search searches agents without recording access
+func (r *InstrumentedResolver) Search(ctx context.Context, criteria SearchCriteria) ([]*AgentMetadata, error) {
+	return r.inner.Search(ctx, criteria)
+}
+
+// record counts one resolution of did, evicting the least recently
+// resolved DID when the log is full
+func (r *InstrumentedResolver) record(did AgentDID, err error) {
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.entries[did]
+	if ok {
+		r.order.MoveToFront(e)
+	} else {
+		if r.order.Len() >= r.capacity {
+			oldest := r.order.Back()
+			r.order.Remove(oldest)
+			delete(r.entries, oldest.Value.(*ResolveAccess).DID)
+		}
+		e = r.order.PushFront(&ResolveAccess{DID: did, FirstSeen: now})
+		r.entries[did] = e
+	}
+
+	access := e.Value.(*ResolveAccess)
+	access.Count++
+	if err != nil {
+		access.Failures++
+	}
+	access.LastSeen = now
+}
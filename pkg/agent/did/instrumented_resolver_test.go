@@ -0,0 +1,120 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentedResolver(t *testing.T) {
+	ctx := context.Background()
+	known := map[AgentDID]bool{"did:sage:ethereum:a": true, "did:sage:ethereum:b": true, "did:sage:ethereum:c": true}
+	inner := &flakyResolver{}
+
+	newResolver := func(capacity int) (*InstrumentedResolver, *time.Time) {
+		r := NewInstrumentedResolver(inner, capacity)
+		now := time.Unix(1700000000, 0)
+		r.now = func() time.Time { now = now.Add(time.Second); return now }
+		return r, &now
+	}
+	resolve := func(r *InstrumentedResolver, did AgentDID, times int) {
+		for i := 0; i < times; i++ {
+			if known[did] {
+				inner.setErr(nil)
+			} else {
+				inner.setErr(ErrDIDNotFound)
+			}
+			_, _ = r.Resolve(ctx, did)
+		}
+	}
+
+	t.Run("top-N reflects access frequency", func(t *testing.T) {
+		r, _ := newResolver(0)
+		resolve(r, "did:sage:ethereum:b", 5)
+		resolve(r, "did:sage:ethereum:a", 2)
+		resolve(r, "did:sage:ethereum:c", 9)
+		resolve(r, "did:sage:ethereum:missing", 1)
+
+		top := r.TopResolved(2)
+		require.Len(t, top, 2)
+		assert.Equal(t, AgentDID("did:sage:ethereum:c"), top[0].DID)
+		assert.Equal(t, uint64(9), top[0].Count)
+		assert.Equal(t, AgentDID("did:sage:ethereum:b"), top[1].DID)
+		assert.Equal(t, uint64(5), top[1].Count)
+
+		all := r.TopResolved(0)
+		require.Len(t, all, 4)
+		assert.Equal(t, AgentDID("did:sage:ethereum:missing"), all[3].DID)
+		assert.Equal(t, uint64(1), all[3].Failures)
+		assert.Zero(t, all[0].Failures)
+		assert.True(t, all[0].LastSeen.After(all[0].FirstSeen))
+	})
+
+	t.Run("ties go to the most recent", func(t *testing.T) {
+		r, _ := newResolver(0)
+		resolve(r, "did:sage:ethereum:a", 1)
+		resolve(r, "did:sage:ethereum:b", 1)
+		assert.Equal(t, AgentDID("did:sage:ethereum:b"), r.TopResolved(1)[0].DID)
+	})
+
+	t.Run("every per-DID lookup is recorded", func(t *testing.T) {
+		did := AgentDID("did:sage:ethereum:a")
+		m := new(MockResolver)
+		m.On("ResolvePublicKey", mock.Anything, did).Return(nil, ErrDIDNotFound)
+		m.On("ResolveKEMKey", mock.Anything, did).Return(nil, ErrDIDNotFound)
+		m.On("VerifyMetadata", mock.Anything, did, mock.Anything).Return(&VerificationResult{Valid: true}, nil)
+		m.On("Search", mock.Anything, mock.Anything).Return([]*AgentMetadata{}, nil)
+		r := NewInstrumentedResolver(m, 0)
+		_, _ = r.ResolvePublicKey(ctx, did)
+		_, _ = r.ResolvePublicKeys(ctx, did)
+		_, _ = r.ResolveKEMKey(ctx, did)
+		_, _ = r.ResolveKEMKeys(ctx, did)
+		_, _ = r.VerifyMetadata(ctx, did, &AgentMetadata{DID: did})
+		_, _ = r.Search(ctx, SearchCriteria{})
+
+		access, ok := r.Access(did)
+		require.True(t, ok)
+		assert.Equal(t, uint64(5), access.Count)
+		assert.Len(t, r.TopResolved(0), 1)
+	})
+
+	t.Run("bounded by least recent resolution", func(t *testing.T) {
+		r, _ := newResolver(2)
+		resolve(r, "did:sage:ethereum:a", 3)
+		resolve(r, "did:sage:ethereum:b", 1)
+		resolve(r, "did:sage:ethereum:a", 1)
+		resolve(r, "did:sage:ethereum:c", 1) // evicts b
+
+		_, ok := r.Access("did:sage:ethereum:b")
+		assert.False(t, ok)
+		access, ok := r.Access("did:sage:ethereum:a")
+		require.True(t, ok)
+		assert.Equal(t, uint64(4), access.Count)
+		assert.Len(t, r.TopResolved(0), 2)
+
+		r.Reset()
+		assert.Empty(t, r.TopResolved(0))
+	})
+}
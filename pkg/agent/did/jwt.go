@@ -0,0 +1,240 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sage-x-project/sage/pkg/agent/crypto"
+)
+
+// AgentJWTLeeway is the clock skew tolerated when checking exp, nbf and iat.
+const AgentJWTLeeway = 30 * time.Second
+
+// ErrInvalidAgentJWT is returned when an agent JWT fails verification.
+var ErrInvalidAgentJWT = errors.New("invalid agent JWT")
+
+// reservedJWTClaims are set by IssueAgentJWT and cannot be supplied by callers.
+var reservedJWTClaims = []string{"iss", "sub", "iat", "nbf", "exp"}
+
+// Claims are the verified contents of an agent JWT.
+type Claims struct {
+	Issuer    AgentDID       // iss: the agent DID
+	Subject   string         // sub: the agent DID
+	Audience  []string       // aud, if present (not checked by VerifyAgentJWT)
+	ID        string         // jti, if present
+	KeyID     string         // kid header: "<did>#<key fingerprint>"
+	IssuedAt  time.Time      // iat
+	NotBefore time.Time      // nbf
+	ExpiresAt time.Time      // exp
+	Custom    map[string]any // all remaining claims
+}
+
+// IssueAgentJWT creates an EdDSA-signed JWT asserting the agent identity did.
+//
+// iss and sub are set to the DID, iat and nbf to the current time and exp to
+// now+ttl. The kid header is "<did>#<fingerprint>", where the fingerprint is
+// the first 8 bytes of SHA-256 over the Ed25519 public key in hex, so the
+// verifier can detect a rotated key. claims may carry any other claim
+// (e.g. aud, jti, scope) but not the reserved registered claims.
+func IssueAgentJWT(kp crypto.KeyPair, did AgentDID, claims map[string]any, ttl time.Duration) (string, error) {
+	if kp == nil {
+		return "", fmt.Errorf("key pair is required")
+	}
+	priv, ok := kp.PrivateKey().(ed25519.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("%w: agent JWTs require an Ed25519 key, got %s", crypto.ErrSignNotSupported, kp.Type())
+	}
+	if did == "" {
+		return "", fmt.Errorf("DID is required")
+	}
+	if ttl <= 0 {
+		return "", fmt.Errorf("ttl must be positive")
+	}
+
+	mc := jwt.MapClaims{}
+	for k, v := range claims {
+		mc[k] = v
+	}
+	for _, k := range reservedJWTClaims {
+		if _, ok := mc[k]; ok {
+			return "", fmt.Errorf("claim %q is reserved", k)
+		}
+	}
+
+	now := time.Now()
+	mc["iss"] = string(did)
+	mc["sub"] = string(did)
+	mc["iat"] = jwt.NewNumericDate(now)
+	mc["nbf"] = jwt.NewNumericDate(now)
+	mc["exp"] = jwt.NewNumericDate(now.Add(ttl))
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, mc)
+	token.Header["kid"] = agentJWTKeyID(did, priv.Public().(ed25519.PublicKey))
+
+	signed, err := token.SignedString(priv)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign agent JWT: %w", err)
+	}
+	return signed, nil
+}
+
+// VerifyAgentJWT verifies an agent JWT issued by IssueAgentJWT. See
+// VerifyAgentJWTWithContext.
+func VerifyAgentJWT(token string, resolver Resolver) (*Claims, error) {
+	return VerifyAgentJWTWithContext(context.Background(), token, resolver)
+}
+
+// VerifyAgentJWTWithContext resolves the issuer DID, checks that kid names
+// the agent's registered Ed25519 key and that the agent is active, then
+// verifies the EdDSA signature and the exp, nbf and iat claims. Capability
+// grants (typ GrantTokenType) are rejected, even self-issued ones.
+func VerifyAgentJWTWithContext(ctx context.Context, token string, resolver Resolver) (*Claims, error) {
+	if resolver == nil {
+		return nil, fmt.Errorf("resolver is required")
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(AgentJWTLeeway),
+	)
+
+	var kid string
+	parsed, err := parser.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		if typ, _ := t.Header["typ"].(string); typ == GrantTokenType {
+			return nil, fmt.Errorf("typ %s is a capability grant, not an agent JWT", typ)
+		}
+		kid, _ = t.Header["kid"].(string)
+		iss, err := t.Claims.GetIssuer()
+		if err != nil || iss == "" {
+			return nil, fmt.Errorf("missing iss")
+		}
+		if sub, err := t.Claims.GetSubject(); err != nil || sub != iss {
+			return nil, fmt.Errorf("sub must equal iss")
+		}
+		keyDID, _, found := strings.Cut(kid, "#")
+		if !found || keyDID != iss {
+			return nil, fmt.Errorf("kid %q does not reference issuer %s", kid, iss)
+		}
+		return issuerJWTKey(ctx, resolver, AgentDID(iss), kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAgentJWT, err)
+	}
+
+	mc, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("%w: unexpected claims type", ErrInvalidAgentJWT)
+	}
+	return claimsFromMap(mc, kid)
+}
+
+// issuerJWTKey resolves the active issuer and returns the Ed25519 key named
+// by kid.
+func issuerJWTKey(ctx context.Context, resolver Resolver, iss AgentDID, kid string) (ed25519.PublicKey, error) {
+	metadata, err := resolver.Resolve(ctx, iss)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve issuer: %w", err)
+	}
+	if !metadata.IsActive {
+		return nil, ErrInactiveAgent
+	}
+	if ks, ok := resolver.(KeySetResolver); ok {
+		// During a key rollover any currently valid key may have signed
+		keys, err := ks.ResolvePublicKeys(ctx, iss)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve issuer keys: %w", err)
+		}
+		key, err := SelectVerificationKey(keys, kid)
+		if err != nil {
+			return nil, err
+		}
+		pub, ok := ed25519PublicKeyOf(key.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("key %q is not an Ed25519 key", kid)
+		}
+		return pub, nil
+	}
+	pub, ok := ed25519PublicKeyOf(metadata.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("issuer has no Ed25519 key")
+	}
+	if kid != agentJWTKeyID(iss, pub) {
+		return nil, fmt.Errorf("kid %q does not match the registered key", kid)
+	}
+	return pub, nil
+}
+
+// agentJWTKeyID builds the kid header value for an agent key.
+func agentJWTKeyID(did AgentDID, pub ed25519.PublicKey) string {
+	id, _ := KeyIDFor(did, pub) // Ed25519 keys always encode
+	return id
+}
+
+// ed25519PublicKeyOf extracts an Ed25519 key from a resolved public key,
+// which may be the raw key or a KeyPair wrapping it.
+func ed25519PublicKeyOf(pub interface{}) (ed25519.PublicKey, bool) {
+	if kp, ok := pub.(crypto.KeyPair); ok {
+		pub = kp.PublicKey()
+	}
+	pk, ok := pub.(ed25519.PublicKey)
+	return pk, ok && len(pk) == ed25519.PublicKeySize
+}
+
+func claimsFromMap(mc jwt.MapClaims, kid string) (*Claims, error) {
+	out := &Claims{KeyID: kid, Custom: make(map[string]any)}
+
+	iss, _ := mc.GetIssuer()
+	out.Issuer = AgentDID(iss)
+	out.Subject, _ = mc.GetSubject()
+	aud, err := mc.GetAudience()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAgentJWT, err)
+	}
+	out.Audience = aud
+	if jti, ok := mc["jti"].(string); ok {
+		out.ID = jti
+	}
+	if nd, _ := mc.GetIssuedAt(); nd != nil {
+		out.IssuedAt = nd.Time
+	}
+	if nd, _ := mc.GetNotBefore(); nd != nil {
+		out.NotBefore = nd.Time
+	}
+	if nd, _ := mc.GetExpirationTime(); nd != nil {
+		out.ExpiresAt = nd.Time
+	}
+
+	for k, v := range mc {
+		switch k {
+		case "iss", "sub", "aud", "jti", "iat", "nbf", "exp":
+		default:
+			out.Custom[k] = v
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,199 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newJWTTestResolver(did AgentDID, pub interface{}, active bool) *MockResolver {
+	r := new(MockResolver)
+	r.On("Resolve", mock.Anything, did).Return(&AgentMetadata{
+		DID:       did,
+		PublicKey: pub,
+		IsActive:  active,
+	}, nil)
+	return r
+}
+
+// signRawAgentJWT signs arbitrary claims with the agent's kid, bypassing the
+// IssueAgentJWT safeguards to exercise verifier checks.
+func signRawAgentJWT(t *testing.T, kp crypto.KeyPair, did AgentDID, claims jwt.MapClaims) string {
+	t.Helper()
+	priv := kp.PrivateKey().(ed25519.PrivateKey)
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = agentJWTKeyID(did, priv.Public().(ed25519.PublicKey))
+	s, err := token.SignedString(priv)
+	require.NoError(t, err)
+	return s
+}
+
+func TestAgentJWT(t *testing.T) {
+	const agentDID = AgentDID("did:sage:ethereum:0xagent")
+
+	kp, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	resolver := newJWTTestResolver(agentDID, kp.PublicKey(), true)
+
+	t.Run("Issue and verify", func(t *testing.T) {
+		token, err := IssueAgentJWT(kp, agentDID, map[string]any{
+			"aud":   "https://api.example.com",
+			"jti":   "token-1",
+			"scope": "chat:write",
+		}, time.Minute)
+		require.NoError(t, err)
+
+		claims, err := VerifyAgentJWT(token, resolver)
+		require.NoError(t, err)
+		assert.Equal(t, agentDID, claims.Issuer)
+		assert.Equal(t, string(agentDID), claims.Subject)
+		assert.Equal(t, []string{"https://api.example.com"}, claims.Audience)
+		assert.Equal(t, "token-1", claims.ID)
+		assert.True(t, strings.HasPrefix(claims.KeyID, string(agentDID)+"#"))
+		assert.Equal(t, "chat:write", claims.Custom["scope"])
+		assert.WithinDuration(t, time.Now().Add(time.Minute), claims.ExpiresAt, 2*time.Second)
+		assert.False(t, claims.NotBefore.After(time.Now()))
+	})
+
+	t.Run("Resolver may return the KeyPair", func(t *testing.T) {
+		token, err := IssueAgentJWT(kp, agentDID, nil, time.Minute)
+		require.NoError(t, err)
+
+		_, err = VerifyAgentJWT(token, newJWTTestResolver(agentDID, kp, true))
+		require.NoError(t, err)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		now := time.Now()
+		token := signRawAgentJWT(t, kp, agentDID, jwt.MapClaims{
+			"iss": string(agentDID),
+			"sub": string(agentDID),
+			"iat": jwt.NewNumericDate(now.Add(-2 * time.Hour)),
+			"nbf": jwt.NewNumericDate(now.Add(-2 * time.Hour)),
+			"exp": jwt.NewNumericDate(now.Add(-time.Hour)),
+		})
+
+		_, err := VerifyAgentJWT(token, resolver)
+		require.ErrorIs(t, err, ErrInvalidAgentJWT)
+		assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+	})
+
+	t.Run("Not yet valid", func(t *testing.T) {
+		now := time.Now()
+		token := signRawAgentJWT(t, kp, agentDID, jwt.MapClaims{
+			"iss": string(agentDID),
+			"sub": string(agentDID),
+			"iat": jwt.NewNumericDate(now),
+			"nbf": jwt.NewNumericDate(now.Add(time.Hour)),
+			"exp": jwt.NewNumericDate(now.Add(2 * time.Hour)),
+		})
+
+		_, err := VerifyAgentJWT(token, resolver)
+		require.ErrorIs(t, err, ErrInvalidAgentJWT)
+		assert.ErrorIs(t, err, jwt.ErrTokenNotValidYet)
+	})
+
+	t.Run("Missing exp", func(t *testing.T) {
+		token := signRawAgentJWT(t, kp, agentDID, jwt.MapClaims{
+			"iss": string(agentDID),
+			"sub": string(agentDID),
+		})
+
+		_, err := VerifyAgentJWT(token, resolver)
+		require.ErrorIs(t, err, ErrInvalidAgentJWT)
+	})
+
+	t.Run("Rotated key", func(t *testing.T) {
+		other, err := keys.GenerateEd25519KeyPair()
+		require.NoError(t, err)
+		token, err := IssueAgentJWT(other, agentDID, nil, time.Minute)
+		require.NoError(t, err)
+
+		_, err = VerifyAgentJWT(token, resolver)
+		require.ErrorIs(t, err, ErrInvalidAgentJWT)
+		assert.Contains(t, err.Error(), "does not match the registered key")
+	})
+
+	t.Run("Tampered payload", func(t *testing.T) {
+		token, err := IssueAgentJWT(kp, agentDID, map[string]any{"scope": "read"}, time.Minute)
+		require.NoError(t, err)
+		forged, err := IssueAgentJWT(kp, agentDID, map[string]any{"scope": "admin"}, time.Minute)
+		require.NoError(t, err)
+
+		parts := strings.Split(token, ".")
+		parts[1] = strings.Split(forged, ".")[1]
+		_, err = VerifyAgentJWT(strings.Join(parts, "."), resolver)
+		require.ErrorIs(t, err, ErrInvalidAgentJWT)
+	})
+
+	t.Run("Inactive agent", func(t *testing.T) {
+		token, err := IssueAgentJWT(kp, agentDID, nil, time.Minute)
+		require.NoError(t, err)
+
+		_, err = VerifyAgentJWT(token, newJWTTestResolver(agentDID, kp.PublicKey(), false))
+		require.ErrorIs(t, err, ErrInvalidAgentJWT)
+	})
+
+	t.Run("Grant rejected", func(t *testing.T) {
+		// A self-issued grant has sub == iss and would otherwise pass
+		grant, err := IssueGrant(kp, agentDID, agentDID, []string{"chat:write"}, time.Minute)
+		require.NoError(t, err)
+
+		_, err = VerifyAgentJWT(grant.Token, resolver)
+		require.ErrorIs(t, err, ErrInvalidAgentJWT)
+		assert.Contains(t, err.Error(), GrantTokenType)
+	})
+
+	t.Run("Other algorithms rejected", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"iss": string(agentDID),
+			"sub": string(agentDID),
+			"exp": jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		})
+		token.Header["kid"] = agentJWTKeyID(agentDID, kp.PublicKey().(ed25519.PublicKey))
+		s, err := token.SignedString([]byte(kp.PublicKey().(ed25519.PublicKey)))
+		require.NoError(t, err)
+
+		_, err = VerifyAgentJWT(s, resolver)
+		require.ErrorIs(t, err, ErrInvalidAgentJWT)
+	})
+
+	t.Run("Issue validation", func(t *testing.T) {
+		_, err := IssueAgentJWT(kp, agentDID, map[string]any{"exp": 0}, time.Minute)
+		assert.Error(t, err)
+
+		_, err = IssueAgentJWT(kp, agentDID, nil, 0)
+		assert.Error(t, err)
+
+		secp, err := keys.GenerateSecp256k1KeyPair()
+		require.NoError(t, err)
+		_, err = IssueAgentJWT(secp, agentDID, nil, time.Minute)
+		assert.ErrorIs(t, err, crypto.ErrSignNotSupported)
+	})
+}
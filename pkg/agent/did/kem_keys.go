@@ -0,0 +1,146 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"fmt"
+
+	"github.com/sage-x-project/sage/pkg/agent/crypto"
+)
+
+// KEM scheme identifiers, matching the "kem" field of the HPKE init payload.
+const (
+	KEMSchemeX25519 = "x25519"
+	KEMSchemeP256   = "p256"
+)
+
+// KEMKeyEntry is one KEM public key published by an agent.
+//
+// An agent may publish one key per scheme (for example X25519 and P-256) so
+// that peers can negotiate the scheme; see hpke.Client.WithKEMPreference.
+type KEMKeyEntry struct {
+	Scheme    string      `json:"scheme"`     // KEMSchemeX25519 or KEMSchemeP256
+	PublicKey interface{} `json:"public_key"` // raw bytes, *ecdh.PublicKey or P-256 *ecdsa.PublicKey
+}
+
+// KEMKeySetResolver is implemented by resolvers that can return every KEM
+// key of an agent rather than a single one. Inactive agents must yield
+// ErrInactiveAgent.
+type KEMKeySetResolver interface {
+	ResolveKEMKeys(ctx context.Context, did AgentDID) ([]KEMKeyEntry, error)
+}
+
+// KEMKeyResolver is the single-key KEM lookup every resolver provides.
+type KEMKeyResolver interface {
+	ResolveKEMKey(ctx context.Context, did AgentDID) (interface{}, error)
+}
+
+// ResolveKEMKeys returns all KEM keys of an agent. Resolvers implementing
+// KEMKeySetResolver are asked for the full set; others fall back to
+// ResolveKEMKey and yield a single entry.
+func ResolveKEMKeys(ctx context.Context, resolver KEMKeyResolver, did AgentDID) ([]KEMKeyEntry, error) {
+	if ks, ok := resolver.(KEMKeySetResolver); ok {
+		return ks.ResolveKEMKeys(ctx, did)
+	}
+
+	pub, err := resolver.ResolveKEMKey(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+	if pub == nil {
+		return nil, nil
+	}
+	scheme, err := KEMSchemeOf(pub)
+	if err != nil {
+		return nil, err
+	}
+	return []KEMKeyEntry{{Scheme: scheme, PublicKey: pub}}, nil
+}
+
+// KEMSchemeOf reports the KEM scheme of a public key: 32 raw bytes or an
+// X25519 *ecdh.PublicKey is X25519; 65 uncompressed bytes or a P-256 key
+// is P-256.
+func KEMSchemeOf(pub interface{}) (string, error) {
+	if kp, ok := pub.(crypto.KeyPair); ok {
+		pub = kp.PublicKey()
+	}
+
+	switch k := pub.(type) {
+	case []byte:
+		switch {
+		case len(k) == 32:
+			return KEMSchemeX25519, nil
+		case len(k) == 65 && k[0] == 0x04:
+			return KEMSchemeP256, nil
+		}
+		return "", fmt.Errorf("unsupported KEM key length: %d", len(k))
+	case *ecdh.PublicKey:
+		switch k.Curve() {
+		case ecdh.X25519():
+			return KEMSchemeX25519, nil
+		case ecdh.P256():
+			return KEMSchemeP256, nil
+		}
+		return "", fmt.Errorf("unsupported KEM curve")
+	case *ecdsa.PublicKey:
+		if k.Curve == elliptic.P256() {
+			return KEMSchemeP256, nil
+		}
+		return "", fmt.Errorf("unsupported KEM curve: %s", k.Curve.Params().Name)
+	default:
+		return "", fmt.Errorf("unsupported KEM key type: %T", pub)
+	}
+}
+
+// KEMKeyEntries returns the agent's KEM keys: PublicKEMKey first, then
+// KEMKeys. Only the first key of each scheme is kept so that selecting by
+// scheme is unambiguous.
+func (m *AgentMetadata) KEMKeyEntries() ([]KEMKeyEntry, error) {
+	out := make([]KEMKeyEntry, 0, 1+len(m.KEMKeys))
+	seen := make(map[string]bool)
+
+	if m.PublicKEMKey != nil {
+		scheme, err := KEMSchemeOf(m.PublicKEMKey)
+		if err != nil {
+			return nil, fmt.Errorf("public KEM key: %w", err)
+		}
+		out = append(out, KEMKeyEntry{Scheme: scheme, PublicKey: m.PublicKEMKey})
+		seen[scheme] = true
+	}
+
+	for _, e := range m.KEMKeys {
+		scheme, err := KEMSchemeOf(e.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("KEM key %s: %w", e.Scheme, err)
+		}
+		if e.Scheme != "" && e.Scheme != scheme {
+			return nil, fmt.Errorf("KEM key labelled %s is a %s key", e.Scheme, scheme)
+		}
+		if seen[scheme] {
+			continue
+		}
+		out = append(out, KEMKeyEntry{Scheme: scheme, PublicKey: e.PublicKey})
+		seen[scheme] = true
+	}
+	return out, nil
+}
@@ -0,0 +1,70 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentMetadata_KEMKeyEntries(t *testing.T) {
+	xPriv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	pPriv, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	xRaw, pRaw := xPriv.PublicKey().Bytes(), pPriv.PublicKey().Bytes()
+
+	m := &AgentMetadata{
+		PublicKEMKey: xRaw,
+		KEMKeys: []KEMKeyEntry{
+			{Scheme: KEMSchemeX25519, PublicKey: make([]byte, 32)}, // duplicate scheme, dropped
+			{Scheme: KEMSchemeP256, PublicKey: pRaw},
+		},
+	}
+	entries, err := m.KEMKeyEntries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, KEMKeyEntry{Scheme: KEMSchemeX25519, PublicKey: xRaw}, entries[0])
+	assert.Equal(t, KEMKeyEntry{Scheme: KEMSchemeP256, PublicKey: pRaw}, entries[1])
+
+	// A label that disagrees with the key is rejected
+	m.KEMKeys = []KEMKeyEntry{{Scheme: KEMSchemeX25519, PublicKey: pRaw}}
+	_, err = m.KEMKeyEntries()
+	require.Error(t, err)
+}
+
+func TestResolveKEMKeys_FallsBackToSingleKey(t *testing.T) {
+	const agentDID = AgentDID("did:sage:ethereum:0xagent")
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	r := new(MockResolver)
+	r.On("ResolveKEMKey", mock.Anything, agentDID).Return(priv.PublicKey(), nil)
+
+	entries, err := ResolveKEMKeys(context.Background(), r, agentDID)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, KEMSchemeP256, entries[0].Scheme)
+}
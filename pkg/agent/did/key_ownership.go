@@ -0,0 +1,190 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+)
+
+// ErrInvalidKeyOwnershipProof is returned when a key ownership proof does not
+// verify.
+var ErrInvalidKeyOwnershipProof = errors.New("invalid key ownership proof")
+
+// KeyOwnershipDomain is the registry deployment a key ownership proof is bound
+// to: the block.chainid and address(this) AgentCardRegistry mixes into every
+// proof message, so a proof cannot be replayed on another chain or registry.
+type KeyOwnershipDomain struct {
+	ChainID  *big.Int
+	Registry common.Address
+}
+
+// KeyOwnershipMessage returns the 32-byte message AgentCardRegistry's
+// _verifyKeyOwnership checks for a key of keyType with public key keyData
+// registered by owner:
+//
+//	ECDSA:   keccak256(abi.encodePacked("SAGE Agent Registration:", chainid, registry, owner))
+//	X25519:  keccak256(abi.encodePacked("SAGE X25519 Ownership:", keyData, chainid, registry, owner))
+//	Ed25519: keccak256(abi.encodePacked("SAGE Ed25519 Ownership:", keyData, chainid, registry, owner))
+//
+// The contract cannot verify Ed25519 on-chain and only checks that the proof
+// is 64 bytes; the Ed25519 message follows the X25519 layout so that
+// VerifyKeyOwnershipProof (and a future verifying oracle) can check it.
+func KeyOwnershipMessage(domain KeyOwnershipDomain, owner common.Address, keyType KeyType, keyData []byte) ([32]byte, error) {
+	chainID := domain.ChainID
+	if chainID == nil {
+		return [32]byte{}, errors.New("chain ID is required")
+	}
+	// encodePacked: uint256 is 32 bytes big-endian, address is 20 bytes
+	tail := [][]byte{common.LeftPadBytes(chainID.Bytes(), 32), domain.Registry.Bytes(), owner.Bytes()}
+
+	var prefix []byte
+	switch keyType {
+	case KeyTypeECDSA:
+		return ethcrypto.Keccak256Hash(append([][]byte{[]byte("SAGE Agent Registration:")}, tail...)...), nil
+	case KeyTypeX25519:
+		prefix = []byte("SAGE X25519 Ownership:")
+	case KeyTypeEd25519:
+		prefix = []byte("SAGE Ed25519 Ownership:")
+	default:
+		return [32]byte{}, fmt.Errorf("unsupported key type for ownership proof: %s", keyType)
+	}
+	return ethcrypto.Keccak256Hash(append([][]byte{prefix, keyData}, tail...)...), nil
+}
+
+// BuildKeyOwnershipProof returns the AgentKey.Signature that proves
+// additionalKey belongs to the agent registered by primaryKey, the
+// secp256k1 key whose address sends the registration transaction.
+// Key pairs are needed rather than AgentKeys because the proof is a
+// signature: ECDSA and X25519 keys are vouched for by the owner with an
+// EIP-191 signature, as the contract verifies, while an Ed25519 key signs the
+// message itself (Ed25519ctx, registration context) since only its holder
+// can prove possession. The registered KeyData must be the raw public key:
+// 32 bytes for Ed25519 and X25519.
+func BuildKeyOwnershipProof(domain KeyOwnershipDomain, primaryKey, additionalKey crypto.KeyPair) ([]byte, error) {
+	if primaryKey == nil || additionalKey == nil {
+		return nil, errors.New("primary and additional keys are required")
+	}
+	ownerKey, ok := primaryKey.PrivateKey().(*ecdsa.PrivateKey)
+	if !ok || primaryKey.Type() != crypto.KeyTypeSecp256k1 {
+		return nil, fmt.Errorf("primary key must be secp256k1, got %s", primaryKey.Type())
+	}
+	owner := ethcrypto.PubkeyToAddress(ownerKey.PublicKey)
+
+	keyType, keyData, err := ownershipKeyData(additionalKey.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	msg, err := KeyOwnershipMessage(domain, owner, keyType, keyData)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyType == KeyTypeEd25519 {
+		return keys.SignWithContext(additionalKey, msg[:], []byte(keys.SigningContextRegistration))
+	}
+	sig, err := ethcrypto.Sign(accounts.TextHash(msg[:]), ownerKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign ownership proof: %w", err)
+	}
+	sig[ethcrypto.RecoveryIDOffset] += 27 // v in {27, 28}, as eth_sign produces
+	return sig, nil
+}
+
+// VerifyKeyOwnershipProof checks key.Signature the way AgentCardRegistry
+// does for a key registered by owner, and additionally verifies Ed25519
+// proofs, which the contract accepts on length alone. Registries and
+// resolvers that call it can accept Ed25519 keys without owner approval.
+func VerifyKeyOwnershipProof(domain KeyOwnershipDomain, owner common.Address, key AgentKey) error {
+	msg, err := KeyOwnershipMessage(domain, owner, key.Type, key.KeyData)
+	if err != nil {
+		return err
+	}
+
+	switch key.Type {
+	case KeyTypeEd25519:
+		if len(key.KeyData) != ed25519.PublicKeySize {
+			return fmt.Errorf("%w: invalid Ed25519 key length %d", ErrInvalidKeyOwnershipProof, len(key.KeyData))
+		}
+		if len(key.Signature) != ed25519.SignatureSize {
+			return fmt.Errorf("%w: invalid Ed25519 signature", ErrInvalidKeyOwnershipProof)
+		}
+		if err := keys.VerifyWithContext(ed25519.PublicKey(key.KeyData), msg[:], key.Signature, []byte(keys.SigningContextRegistration)); err != nil {
+			return fmt.Errorf("%w: Ed25519 signature does not verify", ErrInvalidKeyOwnershipProof)
+		}
+		return nil
+
+	case KeyTypeX25519, KeyTypeECDSA:
+		if key.Type == KeyTypeX25519 && len(key.KeyData) != 32 {
+			return fmt.Errorf("%w: invalid X25519 key length %d", ErrInvalidKeyOwnershipProof, len(key.KeyData))
+		}
+		if len(key.Signature) != 65 {
+			return fmt.Errorf("%w: %s requires a 65-byte ECDSA signature by the owner", ErrInvalidKeyOwnershipProof, key.Type)
+		}
+		sig := append([]byte(nil), key.Signature...)
+		if sig[ethcrypto.RecoveryIDOffset] >= 27 {
+			sig[ethcrypto.RecoveryIDOffset] -= 27
+		}
+		pub, err := ethcrypto.SigToPub(accounts.TextHash(msg[:]), sig)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidKeyOwnershipProof, err)
+		}
+		if ethcrypto.PubkeyToAddress(*pub) != owner {
+			return fmt.Errorf("%w: signed by %s, not owner %s", ErrInvalidKeyOwnershipProof, ethcrypto.PubkeyToAddress(*pub), owner)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported key type for ownership proof: %s", key.Type)
+	}
+}
+
+// ownershipKeyData maps a public key to its registry key type and KeyData.
+func ownershipKeyData(pub interface{}) (KeyType, []byte, error) {
+	switch pk := pub.(type) {
+	case ed25519.PublicKey:
+		return KeyTypeEd25519, pk, nil
+	case *ecdh.PublicKey:
+		if pk.Curve() != ecdh.X25519() {
+			return 0, nil, fmt.Errorf("unsupported ECDH curve for ownership proof")
+		}
+		return KeyTypeX25519, pk.Bytes(), nil
+	case *ecdsa.PublicKey:
+		if pk.Curve.Params().Name != "secp256k1" {
+			return 0, nil, fmt.Errorf("unsupported ECDSA curve for ownership proof: %s", pk.Curve.Params().Name)
+		}
+		data, err := MarshalPublicKey(pk)
+		if err != nil {
+			return 0, nil, err
+		}
+		return KeyTypeECDSA, data, nil
+	default:
+		return 0, nil, fmt.Errorf("unsupported public key type for ownership proof: %T", pub)
+	}
+}
@@ -0,0 +1,186 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testOwnershipDomain = KeyOwnershipDomain{
+	ChainID:  big.NewInt(31337),
+	Registry: common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3"),
+}
+
+// contractRecover mirrors AgentCardRegistry._recoverSigner over the
+// EIP-191 hash of msg.
+func contractRecover(t *testing.T, msg [32]byte, sig []byte) common.Address {
+	t.Helper()
+	require.Len(t, sig, 65)
+	v := sig[64]
+	if v < 27 {
+		v += 27
+	}
+	require.Contains(t, []byte{27, 28}, v)
+	ethSigned := ethcrypto.Keccak256([]byte("\x19Ethereum Signed Message:\n32"), msg[:])
+	pub, err := ethcrypto.SigToPub(ethSigned, append(append([]byte(nil), sig[:64]...), v-27))
+	require.NoError(t, err)
+	return ethcrypto.PubkeyToAddress(*pub)
+}
+
+func TestKeyOwnershipMessage(t *testing.T) {
+	owner := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	x25519Key := make([]byte, 32)
+	x25519Key[0] = 0x42
+
+	// abi.encodePacked("SAGE X25519 Ownership:", keyData, block.chainid, address(this), owner)
+	packed := append([]byte("SAGE X25519 Ownership:"), x25519Key...)
+	packed = append(packed, common.LeftPadBytes(big.NewInt(31337).Bytes(), 32)...)
+	packed = append(packed, testOwnershipDomain.Registry.Bytes()...)
+	packed = append(packed, owner.Bytes()...)
+	msg, err := KeyOwnershipMessage(testOwnershipDomain, owner, KeyTypeX25519, x25519Key)
+	require.NoError(t, err)
+	assert.Equal(t, ethcrypto.Keccak256Hash(packed), common.Hash(msg))
+
+	// The ECDSA message does not cover the key, exactly as the contract
+	ecdsaA, err := KeyOwnershipMessage(testOwnershipDomain, owner, KeyTypeECDSA, []byte{1})
+	require.NoError(t, err)
+	ecdsaB, err := KeyOwnershipMessage(testOwnershipDomain, owner, KeyTypeECDSA, []byte{2})
+	require.NoError(t, err)
+	assert.Equal(t, ecdsaA, ecdsaB)
+
+	_, err = KeyOwnershipMessage(KeyOwnershipDomain{}, owner, KeyTypeECDSA, nil)
+	assert.Error(t, err)
+}
+
+func TestKeyOwnershipProof_Ed25519(t *testing.T) {
+	primary, err := keys.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+	edKP, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	owner := ownerAddress(t, primary)
+
+	proof, err := BuildKeyOwnershipProof(testOwnershipDomain, primary, edKP)
+	require.NoError(t, err)
+	// The registry accepts any 64-byte Ed25519 proof
+	assert.Len(t, proof, 64)
+
+	key := AgentKey{Type: KeyTypeEd25519, KeyData: edKP.PublicKey().(ed25519.PublicKey), Signature: proof}
+	require.NoError(t, VerifyKeyOwnershipProof(testOwnershipDomain, owner, key))
+
+	t.Run("bound to owner, chain and registry", func(t *testing.T) {
+		other := common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC")
+		assert.ErrorIs(t, VerifyKeyOwnershipProof(testOwnershipDomain, other, key), ErrInvalidKeyOwnershipProof)
+
+		otherChain := KeyOwnershipDomain{ChainID: big.NewInt(1), Registry: testOwnershipDomain.Registry}
+		assert.ErrorIs(t, VerifyKeyOwnershipProof(otherChain, owner, key), ErrInvalidKeyOwnershipProof)
+
+		otherRegistry := KeyOwnershipDomain{ChainID: testOwnershipDomain.ChainID, Registry: other}
+		assert.ErrorIs(t, VerifyKeyOwnershipProof(otherRegistry, owner, key), ErrInvalidKeyOwnershipProof)
+	})
+
+	t.Run("another key cannot reuse the proof", func(t *testing.T) {
+		otherKP, err := keys.GenerateEd25519KeyPair()
+		require.NoError(t, err)
+		swapped := key
+		swapped.KeyData = otherKP.PublicKey().(ed25519.PublicKey)
+		assert.ErrorIs(t, VerifyKeyOwnershipProof(testOwnershipDomain, owner, swapped), ErrInvalidKeyOwnershipProof)
+	})
+
+	t.Run("not a handshake or PoP signature", func(t *testing.T) {
+		msg, err := KeyOwnershipMessage(testOwnershipDomain, owner, KeyTypeEd25519, key.KeyData)
+		require.NoError(t, err)
+		plain, err := edKP.Sign(msg[:])
+		require.NoError(t, err)
+		unbound := key
+		unbound.Signature = plain
+		assert.ErrorIs(t, VerifyKeyOwnershipProof(testOwnershipDomain, owner, unbound), ErrInvalidKeyOwnershipProof)
+	})
+}
+
+func TestKeyOwnershipProof_X25519(t *testing.T) {
+	primary, err := keys.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+	xKP, err := keys.GenerateX25519KeyPair()
+	require.NoError(t, err)
+	owner := ownerAddress(t, primary)
+
+	proof, err := BuildKeyOwnershipProof(testOwnershipDomain, primary, xKP)
+	require.NoError(t, err)
+
+	keyData := xKP.PublicKey().(*ecdh.PublicKey).Bytes()
+	msg, err := KeyOwnershipMessage(testOwnershipDomain, owner, KeyTypeX25519, keyData)
+	require.NoError(t, err)
+	assert.Equal(t, owner, contractRecover(t, msg, proof))
+
+	key := AgentKey{Type: KeyTypeX25519, KeyData: keyData, Signature: proof}
+	require.NoError(t, VerifyKeyOwnershipProof(testOwnershipDomain, owner, key))
+
+	other := common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC")
+	assert.ErrorIs(t, VerifyKeyOwnershipProof(testOwnershipDomain, other, key), ErrInvalidKeyOwnershipProof)
+}
+
+func TestKeyOwnershipProof_ECDSA(t *testing.T) {
+	primary, err := keys.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+	owner := ownerAddress(t, primary)
+
+	proof, err := BuildKeyOwnershipProof(testOwnershipDomain, primary, primary)
+	require.NoError(t, err)
+	msg, err := KeyOwnershipMessage(testOwnershipDomain, owner, KeyTypeECDSA, nil)
+	require.NoError(t, err)
+	assert.Equal(t, owner, contractRecover(t, msg, proof))
+
+	keyData, err := MarshalPublicKey(primary.PublicKey())
+	require.NoError(t, err)
+	require.NoError(t, VerifyKeyOwnershipProof(testOwnershipDomain, owner, AgentKey{Type: KeyTypeECDSA, KeyData: keyData, Signature: proof}))
+}
+
+func TestBuildKeyOwnershipProof_Errors(t *testing.T) {
+	edKP, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	p256KP, err := keys.GenerateP256KeyPair()
+	require.NoError(t, err)
+	primary, err := keys.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+
+	_, err = BuildKeyOwnershipProof(testOwnershipDomain, edKP, edKP)
+	assert.ErrorContains(t, err, "secp256k1")
+	_, err = BuildKeyOwnershipProof(testOwnershipDomain, primary, p256KP)
+	assert.Error(t, err)
+	_, err = BuildKeyOwnershipProof(testOwnershipDomain, primary, nil)
+	assert.Error(t, err)
+}
+
+func ownerAddress(t *testing.T, kp crypto.KeyPair) common.Address {
+	t.Helper()
+	pub, ok := kp.PublicKey().(*ecdsa.PublicKey)
+	require.True(t, ok)
+	return ethcrypto.PubkeyToAddress(*pub)
+}
@@ -25,6 +25,7 @@ import (
 	"fmt"
 
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
 )
 
 // GenerateKeyProofOfPossession generates a signature proving ownership of a private key
@@ -57,7 +58,15 @@ func GenerateKeyProofOfPossession(did AgentDID, keyData []byte, privateKey inter
 		if !ok {
 			return nil, fmt.Errorf("invalid Ed25519 private key type")
 		}
-		signature := ed25519.Sign(ed25519Key, hash[:])
+		kp, err := keys.NewEd25519KeyPair(ed25519Key, "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid Ed25519 private key: %w", err)
+		}
+		// Ed25519ctx binds the proof to registration so it cannot double as a handshake signature
+		signature, err := keys.SignWithContext(kp, hash[:], []byte(keys.SigningContextRegistration))
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign with Ed25519: %w", err)
+		}
 		return signature, nil
 
 	case KeyTypeECDSA:
@@ -81,12 +90,25 @@ func GenerateKeyProofOfPossession(did AgentDID, keyData []byte, privateKey inter
 	}
 }
 
+// PoPVerifyOptions configures VerifyKeyProofOfPossessionWithOptions
+type PoPVerifyOptions struct {
+	// AllowLegacyEd25519 also accepts Ed25519 proofs signed with plain
+	// Ed25519, as GenerateKeyProofOfPossession produced before proofs were
+	// bound to the registration context. Enable it only while keys registered
+	// with such proofs are still in use; re-registering a key with a current
+	// proof removes the need for it.
+	AllowLegacyEd25519 bool
+}
+
 // VerifyKeyProofOfPossession verifies a proof-of-possession signature
 //
 // This function verifies that:
 //  1. The signature was created by the private key corresponding to keyData
 //  2. The signature is valid for the challenge (DID + public key)
 //
+// Ed25519 proofs must be Ed25519ctx signatures under the registration
+// context; use VerifyKeyProofOfPossessionWithOptions to accept legacy ones.
+//
 // Parameters:
 //   - did: The agent's DID
 //   - key: The agent key with signature to verify
@@ -95,6 +117,12 @@ func GenerateKeyProofOfPossession(did AgentDID, keyData []byte, privateKey inter
 //   - true if the proof is valid
 //   - Error if verification fails
 func VerifyKeyProofOfPossession(did AgentDID, key *AgentKey) error {
+	return VerifyKeyProofOfPossessionWithOptions(did, key, PoPVerifyOptions{})
+}
+
+// VerifyKeyProofOfPossessionWithOptions verifies a proof-of-possession
+// signature like VerifyKeyProofOfPossession, relaxed by opts.
+func VerifyKeyProofOfPossessionWithOptions(did AgentDID, key *AgentKey, opts PoPVerifyOptions) error {
 	if key == nil {
 		return fmt.Errorf("key cannot be nil")
 	}
@@ -114,11 +142,13 @@ func VerifyKeyProofOfPossession(did AgentDID, key *AgentKey) error {
 			return fmt.Errorf("invalid Ed25519 public key size: %d", len(key.KeyData))
 		}
 		pubKey := ed25519.PublicKey(key.KeyData)
-		valid := ed25519.Verify(pubKey, hash[:], key.Signature)
-		if !valid {
-			return fmt.Errorf("Ed25519 PoP verification failed")
+		if err := keys.VerifyWithContext(pubKey, hash[:], key.Signature, []byte(keys.SigningContextRegistration)); err == nil {
+			return nil
 		}
-		return nil
+		if opts.AllowLegacyEd25519 && ed25519.Verify(pubKey, hash[:], key.Signature) {
+			return nil
+		}
+		return fmt.Errorf("Ed25519 PoP verification failed")
 
 	case KeyTypeECDSA:
 		// Handle both compressed (33 bytes) and uncompressed formats (64 or 65 bytes)
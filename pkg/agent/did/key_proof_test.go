@@ -20,11 +20,13 @@ package did
 
 import (
 	"crypto/ed25519"
+	"crypto/sha256"
 	"testing"
 	"time"
 
 	_ "github.com/sage-x-project/sage/internal/cryptoinit" // Initialize crypto wrappers
 	"github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -319,3 +321,56 @@ func TestValidateKeyWithPoP_X25519(t *testing.T) {
 	err := ValidateKeyWithPoP(did, key)
 	assert.NoError(t, err) // Should pass without PoP verification
 }
+
+func TestVerifyKeyProofOfPossession_RejectsOtherSigningContexts(t *testing.T) {
+	keyPair, err := crypto.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	pubKey := keyPair.PublicKey().(ed25519.PublicKey)
+	privKey := keyPair.PrivateKey().(ed25519.PrivateKey)
+	did := AgentDID("did:sage:ethereum:0xcontext")
+
+	hash := sha256.Sum256(createPoPChallenge(did, pubKey))
+
+	// A handshake signature over the very same bytes must not count as a PoP
+	handshakeSig, err := keys.SignWithContext(keyPair, hash[:], []byte(keys.SigningContextHandshake))
+	require.NoError(t, err)
+	err = VerifyKeyProofOfPossession(did, &AgentKey{Type: KeyTypeEd25519, KeyData: pubKey, Signature: handshakeSig})
+	assert.Error(t, err)
+
+	// Nor does a plain, context-free Ed25519 signature
+	plainSig := ed25519.Sign(privKey, hash[:])
+	err = VerifyKeyProofOfPossession(did, &AgentKey{Type: KeyTypeEd25519, KeyData: pubKey, Signature: plainSig})
+	assert.Error(t, err)
+
+	// The registration-context proof verifies
+	popSig, err := GenerateKeyProofOfPossession(did, pubKey, privKey, KeyTypeEd25519)
+	require.NoError(t, err)
+	assert.NoError(t, keys.VerifyWithContext(pubKey, hash[:], popSig, []byte(keys.SigningContextRegistration)))
+	assert.NoError(t, VerifyKeyProofOfPossession(did, &AgentKey{Type: KeyTypeEd25519, KeyData: pubKey, Signature: popSig}))
+}
+
+func TestVerifyKeyProofOfPossession_LegacyEd25519(t *testing.T) {
+	keyPair, err := crypto.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	pubKey := keyPair.PublicKey().(ed25519.PublicKey)
+	privKey := keyPair.PrivateKey().(ed25519.PrivateKey)
+	did := AgentDID("did:sage:ethereum:0xlegacy")
+
+	// Proofs registered before the registration context was introduced
+	hash := sha256.Sum256(createPoPChallenge(did, pubKey))
+	legacy := &AgentKey{Type: KeyTypeEd25519, KeyData: pubKey, Signature: ed25519.Sign(privKey, hash[:])}
+
+	assert.Error(t, VerifyKeyProofOfPossession(did, legacy))
+	assert.NoError(t, VerifyKeyProofOfPossessionWithOptions(did, legacy, PoPVerifyOptions{AllowLegacyEd25519: true}))
+
+	// The option still rejects proofs for other keys or DIDs and other contexts
+	assert.Error(t, VerifyKeyProofOfPossessionWithOptions("did:sage:ethereum:0xother", legacy, PoPVerifyOptions{AllowLegacyEd25519: true}))
+	handshakeSig, err := keys.SignWithContext(keyPair, hash[:], []byte(keys.SigningContextHandshake))
+	require.NoError(t, err)
+	assert.Error(t, VerifyKeyProofOfPossessionWithOptions(did, &AgentKey{Type: KeyTypeEd25519, KeyData: pubKey, Signature: handshakeSig}, PoPVerifyOptions{AllowLegacyEd25519: true}))
+
+	// Current proofs verify either way
+	popSig, err := GenerateKeyProofOfPossession(did, pubKey, privKey, KeyTypeEd25519)
+	require.NoError(t, err)
+	assert.NoError(t, VerifyKeyProofOfPossessionWithOptions(did, &AgentKey{Type: KeyTypeEd25519, KeyData: pubKey, Signature: popSig}, PoPVerifyOptions{AllowLegacyEd25519: true}))
+}
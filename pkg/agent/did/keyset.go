@@ -0,0 +1,170 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/formats"
+)
+
+// VerificationKey is one currently valid signing key of an agent.
+//
+// During a key rollover an agent holds several verification keys at once:
+// the new key is added before the old one is revoked, so messages signed
+// with either key verify until the revocation lands. Verifiers pick the key
+// by ID, which is what signers put in the RFC 9421 keyid parameter.
+type VerificationKey struct {
+	ID        string      // "<did>#<fingerprint>", see KeyIDFor
+	Type      KeyType     // KeyTypeEd25519 or KeyTypeECDSA
+	PublicKey interface{} // ed25519.PublicKey or *ecdsa.PublicKey
+	CreatedAt time.Time   // When the key was added (zero if unknown)
+}
+
+// KeySetResolver is implemented by resolvers that can return every
+// currently valid signing key of an agent rather than a single primary key.
+// Revoked and unverified keys must not be returned, and inactive agents
+// must yield ErrInactiveAgent.
+type KeySetResolver interface {
+	ResolvePublicKeys(ctx context.Context, did AgentDID) ([]VerificationKey, error)
+}
+
+// PublicKeyResolver is the single-key lookup every resolver provides.
+type PublicKeyResolver interface {
+	ResolvePublicKey(ctx context.Context, did AgentDID) (interface{}, error)
+}
+
+// ResolveVerificationKeys returns the agent's currently valid signing keys.
+// Resolvers implementing KeySetResolver are asked for the full key set;
+// others fall back to ResolvePublicKey and yield a single key.
+func ResolveVerificationKeys(ctx context.Context, resolver PublicKeyResolver, did AgentDID) ([]VerificationKey, error) {
+	if ks, ok := resolver.(KeySetResolver); ok {
+		return ks.ResolvePublicKeys(ctx, did)
+	}
+
+	pub, err := resolver.ResolvePublicKey(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+	key, err := NewVerificationKey(did, pub)
+	if err != nil {
+		return nil, err
+	}
+	return []VerificationKey{key}, nil
+}
+
+// NewVerificationKey wraps a resolved public key, deriving its ID and type.
+func NewVerificationKey(did AgentDID, publicKey interface{}) (VerificationKey, error) {
+	if kp, ok := publicKey.(crypto.KeyPair); ok {
+		publicKey = kp.PublicKey()
+	}
+
+	var keyType KeyType
+	switch publicKey.(type) {
+	case ed25519.PublicKey:
+		keyType = KeyTypeEd25519
+	case *ecdsa.PublicKey:
+		keyType = KeyTypeECDSA
+	default:
+		return VerificationKey{}, fmt.Errorf("unsupported verification key type: %T", publicKey)
+	}
+
+	id, err := KeyIDFor(did, publicKey)
+	if err != nil {
+		return VerificationKey{}, err
+	}
+	return VerificationKey{ID: id, Type: keyType, PublicKey: publicKey}, nil
+}
+
+// KeyIDFor returns the key identifier "<did>#<fingerprint>" for a public key,
+// where the fingerprint is the hex-encoded first 8 bytes of SHA-256 over the
+// key's MarshalPublicKey encoding.
+func KeyIDFor(did AgentDID, publicKey interface{}) (string, error) {
+	raw, err := MarshalPublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode public key: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return string(did) + "#" + hex.EncodeToString(sum[:8]), nil
+}
+
+// SelectVerificationKey returns the key whose ID equals keyID, or failing
+// that the key whose RFC 7638 JWK thumbprint equals keyID, for peers that
+// identify keys by thumbprint (see formats.JWKThumbprint). An empty keyID
+// is accepted only when the set holds exactly one key, since otherwise the
+// choice would be ambiguous during a rollover.
+func SelectVerificationKey(keys []VerificationKey, keyID string) (VerificationKey, error) {
+	if keyID == "" {
+		if len(keys) == 1 {
+			return keys[0], nil
+		}
+		return VerificationKey{}, fmt.Errorf("keyid is required: agent has %d valid keys", len(keys))
+	}
+	for _, k := range keys {
+		if k.ID == keyID {
+			return k, nil
+		}
+	}
+	for _, k := range keys {
+		if thumbprint, err := formats.JWKThumbprint(k.PublicKey); err == nil && thumbprint == keyID {
+			return k, nil
+		}
+	}
+	return VerificationKey{}, fmt.Errorf("key %s is not a currently valid key", keyID)
+}
+
+// VerificationKeys returns the agent's verified Ed25519 and ECDSA keys as
+// verification keys. Keys that fail to decode are skipped.
+func (m *AgentMetadataV4) VerificationKeys() []VerificationKey {
+	out := make([]VerificationKey, 0, len(m.Keys))
+	for _, k := range m.Keys {
+		if !k.Verified {
+			continue
+		}
+
+		var keyType string
+		switch k.Type {
+		case KeyTypeEd25519:
+			keyType = "ed25519"
+		case KeyTypeECDSA:
+			keyType = "secp256k1"
+		default:
+			continue // X25519 keys are for key agreement, not signatures
+		}
+
+		pub, err := UnmarshalPublicKey(k.KeyData, keyType)
+		if err != nil {
+			continue
+		}
+		vk, err := NewVerificationKey(m.DID, pub)
+		if err != nil {
+			continue
+		}
+		vk.CreatedAt = k.CreatedAt
+		out = append(out, vk)
+	}
+	return out
+}
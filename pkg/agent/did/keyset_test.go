@@ -0,0 +1,168 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/formats"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// keySetResolver adds a fixed key set to MockResolver.
+type keySetResolver struct {
+	*MockResolver
+	keys []VerificationKey
+}
+
+func (r *keySetResolver) ResolvePublicKeys(ctx context.Context, did AgentDID) ([]VerificationKey, error) {
+	return r.keys, nil
+}
+
+func TestKeyIDFor(t *testing.T) {
+	const agentDID = AgentDID("did:sage:ethereum:0xagent")
+
+	pub, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	id, err := KeyIDFor(agentDID, pub)
+	require.NoError(t, err)
+	assert.Equal(t, agentJWTKeyID(agentDID, pub), id, "HTTP keyid and JWT kid must agree")
+
+	_, err = KeyIDFor(agentDID, "not a key")
+	assert.Error(t, err)
+}
+
+func TestSelectVerificationKey(t *testing.T) {
+	const agentDID = AgentDID("did:sage:ethereum:0xagent")
+
+	pub1, _, _ := ed25519.GenerateKey(nil)
+	pub2, _, _ := ed25519.GenerateKey(nil)
+	k1, err := NewVerificationKey(agentDID, pub1)
+	require.NoError(t, err)
+	k2, err := NewVerificationKey(agentDID, pub2)
+	require.NoError(t, err)
+	assert.Equal(t, KeyTypeEd25519, k1.Type)
+
+	got, err := SelectVerificationKey([]VerificationKey{k1, k2}, k2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, k2.ID, got.ID)
+
+	got, err = SelectVerificationKey([]VerificationKey{k1}, "")
+	require.NoError(t, err)
+	assert.Equal(t, k1.ID, got.ID)
+
+	_, err = SelectVerificationKey([]VerificationKey{k1, k2}, "")
+	assert.Error(t, err, "ambiguous without keyid")
+
+	_, err = SelectVerificationKey([]VerificationKey{k1}, k2.ID)
+	assert.Error(t, err)
+
+	// A JWK thumbprint keyid selects the matching key
+	thumbprint, err := formats.JWKThumbprint(pub2)
+	require.NoError(t, err)
+	got, err = SelectVerificationKey([]VerificationKey{k1, k2}, thumbprint)
+	require.NoError(t, err)
+	assert.Equal(t, k2.ID, got.ID)
+
+	_, err = SelectVerificationKey([]VerificationKey{k1}, thumbprint)
+	assert.Error(t, err)
+}
+
+func TestAgentMetadataV4_VerificationKeys(t *testing.T) {
+	edPub, _, _ := ed25519.GenerateKey(nil)
+	ecPriv, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	ecRaw, err := MarshalPublicKey(&ecPriv.PublicKey)
+	require.NoError(t, err)
+
+	created := time.Unix(1700000000, 0)
+	md := &AgentMetadataV4{
+		DID: "did:sage:ethereum:0xagent",
+		Keys: []AgentKey{
+			{Type: KeyTypeECDSA, KeyData: ecRaw, Verified: true, CreatedAt: created},
+			{Type: KeyTypeEd25519, KeyData: edPub, Verified: true},
+			{Type: KeyTypeEd25519, KeyData: make([]byte, 32), Verified: false}, // revoked
+			{Type: KeyTypeX25519, KeyData: make([]byte, 32), Verified: true},   // not a signing key
+		},
+	}
+
+	vks := md.VerificationKeys()
+	require.Len(t, vks, 2)
+	assert.Equal(t, KeyTypeECDSA, vks[0].Type)
+	assert.Equal(t, created, vks[0].CreatedAt)
+	assert.Equal(t, KeyTypeEd25519, vks[1].Type)
+	assert.Equal(t, ed25519.PublicKey(edPub), vks[1].PublicKey)
+}
+
+func TestResolveVerificationKeys_FallsBackToSingleKey(t *testing.T) {
+	const agentDID = AgentDID("did:sage:ethereum:0xagent")
+	pub, _, _ := ed25519.GenerateKey(nil)
+
+	r := new(MockResolver)
+	r.On("ResolvePublicKey", mock.Anything, agentDID).Return(pub, nil)
+
+	vks, err := ResolveVerificationKeys(context.Background(), r, agentDID)
+	require.NoError(t, err)
+	require.Len(t, vks, 1)
+	assert.Equal(t, agentJWTKeyID(agentDID, pub), vks[0].ID)
+}
+
+func TestAgentJWT_KeyRollover(t *testing.T) {
+	const agentDID = AgentDID("did:sage:ethereum:0xagent")
+
+	oldKP, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	newKP, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+
+	oldKey, err := NewVerificationKey(agentDID, oldKP.PublicKey())
+	require.NoError(t, err)
+	newKey, err := NewVerificationKey(agentDID, newKP.PublicKey())
+	require.NoError(t, err)
+
+	resolver := &keySetResolver{
+		MockResolver: newJWTTestResolver(agentDID, oldKP.PublicKey(), true),
+		keys:         []VerificationKey{oldKey, newKey},
+	}
+
+	oldToken, err := IssueAgentJWT(oldKP, agentDID, nil, time.Minute)
+	require.NoError(t, err)
+	newToken, err := IssueAgentJWT(newKP, agentDID, nil, time.Minute)
+	require.NoError(t, err)
+
+	_, err = VerifyAgentJWT(oldToken, resolver)
+	assert.NoError(t, err)
+	_, err = VerifyAgentJWT(newToken, resolver)
+	assert.NoError(t, err)
+
+	// Old key revoked
+	resolver.keys = []VerificationKey{newKey}
+	_, err = VerifyAgentJWT(oldToken, resolver)
+	assert.ErrorIs(t, err, ErrInvalidAgentJWT)
+	_, err = VerifyAgentJWT(newToken, resolver)
+	assert.NoError(t, err)
+}
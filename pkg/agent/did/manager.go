@@ -21,10 +21,12 @@ package did
 import (
 	"context"
 	"fmt"
-	"strings"
 	"sync"
+	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/sage-x-project/sage/pkg/agent/crypto"
+	"golang.org/x/sync/singleflight"
 )
 
 // EthereumV4ClientCreator is a factory function type for creating Ethereum V4 clients
@@ -50,6 +52,30 @@ func GetEthereumV4ClientCreator() EthereumV4ClientCreator {
 	return ethereumV4ClientCreator
 }
 
+// EthereumBackendClientCreator is a factory function type for creating Ethereum
+// clients on top of a caller-supplied contract backend instead of dialing RPCEndpoint
+type EthereumBackendClientCreator func(*RegistryConfig, bind.ContractBackend) (interface{}, error)
+
+var (
+	ethereumBackendClientCreator EthereumBackendClientCreator
+	ethereumBackendCreatorMu     sync.RWMutex
+)
+
+// RegisterEthereumBackendClientCreator registers a factory function for backend-based Ethereum clients
+// This is called by the ethereum package's init() function to avoid import cycles
+func RegisterEthereumBackendClientCreator(creator EthereumBackendClientCreator) {
+	ethereumBackendCreatorMu.Lock()
+	defer ethereumBackendCreatorMu.Unlock()
+	ethereumBackendClientCreator = creator
+}
+
+// GetEthereumBackendClientCreator returns the registered backend-based Ethereum client creator
+func GetEthereumBackendClientCreator() EthereumBackendClientCreator {
+	ethereumBackendCreatorMu.RLock()
+	defer ethereumBackendCreatorMu.RUnlock()
+	return ethereumBackendClientCreator
+}
+
 // Manager provides a unified interface for DID operations across multiple chains
 type Manager struct {
 	registry *MultiChainRegistry
@@ -57,8 +83,21 @@ type Manager struct {
 	verifier *MetadataVerifier
 	configs  map[Chain]*RegistryConfig
 	mu       sync.RWMutex
+
+	validator RegistrationValidator
+
+	cache  *resolutionCache
+	flight singleflight.Group // shares concurrent chain reads of one DID
+	now    func() time.Time
+
+	method string // DID method; empty means DefaultMethod
 }
 
+// RegistrationValidator enforces application policy on a registration request
+// (e.g. HTTPS endpoints, name length, required capabilities). A non-nil error
+// rejects the request before any transaction is built.
+type RegistrationValidator func(*RegistrationRequest) error
+
 // NewManager creates a new DID manager
 func NewManager() *Manager {
 	resolver := NewMultiChainResolver()
@@ -67,6 +106,8 @@ func NewManager() *Manager {
 		resolver: resolver,
 		verifier: NewMetadataVerifier(resolver),
 		configs:  make(map[Chain]*RegistryConfig),
+		cache:    newResolutionCache(),
+		now:      time.Now,
 	}
 }
 
@@ -114,6 +155,49 @@ func (m *Manager) Configure(chain Chain, config *RegistryConfig) error {
 	return nil
 }
 
+// ConfigureWithBackend adds configuration for a specific chain and initializes its
+// client on top of an existing contract backend, such as a tuned *ethclient.Client
+// or go-ethereum's simulated backend in tests. config.RPCEndpoint is not used and
+// may be empty.
+func (m *Manager) ConfigureWithBackend(chain Chain, backend bind.ContractBackend, config *RegistryConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if backend == nil {
+		return fmt.Errorf("contract backend is required")
+	}
+	if config == nil {
+		return fmt.Errorf("registry config is required")
+	}
+	if config.ContractAddress == "" {
+		return fmt.Errorf("contract address is required")
+	}
+
+	var creator EthereumBackendClientCreator
+	switch chain {
+	case ChainEthereum:
+		creator = GetEthereumBackendClientCreator()
+	default:
+		return fmt.Errorf("backend configuration not supported for chain: %s", chain)
+	}
+	if creator == nil {
+		return fmt.Errorf("no backend client creator registered for chain %s (import the chain package)", chain)
+	}
+
+	client, err := creator(config, backend)
+	if err != nil {
+		return fmt.Errorf("failed to create %s client: %w", chain, err)
+	}
+
+	m.configs[chain] = config
+	if err := m.setClientUnlocked(chain, client); err != nil {
+		delete(m.configs, chain)
+		return fmt.Errorf("failed to set %s client: %w", chain, err)
+	}
+
+	return nil
+}
+
 // setClientUnlocked sets a client without acquiring the lock (internal use only)
 func (m *Manager) setClientUnlocked(chain Chain, client interface{}) error {
 	// Verify the client implements required interfaces
@@ -170,20 +254,47 @@ func (m *Manager) SetClient(chain Chain, client interface{}) error {
 	return nil
 }
 
+// SetRegistrationValidator installs a validator that RegisterAgent runs
+// before submitting a transaction, so policy violations fail without spending gas.
+// Passing nil removes the validator.
+func (m *Manager) SetRegistrationValidator(validator RegistrationValidator) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.validator = validator
+}
+
 // RegisterAgent registers a new AI agent on the specified chain
 func (m *Manager) RegisterAgent(ctx context.Context, chain Chain, req *RegistrationRequest) (*RegistrationResult, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	if err := m.validateRegistrationLocked(req); err != nil {
+		return nil, err
+	}
+
 	return m.registry.Register(ctx, chain, req)
 }
 
-// ResolveAgent retrieves agent metadata by DID
+// validateRegistrationLocked runs the configured registration validator.
+// Callers must hold m.mu.
+func (m *Manager) validateRegistrationLocked(req *RegistrationRequest) error {
+	if m.validator == nil {
+		return nil
+	}
+	if req == nil {
+		return fmt.Errorf("registration request is nil")
+	}
+	return m.validator(req)
+}
+
+// ResolveAgent retrieves agent metadata by DID, always reading the chain.
+// The result refreshes the cache used by ResolveAgentWithOptions.
 func (m *Manager) ResolveAgent(ctx context.Context, did AgentDID) (*AgentMetadata, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	return m.resolver.Resolve(ctx, did)
+	return m.resolveAndCacheLocked(ctx, did)
 }
 
 // ResolvePublicKey retrieves only the public key for an agent
@@ -194,12 +305,26 @@ func (m *Manager) ResolvePublicKey(ctx context.Context, did AgentDID) (interface
 	return m.resolver.ResolvePublicKey(ctx, did)
 }
 
+// ResolvePublicKeys retrieves every currently valid signing key for an agent.
+// During a key rollover (AddKey followed later by RevokeKey) both the old and
+// the new key are returned.
+func (m *Manager) ResolvePublicKeys(ctx context.Context, did AgentDID) ([]VerificationKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return ResolveVerificationKeys(ctx, m.resolver, did)
+}
+
 // UpdateAgent updates agent metadata
 func (m *Manager) UpdateAgent(ctx context.Context, did AgentDID, updates map[string]interface{}, keyPair crypto.KeyPair) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	return m.registry.Update(ctx, did, updates, keyPair)
+	if err := m.registry.Update(ctx, did, updates, keyPair); err != nil {
+		return err
+	}
+	m.cache.invalidate(did)
+	return nil
 }
 
 // DeactivateAgent deactivates an agent
@@ -207,7 +332,11 @@ func (m *Manager) DeactivateAgent(ctx context.Context, did AgentDID, keyPair cry
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	return m.registry.Deactivate(ctx, did, keyPair)
+	if err := m.registry.Deactivate(ctx, did, keyPair); err != nil {
+		return err
+	}
+	m.cache.invalidate(did)
+	return nil
 }
 
 // ValidateAgent validates an agent's DID and metadata
@@ -271,29 +400,17 @@ func (m *Manager) IsChainConfigured(chain Chain) bool {
 	return exists
 }
 
-// GenerateDID generates a new DID for an agent
+// GenerateDID generates a new DID for an agent.
+// The identifier is not checked; use ValidateIdentifier first when it comes
+// from untrusted input.
 func GenerateDID(chain Chain, identifier string) AgentDID {
-	return AgentDID(fmt.Sprintf("did:sage:%s:%s", chain, identifier))
+	return GenerateDIDWithMethod(DefaultMethod, chain, identifier)
 }
 
-// ParseDID parses a DID and extracts chain and identifier
+// ParseDID parses a DID and extracts chain and identifier.
+// The identifier must satisfy ValidateIdentifier for the parsed chain.
 func ParseDID(did AgentDID) (chain Chain, identifier string, err error) {
-	parts := strings.Split(string(did), ":")
-	if len(parts) < 4 || parts[0] != "did" || parts[1] != "sage" {
-		return "", "", fmt.Errorf("invalid DID format")
-	}
-
-	switch parts[2] {
-	case "ethereum", "eth":
-		chain = ChainEthereum
-	case "solana", "sol":
-		chain = ChainSolana
-	default:
-		return "", "", fmt.Errorf("unknown chain: %s", parts[2])
-	}
-
-	identifier = strings.Join(parts[3:], ":")
-	return chain, identifier, nil
+	return ParseDIDWithMethod(did, DefaultMethod)
 }
 
 // AddKey adds a new cryptographic key to an existing agent
@@ -307,8 +424,8 @@ func (m *Manager) AddKey(ctx context.Context, chain Chain, did AgentDID, key Age
 		return "", fmt.Errorf("no registry configured for chain %s", chain)
 	}
 
-	// Check if registry supports V4 interface (key management)
-	v4Registry, ok := registry.(RegistryV4)
+	// Check if registry supports key management (see FeatureKeyManagement)
+	v4Registry, ok := registry.(keyManager)
 	if !ok {
 		return "", fmt.Errorf("registry for chain %s does not support multi-key management", chain)
 	}
@@ -328,8 +445,8 @@ func (m *Manager) RevokeKey(ctx context.Context, chain Chain, did AgentDID, keyH
 		return fmt.Errorf("no registry configured for chain %s", chain)
 	}
 
-	// Check if registry supports V4 interface (key management)
-	v4Registry, ok := registry.(RegistryV4)
+	// Check if registry supports key management (see FeatureKeyManagement)
+	v4Registry, ok := registry.(keyManager)
 	if !ok {
 		return fmt.Errorf("registry for chain %s does not support multi-key management", chain)
 	}
@@ -349,10 +466,10 @@ func (m *Manager) ApproveEd25519Key(ctx context.Context, chain Chain, keyHashStr
 		return fmt.Errorf("no registry configured for chain %s", chain)
 	}
 
-	// Check if registry supports V4 interface (key management)
-	v4Registry, ok := registry.(RegistryV4)
+	// Check if registry supports key approval (see FeatureKeyApproval)
+	v4Registry, ok := registry.(keyApprover)
 	if !ok {
-		return fmt.Errorf("registry for chain %s does not support multi-key management", chain)
+		return fmt.Errorf("registry for chain %s does not support key approval", chain)
 	}
 
 	// Call ApproveEd25519Key via V4 interface
@@ -22,6 +22,8 @@ import (
 	"context"
 	"crypto/ed25519"
 	"crypto/rand"
+	"errors"
+	"net/url"
 	"testing"
 
 	"github.com/sage-x-project/sage/tests/helpers"
@@ -458,3 +460,72 @@ func TestManagerWithMockRegistry(t *testing.T) {
 		mockResolver.AssertExpectations(t)
 	})
 }
+
+func TestManager_RegistrationValidator(t *testing.T) {
+	ctx := context.Background()
+
+	manager := NewManager()
+	mockRegistry := new(MockRegistry)
+	manager.registry.registries[ChainEthereum] = mockRegistry
+
+	errInsecureEndpoint := errors.New("endpoint must use https")
+	manager.SetRegistrationValidator(func(req *RegistrationRequest) error {
+		u, err := url.Parse(req.Endpoint)
+		if err != nil {
+			return err
+		}
+		if u.Scheme != "https" {
+			return errInsecureEndpoint
+		}
+		return nil
+	})
+
+	t.Run("Rejects http endpoint", func(t *testing.T) {
+		req := &RegistrationRequest{
+			DID:      "did:sage:ethereum:agent001",
+			Name:     "Test Agent",
+			Endpoint: "http://api.example.com",
+			KeyPair:  new(MockKeyPair),
+		}
+
+		result, err := manager.RegisterAgent(ctx, ChainEthereum, req)
+		require.ErrorIs(t, err, errInsecureEndpoint)
+		assert.Nil(t, result)
+
+		// The registry must never be reached
+		mockRegistry.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Allows https endpoint", func(t *testing.T) {
+		req := &RegistrationRequest{
+			DID:      "did:sage:ethereum:agent002",
+			Name:     "Test Agent",
+			Endpoint: "https://api.example.com",
+			KeyPair:  new(MockKeyPair),
+		}
+		expected := &RegistrationResult{TransactionHash: "0xabc123"}
+		mockRegistry.On("Register", ctx, req).Return(expected, nil).Once()
+
+		result, err := manager.RegisterAgent(ctx, ChainEthereum, req)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+
+		mockRegistry.AssertExpectations(t)
+	})
+
+	t.Run("Nil validator disables checks", func(t *testing.T) {
+		manager.SetRegistrationValidator(nil)
+
+		req := &RegistrationRequest{
+			DID:      "did:sage:ethereum:agent003",
+			Name:     "Test Agent",
+			Endpoint: "http://api.example.com",
+			KeyPair:  new(MockKeyPair),
+		}
+		mockRegistry.On("Register", ctx, req).Return(&RegistrationResult{}, nil).Once()
+
+		_, err := manager.RegisterAgent(ctx, ChainEthereum, req)
+		require.NoError(t, err)
+		mockRegistry.AssertExpectations(t)
+	})
+}
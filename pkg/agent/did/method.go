@@ -0,0 +1,126 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultMethod is the DID method used by SAGE, as in did:sage:ethereum:0x...
+const DefaultMethod = "sage"
+
+// ValidateMethod checks that method is a valid DID method name: one or more
+// lowercase letters or digits (W3C DID Core, method-name).
+func ValidateMethod(method string) error {
+	if method == "" {
+		return fmt.Errorf("DID method is required")
+	}
+	for _, r := range method {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return fmt.Errorf("invalid DID method %q: only lowercase letters and digits are allowed", method)
+		}
+	}
+	return nil
+}
+
+// methodOrDefault returns method, or DefaultMethod when it is empty
+func methodOrDefault(method string) string {
+	if method == "" {
+		return DefaultMethod
+	}
+	return method
+}
+
+// GenerateDIDWithMethod is GenerateDID for a private or white-labeled
+// deployment whose DIDs use method instead of DefaultMethod, e.g.
+// did:sagecorp:ethereum:0x...
+func GenerateDIDWithMethod(method string, chain Chain, identifier string) AgentDID {
+	return AgentDID(fmt.Sprintf("did:%s:%s:%s", methodOrDefault(method), chain, identifier))
+}
+
+// ParseDIDWithMethod is ParseDID for DIDs that use method instead of
+// DefaultMethod. DIDs of any other method are rejected.
+func ParseDIDWithMethod(did AgentDID, method string) (chain Chain, identifier string, err error) {
+	parts := strings.Split(string(did), ":")
+	if len(parts) < 4 || parts[0] != "did" || parts[1] != methodOrDefault(method) {
+		return "", "", fmt.Errorf("invalid DID format")
+	}
+
+	switch parts[2] {
+	case "ethereum", "eth":
+		chain = ChainEthereum
+	case "solana", "sol":
+		chain = ChainSolana
+	default:
+		return "", "", fmt.Errorf("unknown chain: %s", parts[2])
+	}
+
+	identifier = strings.Join(parts[3:], ":")
+	if err := ValidateIdentifier(chain, identifier); err != nil {
+		return "", "", err
+	}
+	return chain, identifier, nil
+}
+
+// SetMethod sets the DID method this manager generates, parses and resolves,
+// for private registries that issue e.g. did:sagecorp:... identifiers. The
+// default is DefaultMethod. Call it while configuring the manager, before
+// it is shared.
+func (m *Manager) SetMethod(method string) error {
+	if err := ValidateMethod(method); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.method = method
+	m.registry.method = method
+	m.resolver.method = method
+	return nil
+}
+
+// Method returns the DID method this manager uses
+func (m *Manager) Method() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return methodOrDefault(m.method)
+}
+
+// GenerateDID generates a DID for an agent using the manager's method
+func (m *Manager) GenerateDID(chain Chain, identifier string) AgentDID {
+	return GenerateDIDWithMethod(m.Method(), chain, identifier)
+}
+
+// GenerateAgentDIDWithAddress is the package-level GenerateAgentDIDWithAddress
+// using the manager's method
+func (m *Manager) GenerateAgentDIDWithAddress(chain Chain, ownerAddress string) AgentDID {
+	return generateAgentDIDWithAddress(m.Method(), chain, ownerAddress)
+}
+
+// GenerateAgentDIDWithNonce is the package-level GenerateAgentDIDWithNonce
+// using the manager's method
+func (m *Manager) GenerateAgentDIDWithNonce(chain Chain, ownerAddress string, nonce uint64) AgentDID {
+	return generateAgentDIDWithNonce(m.Method(), chain, ownerAddress, nonce)
+}
+
+// ParseDID parses a DID that uses the manager's method
+func (m *Manager) ParseDID(did AgentDID) (chain Chain, identifier string, err error) {
+	return ParseDIDWithMethod(did, m.Method())
+}
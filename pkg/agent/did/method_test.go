@@ -0,0 +1,101 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateMethod(t *testing.T) {
+	for _, method := range []string{"sage", "sagecorp", "acme2"} {
+		assert.NoError(t, ValidateMethod(method), method)
+	}
+	for _, method := range []string{"", "Sage", "sage-corp", "sage:corp", "sagé"} {
+		assert.Error(t, ValidateMethod(method), method)
+	}
+}
+
+func TestManager_CustomMethod(t *testing.T) {
+	ctx := context.Background()
+	const address = "0x742d35cc6634c0532925a3b844bc9e7595f0beef"
+
+	manager := NewManager()
+	assert.Equal(t, DefaultMethod, manager.Method())
+	assert.Error(t, manager.SetMethod("Sage-Corp"))
+	require.NoError(t, manager.SetMethod("sagecorp"))
+	assert.Equal(t, "sagecorp", manager.Method())
+
+	eth := &basicClient{}
+	sol := &basicClient{}
+	require.NoError(t, manager.Configure(ChainEthereum, &RegistryConfig{
+		ContractAddress: "0x1234567890123456789012345678901234567890",
+		RPCEndpoint:     "http://localhost:8545",
+	}))
+	require.NoError(t, manager.Configure(ChainSolana, &RegistryConfig{
+		ContractAddress: "SageRegistry11111111111111111111111111111111",
+		RPCEndpoint:     "http://localhost:8899",
+	}))
+	require.NoError(t, manager.SetClient(ChainEthereum, eth))
+	require.NoError(t, manager.SetClient(ChainSolana, sol))
+
+	agentDID := manager.GenerateDID(ChainEthereum, address)
+	assert.Equal(t, AgentDID("did:sagecorp:ethereum:"+address), agentDID)
+
+	t.Run("Parse honors the method", func(t *testing.T) {
+		chain, identifier, err := manager.ParseDID(agentDID)
+		require.NoError(t, err)
+		assert.Equal(t, ChainEthereum, chain)
+		assert.Equal(t, address, identifier)
+
+		// The default method and other methods are rejected
+		_, _, err = manager.ParseDID(GenerateDID(ChainEthereum, address))
+		assert.Error(t, err)
+		_, _, err = ParseDID(agentDID)
+		assert.Error(t, err)
+	})
+
+	t.Run("Resolution routes by chain under the custom method", func(t *testing.T) {
+		// Without the method the chain is unknown and Ethereum would be tried first
+		solDID := manager.GenerateDID(ChainSolana, "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK")
+		expected := &AgentMetadata{DID: solDID, Name: "corp agent", IsActive: true}
+		sol.MockResolver.On("Resolve", ctx, solDID).Return(expected, nil).Once()
+
+		metadata, err := manager.ResolveAgent(ctx, solDID)
+		require.NoError(t, err)
+		assert.Equal(t, expected, metadata)
+		sol.MockResolver.AssertExpectations(t)
+		eth.MockResolver.AssertNotCalled(t, "Resolve", ctx, solDID)
+	})
+
+	t.Run("Address DIDs use the custom method", func(t *testing.T) {
+		assert.Equal(t, agentDID, manager.GenerateAgentDIDWithAddress(ChainEthereum, "742D35Cc6634C0532925a3b844Bc9e7595f0bEEf"))
+		assert.Equal(t, AgentDID("did:sagecorp:ethereum:"+address+":7"), manager.GenerateAgentDIDWithNonce(ChainEthereum, address, 7))
+		assert.Equal(t, AgentDID("did:sage:ethereum:"+address), GenerateAgentDIDWithAddress(ChainEthereum, address))
+	})
+
+	t.Run("Registration prefixes the custom method", func(t *testing.T) {
+		assert.True(t, hasChainPrefix(agentDID, manager.Method(), ChainEthereum))
+		assert.False(t, hasChainPrefix(GenerateDID(ChainEthereum, address), manager.Method(), ChainEthereum))
+		assert.Equal(t, agentDID, addChainPrefix(AgentDID(address), manager.Method(), ChainEthereum))
+	})
+}
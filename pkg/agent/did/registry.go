@@ -70,6 +70,7 @@ type RegistryConfig struct {
 type MultiChainRegistry struct {
 	registries map[Chain]Registry
 	configs    map[Chain]*RegistryConfig
+	method     string // DID method; empty means DefaultMethod
 }
 
 // NewMultiChainRegistry creates a new multi-chain registry
@@ -104,8 +105,8 @@ func (m *MultiChainRegistry) Register(ctx context.Context, chain Chain, req *Reg
 	}
 
 	// Add chain prefix to DID if not present
-	if !hasChainPrefix(req.DID, chain) {
-		req.DID = addChainPrefix(req.DID, chain)
+	if !hasChainPrefix(req.DID, m.method, chain) {
+		req.DID = addChainPrefix(req.DID, m.method, chain)
 	}
 
 	return registry.Register(ctx, req)
@@ -113,7 +114,7 @@ func (m *MultiChainRegistry) Register(ctx context.Context, chain Chain, req *Reg
 
 // Update updates agent metadata on the appropriate chain
 func (m *MultiChainRegistry) Update(ctx context.Context, did AgentDID, updates map[string]interface{}, keyPair crypto.KeyPair) error {
-	chain, err := extractChainFromDID(did)
+	chain, err := extractChainFromDID(did, m.method)
 	if err != nil {
 		return err
 	}
@@ -128,7 +129,7 @@ func (m *MultiChainRegistry) Update(ctx context.Context, did AgentDID, updates m
 
 // Deactivate deactivates an agent on the appropriate chain
 func (m *MultiChainRegistry) Deactivate(ctx context.Context, did AgentDID, keyPair crypto.KeyPair) error {
-	chain, err := extractChainFromDID(did)
+	chain, err := extractChainFromDID(did, m.method)
 	if err != nil {
 		return err
 	}
@@ -172,18 +173,18 @@ func validateRegistrationRequest(req *RegistrationRequest) error {
 	return nil
 }
 
-// hasChainPrefix checks if a DID has the specified chain prefix
-func hasChainPrefix(did AgentDID, chain Chain) bool {
-	prefix := fmt.Sprintf("did:sage:%s:", chain)
+// hasChainPrefix checks if a DID has the specified method and chain prefix
+func hasChainPrefix(did AgentDID, method string, chain Chain) bool {
+	prefix := fmt.Sprintf("did:%s:%s:", methodOrDefault(method), chain)
 	return len(string(did)) > len(prefix) && string(did)[:len(prefix)] == prefix
 }
 
-// addChainPrefix adds a chain prefix to a DID
-func addChainPrefix(did AgentDID, chain Chain) AgentDID {
+// addChainPrefix adds a method and chain prefix to a DID
+func addChainPrefix(did AgentDID, method string, chain Chain) AgentDID {
 	if string(did)[:4] == "did:" {
 		// Replace existing prefix
 		parts := string(did)[4:]
-		return AgentDID(fmt.Sprintf("did:sage:%s:%s", chain, parts))
+		return GenerateDIDWithMethod(method, chain, parts)
 	}
-	return AgentDID(fmt.Sprintf("did:sage:%s:%s", chain, did))
+	return GenerateDIDWithMethod(method, chain, string(did))
 }
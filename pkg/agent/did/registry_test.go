@@ -95,6 +95,11 @@ func (m *MockKeyPair) Verify(message, signature []byte) error {
 	return args.Error(0)
 }
 
+func (m *MockKeyPair) PublicKeyEqual(other crypto.PublicKey) bool {
+	args := m.Called(other)
+	return args.Bool(0)
+}
+
 func (m *MockKeyPair) Type() sagecrypto.KeyType {
 	args := m.Called()
 	return args.Get(0).(sagecrypto.KeyType)
@@ -280,7 +285,7 @@ func TestHasChainPrefix(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := hasChainPrefix(tt.did, tt.chain)
+			result := hasChainPrefix(tt.did, "", tt.chain)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -315,7 +320,7 @@ func TestAddChainPrefix(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := addChainPrefix(tt.did, tt.chain)
+			result := addChainPrefix(tt.did, "", tt.chain)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -0,0 +1,120 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+package did
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ResolveOptions bounds how much a cached resolution may be trusted
+type ResolveOptions struct {
+	// MaxStaleness is the oldest cached entry that may be served. Older
+	// entries trigger a fresh on-chain read. Zero accepts any cached entry.
+	MaxStaleness time.Duration
+	// RequireActive fails with ErrInactiveAgent for deactivated agents
+	RequireActive bool
+}
+
+// resolutionCache holds the last on-chain read for each DID together with
+// the time it was fetched.
+type resolutionCache struct {
+	mu      sync.RWMutex
+	entries map[AgentDID]cachedResolution
+}
+
+type cachedResolution struct {
+	metadata  *AgentMetadata
+	fetchedAt time.Time
+}
+
+func newResolutionCache() *resolutionCache {
+	return &resolutionCache{entries: make(map[AgentDID]cachedResolution)}
+}
+
+func (c *resolutionCache) get(did AgentDID) (cachedResolution, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[did]
+	return entry, ok
+}
+
+func (c *resolutionCache) set(did AgentDID, metadata *AgentMetadata, fetchedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[did] = cachedResolution{metadata: metadata, fetchedAt: fetchedAt}
+}
+
+func (c *resolutionCache) invalidate(did AgentDID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, did)
+}
+
+// ResolveAgentWithOptions retrieves agent metadata by DID, serving the cached
+// result of a previous resolution when it is no older than opts.MaxStaleness
+// and reading the chain otherwise. Security-sensitive callers should set a
+// tight MaxStaleness; routine lookups can leave it zero. A nil opts behaves
+// like the zero value.
+func (m *Manager) ResolveAgentWithOptions(ctx context.Context, did AgentDID, opts *ResolveOptions) (*AgentMetadata, error) {
+	if opts == nil {
+		opts = &ResolveOptions{}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var metadata *AgentMetadata
+	if entry, ok := m.cache.get(did); ok && (opts.MaxStaleness <= 0 || m.now().Sub(entry.fetchedAt) <= opts.MaxStaleness) {
+		metadata = entry.metadata
+	} else {
+		var err error
+		metadata, err = m.resolveAndCacheLocked(ctx, did)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.RequireActive && !metadata.IsActive {
+		return nil, ErrInactiveAgent
+	}
+	return metadata, nil
+}
+
+// resolveAndCacheLocked reads the chain and records the result. Concurrent
+// reads of the same DID share one call so a cold cache does not send a
+// burst of identical RPCs. Callers must hold m.mu.
+func (m *Manager) resolveAndCacheLocked(ctx context.Context, did AgentDID) (*AgentMetadata, error) {
+	return shareCall(ctx, &m.flight, string(did), func() (*AgentMetadata, error) {
+		metadata, err := m.resolver.Resolve(ctx, did)
+		if err != nil {
+			return nil, err
+		}
+		if metadata.DID != did {
+			return nil, DIDError{
+				Code:    ErrDIDMismatch.Code,
+				Message: fmt.Sprintf("%s: requested %s, got %s", ErrDIDMismatch.Message, did, metadata.DID),
+				Details: map[string]interface{}{"requested": string(did), "returned": string(metadata.DID)},
+			}
+		}
+		m.cache.set(did, metadata, m.now())
+		return metadata, nil
+	})
+}
@@ -0,0 +1,164 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+package did
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_ResolveAgentWithOptions(t *testing.T) {
+	ctx := context.Background()
+	did := AgentDID("did:sage:ethereum:agent001")
+
+	newManager := func() (*Manager, *MockResolver, *time.Time) {
+		manager := NewManager()
+		mockResolver := new(MockResolver)
+		manager.resolver.resolvers[ChainEthereum] = mockResolver
+		now := time.Unix(1700000000, 0)
+		manager.now = func() time.Time { return now }
+		return manager, mockResolver, &now
+	}
+
+	t.Run("Fresh entry is served from cache", func(t *testing.T) {
+		manager, mockResolver, now := newManager()
+		cached := &AgentMetadata{DID: did, Name: "v1", IsActive: true}
+		mockResolver.On("Resolve", ctx, did).Return(cached, nil).Once()
+
+		opts := &ResolveOptions{MaxStaleness: time.Minute}
+		first, err := manager.ResolveAgentWithOptions(ctx, did, opts)
+		require.NoError(t, err)
+		assert.Equal(t, "v1", first.Name)
+
+		*now = now.Add(30 * time.Second)
+		second, err := manager.ResolveAgentWithOptions(ctx, did, opts)
+		require.NoError(t, err)
+		assert.Same(t, first, second)
+
+		mockResolver.AssertNumberOfCalls(t, "Resolve", 1)
+	})
+
+	t.Run("Stale entry triggers refresh", func(t *testing.T) {
+		manager, mockResolver, now := newManager()
+		mockResolver.On("Resolve", ctx, did).Return(&AgentMetadata{DID: did, Name: "v1", IsActive: true}, nil).Once()
+		mockResolver.On("Resolve", ctx, did).Return(&AgentMetadata{DID: did, Name: "v2", IsActive: true}, nil).Once()
+
+		_, err := manager.ResolveAgent(ctx, did)
+		require.NoError(t, err)
+
+		*now = now.Add(2 * time.Minute)
+		metadata, err := manager.ResolveAgentWithOptions(ctx, did, &ResolveOptions{MaxStaleness: time.Minute})
+		require.NoError(t, err)
+		assert.Equal(t, "v2", metadata.Name)
+
+		// The refreshed entry satisfies a routine lookup without another read
+		metadata, err = manager.ResolveAgentWithOptions(ctx, did, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "v2", metadata.Name)
+
+		mockResolver.AssertExpectations(t)
+		mockResolver.AssertNumberOfCalls(t, "Resolve", 2)
+	})
+
+	t.Run("Zero MaxStaleness accepts any cached entry", func(t *testing.T) {
+		manager, mockResolver, now := newManager()
+		mockResolver.On("Resolve", ctx, did).Return(&AgentMetadata{DID: did, IsActive: true}, nil).Once()
+
+		_, err := manager.ResolveAgentWithOptions(ctx, did, nil)
+		require.NoError(t, err)
+
+		*now = now.Add(24 * time.Hour)
+		_, err = manager.ResolveAgentWithOptions(ctx, did, &ResolveOptions{})
+		require.NoError(t, err)
+
+		mockResolver.AssertNumberOfCalls(t, "Resolve", 1)
+	})
+
+	t.Run("RequireActive rejects deactivated agent", func(t *testing.T) {
+		manager, mockResolver, _ := newManager()
+		mockResolver.On("Resolve", ctx, did).Return(&AgentMetadata{DID: did, IsActive: false}, nil).Once()
+
+		_, err := manager.ResolveAgentWithOptions(ctx, did, &ResolveOptions{RequireActive: true})
+		assert.Equal(t, ErrInactiveAgent, err)
+
+		metadata, err := manager.ResolveAgentWithOptions(ctx, did, nil)
+		require.NoError(t, err)
+		assert.False(t, metadata.IsActive)
+	})
+
+	t.Run("Resolution errors are not cached", func(t *testing.T) {
+		manager, mockResolver, _ := newManager()
+		mockResolver.On("Resolve", ctx, did).Return(nil, ErrDIDNotFound).Once()
+		mockResolver.On("Resolve", ctx, did).Return(&AgentMetadata{DID: did, IsActive: true}, nil).Once()
+
+		_, err := manager.ResolveAgentWithOptions(ctx, did, nil)
+		assert.Equal(t, ErrDIDNotFound, err)
+
+		_, err = manager.ResolveAgentWithOptions(ctx, did, nil)
+		require.NoError(t, err)
+		mockResolver.AssertExpectations(t)
+	})
+
+	t.Run("Deactivation invalidates cache", func(t *testing.T) {
+		manager, mockResolver, _ := newManager()
+		mockRegistry := new(MockRegistry)
+		manager.registry.registries[ChainEthereum] = mockRegistry
+		keyPair := new(MockKeyPair)
+
+		mockResolver.On("Resolve", ctx, did).Return(&AgentMetadata{DID: did, IsActive: true}, nil).Once()
+		mockResolver.On("Resolve", ctx, did).Return(&AgentMetadata{DID: did, IsActive: false}, nil).Once()
+		mockRegistry.On("Deactivate", ctx, did, keyPair).Return(nil).Once()
+
+		_, err := manager.ResolveAgentWithOptions(ctx, did, nil)
+		require.NoError(t, err)
+		require.NoError(t, manager.DeactivateAgent(ctx, did, keyPair))
+
+		_, err = manager.ResolveAgentWithOptions(ctx, did, &ResolveOptions{RequireActive: true})
+		assert.Equal(t, ErrInactiveAgent, err)
+		mockResolver.AssertExpectations(t)
+	})
+}
+
+func TestManager_ResolveAgent_DIDMismatch(t *testing.T) {
+	ctx := context.Background()
+	requested := AgentDID("did:sage:ethereum:agent001")
+	other := AgentDID("did:sage:ethereum:agent002")
+
+	manager := NewManager()
+	mockResolver := new(MockResolver)
+	manager.resolver.resolvers[ChainEthereum] = mockResolver
+	mockResolver.On("Resolve", ctx, requested).Return(&AgentMetadata{DID: other, IsActive: true}, nil)
+
+	metadata, err := manager.ResolveAgent(ctx, requested)
+	require.ErrorIs(t, err, ErrDIDMismatch)
+	assert.Nil(t, metadata)
+
+	var didErr DIDError
+	require.ErrorAs(t, err, &didErr)
+	assert.Equal(t, string(requested), didErr.Details["requested"])
+	assert.Equal(t, string(other), didErr.Details["returned"])
+
+	// The mismatched record is never cached
+	_, err = manager.ResolveAgentWithOptions(ctx, requested, nil)
+	require.ErrorIs(t, err, ErrDIDMismatch)
+	mockResolver.AssertNumberOfCalls(t, "Resolve", 2)
+}
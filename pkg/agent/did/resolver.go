@@ -21,6 +21,9 @@ package did
 import (
 	"context"
 	"fmt"
+	"strings"
+
+	"github.com/sage-x-project/sage/pkg/sagechaos"
 )
 
 // Resolver defines the interface for DID resolution
@@ -55,6 +58,7 @@ type SearchCriteria struct {
 // MultiChainResolver aggregates multiple chain-specific resolvers
 type MultiChainResolver struct {
 	resolvers map[Chain]Resolver
+	method    string // DID method; empty means DefaultMethod
 }
 
 // NewMultiChainResolver creates a new multi-chain resolver
@@ -71,7 +75,11 @@ func (m *MultiChainResolver) AddResolver(chain Chain, resolver Resolver) {
 
 // Resolve attempts to resolve a DID across all configured chains
 func (m *MultiChainResolver) Resolve(ctx context.Context, did AgentDID) (*AgentMetadata, error) {
-	chain, err := extractChainFromDID(did)
+	if err := sagechaos.Fail(sagechaos.DIDResolve); err != nil {
+		return nil, err
+	}
+
+	chain, err := extractChainFromDID(did, m.method)
 	if err != nil {
 		// Try all chains if chain cannot be determined from DID
 		// Try in deterministic order: ethereum first, then others alphabetically
@@ -111,6 +119,31 @@ func (m *MultiChainResolver) ResolvePublicKey(ctx context.Context, did AgentDID)
 	return metadata.PublicKey, nil
 }
 
+// ResolvePublicKeys retrieves all currently valid signing keys for an agent.
+// Chain resolvers without key-set support yield their single public key.
+func (m *MultiChainResolver) ResolvePublicKeys(ctx context.Context, did AgentDID) ([]VerificationKey, error) {
+	if err := sagechaos.Fail(sagechaos.DIDResolve); err != nil {
+		return nil, err
+	}
+	if chain, err := extractChainFromDID(did, m.method); err == nil {
+		resolver, exists := m.resolvers[chain]
+		if !exists {
+			return nil, fmt.Errorf("no resolver for chain %s", chain)
+		}
+		return ResolveVerificationKeys(ctx, resolver, did)
+	}
+
+	pub, err := m.ResolvePublicKey(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+	key, err := NewVerificationKey(did, pub)
+	if err != nil {
+		return nil, err
+	}
+	return []VerificationKey{key}, nil
+}
+
 // ResolvePublicKey retrieves the KEM key for an agent from any chain (for RFC 9180)
 func (m *MultiChainResolver) ResolveKEMKey(ctx context.Context, did AgentDID) (interface{}, error) {
 	metadata, err := m.Resolve(ctx, did)
@@ -125,9 +158,23 @@ func (m *MultiChainResolver) ResolveKEMKey(ctx context.Context, did AgentDID) (i
 	return metadata.PublicKEMKey, nil
 }
 
+// ResolveKEMKeys retrieves every KEM key an agent publishes, one per scheme
+func (m *MultiChainResolver) ResolveKEMKeys(ctx context.Context, did AgentDID) ([]KEMKeyEntry, error) {
+	metadata, err := m.Resolve(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+
+	if !metadata.IsActive {
+		return nil, ErrInactiveAgent
+	}
+
+	return metadata.KEMKeyEntries()
+}
+
 // VerifyMetadata verifies metadata against on-chain data
 func (m *MultiChainResolver) VerifyMetadata(ctx context.Context, did AgentDID, metadata *AgentMetadata) (*VerificationResult, error) {
-	chain, err := extractChainFromDID(did)
+	chain, err := extractChainFromDID(did, m.method)
 	if err != nil {
 		return nil, err
 	}
@@ -177,16 +224,17 @@ func (m *MultiChainResolver) Search(ctx context.Context, criteria SearchCriteria
 }
 
 // extractChainFromDID attempts to determine the chain from a DID
-// Format: did:sage:chain:identifier
-func extractChainFromDID(did AgentDID) (Chain, error) {
+// Format: did:<method>:chain:identifier, where method defaults to DefaultMethod
+func extractChainFromDID(did AgentDID, method string) (Chain, error) {
 	didStr := string(did)
 	if len(didStr) < 10 || didStr[:4] != "did:" {
 		return "", fmt.Errorf("invalid DID format")
 	}
 
 	// Simple extraction - can be enhanced based on actual DID format
-	if len(didStr) > 14 && didStr[4:9] == "sage:" {
-		parts := didStr[9:]
+	prefix := "did:" + methodOrDefault(method) + ":"
+	if len(didStr) > len(prefix)+5 && strings.HasPrefix(didStr, prefix) {
+		parts := didStr[len(prefix):]
 		if len(parts) > 4 {
 			switch parts[:3] {
 			case "eth":
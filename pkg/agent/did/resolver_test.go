@@ -261,7 +261,7 @@ func TestExtractChainFromDID(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			chain, err := extractChainFromDID(tt.did)
+			chain, err := extractChainFromDID(tt.did, "")
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -0,0 +1,442 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sage-x-project/sage/pkg/agent/crypto"
+)
+
+// ErrRevoked is returned for DIDs and keys listed in the revocation list
+var ErrRevoked = DIDError{Code: "REVOKED", Message: "DID or key revoked by revocation list"}
+
+// RevocationList is an operator-signed list of revoked DIDs and keys. It lets
+// operators cut off agents during an incident without sending a transaction
+// per key; on-chain revocation remains the durable record.
+type RevocationList struct {
+	// Version increases with every update; older lists are never accepted
+	Version  uint64    `json:"version"`
+	IssuedAt time.Time `json:"issuedAt"`
+	// RevokedDIDs are rejected outright
+	RevokedDIDs []AgentDID `json:"revokedDids,omitempty"`
+	// RevokedKeys holds RevocationKeyHash values of individual keys
+	RevokedKeys []string `json:"revokedKeys,omitempty"`
+	// Signature is the issuer's Ed25519 signature over the list without it
+	Signature []byte `json:"signature,omitempty"`
+}
+
+// RevocationKeyHash returns the identifier a RevocationList uses for a key:
+// the hex-encoded SHA-256 of its MarshalPublicKey encoding. KEM keys, given
+// as raw bytes or an *ecdh.PublicKey, are hashed in their raw encoding as
+// published in the agent's metadata.
+func RevocationKeyHash(publicKey interface{}) (string, error) {
+	if kp, ok := publicKey.(crypto.KeyPair); ok {
+		publicKey = kp.PublicKey()
+	}
+	var raw []byte
+	switch k := publicKey.(type) {
+	case []byte:
+		raw = k
+	case *ecdh.PublicKey:
+		raw = k.Bytes()
+	default:
+		var err error
+		if raw, err = MarshalPublicKey(publicKey); err != nil {
+			return "", fmt.Errorf("failed to encode public key: %w", err)
+		}
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Sign sets the list's signature using the issuer key
+func (l *RevocationList) Sign(issuer ed25519.PrivateKey) error {
+	payload, err := l.signingPayload()
+	if err != nil {
+		return err
+	}
+	l.Signature = ed25519.Sign(issuer, payload)
+	return nil
+}
+
+// Verify checks the list's signature against the issuer public key
+func (l *RevocationList) Verify(issuer ed25519.PublicKey) error {
+	if len(l.Signature) == 0 {
+		return fmt.Errorf("revocation list is not signed")
+	}
+	payload, err := l.signingPayload()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(issuer, payload, l.Signature) {
+		return fmt.Errorf("revocation list signature verification failed")
+	}
+	return nil
+}
+
+func (l *RevocationList) signingPayload() ([]byte, error) {
+	unsigned := *l
+	unsigned.Signature = nil
+	payload, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal revocation list: %w", err)
+	}
+	return payload, nil
+}
+
+// RevocationConfig configures a RevocationResolver
+type RevocationConfig struct {
+	// URL serves the signed list as JSON; empty disables Refresh and the
+	// list is only changed through Update
+	URL string
+	// IssuerKey verifies list signatures (required)
+	IssuerKey ed25519.PublicKey
+	// RefreshInterval is how often Start refreshes the list (default 5m)
+	RefreshInterval time.Duration
+	// HTTPClient fetches the list; nil uses a client with a 10 second timeout
+	HTTPClient *http.Client
+	// MaxSize bounds the fetched list in bytes (default 4 MiB)
+	MaxSize int64
+	// OnRefreshError is called when a background refresh fails. The last
+	// accepted list stays in force.
+	OnRefreshError func(error)
+}
+
+// RevocationResolver wraps a Resolver and rejects DIDs and keys listed in
+// the current RevocationList, even when they are active on-chain. Until the
+// first list is accepted nothing is rejected; callers that must fail closed
+// should call Refresh before serving traffic.
+type RevocationResolver struct {
+	inner Resolver
+	cfg   RevocationConfig
+
+	mu      sync.RWMutex
+	version uint64
+	loaded  bool
+	dids    map[AgentDID]struct{}
+	keys    map[string]struct{}
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+var (
+	_ Resolver          = (*RevocationResolver)(nil)
+	_ KeySetResolver    = (*RevocationResolver)(nil)
+	_ KEMKeySetResolver = (*RevocationResolver)(nil)
+)
+
+// NewRevocationResolver wraps inner with revocation list checks
+func NewRevocationResolver(inner Resolver, cfg RevocationConfig) (*RevocationResolver, error) {
+	if len(cfg.IssuerKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("revocation list issuer key is required")
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = 5 * time.Minute
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = 4 << 20
+	}
+	return &RevocationResolver{
+		inner: inner,
+		cfg:   cfg,
+		dids:  make(map[AgentDID]struct{}),
+		keys:  make(map[string]struct{}),
+		stop:  make(chan struct{}),
+	}, nil
+}
+
+// Version returns the version of the list in force, or 0 before the first
+// list is accepted
+func (r *RevocationResolver) Version() uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.version
+}
+
+// Update verifies list and puts it in force. A list older than the current
+// one is rejected so a replayed list cannot lift revocations; a list with
+// the current version is ignored.
+func (r *RevocationResolver) Update(list *RevocationList) error {
+	if list == nil {
+		return fmt.Errorf("revocation list cannot be nil")
+	}
+	if err := list.Verify(r.cfg.IssuerKey); err != nil {
+		return err
+	}
+
+	dids := make(map[AgentDID]struct{}, len(list.RevokedDIDs))
+	for _, d := range list.RevokedDIDs {
+		dids[d] = struct{}{}
+	}
+	keys := make(map[string]struct{}, len(list.RevokedKeys))
+	for _, k := range list.RevokedKeys {
+		keys[k] = struct{}{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.loaded {
+		if list.Version < r.version {
+			return fmt.Errorf("revocation list version %d is older than current version %d", list.Version, r.version)
+		}
+		if list.Version == r.version {
+			return nil
+		}
+	}
+	r.version = list.Version
+	r.loaded = true
+	r.dids = dids
+	r.keys = keys
+	return nil
+}
+
+// Refresh fetches the list from the configured URL and applies it with Update
+func (r *RevocationResolver) Refresh(ctx context.Context) error {
+	if r.cfg.URL == "" {
+		return fmt.Errorf("revocation list URL not configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.cfg.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create revocation list request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := r.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("revocation list request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("revocation list request failed: HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, r.cfg.MaxSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read revocation list: %w", err)
+	}
+	if int64(len(body)) > r.cfg.MaxSize {
+		return fmt.Errorf("revocation list too large: exceeds limit of %d bytes", r.cfg.MaxSize)
+	}
+
+	var list RevocationList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return fmt.Errorf("invalid revocation list JSON: %w", err)
+	}
+	return r.Update(&list)
+}
+
+// Start refreshes the list immediately and then every RefreshInterval until
+// ctx is done or Stop is called. Failures are reported to OnRefreshError.
+// It fails without starting when no URL is configured.
+func (r *RevocationResolver) Start(ctx context.Context) error {
+	if r.cfg.URL == "" {
+		return fmt.Errorf("revocation list URL not configured")
+	}
+	go func() {
+		ticker := time.NewTicker(r.cfg.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			if err := r.Refresh(ctx); err != nil && r.cfg.OnRefreshError != nil {
+				r.cfg.OnRefreshError(err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-r.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop ends background refreshing started by Start
+func (r *RevocationResolver) Stop() {
+	r.stopOnce.Do(func() { close(r.stop) })
+}
+
+// IsRevoked reports whether did is in the current list
+func (r *RevocationResolver) IsRevoked(did AgentDID) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.dids[did]
+	return ok
+}
+
+// IsKeyRevoked reports whether publicKey is in the current list
+func (r *RevocationResolver) IsKeyRevoked(publicKey interface{}) bool {
+	hash, err := RevocationKeyHash(publicKey)
+	if err != nil {
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.keys[hash]
+	return ok
+}
+
+// checkDID returns ErrRevoked if did is in the current list
+func (r *RevocationResolver) checkDID(did AgentDID) error {
+	if r.IsRevoked(did) {
+		return ErrRevoked
+	}
+	return nil
+}
+
+// Resolve retrieves agent metadata unless the DID or its key is revoked
+func (r *RevocationResolver) Resolve(ctx context.Context, did AgentDID) (*AgentMetadata, error) {
+	if err := r.checkDID(did); err != nil {
+		return nil, err
+	}
+	metadata, err := r.inner.Resolve(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.PublicKey != nil && r.IsKeyRevoked(metadata.PublicKey) {
+		return nil, ErrRevoked
+	}
+	return metadata, nil
+}
+
+// ResolvePublicKey retrieves the public key unless the DID or the key is revoked
+func (r *RevocationResolver) ResolvePublicKey(ctx context.Context, did AgentDID) (interface{}, error) {
+	if err := r.checkDID(did); err != nil {
+		return nil, err
+	}
+	pub, err := r.inner.ResolvePublicKey(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+	if r.IsKeyRevoked(pub) {
+		return nil, ErrRevoked
+	}
+	return pub, nil
+}
+
+// ResolvePublicKeys retrieves the valid signing keys that are not revoked.
+// It fails with ErrRevoked when every key is revoked.
+func (r *RevocationResolver) ResolvePublicKeys(ctx context.Context, did AgentDID) ([]VerificationKey, error) {
+	if err := r.checkDID(did); err != nil {
+		return nil, err
+	}
+	keys, err := ResolveVerificationKeys(ctx, r.inner, did)
+	if err != nil {
+		return nil, err
+	}
+	valid := make([]VerificationKey, 0, len(keys))
+	for _, k := range keys {
+		if !r.IsKeyRevoked(k.PublicKey) {
+			valid = append(valid, k)
+		}
+	}
+	if len(valid) == 0 && len(keys) > 0 {
+		return nil, ErrRevoked
+	}
+	return valid, nil
+}
+
+// ResolveKEMKey retrieves the KEM key unless the DID or the key is revoked
+func (r *RevocationResolver) ResolveKEMKey(ctx context.Context, did AgentDID) (interface{}, error) {
+	if err := r.checkDID(did); err != nil {
+		return nil, err
+	}
+	pub, err := r.inner.ResolveKEMKey(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+	if pub != nil && r.IsKeyRevoked(pub) {
+		return nil, ErrRevoked
+	}
+	return pub, nil
+}
+
+// ResolveKEMKeys retrieves the KEM keys that are not revoked. It fails with
+// ErrRevoked when every key is revoked.
+func (r *RevocationResolver) ResolveKEMKeys(ctx context.Context, did AgentDID) ([]KEMKeyEntry, error) {
+	if err := r.checkDID(did); err != nil {
+		return nil, err
+	}
+	entries, err := ResolveKEMKeys(ctx, r.inner, did)
+	if err != nil {
+		return nil, err
+	}
+	valid := make([]KEMKeyEntry, 0, len(entries))
+	for _, e := range entries {
+		if !r.IsKeyRevoked(e.PublicKey) {
+			valid = append(valid, e)
+		}
+	}
+	if len(valid) == 0 && len(entries) > 0 {
+		return nil, ErrRevoked
+	}
+	return valid, nil
+}
+
+// VerifyMetadata verifies metadata unless the DID is revoked
+func (r *RevocationResolver) VerifyMetadata(ctx context.Context, did AgentDID, metadata *AgentMetadata) (*VerificationResult, error) {
+	if err := r.checkDID(did); err != nil {
+		return nil, err
+	}
+	return r.inner.VerifyMetadata(ctx, did, metadata)
+}
+
+// ListAgentsByOwner lists the owner's agents, omitting revoked DIDs
+func (r *RevocationResolver) ListAgentsByOwner(ctx context.Context, ownerAddress string) ([]*AgentMetadata, error) {
+	agents, err := r.inner.ListAgentsByOwner(ctx, ownerAddress)
+	if err != nil {
+		return nil, err
+	}
+	return r.filter(agents), nil
+}
+
+// Search finds agents matching criteria, omitting revoked DIDs
+func (r *RevocationResolver) Search(ctx context.Context, criteria SearchCriteria) ([]*AgentMetadata, error) {
+	agents, err := r.inner.Search(ctx, criteria)
+	if err != nil {
+		return nil, err
+	}
+	return r.filter(agents), nil
+}
+
+func (r *RevocationResolver) filter(agents []*AgentMetadata) []*AgentMetadata {
+	out := make([]*AgentMetadata, 0, len(agents))
+	for _, a := range agents {
+		if a != nil && !r.IsRevoked(a.DID) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
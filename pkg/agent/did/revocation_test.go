@@ -0,0 +1,176 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevocationResolver(t *testing.T) {
+	ctx := context.Background()
+	issuerPub, issuerPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	revokedDID := AgentDID("did:sage:ethereum:revoked")
+	goodDID := AgentDID("did:sage:ethereum:good")
+	rotatedDID := AgentDID("did:sage:ethereum:rotated")
+	revokedKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	goodKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	// Every agent is active on-chain
+	inner := new(MockResolver)
+	for did, key := range map[AgentDID]ed25519.PublicKey{revokedDID: goodKey, goodDID: goodKey, rotatedDID: revokedKey} {
+		inner.On("Resolve", ctx, did).Return(&AgentMetadata{DID: did, IsActive: true, PublicKey: key}, nil).Maybe()
+		inner.On("ResolvePublicKey", ctx, did).Return(key, nil).Maybe()
+	}
+	inner.On("Search", ctx, SearchCriteria{}).Return([]*AgentMetadata{{DID: revokedDID}, {DID: goodDID}}, nil).Maybe()
+
+	keyHash, err := RevocationKeyHash(revokedKey)
+	require.NoError(t, err)
+	signed := func(version uint64, dids []AgentDID, keys []string) *RevocationList {
+		list := &RevocationList{Version: version, IssuedAt: time.Now().UTC(), RevokedDIDs: dids, RevokedKeys: keys}
+		require.NoError(t, list.Sign(issuerPriv))
+		return list
+	}
+
+	// Serve the current list over HTTP
+	var current atomic.Pointer[RevocationList]
+	current.Store(signed(1, []AgentDID{revokedDID}, []string{keyHash}))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(current.Load())
+	}))
+	defer server.Close()
+
+	resolver, err := NewRevocationResolver(inner, RevocationConfig{URL: server.URL, IssuerKey: issuerPub})
+	require.NoError(t, err)
+
+	t.Run("Nothing is rejected before the first list", func(t *testing.T) {
+		_, err := resolver.ResolvePublicKey(ctx, revokedDID)
+		assert.NoError(t, err)
+	})
+
+	require.NoError(t, resolver.Refresh(ctx))
+	require.Equal(t, uint64(1), resolver.Version())
+
+	t.Run("Listed DID is rejected although active on-chain", func(t *testing.T) {
+		_, err := resolver.ResolvePublicKey(ctx, revokedDID)
+		assert.ErrorIs(t, err, ErrRevoked)
+		_, err = resolver.Resolve(ctx, revokedDID)
+		assert.ErrorIs(t, err, ErrRevoked)
+	})
+
+	t.Run("Listed key is rejected", func(t *testing.T) {
+		_, err := resolver.ResolvePublicKey(ctx, rotatedDID)
+		assert.ErrorIs(t, err, ErrRevoked)
+		_, err = resolver.ResolvePublicKeys(ctx, rotatedDID)
+		assert.ErrorIs(t, err, ErrRevoked)
+	})
+
+	t.Run("Other agents resolve", func(t *testing.T) {
+		pub, err := resolver.ResolvePublicKey(ctx, goodDID)
+		require.NoError(t, err)
+		assert.Equal(t, goodKey, pub)
+
+		agents, err := resolver.Search(ctx, SearchCriteria{})
+		require.NoError(t, err)
+		require.Len(t, agents, 1)
+		assert.Equal(t, goodDID, agents[0].DID)
+	})
+
+	t.Run("Rejects unsigned, forged and older lists", func(t *testing.T) {
+		assert.Error(t, resolver.Update(&RevocationList{Version: 5}))
+
+		forged := signed(5, nil, nil)
+		forged.RevokedDIDs = []AgentDID{goodDID}
+		assert.Error(t, resolver.Update(forged))
+
+		require.NoError(t, resolver.Update(signed(3, []AgentDID{revokedDID}, nil)))
+		err := resolver.Update(signed(2, nil, nil))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "older than current version")
+		assert.True(t, resolver.IsRevoked(revokedDID))
+	})
+
+	t.Run("Start refreshes on an interval", func(t *testing.T) {
+		refreshing, err := NewRevocationResolver(inner, RevocationConfig{
+			URL:             server.URL,
+			IssuerKey:       issuerPub,
+			RefreshInterval: 10 * time.Millisecond,
+		})
+		require.NoError(t, err)
+		require.NoError(t, refreshing.Start(ctx))
+		defer refreshing.Stop()
+
+		require.Eventually(t, func() bool { return refreshing.Version() == 1 }, time.Second, 5*time.Millisecond)
+
+		// Lifting a revocation takes effect on the next refresh
+		current.Store(signed(10, nil, nil))
+		require.Eventually(t, func() bool { return refreshing.Version() == 10 }, time.Second, 5*time.Millisecond)
+		_, err = refreshing.ResolvePublicKey(ctx, revokedDID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Listed KEM key is rejected", func(t *testing.T) {
+		kemDID := AgentDID("did:sage:ethereum:kem")
+		revokedKEM, err := ecdh.X25519().GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		kemInner := new(MockResolver)
+		// Published as raw bytes, listed by the hash of its *ecdh.PublicKey
+		kemInner.On("ResolveKEMKey", ctx, kemDID).Return(revokedKEM.PublicKey().Bytes(), nil)
+		kemHash, err := RevocationKeyHash(revokedKEM.PublicKey())
+		require.NoError(t, err)
+
+		kemResolver, err := NewRevocationResolver(kemInner, RevocationConfig{IssuerKey: issuerPub})
+		require.NoError(t, err)
+		_, err = kemResolver.ResolveKEMKey(ctx, kemDID)
+		require.NoError(t, err)
+
+		require.NoError(t, kemResolver.Update(signed(1, nil, []string{kemHash})))
+		_, err = kemResolver.ResolveKEMKey(ctx, kemDID)
+		assert.ErrorIs(t, err, ErrRevoked)
+		_, err = kemResolver.ResolveKEMKeys(ctx, kemDID)
+		assert.ErrorIs(t, err, ErrRevoked)
+	})
+
+	t.Run("Start requires a URL", func(t *testing.T) {
+		local, err := NewRevocationResolver(inner, RevocationConfig{IssuerKey: issuerPub})
+		require.NoError(t, err)
+		assert.Error(t, local.Start(ctx))
+	})
+
+	t.Run("Requires an issuer key", func(t *testing.T) {
+		_, err := NewRevocationResolver(inner, RevocationConfig{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,121 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+package did
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// SafeOperation is the Safe execution mode of a transaction
+type SafeOperation uint8
+
+const (
+	// SafeOperationCall executes the transaction as a regular CALL
+	SafeOperationCall SafeOperation = 0
+	// SafeOperationDelegateCall executes the transaction as a DELEGATECALL
+	SafeOperationDelegateCall SafeOperation = 1
+)
+
+// SafeTransaction is a registry transaction prepared for execution by a Safe
+// (Gnosis Safe) multisig. Owners co-sign SafeTxHash and submit the signatures
+// with execTransaction, at which point the Safe becomes the agent's owner.
+type SafeTransaction struct {
+	Chain          Chain         `json:"chain"`
+	ChainID        *big.Int      `json:"chainId"`
+	Safe           string        `json:"safe"`
+	To             string        `json:"to"`
+	Value          *big.Int      `json:"value"`
+	Data           []byte        `json:"data"`
+	Operation      SafeOperation `json:"operation"`
+	SafeTxGas      *big.Int      `json:"safeTxGas"`
+	BaseGas        *big.Int      `json:"baseGas"`
+	GasPrice       *big.Int      `json:"gasPrice"`
+	GasToken       string        `json:"gasToken"`
+	RefundReceiver string        `json:"refundReceiver"`
+	Nonce          *big.Int      `json:"nonce"`
+	SafeTxHash     string        `json:"safeTxHash"` // EIP-712 hash the owners sign
+}
+
+// SafeRegistry is implemented by registries that support agents owned by a
+// Safe multisig instead of a single externally owned account.
+type SafeRegistry interface {
+	// PrepareSafeRegistration builds the registration transaction for
+	// execution by safeAddress without submitting it
+	PrepareSafeRegistration(ctx context.Context, req *RegistrationRequest, safeAddress string) (*SafeTransaction, error)
+
+	// IsSafeOwner reports whether account is one of the owners of
+	// safeAddress. It returns false when safeAddress is not a Safe.
+	IsSafeOwner(ctx context.Context, safeAddress, account string) (bool, error)
+}
+
+// RegisterViaSafe prepares an agent registration to be executed by the Safe
+// at safeAddr. Nothing is sent on-chain; the returned SafeTxHash must be
+// co-signed by the Safe owners and executed through the Safe.
+func (m *Manager) RegisterViaSafe(ctx context.Context, chain Chain, req *RegistrationRequest, safeAddr string) (*SafeTransaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if err := m.validateRegistrationLocked(req); err != nil {
+		return nil, err
+	}
+
+	registry := m.registry.GetRegistry(chain)
+	if registry == nil {
+		return nil, fmt.Errorf("no registry configured for chain %s", chain)
+	}
+
+	safeRegistry, ok := registry.(SafeRegistry)
+	if !ok {
+		return nil, fmt.Errorf("registry for chain %s does not support Safe-owned agents", chain)
+	}
+
+	return safeRegistry.PrepareSafeRegistration(ctx, req, safeAddr)
+}
+
+// VerifyOwnership reports whether account controls the agent. The account
+// must either be the on-chain owner itself or, for Safe-owned agents, one of
+// the Safe's owners. Ownership is always checked against a fresh read.
+func (m *Manager) VerifyOwnership(ctx context.Context, did AgentDID, account string) (bool, error) {
+	chain, _, err := m.ParseDID(did)
+	if err != nil {
+		return false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	metadata, err := m.resolveAndCacheLocked(ctx, did)
+	if err != nil {
+		return false, err
+	}
+	if metadata.Owner == "" || account == "" {
+		return false, nil
+	}
+	if strings.EqualFold(metadata.Owner, account) {
+		return true, nil
+	}
+
+	safeRegistry, ok := m.registry.GetRegistry(chain).(SafeRegistry)
+	if !ok {
+		return false, nil
+	}
+	return safeRegistry.IsSafeOwner(ctx, metadata.Owner, account)
+}
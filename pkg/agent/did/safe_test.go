@@ -0,0 +1,58 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+package did
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_SafeWithoutSupport(t *testing.T) {
+	ctx := context.Background()
+	agentDID := AgentDID("did:sage:ethereum:agent001")
+	owner := "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+
+	manager := NewManager()
+	mockResolver := new(MockResolver)
+	manager.registry.registries[ChainEthereum] = new(MockRegistry)
+	manager.resolver.resolvers[ChainEthereum] = mockResolver
+
+	t.Run("RegisterViaSafe requires a SafeRegistry", func(t *testing.T) {
+		_, err := manager.RegisterViaSafe(ctx, ChainEthereum, &RegistrationRequest{DID: agentDID}, owner)
+		assert.ErrorContains(t, err, "does not support Safe-owned agents")
+
+		_, err = manager.RegisterViaSafe(ctx, ChainSolana, &RegistrationRequest{DID: agentDID}, owner)
+		assert.ErrorContains(t, err, "no registry configured")
+	})
+
+	t.Run("VerifyOwnership falls back to direct owner match", func(t *testing.T) {
+		mockResolver.On("Resolve", ctx, agentDID).
+			Return(&AgentMetadata{DID: agentDID, Owner: owner, IsActive: true}, nil)
+
+		ok, err := manager.VerifyOwnership(ctx, agentDID, "0xF39FD6E51AAD88F6F4CE6AB8827279CFFFB92266")
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = manager.VerifyOwnership(ctx, agentDID, "0x0000000000000000000000000000000000000b0b")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,129 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// SingleFlightResolver wraps a Resolver so concurrent lookups of the same DID
+// share one in-flight call to the underlying resolver. When many handshakes
+// for an uncached DID arrive at once, only the first reaches the RPC endpoint
+// and the rest wait for its result.
+//
+// Callers of the same lookup receive the same result, so returned metadata
+// and key slices must be treated as read-only. The shared call runs under
+// the first caller's context; if it fails because that context ended,
+// waiters whose contexts are still live retry on their own.
+type SingleFlightResolver struct {
+	inner Resolver
+	group singleflight.Group
+}
+
+var (
+	_ Resolver          = (*SingleFlightResolver)(nil)
+	_ KeySetResolver    = (*SingleFlightResolver)(nil)
+	_ KEMKeySetResolver = (*SingleFlightResolver)(nil)
+)
+
+// NewSingleFlightResolver wraps inner with per-DID call deduplication
+func NewSingleFlightResolver(inner Resolver) *SingleFlightResolver {
+	return &SingleFlightResolver{inner: inner}
+}
+
+// Resolve retrieves agent metadata, sharing concurrent calls for the same DID
+func (r *SingleFlightResolver) Resolve(ctx context.Context, did AgentDID) (*AgentMetadata, error) {
+	return shareCall(ctx, &r.group, "resolve:"+string(did), func() (*AgentMetadata, error) {
+		return r.inner.Resolve(ctx, did)
+	})
+}
+
+// ResolvePublicKey retrieves the public key, sharing concurrent calls for the same DID
+func (r *SingleFlightResolver) ResolvePublicKey(ctx context.Context, did AgentDID) (interface{}, error) {
+	return shareCall(ctx, &r.group, "pubkey:"+string(did), func() (interface{}, error) {
+		return r.inner.ResolvePublicKey(ctx, did)
+	})
+}
+
+// ResolvePublicKeys retrieves all currently valid signing keys, sharing
+// concurrent calls for the same DID
+func (r *SingleFlightResolver) ResolvePublicKeys(ctx context.Context, did AgentDID) ([]VerificationKey, error) {
+	return shareCall(ctx, &r.group, "pubkeys:"+string(did), func() ([]VerificationKey, error) {
+		return ResolveVerificationKeys(ctx, r.inner, did)
+	})
+}
+
+// ResolveKEMKey retrieves the KEM key, sharing concurrent calls for the same DID
+func (r *SingleFlightResolver) ResolveKEMKey(ctx context.Context, did AgentDID) (interface{}, error) {
+	return shareCall(ctx, &r.group, "kem:"+string(did), func() (interface{}, error) {
+		return r.inner.ResolveKEMKey(ctx, did)
+	})
+}
+
+// ResolveKEMKeys retrieves all KEM keys, sharing concurrent calls for the same DID
+func (r *SingleFlightResolver) ResolveKEMKeys(ctx context.Context, did AgentDID) ([]KEMKeyEntry, error) {
+	return shareCall(ctx, &r.group, "kems:"+string(did), func() ([]KEMKeyEntry, error) {
+		return ResolveKEMKeys(ctx, r.inner, did)
+	})
+}
+
+// VerifyMetadata passes through; its result depends on the caller's metadata
+func (r *SingleFlightResolver) VerifyMetadata(ctx context.Context, did AgentDID, metadata *AgentMetadata) (*VerificationResult, error) {
+	return r.inner.VerifyMetadata(ctx, did, metadata)
+}
+
+// ListAgentsByOwner passes through to the underlying resolver
+func (r *SingleFlightResolver) ListAgentsByOwner(ctx context.Context, ownerAddress string) ([]*AgentMetadata, error) {
+	return r.inner.ListAgentsByOwner(ctx, ownerAddress)
+}
+
+// Search passes through to the underlying resolver
+func (r *SingleFlightResolver) Search(ctx context.Context, criteria SearchCriteria) ([]*AgentMetadata, error) {
+	return r.inner.Search(ctx, criteria)
+}
+
+// shareCall runs call once per key among concurrent callers; the first
+// caller runs it on its own goroutine and the rest wait for its result. A
+// context error from a call led by another caller reflects that caller's
+// context rather than the lookup, so callers that are still live retry.
+func shareCall[T any](ctx context.Context, group *singleflight.Group, key string, call func() (T, error)) (T, error) {
+	for {
+		led := false
+		v, err, _ := group.Do(key, func() (interface{}, error) {
+			led = true
+			return call()
+		})
+		if err != nil {
+			if !led && isContextError(err) && ctx.Err() == nil {
+				continue
+			}
+			var zero T
+			return zero, err
+		}
+		result, _ := v.(T)
+		return result, nil
+	}
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,156 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedResolver blocks every Resolve until release is closed
+type gatedResolver struct {
+	MockResolver
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func newGatedResolver() *gatedResolver {
+	return &gatedResolver{release: make(chan struct{})}
+}
+
+func (g *gatedResolver) Resolve(ctx context.Context, did AgentDID) (*AgentMetadata, error) {
+	g.calls.Add(1)
+	select {
+	case <-g.release:
+		return &AgentMetadata{DID: did, IsActive: true}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// resolveConcurrently starts n resolves, lets them pile up on the gate and
+// then releases it.
+func resolveConcurrently(t *testing.T, n int, gate *gatedResolver, resolve func() (*AgentMetadata, error)) []*AgentMetadata {
+	t.Helper()
+	results := make([]*AgentMetadata, n)
+	errs := make([]error, n)
+	var started, done sync.WaitGroup
+	started.Add(n)
+	done.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer done.Done()
+			started.Done()
+			results[i], errs[i] = resolve()
+		}(i)
+	}
+	started.Wait()
+	time.Sleep(50 * time.Millisecond)
+	close(gate.release)
+	done.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	return results
+}
+
+func TestSingleFlightResolver(t *testing.T) {
+	ctx := context.Background()
+	did := AgentDID("did:sage:ethereum:stampede")
+
+	t.Run("concurrent resolves share one call", func(t *testing.T) {
+		inner := newGatedResolver()
+		r := NewSingleFlightResolver(inner)
+
+		results := resolveConcurrently(t, 100, inner, func() (*AgentMetadata, error) {
+			return r.Resolve(ctx, did)
+		})
+		assert.Equal(t, int32(1), inner.calls.Load())
+		for _, md := range results {
+			assert.Same(t, results[0], md)
+		}
+
+		// Later calls are not deduplicated against a finished one
+		_, err := r.Resolve(ctx, did)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), inner.calls.Load())
+	})
+
+	t.Run("different DIDs are not shared", func(t *testing.T) {
+		inner := newGatedResolver()
+		close(inner.release)
+		r := NewSingleFlightResolver(inner)
+
+		_, err := r.Resolve(ctx, "did:sage:ethereum:a")
+		require.NoError(t, err)
+		_, err = r.Resolve(ctx, "did:sage:ethereum:b")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), inner.calls.Load())
+	})
+
+	t.Run("waiters retry when the leader is cancelled", func(t *testing.T) {
+		inner := newGatedResolver()
+		r := NewSingleFlightResolver(inner)
+
+		leaderCtx, cancel := context.WithCancel(ctx)
+		leaderErr := make(chan error, 1)
+		go func() {
+			_, err := r.Resolve(leaderCtx, did)
+			leaderErr <- err
+		}()
+		require.Eventually(t, func() bool { return inner.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+		waiterErr := make(chan error, 1)
+		go func() {
+			_, err := r.Resolve(ctx, did)
+			waiterErr <- err
+		}()
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		assert.ErrorIs(t, <-leaderErr, context.Canceled)
+
+		require.Eventually(t, func() bool { return inner.calls.Load() == 2 }, time.Second, time.Millisecond)
+		close(inner.release)
+		assert.NoError(t, <-waiterErr)
+	})
+}
+
+func TestManager_ColdCacheSharesResolve(t *testing.T) {
+	manager := NewManager()
+	inner := newGatedResolver()
+	manager.resolver.resolvers[ChainEthereum] = inner
+
+	did := AgentDID("did:sage:ethereum:cold")
+	opts := &ResolveOptions{MaxStaleness: time.Minute}
+	resolveConcurrently(t, 100, inner, func() (*AgentMetadata, error) {
+		return manager.ResolveAgentWithOptions(context.Background(), did, opts)
+	})
+	assert.Equal(t, int32(1), inner.calls.Load())
+
+	_, err := manager.ResolveAgentWithOptions(context.Background(), did, opts)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), inner.calls.Load(), "served from cache")
+}
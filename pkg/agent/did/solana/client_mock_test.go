@@ -259,3 +259,7 @@ func (m *mockKeyPairForType) Verify(message, signature []byte) error {
 func (m *mockKeyPairForType) ID() string {
 	return "mock-key-id"
 }
+
+func (m *mockKeyPairForType) PublicKeyEqual(other crypto.PublicKey) bool {
+	return false
+}
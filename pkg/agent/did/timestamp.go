@@ -0,0 +1,499 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+// ErrTimestampMismatch is returned by VerifyCardTimestamp when a validly
+// signed token does not cover the given card.
+var ErrTimestampMismatch = DIDError{Code: "TIMESTAMP_MISMATCH", Message: "timestamp token does not match card"}
+
+// maxTimestampResponseSize bounds a TSA response (1 MiB)
+const maxTimestampResponseSize = 1 << 20
+
+// TSAClient sends a DER-encoded RFC 3161 TimeStampReq to a time stamping
+// authority and returns its DER-encoded TimeStampResp.
+type TSAClient interface {
+	Timestamp(ctx context.Context, tsaURL string, req []byte) ([]byte, error)
+}
+
+// TSAClientFunc adapts a function to TSAClient
+type TSAClientFunc func(ctx context.Context, tsaURL string, req []byte) ([]byte, error)
+
+// Timestamp calls f
+func (f TSAClientFunc) Timestamp(ctx context.Context, tsaURL string, req []byte) ([]byte, error) {
+	return f(ctx, tsaURL, req)
+}
+
+// HTTPTSAClient posts timestamp queries over HTTP as described in RFC 3161
+// section 3.4.
+type HTTPTSAClient struct {
+	// HTTPClient performs the request; nil uses a client with a 10 second timeout
+	HTTPClient *http.Client
+}
+
+// Timestamp posts req to tsaURL as application/timestamp-query
+func (c *HTTPTSAClient) Timestamp(ctx context.Context, tsaURL string, req []byte) ([]byte, error) {
+	client := c.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, tsaURL, bytes.NewReader(req))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create timestamp request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/timestamp-query")
+	httpReq.Header.Set("Accept", "application/timestamp-reply")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("timestamp request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("timestamp request failed: HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTimestampResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read timestamp response: %w", err)
+	}
+	if len(body) > maxTimestampResponseSize {
+		return nil, fmt.Errorf("timestamp response too large")
+	}
+	return body, nil
+}
+
+// CardTimestamp is an RFC 3161 timestamp over a card's SHA-256 hash
+type CardTimestamp struct {
+	Token   []byte    `json:"token"`         // DER-encoded TimeStampToken (CMS SignedData)
+	GenTime time.Time `json:"genTime"`       // Time asserted by the TSA
+	TSA     string    `json:"tsa,omitempty"` // URL the token was obtained from
+}
+
+// CardTimestampOptions configures TimestampCardWithOptions
+type CardTimestampOptions struct {
+	// TSAURL is the time stamping authority endpoint
+	TSAURL string
+	// Client sends the request; nil uses an HTTPTSAClient
+	Client TSAClient
+}
+
+// TimestampCard obtains an RFC 3161 timestamp token over card from the TSA
+// at tsaURL. See TimestampCardWithOptions.
+func TimestampCard(card *A2AAgentCard, tsaURL string) (*CardTimestamp, error) {
+	return TimestampCardWithOptions(context.Background(), card, CardTimestampOptions{TSAURL: tsaURL})
+}
+
+// TimestampCardWithOptions obtains an RFC 3161 timestamp token over the
+// SHA-256 hash of card's JSON encoding, the same bytes covered by an
+// A2AProof. The request carries a random nonce and asks for the TSA
+// certificate so the token can be verified on its own later.
+//
+// The token's signature is not checked here; use VerifyCardTimestamp.
+func TimestampCardWithOptions(ctx context.Context, card *A2AAgentCard, opts CardTimestampOptions) (*CardTimestamp, error) {
+	if card == nil {
+		return nil, fmt.Errorf("card is nil")
+	}
+	digest, err := cardDigest(card)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	req, err := asn1.Marshal(timeStampReq{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+			HashedMessage: digest,
+		},
+		Nonce:   nonce,
+		CertReq: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode timestamp request: %w", err)
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &HTTPTSAClient{}
+	}
+	respDER, err := client.Timestamp(ctx, opts.TSAURL, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp timeStampResp
+	if rest, err := asn1.Unmarshal(respDER, &resp); err != nil {
+		return nil, fmt.Errorf("invalid timestamp response: %w", err)
+	} else if len(rest) > 0 {
+		return nil, fmt.Errorf("invalid timestamp response: trailing data")
+	}
+	if resp.Status.Status != 0 && resp.Status.Status != 1 {
+		return nil, fmt.Errorf("TSA rejected request: status %d %v", resp.Status.Status, resp.Status.StatusString)
+	}
+	if len(resp.TimeStampToken.FullBytes) == 0 {
+		return nil, fmt.Errorf("TSA response has no token")
+	}
+
+	token := resp.TimeStampToken.FullBytes
+	_, info, err := parseTimestampToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if info.Nonce == nil || info.Nonce.Cmp(nonce) != 0 {
+		return nil, fmt.Errorf("timestamp nonce mismatch")
+	}
+	if !info.MessageImprint.matches(digest) {
+		return nil, ErrTimestampMismatch
+	}
+
+	return &CardTimestamp{
+		Token:   token,
+		GenTime: info.GenTime.UTC(),
+		TSA:     opts.TSAURL,
+	}, nil
+}
+
+// VerifyCardTimestamp checks that token is a timestamp over card signed by
+// a TSA certificate chaining to roots (nil uses the system roots) and valid
+// for time stamping at the asserted time, which it returns.
+func VerifyCardTimestamp(card *A2AAgentCard, token []byte, roots *x509.CertPool) (time.Time, error) {
+	if card == nil {
+		return time.Time{}, fmt.Errorf("card is nil")
+	}
+	sd, info, err := parseTimestampToken(token)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp certificates: %w", err)
+	}
+	if len(sd.SignerInfos) != 1 {
+		return time.Time{}, fmt.Errorf("timestamp token must have exactly one signer, got %d", len(sd.SignerInfos))
+	}
+	si := sd.SignerInfos[0]
+	signer := si.findCertificate(certs)
+	if signer == nil {
+		return time.Time{}, fmt.Errorf("timestamp signer certificate not included in token")
+	}
+
+	if err := si.verify(signer, sd.EncapContentInfo.EContent); err != nil {
+		return time.Time{}, err
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range certs {
+		if c != signer {
+			intermediates.AddCert(c)
+		}
+	}
+	if _, err := signer.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   info.GenTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	}); err != nil {
+		return time.Time{}, fmt.Errorf("untrusted TSA certificate: %w", err)
+	}
+
+	digest, err := cardDigest(card)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !info.MessageImprint.matches(digest) {
+		return time.Time{}, ErrTimestampMismatch
+	}
+	return info.GenTime.UTC(), nil
+}
+
+// cardDigest hashes card the same way as the A2AProof signature
+func cardDigest(card *A2AAgentCard) ([]byte, error) {
+	cardJSON, err := json.Marshal(card)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal card: %w", err)
+	}
+	sum := sha256.Sum256(cardJSON)
+	return sum[:], nil
+}
+
+var (
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+)
+
+// ASN.1 structures from RFC 3161 and RFC 5652, reduced to the fields needed
+// to request and verify a timestamp.
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+func (m messageImprint) matches(sha256Digest []byte) bool {
+	return m.HashAlgorithm.Algorithm.Equal(oidSHA256) && bytes.Equal(m.HashedMessage, sha256Digest)
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	ReqPolicy      asn1.ObjectIdentifier `asn1:"optional"`
+	Nonce          *big.Int              `asn1:"optional"`
+	CertReq        bool                  `asn1:"optional,default:false"`
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString []string       `asn1:"optional"`
+	FailInfo     asn1.BitString `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status         pkiStatusInfo
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type encapContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,optional,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo encapContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type issuerAndSerial struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+type signerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type accuracy struct {
+	Seconds int `asn1:"optional"`
+	Millis  int `asn1:"optional,tag:0"`
+	Micros  int `asn1:"optional,tag:1"`
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time     `asn1:"generalized"`
+	Accuracy       accuracy      `asn1:"optional"`
+	Ordering       bool          `asn1:"optional,default:false"`
+	Nonce          *big.Int      `asn1:"optional"`
+	TSA            asn1.RawValue `asn1:"optional,explicit,tag:0"`
+	Extensions     asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+// parseTimestampToken decodes a TimeStampToken into its SignedData and
+// TSTInfo without checking the signature.
+func parseTimestampToken(token []byte) (*signedData, *tstInfo, error) {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(token, &ci); err != nil {
+		return nil, nil, fmt.Errorf("invalid timestamp token: %w", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, nil, fmt.Errorf("invalid timestamp token: content type %v is not signed data", ci.ContentType)
+	}
+
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, nil, fmt.Errorf("invalid timestamp signed data: %w", err)
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) {
+		return nil, nil, fmt.Errorf("invalid timestamp token: content type %v is not TSTInfo", sd.EncapContentInfo.EContentType)
+	}
+
+	var info tstInfo
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent, &info); err != nil {
+		return nil, nil, fmt.Errorf("invalid TSTInfo: %w", err)
+	}
+	return &sd, &info, nil
+}
+
+// findCertificate returns the certificate identified by the signer's
+// issuer and serial number or subject key identifier.
+func (si signerInfo) findCertificate(certs []*x509.Certificate) *x509.Certificate {
+	if si.SID.Class == asn1.ClassContextSpecific && si.SID.Tag == 0 {
+		for _, c := range certs {
+			if bytes.Equal(c.SubjectKeyId, si.SID.Bytes) {
+				return c
+			}
+		}
+		return nil
+	}
+
+	var ias issuerAndSerial
+	if _, err := asn1.Unmarshal(si.SID.FullBytes, &ias); err != nil {
+		return nil
+	}
+	for _, c := range certs {
+		if bytes.Equal(c.RawIssuer, ias.Issuer.FullBytes) && c.SerialNumber.Cmp(ias.SerialNumber) == 0 {
+			return c
+		}
+	}
+	return nil
+}
+
+// verify checks the signed attributes against content and the signature
+// over the attributes against cert.
+func (si signerInfo) verify(cert *x509.Certificate, content []byte) error {
+	hash, err := digestHash(si.DigestAlgorithm.Algorithm)
+	if err != nil {
+		return err
+	}
+	if len(si.SignedAttrs.FullBytes) == 0 {
+		return fmt.Errorf("timestamp token has no signed attributes")
+	}
+
+	// The signature covers the attributes encoded as a SET OF, not with
+	// the [0] IMPLICIT tag they carry inside SignerInfo.
+	signed := append([]byte(nil), si.SignedAttrs.FullBytes...)
+	signed[0] = 0x31
+
+	var attrs []attribute
+	if _, err := asn1.UnmarshalWithParams(signed, &attrs, "set"); err != nil {
+		return fmt.Errorf("invalid signed attributes: %w", err)
+	}
+	var contentType asn1.ObjectIdentifier
+	var digest []byte
+	for _, a := range attrs {
+		switch {
+		case a.Type.Equal(oidContentType):
+			if _, err := asn1.Unmarshal(a.Values.Bytes, &contentType); err != nil {
+				return fmt.Errorf("invalid content type attribute: %w", err)
+			}
+		case a.Type.Equal(oidMessageDigest):
+			if _, err := asn1.Unmarshal(a.Values.Bytes, &digest); err != nil {
+				return fmt.Errorf("invalid message digest attribute: %w", err)
+			}
+		}
+	}
+	if !contentType.Equal(oidTSTInfo) {
+		return fmt.Errorf("signed content type %v is not TSTInfo", contentType)
+	}
+	h := hash.New()
+	h.Write(content)
+	if !bytes.Equal(h.Sum(nil), digest) {
+		return fmt.Errorf("%w: TSTInfo digest mismatch", ErrInvalidSignature)
+	}
+
+	algo, err := signatureAlgorithm(cert, hash)
+	if err != nil {
+		return err
+	}
+	if err := cert.CheckSignature(algo, signed, si.Signature); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return nil
+}
+
+func digestHash(oid asn1.ObjectIdentifier) (crypto.Hash, error) {
+	switch {
+	case oid.Equal(oidSHA256):
+		return crypto.SHA256, nil
+	case oid.Equal(oidSHA384):
+		return crypto.SHA384, nil
+	case oid.Equal(oidSHA512):
+		return crypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("unsupported timestamp digest algorithm %v", oid)
+}
+
+func signatureAlgorithm(cert *x509.Certificate, hash crypto.Hash) (x509.SignatureAlgorithm, error) {
+	switch cert.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		switch hash {
+		case crypto.SHA256:
+			return x509.ECDSAWithSHA256, nil
+		case crypto.SHA384:
+			return x509.ECDSAWithSHA384, nil
+		case crypto.SHA512:
+			return x509.ECDSAWithSHA512, nil
+		}
+	case *rsa.PublicKey:
+		switch hash {
+		case crypto.SHA256:
+			return x509.SHA256WithRSA, nil
+		case crypto.SHA384:
+			return x509.SHA384WithRSA, nil
+		case crypto.SHA512:
+			return x509.SHA512WithRSA, nil
+		}
+	case ed25519.PublicKey:
+		return x509.PureEd25519, nil
+	}
+	return x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported TSA key type %T", cert.PublicKey)
+}
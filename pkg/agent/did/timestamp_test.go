@@ -0,0 +1,239 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockTSA issues RFC 3161 tokens signed by a self-signed time stamping
+// certificate.
+type mockTSA struct {
+	key   *ecdsa.PrivateKey
+	cert  *x509.Certificate
+	roots *x509.CertPool
+	now   time.Time
+}
+
+func newMockTSA(t *testing.T) *mockTSA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(42),
+		Subject:               pkix.Name{CommonName: "Mock TSA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	return &mockTSA{key: key, cert: cert, roots: roots, now: now}
+}
+
+func mustMarshal(t *testing.T, v interface{}, params string) []byte {
+	t.Helper()
+	der, err := asn1.MarshalWithParams(v, params)
+	require.NoError(t, err)
+	return der
+}
+
+// respond answers a DER TimeStampReq with a granted DER TimeStampResp
+func (m *mockTSA) respond(t *testing.T, reqDER []byte) []byte {
+	t.Helper()
+	var req timeStampReq
+	_, err := asn1.Unmarshal(reqDER, &req)
+	require.NoError(t, err)
+
+	eContent := mustMarshal(t, tstInfo{
+		Version:        1,
+		Policy:         asn1.ObjectIdentifier{1, 2, 3, 4},
+		MessageImprint: req.MessageImprint,
+		SerialNumber:   big.NewInt(7),
+		GenTime:        m.now,
+		Nonce:          req.Nonce,
+	}, "")
+	contentDigest := sha256.Sum256(eContent)
+
+	attrs := mustMarshal(t, []attribute{
+		{Type: oidContentType, Values: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: mustMarshal(t, oidTSTInfo, "")}},
+		{Type: oidMessageDigest, Values: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: mustMarshal(t, contentDigest[:], "")}},
+	}, "set")
+	attrsDigest := sha256.Sum256(attrs)
+	sig, err := ecdsa.SignASN1(rand.Reader, m.key, attrsDigest[:])
+	require.NoError(t, err)
+
+	implicitAttrs := append([]byte(nil), attrs...)
+	implicitAttrs[0] = 0xa0
+
+	sd := mustMarshal(t, signedData{
+		Version:          3,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: oidSHA256}},
+		EncapContentInfo: encapContentInfo{EContentType: oidTSTInfo, EContent: eContent},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: m.cert.Raw},
+		SignerInfos: []signerInfo{{
+			Version: 1,
+			SID: asn1.RawValue{FullBytes: mustMarshal(t, issuerAndSerial{
+				Issuer:       asn1.RawValue{FullBytes: m.cert.RawIssuer},
+				SerialNumber: m.cert.SerialNumber,
+			}, "")},
+			DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+			SignedAttrs:        asn1.RawValue{FullBytes: implicitAttrs},
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}},
+			Signature:          sig,
+		}},
+	}, "")
+	token := mustMarshal(t, contentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd},
+	}, "")
+
+	return mustMarshal(t, timeStampResp{
+		Status:         pkiStatusInfo{Status: 0},
+		TimeStampToken: asn1.RawValue{FullBytes: token},
+	}, "")
+}
+
+func (m *mockTSA) server(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/timestamp-query" {
+			http.Error(w, "bad content type", http.StatusUnsupportedMediaType)
+			return
+		}
+		req, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		_, _ = w.Write(m.respond(t, req))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTimestampCard_MockTSA(t *testing.T) {
+	tsa := newMockTSA(t)
+	srv := tsa.server(t)
+	card := &signedTestCard(t).A2AAgentCard
+
+	ts, err := TimestampCard(card, srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, tsa.now, ts.GenTime)
+	assert.Equal(t, srv.URL, ts.TSA)
+
+	genTime, err := VerifyCardTimestamp(card, ts.Token, tsa.roots)
+	require.NoError(t, err)
+	assert.Equal(t, tsa.now, genTime)
+
+	t.Run("modified card", func(t *testing.T) {
+		modified := *card
+		modified.Description = "changed after timestamping"
+		_, err := VerifyCardTimestamp(&modified, ts.Token, tsa.roots)
+		assert.ErrorIs(t, err, ErrTimestampMismatch)
+	})
+
+	t.Run("untrusted TSA", func(t *testing.T) {
+		_, err := VerifyCardTimestamp(card, ts.Token, x509.NewCertPool())
+		assert.ErrorContains(t, err, "untrusted TSA certificate")
+	})
+
+	t.Run("tampered token", func(t *testing.T) {
+		// The signature is the last element of the token
+		tampered := append([]byte(nil), ts.Token...)
+		tampered[len(tampered)-1] ^= 0xff
+		_, err := VerifyCardTimestamp(card, tampered, tsa.roots)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+}
+
+func TestTimestampCard_CustomClient(t *testing.T) {
+	tsa := newMockTSA(t)
+	card := &signedTestCard(t).A2AAgentCard
+
+	var gotURL string
+	client := TSAClientFunc(func(_ context.Context, tsaURL string, req []byte) ([]byte, error) {
+		gotURL = tsaURL
+		return tsa.respond(t, req), nil
+	})
+	ts, err := TimestampCardWithOptions(context.Background(), card, CardTimestampOptions{
+		TSAURL: "tsa://in-process",
+		Client: client,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "tsa://in-process", gotURL)
+
+	_, err = VerifyCardTimestamp(card, ts.Token, tsa.roots)
+	require.NoError(t, err)
+
+	t.Run("rejected request", func(t *testing.T) {
+		rejected := TSAClientFunc(func(context.Context, string, []byte) ([]byte, error) {
+			return mustMarshal(t, timeStampResp{Status: pkiStatusInfo{Status: 2, StatusString: []string{"bad alg"}}}, ""), nil
+		})
+		_, err := TimestampCardWithOptions(context.Background(), card, CardTimestampOptions{Client: rejected})
+		assert.ErrorContains(t, err, "TSA rejected request")
+	})
+
+	t.Run("replayed response", func(t *testing.T) {
+		// A response captured for another request carries a different nonce
+		var captured []byte
+		capture := TSAClientFunc(func(_ context.Context, _ string, req []byte) ([]byte, error) {
+			if captured == nil {
+				captured = tsa.respond(t, req)
+			}
+			return captured, nil
+		})
+		opts := CardTimestampOptions{Client: capture}
+		_, err := TimestampCardWithOptions(context.Background(), card, opts)
+		require.NoError(t, err)
+		_, err = TimestampCardWithOptions(context.Background(), card, opts)
+		assert.ErrorContains(t, err, "nonce mismatch")
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		failing := TSAClientFunc(func(context.Context, string, []byte) ([]byte, error) {
+			return nil, errors.New("tsa unavailable")
+		})
+		_, err := TimestampCardWithOptions(context.Background(), card, CardTimestampOptions{Client: failing})
+		assert.ErrorContains(t, err, "tsa unavailable")
+	})
+}
@@ -33,8 +33,9 @@ type AgentMetadata struct {
 	Name         string                 `json:"name"`
 	Description  string                 `json:"description"`
 	Endpoint     string                 `json:"endpoint"`
-	PublicKey    interface{}            `json:"public_key"`     // crypto.PublicKey type
-	PublicKEMKey interface{}            `json:"public_kem_key"` // crypto.PublicKey type
+	PublicKey    interface{}            `json:"public_key"`         // crypto.PublicKey type
+	PublicKEMKey interface{}            `json:"public_kem_key"`     // crypto.PublicKey type
+	KEMKeys      []KEMKeyEntry          `json:"kem_keys,omitempty"` // Additional KEM keys of other schemes
 	Capabilities map[string]interface{} `json:"capabilities"`
 	Owner        string                 `json:"owner"` // Blockchain address of the owner
 	IsActive     bool                   `json:"is_active"`
@@ -58,8 +59,10 @@ type RegistrationResult struct {
 	TransactionHash string    `json:"transaction_hash"`
 	BlockNumber     uint64    `json:"block_number"`
 	Timestamp       time.Time `json:"timestamp"`
-	GasUsed         uint64    `json:"gas_used,omitempty"` // For Ethereum
-	Slot            uint64    `json:"slot,omitempty"`     // For Solana
+	GasUsed         uint64    `json:"gas_used,omitempty"`      // For Ethereum
+	Slot            uint64    `json:"slot,omitempty"`          // For Solana
+	AgentID         string    `json:"agent_id,omitempty"`      // On-chain agent ID from the AgentRegistered event (hex)
+	RegisteredAt    time.Time `json:"registered_at,omitempty"` // Block timestamp emitted with the AgentRegistered event
 }
 
 // VerificationResult contains the result of DID verification
@@ -104,6 +107,13 @@ func (e DIDError) Error() string {
 	return e.Message
 }
 
+// Is matches DID errors by code, so errors.Is(err, ErrDIDNotFound) holds for
+// any DIDError carrying that code regardless of its message or details.
+func (e DIDError) Is(target error) bool {
+	t, ok := target.(DIDError)
+	return ok && t.Code == e.Code
+}
+
 // Common DID errors
 var (
 	ErrDIDNotFound       = DIDError{Code: "DID_NOT_FOUND", Message: "DID not found in registry"}
@@ -112,4 +122,15 @@ var (
 	ErrInactiveAgent     = DIDError{Code: "INACTIVE_AGENT", Message: "agent is deactivated"}
 	ErrUnauthorized      = DIDError{Code: "UNAUTHORIZED", Message: "unauthorized operation"}
 	ErrChainNotSupported = DIDError{Code: "CHAIN_NOT_SUPPORTED", Message: "blockchain not supported"}
+
+	// ErrDIDAlreadyRegistered is returned before any transaction is sent when
+	// the DID is already registered to a different owner. Details carries
+	// "did" and "owner" (the existing owner's address).
+	ErrDIDAlreadyRegistered = DIDError{Code: "DID_ALREADY_REGISTERED", Message: "DID already registered to a different owner"}
+
+	// ErrDIDMismatch is returned when the registry answers a lookup with a
+	// record whose DID differs from the one requested, which points at an
+	// agentId collision or a contract bug. Details carries "requested" and
+	// "returned".
+	ErrDIDMismatch = DIDError{Code: "DID_MISMATCH", Message: "registry returned a record for a different DID"}
 )
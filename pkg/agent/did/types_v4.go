@@ -80,11 +80,12 @@ type AgentMetadataV4 struct {
 
 // A2APublicKey represents a public key in A2A Agent Card format
 type A2APublicKey struct {
-	ID              string `json:"id"`                     // Key identifier
-	Type            string `json:"type"`                   // Key type (e.g., "Ed25519VerificationKey2020")
-	Controller      string `json:"controller"`             // DID that controls this key
-	PublicKeyBase58 string `json:"publicKeyBase58"`        // Base58-encoded public key
-	PublicKeyHex    string `json:"publicKeyHex,omitempty"` // Hex-encoded (alternative)
+	ID                 string `json:"id"`                           // Key identifier
+	Type               string `json:"type"`                         // Key type (e.g., "Ed25519VerificationKey2020")
+	Controller         string `json:"controller"`                   // DID that controls this key
+	PublicKeyBase58    string `json:"publicKeyBase58"`              // Base58-encoded public key
+	PublicKeyHex       string `json:"publicKeyHex,omitempty"`       // Hex-encoded (alternative)
+	PublicKeyMultibase string `json:"publicKeyMultibase,omitempty"` // Multibase, multicodec-prefixed (Ed25519/X25519 only)
 }
 
 // A2AEndpoint represents a service endpoint in A2A Agent Card
@@ -106,6 +107,7 @@ type A2AAgentCard struct {
 	Capabilities []string       `json:"capabilities,omitempty"` // Agent capabilities
 	Created      time.Time      `json:"created"`                // Creation timestamp
 	Updated      time.Time      `json:"updated"`                // Last update timestamp
+	Extras       *SealedExtras  `json:"extras,omitempty"`       // Private metadata sealed to a KEM key (see SealCardExtras)
 }
 
 // GetKeyByType returns the first key of the specified type
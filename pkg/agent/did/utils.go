@@ -124,7 +124,7 @@ func UnmarshalPublicKey(data []byte, keyType string) (interface{}, error) {
 //
 // The function automatically normalizes the address:
 //   - Ethereum: Adds "0x" prefix if missing and converts to lowercase
-//   - Solana: Converts to lowercase (no prefix)
+//   - Solana: Kept as-is, since base58 is case-sensitive
 //
 // Example:
 //
@@ -138,20 +138,13 @@ func UnmarshalPublicKey(data []byte, keyType string) (interface{}, error) {
 //	chain := ChainSolana
 //	ownerAddr := "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK"
 //	agentDID := GenerateAgentDIDWithAddress(chain, ownerAddr)
-//	// Returns: "did:sage:solana:dyw8jctfwhnrjhhmfcbxvvdtqwmevfbx6zkumg5cnskk"
+//	// Returns: "did:sage:solana:DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK"
 //
 // This function is used by sage-a2a-go for creating DIDs that can be verified
-// against on-chain ownership records.
+// against on-chain ownership records. It uses DefaultMethod; see
+// Manager.GenerateAgentDIDWithAddress for a manager with another method.
 func GenerateAgentDIDWithAddress(chain Chain, ownerAddress string) AgentDID {
-	// For Ethereum, ensure address starts with 0x
-	if chain == ChainEthereum {
-		if !strings.HasPrefix(ownerAddress, "0x") {
-			ownerAddress = "0x" + ownerAddress
-		}
-	}
-	// Convert to lowercase for consistency
-	ownerAddress = strings.ToLower(ownerAddress)
-	return AgentDID(fmt.Sprintf("did:sage:%s:%s", chain, ownerAddress))
+	return generateAgentDIDWithAddress(DefaultMethod, chain, ownerAddress)
 }
 
 // GenerateAgentDIDWithNonce creates a DID with both owner address and nonce.
@@ -179,16 +172,30 @@ func GenerateAgentDIDWithAddress(chain Chain, ownerAddress string) AgentDID {
 //	// Returns: "did:sage:ethereum:0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266:1"
 //
 // See also: GenerateAgentDIDWithAddress for single-agent-per-owner scenarios.
+// It uses DefaultMethod; see Manager.GenerateAgentDIDWithNonce for a manager
+// with another method.
 func GenerateAgentDIDWithNonce(chain Chain, ownerAddress string, nonce uint64) AgentDID {
-	// For Ethereum, ensure address starts with 0x
-	if chain == ChainEthereum {
-		if !strings.HasPrefix(ownerAddress, "0x") {
-			ownerAddress = "0x" + ownerAddress
-		}
+	return generateAgentDIDWithNonce(DefaultMethod, chain, ownerAddress, nonce)
+}
+
+func generateAgentDIDWithAddress(method string, chain Chain, ownerAddress string) AgentDID {
+	return GenerateDIDWithMethod(method, chain, normalizeOwnerAddress(chain, ownerAddress))
+}
+
+func generateAgentDIDWithNonce(method string, chain Chain, ownerAddress string, nonce uint64) AgentDID {
+	return GenerateDIDWithMethod(method, chain, fmt.Sprintf("%s:%d", normalizeOwnerAddress(chain, ownerAddress), nonce))
+}
+
+// normalizeOwnerAddress applies the per-chain address normalization used by
+// GenerateAgentDIDWithAddress and GenerateAgentDIDWithNonce.
+func normalizeOwnerAddress(chain Chain, ownerAddress string) string {
+	if chain != ChainEthereum {
+		return ownerAddress
+	}
+	if !strings.HasPrefix(ownerAddress, "0x") {
+		ownerAddress = "0x" + ownerAddress
 	}
-	// Convert to lowercase for consistency
-	ownerAddress = strings.ToLower(ownerAddress)
-	return AgentDID(fmt.Sprintf("did:sage:%s:%s:%d", chain, ownerAddress, nonce))
+	return strings.ToLower(ownerAddress)
 }
 
 // DeriveEthereumAddress derives the Ethereum address from a secp256k1 keypair.
@@ -56,7 +56,7 @@ func TestGenerateAgentDIDWithAddress(t *testing.T) {
 			name:         "Solana address",
 			chain:        ChainSolana,
 			ownerAddress: "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK",
-			expectedDID:  "did:sage:solana:dyw8jctfwhnrjhhmfcbxvvdtqwmevfbx6zkumg5cnskk",
+			expectedDID:  "did:sage:solana:DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK",
 		},
 	}
 
@@ -0,0 +1,261 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sage-x-project/sage/pkg/agent/crypto"
+)
+
+var (
+	// ErrV2Unsupported is returned by V2CompatManager for SageRegistryV2
+	// operations that have no multi-key (V4) equivalent. Details carries
+	// "method" and "use", the V4 call to migrate to.
+	ErrV2Unsupported = DIDError{Code: "V2_UNSUPPORTED", Message: "SageRegistryV2 operation has no V4 equivalent"}
+
+	// ErrUnknownAgentID is returned by V2CompatManager when an agent ID has
+	// not been seen through the adapter, so its DID is unknown. Details
+	// carries "agent_id".
+	ErrUnknownAgentID = DIDError{Code: "UNKNOWN_AGENT_ID", Message: "agent ID not known to the V2 adapter; look the agent up by DID first"}
+)
+
+// V2CompatManager presents the method shapes of the deprecated
+// SageRegistryV2 bindings on top of a Manager backed by the multi-key (V4)
+// AgentCardRegistry, so applications can migrate call sites one at a time.
+//
+// Differences from SageRegistryV2:
+//   - Calls take a context, and the key pair replaces the publicKey and
+//     signature arguments: the V4 registry derives both from it.
+//   - V4 addresses agents by DID. Methods taking an agent ID map it to a DID
+//     the adapter has seen from RegisterAgent, GetAgentByDID or
+//     GetAgentsByOwner, and fail with ErrUnknownAgentID otherwise.
+//   - Capabilities stay a JSON object string on the V2 side and are stored
+//     as a map on the V4 side.
+//   - RevokeKey and IsKeyValid act on a bare public key in V2 but per agent
+//     in V4 and fail with ErrV2Unsupported; so would the hook setters, which
+//     are not provided.
+//
+// V2CompatManager is safe for concurrent use.
+type V2CompatManager struct {
+	manager *Manager
+	chain   Chain
+
+	mu  sync.RWMutex
+	ids map[[32]byte]AgentDID
+}
+
+// NewV2CompatManager wraps manager. Like SageRegistryV2, the adapter only
+// targets Ethereum.
+func NewV2CompatManager(manager *Manager) *V2CompatManager {
+	return &V2CompatManager{
+		manager: manager,
+		chain:   ChainEthereum,
+		ids:     make(map[[32]byte]AgentDID),
+	}
+}
+
+// RegisterAgent mirrors registerAgent and returns the new agent ID. The ID
+// is zero if the registry does not report it.
+func (c *V2CompatManager) RegisterAgent(ctx context.Context, did AgentDID, name, description, endpoint, capabilities string, keyPair crypto.KeyPair) ([32]byte, error) {
+	caps, err := parseV2Capabilities(capabilities)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	result, err := c.manager.RegisterAgent(ctx, c.chain, &RegistrationRequest{
+		DID:          did,
+		Name:         name,
+		Description:  description,
+		Endpoint:     endpoint,
+		Capabilities: caps,
+		KeyPair:      keyPair,
+	})
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	var id [32]byte
+	if result.AgentID == "" {
+		return id, nil
+	}
+	raw, err := hex.DecodeString(strings.TrimPrefix(result.AgentID, "0x"))
+	if err != nil || len(raw) != len(id) {
+		return id, fmt.Errorf("registry returned malformed agent ID %q", result.AgentID)
+	}
+	copy(id[:], raw)
+	c.remember(id, did)
+	return id, nil
+}
+
+// GetAgentByDID mirrors getAgentByDID.
+func (c *V2CompatManager) GetAgentByDID(ctx context.Context, did AgentDID) (*AgentMetadata, error) {
+	meta, err := c.manager.ResolveAgent(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+	if id, ok := v2AgentID(meta); ok {
+		c.remember(id, meta.DID)
+	}
+	return meta, nil
+}
+
+// GetAgent mirrors getAgent.
+func (c *V2CompatManager) GetAgent(ctx context.Context, agentID [32]byte) (*AgentMetadata, error) {
+	did, err := c.lookup(agentID)
+	if err != nil {
+		return nil, err
+	}
+	return c.manager.ResolveAgent(ctx, did)
+}
+
+// GetAgentsByOwner mirrors getAgentsByOwner. Agents whose registration
+// time the registry does not report have no derivable ID and make the call
+// fail with ErrV2Unsupported; use Manager.ListAgentsByOwner instead.
+func (c *V2CompatManager) GetAgentsByOwner(ctx context.Context, owner string) ([][32]byte, error) {
+	agents, err := c.manager.ListAgentsByOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([][32]byte, 0, len(agents))
+	for _, meta := range agents {
+		id, ok := v2AgentID(meta)
+		if !ok {
+			return nil, v2Unsupported("getAgentsByOwner", "Manager.ListAgentsByOwner")
+		}
+		c.remember(id, meta.DID)
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// IsAgentActive mirrors isAgentActive.
+func (c *V2CompatManager) IsAgentActive(ctx context.Context, agentID [32]byte) (bool, error) {
+	meta, err := c.GetAgent(ctx, agentID)
+	if err != nil {
+		return false, err
+	}
+	return meta.IsActive, nil
+}
+
+// VerifyAgentOwnership mirrors verifyAgentOwnership.
+func (c *V2CompatManager) VerifyAgentOwnership(ctx context.Context, agentID [32]byte, claimedOwner string) (bool, error) {
+	meta, err := c.GetAgent(ctx, agentID)
+	if err != nil {
+		return false, err
+	}
+	return meta.Owner != "" && strings.EqualFold(meta.Owner, claimedOwner), nil
+}
+
+// UpdateAgent mirrors updateAgent.
+func (c *V2CompatManager) UpdateAgent(ctx context.Context, agentID [32]byte, name, description, endpoint, capabilities string, keyPair crypto.KeyPair) error {
+	did, err := c.lookup(agentID)
+	if err != nil {
+		return err
+	}
+	caps, err := parseV2Capabilities(capabilities)
+	if err != nil {
+		return err
+	}
+	updates := map[string]interface{}{
+		"name":        name,
+		"description": description,
+		"endpoint":    endpoint,
+	}
+	if caps != nil {
+		updates["capabilities"] = caps
+	}
+	return c.manager.UpdateAgent(ctx, did, updates, keyPair)
+}
+
+// DeactivateAgent mirrors deactivateAgent.
+func (c *V2CompatManager) DeactivateAgent(ctx context.Context, agentID [32]byte, keyPair crypto.KeyPair) error {
+	did, err := c.lookup(agentID)
+	if err != nil {
+		return err
+	}
+	return c.manager.DeactivateAgent(ctx, did, keyPair)
+}
+
+// RevokeKey mirrors revokeKey. V4 revokes keys per agent, so it always
+// fails with ErrV2Unsupported.
+func (c *V2CompatManager) RevokeKey(ctx context.Context, publicKey []byte) error {
+	return v2Unsupported("revokeKey", "Manager.RevokeKey with the agent DID and key hash")
+}
+
+// IsKeyValid mirrors isKeyValid. V4 tracks keys per agent, so it always
+// fails with ErrV2Unsupported.
+func (c *V2CompatManager) IsKeyValid(ctx context.Context, publicKey []byte) (bool, error) {
+	return false, v2Unsupported("isKeyValid", "Manager.ResolvePublicKeys for the agent DID")
+}
+
+func (c *V2CompatManager) remember(id [32]byte, did AgentDID) {
+	c.mu.Lock()
+	c.ids[id] = did
+	c.mu.Unlock()
+}
+
+func (c *V2CompatManager) lookup(id [32]byte) (AgentDID, error) {
+	c.mu.RLock()
+	did, ok := c.ids[id]
+	c.mu.RUnlock()
+	if !ok {
+		return "", DIDError{
+			Code:    ErrUnknownAgentID.Code,
+			Message: ErrUnknownAgentID.Message,
+			Details: map[string]interface{}{"agent_id": "0x" + hex.EncodeToString(id[:])},
+		}
+	}
+	return did, nil
+}
+
+// v2AgentID derives the agent ID from the owner and registration time the
+// registry reports. It reports false if either is missing.
+func v2AgentID(meta *AgentMetadata) ([32]byte, bool) {
+	if !common.IsHexAddress(meta.Owner) || meta.CreatedAt.IsZero() {
+		return [32]byte{}, false
+	}
+	return AgentID(meta.DID, common.HexToAddress(meta.Owner), big.NewInt(meta.CreatedAt.Unix())), true
+}
+
+// parseV2Capabilities decodes V2's capabilities JSON string.
+func parseV2Capabilities(capabilities string) (map[string]interface{}, error) {
+	if strings.TrimSpace(capabilities) == "" {
+		return nil, nil
+	}
+	var caps map[string]interface{}
+	if err := json.Unmarshal([]byte(capabilities), &caps); err != nil {
+		return nil, fmt.Errorf("capabilities must be a JSON object: %w", err)
+	}
+	return caps, nil
+}
+
+func v2Unsupported(method, use string) error {
+	return DIDError{
+		Code:    ErrV2Unsupported.Code,
+		Message: fmt.Sprintf("%s: %s", method, ErrV2Unsupported.Message),
+		Details: map[string]interface{}{"method": method, "use": use},
+	}
+}
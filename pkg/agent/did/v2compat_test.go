@@ -0,0 +1,140 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package did
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestV2CompatManager(t *testing.T) {
+	ctx := context.Background()
+	const owner = "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEEf"
+	agentDID := AgentDID("did:sage:ethereum:" + owner)
+	registeredAt := time.Unix(1735689600, 0)
+	agentID := AgentID(agentDID, common.HexToAddress(owner), big.NewInt(registeredAt.Unix()))
+
+	backend := &keyManagingClient{}
+	manager := NewManager()
+	require.NoError(t, manager.Configure(ChainEthereum, &RegistryConfig{
+		ContractAddress: "0x1234567890123456789012345678901234567890",
+		RPCEndpoint:     "http://localhost:8545",
+	}))
+	require.NoError(t, manager.SetClient(ChainEthereum, backend))
+	compat := NewV2CompatManager(manager)
+
+	keyPair, err := keys.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+	meta := &AgentMetadata{
+		DID:       agentDID,
+		Name:      "legacy-agent",
+		Endpoint:  "https://agent.example.com",
+		Owner:     owner,
+		IsActive:  true,
+		CreatedAt: registeredAt,
+	}
+
+	t.Run("RegisterAgent maps to a V4 registration", func(t *testing.T) {
+		backend.MockRegistry.On("Register", mock.Anything, mock.MatchedBy(func(req *RegistrationRequest) bool {
+			return req.DID == agentDID && req.Name == "legacy-agent" && req.KeyPair == keyPair &&
+				req.Capabilities["chat"] == true
+		})).Return(&RegistrationResult{AgentID: "0x" + hex.EncodeToString(agentID[:])}, nil).Once()
+
+		id, err := compat.RegisterAgent(ctx, agentDID, "legacy-agent", "", "https://agent.example.com", `{"chat":true}`, keyPair)
+		require.NoError(t, err)
+		assert.Equal(t, agentID, id)
+	})
+
+	t.Run("RegisterAgent rejects non-JSON capabilities", func(t *testing.T) {
+		_, err := compat.RegisterAgent(ctx, agentDID, "legacy-agent", "", "", "chat,search", keyPair)
+		assert.ErrorContains(t, err, "JSON object")
+	})
+
+	backend.MockResolver.On("Resolve", mock.Anything, agentDID).Return(meta, nil)
+
+	t.Run("Resolve by DID and by agent ID", func(t *testing.T) {
+		got, err := compat.GetAgentByDID(ctx, agentDID)
+		require.NoError(t, err)
+		assert.Equal(t, "legacy-agent", got.Name)
+
+		got, err = compat.GetAgent(ctx, agentID)
+		require.NoError(t, err)
+		assert.Equal(t, agentDID, got.DID)
+
+		active, err := compat.IsAgentActive(ctx, agentID)
+		require.NoError(t, err)
+		assert.True(t, active)
+
+		owned, err := compat.VerifyAgentOwnership(ctx, agentID, "0x742d35cc6634c0532925a3b844bc9e7595f0beef")
+		require.NoError(t, err)
+		assert.True(t, owned)
+		owned, err = compat.VerifyAgentOwnership(ctx, agentID, "0x0000000000000000000000000000000000000001")
+		require.NoError(t, err)
+		assert.False(t, owned)
+	})
+
+	t.Run("GetAgentsByOwner derives agent IDs", func(t *testing.T) {
+		backend.MockResolver.On("ListAgentsByOwner", mock.Anything, owner).Return([]*AgentMetadata{meta}, nil).Once()
+		ids, err := compat.GetAgentsByOwner(ctx, owner)
+		require.NoError(t, err)
+		assert.Equal(t, [][32]byte{agentID}, ids)
+	})
+
+	t.Run("Update and deactivate by agent ID", func(t *testing.T) {
+		backend.MockRegistry.On("Update", mock.Anything, agentDID, map[string]interface{}{
+			"name":         "renamed",
+			"description":  "",
+			"endpoint":     "https://new.example.com",
+			"capabilities": map[string]interface{}{"chat": false},
+		}, keyPair).Return(nil).Once()
+		require.NoError(t, compat.UpdateAgent(ctx, agentID, "renamed", "", "https://new.example.com", `{"chat":false}`, keyPair))
+
+		backend.MockRegistry.On("Deactivate", mock.Anything, agentDID, keyPair).Return(nil).Once()
+		require.NoError(t, compat.DeactivateAgent(ctx, agentID, keyPair))
+	})
+
+	t.Run("Unknown agent ID", func(t *testing.T) {
+		_, err := compat.GetAgent(ctx, [32]byte{0xde, 0xad})
+		assert.ErrorIs(t, err, ErrUnknownAgentID)
+		assert.ErrorIs(t, compat.DeactivateAgent(ctx, [32]byte{0xde, 0xad}, keyPair), ErrUnknownAgentID)
+	})
+
+	t.Run("Unmappable methods", func(t *testing.T) {
+		err := compat.RevokeKey(ctx, []byte{0x04})
+		assert.ErrorIs(t, err, ErrV2Unsupported)
+		var derr DIDError
+		require.ErrorAs(t, err, &derr)
+		assert.Equal(t, "revokeKey", derr.Details["method"])
+
+		_, err = compat.IsKeyValid(ctx, []byte{0x04})
+		assert.ErrorIs(t, err, ErrV2Unsupported)
+	})
+
+	backend.MockRegistry.AssertExpectations(t)
+	backend.MockResolver.AssertExpectations(t)
+}
@@ -21,13 +21,16 @@ package handshake
 import (
 	"context"
 	"crypto"
+	"crypto/ecdh"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sage-x-project/sage/internal/metrics"
 	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/formats"
 	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
 	"github.com/sage-x-project/sage/pkg/agent/transport"
 )
@@ -49,9 +52,8 @@ func (c *Client) Invitation(ctx context.Context, invMsg InvitationMessage, did s
 	start := time.Now()
 	metrics.HandshakesInitiated.WithLabelValues("client").Inc()
 	defer func() {
-		metrics.HandshakeDuration.WithLabelValues(Invitation.String()).Observe(
-			time.Since(start).Seconds(),
-		)
+		metrics.ObserveWithRequestID(metrics.HandshakeDuration.WithLabelValues(Invitation.String()),
+			time.Since(start).Seconds(), transport.RequestIDFromContext(ctx))
 	}()
 
 	payload, err := json.Marshal(invMsg)
@@ -77,13 +79,7 @@ func (c *Client) Invitation(ctx context.Context, invMsg InvitationMessage, did s
 		Metadata:  make(map[string]string),
 	}
 
-	resp, err := c.transport.Send(ctx, msg)
-	if err != nil {
-		metrics.HandshakesFailed.WithLabelValues("send_error").Inc()
-		return nil, err
-	}
-	metrics.HandshakesCompleted.WithLabelValues("success").Inc()
-	return resp, nil
+	return c.send(ctx, msg)
 }
 
 // Request encrypts RequestMessage for the peer using bootstrap envelope.
@@ -91,9 +87,8 @@ func (c *Client) Request(ctx context.Context, reqMsg RequestMessage, edPeerPub c
 	start := time.Now()
 	metrics.HandshakesInitiated.WithLabelValues("client").Inc()
 	defer func() {
-		metrics.HandshakeDuration.WithLabelValues(Request.String()).Observe(
-			time.Since(start).Seconds(),
-		)
+		metrics.ObserveWithRequestID(metrics.HandshakeDuration.WithLabelValues(Request.String()),
+			time.Since(start).Seconds(), transport.RequestIDFromContext(ctx))
 	}()
 
 	reqBytes, err := json.Marshal(reqMsg)
@@ -125,13 +120,66 @@ func (c *Client) Request(ctx context.Context, reqMsg RequestMessage, edPeerPub c
 		Metadata:  make(map[string]string),
 	}
 
-	resp, err := c.transport.Send(ctx, msg)
+	return c.send(ctx, msg)
+}
+
+// OpenResponse decrypts the server's Response to a Request and authenticates it
+// before any session is derived: the envelope must be signed by serverPub, and
+// the server's ephemeral key must carry a serverPub signature over
+// EphemeralSigningInput for this context and selfEph (the client's raw 32-byte
+// ephemeral public key sent in the Request).
+// It returns the response and the server's raw ephemeral public key.
+func (c *Client) OpenResponse(msg *transport.SecureMessage, serverPub crypto.PublicKey, selfEph []byte) (*ResponseMessage, []byte, error) {
+	if msg == nil {
+		return nil, nil, errors.New("empty message")
+	}
+	if phase, err := ParseTaskID(msg.TaskID); err != nil || phase != Response {
+		return nil, nil, fmt.Errorf("not a handshake response: %q", msg.TaskID)
+	}
+
+	if err := verifySignature(msg.Payload, msg.Signature, serverPub); err != nil {
+		metrics.HandshakesFailed.WithLabelValues("signature_error").Inc()
+		return nil, nil, fmt.Errorf("response signature verification failed: %w", err)
+	}
+
+	plain, err := keys.DecryptWithEd25519Peer(c.key.PrivateKey(), msg.Payload)
 	if err != nil {
-		metrics.HandshakesFailed.WithLabelValues("send_error").Inc()
-		return nil, err
+		metrics.HandshakesFailed.WithLabelValues("decrypt_error").Inc()
+		return nil, nil, fmt.Errorf("response decrypt: %w", err)
 	}
-	metrics.HandshakesCompleted.WithLabelValues("success").Inc()
-	return resp, nil
+
+	var res ResponseMessage
+	if err := json.Unmarshal(plain, &res); err != nil {
+		metrics.HandshakesFailed.WithLabelValues("decode_error").Inc()
+		return nil, nil, fmt.Errorf("response json: %w", err)
+	}
+
+	if len(res.EphemeralPubKey) == 0 {
+		metrics.HandshakesFailed.WithLabelValues("invalid_key").Inc()
+		return nil, nil, errors.New("empty server ephemeral public key")
+	}
+	imported, err := formats.NewJWKImporter().ImportPublic([]byte(res.EphemeralPubKey), sagecrypto.KeyFormatJWK)
+	if err != nil {
+		metrics.HandshakesFailed.WithLabelValues("invalid_key").Inc()
+		return nil, nil, fmt.Errorf("import server ephemeral key: %w", err)
+	}
+	serverEph, ok := imported.(*ecdh.PublicKey)
+	if !ok {
+		metrics.HandshakesFailed.WithLabelValues("invalid_key").Inc()
+		return nil, nil, fmt.Errorf("unexpected server eph key type: %T", imported)
+	}
+	serverEphRaw := serverEph.Bytes()
+
+	if len(res.EphemeralSig) == 0 {
+		metrics.HandshakesFailed.WithLabelValues("signature_error").Inc()
+		return nil, nil, fmt.Errorf("%w: missing", ErrInvalidEphemeralSignature)
+	}
+	if err := verifySignature(EphemeralSigningInput(msg.ContextID, serverEphRaw, selfEph), res.EphemeralSig, serverPub); err != nil {
+		metrics.HandshakesFailed.WithLabelValues("signature_error").Inc()
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidEphemeralSignature, err)
+	}
+
+	return &res, serverEphRaw, nil
 }
 
 // Response is sent by the agent back to the initiator (bootstrap envelope).
@@ -139,9 +187,8 @@ func (c *Client) Response(ctx context.Context, resMsg ResponseMessage, edPeerPub
 	start := time.Now()
 	metrics.HandshakesInitiated.WithLabelValues("client").Inc()
 	defer func() {
-		metrics.HandshakeDuration.WithLabelValues(Response.String()).Observe(
-			time.Since(start).Seconds(),
-		)
+		metrics.ObserveWithRequestID(metrics.HandshakeDuration.WithLabelValues(Response.String()),
+			time.Since(start).Seconds(), transport.RequestIDFromContext(ctx))
 	}()
 
 	resBytes, err := json.Marshal(resMsg)
@@ -173,13 +220,7 @@ func (c *Client) Response(ctx context.Context, resMsg ResponseMessage, edPeerPub
 		Metadata:  make(map[string]string),
 	}
 
-	resp, err := c.transport.Send(ctx, msg)
-	if err != nil {
-		metrics.HandshakesFailed.WithLabelValues("send_error").Inc()
-		return nil, err
-	}
-	metrics.HandshakesCompleted.WithLabelValues("success").Inc()
-	return resp, nil
+	return c.send(ctx, msg)
 }
 
 // Complete notifies completion (clear JSON payload).
@@ -187,9 +228,8 @@ func (c *Client) Complete(ctx context.Context, compMsg CompleteMessage, did stri
 	start := time.Now()
 	metrics.HandshakesInitiated.WithLabelValues("client").Inc()
 	defer func() {
-		metrics.HandshakeDuration.WithLabelValues(Complete.String()).Observe(
-			time.Since(start).Seconds(),
-		)
+		metrics.ObserveWithRequestID(metrics.HandshakeDuration.WithLabelValues(Complete.String()),
+			time.Since(start).Seconds(), transport.RequestIDFromContext(ctx))
 	}()
 
 	payload, err := json.Marshal(compMsg)
@@ -215,11 +255,21 @@ func (c *Client) Complete(ctx context.Context, compMsg CompleteMessage, did stri
 		Metadata:  make(map[string]string),
 	}
 
+	return c.send(ctx, msg)
+}
+
+// send delivers msg and turns a rejection carried in the response into a
+// *HandshakeError, so callers can branch on its Code on any transport.
+func (c *Client) send(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
 	resp, err := c.transport.Send(ctx, msg)
 	if err != nil {
 		metrics.HandshakesFailed.WithLabelValues("send_error").Inc()
 		return nil, err
 	}
+	if herr, ok := ErrorFromResponse(resp); ok {
+		metrics.HandshakesFailed.WithLabelValues(string(herr.Code)).Inc()
+		return resp, herr
+	}
 	metrics.HandshakesCompleted.WithLabelValues("success").Inc()
 	return resp, nil
 }
@@ -0,0 +1,192 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+package handshake_test
+
+import (
+	"context"
+	"crypto/ecdh"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	sessioninit "github.com/sage-x-project/sage/internal"
+	"github.com/sage-x-project/sage/pkg/agent/core/message"
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/formats"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	sagedid "github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/handshake"
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+)
+
+// responseFixture is a Client/Server pair where the server's outbound
+// transport captures the Response it sends back to the client.
+type responseFixture struct {
+	alice    *handshake.Client
+	aliceKey sagecrypto.KeyPair
+	bobKey   sagecrypto.KeyPair
+	sent     []*transport.SecureMessage
+}
+
+func setupResponseTest(t *testing.T) *responseFixture {
+	t.Helper()
+	aliceKey, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	bobKey, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+
+	ethResolver := new(mockResolver)
+	ethResolver.On("Resolve", mock.Anything, mock.Anything).Return(&sagedid.AgentMetadata{
+		IsActive:  true,
+		PublicKey: aliceKey.PublicKey(),
+	}, nil)
+	multiResolver := sagedid.NewMultiChainResolver()
+	multiResolver.AddResolver(sagedid.ChainEthereum, ethResolver)
+
+	f := &responseFixture{aliceKey: aliceKey, bobKey: bobKey}
+	outbound := &transport.MockTransport{
+		SendFunc: func(_ context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
+			f.sent = append(f.sent, msg)
+			return &transport.Response{Success: true, MessageID: msg.ID, TaskID: msg.TaskID}, nil
+		},
+	}
+	hs := handshake.NewServer(bobKey, sessioninit.NewCreator(session.NewManager()), multiResolver, nil, 0, outbound)
+	t.Cleanup(func() { handshake.StopCleanupLoop(hs) })
+
+	f.alice = handshake.NewClient(&transport.MockTransport{SendFunc: hs.HandleMessage}, aliceKey)
+	return f
+}
+
+// request runs Invitation and Request for ctxID and returns the server's
+// Response together with Alice's raw ephemeral public key.
+func (f *responseFixture) request(t *testing.T, ctxID string) (*transport.SecureMessage, []byte) {
+	t.Helper()
+	ctx := context.Background()
+	aliceDID := "did:sage:ethereum:agent-" + uuid.NewString()
+
+	_, err := f.alice.Invitation(ctx, handshake.InvitationMessage{
+		BaseMessage: message.BaseMessage{ContextID: ctxID},
+	}, aliceDID)
+	require.NoError(t, err)
+
+	eph, err := keys.GenerateX25519KeyPair()
+	require.NoError(t, err)
+	jwk, err := formats.NewJWKExporter().ExportPublic(eph, sagecrypto.KeyFormatJWK)
+	require.NoError(t, err)
+
+	sent := len(f.sent)
+	_, err = f.alice.Request(ctx, handshake.RequestMessage{
+		BaseMessage:     message.BaseMessage{ContextID: ctxID},
+		EphemeralPubKey: json.RawMessage(jwk),
+	}, f.bobKey.PublicKey(), aliceDID)
+	require.NoError(t, err)
+	require.Len(t, f.sent, sent+1, "server should send a Response")
+
+	return f.sent[sent], eph.PublicKey().(*ecdh.PublicKey).Bytes()
+}
+
+// reseal decrypts a Response addressed to Alice, applies mutate and encrypts
+// it to Alice again, as a man-in-the-middle could without any private key.
+func (f *responseFixture) reseal(t *testing.T, msg *transport.SecureMessage, mutate func(*handshake.ResponseMessage)) *transport.SecureMessage {
+	t.Helper()
+	plain, err := keys.DecryptWithEd25519Peer(f.aliceKey.PrivateKey(), msg.Payload)
+	require.NoError(t, err)
+	var res handshake.ResponseMessage
+	require.NoError(t, json.Unmarshal(plain, &res))
+	mutate(&res)
+	plain, err = json.Marshal(res)
+	require.NoError(t, err)
+	packet, err := keys.EncryptWithEd25519Peer(f.aliceKey.PublicKey(), plain)
+	require.NoError(t, err)
+
+	out := *msg
+	out.Payload = packet
+	return &out
+}
+
+func TestClient_OpenResponse(t *testing.T) {
+	t.Run("Authentic response", func(t *testing.T) {
+		f := setupResponseTest(t)
+		msg, aliceEph := f.request(t, "ctx-"+uuid.NewString())
+
+		res, serverEph, err := f.alice.OpenResponse(msg, f.bobKey.PublicKey(), aliceEph)
+		require.NoError(t, err)
+		assert.True(t, res.Ack)
+		assert.Len(t, serverEph, 32)
+		assert.NotEmpty(t, res.EphemeralSig)
+	})
+
+	t.Run("Tampered ephemeral rejected", func(t *testing.T) {
+		f := setupResponseTest(t)
+		msg, aliceEph := f.request(t, "ctx-"+uuid.NewString())
+
+		attackerEph, err := keys.GenerateX25519KeyPair()
+		require.NoError(t, err)
+		attackerJWK, err := formats.NewJWKExporter().ExportPublic(attackerEph, sagecrypto.KeyFormatJWK)
+		require.NoError(t, err)
+		swap := func(res *handshake.ResponseMessage) { res.EphemeralPubKey = json.RawMessage(attackerJWK) }
+
+		// Re-encrypted envelope no longer matches the server's envelope signature
+		_, _, err = f.alice.OpenResponse(f.reseal(t, msg, swap), f.bobKey.PublicKey(), aliceEph)
+		require.Error(t, err)
+
+		// Even with a valid envelope signature the swapped key is not vouched for
+		tampered := f.reseal(t, msg, swap)
+		tampered.Signature, err = f.bobKey.Sign(tampered.Payload)
+		require.NoError(t, err)
+		_, _, err = f.alice.OpenResponse(tampered, f.bobKey.PublicKey(), aliceEph)
+		require.ErrorIs(t, err, handshake.ErrInvalidEphemeralSignature)
+	})
+
+	t.Run("Missing ephemeral signature rejected", func(t *testing.T) {
+		f := setupResponseTest(t)
+		msg, aliceEph := f.request(t, "ctx-"+uuid.NewString())
+
+		stripped := f.reseal(t, msg, func(res *handshake.ResponseMessage) { res.EphemeralSig = nil })
+		var err error
+		stripped.Signature, err = f.bobKey.Sign(stripped.Payload)
+		require.NoError(t, err)
+		_, _, err = f.alice.OpenResponse(stripped, f.bobKey.PublicKey(), aliceEph)
+		require.ErrorIs(t, err, handshake.ErrInvalidEphemeralSignature)
+	})
+
+	t.Run("Response bound to the client's ephemeral", func(t *testing.T) {
+		f := setupResponseTest(t)
+		msg, _ := f.request(t, "ctx-"+uuid.NewString())
+
+		otherEph, err := keys.GenerateX25519KeyPair()
+		require.NoError(t, err)
+		_, _, err = f.alice.OpenResponse(msg, f.bobKey.PublicKey(), otherEph.PublicKey().(*ecdh.PublicKey).Bytes())
+		require.ErrorIs(t, err, handshake.ErrInvalidEphemeralSignature)
+	})
+
+	t.Run("Wrong server identity rejected", func(t *testing.T) {
+		f := setupResponseTest(t)
+		msg, aliceEph := f.request(t, "ctx-"+uuid.NewString())
+
+		impostor, err := keys.GenerateEd25519KeyPair()
+		require.NoError(t, err)
+		_, _, err = f.alice.OpenResponse(msg, impostor.PublicKey(), aliceEph)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,190 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package handshake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+)
+
+// HandshakeErrorCode identifies why the server rejected a handshake phase.
+// Codes are stable strings and safe to branch on.
+type HandshakeErrorCode string
+
+const (
+	// CodeUnknownDID means the sender DID could not be resolved to an active key
+	CodeUnknownDID HandshakeErrorCode = "unknown_did"
+	// CodeBadSignature means the message signature did not verify
+	CodeBadSignature HandshakeErrorCode = "bad_signature"
+	// CodeBadEphemeral means the peer ephemeral key was missing or invalid
+	CodeBadEphemeral HandshakeErrorCode = "bad_ephemeral"
+	// CodeNoContext means the phase arrived without a live invitation for
+	// its context; the client should restart from Invitation
+	CodeNoContext HandshakeErrorCode = "no_context"
+	// CodeMalformed means the message could not be decoded or decrypted
+	CodeMalformed HandshakeErrorCode = "malformed"
+	// CodeReplay means the message was already seen. It is raised by Events
+	// hooks that track nonces.
+	CodeReplay HandshakeErrorCode = "replay"
+	// CodeContextReused means the context already has a completed session
+	// and the invitation did not ask to resume it
+	CodeContextReused HandshakeErrorCode = "context_reused"
+	// CodeRateLimited means the server refused the handshake under load and
+	// the client may retry later. It is raised by Events hooks.
+	CodeRateLimited HandshakeErrorCode = "rate_limited"
+	// CodeTooManyHandshakes means the sender DID already has
+	// Server.MaxConcurrentHandshakesPerDID handshakes in flight
+	CodeTooManyHandshakes HandshakeErrorCode = "too_many_handshakes"
+	// CodeInternal means the server failed for reasons unrelated to the message
+	CodeInternal HandshakeErrorCode = "internal"
+)
+
+// gRPC status codes (google.golang.org/grpc/codes), listed here so the
+// handshake package does not depend on gRPC.
+const (
+	grpcInvalidArgument    uint32 = 3
+	grpcNotFound           uint32 = 5
+	grpcAlreadyExists      uint32 = 6
+	grpcResourceExhausted  uint32 = 8
+	grpcFailedPrecondition uint32 = 9
+	grpcInternal           uint32 = 13
+	grpcUnauthenticated    uint32 = 16
+)
+
+// GRPCCode returns the gRPC status code a gRPC transport should use for c,
+// as a value of google.golang.org/grpc/codes.Code.
+func (c HandshakeErrorCode) GRPCCode() uint32 {
+	switch c {
+	case CodeUnknownDID:
+		return grpcNotFound
+	case CodeBadSignature:
+		return grpcUnauthenticated
+	case CodeBadEphemeral, CodeMalformed:
+		return grpcInvalidArgument
+	case CodeNoContext:
+		return grpcFailedPrecondition
+	case CodeReplay, CodeContextReused:
+		return grpcAlreadyExists
+	case CodeRateLimited, CodeTooManyHandshakes:
+		return grpcResourceExhausted
+	default:
+		return grpcInternal
+	}
+}
+
+// Retryable reports whether resending the same phase later may succeed.
+// CodeNoContext is not retryable as such: the handshake must restart.
+func (c HandshakeErrorCode) Retryable() bool {
+	return c == CodeRateLimited || c == CodeTooManyHandshakes || c == CodeInternal
+}
+
+// ErrContextReused is returned for an Invitation whose ContextID already
+// completed a handshake, unless the DID that completed it asks to resume.
+// Match it with errors.Is.
+var ErrContextReused = NewHandshakeError(CodeContextReused, "context already has a completed session")
+
+// ErrTooManyHandshakes is returned for an Invitation from a DID that is at
+// its in-flight handshake limit. Match it with errors.Is.
+var ErrTooManyHandshakes = NewHandshakeError(CodeTooManyHandshakes, "too many concurrent handshakes for this DID")
+
+// HandshakeError is the protocol-level error for a rejected handshake phase.
+// The server returns it from HandleMessage and also carries it in the
+// failure Response's Data (as a ResponseMessage), so clients on any
+// transport can recover it with ErrorFromResponse or errors.As.
+type HandshakeError struct {
+	Code    HandshakeErrorCode `json:"code"`
+	Message string             `json:"message"`
+
+	cause error
+}
+
+// NewHandshakeError returns a HandshakeError with the given code. Events
+// hooks return one to reject a phase, e.g. with CodeRateLimited.
+func NewHandshakeError(code HandshakeErrorCode, message string) *HandshakeError {
+	return &HandshakeError{Code: code, Message: message}
+}
+
+// handshakeErrorf builds a HandshakeError whose message is formatted like
+// fmt.Errorf; a %w operand becomes its cause.
+func handshakeErrorf(code HandshakeErrorCode, format string, args ...any) *HandshakeError {
+	err := fmt.Errorf(format, args...)
+	return &HandshakeError{Code: code, Message: err.Error(), cause: errors.Unwrap(err)}
+}
+
+func (e *HandshakeError) Error() string {
+	return fmt.Sprintf("handshake %s: %s", e.Code, e.Message)
+}
+
+// Unwrap returns the server-side cause, if known. It is not sent to the peer.
+func (e *HandshakeError) Unwrap() error {
+	return e.cause
+}
+
+// Is matches another *HandshakeError with the same code, so
+// errors.Is(err, NewHandshakeError(CodeReplay, "")) tests the code.
+func (e *HandshakeError) Is(target error) bool {
+	var t *HandshakeError
+	return errors.As(target, &t) && t.Code == e.Code
+}
+
+// ErrorFromResponse extracts the HandshakeError carried by a failed
+// handshake Response, as delivered by transports that return the response
+// body instead of the server's Go error.
+func ErrorFromResponse(resp *transport.Response) (*HandshakeError, bool) {
+	if resp == nil {
+		return nil, false
+	}
+	var herr *HandshakeError
+	if errors.As(resp.Error, &herr) {
+		return herr, true
+	}
+	if resp.Success || len(resp.Data) == 0 {
+		return nil, false
+	}
+	var res ResponseMessage
+	if err := json.Unmarshal(resp.Data, &res); err != nil || res.Error == nil || res.Error.Code == "" {
+		return nil, false
+	}
+	return res.Error, true
+}
+
+// failureResponse returns the Response and error for a rejected phase.
+func failureResponse(msg *transport.SecureMessage, herr *HandshakeError) (*transport.Response, error) {
+	data, _ := json.Marshal(ResponseMessage{Error: herr})
+	return &transport.Response{
+		Success:   false,
+		MessageID: msg.ID,
+		TaskID:    msg.TaskID,
+		Data:      data,
+		Error:     herr,
+	}, herr
+}
+
+// hookError returns err as a HandshakeError if an Events hook rejected the
+// phase with one. Other hook errors are ignored.
+func hookError(err error) (*HandshakeError, bool) {
+	var herr *HandshakeError
+	if errors.As(err, &herr) {
+		return herr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,309 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package handshake_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sage-x-project/sage/pkg/agent/core/message"
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	sagedid "github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/handshake"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+	transporthttp "github.com/sage-x-project/sage/pkg/agent/transport/http"
+)
+
+// admissionEvents rejects invitations through the Events hook: a reused
+// nonce is a replay, and every invitation past limit is rate limited.
+type admissionEvents struct {
+	handshake.NoopEvents
+	mu     sync.Mutex
+	nonces map[string]bool
+	limit  int
+	seen   int
+}
+
+func (e *admissionEvents) OnInvitation(_ context.Context, _ string, inv handshake.InvitationMessage) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.nonces[inv.Nonce] {
+		return handshake.NewHandshakeError(handshake.CodeReplay, "invitation nonce already used")
+	}
+	e.nonces[inv.Nonce] = true
+	if e.seen++; e.seen > e.limit {
+		return handshake.NewHandshakeError(handshake.CodeRateLimited, "too many handshakes")
+	}
+	return nil
+}
+
+type errorFixture struct {
+	server   *handshake.Server
+	alice    *handshake.Client
+	aliceKey sagecrypto.KeyPair
+	bobKey   sagecrypto.KeyPair
+}
+
+const unknownDID = "did:sage:ethereum:agent-unknown"
+
+func setupErrorTest(t *testing.T, events handshake.Events) *errorFixture {
+	t.Helper()
+	aliceKey, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	bobKey, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+
+	ethResolver := new(mockResolver)
+	ethResolver.On("Resolve", mock.Anything, sagedid.AgentDID(unknownDID)).Return(nil, errors.New("agent not found"))
+	ethResolver.On("Resolve", mock.Anything, mock.Anything).Return(&sagedid.AgentMetadata{
+		IsActive:  true,
+		PublicKey: aliceKey.PublicKey(),
+	}, nil)
+	multiResolver := sagedid.NewMultiChainResolver()
+	multiResolver.AddResolver(sagedid.ChainEthereum, ethResolver)
+
+	hs := handshake.NewServer(bobKey, events, multiResolver, nil, 0, nil)
+	t.Cleanup(func() { handshake.StopCleanupLoop(hs) })
+
+	return &errorFixture{
+		server:   hs,
+		alice:    handshake.NewClient(&transport.MockTransport{SendFunc: hs.HandleMessage}, aliceKey),
+		aliceKey: aliceKey,
+		bobKey:   bobKey,
+	}
+}
+
+func (f *errorFixture) invite(ctxID, nonce string) error {
+	_, err := f.alice.Invitation(context.Background(), handshake.InvitationMessage{
+		BaseMessage:          message.BaseMessage{ContextID: ctxID},
+		MessageControlHeader: message.MessageControlHeader{Nonce: nonce},
+	}, "did:sage:ethereum:agent-"+ctxID)
+	return err
+}
+
+func (f *errorFixture) request(ctxID string, eph json.RawMessage) error {
+	_, err := f.alice.Request(context.Background(), handshake.RequestMessage{
+		BaseMessage:     message.BaseMessage{ContextID: ctxID},
+		EphemeralPubKey: eph,
+	}, f.bobKey.PublicKey(), "did:sage:ethereum:agent-"+ctxID)
+	return err
+}
+
+func requireCode(t *testing.T, err error, code handshake.HandshakeErrorCode) {
+	t.Helper()
+	var herr *handshake.HandshakeError
+	require.True(t, errors.As(err, &herr), "expected *HandshakeError, got %v", err)
+	assert.Equal(t, code, herr.Code)
+	assert.True(t, errors.Is(err, handshake.NewHandshakeError(code, "")))
+}
+
+func TestServer_HandshakeErrorCodes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Unknown DID", func(t *testing.T) {
+		f := setupErrorTest(t, nil)
+		_, err := f.alice.Invitation(ctx, handshake.InvitationMessage{
+			BaseMessage: message.BaseMessage{ContextID: "ctx-" + uuid.NewString()},
+		}, unknownDID)
+		requireCode(t, err, handshake.CodeUnknownDID)
+	})
+
+	t.Run("Bad signature", func(t *testing.T) {
+		f := setupErrorTest(t, nil)
+		payload, _ := json.Marshal(handshake.InvitationMessage{})
+		sig, err := f.bobKey.Sign(payload) // not Alice's key
+		require.NoError(t, err)
+		_, err = f.server.HandleMessage(ctx, &transport.SecureMessage{
+			ID:        uuid.NewString(),
+			ContextID: "ctx-" + uuid.NewString(),
+			TaskID:    handshake.GenerateTaskID(handshake.Invitation),
+			Payload:   payload,
+			DID:       "did:sage:ethereum:agent-alice",
+			Signature: sig,
+		})
+		requireCode(t, err, handshake.CodeBadSignature)
+	})
+
+	t.Run("Bad ephemeral", func(t *testing.T) {
+		f := setupErrorTest(t, nil)
+		ctxID := "ctx-" + uuid.NewString()
+		require.NoError(t, f.invite(ctxID, ""))
+
+		requireCode(t, f.request(ctxID, nil), handshake.CodeBadEphemeral)
+		// The failure aborted the handshake
+		requireCode(t, f.request(ctxID, nil), handshake.CodeNoContext)
+
+		require.NoError(t, f.invite(ctxID, ""))
+		requireCode(t, f.request(ctxID, json.RawMessage(`{"kty":"OKP","crv":"X25519","x":"AAAA"}`)), handshake.CodeBadEphemeral)
+	})
+
+	t.Run("No context", func(t *testing.T) {
+		f := setupErrorTest(t, nil)
+		requireCode(t, f.request("ctx-"+uuid.NewString(), nil), handshake.CodeNoContext)
+	})
+
+	t.Run("Malformed task", func(t *testing.T) {
+		f := setupErrorTest(t, nil)
+		_, err := f.server.HandleMessage(ctx, &transport.SecureMessage{ID: uuid.NewString(), TaskID: "bogus"})
+		requireCode(t, err, handshake.CodeMalformed)
+	})
+
+	t.Run("Replay and rate limit from Events", func(t *testing.T) {
+		f := setupErrorTest(t, &admissionEvents{nonces: make(map[string]bool), limit: 2})
+
+		require.NoError(t, f.invite("ctx-"+uuid.NewString(), "n1"))
+		requireCode(t, f.invite("ctx-"+uuid.NewString(), "n1"), handshake.CodeReplay)
+		require.NoError(t, f.invite("ctx-"+uuid.NewString(), "n2"))
+		requireCode(t, f.invite("ctx-"+uuid.NewString(), "n3"), handshake.CodeRateLimited)
+	})
+
+	t.Run("Per-DID handshake limit", func(t *testing.T) {
+		f := setupErrorTest(t, nil)
+		f.server.MaxConcurrentHandshakesPerDID = 2
+		const aliceDID, carolDID = "did:sage:ethereum:agent-alice", "did:sage:ethereum:agent-carol"
+		invite := func(ctxID, did string) error {
+			_, err := f.alice.Invitation(ctx, handshake.InvitationMessage{
+				BaseMessage: message.BaseMessage{ContextID: ctxID},
+			}, did)
+			return err
+		}
+
+		ctx1, ctx2 := "ctx-"+uuid.NewString(), "ctx-"+uuid.NewString()
+		require.NoError(t, invite(ctx1, aliceDID))
+		require.NoError(t, invite(ctx2, aliceDID))
+		err := invite("ctx-"+uuid.NewString(), aliceDID)
+		require.ErrorIs(t, err, handshake.ErrTooManyHandshakes)
+		requireCode(t, err, handshake.CodeTooManyHandshakes)
+
+		// A repeated invitation for an open handshake keeps its slot
+		require.NoError(t, invite(ctx1, aliceDID))
+		// Other DIDs have their own budget
+		require.NoError(t, invite("ctx-"+uuid.NewString(), carolDID))
+		require.NoError(t, invite("ctx-"+uuid.NewString(), carolDID))
+
+		// Completing a handshake frees its slot
+		_, err = f.alice.Complete(ctx, handshake.CompleteMessage{
+			BaseMessage: message.BaseMessage{ContextID: ctx1},
+		}, aliceDID)
+		require.NoError(t, err)
+		require.NoError(t, invite("ctx-"+uuid.NewString(), aliceDID))
+		require.ErrorIs(t, invite("ctx-"+uuid.NewString(), aliceDID), handshake.ErrTooManyHandshakes)
+
+		// A handshake failing in the Request phase frees its slot as well
+		_, err = f.alice.Request(ctx, handshake.RequestMessage{
+			BaseMessage: message.BaseMessage{ContextID: ctx2},
+		}, f.bobKey.PublicKey(), aliceDID)
+		requireCode(t, err, handshake.CodeBadEphemeral)
+		require.NoError(t, invite("ctx-"+uuid.NewString(), aliceDID))
+		require.ErrorIs(t, invite("ctx-"+uuid.NewString(), aliceDID), handshake.ErrTooManyHandshakes)
+	})
+
+	t.Run("Failed phases abort the handshake", func(t *testing.T) {
+		f := setupErrorTest(t, nil)
+		f.server.MaxConcurrentHandshakesPerDID = 1
+		const aliceDID = "did:sage:ethereum:agent-alice"
+		malloryKey, err := keys.GenerateEd25519KeyPair()
+		require.NoError(t, err)
+		mallory := handshake.NewClient(&transport.MockTransport{SendFunc: f.server.HandleMessage}, malloryKey)
+		invite := func(ctxID string) error {
+			_, err := f.alice.Invitation(ctx, handshake.InvitationMessage{
+				BaseMessage: message.BaseMessage{ContextID: ctxID},
+			}, aliceDID)
+			return err
+		}
+		complete := func(c *handshake.Client, ctxID string) error {
+			_, err := c.Complete(ctx, handshake.CompleteMessage{
+				BaseMessage: message.BaseMessage{ContextID: ctxID},
+			}, aliceDID)
+			return err
+		}
+
+		// Forged phases neither free alice's slot nor disturb her handshake
+		ctx1 := "ctx-" + uuid.NewString()
+		require.NoError(t, invite(ctx1))
+		_, err = mallory.Request(ctx, handshake.RequestMessage{
+			BaseMessage: message.BaseMessage{ContextID: ctx1},
+		}, f.bobKey.PublicKey(), aliceDID)
+		requireCode(t, err, handshake.CodeBadSignature)
+		requireCode(t, complete(mallory, ctx1), handshake.CodeBadSignature)
+		require.ErrorIs(t, invite("ctx-"+uuid.NewString()), handshake.ErrTooManyHandshakes)
+
+		// A failed Request frees the slot, and the handshake cannot then be
+		// continued without taking a slot again
+		_, err = f.alice.Request(ctx, handshake.RequestMessage{
+			BaseMessage: message.BaseMessage{ContextID: ctx1},
+		}, f.bobKey.PublicKey(), aliceDID)
+		requireCode(t, err, handshake.CodeBadEphemeral)
+		ctx2 := "ctx-" + uuid.NewString()
+		require.NoError(t, invite(ctx2))
+		requireCode(t, complete(f.alice, ctx1), handshake.CodeNoContext)
+		require.ErrorIs(t, invite("ctx-"+uuid.NewString()), handshake.ErrTooManyHandshakes)
+
+		// A completed handshake no longer holds a slot, so Complete cannot be
+		// replayed on it
+		require.NoError(t, complete(f.alice, ctx2))
+		requireCode(t, complete(f.alice, ctx2), handshake.CodeNoContext)
+	})
+
+	t.Run("Carried in the response over HTTP", func(t *testing.T) {
+		f := setupErrorTest(t, nil)
+		client, stop := transporthttp.NewInProcessTransport(f.server.HandleMessage)
+		defer func() { _ = stop() }()
+		alice := handshake.NewClient(client, f.aliceKey)
+
+		resp, err := alice.Request(ctx, handshake.RequestMessage{
+			BaseMessage: message.BaseMessage{ContextID: "ctx-" + uuid.NewString()},
+		}, f.bobKey.PublicKey(), "did:sage:ethereum:agent-alice")
+		requireCode(t, err, handshake.CodeNoContext)
+
+		herr, ok := handshake.ErrorFromResponse(resp)
+		require.True(t, ok)
+		assert.Equal(t, handshake.CodeNoContext, herr.Code)
+		assert.Contains(t, herr.Message, "no cached peer")
+	})
+}
+
+func TestHandshakeErrorCode_GRPCCode(t *testing.T) {
+	for code, want := range map[handshake.HandshakeErrorCode]uint32{
+		handshake.CodeUnknownDID:        5,  // NotFound
+		handshake.CodeBadSignature:      16, // Unauthenticated
+		handshake.CodeBadEphemeral:      3,  // InvalidArgument
+		handshake.CodeMalformed:         3,  // InvalidArgument
+		handshake.CodeNoContext:         9,  // FailedPrecondition
+		handshake.CodeReplay:            6,  // AlreadyExists
+		handshake.CodeContextReused:     6,  // AlreadyExists
+		handshake.CodeRateLimited:       8,  // ResourceExhausted
+		handshake.CodeTooManyHandshakes: 8,  // ResourceExhausted
+		handshake.CodeInternal:          13, // Internal
+	} {
+		assert.Equal(t, want, code.GRPCCode(), code)
+	}
+	assert.True(t, handshake.CodeRateLimited.Retryable())
+	assert.True(t, handshake.CodeTooManyHandshakes.Retryable())
+	assert.False(t, handshake.CodeBadSignature.Retryable())
+}
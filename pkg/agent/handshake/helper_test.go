@@ -30,6 +30,14 @@ func HasPeer(s *Server, ctxID string) bool {
 	return ok
 }
 
+// PeerDID returns the DID cached for the given context identifier.
+func PeerDID(s *Server, ctxID string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	peer, ok := s.peers[ctxID]
+	return peer.did, ok
+}
+
 // HasPending reports whether the server has pending state for the given context.
 func HasPending(s *Server, ctxID string) bool {
 	s.mu.Lock()
@@ -69,6 +69,22 @@ type Server struct {
 	sessionCfg session.Config
 
 	peers map[string]cachedPeer
+	// completed maps contexts that finished a handshake to the DID that
+	// finished it; a new Invitation for them must come from that DID and ask
+	// to resume.
+	completed map[string]completedContext
+	// inflight maps a sender DID to its open handshakes: context ID to when
+	// the slot expires if Complete never arrives.
+	inflight map[string]map[string]time.Time
+
+	// MaxConcurrentHandshakesPerDID caps the handshakes a single DID may have
+	// between Invitation and Complete; further invitations are rejected with
+	// ErrTooManyHandshakes. A handshake that fails after its Invitation is
+	// aborted and frees its slot, and Request or Complete for a context
+	// without a slot is rejected with CodeNoContext. It is independent of
+	// any rate limit applied by Events hooks. Zero means no limit. Set it
+	// before handling messages.
+	MaxConcurrentHandshakesPerDID int
 	// TTL and cleaner
 	pendingTTL    time.Duration
 	cleanupTicker *time.Ticker
@@ -77,6 +93,9 @@ type Server struct {
 
 	exporter sagecrypto.KeyExporter
 	importer sagecrypto.KeyImporter
+
+	// transcripts is nil unless EnableTranscripts was called.
+	transcripts *transcriptLog
 }
 
 type cachedPeer struct {
@@ -85,6 +104,11 @@ type cachedPeer struct {
 	expires time.Time
 }
 
+type completedContext struct {
+	did     string
+	expires time.Time
+}
+
 // NewServer creates a server with required dependencies.
 // - events: application-level hooks (can be NoopEvents{})
 // - transport: optional transport for sending responses (can be nil)
@@ -118,6 +142,8 @@ func NewServer(
 		transport:   t,
 		pending:     make(map[string]pendingState),
 		peers:       make(map[string]cachedPeer),
+		completed:   make(map[string]completedContext),
+		inflight:    make(map[string]map[string]time.Time),
 		sessionCfg:  cfg,
 		exporter:    formats.NewJWKExporter(),
 		importer:    formats.NewJWKImporter(),
@@ -145,30 +171,30 @@ func (s *Server) HandleMessage(ctx context.Context, msg *transport.SecureMessage
 	if msg == nil {
 		return nil, errors.New("empty message")
 	}
+	ctx, requestID := transport.EnsureRequestID(ctx, msg)
 
-	phase, err := parsePhase(msg.TaskID)
+	phase, err := ParseTaskID(msg.TaskID)
 	if err != nil {
-		return nil, err
+		return failureResponse(msg, handshakeErrorf(CodeMalformed, "%w", err))
 	}
 
 	// Track handshake initiation and duration
 	metrics.HandshakesInitiated.WithLabelValues("server").Inc()
 	defer func() {
-		metrics.HandshakeDuration.WithLabelValues(phase.String()).Observe(
-			time.Since(start).Seconds(),
-		)
+		metrics.ObserveWithRequestID(metrics.HandshakeDuration.WithLabelValues(phase.String()),
+			time.Since(start).Seconds(), requestID)
 	}()
 
 	switch phase {
 
 	case Invitation:
 		if msg.DID == "" {
-			return nil, errors.New("missing did in invitation")
+			return failureResponse(msg, NewHandshakeError(CodeMalformed, "missing did in invitation"))
 		}
 		senderDID := msg.DID
 
 		if s.resolver == nil {
-			return nil, errors.New("cannot resolve sender pubkey: resolver not set")
+			return failureResponse(msg, NewHandshakeError(CodeInternal, "cannot resolve sender pubkey: resolver not set"))
 		}
 
 		// Use singleflight for both cache check and resolve to prevent race conditions
@@ -178,43 +204,49 @@ func (s *Server) HandleMessage(ctx context.Context, msg *transport.SecureMessage
 				return cache.pub, nil
 			}
 
-			// Resolve public key from DID
-			pub, err := s.resolver.ResolvePublicKey(ctx, did.AgentDID(senderDID))
-			if err != nil {
-				return nil, err
-			}
-
-			// Cache the resolved public key for future requests
-			if pubKey, ok := pub.(crypto.PublicKey); ok {
-				s.savePeer(msg.ContextID, pubKey, senderDID)
-			}
-
-			return pub, nil
+			// Resolve public key from DID; it is cached only once the
+			// invitation has been accepted.
+			return s.resolver.ResolvePublicKey(ctx, did.AgentDID(senderDID))
 		})
 
 		if err != nil || v == nil {
-			return nil, errors.New("cannot resolve sender pubkey")
+			herr := NewHandshakeError(CodeUnknownDID, "cannot resolve sender pubkey")
+			herr.cause = err
+			return failureResponse(msg, herr)
 		}
 
 		var senderPub crypto.PublicKey
 		var okType bool
 		senderPub, okType = v.(crypto.PublicKey)
 		if !okType {
-			return nil, fmt.Errorf("resolver returned unexpected key type: %T", v)
+			return failureResponse(msg, handshakeErrorf(CodeInternal, "resolver returned unexpected key type: %T", v))
 		}
 
 		// Verify sender signature
-		if err := s.verifySignature(msg.Payload, msg.Signature, senderPub); err != nil {
+		if err := verifySignature(msg.Payload, msg.Signature, senderPub); err != nil {
 			metrics.HandshakesFailed.WithLabelValues("signature_error").Inc()
-			return nil, fmt.Errorf("signature verification failed: %w", err)
+			return failureResponse(msg, handshakeErrorf(CodeBadSignature, "signature verification failed: %w", err))
 		}
 
 		var inv InvitationMessage
 		if err := json.Unmarshal(msg.Payload, &inv); err != nil {
 			metrics.HandshakesFailed.WithLabelValues("decode_error").Inc()
-			return nil, fmt.Errorf("invitation decode: %w", err)
+			return failureResponse(msg, handshakeErrorf(CodeMalformed, "invitation decode: %w", err))
 		}
-		_ = s.events.OnInvitation(ctx, msg.ContextID, inv)
+		if owner, done := s.completedBy(msg.ContextID); done && (!inv.Resume || owner != senderDID) {
+			metrics.HandshakesFailed.WithLabelValues(string(CodeContextReused)).Inc()
+			return failureResponse(msg, ErrContextReused)
+		}
+		if !s.acquireSlot(senderDID, msg.ContextID) {
+			metrics.HandshakesFailed.WithLabelValues(string(CodeTooManyHandshakes)).Inc()
+			return failureResponse(msg, ErrTooManyHandshakes)
+		}
+		if herr, ok := hookError(s.events.OnInvitation(ctx, msg.ContextID, inv)); ok {
+			metrics.HandshakesFailed.WithLabelValues(string(herr.Code)).Inc()
+			return s.failInFlight(msg, senderDID, herr)
+		}
+		s.savePeer(msg.ContextID, senderPub, senderDID)
+		s.recordPhase(Invitation, msg, senderDID)
 		metrics.HandshakesCompleted.WithLabelValues("success").Inc()
 		return s.ackResponse(msg, "invitation_received")
 
@@ -222,90 +254,123 @@ func (s *Server) HandleMessage(ctx context.Context, msg *transport.SecureMessage
 		cache, ok := s.getPeer(msg.ContextID)
 		if !ok {
 			metrics.HandshakesFailed.WithLabelValues("missing_context").Inc()
-			return nil, errors.New("no cached peer for context; invitation required first")
+			return failureResponse(msg, NewHandshakeError(CodeNoContext, "no cached peer for context; invitation required first"))
 		}
 
-		if err := s.verifySignature(msg.Payload, msg.Signature, cache.pub); err != nil {
+		if err := verifySignature(msg.Payload, msg.Signature, cache.pub); err != nil {
 			metrics.HandshakesFailed.WithLabelValues("signature_error").Inc()
-			return nil, fmt.Errorf("request signature verification failed: %w", err)
+			return failureResponse(msg, handshakeErrorf(CodeBadSignature, "request signature verification failed: %w", err))
+		}
+		if !s.holdsSlot(cache.did, msg.ContextID) {
+			metrics.HandshakesFailed.WithLabelValues("missing_context").Inc()
+			return s.failInFlight(msg, cache.did, errSlotReleased)
 		}
 
 		plain, err := keys.DecryptWithEd25519Peer(s.key.PrivateKey(), msg.Payload)
 		if err != nil {
 			metrics.HandshakesFailed.WithLabelValues("decrypt_error").Inc()
-			return nil, fmt.Errorf("request decrypt: %w", err)
+			return s.failInFlight(msg, cache.did, handshakeErrorf(CodeMalformed, "request decrypt: %w", err))
 		}
 
 		var req RequestMessage
 		if err := json.Unmarshal(plain, &req); err != nil {
 			metrics.HandshakesFailed.WithLabelValues("decode_error").Inc()
-			return nil, fmt.Errorf("request json: %w", err)
+			return s.failInFlight(msg, cache.did, handshakeErrorf(CodeMalformed, "request json: %w", err))
 		}
 
 		if len(req.EphemeralPubKey) == 0 {
 			metrics.HandshakesFailed.WithLabelValues("invalid_key").Inc()
-			return nil, fmt.Errorf("empty peer ephemeral public key")
+			return s.failInFlight(msg, cache.did, NewHandshakeError(CodeBadEphemeral, "empty peer ephemeral public key"))
 		}
 
 		exported, err := s.importer.ImportPublic([]byte(req.EphemeralPubKey), sagecrypto.KeyFormatJWK)
 		if err != nil {
 			metrics.HandshakesFailed.WithLabelValues("invalid_key").Inc()
-			return nil, fmt.Errorf("import peer ephemeral key: %w", err)
+			return s.failInFlight(msg, cache.did, handshakeErrorf(CodeBadEphemeral, "import peer ephemeral key: %w", err))
 		}
 		peerPub, ok := exported.(*ecdh.PublicKey)
 		if !ok {
 			metrics.HandshakesFailed.WithLabelValues("invalid_key").Inc()
-			return nil, fmt.Errorf("unexpected peer eph key type: %T", peerPub)
+			return s.failInFlight(msg, cache.did, handshakeErrorf(CodeBadEphemeral, "unexpected peer eph key type: %T", exported))
 		}
 		peerEphRaw := peerPub.Bytes()
 		if len(peerEphRaw) != 32 {
 			metrics.HandshakesFailed.WithLabelValues("invalid_key").Inc()
-			return nil, fmt.Errorf("invalid peer eph length: %d", len(peerEphRaw))
+			return s.failInFlight(msg, cache.did, handshakeErrorf(CodeBadEphemeral, "invalid peer eph length: %d", len(peerEphRaw)))
 		}
 
 		serverEphRaw, serverEphJWK, err := s.events.AskEphemeral(ctx, msg.ContextID)
 		if err != nil {
 			metrics.HandshakesFailed.WithLabelValues("ephemeral_error").Inc()
-			return nil, fmt.Errorf("ask ephemeral: %w", err)
+			if herr, ok := hookError(err); ok {
+				return s.failInFlight(msg, cache.did, herr)
+			}
+			return s.failInFlight(msg, cache.did, handshakeErrorf(CodeInternal, "ask ephemeral: %w", err))
 		}
 		if len(serverEphRaw) != 32 {
 			metrics.HandshakesFailed.WithLabelValues("ephemeral_error").Inc()
-			return nil, fmt.Errorf("invalid server eph length: %d", len(serverEphRaw))
+			return s.failInFlight(msg, cache.did, handshakeErrorf(CodeInternal, "invalid server eph length: %d", len(serverEphRaw)))
 		}
 
+		if herr, ok := hookError(s.events.OnRequest(ctx, msg.ContextID, req, cache.pub)); ok {
+			metrics.HandshakesFailed.WithLabelValues(string(herr.Code)).Inc()
+			return s.failInFlight(msg, cache.did, herr)
+		}
 		s.savePending(msg.ContextID, pendingState{
 			peerEph:   append([]byte(nil), peerEphRaw...),
 			serverEph: append([]byte(nil), serverEphRaw...),
 		})
-		_ = s.events.OnRequest(ctx, msg.ContextID, req, cache.pub)
+		s.recordPhase(Request, msg, cache.did)
+
+		// Vouch for the ephemeral key with the identity key so the client can
+		// tell it was not swapped in transit.
+		ephSig, err := s.key.Sign(EphemeralSigningInput(msg.ContextID, serverEphRaw, peerEphRaw))
+		if err != nil {
+			metrics.HandshakesFailed.WithLabelValues("sign_error").Inc()
+			return s.failInFlight(msg, cache.did, handshakeErrorf(CodeInternal, "sign ephemeral: %w", err))
+		}
 
 		// Optionally respond immediately to the peer.
 		res := ResponseMessage{
 			EphemeralPubKey: json.RawMessage(serverEphJWK),
+			EphemeralSig:    ephSig,
 			Ack:             true,
 		}
 
+		resp, err := s.sendResponseToPeer(ctx, res, msg.ContextID, cache.pub, cache.did)
+		if err != nil {
+			s.abortContext(cache.did, msg.ContextID)
+			return nil, err
+		}
 		metrics.HandshakesCompleted.WithLabelValues("success").Inc()
-		return s.sendResponseToPeer(ctx, res, msg.ContextID, cache.pub, cache.did)
+		return resp, nil
 
 	case Complete:
 		cache, ok := s.getPeer(msg.ContextID)
 		if !ok {
 			metrics.HandshakesFailed.WithLabelValues("missing_context").Inc()
-			return nil, errors.New("no cached peer for context; invitation required first")
+			return failureResponse(msg, NewHandshakeError(CodeNoContext, "no cached peer for context; invitation required first"))
 		}
 
-		if err := s.verifySignature(msg.Payload, msg.Signature, cache.pub); err != nil {
+		if err := verifySignature(msg.Payload, msg.Signature, cache.pub); err != nil {
 			metrics.HandshakesFailed.WithLabelValues("signature_error").Inc()
-			return nil, fmt.Errorf("complete signature verification failed: %w", err)
+			return failureResponse(msg, handshakeErrorf(CodeBadSignature, "complete signature verification failed: %w", err))
+		}
+		if !s.holdsSlot(cache.did, msg.ContextID) {
+			metrics.HandshakesFailed.WithLabelValues("missing_context").Inc()
+			return s.failInFlight(msg, cache.did, errSlotReleased)
 		}
 
 		var comp CompleteMessage
 		_ = json.Unmarshal(msg.Payload, &comp) // best-effort
+		s.recordPhase(Complete, msg, cache.did)
+		s.releaseSlot(cache.did, msg.ContextID)
 
 		st, ok := s.takePending(msg.ContextID)
 		if !ok {
 			_ = s.events.OnComplete(ctx, msg.ContextID, comp, session.Params{})
+			s.markCompleted(msg.ContextID, cache.did)
+			s.completeTranscript(msg.ContextID, "")
 			metrics.HandshakesCompleted.WithLabelValues("success").Inc()
 			return s.ackResponse(msg, "complete_received_no_pending")
 		}
@@ -315,12 +380,15 @@ func (s *Server) HandleMessage(ctx context.Context, msg *transport.SecureMessage
 			SelfEph:   st.serverEph,
 			PeerEph:   st.peerEph,
 			Label:     "a2a/handshake v1",
+			PeerDID:   cache.did,
 		}
 
 		_ = s.events.OnComplete(ctx, msg.ContextID, comp, sessParams)
+		s.markCompleted(msg.ContextID, cache.did)
 
 		if binder, ok := any(s.events).(KeyIDBinder); ok && cache.pub != nil {
 			if kid, ok2 := binder.IssueKeyID(msg.ContextID); ok2 && kid != "" {
+				s.completeTranscript(msg.ContextID, kid)
 				res := ResponseMessage{
 					Ack:   true,
 					KeyID: kid,
@@ -329,11 +397,12 @@ func (s *Server) HandleMessage(ctx context.Context, msg *transport.SecureMessage
 				return s.sendResponseToPeer(ctx, res, msg.ContextID, cache.pub, cache.did)
 			}
 		}
+		s.completeTranscript(msg.ContextID, "")
 		metrics.HandshakesCompleted.WithLabelValues("success").Inc()
 		return s.ackResponse(msg, "complete_received_session_ready")
 
 	default:
-		return nil, errors.New("unknown phase")
+		return failureResponse(msg, NewHandshakeError(CodeMalformed, "unknown phase"))
 	}
 }
 
@@ -379,7 +448,7 @@ func (s *Server) sendResponseToPeer(ctx context.Context, res ResponseMessage, ct
 }
 
 // verifySignature checks the signature against the payload.
-func (s *Server) verifySignature(payload, signature []byte, senderPub crypto.PublicKey) error {
+func verifySignature(payload, signature []byte, senderPub crypto.PublicKey) error {
 	if len(signature) == 0 {
 		return errors.New("missing signature")
 	}
@@ -443,6 +512,111 @@ func (s *Server) getPeer(ctxID string) (cachedPeer, bool) {
 	return cp, ok
 }
 
+// markCompleted records that did finished a handshake on ctxID. The marker
+// lives as long as a session may, so the context cannot be reused while its
+// session could still be alive.
+func (s *Server) markCompleted(ctxID, did string) {
+	ttl := s.sessionCfg.MaxAge
+	if ttl <= 0 {
+		ttl = s.pendingTTL
+	}
+	s.mu.Lock()
+	s.completed[ctxID] = completedContext{did: did, expires: time.Now().Add(ttl)}
+	s.mu.Unlock()
+}
+
+// completedBy reports the DID that completed a handshake on ctxID, if the
+// marker is still live.
+func (s *Server) completedBy(ctxID string) (string, bool) {
+	s.mu.Lock()
+	cc, ok := s.completed[ctxID]
+	s.mu.Unlock()
+	if !ok || !time.Now().Before(cc.expires) {
+		return "", false
+	}
+	return cc.did, true
+}
+
+// acquireSlot records ctxID as an in-flight handshake of did, reporting
+// false if did is at MaxConcurrentHandshakesPerDID. A repeated Invitation for
+// a context already in flight reuses its slot.
+func (s *Server) acquireSlot(did, ctxID string) bool {
+	if s.MaxConcurrentHandshakesPerDID <= 0 {
+		return true
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	slots := s.inflight[did]
+	if slots == nil {
+		slots = make(map[string]time.Time)
+		s.inflight[did] = slots
+	}
+	for id, expires := range slots {
+		if now.After(expires) {
+			delete(slots, id)
+		}
+	}
+	if _, ok := slots[ctxID]; !ok && len(slots) >= s.MaxConcurrentHandshakesPerDID {
+		return false
+	}
+	slots[ctxID] = now.Add(s.pendingTTL)
+	return true
+}
+
+// errSlotReleased rejects Request and Complete for a context whose
+// in-flight slot was released or expired; the handshake must restart.
+var errSlotReleased = NewHandshakeError(CodeNoContext, "handshake no longer in flight; invitation required first")
+
+// holdsSlot reports whether ctxID still holds an in-flight slot of did. It
+// is always true when MaxConcurrentHandshakesPerDID is not set.
+func (s *Server) holdsSlot(did, ctxID string) bool {
+	if s.MaxConcurrentHandshakesPerDID <= 0 {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expires, ok := s.inflight[did][ctxID]
+	return ok && time.Now().Before(expires)
+}
+
+// failInFlight aborts the handshake on msg's context before reporting herr,
+// so it neither holds its slot until it expires nor continues without one:
+// a new Invitation is required. Call it only once msg is authenticated, or
+// anyone knowing a context ID could abort another DID's handshake.
+func (s *Server) failInFlight(msg *transport.SecureMessage, did string, herr *HandshakeError) (*transport.Response, error) {
+	s.abortContext(did, msg.ContextID)
+	return failureResponse(msg, herr)
+}
+
+// abortContext drops the cached peer, pending state and in-flight slot of
+// ctxID.
+func (s *Server) abortContext(did, ctxID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.peers, ctxID)
+	delete(s.pending, ctxID)
+	s.releaseSlotLocked(did, ctxID)
+}
+
+// releaseSlot frees the in-flight slot of ctxID, if any.
+func (s *Server) releaseSlot(did, ctxID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releaseSlotLocked(did, ctxID)
+}
+
+// releaseSlotLocked is releaseSlot for callers holding s.mu.
+func (s *Server) releaseSlotLocked(did, ctxID string) {
+	if slots, ok := s.inflight[did]; ok {
+		delete(slots, ctxID)
+		if len(slots) == 0 {
+			delete(s.inflight, did)
+		}
+	}
+}
+
 func (s *Server) cleanupLoop() {
 	ticker := s.cleanupTicker
 	for {
@@ -475,6 +649,22 @@ func (s *Server) cleanupExpired(now time.Time) {
 			delete(s.peers, ctxID)
 		}
 	}
+	for ctxID, cc := range s.completed {
+		if now.After(cc.expires) {
+			delete(s.completed, ctxID)
+		}
+	}
+	for did, slots := range s.inflight {
+		for ctxID, expires := range slots {
+			if now.After(expires) {
+				delete(slots, ctxID)
+			}
+		}
+		if len(slots) == 0 {
+			delete(s.inflight, did)
+		}
+	}
+	s.cleanupTranscripts(now)
 }
 
 // save/take helpers
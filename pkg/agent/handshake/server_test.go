@@ -43,6 +43,7 @@ import (
 	"github.com/sage-x-project/sage/pkg/agent/handshake"
 	"github.com/sage-x-project/sage/pkg/agent/session"
 	"github.com/sage-x-project/sage/pkg/agent/transport"
+	transporthttp "github.com/sage-x-project/sage/pkg/agent/transport/http"
 )
 
 type mockResolver struct {
@@ -133,6 +134,118 @@ func setupTest(t *testing.T, cleanupInterval time.Duration) (*handshake.Client,
 	return alice, hs, aliceKeyPair, bobKeyPair, srvSessManager, ethResolver, mockTransport
 }
 
+func TestHandshake_ContextReuse(t *testing.T) {
+	alice, hs, aliceKeyPair, bobKeyPair, _, ethResolver, mockTransport := setupTest(t, 0)
+	ctx := context.Background()
+	contextId := "ctx-" + uuid.NewString()
+
+	aliceDID := sagedid.AgentDID("did:sage:ethereum:agent001")
+	ethResolver.On("Resolve", mock.Anything, aliceDID).Return(&sagedid.AgentMetadata{
+		DID:       aliceDID,
+		Name:      "Active Agent",
+		IsActive:  true,
+		PublicKey: aliceKeyPair.PublicKey(),
+	}, nil).Once()
+
+	_, err := alice.Invitation(ctx, handshake.InvitationMessage{
+		BaseMessage: message.BaseMessage{ContextID: contextId},
+	}, string(aliceDID))
+	require.NoError(t, err)
+
+	eph, err := keys.GenerateX25519KeyPair()
+	require.NoError(t, err)
+	ephJWK, err := formats.NewJWKExporter().ExportPublic(eph, sagecrypto.KeyFormatJWK)
+	require.NoError(t, err)
+	_, err = alice.Request(ctx, handshake.RequestMessage{
+		BaseMessage:     message.BaseMessage{ContextID: contextId},
+		EphemeralPubKey: json.RawMessage(ephJWK),
+	}, bobKeyPair.PublicKey(), string(aliceDID))
+	require.NoError(t, err)
+
+	resp, err := alice.Complete(ctx, handshake.CompleteMessage{
+		BaseMessage: message.BaseMessage{ContextID: contextId},
+	}, string(aliceDID))
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+
+	t.Run("rejects reuse", func(t *testing.T) {
+		_, err := alice.Invitation(ctx, handshake.InvitationMessage{
+			BaseMessage: message.BaseMessage{ContextID: contextId},
+		}, string(aliceDID))
+		require.ErrorIs(t, err, handshake.ErrContextReused)
+		var herr *handshake.HandshakeError
+		require.ErrorAs(t, err, &herr)
+		assert.Equal(t, handshake.CodeContextReused, herr.Code)
+	})
+
+	malloryKeyPair, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	mallory := handshake.NewClient(mockTransport, malloryKeyPair)
+	malloryDID := sagedid.AgentDID("did:sage:ethereum:mallory")
+	ethResolver.On("Resolve", mock.Anything, malloryDID).Return(&sagedid.AgentMetadata{
+		DID:       malloryDID,
+		Name:      "Mallory",
+		IsActive:  true,
+		PublicKey: malloryKeyPair.PublicKey(),
+	}, nil)
+
+	t.Run("rejected invitation leaves peer cached", func(t *testing.T) {
+		_, err := mallory.Invitation(ctx, handshake.InvitationMessage{
+			BaseMessage: message.BaseMessage{ContextID: contextId},
+		}, string(malloryDID))
+		require.ErrorIs(t, err, handshake.ErrContextReused)
+
+		peerDID, ok := handshake.PeerDID(hs, contextId)
+		require.True(t, ok)
+		assert.Equal(t, string(aliceDID), peerDID)
+	})
+
+	t.Run("rejects resume from another DID", func(t *testing.T) {
+		_, err := mallory.Invitation(ctx, handshake.InvitationMessage{
+			BaseMessage: message.BaseMessage{ContextID: contextId},
+			Resume:      true,
+		}, string(malloryDID))
+		require.ErrorIs(t, err, handshake.ErrContextReused)
+
+		peerDID, ok := handshake.PeerDID(hs, contextId)
+		require.True(t, ok)
+		assert.Equal(t, string(aliceDID), peerDID)
+	})
+
+	t.Run("bad signature does not cache peer", func(t *testing.T) {
+		freshCtx := "ctx-" + uuid.NewString()
+		// Signed with Alice's key but claiming Mallory's DID
+		_, err := alice.Invitation(ctx, handshake.InvitationMessage{
+			BaseMessage: message.BaseMessage{ContextID: freshCtx},
+		}, string(malloryDID))
+		require.Error(t, err)
+		assert.False(t, handshake.HasPeer(hs, freshCtx))
+	})
+
+	t.Run("allows explicit resume", func(t *testing.T) {
+		resp, err := alice.Invitation(ctx, handshake.InvitationMessage{
+			BaseMessage: message.BaseMessage{ContextID: contextId},
+			Resume:      true,
+		}, string(aliceDID))
+		require.NoError(t, err)
+		assert.True(t, resp.Success)
+	})
+
+	t.Run("fresh context unaffected", func(t *testing.T) {
+		otherDID := sagedid.AgentDID("did:sage:ethereum:agent002")
+		ethResolver.On("Resolve", mock.Anything, otherDID).Return(&sagedid.AgentMetadata{
+			DID:       otherDID,
+			Name:      "Other Agent",
+			IsActive:  true,
+			PublicKey: aliceKeyPair.PublicKey(),
+		}, nil).Once()
+		_, err := alice.Invitation(ctx, handshake.InvitationMessage{
+			BaseMessage: message.BaseMessage{ContextID: "ctx-" + uuid.NewString()},
+		}, string(otherDID))
+		require.NoError(t, err)
+	})
+}
+
 func TestHandshake_Invitation(t *testing.T) {
 	// Specification Requirement: Handshake protocol Phase 1 - Invitation
 	helpers.LogTestSection(t, "10.1.1", "Handshake Server Invitation Phase")
@@ -312,7 +425,7 @@ func TestHandshake_Complete(t *testing.T) {
 	// Specification Requirement: Handshake protocol Phase 3 - Complete and session establishment
 	helpers.LogTestSection(t, "10.1.3", "Handshake Server Complete Phase")
 
-	alice, hs, aliceKeyPair, bobKeyPair, _, ethResolver, _ := setupTest(t, 0)
+	alice, hs, aliceKeyPair, bobKeyPair, srvSessManager, ethResolver, _ := setupTest(t, 0)
 	helpers.LogDetail(t, "Test setup complete with client and server")
 
 	ctx := context.Background()
@@ -380,6 +493,10 @@ func TestHandshake_Complete(t *testing.T) {
 	require.False(t, hasPending, "pending state should be consumed after Complete")
 	helpers.LogSuccess(t, "Pending state consumed successfully")
 
+	// The session is bound to the authenticated peer DID for revocation
+	require.Equal(t, 1, srvSessManager.RevokeSessionsByDID(string(aliceDID)))
+	helpers.LogSuccess(t, "Session bound to Alice's DID")
+
 	// Pass criteria checklist
 	helpers.LogPassCriteria(t, []string{
 		"Client and server initialized",
@@ -612,3 +729,109 @@ func TestInvitation_ResolverSingleflight(t *testing.T) {
 		ethResolver.AssertExpectations(t)
 	})
 }
+
+func TestHandshake_Transcript(t *testing.T) {
+	alice, hs, aliceKeyPair, bobKeyPair, _, ethResolver, _ := setupTest(t, 0)
+	ctx := context.Background()
+	contextId := "ctx-" + uuid.NewString()
+
+	aliceDID := sagedid.AgentDID("did:sage:ethereum:agent001")
+	bobDID := "did:sage:ethereum:agent002"
+	ethResolver.On("Resolve", mock.Anything, aliceDID).Return(&sagedid.AgentMetadata{
+		DID:       aliceDID,
+		IsActive:  true,
+		PublicKey: aliceKeyPair.PublicKey(),
+	}, nil).Once()
+	hs.EnableTranscripts(bobDID)
+
+	_, err := alice.Invitation(ctx, handshake.InvitationMessage{
+		BaseMessage: message.BaseMessage{ContextID: contextId},
+	}, string(aliceDID))
+	require.NoError(t, err)
+
+	ephemeral, err := keys.GenerateX25519KeyPair()
+	require.NoError(t, err)
+	ephJWK, err := formats.NewJWKExporter().ExportPublic(ephemeral, sagecrypto.KeyFormatJWK)
+	require.NoError(t, err)
+	_, err = alice.Request(ctx, handshake.RequestMessage{
+		BaseMessage:     message.BaseMessage{ContextID: contextId},
+		EphemeralPubKey: json.RawMessage(ephJWK),
+	}, bobKeyPair.PublicKey(), string(aliceDID))
+	require.NoError(t, err)
+
+	// Not retrievable until the handshake completes
+	_, ok := hs.Transcript(contextId)
+	require.False(t, ok)
+
+	_, err = alice.Complete(ctx, handshake.CompleteMessage{
+		BaseMessage: message.BaseMessage{ContextID: contextId},
+	}, string(aliceDID))
+	require.NoError(t, err)
+
+	tr, ok := hs.Transcript(contextId)
+	require.True(t, ok)
+	assert.Equal(t, contextId, tr.ContextID)
+	assert.Equal(t, handshake.Participants{Initiator: string(aliceDID), Responder: bobDID}, tr.Participants)
+	assert.NotEmpty(t, tr.DerivedKeyID)
+	assert.False(t, tr.CompletedAt.IsZero())
+
+	require.Len(t, tr.Phases, 3)
+	for i, want := range []handshake.Phase{handshake.Invitation, handshake.Request, handshake.Complete} {
+		rec := tr.Phases[i]
+		assert.Equal(t, want, rec.Phase)
+		assert.Equal(t, string(aliceDID), rec.SenderDID)
+		assert.Len(t, rec.PayloadSHA256, 64)
+		if i > 0 {
+			assert.False(t, rec.ReceivedAt.Before(tr.Phases[i-1].ReceivedAt))
+		}
+	}
+}
+
+func TestHandshake_RequestIDLifecycle(t *testing.T) {
+	alice, hs, aliceKeyPair, bobKeyPair, _, ethResolver, mockTransport := setupTest(t, 0)
+	contextId := "ctx-" + uuid.NewString()
+	requestID := transport.NewRequestID()
+	ctx := transport.WithRequestID(context.Background(), requestID)
+
+	// Carry every phase over HTTP so the ID has to cross the wire
+	client, stop := transporthttp.NewInProcessTransport(hs.HandleMessage)
+	t.Cleanup(func() { _ = stop() })
+	mockTransport.SendFunc = client.Send
+
+	aliceDID := sagedid.AgentDID("did:sage:ethereum:agent001")
+	ethResolver.On("Resolve", mock.Anything, aliceDID).Return(&sagedid.AgentMetadata{
+		DID:       aliceDID,
+		IsActive:  true,
+		PublicKey: aliceKeyPair.PublicKey(),
+	}, nil).Once()
+	hs.EnableTranscripts("did:sage:ethereum:agent002")
+
+	_, err := alice.Invitation(ctx, handshake.InvitationMessage{
+		BaseMessage: message.BaseMessage{ContextID: contextId},
+	}, string(aliceDID))
+	require.NoError(t, err)
+
+	ephemeral, err := keys.GenerateX25519KeyPair()
+	require.NoError(t, err)
+	ephJWK, err := formats.NewJWKExporter().ExportPublic(ephemeral, sagecrypto.KeyFormatJWK)
+	require.NoError(t, err)
+	_, err = alice.Request(ctx, handshake.RequestMessage{
+		BaseMessage:     message.BaseMessage{ContextID: contextId},
+		EphemeralPubKey: json.RawMessage(ephJWK),
+	}, bobKeyPair.PublicKey(), string(aliceDID))
+	require.NoError(t, err)
+
+	// Without an ID from the client the server assigns one
+	_, err = alice.Complete(context.Background(), handshake.CompleteMessage{
+		BaseMessage: message.BaseMessage{ContextID: contextId},
+	}, string(aliceDID))
+	require.NoError(t, err)
+
+	tr, ok := hs.Transcript(contextId)
+	require.True(t, ok)
+	require.Len(t, tr.Phases, 3)
+	assert.Equal(t, requestID, tr.Phases[0].RequestID)
+	assert.Equal(t, requestID, tr.Phases[1].RequestID)
+	assert.NotEmpty(t, tr.Phases[2].RequestID)
+	assert.NotEqual(t, requestID, tr.Phases[2].RequestID)
+}
@@ -0,0 +1,172 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package handshake
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+)
+
+// PhaseRecord describes one handshake message accepted by the server.
+// Only a digest of the wire payload is kept, never the payload itself.
+type PhaseRecord struct {
+	Phase         Phase     `json:"phase"`
+	MessageID     string    `json:"messageId,omitempty"`
+	RequestID     string    `json:"requestId,omitempty"`
+	SenderDID     string    `json:"senderDid"`
+	ReceivedAt    time.Time `json:"receivedAt"`
+	PayloadSHA256 string    `json:"payloadSha256"`
+}
+
+// Participants names both sides of a handshake.
+type Participants struct {
+	Initiator string `json:"initiator"`
+	Responder string `json:"responder,omitempty"`
+}
+
+// HandshakeTranscript is an audit record of one completed handshake.
+// It holds DIDs, message IDs, timestamps and payload digests only; no
+// ephemeral keys, shared secrets or plaintext are recorded.
+type HandshakeTranscript struct {
+	ContextID    string        `json:"contextId"`
+	Participants Participants  `json:"participants"`
+	Phases       []PhaseRecord `json:"phases"`
+	DerivedKeyID string        `json:"derivedKeyId,omitempty"`
+	CompletedAt  time.Time     `json:"completedAt"`
+}
+
+type transcriptEntry struct {
+	t       *HandshakeTranscript
+	expires time.Time
+}
+
+// transcriptLog keeps in-flight and completed transcripts per context.
+// Both are dropped by the cleanup loop once their TTL passes.
+type transcriptLog struct {
+	selfDID   string
+	active    map[string]transcriptEntry
+	completed map[string]transcriptEntry
+}
+
+// EnableTranscripts turns on transcript recording. selfDID is recorded as
+// the responder, since the server is not otherwise told its own DID.
+// Completed transcripts stay retrievable through Transcript for the pending
+// TTL. Calling it again discards everything recorded so far.
+func (s *Server) EnableTranscripts(selfDID string) {
+	s.mu.Lock()
+	s.transcripts = &transcriptLog{
+		selfDID:   selfDID,
+		active:    make(map[string]transcriptEntry),
+		completed: make(map[string]transcriptEntry),
+	}
+	s.mu.Unlock()
+}
+
+// Transcript returns a copy of the transcript of the completed handshake
+// for ctxID. It reports false while the handshake is still in progress,
+// when transcripts are disabled, or after the transcript has expired.
+func (s *Server) Transcript(ctxID string) (*HandshakeTranscript, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.transcripts == nil {
+		return nil, false
+	}
+	e, ok := s.transcripts.completed[ctxID]
+	if !ok {
+		return nil, false
+	}
+	cp := *e.t
+	cp.Phases = append([]PhaseRecord(nil), e.t.Phases...)
+	return &cp, true
+}
+
+// recordPhase appends an accepted message to the transcript for its context.
+// An Invitation starts a fresh transcript; later phases without one are ignored.
+func (s *Server) recordPhase(phase Phase, msg *transport.SecureMessage, senderDID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tl := s.transcripts
+	if tl == nil {
+		return
+	}
+
+	now := time.Now()
+	sum := sha256.Sum256(msg.Payload)
+	rec := PhaseRecord{
+		Phase:         phase,
+		MessageID:     msg.ID,
+		RequestID:     msg.Metadata[transport.RequestIDMetadataKey],
+		SenderDID:     senderDID,
+		ReceivedAt:    now,
+		PayloadSHA256: hex.EncodeToString(sum[:]),
+	}
+
+	if phase == Invitation {
+		tl.active[msg.ContextID] = transcriptEntry{
+			t: &HandshakeTranscript{
+				ContextID:    msg.ContextID,
+				Participants: Participants{Initiator: senderDID, Responder: tl.selfDID},
+				Phases:       []PhaseRecord{rec},
+			},
+			expires: now.Add(s.pendingTTL),
+		}
+		return
+	}
+	if e, ok := tl.active[msg.ContextID]; ok {
+		e.t.Phases = append(e.t.Phases, rec)
+	}
+}
+
+// completeTranscript moves the transcript for ctxID to the completed set.
+func (s *Server) completeTranscript(ctxID, keyID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tl := s.transcripts
+	if tl == nil {
+		return
+	}
+	e, ok := tl.active[ctxID]
+	if !ok {
+		return
+	}
+	delete(tl.active, ctxID)
+
+	now := time.Now()
+	e.t.DerivedKeyID = keyID
+	e.t.CompletedAt = now
+	e.expires = now.Add(s.pendingTTL)
+	tl.completed[ctxID] = e
+}
+
+// cleanupTranscripts drops expired transcripts. Callers must hold s.mu.
+func (s *Server) cleanupTranscripts(now time.Time) {
+	if s.transcripts == nil {
+		return
+	}
+	for _, m := range []map[string]transcriptEntry{s.transcripts.active, s.transcripts.completed} {
+		for ctxID, e := range m {
+			if now.After(e.expires) {
+				delete(m, ctxID)
+			}
+		}
+	}
+}
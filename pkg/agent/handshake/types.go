@@ -56,6 +56,9 @@ func (p Phase) String() string {
 // Events for agent (session) layer
 // Events defines callbacks for the agent/application layer.
 // The handshake package does not create or store sessions; it only emits events.
+// OnInvitation, OnRequest and AskEphemeral may reject the phase by returning a
+// *HandshakeError (e.g. CodeRateLimited or CodeReplay), which is sent to the
+// client; other errors from OnInvitation and OnRequest are ignored.
 type Events interface {
 	// OnInvitation is called when an Invitation is received.
 	OnInvitation(ctx context.Context, ctxID string, inv InvitationMessage) error
@@ -106,6 +109,11 @@ type KeyIDBinder interface {
 type InvitationMessage struct {
 	message.BaseMessage
 	message.MessageControlHeader
+
+	// Resume asks the server to run a new handshake on a ContextID that
+	// already completed one. Only the DID that completed it may resume;
+	// otherwise the server rejects the invitation with ErrContextReused.
+	Resume bool `json:"resume,omitempty"`
 }
 
 func (m *InvitationMessage) GetSequence() uint64 {
@@ -146,8 +154,14 @@ type ResponseMessage struct {
 	message.BaseMessage
 	message.MessageControlHeader
 	EphemeralPubKey json.RawMessage `json:"ephemeralPublicKey"` // JWK format
-	KeyID           string          `json:"keyid,omitempty"`
-	Ack             bool            `json:"ack"`
+	// EphemeralSig is the server identity key's signature over
+	// EphemeralSigningInput, binding EphemeralPubKey to this context and to
+	// the client's ephemeral key.
+	EphemeralSig []byte `json:"ephemeralSignature,omitempty"`
+	KeyID        string `json:"keyid,omitempty"`
+	Ack          bool   `json:"ack"`
+	// Error is set instead of the fields above when the server rejects a phase.
+	Error *HandshakeError `json:"error,omitempty"`
 }
 
 func (m *ResponseMessage) GetSequence() uint64 {
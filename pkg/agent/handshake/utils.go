@@ -19,8 +19,11 @@
 package handshake
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // GenerateTaskID returns a task ID prefixed with the handshake step, e.g. "invitation-<uuid>".
@@ -32,11 +35,47 @@ func GenerateTaskID(p Phase) string {
 // These helper functions are no longer needed with transport abstraction
 // structpb-based helpers have been removed
 
-func parsePhase(taskID string) (Phase, error) {
-	var p int
-	_, err := fmt.Sscanf(taskID, "handshake/%d", &p)
-	if err != nil || p < int(Invitation) || p > int(Complete) {
-		return 0, errors.New("invalid task id")
+// ErrInvalidTaskID is returned by ParseTaskID for task IDs that were not
+// produced by GenerateTaskID.
+var ErrInvalidTaskID = errors.New("invalid task id")
+
+// ParseTaskID is the inverse of GenerateTaskID. Only the exact canonical form
+// of a known phase is accepted, so padded, signed or suffixed variants of a
+// valid ID are rejected rather than mapped to a phase.
+func ParseTaskID(id string) (Phase, error) {
+	rest, ok := strings.CutPrefix(id, "handshake/")
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidTaskID, id)
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil || n < int(Invitation) || n > int(Complete) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidTaskID, id)
 	}
-	return Phase(p), nil
+	p := Phase(n)
+	if GenerateTaskID(p) != id {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidTaskID, id)
+	}
+	return p, nil
+}
+
+// ErrInvalidEphemeralSignature is returned when a Response's ephemeral key is
+// not signed by the server's identity key for this context.
+var ErrInvalidEphemeralSignature = errors.New("invalid server ephemeral signature")
+
+// ephemeralSigLabel domain-separates ephemeral key signatures from the other
+// payloads signed with the same identity key.
+const ephemeralSigLabel = "sage/handshake server-ephemeral v1"
+
+// EphemeralSigningInput returns the bytes the server signs to vouch for its
+// ephemeral X25519 key: the label, the context ID (length-prefixed), the
+// server's raw ephemeral key and the client's raw ephemeral key. Covering the
+// client key ties the signature to one Request so it cannot be replayed.
+func EphemeralSigningInput(ctxID string, serverEph, clientEph []byte) []byte {
+	out := make([]byte, 0, len(ephemeralSigLabel)+4+len(ctxID)+len(serverEph)+len(clientEph))
+	out = append(out, ephemeralSigLabel...)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(ctxID))) // #nosec G115 -- context IDs are short strings
+	out = append(out, ctxID...)
+	out = append(out, serverEph...)
+	out = append(out, clientEph...)
+	return out
 }
@@ -0,0 +1,60 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package handshake_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sage-x-project/sage/pkg/agent/handshake"
+)
+
+func TestParseTaskID(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		for _, p := range []handshake.Phase{handshake.Invitation, handshake.Request, handshake.Response, handshake.Complete} {
+			got, err := handshake.ParseTaskID(handshake.GenerateTaskID(p))
+			require.NoError(t, err, p.String())
+			assert.Equal(t, p, got)
+		}
+	})
+
+	t.Run("rejects unknown and forged ids", func(t *testing.T) {
+		for _, id := range []string{
+			"",
+			"garbage",
+			"handshake/",
+			"handshake/0",
+			"handshake/5",
+			"handshake/-1",
+			"handshake/+2",
+			"handshake/02",
+			"handshake/ 2",
+			"handshake/2x",
+			"handshake/2/extra",
+			"Handshake/2",
+			"xhandshake/2",
+			"hpke/complete@v1",
+		} {
+			_, err := handshake.ParseTaskID(id)
+			assert.ErrorIs(t, err, handshake.ErrInvalidTaskID, "id %q", id)
+		}
+	})
+}
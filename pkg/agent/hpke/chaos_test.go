@@ -0,0 +1,80 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+//go:build sagechaos
+
+package hpke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	"github.com/sage-x-project/sage/pkg/sagechaos"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HPKE_Chaos(t *testing.T) {
+	ctx := context.Background()
+	t.Cleanup(sagechaos.Reset)
+
+	t.Run("Resolve timeout surfaces from the handshake", func(t *testing.T) {
+		cli, _, srvMgr, _, _, _, _, clientDID, serverDID := setupHPKETestWithTransport(t, session.Config{}, session.Config{})
+		sagechaos.Inject(sagechaos.DIDResolve, fmt.Errorf("rpc: %w", context.DeadlineExceeded))
+		defer sagechaos.Reset()
+
+		_, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.Error(t, err)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Zero(t, srvMgr.GetSessionCount())
+
+		sagechaos.Inject(sagechaos.DIDResolve, nil)
+		_, err = cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.NoError(t, err, "handshake recovers once the fault is cleared")
+	})
+
+	t.Run("Signature failure aborts the handshake", func(t *testing.T) {
+		cli, _, srvMgr, _, _, _, _, clientDID, serverDID := setupHPKETestWithTransport(t, session.Config{}, session.Config{})
+		injected := errors.New("injected signature failure")
+		sagechaos.Inject(sagechaos.SignatureVerify, injected)
+		defer sagechaos.Reset()
+
+		_, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), injected.Error())
+		require.Zero(t, srvMgr.GetSessionCount())
+	})
+
+	t.Run("Decrypt failure rejects session messages", func(t *testing.T) {
+		cli, srv, _, _, _, _, _, _, serverDID := setupHPKETestWithTransport(t, session.Config{}, session.Config{})
+		srv.sessionMessage = func(context.Context, string, []byte) error { return nil }
+		sc := NewSecureClient(cli, serverDID)
+		_, err := sc.Establish(ctx)
+		require.NoError(t, err)
+
+		injected := errors.New("injected decrypt failure")
+		sagechaos.Inject(sagechaos.SessionDecrypt, injected)
+		defer sagechaos.Reset()
+
+		_, err = sc.Send(ctx, []byte("hello"))
+		require.ErrorIs(t, err, injected)
+	})
+}
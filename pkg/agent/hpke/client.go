@@ -21,20 +21,15 @@ package hpke
 import (
 	"context"
 	"crypto/ecdh"
-	"crypto/ed25519"
-	"crypto/hmac"
-	"crypto/rand"
 	"crypto/sha256"
-	"crypto/subtle"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
-	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
 	"github.com/sage-x-project/sage/pkg/agent/did"
 	"github.com/sage-x-project/sage/pkg/agent/session"
 	"github.com/sage-x-project/sage/pkg/agent/transport"
@@ -49,8 +44,19 @@ type Client struct {
 	info      InfoBuilder
 	sessMgr   *session.Manager
 
-	cookies CookieSource      // optional
-	pins    map[string][]byte // DID -> ed25519 pub (TOFU pin)
+	cookies CookieSource // optional
+	pins    PinStore     // optional; server key fingerprints by DID
+	tofu    bool         // record unknown servers in pins on first contact
+	mode    Mode         // ModeSessionKey unless set via WithMode
+	kemPref []KEMScheme  // DefaultKEMPreference unless set via WithKEMPreference
+
+	lifetime *SessionLifetime // proposed session lifetime; see WithSessionLifetime
+
+	kemFallback KEMFallback        // KEMFallbackStrict unless set via WithKEMFallback
+	kemWarn     KEMFallbackWarning // nil derives without warning
+
+	verifyEndpoint bool // check the transport endpoint; see WithEndpointVerification
+	endpointPort   bool // also compare ports
 }
 
 func NewClient(t transport.MessageTransport, resolver did.Resolver, key sagecrypto.KeyPair, didStr string, ib InfoBuilder, sessMgr *session.Manager) *Client {
@@ -64,7 +70,6 @@ func NewClient(t transport.MessageTransport, resolver did.Resolver, key sagecryp
 		DID:       didStr,
 		info:      ib,
 		sessMgr:   sessMgr,
-		pins:      make(map[string][]byte),
 	}
 }
 
@@ -74,35 +79,108 @@ func (c *Client) WithCookieSource(src CookieSource) *Client {
 	return c
 }
 
+// WithKEMPreference sets the KEM schemes the client accepts, most preferred
+// first. The client uses the first scheme for which the peer publishes a KEM
+// key, so a peer offering both X25519 and P-256 can be reached over either.
+func (c *Client) WithKEMPreference(schemes ...KEMScheme) *Client {
+	c.kemPref = schemes
+	return c
+}
+
+// WithSessionLifetime sets the session lifetime the client proposes during
+// Initialize. By default it proposes the session manager's default config.
+// The server rejects proposals longer than its own policy.
+func (c *Client) WithSessionLifetime(lt SessionLifetime) *Client {
+	c.lifetime = &lt
+	return c
+}
+
+// sessionLifetime returns the lifetime to bind into the handshake, or nil when
+// the info builder cannot bind one. A default config with unset limits is not
+// proposed, keeping the unbound handshake for it.
+func (c *Client) sessionLifetime() (*SessionLifetime, error) {
+	if _, ok := c.info.(LifetimeInfoBuilder); !ok {
+		return nil, nil
+	}
+	if c.lifetime != nil {
+		lt := *c.lifetime
+		if err := lt.validate(); err != nil {
+			return nil, err
+		}
+		return &lt, nil
+	}
+	if c.sessMgr == nil {
+		return nil, nil
+	}
+	lt := LifetimeFromConfig(c.sessMgr.GetDefaultConfig())
+	if lt.validate() != nil {
+		return nil, nil
+	}
+	return &lt, nil
+}
+
+// sequenceNumbers reports whether the client proposes sequence numbers,
+// which it does when its session manager's default config enables them.
+func (c *Client) sequenceNumbers() bool {
+	return c.sessMgr != nil && c.sessMgr.GetDefaultConfig().SequenceNumbers
+}
+
+func (c *Client) kemPreference() []KEMScheme {
+	if len(c.kemPref) == 0 {
+		return DefaultKEMPreference
+	}
+	return c.kemPref
+}
+
 // Initialize performs HPKE Base sender-side derivation, mixes E2E DH, verifies ackTag & server signature,
 // and creates/binds a session keyed by kid.
 func (c *Client) Initialize(ctx context.Context, ctxID, initDID, peerDID string) (kid string, err error) {
-	// 1) Resolve peer's KEM (X25519) public key.
-	peerKEM, err := c.resolvePeerKEM(ctx, peerDID)
+	if c.mode != ModeSessionKey {
+		return "", fmt.Errorf("%w: Initialize requires %s, client is %s", ErrWrongMode, ModeSessionKey, c.mode)
+	}
+
+	// 0) Reject a server whose signing key does not match its pin, or whose
+	//    endpoint is not the one its DID advertises.
+	if err := c.checkServerPinEarly(ctx, peerDID); err != nil {
+		return "", err
+	}
+	if err := c.checkServerEndpoint(ctx, peerDID); err != nil {
+		return "", err
+	}
+
+	// 1) Resolve peer's KEM public key; its type selects the KEM scheme.
+	scheme, peerKEM, err := c.resolvePeerKEM(ctx, peerDID)
 	if err != nil {
 		return "", err
 	}
 
-	// 2) Build HPKE info/export contexts (stable transcript inputs).
-	info := c.info.BuildInfo(ctxID, initDID, peerDID)
+	// 2) Build HPKE info/export contexts (stable transcript inputs), binding
+	//    the proposed session lifetime when the info builder supports it.
+	lt, err := c.sessionLifetime()
+	if err != nil {
+		return "", err
+	}
+	seq := c.sequenceNumbers()
+	info := buildInfo(c.info, ctxID, initDID, peerDID, lt)
 	exportCtx := c.info.BuildExportContext(ctxID)
 
 	// 3) Derive HPKE sender secrets: enc (ephemeral HPKE pub) and exporter.
-	enc, exporterHPKE, err := c.deriveHPKESenderSecrets(peerKEM, info, exportCtx)
+	enc, exporterHPKE, err := c.deriveHPKESenderSecrets(scheme, peerKEM, info, exportCtx)
 	if err != nil {
 		return "", err
 	}
 
-	// 4) Generate client ephemeral X25519 key for additional E2E DH.
-	ephCpriv, ephCPubBytes, err := genEphX25519()
+	// 4) Generate client ephemeral key (same curve as the KEM) for additional E2E DH.
+	ephCpriv, err := generateEphemeral(scheme)
 	if err != nil {
 		zeroBytes(exporterHPKE)
 		return "", err
 	}
+	ephCPubBytes := ephCpriv.PublicKey().Bytes()
 
 	// 5) Build HPKE-init message and sign it (DID-bound).
 	nonce := uuid.NewString()
-	msg, err := c.buildAndSignInitMsg(ctxID, initDID, peerDID, info, exportCtx, nonce, enc, ephCPubBytes)
+	msg, err := c.buildAndSignInitMsg(ctxID, initDID, peerDID, info, exportCtx, nonce, scheme, enc, ephCPubBytes, lt, seq)
 	if err != nil {
 		zeroBytes(exporterHPKE)
 		return "", err
@@ -133,7 +211,7 @@ func (c *Client) Initialize(ctx context.Context, ctxID, initDID, peerDID string)
 	}
 
 	// 8) Compute ssE2E
-	ssE2E, err := computeE2ESecret(ephCpriv, r.EphSBytes)
+	ssE2E, err := ecdhE2E(ephCpriv, r.EphSBytes)
 	if err != nil {
 		zeroBytes(exporterHPKE)
 		return "", err
@@ -148,8 +226,8 @@ func (c *Client) Initialize(ctx context.Context, ctxID, initDID, peerDID string)
 	}
 	ih := sha256.Sum256(info)
 	eh := sha256.Sum256(exportCtx)
-	if subtle.ConstantTimeCompare(ih[:], r.InfoHash) != 1 ||
-		subtle.ConstantTimeCompare(eh[:], r.ExportCtxHash) != 1 {
+	if !sagecrypto.SecureCompare(ih[:], r.InfoHash) ||
+		!sagecrypto.SecureCompare(eh[:], r.ExportCtxHash) {
 		zeroBytes(combined)
 		return "", fmt.Errorf("pre-ack mismatch: info/exportCtx")
 	}
@@ -164,13 +242,13 @@ func (c *Client) Initialize(ctx context.Context, ctxID, initDID, peerDID string)
 
 	// Cross-check that response echoed our enc/ephC (if present)
 	if len(r.Enc) > 0 {
-		if base64.RawURLEncoding.EncodeToString(enc) != r.EncB64 {
+		if !sagecrypto.SecureCompare(enc, r.Enc) {
 			zeroBytes(combined)
 			return "", fmt.Errorf("enc mismatch")
 		}
 	}
 	if len(r.EphC) > 0 {
-		if base64.RawURLEncoding.EncodeToString(ephCPubBytes) != r.EphCB64 {
+		if !sagecrypto.SecureCompare(ephCPubBytes, r.EphC) {
 			zeroBytes(combined)
 			return "", fmt.Errorf("ephC mismatch")
 		}
@@ -183,7 +261,7 @@ func (c *Client) Initialize(ctx context.Context, ctxID, initDID, peerDID string)
 	}
 
 	// 12) Create session and bind kid
-	if err := c.createAndBindSession(combined, r.Kid); err != nil {
+	if err := c.createAndBindSession(ctxID, peerDID, combined, r.Kid, lt, seq); err != nil {
 		zeroBytes(combined)
 		return "", err
 	}
@@ -191,68 +269,52 @@ func (c *Client) Initialize(ctx context.Context, ctxID, initDID, peerDID string)
 	return r.Kid, nil
 }
 
-// Resolve KEM public key of the peer by DID.
-func (c *Client) resolvePeerKEM(ctx context.Context, peerDID string) (*ecdh.PublicKey, error) {
+// Resolve KEM public keys of the peer by DID and select the negotiated scheme.
+func (c *Client) resolvePeerKEM(ctx context.Context, peerDID string) (KEMScheme, *ecdh.PublicKey, error) {
 	if c.resolver == nil {
-		return nil, fmt.Errorf("nil Resolver")
+		return nil, nil, fmt.Errorf("nil Resolver")
 	}
-	peerPub, err := c.resolver.ResolveKEMKey(ctx, did.AgentDID(peerDID))
-
-	if err != nil || peerPub == nil {
-		return nil, fmt.Errorf("cannot resolve receiver KEM pubkey: %w", err)
+	entries, err := did.ResolveKEMKeys(ctx, c.resolver, did.AgentDID(peerDID))
+	if err != nil {
+		return c.kemUnavailable(ctx, peerDID, fmt.Errorf("cannot resolve receiver KEM pubkey: %w", err))
 	}
-
-	// Type assert to *ecdh.PublicKey
-	switch v := peerPub.(type) {
-	case *ecdh.PublicKey:
-		return v, nil
-	case []byte:
-		if len(v) != 32 {
-			return nil, fmt.Errorf("invalid KEM key length: got %d, want 32", len(v))
-		}
-		pk, err := ecdh.X25519().NewPublicKey(v)
-		if err != nil {
-			return nil, fmt.Errorf("x25519 public key decode: %w", err)
-		}
-		return pk, nil
-	default:
-		return nil, fmt.Errorf("unexpected KEM key type %T", v)
+	if len(entries) == 0 {
+		return c.kemUnavailable(ctx, peerDID, errors.New("cannot resolve receiver KEM pubkey: none published"))
 	}
+
+	return SelectKEMKey(entries, c.kemPreference())
 }
 
 // HPKE sender-side derivation: returns enc and exporter.
-func (c *Client) deriveHPKESenderSecrets(peerKEM *ecdh.PublicKey, info, exportCtx []byte) (enc, exporter []byte, err error) {
-	enc, exporter, err = keys.HPKEDeriveSharedSecretToPeer(peerKEM, info, exportCtx, 32)
+func (c *Client) deriveHPKESenderSecrets(scheme KEMScheme, peerKEM *ecdh.PublicKey, info, exportCtx []byte) (enc, exporter []byte, err error) {
+	enc, exporter, err = scheme.Encap(peerKEM, info, exportCtx)
 	if err != nil {
 		return nil, nil, fmt.Errorf("HPKE sender derive: %v", err)
 	}
-	if len(enc) != 32 || len(exporter) != 32 {
+	if len(enc) != scheme.EncLen() || len(exporter) != 32 {
 		return nil, nil, fmt.Errorf("unexpected sizes: enc=%d exporter=%d", len(enc), len(exporter))
 	}
 	return enc, exporter, nil
 }
 
-// Create ephemeral X25519 key pair and return (priv, pubBytes).
-func genEphX25519() (priv *ecdh.PrivateKey, pubBytes []byte, err error) {
-	x := ecdh.X25519()
-	priv, err = x.GenerateKey(rand.Reader)
-	if err != nil {
-		return nil, nil, fmt.Errorf("ephC gen: %w", err)
-	}
-	return priv, priv.PublicKey().Bytes(), nil
-}
-
 // Build a transport message for HPKE init and sign it using DID key.
-func (c *Client) buildAndSignInitMsg(ctxID, initDID, peerDID string, info, exportCtx []byte, nonce string, enc, ephCPubBytes []byte) (*transport.SecureMessage, error) {
+func (c *Client) buildAndSignInitMsg(ctxID, initDID, peerDID string, info, exportCtx []byte, nonce string, scheme KEMScheme, enc, ephCPubBytes []byte, lt *SessionLifetime, seq bool) (*transport.SecureMessage, error) {
 	pl := map[string]any{
+		"kem":       scheme.Name(),
 		"initDid":   initDID,
 		"respDid":   peerDID,
 		"info":      string(info),
 		"exportCtx": string(exportCtx),
 		"nonce":     nonce,
 		"ts":        time.Now().UTC().Format(time.RFC3339Nano),
-		"enc":       base64.RawURLEncoding.EncodeToString(enc),
-		"ephC":      base64.RawURLEncoding.EncodeToString(ephCPubBytes),
+		"enc":       session.Encoding.EncodeToString(enc),
+		"ephC":      session.Encoding.EncodeToString(ephCPubBytes),
+	}
+	if lt != nil {
+		pl["lifetime"] = lt.String()
+	}
+	if seq {
+		pl["seq"] = "1"
 	}
 
 	payload, err := json.Marshal(pl)
@@ -260,7 +322,7 @@ func (c *Client) buildAndSignInitMsg(ctxID, initDID, peerDID string, info, expor
 		return nil, fmt.Errorf("marshal payload: %w", err)
 	}
 
-	signature, err := c.key.Sign(payload)
+	signature, err := signHandshake(c.key, payload)
 	if err != nil {
 		return nil, fmt.Errorf("sign: %w", err)
 	}
@@ -286,6 +348,21 @@ func (c *Client) buildAndSignInitMsg(ctxID, initDID, peerDID string, info, expor
 // - Extracts detailed error messages from resp.Error
 // - Provides clear error context for debugging
 func (c *Client) sendAndGetSignedMsg(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
+	resp, err := c.sendAndGetResponse(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for empty response data
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("empty response data")
+	}
+
+	return resp, nil
+}
+
+// Send a message and check the response status without requiring a body.
+func (c *Client) sendAndGetResponse(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
 	resp, err := c.transport.Send(ctx, msg)
 	if err != nil {
 		return nil, fmt.Errorf("transport send: %w", err)
@@ -308,11 +385,6 @@ func (c *Client) sendAndGetSignedMsg(ctx context.Context, msg *transport.SecureM
 		return nil, fmt.Errorf("handshake failed: no error details provided")
 	}
 
-	// Check for empty response data
-	if len(resp.Data) == 0 {
-		return nil, fmt.Errorf("empty response data")
-	}
-
 	return resp, nil
 }
 
@@ -359,23 +431,23 @@ func parseServerSignedResponse(data []byte) (*serverSignedResponse, error) {
 	exportHB64, _ := get("exportCtxHash")
 	sigB64, _ := get("sigB64")
 
-	ephS, err := base64.RawURLEncoding.DecodeString(ephSB64)
-	if err != nil || len(ephS) != 32 {
+	ephS, err := session.DecodeBase64(ephSB64)
+	if err != nil || len(ephS) == 0 {
 		return nil, fmt.Errorf("bad ephS")
 	}
-	ack, err := base64.RawURLEncoding.DecodeString(ackB64)
+	ack, err := session.DecodeBase64(ackB64)
 	if err != nil {
 		return nil, fmt.Errorf("bad ackTagB64")
 	}
-	ih, err := base64.RawURLEncoding.DecodeString(infoHB64)
+	ih, err := session.DecodeBase64(infoHB64)
 	if err != nil || len(ih) != 32 {
 		return nil, fmt.Errorf("bad infoHash")
 	}
-	eh, err := base64.RawURLEncoding.DecodeString(exportHB64)
+	eh, err := session.DecodeBase64(exportHB64)
 	if err != nil || len(eh) != 32 {
 		return nil, fmt.Errorf("bad exportCtxHash")
 	}
-	sig, err := base64.RawURLEncoding.DecodeString(sigB64)
+	sig, err := session.DecodeBase64(sigB64)
 	if err != nil {
 		return nil, fmt.Errorf("bad sigB64")
 	}
@@ -383,14 +455,14 @@ func parseServerSignedResponse(data []byte) (*serverSignedResponse, error) {
 	var enc, ephC []byte
 	encB64, ok := m["enc"]
 	if ok && encB64 != "" {
-		enc, err = base64.RawURLEncoding.DecodeString(encB64)
+		enc, err = session.DecodeBase64(encB64)
 		if err != nil {
 			return nil, fmt.Errorf("bad enc")
 		}
 	}
 	ephCB64, ok := m["ephC"]
 	if ok && ephCB64 != "" {
-		ephC, err = base64.RawURLEncoding.DecodeString(ephCB64)
+		ephC, err = session.DecodeBase64(ephCB64)
 		if err != nil {
 			return nil, fmt.Errorf("bad ephC")
 		}
@@ -428,17 +500,14 @@ func (c *Client) verifySignature(ctx context.Context, serverDID string, r *serve
 		return fmt.Errorf("cannot resolve server pubkey")
 	}
 
-	if pinned, ok := c.pins[serverDID]; ok {
-		pk, ok := pub.(ed25519.PublicKey)
-		if !ok || subtle.ConstantTimeCompare(pinned, pk) != 1 {
-			return fmt.Errorf("pin mismatch: server signing key changed")
-		}
+	if err := c.checkServerPin(serverDID, pub, false); err != nil {
+		return err
 	}
 
 	// Recompute info/export hashes and check equality
 	ih := sha256.Sum256(info)
 	eh := sha256.Sum256(exportCtx)
-	if !hmac.Equal(ih[:], r.InfoHash) || !hmac.Equal(eh[:], r.ExportCtxHash) {
+	if !sagecrypto.SecureCompare(ih[:], r.InfoHash) || !sagecrypto.SecureCompare(eh[:], r.ExportCtxHash) {
 		return fmt.Errorf("info/exportCtx hash mismatch")
 	}
 
@@ -448,14 +517,14 @@ func (c *Client) verifySignature(ctx context.Context, serverDID string, r *serve
 		Task:          r.Task,
 		Ctx:           r.Ctx, // prefer local ctxID
 		Kid:           r.Kid,
-		EphS:          base64.RawURLEncoding.EncodeToString(r.EphSBytes),
-		AckTagB64:     base64.RawURLEncoding.EncodeToString(r.AckTag),
+		EphS:          session.Encoding.EncodeToString(r.EphSBytes),
+		AckTagB64:     session.Encoding.EncodeToString(r.AckTag),
 		Ts:            r.Ts,
 		Did:           serverDID,
-		InfoHash:      base64.RawURLEncoding.EncodeToString(ih[:]),
-		ExportCtxHash: base64.RawURLEncoding.EncodeToString(eh[:]),
-		Enc:           base64.RawURLEncoding.EncodeToString(enc),
-		EphC:          base64.RawURLEncoding.EncodeToString(ephC),
+		InfoHash:      session.Encoding.EncodeToString(ih[:]),
+		ExportCtxHash: session.Encoding.EncodeToString(eh[:]),
+		Enc:           session.Encoding.EncodeToString(enc),
+		EphC:          session.Encoding.EncodeToString(ephC),
 	}
 	envBytes, err := json.Marshal(env)
 	if err != nil {
@@ -466,49 +535,31 @@ func (c *Client) verifySignature(ctx context.Context, serverDID string, r *serve
 	if err := verifySignature(envBytes, r.Sig, pub); err != nil {
 		return fmt.Errorf("server signature verify failed: %w", err)
 	}
-	return nil
-}
-
-// Compute ssE2E = X25519(ephCpriv, ephSPub).
-func computeE2ESecret(ephCpriv *ecdh.PrivateKey, ephSbytes []byte) ([]byte, error) {
-	x := ecdh.X25519()
-	ephSPub, err := x.NewPublicKey(ephSbytes)
-	if err != nil {
-		return nil, fmt.Errorf("ephS parse: %w", err)
-	}
-	ssE2E, err := ephCpriv.ECDH(ephSPub)
-	if err != nil {
-		return nil, fmt.Errorf("e2e ecdh: %w", err)
-	}
-	// RFC 7748
-	if isAllZero32(ssE2E) {
-		return nil, fmt.Errorf("invalid ECDH (all-zero)")
-	}
-	return ssE2E, nil
+	// The server has proven possession of pub; record it on first use.
+	return c.checkServerPin(serverDID, pub, true)
 }
 
 // Constant-time verification of the server's ack tag.
 func verifyAckTag(seed []byte, ctxID, nonce, kid string, binds [][]byte, tag []byte) error {
 	expect := MakeAckTag(seed, ctxID, nonce, kid, binds...)
-	if !hmac.Equal(expect, tag) {
+	if !sagecrypto.SecureCompare(expect, tag) {
 		return fmt.Errorf("ack tag mismatch")
 	}
 	return nil
 }
 
-// Create a session as initiator and bind the provided key ID.
-func (c *Client) createAndBindSession(combined []byte, kid string) error {
-	_, sid, _, err := c.sessMgr.EnsureSessionFromExporterWithRole(
-		combined,
-		"sage/hpke+e2e v1",
-		true, // initiator
-		nil,
-	)
+// Create a session as initiator under the agreed lifetime and bind the
+// provided key ID, the server DID (so the session can be revoked by DID) and
+// the handshake context ID.
+func (c *Client) createAndBindSession(ctxID, peerDID string, combined []byte, kid string, lt *SessionLifetime, seq bool) error {
+	sid, err := ensureLifetimeSession(c.sessMgr, combined, true, lt, seq)
 	if err != nil {
-		return fmt.Errorf("session: %w", err)
+		return err
 	}
 	if c.sessMgr != nil {
 		c.sessMgr.BindKeyID(kid, sid)
+		c.sessMgr.BindDID(peerDID, sid)
+		c.sessMgr.BindContextID(ctxID, sid)
 	}
 	return nil
 }
@@ -20,10 +20,9 @@ package hpke
 
 import (
 	"crypto"
+	"crypto/ed25519"
 	"crypto/hmac"
 	"crypto/sha256"
-	"crypto/subtle"
-	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
 	"errors"
@@ -32,6 +31,10 @@ import (
 	"sync"
 	"time"
 
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	"github.com/sage-x-project/sage/pkg/sagechaos"
 	"golang.org/x/crypto/hkdf"
 )
 
@@ -48,6 +51,12 @@ func (DefaultInfoBuilder) BuildInfo(ctxID, initDID, respDID string) []byte {
 	)
 }
 
+// BuildInfoWithLifetime appends the agreed session lifetime to the info so
+// both sides commit to it in the HPKE key schedule.
+func (b DefaultInfoBuilder) BuildInfoWithLifetime(ctxID, initDID, respDID string, lt SessionLifetime) []byte {
+	return append(b.BuildInfo(ctxID, initDID, respDID), "|lifetime="+lt.String()...)
+}
+
 // exportCtx is used as the HKDF salt / "export context" value.
 // It repeats the domain label, suite, combiner, and context in a fixed order.
 func (DefaultInfoBuilder) BuildExportContext(ctxID string) []byte {
@@ -94,7 +103,7 @@ func isAllZero32(b []byte) bool {
 		return false
 	}
 	var z [32]byte
-	return subtle.ConstantTimeCompare(b, z[:]) == 1
+	return sagecrypto.SecureCompare(b, z[:])
 }
 
 type TrafficKeys struct {
@@ -116,10 +125,24 @@ func DeriveTrafficKeys(seed []byte) TrafficKeys {
 	}
 }
 
-// verifySignature verifies a detached signature using the appropriate verifier.
+// handshakeSigningContext domain-separates Ed25519 handshake signatures
+// (Ed25519ctx) from registration and other protocol signatures.
+var handshakeSigningContext = []byte(keys.SigningContextHandshake)
+
+// signHandshake signs a handshake payload with the DID key.
+// Ed25519 keys sign under handshakeSigningContext; other key types are
+// already domain-separated by their own hashing and sign as before.
+func signHandshake(key sagecrypto.KeyPair, payload []byte) ([]byte, error) {
+	if key.Type() == sagecrypto.KeyTypeEd25519 {
+		return keys.SignWithContext(key, payload, handshakeSigningContext)
+	}
+	return key.Sign(payload)
+}
+
+// verifySignature verifies a detached handshake signature using the appropriate verifier.
 //
-// Supported key types (via CompositeVerifier):
-// - Ed25519: EdDSA signatures
+// Supported key types:
+// - Ed25519: Ed25519ctx signatures under handshakeSigningContext
 // - ECDSA: Secp256k1 signatures (Ethereum-compatible)
 // - Custom Verify interface: Extensible verification
 //
@@ -129,6 +152,18 @@ func verifySignature(payload, signature []byte, senderPub crypto.PublicKey) erro
 	if len(signature) == 0 {
 		return errors.New("missing signature")
 	}
+	if err := sagechaos.Fail(sagechaos.SignatureVerify); err != nil {
+		return fmt.Errorf("signature verify failed: %w", err)
+	}
+
+	// Ed25519 handshake signatures are context-bound, whether the resolver
+	// returned the raw key or a key pair wrapping it
+	if pub, ok := ed25519Public(senderPub); ok {
+		if err := keys.VerifyWithContext(pub, payload, signature, handshakeSigningContext); err != nil {
+			return fmt.Errorf("signature verify failed: %w", err)
+		}
+		return nil
+	}
 
 	// Support custom Verify interface for extensibility
 	type verifyKey interface {
@@ -142,7 +177,7 @@ func verifySignature(payload, signature []byte, senderPub crypto.PublicKey) erro
 		return nil
 	}
 
-	// Use CompositeVerifier for standard crypto algorithms (Ed25519, ECDSA)
+	// Use CompositeVerifier for the remaining algorithms (ECDSA)
 	composite := NewCompositeVerifier()
 	if err := composite.Verify(payload, signature, senderPub); err != nil {
 		return fmt.Errorf("signature verify failed: %w", err)
@@ -151,6 +186,15 @@ func verifySignature(payload, signature []byte, senderPub crypto.PublicKey) erro
 	return nil
 }
 
+// ed25519Public extracts an Ed25519 public key from a raw key or a key pair
+func ed25519Public(pub crypto.PublicKey) (ed25519.PublicKey, bool) {
+	if kp, ok := pub.(interface{ PublicKey() crypto.PublicKey }); ok {
+		pub = kp.PublicKey()
+	}
+	edPub, ok := pub.(ed25519.PublicKey)
+	return edPub, ok
+}
+
 type nonceStore struct {
 	ttl     time.Duration
 	mu      sync.Mutex
@@ -190,7 +234,7 @@ func getBase64(m map[string]string, key string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	return base64.RawURLEncoding.DecodeString(s)
+	return session.DecodeBase64(s)
 }
 
 func strContains(arr []string, v string) bool {
@@ -255,13 +299,17 @@ type HPKEInitPayload struct {
 	RespDID   string
 	Info      []byte
 	ExportCtx []byte
+	KEM       string // KEM scheme name (see KEMScheme); defaults to "x25519"
 	Enc       []byte // HPKE enc (sender eph KEM pub) - raw
-	EphC      []byte // Client ephemeral X25519 pub - raw (32B)
+	EphC      []byte // Client ephemeral pub on the KEM curve - raw
 	Nonce     string
 	Timestamp time.Time
+	Lifetime  *SessionLifetime // Proposed session lifetime; nil for peers without negotiation
+
+	SequenceNumbers bool // Initiator proposes sequence numbers; see session.Config
 }
 
-// Parse: enc and ephC arrive as base64url strings and are converted to raw bytes.
+// Parse: enc and ephC arrive as session.Encoding strings and are converted to raw bytes.
 func ParseHPKEInitPayloadWithEphCFromJSON(data []byte) (HPKEInitPayload, error) {
 	var out HPKEInitPayload
 	var m map[string]string
@@ -308,10 +356,31 @@ func ParseHPKEInitPayloadWithEphCFromJSON(data []byte) (HPKEInitPayload, error)
 	if out.EphC, err = getBase64(m, "ephC"); err != nil {
 		return out, fmt.Errorf("missing ephC: %w", err)
 	}
-	if l := len(out.EphC); l != 32 {
+	// kem is optional; peers predating scheme negotiation only speak X25519.
+	scheme, err := KEMSchemeByName(m["kem"])
+	if err != nil {
+		return out, err
+	}
+	out.KEM = scheme.Name()
+	// lifetime is optional; peers predating lifetime negotiation omit it.
+	if v, ok := m["lifetime"]; ok {
+		lt, err := ParseSessionLifetime(v)
+		if err != nil {
+			return out, err
+		}
+		out.Lifetime = &lt
+	}
+	// seq is optional; peers predating sequence numbers omit it.
+	if v, ok := m["seq"]; ok {
+		if v != "1" {
+			return out, fmt.Errorf("bad seq: %q", v)
+		}
+		out.SequenceNumbers = true
+	}
+	if l := len(out.EphC); l != scheme.EncLen() {
 		return out, fmt.Errorf("bad ephC length: %d", l)
 	}
-	if l := len(out.Enc); l != 32 {
+	if l := len(out.Enc); l != scheme.EncLen() {
 		return out, fmt.Errorf("bad enc length: %d", l)
 	}
 	return out, nil
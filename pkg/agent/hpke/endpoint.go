@@ -0,0 +1,107 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+)
+
+// ErrEndpointMismatch is returned when the endpoint the client's transport
+// connects to is not the endpoint advertised for the server's DID.
+var ErrEndpointMismatch = errors.New("hpke: connected endpoint does not match resolved endpoint")
+
+// WithEndpointVerification makes the client check, before contacting a
+// server, that its transport's endpoint has the same host as the server's
+// resolved AgentMetadata.Endpoint. With matchPort the ports must also match,
+// defaulting by scheme. The transport must implement transport.Endpointer.
+//
+// Key checks already bind the session to the server's DID; this also catches
+// being pointed at an impostor host that somehow holds the right keys.
+func (c *Client) WithEndpointVerification(matchPort bool) *Client {
+	c.verifyEndpoint = true
+	c.endpointPort = matchPort
+	return c
+}
+
+// checkServerEndpoint applies WithEndpointVerification for serverDID.
+func (c *Client) checkServerEndpoint(ctx context.Context, serverDID string) error {
+	if !c.verifyEndpoint {
+		return nil
+	}
+	ep, ok := c.transport.(transport.Endpointer)
+	if !ok {
+		return fmt.Errorf("%w: transport %T does not report its endpoint", ErrEndpointMismatch, c.transport)
+	}
+	meta, err := c.resolver.Resolve(ctx, did.AgentDID(serverDID))
+	if err != nil {
+		return fmt.Errorf("resolve server endpoint: %w", err)
+	}
+	return MatchEndpoint(ep.Endpoint(), meta.Endpoint, c.endpointPort)
+}
+
+// MatchEndpoint reports whether connected and advertised name the same
+// host, comparing hostnames case-insensitively. With matchPort the ports
+// must also match; a missing port defaults to 80 for http/ws and 443 for
+// https/wss. It is also usable against an A2A card's service endpoints.
+func MatchEndpoint(connected, advertised string, matchPort bool) error {
+	if advertised == "" {
+		return fmt.Errorf("%w: no endpoint advertised", ErrEndpointMismatch)
+	}
+	cu, err := url.Parse(connected)
+	if err != nil || cu.Host == "" {
+		return fmt.Errorf("%w: invalid connected endpoint %q", ErrEndpointMismatch, connected)
+	}
+	au, err := url.Parse(advertised)
+	if err != nil || au.Host == "" {
+		return fmt.Errorf("%w: invalid advertised endpoint %q", ErrEndpointMismatch, advertised)
+	}
+
+	if !strings.EqualFold(cu.Hostname(), au.Hostname()) {
+		return fmt.Errorf("%w: connected to %s, advertised %s", ErrEndpointMismatch, cu.Hostname(), au.Hostname())
+	}
+	if matchPort {
+		if cp, ap := endpointPort(cu), endpointPort(au); cp != ap {
+			return fmt.Errorf("%w: connected to %s, advertised %s",
+				ErrEndpointMismatch, net.JoinHostPort(cu.Hostname(), cp), net.JoinHostPort(au.Hostname(), ap))
+		}
+	}
+	return nil
+}
+
+// endpointPort returns u's port, or the default port for its scheme.
+func endpointPort(u *url.URL) string {
+	if p := u.Port(); p != "" {
+		return p
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "ws":
+		return "80"
+	case "https", "wss":
+		return "443"
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	sagedid "github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	sagehttp "github.com/sage-x-project/sage/pkg/agent/transport/http"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_EndpointVerification(t *testing.T) {
+	ctx := context.Background()
+
+	// setup serves the server in-process at http://in-process and advertises
+	// endpoint for it
+	setup := func(t *testing.T, endpoint string) (*Client, *sagedid.MultiChainResolver, string, string) {
+		base, srv, _, cliMgr, _, resolver, _, clientDID, serverDID :=
+			setupHPKETestWithTransport(t, session.Config{}, session.Config{})
+		meta, err := resolver.Resolve(ctx, sagedid.AgentDID(serverDID))
+		require.NoError(t, err)
+		meta.Endpoint = endpoint
+
+		tr, stop := sagehttp.NewInProcessTransport(srv.HandleMessage)
+		t.Cleanup(func() { _ = stop() })
+		cli := NewClient(tr, resolver, base.key, clientDID, DefaultInfoBuilder{}, cliMgr)
+		return cli, resolver, clientDID, serverDID
+	}
+
+	t.Run("matching endpoint", func(t *testing.T) {
+		cli, _, clientDID, serverDID := setup(t, "http://IN-PROCESS:80/agent")
+		cli.WithEndpointVerification(true)
+
+		_, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.NoError(t, err)
+	})
+
+	t.Run("mismatching host is rejected before sending", func(t *testing.T) {
+		cli, resolver, clientDID, serverDID := setup(t, "https://agent.example.com")
+		cli.WithEndpointVerification(false)
+
+		_, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.ErrorIs(t, err, ErrEndpointMismatch)
+
+		// The server never saw the init, so consume its client lookup here
+		_, err = resolver.Resolve(ctx, sagedid.AgentDID(clientDID))
+		require.NoError(t, err)
+	})
+
+	t.Run("port is compared only when asked", func(t *testing.T) {
+		cli, _, clientDID, serverDID := setup(t, "http://in-process:8080")
+		cli.WithEndpointVerification(false)
+		_, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.NoError(t, err)
+
+		cli.WithEndpointVerification(true)
+		_, err = cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.ErrorIs(t, err, ErrEndpointMismatch)
+	})
+}
+
+func TestMatchEndpoint(t *testing.T) {
+	tests := []struct {
+		connected, advertised string
+		matchPort             bool
+		ok                    bool
+	}{
+		{"https://agent.example.com", "https://agent.example.com/a2a", true, true},
+		{"https://agent.example.com", "https://agent.example.com:443", true, true},
+		{"wss://agent.example.com/ws", "https://Agent.Example.com", true, true},
+		{"https://agent.example.com:8443", "https://agent.example.com", false, true},
+		{"https://agent.example.com:8443", "https://agent.example.com", true, false},
+		{"https://impostor.example.com", "https://agent.example.com", false, false},
+		{"https://agent.example.com", "", false, false},
+		{"https://agent.example.com", "agent.example.com", false, false},
+	}
+	for _, tt := range tests {
+		err := MatchEndpoint(tt.connected, tt.advertised, tt.matchPort)
+		if tt.ok {
+			require.NoError(t, err, "%s vs %s", tt.connected, tt.advertised)
+		} else {
+			require.ErrorIs(t, err, ErrEndpointMismatch, "%s vs %s", tt.connected, tt.advertised)
+		}
+	}
+}
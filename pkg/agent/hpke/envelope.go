@@ -0,0 +1,104 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+)
+
+// envelopeInfoLabel prefixes the caller's info so a detached envelope can
+// never be opened as (or confused with) a handshake init packet.
+const envelopeInfoLabel = "sage/hpke-envelope v1|"
+
+// ErrEnvelopeOpen is returned when an envelope cannot be decrypted: wrong
+// recipient key, wrong info, or tampered enc/ciphertext. The causes are
+// deliberately indistinguishable.
+var ErrEnvelopeOpen = errors.New("hpke: envelope open failed")
+
+// HPKEEnvelope is a one-shot RFC 9180 Base-mode ciphertext addressed to a
+// single recipient KEM key. It is independent of the handshake and session
+// machinery and suits files or messages sent at rest or out of band.
+type HPKEEnvelope struct {
+	Suite      string `json:"suite"`      // KEMScheme.SuiteID of the recipient key
+	Enc        []byte `json:"enc"`        // HPKE encapsulated key
+	Ciphertext []byte `json:"ciphertext"` // AEAD ciphertext; info is bound through the key schedule
+}
+
+// SealToPublicKey encrypts plaintext to recipient, an X25519 or P-256 KEM
+// public key (*ecdh.PublicKey, *ecdsa.PublicKey, raw bytes, or a
+// sagecrypto.KeyPair). info binds the envelope to its context, e.g. a file
+// name or message type, and must be repeated exactly by OpenWithPrivateKey.
+func SealToPublicKey(recipient crypto.PublicKey, plaintext, info []byte) (*HPKEEnvelope, error) {
+	if kp, ok := recipient.(sagecrypto.KeyPair); ok {
+		recipient = kp.PublicKey()
+	}
+	scheme, pk, err := KEMSchemeForPublicKey(recipient)
+	if err != nil {
+		return nil, fmt.Errorf("envelope recipient: %w", err)
+	}
+	packet, _, err := keys.HPKESealAndExportToPeer(pk, plaintext, envelopeInfo(info), nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("envelope seal: %w", err)
+	}
+	encLen := scheme.EncLen()
+	return &HPKEEnvelope{
+		Suite:      scheme.SuiteID(),
+		Enc:        packet[:encLen:encLen],
+		Ciphertext: packet[encLen:],
+	}, nil
+}
+
+// OpenWithPrivateKey decrypts an envelope produced by SealToPublicKey. priv
+// is the recipient's KEM private key (*ecdh.PrivateKey, *ecdsa.PrivateKey,
+// or a sagecrypto.KeyPair) and info must match the value used to seal.
+func OpenWithPrivateKey(priv crypto.PrivateKey, env *HPKEEnvelope, info []byte) ([]byte, error) {
+	if env == nil {
+		return nil, errors.New("envelope is nil")
+	}
+	if kp, ok := priv.(sagecrypto.KeyPair); ok {
+		priv = kp.PrivateKey()
+	}
+	scheme, sk, err := KEMSchemeForPrivateKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("envelope recipient: %w", err)
+	}
+	if env.Suite != scheme.SuiteID() {
+		return nil, fmt.Errorf("%w: envelope suite %q, key suite %q", ErrKEMMismatch, env.Suite, scheme.SuiteID())
+	}
+	if len(env.Enc) != scheme.EncLen() {
+		return nil, fmt.Errorf("%w: invalid enc length %d", ErrEnvelopeOpen, len(env.Enc))
+	}
+
+	packet := make([]byte, 0, len(env.Enc)+len(env.Ciphertext))
+	packet = append(append(packet, env.Enc...), env.Ciphertext...)
+	pt, _, err := keys.HPKEOpenAndExportWithPriv(sk, packet, envelopeInfo(info), nil, 0)
+	if err != nil {
+		return nil, ErrEnvelopeOpen
+	}
+	return pt, nil
+}
+
+func envelopeInfo(info []byte) []byte {
+	return append([]byte(envelopeInfoLabel), info...)
+}
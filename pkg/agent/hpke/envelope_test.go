@@ -0,0 +1,145 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelope_RoundTrip(t *testing.T) {
+	x25519KP, err := keys.GenerateX25519KeyPair()
+	require.NoError(t, err)
+	p256KP, err := keys.GenerateP256KeyPair()
+	require.NoError(t, err)
+
+	large := make([]byte, 1<<20)
+	_, err = rand.Read(large)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name  string
+		kp    sagecrypto.KeyPair
+		suite string
+	}{
+		{"x25519", x25519KP, KEMX25519.SuiteID()},
+		{"p256", p256KP, KEMP256.SuiteID()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, pt := range [][]byte{[]byte("quarterly-report.pdf contents"), {}, large} {
+				info := []byte("file:report.pdf")
+				env, err := SealToPublicKey(tc.kp.PublicKey(), pt, info)
+				require.NoError(t, err)
+				assert.Equal(t, tc.suite, env.Suite)
+				assert.False(t, len(pt) > 0 && bytes.Contains(env.Ciphertext, pt), "ciphertext must not contain plaintext")
+
+				got, err := OpenWithPrivateKey(tc.kp.PrivateKey(), env, info)
+				require.NoError(t, err)
+				assert.True(t, bytes.Equal(pt, got))
+			}
+		})
+	}
+
+	t.Run("key pair and JSON", func(t *testing.T) {
+		env, err := SealToPublicKey(x25519KP, []byte("hello"), nil)
+		require.NoError(t, err)
+		data, err := json.Marshal(env)
+		require.NoError(t, err)
+
+		var decoded HPKEEnvelope
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		got, err := OpenWithPrivateKey(x25519KP, &decoded, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("hello"), got)
+	})
+
+	t.Run("fresh encapsulation per seal", func(t *testing.T) {
+		a, err := SealToPublicKey(x25519KP, []byte("same"), nil)
+		require.NoError(t, err)
+		b, err := SealToPublicKey(x25519KP, []byte("same"), nil)
+		require.NoError(t, err)
+		assert.NotEqual(t, a.Enc, b.Enc)
+		assert.NotEqual(t, a.Ciphertext, b.Ciphertext)
+	})
+}
+
+func TestEnvelope_OpenFailures(t *testing.T) {
+	recipient, err := keys.GenerateX25519KeyPair()
+	require.NoError(t, err)
+	other, err := keys.GenerateX25519KeyPair()
+	require.NoError(t, err)
+	p256KP, err := keys.GenerateP256KeyPair()
+	require.NoError(t, err)
+
+	info := []byte("file:secret.txt")
+	env, err := SealToPublicKey(recipient.PublicKey(), []byte("top secret"), info)
+	require.NoError(t, err)
+
+	t.Run("wrong recipient", func(t *testing.T) {
+		_, err := OpenWithPrivateKey(other.PrivateKey(), env, info)
+		assert.ErrorIs(t, err, ErrEnvelopeOpen)
+	})
+
+	t.Run("wrong recipient scheme", func(t *testing.T) {
+		_, err := OpenWithPrivateKey(p256KP.PrivateKey(), env, info)
+		assert.ErrorIs(t, err, ErrKEMMismatch)
+	})
+
+	t.Run("wrong info", func(t *testing.T) {
+		_, err := OpenWithPrivateKey(recipient.PrivateKey(), env, []byte("file:other.txt"))
+		assert.ErrorIs(t, err, ErrEnvelopeOpen)
+	})
+
+	t.Run("tampered ciphertext", func(t *testing.T) {
+		tampered := *env
+		tampered.Ciphertext = append([]byte(nil), env.Ciphertext...)
+		tampered.Ciphertext[0] ^= 0x01
+		_, err := OpenWithPrivateKey(recipient.PrivateKey(), &tampered, info)
+		assert.ErrorIs(t, err, ErrEnvelopeOpen)
+	})
+
+	t.Run("tampered enc", func(t *testing.T) {
+		tampered := *env
+		tampered.Enc = append([]byte(nil), env.Enc...)
+		tampered.Enc[0] ^= 0x01
+		_, err := OpenWithPrivateKey(recipient.PrivateKey(), &tampered, info)
+		assert.ErrorIs(t, err, ErrEnvelopeOpen)
+	})
+
+	t.Run("truncated enc", func(t *testing.T) {
+		tampered := *env
+		tampered.Enc = env.Enc[:16]
+		_, err := OpenWithPrivateKey(recipient.PrivateKey(), &tampered, info)
+		assert.ErrorIs(t, err, ErrEnvelopeOpen)
+	})
+
+	t.Run("signing key rejected", func(t *testing.T) {
+		ed, err := keys.GenerateEd25519KeyPair()
+		require.NoError(t, err)
+		_, err = SealToPublicKey(ed.PublicKey(), []byte("x"), nil)
+		assert.Error(t, err)
+	})
+}
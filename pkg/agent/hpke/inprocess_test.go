@@ -0,0 +1,77 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	sagehttp "github.com/sage-x-project/sage/pkg/agent/transport/http"
+	"github.com/stretchr/testify/require"
+)
+
+// openSockets counts this process's socket file descriptors (Linux only).
+func openSockets(t *testing.T) int {
+	t.Helper()
+	fds, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skip("cannot inspect file descriptors on this platform")
+	}
+	n := 0
+	for _, fd := range fds {
+		target, err := os.Readlink("/proc/self/fd/" + fd.Name())
+		if err == nil && strings.HasPrefix(target, "socket:") {
+			n++
+		}
+	}
+	return n
+}
+
+func Test_HPKE_Handshake_InProcessTransport(t *testing.T) {
+	ctx := context.Background()
+
+	base, srv, srvMgr, cliMgr, _, resolver, _, clientDID, serverDID :=
+		setupHPKETestWithTransport(t, session.Config{}, session.Config{})
+
+	before := openSockets(t)
+
+	// Real HTTP transport, but over an in-process listener instead of TCP
+	tr, stop := sagehttp.NewInProcessTransport(srv.HandleMessage)
+	defer func() { require.NoError(t, stop()) }()
+	cli := NewClient(tr, resolver, base.key, clientDID, DefaultInfoBuilder{}, cliMgr)
+
+	kid, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+	require.NoError(t, err)
+
+	sCli, ok := cliMgr.GetByKeyID(kid)
+	require.True(t, ok)
+	sSrv, ok := srvMgr.GetByKeyID(kid)
+	require.True(t, ok)
+	ct, err := sCli.Encrypt([]byte("no sockets"))
+	require.NoError(t, err)
+	pt, err := sSrv.Decrypt(ct)
+	require.NoError(t, err)
+	require.Equal(t, "no sockets", string(pt))
+
+	require.Equal(t, before, openSockets(t), "handshake must not open sockets")
+}
@@ -0,0 +1,233 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+)
+
+// KEMScheme is the key agreement behind the handshake: the HPKE KEM run
+// against the responder's static KEM key and the curve of the ephemeral
+// ephC/ephS exchange. Everything after key agreement (combiner, ackTag,
+// session derivation) is identical for every scheme.
+//
+// The scheme is chosen from the responder's published KEM keys: the client
+// takes its most preferred scheme the responder offers (see
+// Client.WithKEMPreference), announces it in the init payload, and the
+// server rejects inits for which it holds no KEM key of that scheme.
+type KEMScheme interface {
+	// Name is the wire identifier carried in the init payload ("kem").
+	Name() string
+	// SuiteID identifies the HPKE suite for ServerOpts.AllowedSuites.
+	SuiteID() string
+	// Curve is the ECDH curve for both the static and ephemeral keys.
+	Curve() ecdh.Curve
+	// EncLen is the length of the HPKE encapsulated key.
+	EncLen() int
+	// Encap runs HPKE Base setup to peer and returns enc and a 32-byte exporter.
+	Encap(peer *ecdh.PublicKey, info, exportCtx []byte) (enc, exporter []byte, err error)
+	// Decap reproduces the exporter from the static private key and enc.
+	Decap(priv *ecdh.PrivateKey, enc, info, exportCtx []byte) (exporter []byte, err error)
+}
+
+// Built-in KEM schemes.
+var (
+	// KEMX25519 is DHKEM(X25519, HKDF-SHA256) with X25519 ephemerals (default).
+	KEMX25519 KEMScheme = ecdhKEM{name: did.KEMSchemeX25519, suite: hpkeSuiteID, curve: ecdh.X25519()}
+	// KEMP256 is DHKEM(P-256, HKDF-SHA256) with P-256 ephemerals, for
+	// hardware and FIPS contexts that cannot use X25519.
+	KEMP256 KEMScheme = ecdhKEM{name: did.KEMSchemeP256, suite: "hpke-base+p256+hkdf-sha256", curve: ecdh.P256()}
+)
+
+// ErrKEMMismatch is returned when the two sides use different KEM schemes.
+var ErrKEMMismatch = errors.New("hpke: KEM scheme mismatch")
+
+// KEMSchemeByName returns the built-in scheme with the given wire name.
+// An empty name selects KEMX25519, which is what peers predating scheme
+// negotiation use.
+func KEMSchemeByName(name string) (KEMScheme, error) {
+	switch name {
+	case "", KEMX25519.Name():
+		return KEMX25519, nil
+	case KEMP256.Name():
+		return KEMP256, nil
+	default:
+		return nil, fmt.Errorf("unsupported KEM scheme: %q", name)
+	}
+}
+
+// DefaultKEMPreference is the client's scheme order when none is configured.
+var DefaultKEMPreference = []KEMScheme{KEMX25519, KEMP256}
+
+// SelectKEMKey picks the first scheme in prefs for which entries holds a key
+// and returns that scheme with the key in ECDH form. It fails with
+// ErrKEMMismatch when the peer publishes no key of an acceptable scheme.
+func SelectKEMKey(entries []did.KEMKeyEntry, prefs []KEMScheme) (KEMScheme, *ecdh.PublicKey, error) {
+	for _, want := range prefs {
+		for _, e := range entries {
+			if e.Scheme != want.Name() {
+				continue
+			}
+			scheme, pk, err := KEMSchemeForPublicKey(e.PublicKey)
+			if err != nil {
+				return nil, nil, fmt.Errorf("peer %s KEM key: %w", e.Scheme, err)
+			}
+			if scheme != want {
+				return nil, nil, fmt.Errorf("peer KEM key labelled %s is a %s key", e.Scheme, scheme.Name())
+			}
+			return scheme, pk, nil
+		}
+	}
+
+	offered := make([]string, 0, len(entries))
+	for _, e := range entries {
+		offered = append(offered, e.Scheme)
+	}
+	accepted := make([]string, 0, len(prefs))
+	for _, p := range prefs {
+		accepted = append(accepted, p.Name())
+	}
+	return nil, nil, fmt.Errorf("%w: peer offers %v, client accepts %v", ErrKEMMismatch, offered, accepted)
+}
+
+// kemSchemeForCurve maps an ECDH curve to its built-in scheme.
+func kemSchemeForCurve(curve ecdh.Curve) (KEMScheme, error) {
+	for _, s := range []KEMScheme{KEMX25519, KEMP256} {
+		if s.Curve() == curve {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported KEM curve: %v", curve)
+}
+
+// KEMSchemeForPublicKey selects the scheme for a resolved KEM public key and
+// returns the key in ECDH form. Raw keys are told apart by length: 32 bytes
+// is X25519, 65 bytes an uncompressed P-256 point.
+func KEMSchemeForPublicKey(pub interface{}) (KEMScheme, *ecdh.PublicKey, error) {
+	switch v := pub.(type) {
+	case []byte:
+		var scheme KEMScheme
+		switch len(v) {
+		case 32:
+			scheme = KEMX25519
+		case 65:
+			scheme = KEMP256
+		default:
+			return nil, nil, fmt.Errorf("invalid KEM key length: %d", len(v))
+		}
+		pk, err := scheme.Curve().NewPublicKey(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s public key decode: %w", scheme.Name(), err)
+		}
+		return scheme, pk, nil
+	case *ecdh.PublicKey, *ecdsa.PublicKey:
+		pk, err := keys.ECDHPublicKey(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		scheme, err := kemSchemeForCurve(pk.Curve())
+		if err != nil {
+			return nil, nil, err
+		}
+		return scheme, pk, nil
+	default:
+		return nil, nil, fmt.Errorf("unexpected KEM key type %T", v)
+	}
+}
+
+// KEMSchemeForPrivateKey selects the scheme for a static KEM private key.
+func KEMSchemeForPrivateKey(priv crypto.PrivateKey) (KEMScheme, *ecdh.PrivateKey, error) {
+	sk, err := keys.ECDHPrivateKey(priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	scheme, err := kemSchemeForCurve(sk.Curve())
+	if err != nil {
+		return nil, nil, err
+	}
+	return scheme, sk, nil
+}
+
+// ecdhKEM implements KEMScheme over an RFC 9180 DHKEM curve.
+type ecdhKEM struct {
+	name  string
+	suite string
+	curve ecdh.Curve
+}
+
+func (k ecdhKEM) Name() string      { return k.name }
+func (k ecdhKEM) SuiteID() string   { return k.suite }
+func (k ecdhKEM) Curve() ecdh.Curve { return k.curve }
+
+func (k ecdhKEM) EncLen() int {
+	n, _ := keys.HPKEEncLen(k.curve) // built-in curves are always supported
+	return n
+}
+
+func (k ecdhKEM) Encap(peer *ecdh.PublicKey, info, exportCtx []byte) ([]byte, []byte, error) {
+	if peer.Curve() != k.curve {
+		return nil, nil, fmt.Errorf("%w: %s key used with %s scheme", ErrKEMMismatch, peer.Curve(), k.name)
+	}
+	return keys.HPKEDeriveSharedSecretToPeer(peer, info, exportCtx, 32)
+}
+
+func (k ecdhKEM) Decap(priv *ecdh.PrivateKey, enc, info, exportCtx []byte) ([]byte, error) {
+	if priv.Curve() != k.curve {
+		return nil, fmt.Errorf("%w: %s key used with %s scheme", ErrKEMMismatch, priv.Curve(), k.name)
+	}
+	if len(enc) != k.EncLen() {
+		return nil, fmt.Errorf("%w: enc length %d, want %d for %s", ErrKEMMismatch, len(enc), k.EncLen(), k.name)
+	}
+	return keys.HPKEOpenSharedSecretWithPriv(priv, enc, info, exportCtx, 32)
+}
+
+// generateEphemeral creates the E2E ephemeral key for a scheme.
+func generateEphemeral(scheme KEMScheme) (*ecdh.PrivateKey, error) {
+	priv, err := scheme.Curve().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("%s ephemeral gen: %w", scheme.Name(), err)
+	}
+	return priv, nil
+}
+
+// ecdhE2E computes the E2E secret between a local ephemeral key and the
+// peer's raw ephemeral public key on the same curve.
+func ecdhE2E(priv *ecdh.PrivateKey, peerRaw []byte) ([]byte, error) {
+	peer, err := priv.Curve().NewPublicKey(peerRaw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad ephemeral key: %v", ErrKEMMismatch, err)
+	}
+	ss, err := priv.ECDH(peer)
+	if err != nil {
+		return nil, fmt.Errorf("e2e ecdh: %w", err)
+	}
+	// RFC 7748 (X25519); P-256 rejects the identity point itself
+	if isAllZero32(ss) {
+		return nil, fmt.Errorf("invalid ECDH (all-zero)")
+	}
+	return ss, nil
+}
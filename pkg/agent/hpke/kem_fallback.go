@@ -0,0 +1,142 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+)
+
+// ErrNoKEMKey is returned when the peer's KEM key cannot be resolved and the
+// client is not configured to fall back to its signing key. The resolver
+// error, if any, is wrapped alongside it.
+var ErrNoKEMKey = errors.New("hpke: peer KEM key unavailable")
+
+// KEMFallback selects what a Client does when the peer publishes no usable
+// KEM key.
+type KEMFallback int
+
+const (
+	// KEMFallbackStrict fails with ErrNoKEMKey (default).
+	KEMFallbackStrict KEMFallback = iota
+	// KEMFallbackDeriveFromSigningKey maps the peer's Ed25519 signing key to
+	// X25519 and uses it as an X25519 KEM key. The peer must accept it, see
+	// ServerOpts.DeriveKEMFromSigningKey. One key then serves both signing
+	// and key agreement, so this is meant for transition periods only.
+	KEMFallbackDeriveFromSigningKey
+)
+
+// String returns the fallback name.
+func (f KEMFallback) String() string {
+	switch f {
+	case KEMFallbackStrict:
+		return "strict"
+	case KEMFallbackDeriveFromSigningKey:
+		return "derive-from-signing-key"
+	default:
+		return fmt.Sprintf("KEMFallback(%d)", int(f))
+	}
+}
+
+// KEMFallbackWarning is told whenever a KEM key was derived from a signing
+// key, with the resolver error that caused the fallback.
+type KEMFallbackWarning func(peerDID string, cause error)
+
+// WithKEMFallback sets the behaviour when the peer has no KEM key. warn is
+// called on every derived key; nil derives silently, so pass one that
+// reaches the application's logger to keep the fallback visible.
+func (c *Client) WithKEMFallback(f KEMFallback, warn KEMFallbackWarning) *Client {
+	c.kemFallback = f
+	c.kemWarn = warn
+	return c
+}
+
+// kemUnavailable applies the configured KEMFallback after the peer's KEM key
+// could not be resolved.
+func (c *Client) kemUnavailable(ctx context.Context, peerDID string, cause error) (KEMScheme, *ecdh.PublicKey, error) {
+	if c.kemFallback != KEMFallbackDeriveFromSigningKey {
+		return nil, nil, fmt.Errorf("%w: %w", ErrNoKEMKey, cause)
+	}
+	if !c.acceptsKEM(KEMX25519) {
+		return nil, nil, fmt.Errorf("%w: %w (fallback needs %s)", ErrNoKEMKey, cause, KEMX25519.Name())
+	}
+
+	pub, err := c.resolver.ResolvePublicKey(ctx, did.AgentDID(peerDID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w; resolve signing key: %w", ErrNoKEMKey, cause, err)
+	}
+	if kp, ok := pub.(sagecrypto.KeyPair); ok {
+		pub = kp.PublicKey()
+	}
+	edPub, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %w; signing key is %T, not Ed25519", ErrNoKEMKey, cause, pub)
+	}
+	raw, err := keys.Ed25519PublicKeyToX25519(edPub)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w; derive: %w", ErrNoKEMKey, cause, err)
+	}
+	pk, err := KEMX25519.Curve().NewPublicKey(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w; derive: %w", ErrNoKEMKey, cause, err)
+	}
+
+	if c.kemWarn != nil {
+		c.kemWarn(peerDID, cause)
+	}
+	return KEMX25519, pk, nil
+}
+
+func (c *Client) acceptsKEM(scheme KEMScheme) bool {
+	for _, s := range c.kemPreference() {
+		if s == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+// signingKeyKEM derives the X25519 KEM key matching an Ed25519 signing key,
+// or returns nil if key is not Ed25519.
+func signingKeyKEM(key sagecrypto.KeyPair) sagecrypto.KeyPair {
+	if key == nil || key.Type() != sagecrypto.KeyTypeEd25519 {
+		return nil
+	}
+	kem, err := keys.X25519KeyPairFromEd25519(key)
+	if err != nil {
+		return nil
+	}
+	return kem
+}
+
+func hasKEMScheme(kems []sagecrypto.KeyPair, want KEMScheme) bool {
+	for _, kp := range kems {
+		if scheme, _, err := KEMSchemeForPrivateKey(kp.PrivateKey()); err == nil && scheme == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	sagedid "github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+	"github.com/stretchr/testify/require"
+	"github.com/test-go/testify/mock"
+)
+
+// setupNoKEMTest wires a client and server where the server publishes only
+// its Ed25519 signing key and accepts the KEM key derived from it.
+func setupNoKEMTest(t *testing.T) (*Client, *session.Manager, *session.Manager, string, string) {
+	t.Helper()
+
+	clientDID := "did:sage:test:client-" + uuid.NewString()
+	serverDID := "did:sage:test:server-" + uuid.NewString()
+
+	serverSignKP, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	clientSignKP, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+
+	ethResolver := new(mockResolver)
+	multiResolver := sagedid.NewMultiChainResolver()
+	multiResolver.AddResolver(sagedid.ChainEthereum, ethResolver)
+	ethResolver.On("Resolve", mock.Anything, sagedid.AgentDID(clientDID)).Return(&sagedid.AgentMetadata{
+		DID: sagedid.AgentDID(clientDID), IsActive: true, PublicKey: clientSignKP.PublicKey(),
+	}, nil)
+	ethResolver.On("Resolve", mock.Anything, sagedid.AgentDID(serverDID)).Return(&sagedid.AgentMetadata{
+		DID: sagedid.AgentDID(serverDID), IsActive: true, PublicKey: serverSignKP.PublicKey(),
+	}, nil)
+
+	srvMgr := session.NewManager()
+	cliMgr := session.NewManager()
+	t.Cleanup(func() {
+		_ = srvMgr.Close()
+		_ = cliMgr.Close()
+	})
+
+	srv := NewServer(serverSignKP, srvMgr, serverDID, multiResolver, &ServerOpts{
+		MaxSkew:                 2 * time.Minute,
+		DeriveKEMFromSigningKey: true,
+	})
+	tr := &transport.MockTransport{
+		SendFunc: func(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
+			return srv.HandleMessage(ctx, msg)
+		},
+	}
+	cli := NewClient(tr, multiResolver, clientSignKP, clientDID, DefaultInfoBuilder{}, cliMgr)
+	return cli, srvMgr, cliMgr, clientDID, serverDID
+}
+
+func Test_HPKE_KEM_Fallback(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("strict mode returns ErrNoKEMKey", func(t *testing.T) {
+		cli, srvMgr, cliMgr, clientDID, serverDID := setupNoKEMTest(t)
+
+		_, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.ErrorIs(t, err, ErrNoKEMKey)
+		require.Zero(t, cliMgr.GetSessionCount())
+		require.Zero(t, srvMgr.GetSessionCount())
+	})
+
+	t.Run("derive mode completes the handshake and warns", func(t *testing.T) {
+		cli, srvMgr, cliMgr, clientDID, serverDID := setupNoKEMTest(t)
+		var warned []string
+		cli.WithKEMFallback(KEMFallbackDeriveFromSigningKey, func(peerDID string, cause error) {
+			require.Error(t, cause)
+			warned = append(warned, peerDID)
+		})
+
+		kid, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.NoError(t, err)
+		require.Equal(t, []string{serverDID}, warned)
+
+		sCli, ok := cliMgr.GetByKeyID(kid)
+		require.True(t, ok)
+		sSrv, ok := srvMgr.GetByKeyID(kid)
+		require.True(t, ok)
+		ct, err := sCli.Encrypt([]byte("derived kem"))
+		require.NoError(t, err)
+		pt, err := sSrv.Decrypt(ct)
+		require.NoError(t, err)
+		require.Equal(t, "derived kem", string(pt))
+	})
+
+	t.Run("derive mode without a warning callback", func(t *testing.T) {
+		cli, _, _, clientDID, serverDID := setupNoKEMTest(t)
+		cli.WithKEMFallback(KEMFallbackDeriveFromSigningKey, nil)
+
+		_, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.NoError(t, err)
+	})
+
+	t.Run("derive mode needs X25519 in the preference", func(t *testing.T) {
+		cli, _, _, clientDID, serverDID := setupNoKEMTest(t)
+		cli.WithKEMFallback(KEMFallbackDeriveFromSigningKey, nil).WithKEMPreference(KEMP256)
+
+		_, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.ErrorIs(t, err, ErrNoKEMKey)
+	})
+}
+
+func Test_X25519KeyPairFromEd25519_MatchesPublicMapping(t *testing.T) {
+	edKP, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+
+	kem := signingKeyKEM(edKP)
+	require.NotNil(t, kem)
+	raw, err := keys.Ed25519PublicKeyToX25519(edKP.PublicKey())
+	require.NoError(t, err)
+	_, sk, err := KEMSchemeForPrivateKey(kem.PrivateKey())
+	require.NoError(t, err)
+	require.Equal(t, raw, sk.PublicKey().Bytes())
+}
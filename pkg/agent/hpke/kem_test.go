@@ -0,0 +1,214 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	sagedid "github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+	"github.com/stretchr/testify/require"
+	"github.com/test-go/testify/mock"
+)
+
+// setupKEMTest wires a client and server where the server holds serverKEMs and
+// the registry advertises advertisedKEM (plus extraKEMs) as its KEM keys.
+func setupKEMTest(t *testing.T, serverKEMs []sagecrypto.KeyPair, advertisedKEM interface{}, extraKEMs ...sagedid.KEMKeyEntry) (*Client, *session.Manager, *session.Manager, string, string) {
+	t.Helper()
+
+	clientDID := "did:sage:test:client-" + uuid.NewString()
+	serverDID := "did:sage:test:server-" + uuid.NewString()
+
+	serverSignKP, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	clientSignKP, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+
+	ethResolver := new(mockResolver)
+	multiResolver := sagedid.NewMultiChainResolver()
+	multiResolver.AddResolver(sagedid.ChainEthereum, ethResolver)
+	ethResolver.On("Resolve", mock.Anything, sagedid.AgentDID(clientDID)).Return(&sagedid.AgentMetadata{
+		DID: sagedid.AgentDID(clientDID), IsActive: true, PublicKey: clientSignKP,
+	}, nil)
+	ethResolver.On("Resolve", mock.Anything, sagedid.AgentDID(serverDID)).Return(&sagedid.AgentMetadata{
+		DID: sagedid.AgentDID(serverDID), IsActive: true, PublicKey: serverSignKP, PublicKEMKey: advertisedKEM, KEMKeys: extraKEMs,
+	}, nil)
+
+	srvMgr := session.NewManager()
+	cliMgr := session.NewManager()
+	t.Cleanup(func() {
+		_ = srvMgr.Close()
+		_ = cliMgr.Close()
+	})
+
+	srv := NewServer(serverSignKP, srvMgr, serverDID, multiResolver, &ServerOpts{
+		MaxSkew: 2 * time.Minute,
+		KEM:     serverKEMs[0],
+		KEMKeys: serverKEMs[1:],
+	})
+	tr := &transport.MockTransport{
+		SendFunc: func(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
+			return srv.HandleMessage(ctx, msg)
+		},
+	}
+	cli := NewClient(tr, multiResolver, clientSignKP, clientDID, DefaultInfoBuilder{}, cliMgr)
+	return cli, srvMgr, cliMgr, clientDID, serverDID
+}
+
+func Test_HPKE_KEM_P256_EndToEnd(t *testing.T) {
+	ctx := context.Background()
+
+	serverKEM, err := keys.GenerateP256KeyPair()
+	require.NoError(t, err)
+
+	// Registries may hold the P-256 KEM key as an ECDSA key or as raw bytes
+	ecdhPub, err := serverKEM.PublicKey().(*ecdsa.PublicKey).ECDH()
+	require.NoError(t, err)
+	for name, advertised := range map[string]interface{}{
+		"ecdsa key": serverKEM.PublicKey(),
+		"raw bytes": ecdhPub.Bytes(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			cli, srvMgr, cliMgr, clientDID, serverDID := setupKEMTest(t, []sagecrypto.KeyPair{serverKEM}, advertised)
+
+			kid, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+			require.NoError(t, err)
+
+			sCli, ok := cliMgr.GetByKeyID(kid)
+			require.True(t, ok)
+			sSrv, ok := srvMgr.GetByKeyID(kid)
+			require.True(t, ok)
+
+			ct, err := sCli.Encrypt([]byte("over p-256"))
+			require.NoError(t, err)
+			pt, err := sSrv.Decrypt(ct)
+			require.NoError(t, err)
+			require.Equal(t, "over p-256", string(pt))
+		})
+	}
+}
+
+func Test_HPKE_KEM_Mismatch(t *testing.T) {
+	ctx := context.Background()
+
+	x25519KP, err := keys.GenerateX25519KeyPair()
+	require.NoError(t, err)
+	p256KP, err := keys.GenerateP256KeyPair()
+	require.NoError(t, err)
+
+	t.Run("client X25519, server P-256", func(t *testing.T) {
+		cli, srvMgr, cliMgr, clientDID, serverDID := setupKEMTest(t, []sagecrypto.KeyPair{p256KP}, x25519KP.PublicKey())
+
+		_, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), ErrKEMMismatch.Error())
+		require.Zero(t, cliMgr.GetSessionCount())
+		require.Zero(t, srvMgr.GetSessionCount())
+	})
+
+	t.Run("client P-256, server X25519", func(t *testing.T) {
+		cli, srvMgr, cliMgr, clientDID, serverDID := setupKEMTest(t, []sagecrypto.KeyPair{x25519KP}, p256KP.PublicKey())
+
+		_, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), ErrKEMMismatch.Error())
+		require.Zero(t, cliMgr.GetSessionCount())
+		require.Zero(t, srvMgr.GetSessionCount())
+	})
+}
+
+func Test_HPKE_KEM_MultipleKeys_SelectByPreference(t *testing.T) {
+	ctx := context.Background()
+
+	x25519KP, err := keys.GenerateX25519KeyPair()
+	require.NoError(t, err)
+	p256KP, err := keys.GenerateP256KeyPair()
+	require.NoError(t, err)
+
+	// The server publishes and holds both KEM keys
+	serverKEMs := []sagecrypto.KeyPair{x25519KP, p256KP}
+	extra := sagedid.KEMKeyEntry{Scheme: sagedid.KEMSchemeP256, PublicKey: p256KP.PublicKey()}
+
+	for _, tc := range []struct {
+		name string
+		pref []KEMScheme
+	}{
+		{"default prefers x25519", nil},
+		{"p256 preferred", []KEMScheme{KEMP256, KEMX25519}},
+		{"p256 only", []KEMScheme{KEMP256}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, srvMgr, cliMgr, clientDID, serverDID := setupKEMTest(t, serverKEMs, x25519KP.PublicKey(), extra)
+			cli.WithKEMPreference(tc.pref...)
+
+			want := KEMX25519
+			if len(tc.pref) > 0 {
+				want = tc.pref[0]
+			}
+			scheme, _, err := cli.resolvePeerKEM(ctx, serverDID)
+			require.NoError(t, err)
+			require.Equal(t, want, scheme)
+
+			kid, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+			require.NoError(t, err)
+
+			sCli, ok := cliMgr.GetByKeyID(kid)
+			require.True(t, ok)
+			sSrv, ok := srvMgr.GetByKeyID(kid)
+			require.True(t, ok)
+			ct, err := sCli.Encrypt([]byte("negotiated"))
+			require.NoError(t, err)
+			pt, err := sSrv.Decrypt(ct)
+			require.NoError(t, err)
+			require.Equal(t, "negotiated", string(pt))
+		})
+	}
+
+	t.Run("no common scheme", func(t *testing.T) {
+		cli, _, _, clientDID, serverDID := setupKEMTest(t, []sagecrypto.KeyPair{x25519KP}, x25519KP.PublicKey())
+		cli.WithKEMPreference(KEMP256)
+
+		_, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.ErrorIs(t, err, ErrKEMMismatch)
+	})
+}
+
+func Test_KEMSchemeSelection(t *testing.T) {
+	s, err := KEMSchemeByName("")
+	require.NoError(t, err)
+	require.Equal(t, KEMX25519, s, "absent kem means X25519")
+
+	s, err = KEMSchemeByName("p256")
+	require.NoError(t, err)
+	require.Equal(t, KEMP256, s)
+	require.Equal(t, 65, s.EncLen())
+
+	_, err = KEMSchemeByName("kyber768")
+	require.Error(t, err)
+
+	_, _, err = KEMSchemeForPublicKey(make([]byte, 33))
+	require.Error(t, err)
+}
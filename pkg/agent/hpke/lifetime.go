@@ -0,0 +1,138 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sage-x-project/sage/pkg/agent/session"
+)
+
+// sessionLabel is the session manager label for handshake-derived sessions
+const sessionLabel = "sage/hpke+e2e v1"
+
+// ErrLifetimeRejected is returned when a client proposes a session lifetime
+// longer than the server's session policy.
+var ErrLifetimeRejected = errors.New("session lifetime exceeds server policy")
+
+// SessionLifetime is the session policy both peers commit to during the
+// handshake. With a LifetimeInfoBuilder it is bound into the HPKE info and
+// into the session key derivation, so a peer that runs the session under a
+// different policy than the one agreed derives different keys and its first
+// protected message fails to open.
+type SessionLifetime struct {
+	MaxAge      time.Duration
+	IdleTimeout time.Duration
+	MaxMessages int
+}
+
+// LifetimeFromConfig extracts the lifetime fields of a session config
+func LifetimeFromConfig(cfg session.Config) SessionLifetime {
+	return SessionLifetime{
+		MaxAge:      cfg.MaxAge,
+		IdleTimeout: cfg.IdleTimeout,
+		MaxMessages: cfg.MaxMessages,
+	}
+}
+
+// String is the canonical encoding used in the HPKE info, the init payload
+// and the session label. Durations are in nanoseconds so it round-trips.
+func (lt SessionLifetime) String() string {
+	return fmt.Sprintf("maxAge=%d|idle=%d|maxMsgs=%d", int64(lt.MaxAge), int64(lt.IdleTimeout), lt.MaxMessages)
+}
+
+// ParseSessionLifetime parses the String encoding
+func ParseSessionLifetime(s string) (SessionLifetime, error) {
+	var maxAge, idle int64
+	var lt SessionLifetime
+	if _, err := fmt.Sscanf(s, "maxAge=%d|idle=%d|maxMsgs=%d", &maxAge, &idle, &lt.MaxMessages); err != nil {
+		return SessionLifetime{}, fmt.Errorf("bad session lifetime %q: %w", s, err)
+	}
+	lt.MaxAge, lt.IdleTimeout = time.Duration(maxAge), time.Duration(idle)
+	if err := lt.validate(); err != nil {
+		return SessionLifetime{}, err
+	}
+	if lt.String() != s {
+		return SessionLifetime{}, fmt.Errorf("bad session lifetime %q: not canonical", s)
+	}
+	return lt, nil
+}
+
+func (lt SessionLifetime) validate() error {
+	if lt.MaxAge <= 0 || lt.IdleTimeout <= 0 || lt.MaxMessages <= 0 {
+		return fmt.Errorf("bad session lifetime %s: all limits must be positive", lt)
+	}
+	return nil
+}
+
+// within reports whether every limit of lt is at most the matching limit
+// of policy. Unset policy limits do not constrain.
+func (lt SessionLifetime) within(policy SessionLifetime) bool {
+	return (policy.MaxAge <= 0 || lt.MaxAge <= policy.MaxAge) &&
+		(policy.IdleTimeout <= 0 || lt.IdleTimeout <= policy.IdleTimeout) &&
+		(policy.MaxMessages <= 0 || lt.MaxMessages <= policy.MaxMessages)
+}
+
+// apply returns base with the lifetime limits replaced by lt
+func (lt SessionLifetime) apply(base session.Config) *session.Config {
+	base.MaxAge = lt.MaxAge
+	base.IdleTimeout = lt.IdleTimeout
+	base.MaxMessages = lt.MaxMessages
+	return &base
+}
+
+// lifetimeSessionLabel binds lt and the sequence number choice into the
+// session ID, and with it the session keys. A nil lifetime without sequence
+// numbers keeps the unbound label of peers without negotiation.
+func lifetimeSessionLabel(lt *SessionLifetime, seq bool) string {
+	label := sessionLabel
+	if lt != nil {
+		label += "|" + lt.String()
+	}
+	if seq {
+		label += "|seq"
+	}
+	return label
+}
+
+// buildInfo builds the HPKE info, binding lt when the builder supports it
+func buildInfo(ib InfoBuilder, ctxID, initDID, respDID string, lt *SessionLifetime) []byte {
+	if lb, ok := ib.(LifetimeInfoBuilder); ok && lt != nil {
+		return lb.BuildInfoWithLifetime(ctxID, initDID, respDID, *lt)
+	}
+	return ib.BuildInfo(ctxID, initDID, respDID)
+}
+
+// ensureLifetimeSession creates (or returns) the handshake session for the
+// given role, deriving its keys under the agreed lifetime and with sequence
+// numbers when the initiator proposed them
+func ensureLifetimeSession(mgr *session.Manager, combined []byte, initiator bool, lt *SessionLifetime, seq bool) (string, error) {
+	cfg := mgr.GetDefaultConfig()
+	if lt != nil {
+		cfg = *lt.apply(cfg)
+	}
+	cfg.SequenceNumbers = seq
+	_, sid, _, err := mgr.EnsureSessionFromExporterWithRole(combined, lifetimeSessionLabel(lt, seq), initiator, &cfg)
+	if err != nil {
+		return "", fmt.Errorf("session: %w", err)
+	}
+	return sid, nil
+}
@@ -0,0 +1,177 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HPKE_SessionLifetime(t *testing.T) {
+	policy := session.Config{MaxAge: time.Hour, IdleTimeout: 10 * time.Minute, MaxMessages: 1000}
+
+	t.Run("agreed lifetime applies to both sessions", func(t *testing.T) {
+		cli, _, srvMgr, cliMgr, _, _, _, clientDID, serverDID := setupHPKETestWithTransport(t, policy, policy)
+		lt := SessionLifetime{MaxAge: 30 * time.Minute, IdleTimeout: 5 * time.Minute, MaxMessages: 50}
+		cli.WithSessionLifetime(lt)
+
+		kid, err := cli.Initialize(context.Background(), "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.NoError(t, err)
+
+		cliSess, ok := cliMgr.GetByKeyID(kid)
+		require.True(t, ok)
+		srvSess, ok := srvMgr.GetByKeyID(kid)
+		require.True(t, ok)
+		require.Equal(t, lt, LifetimeFromConfig(cliSess.GetConfig()))
+		require.Equal(t, lt, LifetimeFromConfig(srvSess.GetConfig()))
+
+		ct, err := cliSess.Encrypt([]byte("hello"))
+		require.NoError(t, err)
+		pt, err := srvSess.Decrypt(ct)
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(pt))
+	})
+
+	t.Run("lifetime beyond server policy is rejected", func(t *testing.T) {
+		cli, _, _, _, _, _, _, clientDID, serverDID := setupHPKETestWithTransport(t, policy, policy)
+		cli.WithSessionLifetime(SessionLifetime{MaxAge: 24 * time.Hour, IdleTimeout: time.Minute, MaxMessages: 10})
+
+		_, err := cli.Initialize(context.Background(), "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.ErrorContains(t, err, ErrLifetimeRejected.Error())
+	})
+
+	t.Run("info commits to the lifetime", func(t *testing.T) {
+		lt := LifetimeFromConfig(policy)
+		longer := lt
+		longer.MaxMessages *= 10
+		a := DefaultInfoBuilder{}.BuildInfoWithLifetime("ctx", "did:a", "did:b", lt)
+		b := DefaultInfoBuilder{}.BuildInfoWithLifetime("ctx", "did:a", "did:b", longer)
+		require.NotEqual(t, a, b)
+
+		parsed, err := ParseSessionLifetime(lt.String())
+		require.NoError(t, err)
+		require.Equal(t, lt, parsed)
+	})
+
+	t.Run("mismatched claimed lifetime fails the first protected call", func(t *testing.T) {
+		cliMgr := session.NewManager()
+		srvMgr := session.NewManager()
+		t.Cleanup(func() {
+			_ = cliMgr.Close()
+			_ = srvMgr.Close()
+		})
+
+		combined := make([]byte, 32)
+		_, err := rand.Read(combined)
+		require.NoError(t, err)
+
+		bound := LifetimeFromConfig(policy)
+		claimed := bound
+		claimed.MaxAge = 24 * time.Hour
+
+		// The server derives under the lifetime bound in the handshake, the
+		// client under the longer lifetime it later claims.
+		srvSID, err := ensureLifetimeSession(srvMgr, combined, false, &bound, false)
+		require.NoError(t, err)
+		cliSID, err := ensureLifetimeSession(cliMgr, combined, true, &claimed, false)
+		require.NoError(t, err)
+		require.NotEqual(t, srvSID, cliSID)
+
+		cliSess, ok := cliMgr.GetSession(cliSID)
+		require.True(t, ok)
+		srvSess, ok := srvMgr.GetSession(srvSID)
+		require.True(t, ok)
+
+		ct, err := cliSess.Encrypt([]byte("first call"))
+		require.NoError(t, err)
+		_, err = srvSess.Decrypt(ct)
+		require.Error(t, err)
+
+		// Under the bound lifetime the same secret yields matching keys
+		honestSID, err := ensureLifetimeSession(cliMgr, combined, true, &bound, false)
+		require.NoError(t, err)
+		require.Equal(t, srvSID, honestSID)
+	})
+}
+
+func Test_HPKE_SequenceNumbers(t *testing.T) {
+	policy := session.Config{MaxAge: time.Hour, IdleTimeout: 10 * time.Minute, MaxMessages: 1000}
+	sequenced := policy
+	sequenced.SequenceNumbers = true
+
+	t.Run("client proposal enables them on both sessions", func(t *testing.T) {
+		cli, _, srvMgr, cliMgr, _, _, _, clientDID, serverDID := setupHPKETestWithTransport(t, policy, sequenced)
+
+		kid, err := cli.Initialize(context.Background(), "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.NoError(t, err)
+
+		cliSess, ok := cliMgr.GetByKeyID(kid)
+		require.True(t, ok)
+		srvSess, ok := srvMgr.GetByKeyID(kid)
+		require.True(t, ok)
+		require.True(t, cliSess.GetConfig().SequenceNumbers)
+		require.True(t, srvSess.GetConfig().SequenceNumbers)
+
+		ct, err := cliSess.Encrypt([]byte("hello"))
+		require.NoError(t, err)
+		pt, err := srvSess.Decrypt(ct)
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(pt))
+		_, err = srvSess.Decrypt(ct)
+		require.ErrorIs(t, err, session.ErrDuplicateSequence)
+	})
+
+	t.Run("server default alone does not enable them", func(t *testing.T) {
+		cli, _, srvMgr, cliMgr, _, _, _, clientDID, serverDID := setupHPKETestWithTransport(t, sequenced, policy)
+
+		kid, err := cli.Initialize(context.Background(), "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.NoError(t, err)
+
+		cliSess, ok := cliMgr.GetByKeyID(kid)
+		require.True(t, ok)
+		srvSess, ok := srvMgr.GetByKeyID(kid)
+		require.True(t, ok)
+		require.False(t, srvSess.GetConfig().SequenceNumbers)
+
+		ct, err := cliSess.Encrypt([]byte("hello"))
+		require.NoError(t, err)
+		_, err = srvSess.Decrypt(ct)
+		require.NoError(t, err)
+	})
+
+	t.Run("session keys commit to the choice", func(t *testing.T) {
+		mgr := session.NewManager()
+		t.Cleanup(func() { _ = mgr.Close() })
+		combined := make([]byte, 32)
+		_, err := rand.Read(combined)
+		require.NoError(t, err)
+
+		plain, err := ensureLifetimeSession(mgr, combined, false, nil, false)
+		require.NoError(t, err)
+		seq, err := ensureLifetimeSession(mgr, combined, false, nil, true)
+		require.NoError(t, err)
+		require.NotEqual(t, plain, seq)
+	})
+}
@@ -0,0 +1,241 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+)
+
+// Mode selects how a Client protects application messages.
+//
+// Trade-offs:
+//   - ModeSessionKey runs one interactive handshake (HPKE Base + ephemeral
+//     X25519 DH) and then reuses the derived session keys for every message.
+//     It gives forward secrecy and cheap per-message AEAD, but both sides must
+//     keep session state and the server must be online for the handshake.
+//   - ModeSingleShot encapsulates to the peer's static KEM key for every
+//     message. It is stateless and needs no round trip, which suits one-off
+//     messages, but each message costs a KEM operation, there is no session to
+//     reply on, and compromise of the peer's KEM key exposes past messages.
+type Mode int
+
+const (
+	// ModeSessionKey derives a session with Initialize and reuses it (default).
+	ModeSessionKey Mode = iota
+	// ModeSingleShot performs a fresh HPKE encapsulation per message.
+	ModeSingleShot
+)
+
+// TaskHPKESingleShot identifies a single-shot HPKE message.
+const TaskHPKESingleShot = "hpke/single-shot@v1"
+
+// ErrWrongMode is returned when an operation is not valid in the client's Mode.
+var ErrWrongMode = errors.New("hpke: operation not supported in this mode")
+
+// SingleShotHandler receives the decrypted plaintext of a single-shot message.
+type SingleShotHandler func(ctx context.Context, senderDID string, plaintext []byte) error
+
+// String returns the mode name.
+func (m Mode) String() string {
+	switch m {
+	case ModeSessionKey:
+		return "session-key"
+	case ModeSingleShot:
+		return "single-shot"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// WithMode sets how the client protects messages. See Mode for trade-offs.
+func (c *Client) WithMode(m Mode) *Client {
+	c.mode = m
+	return c
+}
+
+// Mode returns the client's message protection mode.
+func (c *Client) Mode() Mode {
+	return c.mode
+}
+
+// SealSingleShot encrypts plaintext to peerDID's KEM key with a fresh HPKE
+// encapsulation and returns a signed message ready for transport. No session
+// is created or reused.
+func (c *Client) SealSingleShot(ctx context.Context, ctxID, initDID, peerDID string, plaintext []byte) (*transport.SecureMessage, error) {
+	if c.mode != ModeSingleShot {
+		return nil, fmt.Errorf("%w: SealSingleShot requires %s, client is %s", ErrWrongMode, ModeSingleShot, c.mode)
+	}
+
+	if err := c.checkServerPinEarly(ctx, peerDID); err != nil {
+		return nil, err
+	}
+	_, peerKEM, err := c.resolvePeerKEM(ctx, peerDID)
+	if err != nil {
+		return nil, err
+	}
+
+	info := c.info.BuildInfo(ctxID, initDID, peerDID)
+	packet, _, err := keys.HPKESealAndExportToPeer(peerKEM, plaintext, info, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("hpke seal: %w", err)
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"initDid": initDID,
+		"respDid": peerDID,
+		"nonce":   uuid.NewString(),
+		"ts":      time.Now().UTC().Format(time.RFC3339Nano),
+		"packet":  session.Encoding.EncodeToString(packet),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	signature, err := signHandshake(c.key, payload)
+	if err != nil {
+		return nil, fmt.Errorf("sign: %w", err)
+	}
+
+	return &transport.SecureMessage{
+		ID:        uuid.NewString(),
+		ContextID: ctxID,
+		TaskID:    TaskHPKESingleShot,
+		Payload:   payload,
+		DID:       c.DID,
+		Signature: signature,
+		Role:      "user",
+		Metadata:  make(map[string]string),
+	}, nil
+}
+
+// SendSingleShot seals plaintext with SealSingleShot and sends it.
+func (c *Client) SendSingleShot(ctx context.Context, ctxID, initDID, peerDID string, plaintext []byte) error {
+	if err := c.checkServerEndpoint(ctx, peerDID); err != nil {
+		return err
+	}
+	msg, err := c.SealSingleShot(ctx, ctxID, initDID, peerDID, plaintext)
+	if err != nil {
+		return err
+	}
+	_, err = c.sendAndGetResponse(ctx, msg)
+	return err
+}
+
+// singleShotPayload is the signed body of a single-shot message.
+type singleShotPayload struct {
+	InitDID   string
+	RespDID   string
+	Nonce     string
+	Timestamp time.Time
+	Packet    []byte // enc || ciphertext
+}
+
+func parseSingleShotPayload(data []byte) (singleShotPayload, error) {
+	var out singleShotPayload
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		return out, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	var err error
+	if out.InitDID, err = getString(m, "initDid"); err != nil {
+		return out, err
+	}
+	if out.RespDID, err = getString(m, "respDid"); err != nil {
+		return out, err
+	}
+	if out.Nonce, err = getString(m, "nonce"); err != nil {
+		return out, err
+	}
+	tsStr, err := getString(m, "ts")
+	if err != nil {
+		return out, err
+	}
+	if out.Timestamp, err = time.Parse(time.RFC3339Nano, tsStr); err != nil {
+		return out, fmt.Errorf("bad ts: %w", err)
+	}
+	if out.Packet, err = getBase64(m, "packet"); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// handleSingleShot verifies, decrypts and dispatches a single-shot message.
+func (s *Server) handleSingleShot(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
+	if s.singleShot == nil {
+		return nil, fmt.Errorf("single-shot messages not enabled")
+	}
+
+	senderDID, _, err := s.verifySender(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	pl, err := parseSingleShotPayload(msg.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("parse payload: %w", err)
+	}
+	if pl.InitDID != senderDID {
+		return nil, fmt.Errorf("authentication failed")
+	}
+	if pl.RespDID != s.DID {
+		return nil, fmt.Errorf("recipient mismatch")
+	}
+	now := time.Now()
+	if pl.Timestamp.Before(now.Add(-s.maxSkew)) || pl.Timestamp.After(now.Add(s.maxSkew)) {
+		return nil, fmt.Errorf("ts out of window")
+	}
+	if !s.nonces.checkAndMark(msg.ContextID + "|" + pl.Nonce) {
+		return nil, fmt.Errorf("replay detected")
+	}
+	if len(s.kems) == 0 {
+		return nil, fmt.Errorf("server KEM private key not configured")
+	}
+
+	// The packet does not name its scheme; try each KEM key (one per scheme)
+	info := s.info.BuildInfo(msg.ContextID, pl.InitDID, pl.RespDID)
+	var plaintext []byte
+	for _, kp := range s.kems {
+		plaintext, _, err = keys.HPKEOpenAndExportWithPriv(kp.PrivateKey(), pl.Packet, info, nil, 0)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("hpke open: %w", err)
+	}
+
+	if err := s.singleShot(ctx, senderDID, plaintext); err != nil {
+		return nil, err
+	}
+	return &transport.Response{
+		Success:   true,
+		MessageID: msg.ID,
+		TaskID:    msg.TaskID,
+	}, nil
+}
@@ -0,0 +1,133 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HPKE_Mode_SessionKey(t *testing.T) {
+	ctx := context.Background()
+	cli, _, srvMgr, cliMgr, _, _, clientDID, serverDID := setupHPKETest(t, session.Config{}, session.Config{})
+	require.Equal(t, ModeSessionKey, cli.Mode())
+
+	// Single-shot sealing is rejected in session-key mode
+	_, err := cli.SealSingleShot(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID, []byte("hi"))
+	require.ErrorIs(t, err, ErrWrongMode)
+
+	// One handshake, then the same session keys protect every message
+	kid, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+	require.NoError(t, err)
+	require.Equal(t, 1, cliMgr.GetSessionCount())
+	require.Equal(t, 1, srvMgr.GetSessionCount())
+
+	sCli, ok := cliMgr.GetByKeyID(kid)
+	require.True(t, ok)
+	sSrv, ok := srvMgr.GetByKeyID(kid)
+	require.True(t, ok)
+	for _, m := range []string{"first", "second"} {
+		ct, err := sCli.Encrypt([]byte(m))
+		require.NoError(t, err)
+		pt, err := sSrv.Decrypt(ct)
+		require.NoError(t, err)
+		require.Equal(t, m, string(pt))
+	}
+}
+
+func Test_HPKE_Mode_SingleShot(t *testing.T) {
+	ctx := context.Background()
+	cli, srv, srvMgr, cliMgr, _, _, clientDID, serverDID := setupHPKETest(t, session.Config{}, session.Config{})
+
+	type delivery struct {
+		sender    string
+		plaintext []byte
+	}
+	var got []delivery
+	srv.singleShot = func(_ context.Context, senderDID string, plaintext []byte) error {
+		got = append(got, delivery{senderDID, plaintext})
+		return nil
+	}
+
+	cli.WithMode(ModeSingleShot)
+
+	// Initialize is not available in single-shot mode
+	_, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+	require.ErrorIs(t, err, ErrWrongMode)
+
+	ctxID := "ctx-" + uuid.NewString()
+	msg1, err := cli.SealSingleShot(ctx, ctxID, clientDID, serverDID, []byte("card-to-card"))
+	require.NoError(t, err)
+	msg2, err := cli.SealSingleShot(ctx, ctxID, clientDID, serverDID, []byte("card-to-card"))
+	require.NoError(t, err)
+
+	// Every message carries a fresh encapsulation
+	p1, err := parseSingleShotPayload(msg1.Payload)
+	require.NoError(t, err)
+	p2, err := parseSingleShotPayload(msg2.Payload)
+	require.NoError(t, err)
+	require.False(t, bytes.Equal(p1.Packet[:32], p2.Packet[:32]), "enc must differ per message")
+	require.NotContains(t, string(msg1.Payload), "card-to-card")
+
+	for _, m := range []*transport.SecureMessage{msg1, msg2} {
+		_, err := srv.HandleMessage(ctx, m)
+		require.NoError(t, err)
+	}
+	require.NoError(t, cli.SendSingleShot(ctx, ctxID, clientDID, serverDID, []byte("via transport")))
+
+	require.Len(t, got, 3)
+	for _, d := range got {
+		require.Equal(t, clientDID, d.sender)
+	}
+	require.Equal(t, "card-to-card", string(got[0].plaintext))
+	require.Equal(t, "via transport", string(got[2].plaintext))
+
+	// Stateless: no sessions on either side
+	require.Zero(t, cliMgr.GetSessionCount())
+	require.Zero(t, srvMgr.GetSessionCount())
+
+	t.Run("replay rejected", func(t *testing.T) {
+		_, err := srv.HandleMessage(ctx, msg1)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "replay")
+	})
+
+	t.Run("context mismatch rejected", func(t *testing.T) {
+		msg, err := cli.SealSingleShot(ctx, ctxID, clientDID, serverDID, []byte("x"))
+		require.NoError(t, err)
+		// Opening under a different context ID changes the HPKE info binding
+		msg.ContextID = "ctx-other"
+		_, err = srv.HandleMessage(ctx, msg)
+		require.Error(t, err)
+	})
+
+	t.Run("disabled on server", func(t *testing.T) {
+		srv.singleShot = nil
+		msg, err := cli.SealSingleShot(ctx, ctxID, clientDID, serverDID, []byte("x"))
+		require.NoError(t, err)
+		_, err = srv.HandleMessage(ctx, msg)
+		require.Error(t, err)
+	})
+}
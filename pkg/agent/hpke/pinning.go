@@ -0,0 +1,148 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+)
+
+// ErrServerKeyPinMismatch is returned when the server's resolved signing key
+// does not match the fingerprint pinned for its DID.
+var ErrServerKeyPinMismatch = errors.New("hpke: server key does not match pin")
+
+// KeyFingerprint returns the hex SHA-256 of a public signing key in its
+// registry encoding. It is the value stored in a PinStore.
+func KeyFingerprint(pub interface{}) (string, error) {
+	if kp, ok := pub.(sagecrypto.KeyPair); ok {
+		pub = kp.PublicKey()
+	}
+	raw, err := did.MarshalPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("fingerprint: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// PinStore holds server key fingerprints by DID. Implementations must be
+// safe for concurrent use; a persistent store keeps TOFU pins across restarts.
+type PinStore interface {
+	// Pin returns the fingerprint pinned for serverDID, if any.
+	Pin(serverDID string) (fingerprint string, ok bool, err error)
+	// SetPin records the fingerprint for serverDID.
+	SetPin(serverDID, fingerprint string) error
+}
+
+// MemoryPinStore is an in-memory PinStore.
+type MemoryPinStore struct {
+	mu   sync.RWMutex
+	pins map[string]string
+}
+
+// NewMemoryPinStore creates an empty MemoryPinStore.
+func NewMemoryPinStore() *MemoryPinStore {
+	return &MemoryPinStore{pins: make(map[string]string)}
+}
+
+// Pin implements PinStore.
+func (m *MemoryPinStore) Pin(serverDID string) (string, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	fp, ok := m.pins[serverDID]
+	return fp, ok, nil
+}
+
+// SetPin implements PinStore.
+func (m *MemoryPinStore) SetPin(serverDID, fingerprint string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pins[serverDID] = fingerprint
+	return nil
+}
+
+// WithPinStore makes the client check every server it contacts against
+// store. With trustOnFirstUse, a server without a pin is accepted and its
+// fingerprint recorded once it has proven possession of the key by signing
+// the handshake response; otherwise unpinned servers are accepted unchecked.
+func (c *Client) WithPinStore(store PinStore, trustOnFirstUse bool) *Client {
+	c.pins = store
+	c.tofu = trustOnFirstUse
+	return c
+}
+
+// PinServerKey pre-pins the expected key fingerprint (see KeyFingerprint)
+// for serverDID. Initialize then fails with ErrServerKeyPinMismatch before
+// sending anything if the resolver returns a different key.
+func (c *Client) PinServerKey(serverDID, fingerprint string) error {
+	if c.pins == nil {
+		c.pins = NewMemoryPinStore()
+	}
+	return c.pins.SetPin(serverDID, strings.ToLower(fingerprint))
+}
+
+// checkServerPinEarly resolves the server's signing key and compares it with
+// an existing pin, so a substituted server is rejected before the init is sent.
+func (c *Client) checkServerPinEarly(ctx context.Context, serverDID string) error {
+	if c.pins == nil {
+		return nil
+	}
+	if _, ok, err := c.pins.Pin(serverDID); err != nil || !ok {
+		return err
+	}
+	pub, err := c.resolver.ResolvePublicKey(ctx, did.AgentDID(serverDID))
+	if err != nil || pub == nil {
+		return fmt.Errorf("cannot resolve server pubkey")
+	}
+	return c.checkServerPin(serverDID, pub, false)
+}
+
+// checkServerPin compares pub with the pin for serverDID. If there is no pin
+// and record is set under TOFU, pub's fingerprint is recorded.
+func (c *Client) checkServerPin(serverDID string, pub interface{}, record bool) error {
+	if c.pins == nil {
+		return nil
+	}
+	got, err := KeyFingerprint(pub)
+	if err != nil {
+		return err
+	}
+	want, ok, err := c.pins.Pin(serverDID)
+	if err != nil {
+		return fmt.Errorf("pin store: %w", err)
+	}
+	if !ok {
+		if record && c.tofu {
+			return c.pins.SetPin(serverDID, got)
+		}
+		return nil
+	}
+	if !sagecrypto.SecureCompare([]byte(want), []byte(got)) {
+		return fmt.Errorf("%w: %s", ErrServerKeyPinMismatch, serverDID)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	sagedid "github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_ServerKeyPinning(t *testing.T) {
+	ctx := context.Background()
+
+	// setup returns a client whose transport counts the messages it sends
+	setup := func(t *testing.T) (*Client, *sagedid.MultiChainResolver, *atomic.Int32, string, string) {
+		cli, srv, _, _, _, resolver, mt, clientDID, serverDID :=
+			setupHPKETestWithTransport(t, session.Config{}, session.Config{})
+		var sent atomic.Int32
+		mt.SendFunc = func(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
+			sent.Add(1)
+			return srv.HandleMessage(ctx, msg)
+		}
+		return cli, resolver, &sent, clientDID, serverDID
+	}
+	serverFingerprint := func(t *testing.T, resolver *sagedid.MultiChainResolver, serverDID string) string {
+		pub, err := resolver.ResolvePublicKey(ctx, sagedid.AgentDID(serverDID))
+		require.NoError(t, err)
+		fp, err := KeyFingerprint(pub)
+		require.NoError(t, err)
+		return fp
+	}
+
+	t.Run("pre-pinned key matches", func(t *testing.T) {
+		cli, resolver, _, clientDID, serverDID := setup(t)
+		require.NoError(t, cli.PinServerKey(serverDID, serverFingerprint(t, resolver, serverDID)))
+
+		_, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.NoError(t, err)
+	})
+
+	t.Run("mismatch is rejected before sending", func(t *testing.T) {
+		cli, resolver, sent, clientDID, serverDID := setup(t)
+		other, err := keys.GenerateEd25519KeyPair()
+		require.NoError(t, err)
+		otherFP, err := KeyFingerprint(other.PublicKey())
+		require.NoError(t, err)
+		require.NoError(t, cli.PinServerKey(serverDID, otherFP))
+
+		_, err = cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.ErrorIs(t, err, ErrServerKeyPinMismatch)
+		require.Zero(t, sent.Load())
+
+		// The server never saw the init, so consume its client lookup here
+		_, err = resolver.Resolve(ctx, sagedid.AgentDID(clientDID))
+		require.NoError(t, err)
+	})
+
+	t.Run("trust on first use records the key", func(t *testing.T) {
+		cli, resolver, sent, clientDID, serverDID := setup(t)
+		store := NewMemoryPinStore()
+		cli.WithPinStore(store, true)
+
+		_, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.NoError(t, err)
+		fp, ok, err := store.Pin(serverDID)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, serverFingerprint(t, resolver, serverDID), fp)
+
+		// The resolver now substitutes another key for the same DID
+		meta, err := resolver.Resolve(ctx, sagedid.AgentDID(serverDID))
+		require.NoError(t, err)
+		substitute, err := keys.GenerateEd25519KeyPair()
+		require.NoError(t, err)
+		meta.PublicKey = substitute
+
+		before := sent.Load()
+		_, err = cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.ErrorIs(t, err, ErrServerKeyPinMismatch)
+		require.Equal(t, before, sent.Load())
+	})
+
+	t.Run("without TOFU unknown servers are not recorded", func(t *testing.T) {
+		cli, _, _, clientDID, serverDID := setup(t)
+		store := NewMemoryPinStore()
+		cli.WithPinStore(store, false)
+
+		_, err := cli.Initialize(ctx, "ctx-"+uuid.NewString(), clientDID, serverDID)
+		require.NoError(t, err)
+		_, ok, err := store.Pin(serverDID)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+}
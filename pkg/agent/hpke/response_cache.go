@@ -0,0 +1,133 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"crypto/sha256"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultResponseCacheSize bounds a ResponseCache created with a
+// non-positive size.
+const DefaultResponseCacheSize = 1000
+
+// ResponseCache remembers the reply plaintexts of a SessionReplyHandler by
+// the hash of the request plaintext, so a hot, deterministic tool answering
+// the same request from many agents computes the reply once. Only the
+// plaintext is shared: every reply is still encrypted with the caller's own
+// session, under a fresh nonce, on every request.
+//
+// Enable it only for handlers whose reply depends on the request bytes
+// alone. A reply that varies with the caller (the kid passed to the
+// handler, its DID or its permissions) or with time beyond the TTL would
+// be served to the wrong agent. A ResponseCache is safe for concurrent use.
+type ResponseCache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]cachedReply
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type cachedReply struct {
+	plaintext []byte
+	expires   time.Time
+}
+
+// NewResponseCache returns a cache whose entries live for ttl and which
+// holds at most maxEntries replies; non-positive means
+// DefaultResponseCacheSize.
+func NewResponseCache(ttl time.Duration, maxEntries int) *ResponseCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultResponseCacheSize
+	}
+	return &ResponseCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[[sha256.Size]byte]cachedReply),
+	}
+}
+
+// Stats returns how many replies were served from the cache and how many
+// had to be computed by the handler.
+func (c *ResponseCache) Stats() (hits, misses uint64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+// Len returns the number of cached replies, including expired ones not yet
+// evicted.
+func (c *ResponseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// lookup returns the cached reply to request, if it has not expired.
+func (c *ResponseCache) lookup(request []byte) ([]byte, bool) {
+	key := sha256.Sum256(request)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if ok {
+		c.hits.Add(1)
+		return entry.plaintext, true
+	}
+	c.misses.Add(1)
+	return nil, false
+}
+
+// store records reply as the answer to request. When the cache is full,
+// expired entries are evicted; if it is still full the reply is not cached.
+func (c *ResponseCache) store(request, reply []byte) {
+	if c.ttl <= 0 {
+		return
+	}
+	key := sha256.Sum256(request)
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.maxEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			return
+		}
+	}
+	c.entries[key] = cachedReply{
+		plaintext: append([]byte(nil), reply...),
+		expires:   now.Add(c.ttl),
+	}
+}
@@ -0,0 +1,162 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+func Test_HPKE_ResponseCache(t *testing.T) {
+	ctx := context.Background()
+	cli, srv, _, _, _, _, mt, _, serverDID := setupHPKETestWithTransport(t, session.Config{}, session.Config{})
+
+	var computed atomic.Int32
+	srv.sessionReply = func(_ context.Context, _ string, plaintext []byte) ([]byte, error) {
+		computed.Add(1)
+		if string(plaintext) == "fail" {
+			return nil, errors.New("tool failed")
+		}
+		return append([]byte("result:"), plaintext...), nil
+	}
+	cache := NewResponseCache(time.Minute, 0)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	srv.responseCache = cache
+
+	// Record the encrypted replies on the wire
+	var wire [][]byte
+	next := mt.SendFunc
+	mt.SendFunc = func(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
+		resp, err := next(ctx, msg)
+		if err == nil && msg.TaskID == TaskSessionMessage {
+			wire = append(wire, replyCiphertext(t, resp.Data))
+		}
+		return resp, err
+	}
+
+	// Two clients establish two independent sessions with the server
+	sc1 := NewSecureClient(cli, serverDID)
+	sc2 := NewSecureClient(cli, serverDID)
+
+	t.Run("Identical requests share one computation", func(t *testing.T) {
+		r1, err := sc1.Call(ctx, []byte("weather?"))
+		require.NoError(t, err)
+		r2, err := sc2.Call(ctx, []byte("weather?"))
+		require.NoError(t, err)
+		r3, err := sc1.Call(ctx, []byte("weather?"))
+		require.NoError(t, err)
+
+		require.NotEqual(t, sc1.KeyID(), sc2.KeyID())
+		require.Equal(t, "result:weather?", string(r1))
+		require.Equal(t, r1, r2)
+		require.Equal(t, r1, r3)
+		require.Equal(t, int32(1), computed.Load())
+		hits, misses := cache.Stats()
+		require.Equal(t, uint64(2), hits)
+		require.Equal(t, uint64(1), misses)
+	})
+
+	t.Run("Every reply is encrypted afresh", func(t *testing.T) {
+		require.Len(t, wire, 3)
+		require.False(t, bytes.Equal(wire[0], wire[1]), "different sessions")
+		require.False(t, bytes.Equal(wire[0], wire[2]), "same session, new nonce")
+		nonces := map[string]bool{}
+		for _, ct := range wire {
+			nonce := string(ct[session.FrameHeaderSize : session.FrameHeaderSize+chacha20poly1305.NonceSize])
+			require.False(t, nonces[nonce], "nonce reused")
+			nonces[nonce] = true
+		}
+	})
+
+	t.Run("Different requests are computed", func(t *testing.T) {
+		reply, err := sc2.Call(ctx, []byte("time?"))
+		require.NoError(t, err)
+		require.Equal(t, "result:time?", string(reply))
+		require.Equal(t, int32(2), computed.Load())
+	})
+
+	t.Run("Errors are not cached", func(t *testing.T) {
+		before := computed.Load()
+		_, err := sc1.Call(ctx, []byte("fail"))
+		require.Error(t, err)
+		_, err = sc1.Call(ctx, []byte("fail"))
+		require.Error(t, err)
+		require.Equal(t, before+2, computed.Load())
+	})
+
+	t.Run("Entries expire", func(t *testing.T) {
+		before := computed.Load()
+		now = now.Add(2 * time.Minute)
+		_, err := sc1.Call(ctx, []byte("weather?"))
+		require.NoError(t, err)
+		require.Equal(t, before+1, computed.Load())
+	})
+}
+
+// BenchmarkSessionReply_RepeatedRequest measures a server answering the
+// same request from several sessions with a reply that is costly to
+// compute, with and without a ResponseCache.
+func BenchmarkSessionReply_RepeatedRequest(b *testing.B) {
+	for _, cached := range []bool{false, true} {
+		b.Run(fmt.Sprintf("cached=%v", cached), func(b *testing.B) {
+			ctx := context.Background()
+			cli, srv, _, _, _, _, _, _, serverDID := setupHPKETestWithTransport(b, session.Config{}, session.Config{})
+			srv.sessionReply = func(_ context.Context, _ string, plaintext []byte) ([]byte, error) {
+				// Stand-in for a deterministic tool doing real work
+				sum := sha256.Sum256(plaintext)
+				for i := 0; i < 2000; i++ {
+					sum = sha256.Sum256(sum[:])
+				}
+				return sum[:], nil
+			}
+			if cached {
+				srv.responseCache = NewResponseCache(time.Minute, 0)
+			}
+
+			clients := make([]*SecureClient, 4)
+			for i := range clients {
+				clients[i] = NewSecureClient(cli, serverDID)
+				if _, err := clients[i].Establish(ctx); err != nil {
+					b.Fatal(err)
+				}
+			}
+			request := []byte(`{"tool":"lookup","args":{"symbol":"SAGE"}}`)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := clients[i%len(clients)].Call(ctx, request); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
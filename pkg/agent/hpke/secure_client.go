@@ -0,0 +1,388 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+	"golang.org/x/sync/singleflight"
+)
+
+// TaskSessionMessage identifies an application message encrypted with an
+// established session. The session is named by Metadata["kid"].
+const TaskSessionMessage = "hpke/session-message@v1"
+
+// Reasons the server gives when it cannot accept a session message. A client
+// seeing SessionReasonNotEstablished must complete a handshake first; the
+// other reasons mean a session existed and has to be replaced.
+const (
+	SessionReasonNotEstablished = "session_not_established"
+	SessionReasonExpired        = "session_expired"
+	SessionReasonExhausted      = "session_exhausted"
+	SessionReasonRevoked        = "session_revoked"
+)
+
+// SessionRejectReason maps a session.Manager lookup error to the reason
+// reported for it, or "" if err is not a lookup failure.
+func SessionRejectReason(err error) string {
+	switch {
+	case errors.Is(err, session.ErrSessionNotFound):
+		return SessionReasonNotEstablished
+	case errors.Is(err, session.ErrSessionExpired):
+		return SessionReasonExpired
+	case errors.Is(err, session.ErrSessionExhausted):
+		return SessionReasonExhausted
+	case errors.Is(err, session.ErrSessionRevoked):
+		return SessionReasonRevoked
+	default:
+		return ""
+	}
+}
+
+// ErrReplySignature is returned by SecureClient.Call when a reply is not
+// signed by the peer's DID key or is not bound to the request it answers:
+// the reply was tampered with or swapped in transit. It is checked before
+// decryption, so a "decrypt reply" error instead means the server itself
+// sent a ciphertext the session cannot open.
+var ErrReplySignature = errors.New("hpke: session reply signature verification failed")
+
+// sessionReplyEnvelope is the signed part of a session reply. It binds the
+// ciphertext to the session, the request it answers and the server DID.
+type sessionReplyEnvelope struct {
+	V         string `json:"v"`
+	Task      string `json:"task"`
+	Kid       string `json:"kid"`
+	ReqID     string `json:"reqId"`
+	Did       string `json:"did"`
+	CipherB64 string `json:"cipherB64"` // b64url(session ciphertext)
+}
+
+// signedSessionReply is the Response.Data of a session reply: the envelope
+// fields plus a detached signature over the marshalled envelope.
+type signedSessionReply struct {
+	sessionReplyEnvelope
+	SigB64 string `json:"sigB64"`
+}
+
+// SessionMessageHandler receives the decrypted plaintext of a session message.
+type SessionMessageHandler func(ctx context.Context, kid string, plaintext []byte) error
+
+// SessionReplyHandler receives the decrypted plaintext of a session message
+// and returns the reply plaintext, which the server encrypts with the same
+// session, signs with its DID key and returns in Response.Data (see
+// SecureClient.Call).
+type SessionReplyHandler func(ctx context.Context, kid string, plaintext []byte) ([]byte, error)
+
+// SecureClient sends session-protected messages to a single peer and performs
+// the handshake lazily: the first Send runs Initialize, later sends reuse the
+// session, and a new handshake runs once the session expires or is dropped.
+// Concurrent sends that find no session share a single handshake.
+type SecureClient struct {
+	client  *Client
+	peerDID string
+
+	mu    sync.Mutex
+	kid   string
+	ctxID string
+	sf    singleflight.Group
+}
+
+// NewSecureClient wraps client for messages to peerDID. The client must be in
+// ModeSessionKey.
+func NewSecureClient(client *Client, peerDID string) *SecureClient {
+	return &SecureClient{client: client, peerDID: peerDID}
+}
+
+// KeyID returns the key ID of the current session, or "" before the first
+// handshake.
+func (sc *SecureClient) KeyID() string {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.kid
+}
+
+// Reset drops the current session so the next Send performs a new handshake.
+func (sc *SecureClient) Reset() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.kid = ""
+	sc.ctxID = ""
+}
+
+// Establish returns the live session, performing the handshake if there is
+// none. Concurrent callers wait for the same handshake and share its result,
+// including its error; the handshake runs with the first caller's context.
+func (sc *SecureClient) Establish(ctx context.Context) (kid string, err error) {
+	if kid, _, ok := sc.current(); ok {
+		return kid, nil
+	}
+
+	v, err, _ := sc.sf.Do("handshake", func() (any, error) {
+		// A handshake may have completed since the check above
+		if kid, _, ok := sc.current(); ok {
+			return kid, nil
+		}
+
+		ctxID := "ctx-" + uuid.NewString()
+		kid, err := sc.client.Initialize(ctx, ctxID, sc.client.DID, sc.peerDID)
+		if err != nil {
+			return "", err
+		}
+
+		sc.mu.Lock()
+		sc.kid = kid
+		sc.ctxID = ctxID
+		sc.mu.Unlock()
+		return kid, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("handshake: %w", err)
+	}
+	return v.(string), nil
+}
+
+// Send encrypts plaintext with the peer session, establishing it first if
+// needed, and delivers it as a TaskSessionMessage.
+func (sc *SecureClient) Send(ctx context.Context, plaintext []byte) (*transport.Response, error) {
+	resp, _, _, err := sc.send(ctx, plaintext)
+	return resp, err
+}
+
+// Call is Send for a peer serving a SessionReplyHandler: it verifies the
+// peer's signature over the reply and returns the decrypted reply. A reply
+// that fails verification yields ErrReplySignature.
+func (sc *SecureClient) Call(ctx context.Context, plaintext []byte) ([]byte, error) {
+	resp, sess, msg, err := sc.send(ctx, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 {
+		return nil, errors.New("peer sent no reply")
+	}
+	ct, err := sc.client.verifySessionReply(ctx, sc.peerDID, msg, resp.Data)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := sess.Decrypt(ct)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt reply: %w", err)
+	}
+	return reply, nil
+}
+
+func (sc *SecureClient) send(ctx context.Context, plaintext []byte) (*transport.Response, session.Session, *transport.SecureMessage, error) {
+	if _, err := sc.Establish(ctx); err != nil {
+		return nil, nil, nil, err
+	}
+
+	kid, ctxID, ok := sc.current()
+	if !ok {
+		return nil, nil, nil, errors.New("session expired during send")
+	}
+	sess, ok := sc.client.sessMgr.GetByKeyID(kid)
+	if !ok {
+		return nil, nil, nil, errors.New("session expired during send")
+	}
+
+	ct, err := sess.Encrypt(plaintext)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("encrypt: %w", err)
+	}
+
+	msg := &transport.SecureMessage{
+		ID:        uuid.NewString(),
+		ContextID: ctxID,
+		TaskID:    TaskSessionMessage,
+		Payload:   ct,
+		DID:       sc.client.DID,
+		Role:      "user",
+		Metadata:  map[string]string{"kid": kid},
+	}
+	resp, err := sc.client.sendAndGetResponse(ctx, msg)
+	return resp, sess, msg, err
+}
+
+// verifySessionReply checks the server's signature over a session reply to
+// req and returns the reply ciphertext. Every failure wraps ErrReplySignature.
+func (c *Client) verifySessionReply(ctx context.Context, serverDID string, req *transport.SecureMessage, data []byte) ([]byte, error) {
+	var r signedSessionReply
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%w: malformed reply: %v", ErrReplySignature, err)
+	}
+	env := r.sessionReplyEnvelope
+	if env.V != "v1" || env.Task != TaskSessionMessage {
+		return nil, fmt.Errorf("%w: unsupported version/task: %s/%s", ErrReplySignature, env.V, env.Task)
+	}
+	if env.Kid != req.Metadata["kid"] || env.ReqID != req.ID || env.Did != serverDID {
+		return nil, fmt.Errorf("%w: reply not bound to this request", ErrReplySignature)
+	}
+	sig, err := session.Encoding.DecodeString(r.SigB64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad sigB64", ErrReplySignature)
+	}
+	ct, err := session.Encoding.DecodeString(env.CipherB64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad cipherB64", ErrReplySignature)
+	}
+
+	if c.resolver == nil {
+		return nil, fmt.Errorf("nil resolver")
+	}
+	pub, err := c.resolver.ResolvePublicKey(ctx, did.AgentDID(serverDID))
+	if err != nil || pub == nil {
+		return nil, fmt.Errorf("cannot resolve server pubkey")
+	}
+	if err := c.checkServerPin(serverDID, pub, false); err != nil {
+		return nil, err
+	}
+
+	envBytes, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("marshal reply env (client): %w", err)
+	}
+	if err := verifySignature(envBytes, sig, pub); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrReplySignature, err)
+	}
+	return ct, nil
+}
+
+// current returns the session key ID and context if the session is still
+// alive, clearing them otherwise.
+func (sc *SecureClient) current() (kid, ctxID string, ok bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.kid == "" {
+		return "", "", false
+	}
+	if sess, ok := sc.client.sessMgr.GetByKeyID(sc.kid); ok && !sess.IsExpired() {
+		return sc.kid, sc.ctxID, true
+	}
+	sc.kid = ""
+	sc.ctxID = ""
+	return "", "", false
+}
+
+// handleSessionMessage decrypts a session message and dispatches it.
+func (s *Server) handleSessionMessage(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
+	if s.sessionMessage == nil && s.sessionReply == nil {
+		return nil, fmt.Errorf("session messages not enabled")
+	}
+
+	kid := msg.Metadata["kid"]
+	if kid == "" {
+		return nil, fmt.Errorf("missing kid")
+	}
+	sess, err := s.sessMgr.LookupByKeyID(kid)
+	if err != nil {
+		if reason := SessionRejectReason(err); reason != "" {
+			return nil, fmt.Errorf("%s: %w", reason, err)
+		}
+		return nil, fmt.Errorf("lookup session: %w", err)
+	}
+
+	plaintext, err := sess.Decrypt(msg.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt: %w", err)
+	}
+
+	if s.sessionReply == nil {
+		if err := s.sessionMessage(ctx, kid, plaintext); err != nil {
+			return nil, err
+		}
+		return &transport.Response{
+			Success:   true,
+			MessageID: msg.ID,
+			TaskID:    msg.TaskID,
+		}, nil
+	}
+
+	reply, err := s.replyTo(ctx, kid, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	// Always encrypted afresh: a cached reply is shared as plaintext only
+	ct, err := sess.Encrypt(reply)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt reply: %w", err)
+	}
+	data, err := s.signSessionReply(msg, kid, ct)
+	if err != nil {
+		return nil, err
+	}
+	return &transport.Response{
+		Success:   true,
+		MessageID: msg.ID,
+		TaskID:    msg.TaskID,
+		Data:      data,
+	}, nil
+}
+
+// signSessionReply wraps a reply ciphertext for req in a sessionReplyEnvelope
+// signed with the server's DID key.
+func (s *Server) signSessionReply(req *transport.SecureMessage, kid string, ct []byte) ([]byte, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("server signing key not configured")
+	}
+	env := sessionReplyEnvelope{
+		V:         "v1",
+		Task:      TaskSessionMessage,
+		Kid:       kid,
+		ReqID:     req.ID,
+		Did:       s.DID,
+		CipherB64: session.Encoding.EncodeToString(ct),
+	}
+	envBytes, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("marshal reply env: %w", err)
+	}
+	sig, err := signHandshake(s.key, envBytes)
+	if err != nil {
+		return nil, fmt.Errorf("sign reply env: %w", err)
+	}
+	data, err := json.Marshal(signedSessionReply{sessionReplyEnvelope: env, SigB64: session.Encoding.EncodeToString(sig)})
+	if err != nil {
+		return nil, fmt.Errorf("marshal reply: %w", err)
+	}
+	return data, nil
+}
+
+// replyTo runs the reply handler, consulting the response cache if one is
+// configured.
+func (s *Server) replyTo(ctx context.Context, kid string, plaintext []byte) ([]byte, error) {
+	if s.responseCache == nil {
+		return s.sessionReply(ctx, kid, plaintext)
+	}
+	if reply, ok := s.responseCache.lookup(plaintext); ok {
+		return reply, nil
+	}
+	reply, err := s.sessionReply(ctx, kid, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	s.responseCache.store(plaintext, reply)
+	return reply, nil
+}
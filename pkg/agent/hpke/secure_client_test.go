@@ -0,0 +1,256 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package hpke
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HPKE_SecureClient_LazyHandshake(t *testing.T) {
+	ctx := context.Background()
+	cli, srv, srvMgr, _, _, _, mt, _, serverDID := setupHPKETestWithTransport(t, session.Config{}, session.Config{})
+
+	var mu sync.Mutex
+	var received []string
+	srv.sessionMessage = func(_ context.Context, kid string, plaintext []byte) error {
+		mu.Lock()
+		received = append(received, string(plaintext))
+		mu.Unlock()
+		return nil
+	}
+
+	// Count handshakes on the way to the server
+	var handshakes atomic.Int32
+	next := mt.SendFunc
+	mt.SendFunc = func(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
+		if msg.TaskID == TaskHPKEComplete {
+			handshakes.Add(1)
+		}
+		return next(ctx, msg)
+	}
+
+	sc := NewSecureClient(cli, serverDID)
+	require.Empty(t, sc.KeyID())
+
+	t.Run("Concurrent first sends share one handshake", func(t *testing.T) {
+		const n = 10
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		errs := make([]error, n)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				<-start
+				_, errs[i] = sc.Send(ctx, []byte(fmt.Sprintf("msg-%d", i)))
+			}(i)
+		}
+		close(start)
+		wg.Wait()
+
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+		require.Equal(t, int32(1), handshakes.Load())
+		require.Equal(t, 1, srvMgr.GetSessionCount())
+
+		mu.Lock()
+		got := append([]string(nil), received...)
+		mu.Unlock()
+		sort.Strings(got)
+		want := make([]string, n)
+		for i := range want {
+			want[i] = fmt.Sprintf("msg-%d", i)
+		}
+		sort.Strings(want)
+		require.Equal(t, want, got)
+	})
+
+	t.Run("Later sends reuse the session", func(t *testing.T) {
+		kid := sc.KeyID()
+		require.NotEmpty(t, kid)
+		_, err := sc.Send(ctx, []byte("again"))
+		require.NoError(t, err)
+		require.Equal(t, int32(1), handshakes.Load())
+		require.Equal(t, kid, sc.KeyID())
+	})
+
+	t.Run("Reset triggers a new handshake", func(t *testing.T) {
+		kid := sc.KeyID()
+		sc.Reset()
+		_, err := sc.Send(ctx, []byte("after reset"))
+		require.NoError(t, err)
+		require.Equal(t, int32(2), handshakes.Load())
+		require.NotEqual(t, kid, sc.KeyID())
+	})
+
+	t.Run("Server rejects unknown sessions", func(t *testing.T) {
+		_, err := srv.HandleMessage(ctx, &transport.SecureMessage{
+			TaskID:   TaskSessionMessage,
+			Payload:  []byte("ciphertext"),
+			Metadata: map[string]string{"kid": "kid-unknown"},
+		})
+		require.Error(t, err)
+	})
+}
+
+// replyCiphertext extracts the session ciphertext from a signed session reply.
+func replyCiphertext(t testing.TB, data []byte) []byte {
+	t.Helper()
+	var r signedSessionReply
+	require.NoError(t, json.Unmarshal(data, &r))
+	ct, err := session.Encoding.DecodeString(r.CipherB64)
+	require.NoError(t, err)
+	return ct
+}
+
+func Test_HPKE_SecureClient_ReplySignature(t *testing.T) {
+	ctx := context.Background()
+	cli, srv, _, _, _, _, mt, _, serverDID := setupHPKETestWithTransport(t, session.Config{}, session.Config{})
+	srv.sessionReply = func(_ context.Context, _ string, plaintext []byte) ([]byte, error) {
+		return append([]byte("re:"), plaintext...), nil
+	}
+
+	// A man in the middle rewrites session replies on the way back
+	var tamper func(req *transport.SecureMessage, data []byte) []byte
+	var last []byte
+	next := mt.SendFunc
+	mt.SendFunc = func(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
+		resp, err := next(ctx, msg)
+		if err != nil || msg.TaskID != TaskSessionMessage {
+			return resp, err
+		}
+		if tamper != nil {
+			resp.Data = tamper(msg, resp.Data)
+		}
+		last = resp.Data
+		return resp, nil
+	}
+
+	sc1 := NewSecureClient(cli, serverDID)
+	sc2 := NewSecureClient(cli, serverDID)
+
+	reply, err := sc1.Call(ctx, []byte("ping"))
+	require.NoError(t, err)
+	require.Equal(t, "re:ping", string(reply))
+
+	// A genuine reply to another session and request
+	_, err = sc2.Call(ctx, []byte("other"))
+	require.NoError(t, err)
+	otherReply := last
+
+	t.Run("Swapped ciphertext", func(t *testing.T) {
+		tamper = func(_ *transport.SecureMessage, data []byte) []byte {
+			var r signedSessionReply
+			require.NoError(t, json.Unmarshal(data, &r))
+			r.CipherB64 = session.Encoding.EncodeToString(replyCiphertext(t, otherReply))
+			out, err := json.Marshal(r)
+			require.NoError(t, err)
+			return out
+		}
+		_, err := sc1.Call(ctx, []byte("ping"))
+		require.ErrorIs(t, err, ErrReplySignature)
+	})
+
+	t.Run("Swapped signed reply", func(t *testing.T) {
+		tamper = func(*transport.SecureMessage, []byte) []byte { return otherReply }
+		_, err := sc1.Call(ctx, []byte("ping"))
+		require.ErrorIs(t, err, ErrReplySignature)
+	})
+
+	t.Run("Unsigned ciphertext", func(t *testing.T) {
+		tamper = func(_ *transport.SecureMessage, data []byte) []byte { return replyCiphertext(t, data) }
+		_, err := sc1.Call(ctx, []byte("ping"))
+		require.ErrorIs(t, err, ErrReplySignature)
+	})
+
+	t.Run("Genuine decrypt failure is distinct", func(t *testing.T) {
+		// Correctly signed by the server, but not a ciphertext of the session
+		tamper = func(req *transport.SecureMessage, _ []byte) []byte {
+			data, err := srv.signSessionReply(req, req.Metadata["kid"], []byte("not a session ciphertext"))
+			require.NoError(t, err)
+			return data
+		}
+		_, err := sc1.Call(ctx, []byte("ping"))
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrReplySignature)
+		require.Contains(t, err.Error(), "decrypt reply")
+	})
+
+	t.Run("Untampered replies still verify", func(t *testing.T) {
+		tamper = nil
+		reply, err := sc1.Call(ctx, []byte("pong"))
+		require.NoError(t, err)
+		require.Equal(t, "re:pong", string(reply))
+	})
+}
+
+func Test_HPKE_Server_SessionRejectReasons(t *testing.T) {
+	ctx := context.Background()
+	srvMgr := session.NewManager()
+	t.Cleanup(func() { _ = srvMgr.Close() })
+	srv := NewServer(nil, srvMgr, "did:sage:ethereum:0xserver", nil, &ServerOpts{
+		SessionMessage: func(context.Context, string, []byte) error { return nil },
+	})
+
+	bind := func(kid string, cfg session.Config) session.Session {
+		sess, err := srvMgr.CreateSessionWithConfig("sid-"+kid, make([]byte, 32), cfg)
+		require.NoError(t, err)
+		srvMgr.BindKeyID(kid, "sid-"+kid)
+		return sess
+	}
+	bind("kid-expired", session.Config{MaxAge: 20 * time.Millisecond})
+	exhausted := bind("kid-exhausted", session.Config{MaxMessages: 1})
+	exhausted.UpdateLastUsed()
+	bind("kid-revoked", session.Config{})
+	srvMgr.BindDID("did:sage:ethereum:0xpeer", "sid-kid-revoked")
+	require.Equal(t, 1, srvMgr.RevokeSessionsByDID("did:sage:ethereum:0xpeer"))
+	time.Sleep(30 * time.Millisecond)
+
+	cases := map[string]string{
+		"kid-never":     SessionReasonNotEstablished,
+		"kid-expired":   SessionReasonExpired,
+		"kid-exhausted": SessionReasonExhausted,
+		"kid-revoked":   SessionReasonRevoked,
+	}
+	seen := make(map[string]bool)
+	for kid, want := range cases {
+		_, err := srv.HandleMessage(ctx, &transport.SecureMessage{
+			TaskID:   TaskSessionMessage,
+			Payload:  []byte("ciphertext"),
+			Metadata: map[string]string{"kid": kid},
+		})
+		require.Error(t, err, kid)
+		require.Equal(t, want, SessionRejectReason(err), kid)
+		require.Contains(t, err.Error(), want, kid)
+		seen[want] = true
+	}
+	require.Len(t, seen, len(cases))
+}
@@ -34,7 +34,7 @@ import (
 
 // setupHPKETestWithTransport returns a complete test rig and the MockTransport
 // so tests can intercept/mutate the server response.
-func setupHPKETestWithTransport(t *testing.T, srvCfg, cliCfg session.Config) (
+func setupHPKETestWithTransport(t testing.TB, srvCfg, cliCfg session.Config) (
 	*Client,
 	*Server,
 	*session.Manager,
@@ -698,17 +698,15 @@ func Test_Downgrade_Task_Rejects(t *testing.T) {
 // Client pinning: reject when the returned key differs from the stored pin.
 func Test_Client_Pinning_Rejects_KeyChange(t *testing.T) {
 	ctx := context.Background()
-	cli, srv, _, _, _, _, mt, clientDID, serverDID :=
+	cli, srv, _, _, _, resolver, mt, clientDID, serverDID :=
 		setupHPKETestWithTransport(t, session.Config{}, session.Config{})
 
 	// Preload WRONG pin for serverDID
 	wrongKP, err := keys.GenerateEd25519KeyPair()
 	require.NoError(t, err)
-	// Client.pins is exported within the same package.
-	if cli.pins == nil {
-		cli.pins = make(map[string][]byte)
-	}
-	cli.pins[serverDID] = wrongKP.PublicKey().(ed25519.PublicKey)
+	wrongFP, err := KeyFingerprint(wrongKP.PublicKey())
+	require.NoError(t, err)
+	require.NoError(t, cli.PinServerKey(serverDID, wrongFP))
 
 	mt.SendFunc = func(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
 		return srv.HandleMessage(ctx, msg)
@@ -716,7 +714,11 @@ func Test_Client_Pinning_Rejects_KeyChange(t *testing.T) {
 
 	ctxID := "ctx-" + uuid.NewString()
 	_, err = cli.Initialize(ctx, ctxID, clientDID, serverDID)
-	require.Error(t, err, "pin mismatch must be rejected")
+	require.ErrorIs(t, err, ErrServerKeyPinMismatch, "pin mismatch must be rejected")
+
+	// Rejected before sending, so the server never resolved the client
+	_, err = resolver.Resolve(ctx, sagedid.AgentDID(clientDID))
+	require.NoError(t, err)
 }
 
 // Server suite whitelist: reject suites that are not explicitly allowed.
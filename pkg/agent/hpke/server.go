@@ -22,9 +22,7 @@ import (
 	"context"
 	"crypto"
 	"crypto/ecdh"
-	"crypto/rand"
 	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -32,7 +30,6 @@ import (
 
 	"github.com/google/uuid"
 	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
-	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
 	"github.com/sage-x-project/sage/pkg/agent/did"
 	"github.com/sage-x-project/sage/pkg/agent/session"
 	"github.com/sage-x-project/sage/pkg/agent/transport"
@@ -41,8 +38,8 @@ import (
 // Server accepts HPKE init, verifies DID-signature, derives secrets,
 // creates a session, and returns a signed response with kid/ephS/ackTag.
 type Server struct {
-	key       sagecrypto.KeyPair // Ed25519 or ECDSA(Secp256k1) for signing messages (PR #118)
-	kem       sagecrypto.KeyPair // X25519 KEM static key (HPKE Base recipient)
+	key       sagecrypto.KeyPair   // Ed25519 or ECDSA(Secp256k1) for signing messages (PR #118)
+	kems      []sagecrypto.KeyPair // KEM static keys (HPKE Base recipient), at most one per scheme
 	DID       string
 	resolver  did.Resolver
 	transport transport.MessageTransport // Optional: for sending responses
@@ -56,6 +53,11 @@ type Server struct {
 	binder        KeyIDBinder
 	cookies       CookieVerifier // optional anti-DoS
 	allowedSuites []string
+	singleShot    SingleShotHandler // optional; enables TaskHPKESingleShot
+
+	sessionMessage SessionMessageHandler // optional; enables TaskSessionMessage
+	sessionReply   SessionReplyHandler   // optional; enables TaskSessionMessage with replies
+	responseCache  *ResponseCache        // optional; shares reply plaintexts across sessions
 }
 
 type ServerOpts struct {
@@ -63,9 +65,21 @@ type ServerOpts struct {
 	MaxSkew       time.Duration
 	Binder        KeyIDBinder
 	Info          InfoBuilder
-	KEM           sagecrypto.KeyPair         // X25519 KEM static key
+	KEM           sagecrypto.KeyPair         // KEM static key; its type selects the KEMScheme
+	KEMKeys       []sagecrypto.KeyPair       // Optional extra KEM keys of other schemes, for negotiation
 	Transport     transport.MessageTransport // Optional transport for responses
 	Cookies       CookieVerifier
+	SingleShot    SingleShotHandler // Optional: accept single-shot messages (see Mode)
+
+	SessionMessage SessionMessageHandler // Optional: accept session messages (see SecureClient)
+	SessionReply   SessionReplyHandler   // Optional: accept session messages and reply; takes precedence over SessionMessage
+	ResponseCache  *ResponseCache        // Optional: reuse SessionReply plaintexts for identical requests
+
+	// DeriveKEMFromSigningKey accepts inits encapsulated to the X25519 key
+	// derived from the Ed25519 signing key, for clients using
+	// KEMFallbackDeriveFromSigningKey. It only applies when no X25519 KEM
+	// key is configured and is ignored for other signing key types.
+	DeriveKEMFromSigningKey bool
 }
 
 // serverSigEnvelope is the canonical structure signed by the server.
@@ -95,9 +109,19 @@ func NewServer(key sagecrypto.KeyPair, sessMgr *session.Manager, didStr string,
 	if opts.MaxSkew == 0 {
 		opts.MaxSkew = 2 * time.Minute
 	}
+	var kems []sagecrypto.KeyPair
+	if opts.KEM != nil {
+		kems = append(kems, opts.KEM)
+	}
+	kems = append(kems, opts.KEMKeys...)
+	if opts.DeriveKEMFromSigningKey && !hasKEMScheme(kems, KEMX25519) {
+		if kem := signingKeyKEM(key); kem != nil {
+			kems = append(kems, kem)
+		}
+	}
 	return &Server{
 		key:           key,
-		kem:           opts.KEM,
+		kems:          kems,
 		resolver:      resolver,
 		transport:     opts.Transport,
 		sessMgr:       sessMgr,
@@ -108,6 +132,11 @@ func NewServer(key sagecrypto.KeyPair, sessMgr *session.Manager, didStr string,
 		binder:        opts.Binder,
 		cookies:       opts.Cookies,
 		allowedSuites: opts.AllowedSuites,
+		singleShot:    opts.SingleShot,
+
+		sessionMessage: opts.SessionMessage,
+		sessionReply:   opts.SessionReply,
+		responseCache:  opts.ResponseCache,
 	}
 }
 
@@ -117,6 +146,13 @@ func (s *Server) HandleMessage(ctx context.Context, msg *transport.SecureMessage
 	if msg == nil {
 		return nil, errors.New("empty message")
 	}
+	ctx, _ = transport.EnsureRequestID(ctx, msg)
+	if msg.TaskID == TaskHPKESingleShot {
+		return s.handleSingleShot(ctx, msg)
+	}
+	if msg.TaskID == TaskSessionMessage {
+		return s.handleSessionMessage(ctx, msg)
+	}
 	if msg.TaskID != TaskHPKEComplete {
 		return nil, fmt.Errorf("unsupported task: %s", msg.TaskID)
 	}
@@ -147,13 +183,13 @@ func (s *Server) HandleMessage(ctx context.Context, msg *transport.SecureMessage
 	}
 
 	// 5) Reproduce HPKE exporter from server skR and sender enc.
-	exporterHPKE, err := s.reproduceExporter(pl)
+	scheme, exporterHPKE, err := s.reproduceExporter(pl)
 	if err != nil {
 		return nil, err
 	}
 
 	// 6) Generate server ephS and compute ssE2E with client ephC.
-	ephSPubBytes, ssE2E, err := generateSrvE2E(pl.EphC)
+	ephSPubBytes, ssE2E, err := generateSrvE2E(scheme, pl.EphC)
 	if err != nil {
 		zeroBytes(exporterHPKE)
 		return nil, err
@@ -168,7 +204,7 @@ func (s *Server) HandleMessage(ctx context.Context, msg *transport.SecureMessage
 	}
 
 	// 8) Create a session for the receiver side and bind a key ID.
-	kid, err := s.createSessionAndBindKid(msg.ContextID, combined)
+	kid, err := s.createSessionAndBindKid(msg.ContextID, pl.InitDID, combined, pl.Lifetime, pl.SequenceNumbers)
 	if err != nil {
 		zeroBytes(combined)
 		return nil, err
@@ -231,7 +267,10 @@ func (s *Server) validateInitEnvelope(msg *transport.SecureMessage, pl HPKEInitP
 	if !s.nonces.checkAndMark(msg.ContextID + "|" + pl.Nonce) {
 		return fmt.Errorf("replay detected")
 	}
-	cInfo := s.info.BuildInfo(msg.ContextID, pl.InitDID, pl.RespDID)
+	if err := s.checkLifetime(pl.Lifetime); err != nil {
+		return err
+	}
+	cInfo := buildInfo(s.info, msg.ContextID, pl.InitDID, pl.RespDID, pl.Lifetime)
 	if string(cInfo) != string(pl.Info) {
 		return fmt.Errorf("info mismatch")
 	}
@@ -240,62 +279,89 @@ func (s *Server) validateInitEnvelope(msg *transport.SecureMessage, pl HPKEInitP
 		return fmt.Errorf("exportCtx mismatch")
 	}
 	// suite whitelist
-	if len(s.allowedSuites) > 0 && !strContains(s.allowedSuites, hpkeSuiteID) {
+	scheme, err := KEMSchemeByName(pl.KEM)
+	if err != nil {
+		return err
+	}
+	if len(s.allowedSuites) > 0 && !strContains(s.allowedSuites, scheme.SuiteID()) {
 		return fmt.Errorf("suite not allowed")
 	}
 	return nil
 }
 
+// checkLifetime accepts a proposed session lifetime that the info builder can
+// bind and that stays within the session manager's default config.
+func (s *Server) checkLifetime(lt *SessionLifetime) error {
+	if lt == nil {
+		return nil
+	}
+	if _, ok := s.info.(LifetimeInfoBuilder); !ok {
+		return fmt.Errorf("session lifetime negotiation not supported")
+	}
+	if policy := LifetimeFromConfig(s.sessMgr.GetDefaultConfig()); !lt.within(policy) {
+		return fmt.Errorf("%w: proposed %s, policy %s", ErrLifetimeRejected, lt, policy)
+	}
+	return nil
+}
+
 // Recompute HPKE exporter from server KEM private key and sender enc.
-func (s *Server) reproduceExporter(pl HPKEInitPayload) ([]byte, error) {
-	if s.kem == nil {
-		return nil, fmt.Errorf("server KEM private key not configured")
+// The server must hold a KEM key of the scheme the client used.
+func (s *Server) reproduceExporter(pl HPKEInitPayload) (KEMScheme, []byte, error) {
+	scheme, skR, err := s.kemKeyFor(pl.KEM)
+	if err != nil {
+		return nil, nil, err
 	}
-	exporter, err := keys.HPKEOpenSharedSecretWithPriv(
-		s.kem.PrivateKey(), // X25519 KEM skR
-		pl.Enc,             // sender enc (32B)
-		pl.Info,
-		pl.ExportCtx,
-		32,
-	)
+	exporter, err := scheme.Decap(skR, pl.Enc, pl.Info, pl.ExportCtx)
 	if err != nil {
-		return nil, fmt.Errorf("hpke open: %w", err)
+		return nil, nil, fmt.Errorf("hpke open: %w", err)
 	}
-	return exporter, nil
+	return scheme, exporter, nil
 }
 
-// Generate server ephemeral X25519 and compute ssE2E with client ephC.
-func generateSrvE2E(ephC []byte) (ephSPubBytes, ssE2E []byte, err error) {
-	x := ecdh.X25519()
-	ephCPub, err := x.NewPublicKey(ephC)
-	if err != nil {
-		return nil, nil, fmt.Errorf("bad ephC: %w", err)
+// kemKeyFor returns the server's KEM private key for the named scheme.
+func (s *Server) kemKeyFor(name string) (KEMScheme, *ecdh.PrivateKey, error) {
+	if len(s.kems) == 0 {
+		return nil, nil, fmt.Errorf("server KEM private key not configured")
 	}
-	srvPriv, err := x.GenerateKey(rand.Reader)
+	if name == "" {
+		name = KEMX25519.Name()
+	}
+	have := make([]string, 0, len(s.kems))
+	for _, kp := range s.kems {
+		scheme, sk, err := KEMSchemeForPrivateKey(kp.PrivateKey())
+		if err != nil {
+			return nil, nil, fmt.Errorf("server KEM key: %w", err)
+		}
+		if scheme.Name() == name {
+			return scheme, sk, nil
+		}
+		have = append(have, scheme.Name())
+	}
+	return nil, nil, fmt.Errorf("%w: client used %s, server KEM keys are %v", ErrKEMMismatch, name, have)
+}
+
+// Generate server ephemeral key on the scheme's curve and compute ssE2E with client ephC.
+func generateSrvE2E(scheme KEMScheme, ephC []byte) (ephSPubBytes, ssE2E []byte, err error) {
+	srvPriv, err := generateEphemeral(scheme)
 	if err != nil {
 		return nil, nil, fmt.Errorf("srv eph gen: %w", err)
 	}
-	sec, err := srvPriv.ECDH(ephCPub)
+	sec, err := ecdhE2E(srvPriv, ephC)
 	if err != nil {
-		return nil, nil, fmt.Errorf("e2e ecdh: %w", err)
-	}
-	// RFC 7748
-	if isAllZero32(sec) {
-		return nil, nil, fmt.Errorf("invalid ECDH (all-zero)")
+		return nil, nil, fmt.Errorf("bad ephC: %w", err)
 	}
 	return srvPriv.PublicKey().Bytes(), sec, nil
 }
 
-// Create a session as receiver and bind a generated (or issued) key ID.
-func (s *Server) createSessionAndBindKid(ctxID string, combined []byte) (string, error) {
-	_, sid, _, err := s.sessMgr.EnsureSessionFromExporterWithRole(
-		combined,
-		"sage/hpke+e2e v1",
-		false, // receiver
-		nil,
-	)
+// Create a session as receiver under the agreed lifetime and bind a generated
+// (or issued) key ID. The session uses sequence numbers when the initiator
+// proposed them, whatever the server's default config says: they only add
+// replay protection, and both peers must agree. The session is also bound to
+// the initiator DID so it can be revoked by DID, and to the handshake context ID.
+func (s *Server) createSessionAndBindKid(ctxID, peerDID string, combined []byte, lt *SessionLifetime, seq bool) (string, error) {
+	sid, err := ensureLifetimeSession(s.sessMgr, combined, false, lt, seq)
 	if err != nil {
-		return "", fmt.Errorf("session: %w", err)
+		return "", err
 	}
 	kid := "kid-" + uuid.NewString()
 	if s.binder != nil {
@@ -304,6 +370,8 @@ func (s *Server) createSessionAndBindKid(ctxID string, combined []byte) (string,
 		}
 	}
 	s.sessMgr.BindKeyID(kid, sid)
+	s.sessMgr.BindDID(peerDID, sid)
+	s.sessMgr.BindContextID(ctxID, sid)
 	return kid, nil
 }
 
@@ -322,21 +390,21 @@ func (s *Server) buildAndSignResponse(req *transport.SecureMessage, pl HPKEInitP
 		Task:          req.TaskID,
 		Ctx:           req.ContextID,
 		Kid:           kid,
-		EphS:          base64.RawURLEncoding.EncodeToString(ephSPubBytes),
-		AckTagB64:     base64.RawURLEncoding.EncodeToString(ack),
+		EphS:          session.Encoding.EncodeToString(ephSPubBytes),
+		AckTagB64:     session.Encoding.EncodeToString(ack),
 		Ts:            ts,
 		Did:           s.DID,
-		InfoHash:      base64.RawURLEncoding.EncodeToString(ih[:]),
-		ExportCtxHash: base64.RawURLEncoding.EncodeToString(eh[:]),
-		Enc:           base64.RawURLEncoding.EncodeToString(pl.Enc),
-		EphC:          base64.RawURLEncoding.EncodeToString(pl.EphC),
+		InfoHash:      session.Encoding.EncodeToString(ih[:]),
+		ExportCtxHash: session.Encoding.EncodeToString(eh[:]),
+		Enc:           session.Encoding.EncodeToString(pl.Enc),
+		EphC:          session.Encoding.EncodeToString(pl.EphC),
 	}
 
 	envBytes, err := json.Marshal(env) // deterministic order by struct field order
 	if err != nil {
 		return nil, fmt.Errorf("marshal env: %w", err)
 	}
-	sig, err := s.key.Sign(envBytes) // Ed25519 sign
+	sig, err := signHandshake(s.key, envBytes) // Ed25519ctx sign
 	if err != nil {
 		return nil, fmt.Errorf("sign env: %w", err)
 	}
@@ -356,7 +424,7 @@ func (s *Server) buildAndSignResponse(req *transport.SecureMessage, pl HPKEInitP
 		"enc":           env.Enc,
 		"ephC":          env.EphC,
 		// Detached signature
-		"sigB64": base64.RawURLEncoding.EncodeToString(sig),
+		"sigB64": session.Encoding.EncodeToString(sig),
 	}
 
 	data, err := json.Marshal(out)
@@ -389,6 +389,27 @@ func Test_Session_KeyID_Uniqueness(t *testing.T) {
 	require.Equal(t, srvSession.GetID(), cliSession.GetID(), "shared secrets should match across phases")
 }
 
+func Test_Session_LookupByContextID(t *testing.T) {
+	ctx := context.Background()
+
+	cli, _, srvMgr, cliMgr, _, _, clientDID, serverDID := setupHPKETest(t, session.Config{}, session.Config{})
+
+	ctxID := "ctx-" + uuid.NewString()
+	kid, err := cli.Initialize(ctx, ctxID, clientDID, serverDID)
+	require.NoError(t, err)
+
+	for name, mgr := range map[string]*session.Manager{"server": srvMgr, "client": cliMgr} {
+		byKid, ok := mgr.GetByKeyID(kid)
+		require.True(t, ok, name)
+		byCtx, ok := mgr.GetByContextID(ctxID)
+		require.True(t, ok, name)
+		require.Equal(t, byKid.GetID(), byCtx.GetID(), name)
+	}
+
+	_, ok := srvMgr.GetByContextID("ctx-" + uuid.NewString())
+	require.False(t, ok)
+}
+
 // Test 8.1.4: HPKE 서버
 func TestServer(t *testing.T) {
 	// Specification Requirement: HPKE server communication test
@@ -517,3 +538,16 @@ func TestServer(t *testing.T) {
 	}
 	helpers.SaveTestData(t, "hpke/hpke_server.json", testData)
 }
+
+func Test_HPKE_SessionsBoundToPeerDID(t *testing.T) {
+	cli, _, srvMgr, cliMgr, _, _, clientDID, serverDID := setupHPKETest(t, session.Config{}, session.Config{})
+
+	kid, err := cli.Initialize(context.Background(), "ctx-"+uuid.NewString(), clientDID, serverDID)
+	require.NoError(t, err)
+
+	// Each side's session can be revoked by the other side's DID
+	require.Equal(t, 1, cliMgr.RevokeSessionsByDID(serverDID))
+	require.Equal(t, 1, srvMgr.RevokeSessionsByDID(clientDID))
+	_, err = cliMgr.LookupByKeyID(kid)
+	require.ErrorIs(t, err, session.ErrSessionRevoked)
+}
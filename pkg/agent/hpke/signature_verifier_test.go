@@ -27,6 +27,7 @@ import (
 	"testing"
 
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
 	"github.com/sage-x-project/sage/tests/helpers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -268,3 +269,28 @@ func BenchmarkCompositeVerifier_Verify_ECDSA(b *testing.B) {
 		_ = composite.Verify(payload, rawSignature, &privateKey.PublicKey)
 	}
 }
+
+func TestVerifySignature_Ed25519HandshakeContext(t *testing.T) {
+	keyPair, err := keys.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	pub := keyPair.PublicKey().(ed25519.PublicKey)
+	payload := []byte(`{"initDid":"did:sage:test:alice","nonce":"n-1"}`)
+
+	sig, err := signHandshake(keyPair, payload)
+	require.NoError(t, err)
+
+	// Accepted with the raw key or the key pair returned by some resolvers
+	assert.NoError(t, verifySignature(payload, sig, pub))
+	assert.NoError(t, verifySignature(payload, sig, keyPair))
+
+	// A registration-context signature over the same bytes is rejected
+	regSig, err := keys.SignWithContext(keyPair, payload, []byte(keys.SigningContextRegistration))
+	require.NoError(t, err)
+	assert.Error(t, verifySignature(payload, regSig, pub))
+
+	// So is a plain Ed25519 signature
+	plainSig, err := keyPair.Sign(payload)
+	require.NoError(t, err)
+	assert.Error(t, verifySignature(payload, plainSig, pub))
+	assert.Error(t, verifySignature(payload, plainSig, keyPair))
+}
@@ -25,6 +25,14 @@ type InfoBuilder interface {
 	BuildExportContext(ctxID string) []byte
 }
 
+// LifetimeInfoBuilder is an InfoBuilder that can bind the negotiated session
+// lifetime into the HPKE info (see SessionLifetime). Builders without it keep
+// the unbound transcript and handshakes do not negotiate a lifetime.
+type LifetimeInfoBuilder interface {
+	InfoBuilder
+	BuildInfoWithLifetime(ctxID, initDID, respDID string, lt SessionLifetime) []byte
+}
+
 // KeyIDBinder optionally lets the server issue custom key IDs.
 type KeyIDBinder interface {
 	IssueKeyID(ctxID string) (keyid string, ok bool)
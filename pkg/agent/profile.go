@@ -0,0 +1,322 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package agent
+
+import (
+	"context"
+	"crypto/ecdh"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/hpke"
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+	sagehttp "github.com/sage-x-project/sage/pkg/agent/transport/http"
+)
+
+// ErrNoEndpoint is returned by DialSecure when the peer publishes no endpoint.
+var ErrNoEndpoint = errors.New("peer publishes no endpoint")
+
+// Registry is the DID registry a Profile registers with and, unless a
+// Resolver is configured, resolves peers from. *did.Manager and
+// *didtest.FakeManager satisfy it.
+type Registry interface {
+	RegisterAgent(ctx context.Context, chain did.Chain, req *did.RegistrationRequest) (*did.RegistrationResult, error)
+	ResolveAgent(ctx context.Context, agentDID did.AgentDID) (*did.AgentMetadata, error)
+	ResolvePublicKey(ctx context.Context, agentDID did.AgentDID) (interface{}, error)
+}
+
+// Dialer returns the transport that reaches a peer at endpoint.
+type Dialer func(endpoint string) transport.MessageTransport
+
+// Config describes the agent a Profile acts as. DID, Name and Registry are
+// required; everything else has a working default.
+type Config struct {
+	DID         did.AgentDID
+	Name        string
+	Description string
+	// Endpoint is the base URL peers reach ServeSecure at; the handler is
+	// expected under {Endpoint}/messages. Register publishes it.
+	Endpoint string
+
+	Registry Registry
+	// Resolver resolves peers. Defaults to one backed by Registry.
+	Resolver did.Resolver
+	// SigningKey is the agent's Ed25519 key. A new key is generated if nil.
+	SigningKey sagecrypto.KeyPair
+	// KEMKey is the agent's X25519 key for HPKE key agreement, published by
+	// Register. A new key is generated if nil.
+	KEMKey sagecrypto.KeyPair
+	// Sessions holds the agent's secure sessions. A new manager, closed by
+	// Close, is created if nil.
+	Sessions *session.Manager
+	// Dial opens transports to peers. Defaults to HTTP.
+	Dial Dialer
+}
+
+// Profile bundles an agent's key, DID, resolver and session manager so an
+// application wires them once and then registers, dials and serves through
+// a single object.
+//
+// Secure channels are HPKE sessions to the KEM key each peer publishes;
+// peers without one are refused rather than reached through a key derived
+// from their signing key. DIDs use the registry's method when it reports
+// one, as *did.Manager does, and did.DefaultMethod otherwise. Profile is
+// safe for concurrent use.
+type Profile struct {
+	did         did.AgentDID
+	name        string
+	description string
+	endpoint    string
+	method      string
+
+	key         sagecrypto.KeyPair
+	kem         sagecrypto.KeyPair
+	registry    Registry
+	resolver    did.Resolver
+	sessions    *session.Manager
+	ownSessions bool
+	dial        Dialer
+
+	mu      sync.Mutex
+	clients map[did.AgentDID]*hpke.SecureClient
+}
+
+// New builds a Profile from cfg.
+func New(cfg Config) (*Profile, error) {
+	if cfg.DID == "" || cfg.Name == "" {
+		return nil, fmt.Errorf("profile needs a DID and a name")
+	}
+	if cfg.Registry == nil {
+		return nil, fmt.Errorf("profile needs a registry")
+	}
+	method := registryMethod(cfg.Registry)
+	if _, _, err := did.ParseDIDWithMethod(cfg.DID, method); err != nil {
+		return nil, fmt.Errorf("invalid DID %s: %w", cfg.DID, err)
+	}
+
+	p := &Profile{
+		did:         cfg.DID,
+		name:        cfg.Name,
+		description: cfg.Description,
+		endpoint:    cfg.Endpoint,
+		method:      method,
+		key:         cfg.SigningKey,
+		kem:         cfg.KEMKey,
+		registry:    cfg.Registry,
+		resolver:    cfg.Resolver,
+		sessions:    cfg.Sessions,
+		dial:        cfg.Dial,
+		clients:     make(map[did.AgentDID]*hpke.SecureClient),
+	}
+	if p.key == nil {
+		key, err := keys.GenerateEd25519KeyPair()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		p.key = key
+	}
+	if p.key.Type() != sagecrypto.KeyTypeEd25519 {
+		return nil, fmt.Errorf("signing key must be %s, got %s", sagecrypto.KeyTypeEd25519, p.key.Type())
+	}
+	if p.kem == nil {
+		kem, err := keys.GenerateX25519KeyPair()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate KEM key: %w", err)
+		}
+		p.kem = kem
+	}
+	if p.kem.Type() != sagecrypto.KeyTypeX25519 {
+		return nil, fmt.Errorf("KEM key must be %s, got %s", sagecrypto.KeyTypeX25519, p.kem.Type())
+	}
+	if p.resolver == nil {
+		p.resolver = registryResolver{p.registry}
+	}
+	if p.sessions == nil {
+		p.sessions = session.NewManager()
+		p.ownSessions = true
+	}
+	if p.dial == nil {
+		p.dial = func(endpoint string) transport.MessageTransport {
+			return sagehttp.NewHTTPTransport(endpoint)
+		}
+	}
+	return p, nil
+}
+
+// DID returns the agent's DID.
+func (p *Profile) DID() did.AgentDID {
+	return p.did
+}
+
+// SigningKey returns the agent's Ed25519 key.
+func (p *Profile) SigningKey() sagecrypto.KeyPair {
+	return p.key
+}
+
+// KEMKey returns the agent's X25519 KEM key.
+func (p *Profile) KEMKey() sagecrypto.KeyPair {
+	return p.kem
+}
+
+// Sessions returns the agent's session manager.
+func (p *Profile) Sessions() *session.Manager {
+	return p.sessions
+}
+
+// Register publishes the agent's DID, name, endpoint, signing key and KEM
+// key on the chain named by its DID.
+func (p *Profile) Register(ctx context.Context) (*did.RegistrationResult, error) {
+	chain, _, err := did.ParseDIDWithMethod(p.did, p.method)
+	if err != nil {
+		return nil, err
+	}
+	kemPub, ok := p.kem.PublicKey().(*ecdh.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported KEM public key type %T", p.kem.PublicKey())
+	}
+	return p.registry.RegisterAgent(ctx, chain, &did.RegistrationRequest{
+		DID:         p.did,
+		Name:        p.name,
+		Description: p.description,
+		Endpoint:    p.endpoint,
+		KeyPair:     p.key,
+		Keys:        []did.AgentKey{{Type: did.KeyTypeX25519, KeyData: kemPub.Bytes()}},
+	})
+}
+
+// ResolvePeer returns the metadata of an active peer agent.
+func (p *Profile) ResolvePeer(ctx context.Context, peerDID did.AgentDID) (*did.AgentMetadata, error) {
+	meta, err := p.resolver.Resolve(ctx, peerDID)
+	if err != nil {
+		return nil, err
+	}
+	if !meta.IsActive {
+		return nil, did.ErrInactiveAgent
+	}
+	return meta, nil
+}
+
+// DialSecure returns a client for sending encrypted messages to peerDID,
+// performing the handshake with the peer's published endpoint. Later calls
+// for the same peer return the same client, which renews the session on
+// its own once it expires.
+func (p *Profile) DialSecure(ctx context.Context, peerDID did.AgentDID) (*hpke.SecureClient, error) {
+	p.mu.Lock()
+	sc, ok := p.clients[peerDID]
+	p.mu.Unlock()
+	if ok {
+		return sc, nil
+	}
+
+	meta, err := p.ResolvePeer(ctx, peerDID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", peerDID, err)
+	}
+	if meta.Endpoint == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNoEndpoint, peerDID)
+	}
+
+	client := hpke.NewClient(p.dial(meta.Endpoint), p.resolver, p.key, string(p.did), hpke.DefaultInfoBuilder{}, p.sessions)
+	sc = hpke.NewSecureClient(client, string(peerDID))
+	if _, err := sc.Establish(ctx); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if existing, ok := p.clients[peerDID]; ok {
+		return existing, nil
+	}
+	p.clients[peerDID] = sc
+	return sc, nil
+}
+
+// ServeSecure returns the HTTP handler that accepts handshakes and
+// encrypted messages from peers, passing each decrypted message to handler.
+// Mount it at {Endpoint}/messages.
+func (p *Profile) ServeSecure(handler hpke.SessionMessageHandler) http.Handler {
+	srv := hpke.NewServer(p.key, p.sessions, string(p.did), p.resolver, &hpke.ServerOpts{
+		KEM:            p.kem,
+		SessionMessage: handler,
+	})
+	return sagehttp.NewHTTPServer(srv.HandleMessage)
+}
+
+// Close releases the session manager if the Profile created it.
+func (p *Profile) Close() error {
+	if p.ownSessions {
+		return p.sessions.Close()
+	}
+	return nil
+}
+
+// registryMethod returns the DID method registry uses, or
+// did.DefaultMethod if it does not report one.
+func registryMethod(registry Registry) string {
+	if m, ok := registry.(interface{ Method() string }); ok && m.Method() != "" {
+		return m.Method()
+	}
+	return did.DefaultMethod
+}
+
+// registryResolver adapts a Registry to did.Resolver. KEM keys come from
+// the agent metadata; listing and search are not supported.
+type registryResolver struct {
+	registry Registry
+}
+
+func (r registryResolver) Resolve(ctx context.Context, agentDID did.AgentDID) (*did.AgentMetadata, error) {
+	return r.registry.ResolveAgent(ctx, agentDID)
+}
+
+func (r registryResolver) ResolvePublicKey(ctx context.Context, agentDID did.AgentDID) (interface{}, error) {
+	return r.registry.ResolvePublicKey(ctx, agentDID)
+}
+
+func (r registryResolver) ResolveKEMKey(ctx context.Context, agentDID did.AgentDID) (interface{}, error) {
+	meta, err := r.registry.ResolveAgent(ctx, agentDID)
+	if err != nil {
+		return nil, err
+	}
+	if !meta.IsActive {
+		return nil, did.ErrInactiveAgent
+	}
+	if meta.PublicKEMKey == nil {
+		return nil, fmt.Errorf("no KEM key published for %s", agentDID)
+	}
+	return meta.PublicKEMKey, nil
+}
+
+func (r registryResolver) VerifyMetadata(ctx context.Context, agentDID did.AgentDID, metadata *did.AgentMetadata) (*did.VerificationResult, error) {
+	return nil, fmt.Errorf("metadata verification is not supported by the registry resolver")
+}
+
+func (r registryResolver) ListAgentsByOwner(ctx context.Context, ownerAddress string) ([]*did.AgentMetadata, error) {
+	return nil, fmt.Errorf("listing agents is not supported by the registry resolver")
+}
+
+func (r registryResolver) Search(ctx context.Context, criteria did.SearchCriteria) ([]*did.AgentMetadata, error) {
+	return nil, fmt.Errorf("search is not supported by the registry resolver")
+}
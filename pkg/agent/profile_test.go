@@ -0,0 +1,195 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package agent
+
+import (
+	"context"
+	"crypto/ecdh"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/did/didtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newServedProfile starts an HTTP endpoint and builds a Profile that
+// publishes it. mux is where the caller mounts ServeSecure.
+func newServedProfile(t *testing.T, reg Registry, agentDID did.AgentDID, name string) (*Profile, *http.ServeMux) {
+	t.Helper()
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	p, err := New(Config{DID: agentDID, Name: name, Endpoint: ts.URL, Registry: reg})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = p.Close() })
+	return p, mux
+}
+
+// TestProfile_Quickstart registers two agents and sends one encrypted
+// message from alice to bob, the way a new application would.
+func TestProfile_Quickstart(t *testing.T) {
+	ctx := context.Background()
+	reg := didtest.NewFakeManager()
+
+	alice, _ := newServedProfile(t, reg, "did:sage:ethereum:alice", "alice")
+	bob, bobMux := newServedProfile(t, reg, "did:sage:ethereum:bob", "bob")
+
+	_, err := alice.Register(ctx)
+	require.NoError(t, err)
+	_, err = bob.Register(ctx)
+	require.NoError(t, err)
+
+	received := make(chan string, 1)
+	bobMux.Handle("/messages", bob.ServeSecure(func(ctx context.Context, kid string, plaintext []byte) error {
+		received <- string(plaintext)
+		return nil
+	}))
+
+	peer, err := alice.ResolvePeer(ctx, bob.DID())
+	require.NoError(t, err)
+	assert.Equal(t, "bob", peer.Name)
+
+	sc, err := alice.DialSecure(ctx, bob.DID())
+	require.NoError(t, err)
+	_, err = sc.Send(ctx, []byte("hello bob"))
+	require.NoError(t, err)
+
+	select {
+	case msg := <-received:
+		assert.Equal(t, "hello bob", msg)
+	case <-time.After(5 * time.Second):
+		t.Fatal("bob did not receive the message")
+	}
+
+	// Both sides hold the same session
+	_, ok := bob.Sessions().GetByKeyID(sc.KeyID())
+	assert.True(t, ok)
+
+	again, err := alice.DialSecure(ctx, bob.DID())
+	require.NoError(t, err)
+	assert.Same(t, sc, again)
+}
+
+func TestProfile_Errors(t *testing.T) {
+	ctx := context.Background()
+	reg := didtest.NewFakeManager()
+
+	t.Run("missing config", func(t *testing.T) {
+		_, err := New(Config{Name: "x", Registry: reg})
+		assert.Error(t, err)
+		_, err = New(Config{DID: "did:sage:ethereum:x", Name: "x"})
+		assert.Error(t, err)
+	})
+
+	t.Run("non-Ed25519 key", func(t *testing.T) {
+		k, err := keys.GenerateSecp256k1KeyPair()
+		require.NoError(t, err)
+		_, err = New(Config{DID: "did:sage:ethereum:x", Name: "x", Registry: reg, SigningKey: k})
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown peer", func(t *testing.T) {
+		p, err := New(Config{DID: "did:sage:ethereum:carol", Name: "carol", Registry: reg})
+		require.NoError(t, err)
+		defer p.Close()
+		_, err = p.DialSecure(ctx, "did:sage:ethereum:nobody")
+		assert.ErrorIs(t, err, did.ErrDIDNotFound)
+	})
+
+	t.Run("non-X25519 KEM key", func(t *testing.T) {
+		k, err := keys.GenerateEd25519KeyPair()
+		require.NoError(t, err)
+		_, err = New(Config{DID: "did:sage:ethereum:x", Name: "x", Registry: reg, KEMKey: k})
+		assert.Error(t, err)
+	})
+
+	t.Run("peer without KEM key", func(t *testing.T) {
+		// Registered directly, so no KEM key is published; DialSecure must
+		// not fall back to deriving one from the signing key.
+		eve, err := keys.GenerateEd25519KeyPair()
+		require.NoError(t, err)
+		_, err = reg.RegisterAgent(ctx, did.ChainEthereum, &did.RegistrationRequest{
+			DID: "did:sage:ethereum:eve", Name: "eve", Endpoint: "http://127.0.0.1:1", KeyPair: eve,
+		})
+		require.NoError(t, err)
+
+		p, err := New(Config{DID: "did:sage:ethereum:frank", Name: "frank", Registry: reg})
+		require.NoError(t, err)
+		defer p.Close()
+		_, err = p.DialSecure(ctx, "did:sage:ethereum:eve")
+		assert.Error(t, err)
+	})
+
+	t.Run("peer without endpoint", func(t *testing.T) {
+		p, err := New(Config{DID: "did:sage:ethereum:dave", Name: "dave", Registry: reg})
+		require.NoError(t, err)
+		defer p.Close()
+		_, err = p.Register(ctx)
+		require.NoError(t, err)
+		_, err = p.DialSecure(ctx, p.DID())
+		assert.ErrorIs(t, err, ErrNoEndpoint)
+	})
+}
+
+func TestProfile_Register_PublishesKEMKey(t *testing.T) {
+	ctx := context.Background()
+	reg := didtest.NewFakeManager()
+
+	p, err := New(Config{DID: "did:sage:ethereum:alice", Name: "alice", Registry: reg})
+	require.NoError(t, err)
+	defer p.Close()
+	_, err = p.Register(ctx)
+	require.NoError(t, err)
+
+	meta, err := reg.ResolveAgent(ctx, p.DID())
+	require.NoError(t, err)
+	assert.Equal(t, p.KEMKey().PublicKey().(*ecdh.PublicKey).Bytes(), meta.PublicKEMKey)
+}
+
+// TestProfile_CustomMethod checks that a Profile follows the registry's DID
+// method instead of assuming did:sage.
+func TestProfile_CustomMethod(t *testing.T) {
+	ctx := context.Background()
+	reg := didtest.NewFakeManager()
+	require.NoError(t, reg.SetMethod("sagecorp"))
+
+	_, err := New(Config{DID: "did:sage:ethereum:alice", Name: "alice", Registry: reg})
+	assert.Error(t, err)
+
+	alice, _ := newServedProfile(t, reg, "did:sagecorp:ethereum:alice", "alice")
+	bob, bobMux := newServedProfile(t, reg, "did:sagecorp:ethereum:bob", "bob")
+	_, err = alice.Register(ctx)
+	require.NoError(t, err)
+	_, err = bob.Register(ctx)
+	require.NoError(t, err)
+	bobMux.Handle("/messages", bob.ServeSecure(func(ctx context.Context, kid string, plaintext []byte) error {
+		return nil
+	}))
+
+	sc, err := alice.DialSecure(ctx, bob.DID())
+	require.NoError(t, err)
+	_, err = sc.Send(ctx, []byte("hello"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,258 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+// Package relay forwards end-to-end encrypted A2A messages through an
+// intermediary for agents that cannot be reached directly.
+//
+// The sender seals the message to the recipient's KEM key with HPKE (RFC
+// 9180) and sends it to a relay Server addressed by recipient DID. The relay
+// only reads the recipient from the envelope metadata and forwards the
+// message unchanged; it holds no KEM key and cannot read the payload. The
+// recipient opens it with its KEM private key.
+//
+// The envelope binds the sender and recipient DIDs, so a relay cannot
+// re-address a message or change its claimed sender without the recipient
+// noticing. HPKE base mode does not authenticate the sender, however: anyone
+// with the recipient's public KEM key can seal a message claiming any sender
+// DID. Sign the plaintext, or run a handshake, when the recipient must know
+// who wrote it.
+package relay
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+)
+
+// TaskRelayForward identifies a sealed envelope for the relay to forward.
+const TaskRelayForward = "relay/forward@v1"
+
+// MetadataRecipient is the metadata key holding the recipient DID.
+const MetadataRecipient = "recipient"
+
+// envelopeInfo prefixes the HPKE info; the sender and recipient DIDs are
+// appended so the envelope cannot be re-addressed or re-attributed.
+const envelopeInfo = "sage/relay/v1|"
+
+var (
+	// ErrUnknownRecipient is returned by the relay for a recipient DID it has
+	// no route to.
+	ErrUnknownRecipient = errors.New("relay: unknown recipient")
+	// ErrNotForRecipient is returned when an envelope is addressed to another
+	// agent.
+	ErrNotForRecipient = errors.New("relay: envelope addressed to another recipient")
+)
+
+// KEMKeyResolver looks up an agent's public KEM key. did.Resolver and
+// did.MultiChainResolver implement it.
+type KEMKeyResolver interface {
+	ResolveKEMKey(ctx context.Context, agentDID did.AgentDID) (interface{}, error)
+}
+
+// Seal encrypts plaintext from senderDID to recipientDID under the
+// recipient's KEM key (X25519 or P-256) and returns the envelope to send to
+// a relay.
+func Seal(senderDID, recipientDID string, recipientKEMKey crypto.PublicKey, plaintext []byte) (*transport.SecureMessage, error) {
+	if senderDID == "" || recipientDID == "" {
+		return nil, fmt.Errorf("sender and recipient DIDs are required")
+	}
+	pub, err := keys.ECDHPublicKey(recipientKEMKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient KEM key: %w", err)
+	}
+	packet, _, err := keys.HPKESealAndExportToPeer(pub, plaintext, envelopeInfoFor(senderDID, recipientDID), nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seal envelope: %w", err)
+	}
+	return &transport.SecureMessage{
+		ID:       uuid.NewString(),
+		TaskID:   TaskRelayForward,
+		Payload:  packet,
+		DID:      senderDID,
+		Role:     "user",
+		Metadata: map[string]string{MetadataRecipient: recipientDID},
+	}, nil
+}
+
+// Open decrypts an envelope addressed to recipientDID with the recipient's
+// KEM private key and returns the sender DID it claims and the plaintext.
+func Open(msg *transport.SecureMessage, recipientDID string, recipientKEMPriv crypto.PrivateKey) (senderDID string, plaintext []byte, err error) {
+	priv, err := keys.ECDHPrivateKey(recipientKEMPriv)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid KEM private key: %w", err)
+	}
+	return open(msg, recipientDID, priv)
+}
+
+func open(msg *transport.SecureMessage, recipientDID string, priv *ecdh.PrivateKey) (string, []byte, error) {
+	if msg == nil || msg.TaskID != TaskRelayForward {
+		return "", nil, fmt.Errorf("not a relay envelope")
+	}
+	if to := msg.Metadata[MetadataRecipient]; to != recipientDID {
+		return "", nil, fmt.Errorf("%w: %s", ErrNotForRecipient, to)
+	}
+	plaintext, _, err := keys.HPKEOpenAndExportWithPriv(priv, msg.Payload, envelopeInfoFor(msg.DID, recipientDID), nil, 0)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open envelope: %w", err)
+	}
+	return msg.DID, plaintext, nil
+}
+
+func envelopeInfoFor(senderDID, recipientDID string) []byte {
+	return []byte(envelopeInfo + senderDID + "|" + recipientDID)
+}
+
+// Client seals messages and sends them through a relay.
+type Client struct {
+	transport transport.MessageTransport
+	resolver  KEMKeyResolver
+	did       string
+}
+
+// NewClient returns a client that sends as senderDID over t, which must be
+// connected to the relay. Recipient KEM keys are looked up with resolver.
+func NewClient(t transport.MessageTransport, resolver KEMKeyResolver, senderDID string) *Client {
+	return &Client{transport: t, resolver: resolver, did: senderDID}
+}
+
+// Send seals plaintext to recipientDID and hands it to the relay.
+func (c *Client) Send(ctx context.Context, recipientDID string, plaintext []byte) (*transport.Response, error) {
+	kemKey, err := c.resolver.ResolveKEMKey(ctx, did.AgentDID(recipientDID))
+	if err != nil {
+		return nil, fmt.Errorf("resolve recipient KEM key: %w", err)
+	}
+	msg, err := Seal(c.did, recipientDID, kemKey, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.transport.Send(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("send to relay: %w", err)
+	}
+	if !resp.Success {
+		if resp.Error != nil {
+			return resp, fmt.Errorf("relay rejected message: %w", resp.Error)
+		}
+		return resp, errors.New("relay rejected message")
+	}
+	return resp, nil
+}
+
+// Server is the relay: it forwards envelopes to the transport registered
+// for their recipient without decrypting them.
+type Server struct {
+	mu     sync.RWMutex
+	routes map[string]transport.MessageTransport
+}
+
+// NewServer creates a relay with no routes.
+func NewServer() *Server {
+	return &Server{routes: make(map[string]transport.MessageTransport)}
+}
+
+// Register routes envelopes for recipientDID to t, replacing any previous
+// route.
+func (s *Server) Register(recipientDID string, t transport.MessageTransport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[recipientDID] = t
+}
+
+// Unregister removes the route for recipientDID.
+func (s *Server) Unregister(recipientDID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.routes, recipientDID)
+}
+
+// HandleMessage forwards a TaskRelayForward envelope to its recipient and
+// returns the recipient's response. It can serve as an HTTP or WebSocket
+// message handler.
+func (s *Server) HandleMessage(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
+	if msg == nil {
+		return nil, errors.New("empty message")
+	}
+	if msg.TaskID != TaskRelayForward {
+		return nil, fmt.Errorf("unsupported task: %s", msg.TaskID)
+	}
+	ctx, _ = transport.EnsureRequestID(ctx, msg)
+
+	recipient := msg.Metadata[MetadataRecipient]
+	s.mu.RLock()
+	next, ok := s.routes[recipient]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownRecipient, recipient)
+	}
+
+	fwd := *msg
+	fwd.Metadata = make(map[string]string, len(msg.Metadata))
+	for k, v := range msg.Metadata {
+		fwd.Metadata[k] = v
+	}
+	resp, err := next.Send(ctx, &fwd)
+	if err != nil {
+		return nil, fmt.Errorf("forward to %s: %w", recipient, err)
+	}
+	return resp, nil
+}
+
+// Handler receives the plaintext of an envelope and the sender DID it
+// claims.
+type Handler func(ctx context.Context, senderDID string, plaintext []byte) error
+
+// Receiver opens envelopes delivered by a relay for one recipient.
+type Receiver struct {
+	did     string
+	priv    *ecdh.PrivateKey
+	handler Handler
+}
+
+// NewReceiver returns a receiver for recipientDID that opens envelopes with
+// kemPriv (X25519 or P-256) and passes their plaintext to handler.
+func NewReceiver(recipientDID string, kemPriv crypto.PrivateKey, handler Handler) (*Receiver, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("handler is required")
+	}
+	priv, err := keys.ECDHPrivateKey(kemPriv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KEM private key: %w", err)
+	}
+	return &Receiver{did: recipientDID, priv: priv, handler: handler}, nil
+}
+
+// HandleMessage opens a forwarded envelope and dispatches it to the handler.
+func (r *Receiver) HandleMessage(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
+	sender, plaintext, err := open(msg, r.did, r.priv)
+	if err != nil {
+		return nil, err
+	}
+	ctx, _ = transport.EnsureRequestID(ctx, msg)
+	if err := r.handler(ctx, sender, plaintext); err != nil {
+		return nil, err
+	}
+	return &transport.Response{Success: true, MessageID: msg.ID, TaskID: msg.TaskID}, nil
+}
@@ -0,0 +1,107 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package relay
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"testing"
+
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	aliceDID = "did:sage:ethereum:0xalice"
+	bobDID   = "did:sage:ethereum:0xbob"
+)
+
+func TestSealOpen(t *testing.T) {
+	bob, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	eve, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	secret := []byte("meet at the usual place")
+	msg, err := Seal(aliceDID, bobDID, bob.PublicKey(), secret)
+	require.NoError(t, err)
+	assert.Equal(t, TaskRelayForward, msg.TaskID)
+	assert.Equal(t, bobDID, msg.Metadata[MetadataRecipient])
+	assert.False(t, bytes.Contains(msg.Payload, secret))
+
+	sender, plaintext, err := Open(msg, bobDID, bob)
+	require.NoError(t, err)
+	assert.Equal(t, aliceDID, sender)
+	assert.Equal(t, secret, plaintext)
+
+	t.Run("Other keys cannot open", func(t *testing.T) {
+		_, _, err := Open(msg, bobDID, eve)
+		assert.Error(t, err)
+	})
+
+	t.Run("Re-attributed sender is rejected", func(t *testing.T) {
+		forged := *msg
+		forged.DID = "did:sage:ethereum:0xmallory"
+		_, _, err := Open(&forged, bobDID, bob)
+		assert.Error(t, err)
+	})
+
+	t.Run("Re-addressed envelope is rejected", func(t *testing.T) {
+		_, _, err := Open(msg, "did:sage:ethereum:0xcarol", bob)
+		assert.ErrorIs(t, err, ErrNotForRecipient)
+	})
+}
+
+func TestServer_HandleMessage(t *testing.T) {
+	bob, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var delivered []byte
+	receiver, err := NewReceiver(bobDID, bob, func(_ context.Context, sender string, plaintext []byte) error {
+		assert.Equal(t, aliceDID, sender)
+		delivered = plaintext
+		return nil
+	})
+	require.NoError(t, err)
+
+	relay := NewServer()
+	relay.Register(bobDID, &transport.MockTransport{SendFunc: receiver.HandleMessage})
+
+	msg, err := Seal(aliceDID, bobDID, bob.PublicKey(), []byte("hello"))
+	require.NoError(t, err)
+	resp, err := relay.HandleMessage(ctx, msg)
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, "hello", string(delivered))
+
+	t.Run("Unknown recipients are refused", func(t *testing.T) {
+		relay.Unregister(bobDID)
+		_, err := relay.HandleMessage(ctx, msg)
+		assert.ErrorIs(t, err, ErrUnknownRecipient)
+	})
+
+	t.Run("Other tasks are refused", func(t *testing.T) {
+		_, err := relay.HandleMessage(ctx, &transport.SecureMessage{TaskID: "other"})
+		assert.Error(t, err)
+	})
+}
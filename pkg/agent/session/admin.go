@@ -0,0 +1,88 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+)
+
+// RevokeRequest is the body accepted by the handler from NewRevokeHandler
+type RevokeRequest struct {
+	DID string `json:"did"`
+	// Reason is informational; it travels with the request for audit proxies
+	Reason string `json:"reason,omitempty"`
+}
+
+// RevokeResponse reports how many sessions a revoke call closed
+type RevokeResponse struct {
+	DID     string `json:"did"`
+	Revoked int    `json:"revoked"`
+}
+
+// NewRevokeHandler returns an admin endpoint that calls RevokeSessionsByDID
+// for the DID in a POSTed RevokeRequest. Callers must present the token as a
+// bearer credential; an empty token is refused so the kill switch is never
+// exposed unauthenticated.
+func NewRevokeHandler(m *Manager, token string) (http.Handler, error) {
+	if m == nil {
+		return nil, fmt.Errorf("session manager is required")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("admin token is required")
+	}
+	return &revokeHandler{manager: m, token: []byte(token)}, nil
+}
+
+type revokeHandler struct {
+	manager *Manager
+	token   []byte
+}
+
+func (h *revokeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	auth := r.Header.Get("Authorization")
+	presented, ok := strings.CutPrefix(auth, "Bearer ")
+	if !ok || !sagecrypto.SecureCompare([]byte(presented), h.token) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req RevokeRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.DID == "" {
+		http.Error(w, "did is required", http.StatusBadRequest)
+		return
+	}
+
+	n := h.manager.RevokeSessionsByDID(req.DID)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(RevokeResponse{DID: req.DID, Revoked: n})
+}
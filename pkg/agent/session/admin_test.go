@@ -0,0 +1,70 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package session
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokeHandler(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Close() }()
+
+	const peer = "did:sage:ethereum:0xpeer"
+	_, sid, _, err := mgr.EnsureAndBindFromExporterWithRole(rb(32), "", false, "kid-1", nil)
+	require.NoError(t, err)
+	mgr.BindDID(peer, sid)
+
+	_, err = NewRevokeHandler(mgr, "")
+	require.Error(t, err)
+	h, err := NewRevokeHandler(mgr, "s3cret")
+	require.NoError(t, err)
+
+	do := func(method, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/sessions/revoke", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.Equal(t, http.StatusMethodNotAllowed, do(http.MethodGet, "s3cret", "").Code)
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "", `{"did":"`+peer+`"}`).Code)
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "wrong", `{"did":"`+peer+`"}`).Code)
+	require.Equal(t, http.StatusBadRequest, do(http.MethodPost, "s3cret", `{}`).Code)
+	require.Equal(t, 1, mgr.GetSessionCount())
+
+	rec := do(http.MethodPost, "s3cret", `{"did":"`+peer+`","reason":"key leaked"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp RevokeResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, RevokeResponse{DID: peer, Revoked: 1}, resp)
+	require.Zero(t, mgr.GetSessionCount())
+
+	_, err = mgr.LookupByKeyID("kid-1")
+	require.ErrorIs(t, err, ErrSessionRevoked)
+}
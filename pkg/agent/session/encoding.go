@@ -0,0 +1,54 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package session
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// Encoding is the base64 variant for session-layer binary values carried as
+// strings: session IDs, salts and the HPKE handshake fields. It is unpadded
+// base64url so the values are safe in URLs, JSON and HTTP headers alike.
+//
+// HTTP structured-field byte sequences (signatures, Content-Digest) use
+// rfc9421.ByteSequenceEncoding instead, as RFC 8941 requires.
+var Encoding = base64.RawURLEncoding
+
+// ErrMixedBase64 is returned by DecodeBase64 for input that mixes the
+// base64url and standard alphabets.
+var ErrMixedBase64 = errors.New("base64 input mixes url and standard alphabets")
+
+// DecodeBase64 decodes a value written with Encoding. It also accepts padded
+// input and the standard alphabet, so a peer that emits base64std still
+// interoperates; only a mix of both alphabets is rejected.
+func DecodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	url := strings.ContainsAny(s, "-_")
+	std := strings.ContainsAny(s, "+/")
+	switch {
+	case url && std:
+		return nil, ErrMixedBase64
+	case std:
+		return base64.RawStdEncoding.DecodeString(s)
+	default:
+		return Encoding.DecodeString(s)
+	}
+}
@@ -0,0 +1,99 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package session
+
+import (
+	"encoding/base64"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeBase64(t *testing.T) {
+	// 0xfb 0xff 0xbf encodes to "+/+/" in std and "-_-_" in url.
+	raw := []byte{0xfb, 0xff, 0xbf, 0x01}
+	for name, in := range map[string]string{
+		"raw url": base64.RawURLEncoding.EncodeToString(raw),
+		"url":     base64.URLEncoding.EncodeToString(raw),
+		"raw std": base64.RawStdEncoding.EncodeToString(raw),
+		"std":     base64.StdEncoding.EncodeToString(raw),
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := DecodeBase64(in)
+			require.NoError(t, err)
+			assert.Equal(t, raw, got)
+		})
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		got, err := DecodeBase64(Encoding.EncodeToString(raw))
+		require.NoError(t, err)
+		assert.Equal(t, raw, got)
+	})
+
+	t.Run("mixed alphabets", func(t *testing.T) {
+		_, err := DecodeBase64("-_+/")
+		assert.ErrorIs(t, err, ErrMixedBase64)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := DecodeBase64("not base64!")
+		assert.Error(t, err)
+	})
+}
+
+// TestEncoding_NoAdHocBase64 keeps the session, HPKE and RFC 9421 code on
+// the shared encodings: session.Encoding / DecodeBase64 for session values
+// and rfc9421.ByteSequenceEncoding for structured-field byte sequences.
+// Only each package's encoding.go may name a base64 variant directly.
+func TestEncoding_NoAdHocBase64(t *testing.T) {
+	dirs := []string{".", "../hpke", "../core/rfc9421"}
+
+	for _, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		require.NoError(t, err)
+		require.NotEmpty(t, files, dir)
+
+		fset := token.NewFileSet()
+		for _, file := range files {
+			if strings.HasSuffix(file, "_test.go") || filepath.Base(file) == "encoding.go" {
+				continue
+			}
+			f, err := parser.ParseFile(fset, file, nil, 0)
+			require.NoError(t, err)
+			ast.Inspect(f, func(n ast.Node) bool {
+				sel, ok := n.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				pkg, ok := sel.X.(*ast.Ident)
+				if ok && pkg.Name == "base64" && strings.HasSuffix(sel.Sel.Name, "Encoding") {
+					t.Errorf("%s: use session.Encoding or rfc9421.ByteSequenceEncoding instead of base64.%s", fset.Position(sel.Pos()), sel.Sel.Name)
+				}
+				return true
+			})
+		}
+	}
+}
@@ -0,0 +1,405 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/sage-x-project/sage/internal/metrics"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// MasterKeySize is the length of the key that wraps exported sessions.
+const MasterKeySize = chacha20poly1305.KeySize
+
+// exportVersion is the leading byte of an exported session:
+// version(1) || master key id(8) || nonce(12) || ciphertext+tag
+const exportVersion byte = 0x01
+
+const masterKeyIDSize = 8
+
+var (
+	// ErrNoMasterKey is returned by ExportSession and ImportSession before a
+	// master key has been configured.
+	ErrNoMasterKey = errors.New("session master key not configured")
+	// ErrUnknownMasterKey is returned when an exported session was wrapped
+	// under a key that is neither current nor within its grace period.
+	ErrUnknownMasterKey = errors.New("session export wrapped under unknown or retired master key")
+	// ErrMalformedExport is returned for exported data that cannot be unwrapped.
+	ErrMalformedExport = errors.New("malformed session export")
+)
+
+// masterKey is a key that wraps exported sessions. Retired keys carry the
+// end of their grace period.
+type masterKey struct {
+	id    [masterKeyIDSize]byte
+	key   []byte
+	until time.Time
+}
+
+// exportedSession is the state needed to resume a session on another
+// manager, including its send counter so no nonce is ever reused.
+type exportedSession struct {
+	ID           string    `json:"id"`
+	Seed         []byte    `json:"seed"`
+	Directional  bool      `json:"directional"`
+	Initiator    bool      `json:"initiator"`
+	Config       Config    `json:"config"`
+	CreatedAt    time.Time `json:"createdAt"`
+	LastUsedAt   time.Time `json:"lastUsedAt"`
+	MessageCount int       `json:"messageCount"`
+	NonceIV      []byte    `json:"nonceIv,omitempty"`
+	SendCounter  uint64    `json:"sendCounter"`
+	RecvMax      uint64    `json:"recvMax"`
+	RecvSeen     uint64    `json:"recvSeen"`
+	RecvValid    bool      `json:"recvValid"`
+	KeyIDs       []string  `json:"keyIds,omitempty"`
+	PeerDID      string    `json:"peerDid,omitempty"`
+	ContextID    string    `json:"contextId,omitempty"`
+}
+
+func newMasterKey(key []byte) (masterKey, error) {
+	if len(key) != MasterKeySize {
+		return masterKey{}, fmt.Errorf("master key must be %d bytes, got %d", MasterKeySize, len(key))
+	}
+	mk := masterKey{key: append([]byte(nil), key...)}
+	sum := sha256.Sum256(key)
+	copy(mk.id[:], sum[:masterKeyIDSize])
+	return mk, nil
+}
+
+// clone returns a copy of k whose key outlives rotation and Close, which
+// zero the manager's copy.
+func (k *masterKey) clone() *masterKey {
+	if k == nil {
+		return nil
+	}
+	c := *k
+	c.key = append([]byte(nil), k.key...)
+	return &c
+}
+
+// SetMasterKey configures the key that wraps exported sessions, replacing any
+// previous key without a grace period. Use RotateMasterKey to replace a key
+// that may still wrap outstanding exports.
+func (m *Manager) SetMasterKey(key []byte) error {
+	mk, err := newMasterKey(key)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.masterKey != nil {
+		zeroBytes(m.masterKey.key)
+	}
+	m.masterKey = &mk
+	return nil
+}
+
+// RotateMasterKey makes newKey the key for all new exports. Live sessions are
+// wrapped at export time, so every session exported from now on is wrapped
+// under newKey; exports already handed out under the previous key can still
+// be imported (or moved to newKey with RewrapExport) for a grace period of
+// the manager's default MaxAge, after which any session they hold would have
+// expired anyway. Without a current key this is the same as SetMasterKey.
+func (m *Manager) RotateMasterKey(newKey []byte) error {
+	mk, err := newMasterKey(newKey)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.pruneRetiredKeysLocked(now)
+	if m.masterKey != nil && m.masterKey.id != mk.id {
+		old := *m.masterKey
+		old.until = now.Add(m.defaultConfig.MaxAge)
+		m.retiredKeys = append(m.retiredKeys, old)
+	}
+	m.masterKey = &mk
+	return nil
+}
+
+// pruneRetiredKeysLocked forgets retired keys whose grace period has ended.
+// Caller must hold m.mu.
+func (m *Manager) pruneRetiredKeysLocked(now time.Time) {
+	kept := m.retiredKeys[:0]
+	for _, k := range m.retiredKeys {
+		if now.Before(k.until) {
+			kept = append(kept, k)
+		} else {
+			zeroBytes(k.key)
+		}
+	}
+	m.retiredKeys = kept
+}
+
+// ExportSession returns the state of a live session wrapped under the current
+// master key, for ImportSession on a manager that shares the key (e.g. after
+// a restart or on another replica). The export includes the session's key
+// material, its keyid and peer DID bindings, and its nonce counter.
+//
+// Stop using the session on this manager once it is exported: both copies
+// would seal with the same nonces.
+func (m *Manager) ExportSession(sessionID string) ([]byte, error) {
+	m.mu.RLock()
+	mk := m.masterKey.clone()
+	sess, ok := m.sessions[sessionID]
+	var kids []string
+	for kid := range m.keyIDsBySID[sessionID] {
+		kids = append(kids, kid)
+	}
+	did := m.didBySID[sessionID]
+	ctxID := m.ctxIDBySID[sessionID]
+	m.mu.RUnlock()
+
+	if mk == nil {
+		return nil, ErrNoMasterKey
+	}
+	defer zeroBytes(mk.key)
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	secSess, ok := sess.(*SecureSession)
+	if !ok {
+		return nil, fmt.Errorf("session %s of type %T cannot be exported", sessionID, sess)
+	}
+	st, err := secSess.exportState()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(kids)
+	st.KeyIDs = kids
+	st.PeerDID = did
+	st.ContextID = ctxID
+
+	plaintext, err := json.Marshal(st)
+	if err != nil {
+		return nil, fmt.Errorf("encode session: %w", err)
+	}
+	defer zeroBytes(plaintext)
+	return wrapExport(mk, plaintext)
+}
+
+// ImportSession restores a session from ExportSession output wrapped under
+// the current master key or a retired key still in its grace period, and
+// rebinds its keyids, peer DID and context ID. It fails if the session ID is already in
+// use or the session has expired.
+func (m *Manager) ImportSession(data []byte) (Session, error) {
+	plaintext, err := m.unwrapExport(data)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(plaintext)
+
+	var st exportedSession
+	if err := json.Unmarshal(plaintext, &st); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedExport, err)
+	}
+	sess, err := restoreSession(&st)
+	if err != nil {
+		return nil, err
+	}
+	if sess.IsExpired() {
+		reason := sess.endReason()
+		_ = sess.Close()
+		return nil, reason
+	}
+
+	m.mu.Lock()
+	if _, exists := m.sessions[st.ID]; exists {
+		m.mu.Unlock()
+		_ = sess.Close()
+		metrics.SessionsCreated.WithLabelValues("failure").Inc()
+		return nil, fmt.Errorf("session %s already exists", st.ID)
+	}
+	m.sessions[st.ID] = sess
+	metrics.SessionsCreated.WithLabelValues("success").Inc()
+	metrics.SessionsActive.Inc()
+	evicted := m.enforceMaxSessionsLocked(st.ID)
+	m.mu.Unlock()
+	m.notifyEvicted(evicted)
+
+	for _, kid := range st.KeyIDs {
+		m.BindKeyID(kid, st.ID)
+	}
+	m.BindDID(st.PeerDID, st.ID)
+	m.BindContextID(st.ContextID, st.ID)
+	return sess, nil
+}
+
+// RewrapExport re-wraps an exported session under the current master key, so
+// exports stored outside the manager outlive the grace period of the key
+// they were made with.
+func (m *Manager) RewrapExport(data []byte) ([]byte, error) {
+	plaintext, err := m.unwrapExport(data)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(plaintext)
+
+	m.mu.RLock()
+	mk := m.masterKey.clone()
+	m.mu.RUnlock()
+	if mk == nil {
+		return nil, ErrNoMasterKey
+	}
+	defer zeroBytes(mk.key)
+	return wrapExport(mk, plaintext)
+}
+
+// unwrapExport opens data with the master key it names.
+func (m *Manager) unwrapExport(data []byte) ([]byte, error) {
+	headerSize := 1 + masterKeyIDSize
+	if len(data) < headerSize+chacha20poly1305.NonceSize+chacha20poly1305.Overhead {
+		return nil, ErrMalformedExport
+	}
+	if data[0] != exportVersion {
+		return nil, fmt.Errorf("%w: version 0x%02x", ErrMalformedExport, data[0])
+	}
+	var id [masterKeyIDSize]byte
+	copy(id[:], data[1:headerSize])
+
+	m.mu.Lock()
+	if m.masterKey == nil {
+		m.mu.Unlock()
+		return nil, ErrNoMasterKey
+	}
+	m.pruneRetiredKeysLocked(time.Now())
+	var mk *masterKey
+	if m.masterKey.id == id {
+		mk = m.masterKey.clone()
+	}
+	for i := range m.retiredKeys {
+		if mk == nil && m.retiredKeys[i].id == id {
+			mk = m.retiredKeys[i].clone()
+		}
+	}
+	m.mu.Unlock()
+	if mk == nil {
+		return nil, ErrUnknownMasterKey
+	}
+	defer zeroBytes(mk.key)
+	key := mk.key
+
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := data[headerSize : headerSize+chacha20poly1305.NonceSize]
+	plaintext, err := aead.Open(nil, nonce, data[headerSize+chacha20poly1305.NonceSize:], data[:headerSize])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedExport, err)
+	}
+	return plaintext, nil
+}
+
+// wrapExport seals plaintext under mk, binding the header as AAD.
+func wrapExport(mk *masterKey, plaintext []byte) ([]byte, error) {
+	if mk == nil {
+		return nil, ErrNoMasterKey
+	}
+	aead, err := chacha20poly1305.New(mk.key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 1+masterKeyIDSize+chacha20poly1305.NonceSize, 1+masterKeyIDSize+chacha20poly1305.NonceSize+len(plaintext)+chacha20poly1305.Overhead)
+	out[0] = exportVersion
+	copy(out[1:], mk.id[:])
+	nonce := out[1+masterKeyIDSize:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return aead.Seal(out, nonce, plaintext, out[:1+masterKeyIDSize]), nil
+}
+
+// exportState captures the state ExportSession serializes.
+func (s *SecureSession) exportState() (*exportedSession, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil, ErrSessionExpired
+	}
+	st := &exportedSession{
+		ID:           s.id,
+		Seed:         append([]byte(nil), s.sessionSeed...),
+		Directional:  s.aeadOut != nil,
+		Initiator:    s.initiator,
+		Config:       s.config,
+		CreatedAt:    s.createdAt,
+		LastUsedAt:   s.lastUsedAt,
+		MessageCount: s.messageCount,
+		SendCounter:  s.sendCounter,
+		RecvMax:      s.recvWindow.max,
+		RecvSeen:     s.recvWindow.seen,
+		RecvValid:    s.recvWindow.valid,
+	}
+	if s.nonceIVSet {
+		st.NonceIV = append([]byte(nil), s.nonceIV[:]...)
+	}
+	return st, nil
+}
+
+// restoreSession rebuilds a session from exported state.
+func restoreSession(st *exportedSession) (*SecureSession, error) {
+	if st.ID == "" || len(st.Seed) == 0 {
+		return nil, ErrMalformedExport
+	}
+	var (
+		sess *SecureSession
+		err  error
+	)
+	if st.Directional {
+		sess, err = NewSecureSessionFromExporterWithRole(st.ID, st.Seed, st.Initiator, st.Config)
+	} else {
+		sess, err = NewSecureSession(st.ID, append([]byte(nil), st.Seed...), st.Config)
+	}
+	zeroBytes(st.Seed)
+	if err != nil {
+		return nil, fmt.Errorf("restore session: %w", err)
+	}
+	sess.createdAt = st.CreatedAt
+	sess.lastUsedAt = st.LastUsedAt
+	sess.messageCount = st.MessageCount
+	sess.sendCounter = st.SendCounter
+	sess.recvWindow = replayWindow{max: st.RecvMax, seen: st.RecvSeen, valid: st.RecvValid}
+	if len(st.NonceIV) > 0 {
+		if len(st.NonceIV) != len(sess.nonceIV) {
+			_ = sess.Close()
+			return nil, ErrMalformedExport
+		}
+		copy(sess.nonceIV[:], st.NonceIV)
+		sess.nonceIVSet = true
+	}
+	return sess, nil
+}
+
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
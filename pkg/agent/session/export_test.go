@@ -0,0 +1,131 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+func TestManager_ExportImport(t *testing.T) {
+	exporter := rb(32)
+	mgr := NewManager()
+	defer func() { _ = mgr.Close() }()
+	_, sid, _, err := mgr.EnsureAndBindFromExporterWithRole(exporter, "", false, "kid-1", nil)
+	require.NoError(t, err)
+	peer, err := NewSecureSessionFromExporterWithRole(sid, exporter, true, Config{})
+	require.NoError(t, err)
+	mgr.BindDID("did:sage:ethereum:0xpeer", sid)
+	mgr.BindContextID("ctx-export", sid)
+
+	_, err = mgr.ExportSession(sid)
+	require.ErrorIs(t, err, ErrNoMasterKey)
+	require.Error(t, mgr.SetMasterKey(rb(16)))
+	require.NoError(t, mgr.SetMasterKey(rb(MasterKeySize)))
+
+	// Advance the send counter so the import must carry it over
+	sess, err := mgr.LookupByKeyID("kid-1")
+	require.NoError(t, err)
+	first, err := sess.(*SecureSession).EncryptOutbound([]byte("before export"))
+	require.NoError(t, err)
+
+	data, err := mgr.ExportSession(sid)
+	require.NoError(t, err)
+	mgr.RemoveSession(sid)
+
+	imported, err := mgr.ImportSession(data)
+	require.NoError(t, err)
+	require.Equal(t, sid, imported.GetID())
+	got, err := mgr.LookupByKeyID("kid-1")
+	require.NoError(t, err)
+	require.Equal(t, imported, got)
+	got, ok := mgr.GetByContextID("ctx-export")
+	require.True(t, ok, "context ID binding restored")
+	require.Equal(t, imported, got)
+	require.Equal(t, 1, mgr.RevokeSessionsByDID("did:sage:ethereum:0xpeer"), "DID binding restored")
+
+	// The restored session resumes the nonce sequence and talks to the peer
+	imported, err = mgr.ImportSession(data)
+	require.NoError(t, err)
+	second, err := imported.(*SecureSession).EncryptOutbound([]byte("after import"))
+	require.NoError(t, err)
+	nonce := func(b []byte) []byte { return b[FrameHeaderSize : FrameHeaderSize+chacha20poly1305.NonceSize] }
+	require.NotEqual(t, nonce(first), nonce(second))
+	pt, err := peer.DecryptInbound(second)
+	require.NoError(t, err)
+	require.Equal(t, "after import", string(pt))
+
+	t.Run("rejects duplicates and tampering", func(t *testing.T) {
+		_, err := mgr.ImportSession(data)
+		require.Error(t, err)
+
+		bad := append([]byte(nil), data...)
+		bad[len(bad)-1] ^= 1
+		mgr.RemoveSession(sid)
+		_, err = mgr.ImportSession(bad)
+		require.ErrorIs(t, err, ErrMalformedExport)
+	})
+}
+
+func TestManager_RotateMasterKey(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Close() }()
+	mgr.SetDefaultConfig(Config{MaxAge: time.Hour, IdleTimeout: 10 * time.Minute, MaxMessages: 1000})
+
+	oldKey, newKey := rb(MasterKeySize), rb(MasterKeySize)
+	require.NoError(t, mgr.SetMasterKey(oldKey))
+
+	_, sid, _, err := mgr.EnsureAndBindFromExporterWithRole(rb(32), "", false, "kid-1", nil)
+	require.NoError(t, err)
+	oldExport, err := mgr.ExportSession(sid)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.RotateMasterKey(newKey))
+
+	// New exports use the new key
+	newExport, err := mgr.ExportSession(sid)
+	require.NoError(t, err)
+	require.NotEqual(t, oldExport[:1+masterKeyIDSize], newExport[:1+masterKeyIDSize])
+
+	// The old key is still accepted during the grace period
+	mgr.RemoveSession(sid)
+	_, err = mgr.ImportSession(oldExport)
+	require.NoError(t, err)
+	_, err = mgr.LookupByKeyID("kid-1")
+	require.NoError(t, err)
+
+	rewrapped, err := mgr.RewrapExport(oldExport)
+	require.NoError(t, err)
+	require.Equal(t, newExport[:1+masterKeyIDSize], rewrapped[:1+masterKeyIDSize])
+
+	// Once the grace period ends only exports under the new key import
+	mgr.mu.Lock()
+	require.Len(t, mgr.retiredKeys, 1)
+	mgr.retiredKeys[0].until = time.Now().Add(-time.Second)
+	mgr.mu.Unlock()
+
+	mgr.RemoveSession(sid)
+	_, err = mgr.ImportSession(oldExport)
+	require.ErrorIs(t, err, ErrUnknownMasterKey)
+	_, err = mgr.ImportSession(rewrapped)
+	require.NoError(t, err)
+}
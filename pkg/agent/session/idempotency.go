@@ -0,0 +1,238 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+package session
+
+import (
+	"sync"
+	"time"
+)
+
+// IdempotencyKeyHeader is the request header a client uses to mark a
+// protected request as safe to retry with the same nonce. Signers should
+// cover it (RFC 9421 component "idempotency-key") so it cannot be stripped
+// or swapped in transit.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ReplayVerdict is the outcome of Manager.CheckReplay
+type ReplayVerdict int
+
+const (
+	// ReplayFresh means the nonce is new, or its idempotent request was
+	// abandoned: process the request, and for idempotent requests call
+	// RecordResponse with the result or AbandonRequest if it failed.
+	ReplayFresh ReplayVerdict = iota
+	// ReplayRejected means the nonce was already used by a request that was
+	// not marked idempotent with the same key: reject it as a replay.
+	ReplayRejected
+	// ReplayCached means this is a retry of an idempotent request whose
+	// response is available: send the cached response without reprocessing.
+	ReplayCached
+	// ReplayInProgress means the original idempotent request is still being
+	// processed: ask the client to retry later.
+	ReplayInProgress
+)
+
+// String returns the verdict name
+func (v ReplayVerdict) String() string {
+	switch v {
+	case ReplayFresh:
+		return "fresh"
+	case ReplayRejected:
+		return "rejected"
+	case ReplayCached:
+		return "cached"
+	case ReplayInProgress:
+		return "in-progress"
+	default:
+		return "unknown"
+	}
+}
+
+// IdempotentResponse is the response recorded for an idempotent request and
+// returned verbatim to its retries.
+type IdempotentResponse struct {
+	StatusCode int
+	Header     map[string][]string
+	Body       []byte
+}
+
+// idempotencyCache remembers, per (keyid, nonce), the idempotency key the
+// request was first seen with and its response once recorded.
+type idempotencyCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]map[string]*idempotencyEntry // keyid -> nonce -> entry
+}
+
+type idempotencyEntry struct {
+	key       string
+	response  *IdempotentResponse
+	abandoned bool // processing failed; a retry may run the request again
+	expiresAt time.Time
+}
+
+func newIdempotencyCache(ttl time.Duration) *idempotencyCache {
+	return &idempotencyCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]map[string]*idempotencyEntry),
+	}
+}
+
+// check classifies a request. seen is the plain replay guard and is only
+// consulted for nonces without a live idempotency entry.
+func (c *idempotencyCache) check(keyid, nonce, idemKey string, seen func(keyid, nonce string) bool) (ReplayVerdict, *IdempotentResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if entry := c.lookupLocked(keyid, nonce, now); entry != nil {
+		if idemKey == "" || entry.key != idemKey {
+			return ReplayRejected, nil
+		}
+		if entry.abandoned {
+			entry.abandoned = false
+			entry.expiresAt = now.Add(c.ttl)
+			return ReplayFresh, nil
+		}
+		if entry.response == nil {
+			return ReplayInProgress, nil
+		}
+		return ReplayCached, entry.response
+	}
+
+	if seen(keyid, nonce) {
+		return ReplayRejected, nil
+	}
+	if idemKey != "" && keyid != "" && nonce != "" {
+		c.pruneLocked(now)
+		byNonce, ok := c.entries[keyid]
+		if !ok {
+			byNonce = make(map[string]*idempotencyEntry)
+			c.entries[keyid] = byNonce
+		}
+		byNonce[nonce] = &idempotencyEntry{key: idemKey, expiresAt: now.Add(c.ttl)}
+	}
+	return ReplayFresh, nil
+}
+
+// record stores the response for a request previously classified as fresh
+// with the same idempotency key.
+func (c *idempotencyCache) record(keyid, nonce, idemKey string, resp *IdempotentResponse) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.lookupLocked(keyid, nonce, c.now())
+	if entry == nil || entry.key != idemKey || entry.response != nil || entry.abandoned {
+		return false
+	}
+	entry.response = resp
+	return true
+}
+
+// abandon marks an in-progress request as failed so its next retry with the
+// same idempotency key is processed again.
+func (c *idempotencyCache) abandon(keyid, nonce, idemKey string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.lookupLocked(keyid, nonce, c.now())
+	if entry == nil || entry.key != idemKey || entry.response != nil || entry.abandoned {
+		return false
+	}
+	entry.abandoned = true
+	return true
+}
+
+func (c *idempotencyCache) deleteKey(keyid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, keyid)
+}
+
+// lookupLocked returns the live entry for (keyid, nonce). Caller must hold c.mu.
+func (c *idempotencyCache) lookupLocked(keyid, nonce string, now time.Time) *idempotencyEntry {
+	entry, ok := c.entries[keyid][nonce]
+	if !ok {
+		return nil
+	}
+	if now.After(entry.expiresAt) {
+		delete(c.entries[keyid], nonce)
+		if len(c.entries[keyid]) == 0 {
+			delete(c.entries, keyid)
+		}
+		return nil
+	}
+	return entry
+}
+
+// pruneLocked drops expired entries. Caller must hold c.mu.
+func (c *idempotencyCache) pruneLocked(now time.Time) {
+	for keyid, byNonce := range c.entries {
+		for nonce, entry := range byNonce {
+			if now.After(entry.expiresAt) {
+				delete(byNonce, nonce)
+			}
+		}
+		if len(byNonce) == 0 {
+			delete(c.entries, keyid)
+		}
+	}
+}
+
+// CheckReplay applies the replay guard to an incoming request, letting
+// retries of idempotent requests through. idempotencyKey is the request's
+// Idempotency-Key header value ("" when absent).
+//
+// A nonce is normally accepted once. When the first request carrying it was
+// marked idempotent, later requests with the same (keyid, nonce) and the same
+// idempotency key are treated as retries and receive the recorded response
+// (ReplayCached) for the replay TTL; anything else reusing the nonce is
+// rejected.
+func (m *Manager) CheckReplay(keyid, nonce, idempotencyKey string) (ReplayVerdict, *IdempotentResponse) {
+	if m.idempotency == nil {
+		if m.ReplayGuardSeenOnce(keyid, nonce) {
+			return ReplayRejected, nil
+		}
+		return ReplayFresh, nil
+	}
+	return m.idempotency.check(keyid, nonce, idempotencyKey, m.ReplayGuardSeenOnce)
+}
+
+// RecordResponse stores the response of an idempotent request that
+// CheckReplay classified as ReplayFresh, so retries can be answered from
+// cache. It reports whether the response was recorded.
+func (m *Manager) RecordResponse(keyid, nonce, idempotencyKey string, resp *IdempotentResponse) bool {
+	if m.idempotency == nil || idempotencyKey == "" || resp == nil {
+		return false
+	}
+	return m.idempotency.record(keyid, nonce, idempotencyKey, resp)
+}
+
+// AbandonRequest releases an idempotent request that CheckReplay classified
+// as ReplayFresh but that failed without a response worth caching. Its next
+// retry with the same idempotency key is classified ReplayFresh again
+// instead of ReplayInProgress until the replay TTL runs out. The nonce stays
+// bound to that key. It reports whether the request was released.
+func (m *Manager) AbandonRequest(keyid, nonce, idempotencyKey string) bool {
+	if m.idempotency == nil || idempotencyKey == "" {
+		return false
+	}
+	return m.idempotency.abandon(keyid, nonce, idempotencyKey)
+}
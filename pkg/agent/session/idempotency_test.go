@@ -0,0 +1,171 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_CheckReplay_Idempotency(t *testing.T) {
+	newManager := func(t *testing.T) *Manager {
+		m := NewManager()
+		t.Cleanup(func() { _ = m.Close() })
+		return m
+	}
+	resp := &IdempotentResponse{
+		StatusCode: 201,
+		Header:     map[string][]string{"Content-Type": {"application/json"}},
+		Body:       []byte(`{"transfer":"t-1"}`),
+	}
+
+	t.Run("Retried idempotent request returns cached response", func(t *testing.T) {
+		m := newManager(t)
+
+		verdict, cached := m.CheckReplay("kid-1", "n-1", "idem-1")
+		require.Equal(t, ReplayFresh, verdict)
+		assert.Nil(t, cached)
+
+		// Retry before the handler finished
+		verdict, _ = m.CheckReplay("kid-1", "n-1", "idem-1")
+		assert.Equal(t, ReplayInProgress, verdict)
+
+		require.True(t, m.RecordResponse("kid-1", "n-1", "idem-1", resp))
+
+		verdict, cached = m.CheckReplay("kid-1", "n-1", "idem-1")
+		assert.Equal(t, ReplayCached, verdict)
+		assert.Equal(t, resp, cached)
+
+		// The first recorded response wins
+		assert.False(t, m.RecordResponse("kid-1", "n-1", "idem-1", &IdempotentResponse{StatusCode: 500}))
+	})
+
+	t.Run("Abandoned request can be retried", func(t *testing.T) {
+		m := newManager(t)
+
+		verdict, _ := m.CheckReplay("kid-1", "n-1", "idem-1")
+		require.Equal(t, ReplayFresh, verdict)
+		// The handler failed and has nothing to record
+		require.True(t, m.AbandonRequest("kid-1", "n-1", "idem-1"))
+		assert.False(t, m.AbandonRequest("kid-1", "n-1", "idem-1"))
+		assert.False(t, m.RecordResponse("kid-1", "n-1", "idem-1", resp))
+
+		// Other keys still cannot reuse the nonce
+		verdict, _ = m.CheckReplay("kid-1", "n-1", "idem-2")
+		assert.Equal(t, ReplayRejected, verdict)
+		verdict, _ = m.CheckReplay("kid-1", "n-1", "")
+		assert.Equal(t, ReplayRejected, verdict)
+
+		// The retry runs again and is in progress for concurrent retries
+		verdict, _ = m.CheckReplay("kid-1", "n-1", "idem-1")
+		require.Equal(t, ReplayFresh, verdict)
+		verdict, _ = m.CheckReplay("kid-1", "n-1", "idem-1")
+		assert.Equal(t, ReplayInProgress, verdict)
+
+		require.True(t, m.RecordResponse("kid-1", "n-1", "idem-1", resp))
+		verdict, cached := m.CheckReplay("kid-1", "n-1", "idem-1")
+		assert.Equal(t, ReplayCached, verdict)
+		assert.Equal(t, resp, cached)
+		assert.False(t, m.AbandonRequest("kid-1", "n-1", "idem-1"))
+	})
+
+	t.Run("Non-idempotent replay is rejected", func(t *testing.T) {
+		m := newManager(t)
+
+		verdict, _ := m.CheckReplay("kid-1", "n-1", "")
+		require.Equal(t, ReplayFresh, verdict)
+
+		verdict, cached := m.CheckReplay("kid-1", "n-1", "")
+		assert.Equal(t, ReplayRejected, verdict)
+		assert.Nil(t, cached)
+
+		// Adding an idempotency key to a replay does not launder it
+		verdict, _ = m.CheckReplay("kid-1", "n-1", "idem-1")
+		assert.Equal(t, ReplayRejected, verdict)
+		assert.False(t, m.RecordResponse("kid-1", "n-1", "idem-1", resp))
+	})
+
+	t.Run("Replay with a different idempotency key is rejected", func(t *testing.T) {
+		m := newManager(t)
+
+		_, _ = m.CheckReplay("kid-1", "n-1", "idem-1")
+		require.True(t, m.RecordResponse("kid-1", "n-1", "idem-1", resp))
+
+		verdict, _ := m.CheckReplay("kid-1", "n-1", "idem-2")
+		assert.Equal(t, ReplayRejected, verdict)
+		verdict, _ = m.CheckReplay("kid-1", "n-1", "")
+		assert.Equal(t, ReplayRejected, verdict)
+	})
+
+	t.Run("Entries are scoped to the key ID", func(t *testing.T) {
+		m := newManager(t)
+
+		_, _ = m.CheckReplay("kid-1", "n-1", "idem-1")
+		require.True(t, m.RecordResponse("kid-1", "n-1", "idem-1", resp))
+
+		verdict, _ := m.CheckReplay("kid-2", "n-1", "idem-1")
+		assert.Equal(t, ReplayFresh, verdict)
+	})
+
+	t.Run("Unbinding the key ID drops cached responses", func(t *testing.T) {
+		m := newManager(t)
+		_, err := m.CreateSession("sess-1", make([]byte, 32))
+		require.NoError(t, err)
+		m.BindKeyID("kid-1", "sess-1")
+
+		_, _ = m.CheckReplay("kid-1", "n-1", "idem-1")
+		require.True(t, m.RecordResponse("kid-1", "n-1", "idem-1", resp))
+		require.True(t, m.UnbindKeyID("kid-1"))
+
+		verdict, _ := m.CheckReplay("kid-1", "n-1", "idem-1")
+		assert.Equal(t, ReplayFresh, verdict)
+	})
+}
+
+func TestIdempotencyCache_TTL(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	c := newIdempotencyCache(time.Minute)
+	c.now = func() time.Time { return now }
+
+	seen := map[string]bool{}
+	guard := func(keyid, nonce string) bool {
+		k := keyid + "|" + nonce
+		if seen[k] {
+			return true
+		}
+		seen[k] = true
+		return false
+	}
+
+	verdict, _ := c.check("kid", "n", "idem", guard)
+	require.Equal(t, ReplayFresh, verdict)
+	require.True(t, c.record("kid", "n", "idem", &IdempotentResponse{StatusCode: 200}))
+
+	now = now.Add(30 * time.Second)
+	verdict, _ = c.check("kid", "n", "idem", guard)
+	assert.Equal(t, ReplayCached, verdict)
+
+	// Past the TTL the cached response is gone and the nonce guard decides
+	now = now.Add(time.Minute)
+	verdict, _ = c.check("kid", "n", "idem", guard)
+	assert.Equal(t, ReplayRejected, verdict)
+	assert.Empty(t, c.entries)
+}
@@ -31,12 +31,34 @@ type Manager struct {
 	sessions      map[string]Session
 	byKeyID       map[string]string
 	keyIDsBySID   map[string]map[string]struct{}
+	sidsByDID     map[string]map[string]struct{}
+	didBySID      map[string]string
+	sidByCtxID    map[string]string // handshake context ID -> session ID
+	ctxIDBySID    map[string]string
+	endedKIDs     map[string]endedKey // keyid -> why its session ended (tombstones)
+	tombstoneTTL  time.Duration       // how long endedKIDs entries are kept
 	mu            sync.RWMutex
 	cleanupTicker *time.Ticker
 	stopCleanup   chan struct{}
 	defaultConfig Config
-	nonceCache    *NonceCache // replay guard
-	sessionPool   sync.Pool   // Pool for session object reuse
+	nonceCache    *NonceCache       // replay guard
+	idempotency   *idempotencyCache // cached responses for idempotent retries
+	sessionPool   sync.Pool         // Pool for session object reuse
+	maxSessions   int               // session cap; <= 0 means unlimited
+	onEvict       func(sessionID string)
+	masterKey     *masterKey  // wraps exported sessions; see ExportSession
+	retiredKeys   []masterKey // previous master keys within their grace period
+}
+
+// DefaultTombstoneTTL is how long a manager remembers why a keyid's session
+// ended, see SetTombstoneTTL.
+const DefaultTombstoneTTL = time.Hour
+
+// endedKey is a tombstone for a keyid whose session has ended, so lookups can
+// tell a finished session from one that was never established.
+type endedKey struct {
+	reason error // ErrSessionExpired, ErrSessionExhausted or ErrSessionRevoked
+	at     time.Time
 }
 
 // NewManager creates a new session manager with default configuration
@@ -49,7 +71,9 @@ func NewManager() *Manager {
 			IdleTimeout: 10 * time.Minute, // 10-minute idle timeout
 			MaxMessages: 1000,
 		},
-		nonceCache: NewNonceCache(10 * time.Minute), // replay TTL
+		tombstoneTTL: DefaultTombstoneTTL,
+		nonceCache:   NewNonceCache(10 * time.Minute), // replay TTL
+		idempotency:  newIdempotencyCache(10 * time.Minute),
 		sessionPool: sync.Pool{
 			New: func() interface{} {
 				// Pre-allocate a session with keyMaterial buffer
@@ -124,7 +148,9 @@ func (m *Manager) EnsureSessionFromExporterWithRole(
 	m.sessions[sid] = s
 	metrics.SessionsCreated.WithLabelValues("success").Inc()
 	metrics.SessionsActive.Inc()
+	evicted := m.enforceMaxSessionsLocked(sid)
 	m.mu.Unlock()
+	m.notifyEvicted(evicted)
 
 	return s, sid, false, nil
 }
@@ -188,7 +214,11 @@ func (m *Manager) EnsureSessionWithParams(p Params, cfg *Config) (Session, strin
 	m.sessions[sid] = s
 	metrics.SessionsCreated.WithLabelValues("success").Inc()
 	metrics.SessionsActive.Inc()
+	evicted := m.enforceMaxSessionsLocked(sid)
 	m.mu.Unlock()
+	m.notifyEvicted(evicted)
+	m.BindContextID(p.ContextID, sid)
+	m.BindDID(p.PeerDID, sid)
 
 	return s, sid, false, nil
 }
@@ -197,7 +227,11 @@ func (m *Manager) EnsureSessionWithParams(p Params, cfg *Config) (Session, strin
 // Uses session pool to reduce GC pressure
 func (m *Manager) CreateSessionWithConfig(sessionID string, sharedSecret []byte, config Config) (Session, error) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	var evicted []string
+	defer func() {
+		m.mu.Unlock()
+		m.notifyEvicted(evicted)
+	}()
 
 	// Check if session already exists
 	if _, exists := m.sessions[sessionID]; exists {
@@ -221,6 +255,7 @@ func (m *Manager) CreateSessionWithConfig(sessionID string, sharedSecret []byte,
 	m.sessions[sessionID] = sess
 	metrics.SessionsCreated.WithLabelValues("success").Inc()
 	metrics.SessionsActive.Inc()
+	evicted = m.enforceMaxSessionsLocked(sessionID)
 
 	return sess, nil
 }
@@ -235,6 +270,7 @@ func (m *Manager) BindKeyID(keyid, sid string) {
 		m.keyIDsBySID = make(map[string]map[string]struct{})
 	}
 	m.byKeyID[keyid] = sid
+	delete(m.endedKIDs, keyid)
 	set, ok := m.keyIDsBySID[sid]
 	if !ok {
 		set = make(map[string]struct{})
@@ -263,13 +299,104 @@ func (m *Manager) UnbindKeyID(keyid string) bool {
 	if m.nonceCache != nil {
 		m.nonceCache.DeleteKey(keyid)
 	}
+	if m.idempotency != nil {
+		m.idempotency.deleteKey(keyid)
+	}
 	return true
 }
 
+// BindDID associates the authenticated peer DID with an existing session ID so
+// that all of the peer's sessions can later be revoked at once.
+func (m *Manager) BindDID(did, sid string) {
+	if did == "" || sid == "" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sidsByDID == nil {
+		m.sidsByDID = make(map[string]map[string]struct{})
+	}
+	if m.didBySID == nil {
+		m.didBySID = make(map[string]string)
+	}
+	if prev, ok := m.didBySID[sid]; ok && prev != did {
+		m.unbindDIDLocked(sid)
+	}
+	set, ok := m.sidsByDID[did]
+	if !ok {
+		set = make(map[string]struct{})
+		m.sidsByDID[did] = set
+	}
+	set[sid] = struct{}{}
+	m.didBySID[sid] = did
+}
+
+// BindContextID associates the handshake context ID that produced a session
+// with its session ID, so tooling that only knows the context can find the
+// session. A context maps to one session: binding it again, as a resumed
+// handshake does, moves it to the new session.
+func (m *Manager) BindContextID(ctxID, sid string) {
+	if ctxID == "" || sid == "" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sidByCtxID == nil {
+		m.sidByCtxID = make(map[string]string)
+	}
+	if m.ctxIDBySID == nil {
+		m.ctxIDBySID = make(map[string]string)
+	}
+	if prev, ok := m.sidByCtxID[ctxID]; ok && prev != sid {
+		delete(m.ctxIDBySID, prev)
+	}
+	m.unbindContextIDLocked(sid)
+	m.sidByCtxID[ctxID] = sid
+	m.ctxIDBySID[sid] = ctxID
+}
+
+// RevokeSessionsByDID forcibly closes every session bound to the given DID
+// (kill switch for compromised client keys) and returns how many sessions were
+// killed. Key IDs of revoked sessions are remembered so that lookups report
+// ErrSessionRevoked instead of a plain miss until the peer re-handshakes.
+func (m *Manager) RevokeSessionsByDID(did string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set, ok := m.sidsByDID[did]
+	if !ok {
+		return 0
+	}
+	now := time.Now()
+	killed := 0
+	for sid := range set {
+		m.markEndedLocked(sid, ErrSessionRevoked, now)
+		if m.removeSessionLocked(sid) {
+			killed++
+		}
+	}
+	delete(m.sidsByDID, did)
+	if killed > 0 {
+		metrics.SessionsRevoked.Add(float64(killed))
+	}
+	return killed
+}
+
 // GetByKeyID returns the Session associated with the given keyid (if alive).
+// Ended sessions are never returned; use LookupByKeyID to learn why.
 func (m *Manager) GetByKeyID(keyid string) (Session, bool) {
+	sess, err := m.LookupByKeyID(keyid)
+	if err != nil {
+		return nil, false
+	}
+	return sess, true
+}
+
+// GetByContextID returns the live Session established by the handshake with
+// the given context ID. Like GetByKeyID it never returns ended sessions.
+func (m *Manager) GetByContextID(ctxID string) (Session, bool) {
 	m.mu.RLock()
-	sid, ok := m.byKeyID[keyid]
+	sid, ok := m.sidByCtxID[ctxID]
 	m.mu.RUnlock()
 	if !ok {
 		return nil, false
@@ -277,6 +404,36 @@ func (m *Manager) GetByKeyID(keyid string) (Session, bool) {
 	return m.GetSession(sid)
 }
 
+// LookupByKeyID returns the Session associated with the given keyid. When
+// there is no live session the error says why: ErrSessionRevoked if it was
+// killed by RevokeSessionsByDID, ErrSessionExpired if it outlived MaxAge or
+// IdleTimeout, ErrSessionExhausted if it reached MaxMessages, and
+// ErrSessionNotFound if no session was ever established for the keyid.
+func (m *Manager) LookupByKeyID(keyid string) (Session, error) {
+	m.mu.RLock()
+	ended, hasEnded := m.endedKIDs[keyid]
+	sid, ok := m.byKeyID[keyid]
+	m.mu.RUnlock()
+	if hasEnded {
+		return nil, ended.reason
+	}
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	sess, ok := m.GetSession(sid)
+	if !ok {
+		// GetSession leaves a tombstone when it drops an expired session
+		m.mu.RLock()
+		ended, hasEnded = m.endedKIDs[keyid]
+		m.mu.RUnlock()
+		if hasEnded {
+			return nil, ended.reason
+		}
+		return nil, ErrSessionNotFound
+	}
+	return sess, nil
+}
+
 // GetSession retrieves a session by ID, returns nil if not found or expired
 func (m *Manager) GetSession(sessionID string) (Session, bool) {
 	m.mu.RLock()
@@ -289,7 +446,11 @@ func (m *Manager) GetSession(sessionID string) (Session, bool) {
 
 	if sess.IsExpired() {
 		// Remove expired session
-		m.RemoveSession(sessionID)
+		m.mu.Lock()
+		if cur, ok := m.sessions[sessionID]; ok {
+			m.expireSessionLocked(sessionID, cur)
+		}
+		m.mu.Unlock()
 		return nil, false
 	}
 
@@ -301,8 +462,14 @@ func (m *Manager) GetSession(sessionID string) (Session, bool) {
 func (m *Manager) RemoveSession(sessionID string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.removeSessionLocked(sessionID)
+}
 
-	if sess, exists := m.sessions[sessionID]; exists {
+// removeSessionLocked closes and drops a session together with its keyid,
+// DID and context ID bindings. Caller must hold m.mu. Reports whether a session was removed.
+func (m *Manager) removeSessionLocked(sessionID string) bool {
+	sess, exists := m.sessions[sessionID]
+	if exists {
 		if err := sess.Close(); err != nil {
 			fmt.Printf("Warning: error closing session %s: %v\n", sessionID, err)
 		}
@@ -315,18 +482,83 @@ func (m *Manager) RemoveSession(sessionID string) {
 			m.sessionPool.Put(secSess)
 		}
 	}
-	// Unbind all keyids mapped to this sessionID
+	m.unbindKeyIDsLocked(sessionID)
+	m.unbindDIDLocked(sessionID)
+	m.unbindContextIDLocked(sessionID)
+	return exists
+}
+
+// expireSessionLocked drops an expired session, remembering for each of its
+// keyids whether it timed out or used up its message budget. Caller must hold
+// m.mu.
+func (m *Manager) expireSessionLocked(sessionID string, sess Session) {
+	reason := ErrSessionExpired
+	if secSess, ok := sess.(*SecureSession); ok {
+		reason = secSess.endReason()
+	}
+	m.markEndedLocked(sessionID, reason, time.Now())
+	m.removeSessionLocked(sessionID)
+}
+
+// markEndedLocked records a tombstone with the given reason for every keyid
+// bound to sessionID. Caller must hold m.mu.
+func (m *Manager) markEndedLocked(sessionID string, reason error, at time.Time) {
+	set := m.keyIDsBySID[sessionID]
+	if len(set) == 0 {
+		return
+	}
+	if m.endedKIDs == nil {
+		m.endedKIDs = make(map[string]endedKey)
+	}
+	for kid := range set {
+		m.endedKIDs[kid] = endedKey{reason: reason, at: at}
+	}
+}
+
+// unbindKeyIDsLocked removes all keyids mapped to sessionID. Caller must hold m.mu.
+func (m *Manager) unbindKeyIDsLocked(sessionID string) {
 	if set, ok := m.keyIDsBySID[sessionID]; ok {
 		for kid := range set {
 			delete(m.byKeyID, kid)
 			if m.nonceCache != nil {
 				m.nonceCache.DeleteKey(kid)
 			}
+			if m.idempotency != nil {
+				m.idempotency.deleteKey(kid)
+			}
 		}
 		delete(m.keyIDsBySID, sessionID)
 	}
 }
 
+// unbindDIDLocked removes the DID binding of sessionID. Caller must hold m.mu.
+func (m *Manager) unbindDIDLocked(sessionID string) {
+	did, ok := m.didBySID[sessionID]
+	if !ok {
+		return
+	}
+	delete(m.didBySID, sessionID)
+	if set, ok := m.sidsByDID[did]; ok {
+		delete(set, sessionID)
+		if len(set) == 0 {
+			delete(m.sidsByDID, did)
+		}
+	}
+}
+
+// unbindContextIDLocked removes the context ID binding of sessionID. Caller
+// must hold m.mu.
+func (m *Manager) unbindContextIDLocked(sessionID string) {
+	ctxID, ok := m.ctxIDBySID[sessionID]
+	if !ok {
+		return
+	}
+	delete(m.ctxIDBySID, sessionID)
+	if m.sidByCtxID[ctxID] == sessionID {
+		delete(m.sidByCtxID, ctxID)
+	}
+}
+
 // ListSessions returns all active session IDs
 func (m *Manager) ListSessions() []string {
 	m.mu.RLock()
@@ -378,11 +610,134 @@ func (m *Manager) GetSessionStats() Status {
 	return stats
 }
 
-// SetDefaultConfig updates the default session configuration
+// SetDefaultConfig updates the default session configuration. It only applies
+// to sessions created afterwards; use ApplyConfigToExisting to move live
+// sessions onto the new policy.
 func (m *Manager) SetDefaultConfig(config Config) {
 	m.defaultConfig = config
 }
 
+// GetDefaultConfig returns the configuration applied to new sessions
+func (m *Manager) GetDefaultConfig() Config {
+	return m.defaultConfig
+}
+
+// SetMaxSessions caps the number of sessions the manager holds, bounding
+// memory under a flood of handshakes regardless of idle and age timeouts.
+// When a new session would exceed the cap, the least recently used sessions
+// (by LastUsedAt) are closed and removed; see SetEvictionHandler. The cap is
+// applied to existing sessions immediately. n <= 0 removes the cap.
+func (m *Manager) SetMaxSessions(n int) {
+	m.mu.Lock()
+	m.maxSessions = n
+	evicted := m.enforceMaxSessionsLocked("")
+	m.mu.Unlock()
+	m.notifyEvicted(evicted)
+}
+
+// SetTombstoneTTL sets how long LookupByKeyID keeps reporting why a keyid's
+// session ended (ErrSessionExpired, ErrSessionExhausted or ErrSessionRevoked)
+// before treating the keyid as unknown. It is independent of session
+// lifetimes; peers that keep retrying a revoked key for longer should get a
+// longer TTL. d <= 0 restores DefaultTombstoneTTL.
+func (m *Manager) SetTombstoneTTL(d time.Duration) {
+	if d <= 0 {
+		d = DefaultTombstoneTTL
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tombstoneTTL = d
+}
+
+// SetEvictionHandler registers fn to be called with the ID of every session
+// evicted by the session cap. It runs after the manager lock is released,
+// so it may call back into the manager. Passing nil removes the handler.
+func (m *Manager) SetEvictionHandler(fn func(sessionID string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onEvict = fn
+}
+
+// enforceMaxSessionsLocked evicts least recently used sessions, never keep,
+// until the cap holds, and returns the evicted IDs. Each eviction scans all
+// sessions, which only happens while the manager is at its cap. Caller must
+// hold m.mu.
+func (m *Manager) enforceMaxSessionsLocked(keep string) []string {
+	if m.maxSessions <= 0 {
+		return nil
+	}
+	var evicted []string
+	for len(m.sessions) > m.maxSessions {
+		victim := ""
+		var oldest time.Time
+		for sid, sess := range m.sessions {
+			if sid == keep {
+				continue
+			}
+			if used := sess.GetLastUsedAt(); victim == "" || used.Before(oldest) {
+				victim, oldest = sid, used
+			}
+		}
+		if victim == "" {
+			break
+		}
+		m.removeSessionLocked(victim)
+		metrics.SessionsEvicted.Inc()
+		evicted = append(evicted, victim)
+	}
+	return evicted
+}
+
+// notifyEvicted reports evicted sessions to the eviction handler. It must be
+// called without holding m.mu.
+func (m *Manager) notifyEvicted(evicted []string) {
+	if len(evicted) == 0 {
+		return
+	}
+	m.mu.RLock()
+	fn := m.onEvict
+	m.mu.RUnlock()
+	if fn == nil {
+		return
+	}
+	for _, sid := range evicted {
+		fn(sid)
+	}
+}
+
+// ApplyConfigToExisting updates the policy limits of every live session for
+// which selector returns true (all sessions if selector is nil) and returns
+// how many were updated, e.g. to shorten IdleTimeout for all sessions during
+// an incident. Sessions that become expired under cfg are evicted by the next
+// cleanup pass.
+//
+// Only the non-zero limits in cfg (MaxAge, IdleTimeout, MaxMessages) are
+// applied; a zero field leaves that limit of each session unchanged, so
+// Config{IdleTimeout: time.Minute} touches nothing else. SequenceNumbers and
+// ReorderWindow change the message framing both peers agreed on and are
+// ignored, as are keys, ciphers and the session's role, which are fixed by
+// the handshake.
+func (m *Manager) ApplyConfigToExisting(selector func(SessionStats) bool, cfg Config) int {
+	stats := m.SnapshotAll()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	updated := 0
+	for _, st := range stats {
+		if selector != nil && !selector(st) {
+			continue
+		}
+		sess, ok := m.sessions[st.SessionID].(*SecureSession)
+		if !ok {
+			continue
+		}
+		sess.applyLimits(cfg)
+		updated++
+	}
+	return updated
+}
+
 // Close stops the manager and cleans up all sessions and caches.
 func (m *Manager) Close() error {
 	close(m.stopCleanup)
@@ -403,6 +758,19 @@ func (m *Manager) Close() error {
 	m.sessions = make(map[string]Session)
 	m.byKeyID = nil
 	m.keyIDsBySID = nil
+	m.sidsByDID = nil
+	m.didBySID = nil
+	m.sidByCtxID = nil
+	m.ctxIDBySID = nil
+	m.endedKIDs = nil
+	if m.masterKey != nil {
+		zeroBytes(m.masterKey.key)
+		m.masterKey = nil
+	}
+	for _, k := range m.retiredKeys {
+		zeroBytes(k.key)
+	}
+	m.retiredKeys = nil
 	return nil
 }
 
@@ -430,29 +798,16 @@ func (m *Manager) cleanupExpiredSessions() {
 		}
 	}
 	for _, id := range expiredIDs {
-		if sess, exists := m.sessions[id]; exists {
-			if err := sess.Close(); err != nil {
-				fmt.Printf("Warning: error closing expired session %s: %v\n", id, err)
-			}
-			delete(m.sessions, id)
-			metrics.SessionsExpired.Inc()
-			metrics.SessionsActive.Dec()
-
-			// Return session to pool
-			if secSess, ok := sess.(*SecureSession); ok {
-				secSess.Reset()
-				m.sessionPool.Put(secSess)
-			}
-		}
-		// Unbind all keyids for this session
-		if set, ok := m.keyIDsBySID[id]; ok {
-			for kid := range set {
-				delete(m.byKeyID, kid)
-				if m.nonceCache != nil {
-					m.nonceCache.DeleteKey(kid)
-				}
-			}
-			delete(m.keyIDsBySID, id)
+		// Closes the session, returns it to the pool and unbinds its keyids
+		// and peer DID
+		m.expireSessionLocked(id, m.sessions[id])
+		metrics.SessionsExpired.Inc()
+	}
+
+	// Forget tombstones once their TTL has passed
+	for kid, ended := range m.endedKIDs {
+		if time.Since(ended.at) > m.tombstoneTTL {
+			delete(m.endedKIDs, kid)
 		}
 	}
 	if len(expiredIDs) > 0 {
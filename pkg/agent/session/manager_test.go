@@ -404,6 +404,323 @@ func TestManager_EnsureSessionWithParams_ErrorPaths(t *testing.T) {
 	})
 }
 
+func TestManager_EnsureSessionWithParams_BindsPeerDID(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Close() }()
+
+	const peer = "did:sage:ethereum:0xpeer"
+	p := Params{ContextID: "ctx-did", SelfEph: rb(32), PeerEph: rb(32), SharedSecret: rb(32), Label: "v"}
+	_, plainSID, _, err := mgr.EnsureSessionWithParams(p, nil)
+	require.NoError(t, err)
+
+	p.ContextID, p.PeerDID = "ctx-did-bound", peer
+	_, sid, _, err := mgr.EnsureSessionWithParams(p, nil)
+	require.NoError(t, err)
+	require.NotEqual(t, plainSID, sid)
+
+	require.Equal(t, 1, mgr.RevokeSessionsByDID(peer))
+	_, ok := mgr.GetSession(sid)
+	require.False(t, ok)
+	_, ok = mgr.GetSession(plainSID)
+	require.True(t, ok)
+}
+
+func TestManager_RevokeSessionsByDID(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Close() }()
+
+	const peer = "did:sage:ethereum:0xpeer"
+
+	_, sid1, _, err := mgr.EnsureAndBindFromExporterWithRole(rb(32), "", false, "kid-1", nil)
+	require.NoError(t, err)
+	_, sid2, _, err := mgr.EnsureAndBindFromExporterWithRole(rb(32), "", false, "kid-2", nil)
+	require.NoError(t, err)
+	_, sid3, _, err := mgr.EnsureAndBindFromExporterWithRole(rb(32), "", false, "kid-3", nil)
+	require.NoError(t, err)
+	mgr.BindDID(peer, sid1)
+	mgr.BindDID(peer, sid2)
+	mgr.BindDID("did:sage:ethereum:0xother", sid3)
+
+	require.Equal(t, 2, mgr.RevokeSessionsByDID(peer))
+	require.Equal(t, 1, mgr.GetSessionCount())
+
+	for _, kid := range []string{"kid-1", "kid-2"} {
+		_, ok := mgr.GetByKeyID(kid)
+		require.False(t, ok)
+		_, err := mgr.LookupByKeyID(kid)
+		require.ErrorIs(t, err, ErrSessionRevoked)
+	}
+
+	// Unrelated DID is untouched
+	_, err = mgr.LookupByKeyID("kid-3")
+	require.NoError(t, err)
+	_, err = mgr.LookupByKeyID("kid-unknown")
+	require.ErrorIs(t, err, ErrSessionNotFound)
+
+	// Second revocation is a no-op
+	require.Zero(t, mgr.RevokeSessionsByDID(peer))
+
+	// Re-issuing a key after a fresh handshake clears the tombstone
+	_, _, _, err = mgr.EnsureAndBindFromExporterWithRole(rb(32), "", false, "kid-1", nil)
+	require.NoError(t, err)
+	_, err = mgr.LookupByKeyID("kid-1")
+	require.NoError(t, err)
+}
+
+func TestManager_GetByContextID(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Close() }()
+
+	// EnsureSessionWithParams binds the context it derives the session from
+	p := Params{ContextID: "ctx-params", SelfEph: rb(32), PeerEph: rb(32), Label: "sage/session v1", SharedSecret: rb(32)}
+	sess, _, _, err := mgr.EnsureSessionWithParams(p, nil)
+	require.NoError(t, err)
+	got, ok := mgr.GetByContextID("ctx-params")
+	require.True(t, ok)
+	require.Equal(t, sess, got)
+
+	_, sid1, _, err := mgr.EnsureAndBindFromExporterWithRole(rb(32), "", false, "kid-1", nil)
+	require.NoError(t, err)
+	mgr.BindContextID("ctx-1", sid1)
+	byKid, ok := mgr.GetByKeyID("kid-1")
+	require.True(t, ok)
+	byCtx, ok := mgr.GetByContextID("ctx-1")
+	require.True(t, ok)
+	require.Equal(t, byKid, byCtx)
+
+	_, ok = mgr.GetByContextID("ctx-unknown")
+	require.False(t, ok)
+	mgr.BindContextID("", sid1)
+	_, ok = mgr.GetByContextID("")
+	require.False(t, ok)
+
+	// A resumed handshake on the same context moves it to the new session
+	_, sid2, _, err := mgr.EnsureAndBindFromExporterWithRole(rb(32), "", false, "kid-2", nil)
+	require.NoError(t, err)
+	mgr.BindContextID("ctx-1", sid2)
+	got, ok = mgr.GetByContextID("ctx-1")
+	require.True(t, ok)
+	require.Equal(t, sid2, got.GetID())
+
+	// Removing the old session leaves the moved binding intact
+	mgr.RemoveSession(sid1)
+	_, ok = mgr.GetByContextID("ctx-1")
+	require.True(t, ok)
+
+	// Ended sessions are not returned
+	mgr.RemoveSession(sid2)
+	_, ok = mgr.GetByContextID("ctx-1")
+	require.False(t, ok)
+}
+
+func TestManager_TombstoneTTL(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Close() }()
+
+	// Tombstones outlive a short session MaxAge
+	mgr.SetDefaultConfig(Config{MaxAge: time.Minute})
+	mgr.SetTombstoneTTL(2 * time.Hour)
+	_, sid, _, err := mgr.EnsureAndBindFromExporterWithRole(rb(32), "", false, "kid-1", nil)
+	require.NoError(t, err)
+	mgr.BindDID("did:sage:ethereum:0xpeer", sid)
+	require.Equal(t, 1, mgr.RevokeSessionsByDID("did:sage:ethereum:0xpeer"))
+
+	age := func(d time.Duration) {
+		mgr.mu.Lock()
+		ended := mgr.endedKIDs["kid-1"]
+		ended.at = time.Now().Add(-d)
+		mgr.endedKIDs["kid-1"] = ended
+		mgr.mu.Unlock()
+		mgr.cleanupExpiredSessions()
+	}
+
+	age(time.Hour)
+	_, err = mgr.LookupByKeyID("kid-1")
+	require.ErrorIs(t, err, ErrSessionRevoked)
+
+	age(3 * time.Hour)
+	_, err = mgr.LookupByKeyID("kid-1")
+	require.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestManager_Close_ClearsContextBindings(t *testing.T) {
+	mgr := NewManager()
+	_, sid, _, err := mgr.EnsureAndBindFromExporterWithRole(rb(32), "", false, "kid-1", nil)
+	require.NoError(t, err)
+	mgr.BindContextID("ctx-1", sid)
+
+	require.NoError(t, mgr.Close())
+	require.Empty(t, mgr.sidByCtxID)
+	require.Empty(t, mgr.ctxIDBySID)
+	_, ok := mgr.GetByContextID("ctx-1")
+	require.False(t, ok)
+}
+
+func TestManager_LookupByKeyID_EndReasons(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Close() }()
+
+	bind := func(kid string, cfg Config) Session {
+		sess, err := mgr.CreateSessionWithConfig("sid-"+kid, rb(32), cfg)
+		require.NoError(t, err)
+		mgr.BindKeyID(kid, "sid-"+kid)
+		return sess
+	}
+
+	bind("kid-expired", Config{MaxAge: 20 * time.Millisecond})
+	exhausted := bind("kid-exhausted", Config{MaxMessages: 2})
+	bind("kid-revoked", Config{})
+	mgr.BindDID("did:sage:ethereum:0xpeer", "sid-kid-revoked")
+
+	exhausted.UpdateLastUsed()
+	exhausted.UpdateLastUsed()
+	require.Equal(t, 1, mgr.RevokeSessionsByDID("did:sage:ethereum:0xpeer"))
+	time.Sleep(30 * time.Millisecond)
+
+	cases := map[string]error{
+		"kid-never":     ErrSessionNotFound,
+		"kid-expired":   ErrSessionExpired,
+		"kid-exhausted": ErrSessionExhausted,
+		"kid-revoked":   ErrSessionRevoked,
+	}
+	for kid, want := range cases {
+		_, err := mgr.LookupByKeyID(kid)
+		require.ErrorIs(t, err, want, kid)
+		// The reason is remembered after the session has been dropped
+		_, err = mgr.LookupByKeyID(kid)
+		require.ErrorIs(t, err, want, kid)
+	}
+
+	t.Run("cleanup records the reason too", func(t *testing.T) {
+		bind("kid-swept", Config{MaxAge: 10 * time.Millisecond})
+		time.Sleep(20 * time.Millisecond)
+		mgr.cleanupExpiredSessions()
+		_, err := mgr.LookupByKeyID("kid-swept")
+		require.ErrorIs(t, err, ErrSessionExpired)
+	})
+
+	t.Run("a new handshake clears the reason", func(t *testing.T) {
+		bind("kid-expired", Config{})
+		_, err := mgr.LookupByKeyID("kid-expired")
+		require.NoError(t, err)
+	})
+}
+
+func TestManager_ApplyConfigToExisting(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Close() }()
+
+	relaxed := Config{MaxAge: time.Hour, IdleTimeout: time.Hour, MaxMessages: 1000}
+	for _, sid := range []string{"a1", "a2", "b1"} {
+		_, err := mgr.CreateSessionWithConfig(sid, rb(32), relaxed)
+		require.NoError(t, err)
+	}
+
+	// Tighten idle timeout for the "a" sessions only
+	tight := relaxed
+	tight.IdleTimeout = 20 * time.Millisecond
+	n := mgr.ApplyConfigToExisting(func(st SessionStats) bool {
+		return st.SessionID[0] == 'a'
+	}, tight)
+	require.Equal(t, 2, n)
+
+	sess, ok := mgr.GetSession("a1")
+	require.True(t, ok)
+	require.Equal(t, tight, sess.GetConfig())
+
+	// Keys are unaffected: the updated session still round-trips
+	ct, err := sess.Encrypt([]byte("hello"))
+	require.NoError(t, err)
+	pt, err := sess.Decrypt(ct)
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), pt)
+
+	time.Sleep(40 * time.Millisecond)
+	mgr.cleanupExpiredSessions()
+
+	require.Equal(t, []string{"b1"}, mgr.ListSessions())
+
+	// A nil selector applies to every session
+	require.Equal(t, 1, mgr.ApplyConfigToExisting(nil, tight))
+}
+
+func TestManager_ApplyConfigToExisting_Partial(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Close() }()
+
+	orig := Config{MaxAge: 2 * time.Hour, IdleTimeout: time.Hour, MaxMessages: 500, SequenceNumbers: true}
+	sess, err := mgr.CreateSessionWithConfig("s1", rb(32), orig)
+	require.NoError(t, err)
+
+	// Only IdleTimeout is set; the other limits must survive
+	require.Equal(t, 1, mgr.ApplyConfigToExisting(nil, Config{IdleTimeout: time.Minute}))
+
+	want := orig
+	want.IdleTimeout = time.Minute
+	require.Equal(t, want, sess.GetConfig())
+
+	// Framing options are never changed on a live session
+	mgr.ApplyConfigToExisting(nil, Config{MaxMessages: 10, SequenceNumbers: false, ReorderWindow: 5})
+	want.MaxMessages = 10
+	require.Equal(t, want, sess.GetConfig())
+}
+
+func TestManager_MaxSessions(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Close() }()
+
+	var mu sync.Mutex
+	var evicted []string
+	mgr.SetEvictionHandler(func(sid string) {
+		mu.Lock()
+		defer mu.Unlock()
+		evicted = append(evicted, sid)
+	})
+	mgr.SetMaxSessions(3)
+
+	create := func(sid string) Session {
+		sess, err := mgr.CreateSessionWithConfig(sid, rb(32), mgr.defaultConfig)
+		require.NoError(t, err)
+		time.Sleep(2 * time.Millisecond) // distinct LastUsedAt
+		return sess
+	}
+
+	s0 := create("s0")
+	create("s1")
+	create("s2")
+	require.Equal(t, 3, mgr.GetSessionCount())
+	require.Empty(t, evicted)
+
+	// s0 is used again, so s1 and s2 become the least recently used
+	s0.UpdateLastUsed()
+	time.Sleep(2 * time.Millisecond)
+
+	create("s3")
+	_, _, _, err := mgr.EnsureSessionFromExporterWithRole(rb(32), "", true, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, 3, mgr.GetSessionCount())
+	mu.Lock()
+	require.Equal(t, []string{"s1", "s2"}, evicted)
+	mu.Unlock()
+	for _, sid := range []string{"s1", "s2"} {
+		_, ok := mgr.GetSession(sid)
+		require.False(t, ok, sid)
+	}
+	for _, sid := range []string{"s0", "s3"} {
+		_, ok := mgr.GetSession(sid)
+		require.True(t, ok, sid)
+	}
+
+	// Lowering the cap evicts immediately; removing it stops eviction
+	mgr.SetMaxSessions(1)
+	require.Equal(t, 1, mgr.GetSessionCount())
+	mgr.SetMaxSessions(0)
+	create("s4")
+	create("s5")
+	require.Equal(t, 3, mgr.GetSessionCount())
+}
+
 func rb(n int) []byte {
 	b := make([]byte, n)
 	_, _ = rand.Read(b)
@@ -20,7 +20,6 @@ package session
 
 import (
 	"crypto/rand"
-	"encoding/base64"
 	"fmt"
 	"time"
 
@@ -92,5 +91,5 @@ func GenerateSalt() (string, error) {
 	}
 
 	// Encode to Base64URL without padding
-	return base64.RawURLEncoding.EncodeToString(saltBytes), nil
+	return Encoding.EncodeToString(saltBytes), nil
 }
@@ -0,0 +1,111 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package session
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// SequenceSize is the length of the sequence number prefixed to the
+	// plaintext of a CiphertextVersion2 frame.
+	SequenceSize = 8
+	// DefaultReorderWindow is the reorder window used when
+	// Config.ReorderWindow is 0.
+	DefaultReorderWindow = 32
+	// MaxReorderWindow is the largest supported reorder window.
+	MaxReorderWindow = 64
+)
+
+// replayWindow tracks inbound sequence numbers, as in the IPsec and DTLS
+// anti-replay windows. Bit i of seen is set when sequence number max-i was
+// accepted.
+type replayWindow struct {
+	max   uint64
+	seen  uint64
+	valid bool // a sequence number has been accepted
+}
+
+// accept records seq, or rejects it as a duplicate or as older than size
+// messages behind the newest accepted one.
+func (w *replayWindow) accept(seq uint64, size int) error {
+	if !w.valid {
+		w.max, w.seen, w.valid = seq, 1, true
+		return nil
+	}
+	if seq > w.max {
+		if shift := seq - w.max; shift < 64 {
+			w.seen = w.seen<<shift | 1
+		} else {
+			w.seen = 1
+		}
+		w.max = seq
+		return nil
+	}
+	behind := w.max - seq
+	if behind >= uint64(size) {
+		return fmt.Errorf("%w: %d is %d behind %d", ErrStaleSequence, seq, behind, w.max)
+	}
+	if w.seen&(1<<behind) != 0 {
+		return fmt.Errorf("%w: %d", ErrDuplicateSequence, seq)
+	}
+	w.seen |= 1 << behind
+	return nil
+}
+
+// reorderWindow returns the effective reorder window for cfg.
+func reorderWindow(cfg Config) int {
+	switch {
+	case cfg.ReorderWindow <= 0:
+		return DefaultReorderWindow
+	case cfg.ReorderWindow > MaxReorderWindow:
+		return MaxReorderWindow
+	}
+	return cfg.ReorderWindow
+}
+
+// acceptSequence checks seq against the session's inbound window. It runs
+// only after the frame authenticated, so forged messages cannot move it.
+func (s *SecureSession) acceptSequence(seq uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.recvWindow.accept(seq, reorderWindow(s.config))
+}
+
+// sequencedPlaintext returns seq || plaintext.
+func sequencedPlaintext(seq uint64, plaintext []byte) []byte {
+	out := make([]byte, SequenceSize, SequenceSize+len(plaintext))
+	binary.BigEndian.PutUint64(out, seq)
+	return append(out, plaintext...)
+}
+
+// splitSequence splits an opened CiphertextVersion2 plaintext.
+func splitSequence(pt []byte) (uint64, []byte, error) {
+	if len(pt) < SequenceSize {
+		return 0, nil, fmt.Errorf("%w: no sequence number", ErrCiphertextTooShort)
+	}
+	return binary.BigEndian.Uint64(pt), pt[SequenceSize:], nil
+}
+
+// isReplay reports whether err rejects a message's sequence number.
+func isReplay(err error) bool {
+	return errors.Is(err, ErrDuplicateSequence) || errors.Is(err, ErrStaleSequence)
+}
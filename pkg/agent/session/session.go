@@ -20,23 +20,38 @@ package session
 
 import (
 	"bytes"
+	"context"
 	"crypto/cipher"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
-	"encoding/base64"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
 	"io"
 
 	"github.com/sage-x-project/sage/internal/metrics"
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/sagechaos"
 	"golang.org/x/crypto/chacha20poly1305"
 	"golang.org/x/crypto/hkdf"
 )
 
-// SecureSession implements Session with ChaCha20-Poly1305 AEAD
+// SecureSession implements Session with ChaCha20-Poly1305 AEAD.
+//
+// Concurrency: a SecureSession is safe for concurrent use once initialized.
+// Encrypt/Decrypt and their AAD, directional and sign/verify variants may be
+// called from many goroutines; AEAD instances are immutable after creation,
+// key material is only read under the read lock, and activity counters are
+// updated under the write lock. Close may run concurrently with in-flight
+// operations: those either complete with the old keys or fail as expired.
+// Getters return individually consistent values; use Snapshot for a
+// consistent view of several fields at once. Reset and InitializeSession are
+// reserved for the pool and require exclusive ownership.
 type SecureSession struct {
 	mu           sync.RWMutex
 	id           string
@@ -70,6 +85,14 @@ type SecureSession struct {
 	inSign  []byte // HMAC-SHA256 key for inbound  signatures
 	aeadOut cipher.AEAD
 	aeadIn  cipher.AEAD
+
+	// Nonce state for sealing; see nextNonce
+	nonceIV     [chacha20poly1305.NonceSize]byte
+	nonceIVSet  bool
+	sendCounter uint64
+
+	// Inbound sequence numbers seen; see Config.SequenceNumbers
+	recvWindow replayWindow
 }
 
 // Params describes the handshake context required to deterministically
@@ -83,6 +106,10 @@ type Params struct {
 	// Protocol version
 	Label        string
 	SharedSecret []byte
+	// PeerDID is the authenticated peer DID. It does not enter key
+	// derivation; EnsureSessionWithParams binds it so RevokeSessionsByDID
+	// can end the session.
+	PeerDID string
 }
 
 // NewSecureSession creates a new session with derived encryption/signing keys
@@ -216,7 +243,7 @@ func ComputeSessionIDFromSeed(seed []byte, label string) (string, error) {
 	h.Write([]byte(label))
 	h.Write(seed)
 	full := h.Sum(nil)
-	return base64.RawURLEncoding.EncodeToString(full[:16]), nil
+	return Encoding.EncodeToString(full[:16]), nil
 }
 
 // deriveKeys derives encryption and signing keys from shared secret using HKDF
@@ -334,20 +361,41 @@ func (s *SecureSession) GetCreatedAt() time.Time {
 
 // GetLastUsedAt returns the last activity timestamp
 func (s *SecureSession) GetLastUsedAt() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.lastUsedAt
 }
 
+// Snapshot returns a consistent, immutable view of the session state for
+// logging and metrics. It never exposes key material.
+func (s *SecureSession) Snapshot() Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return Snapshot{
+		ID:           s.id,
+		CreatedAt:    s.createdAt,
+		LastUsedAt:   s.lastUsedAt,
+		MessageCount: s.messageCount,
+		Config:       s.config,
+		Initiator:    s.initiator,
+		Closed:       s.closed,
+		Expired:      s.isExpiredLocked(time.Now()),
+	}
+}
+
 // IsExpired checks if the session has expired based on configured policies
 func (s *SecureSession) IsExpired() bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.isExpiredLocked(time.Now())
+}
 
+// isExpiredLocked evaluates expiry policies; the caller must hold s.mu.
+func (s *SecureSession) isExpiredLocked(now time.Time) bool {
 	if s.closed {
 		return true
 	}
 
-	now := time.Now()
-
 	// Check absolute expiration
 	if s.config.MaxAge > 0 && now.After(s.createdAt.Add(s.config.MaxAge)) {
 		return true
@@ -366,6 +414,18 @@ func (s *SecureSession) IsExpired() bool {
 	return false
 }
 
+// endReason reports why an expired session ended: ErrSessionExhausted once
+// its message budget is used up, ErrSessionExpired for every other cause
+// (age, idle timeout or an explicit Close).
+func (s *SecureSession) endReason() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.closed && s.config.MaxMessages > 0 && s.messageCount >= s.config.MaxMessages {
+		return ErrSessionExhausted
+	}
+	return ErrSessionExpired
+}
+
 // UpdateLastUsed updates the last activity timestamp and increments message count
 func (s *SecureSession) UpdateLastUsed() {
 	s.mu.Lock()
@@ -383,6 +443,10 @@ func (s *SecureSession) Reset() {
 	s.lastUsedAt = time.Time{}
 	s.messageCount = 0
 	s.initiator = false
+	s.nonceIV = [chacha20poly1305.NonceSize]byte{}
+	s.nonceIVSet = false
+	s.sendCounter = 0
+	s.recvWindow = replayWindow{}
 
 	// Clear sensitive key material (zero the entire keyMaterial buffer)
 	if s.keyMaterial != nil {
@@ -425,6 +489,9 @@ func (s *SecureSession) InitializeSession(sid string, sessionSeed []byte, config
 	s.messageCount = 0
 	s.config = config
 	s.closed = false
+	s.nonceIVSet = false
+	s.sendCounter = 0
+	s.recvWindow = replayWindow{}
 	s.sessionSeed = append([]byte(nil), sessionSeed...)
 
 	// Derive encryption and signing keys using HKDF
@@ -442,8 +509,11 @@ func (s *SecureSession) InitializeSession(sid string, sessionSeed []byte, config
 	return nil
 }
 
-// Close marks the session as closed
+// Close marks the session as closed and zeroes its key material
 func (s *SecureSession) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.closed = true
 
 	zeroBytes := func(b []byte) {
@@ -469,46 +539,264 @@ func (s *SecureSession) Close() error {
 
 // GetMessageCount returns the number of messages processed
 func (s *SecureSession) GetMessageCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.messageCount
 }
 
 // GetConfig returns the session configuration
 func (s *SecureSession) GetConfig() Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.config
 }
 
+// applyLimits overrides the session's MaxAge, IdleTimeout and MaxMessages
+// with the non-zero values in config. Keys, ciphers and message framing are
+// fixed at creation and are not affected.
+func (s *SecureSession) applyLimits(config Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if config.MaxAge != 0 {
+		s.config.MaxAge = config.MaxAge
+	}
+	if config.IdleTimeout != 0 {
+		s.config.IdleTimeout = config.IdleTimeout
+	}
+	if config.MaxMessages != 0 {
+		s.config.MaxMessages = config.MaxMessages
+	}
+}
+
+// ciphers returns the AEAD instances under the read lock. The instances
+// themselves are safe for concurrent Seal/Open.
+func (s *SecureSession) ciphers() (legacy, out, in cipher.AEAD) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.aead, s.aeadOut, s.aeadIn
+}
+
+// mac computes HMAC-SHA256(signingKey, covered) under the read lock so Close
+// cannot zero the key mid-computation.
+func (s *SecureSession) mac(covered []byte) []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m := hmac.New(sha256.New, s.signingKey)
+	m.Write(covered)
+	return m.Sum(nil)
+}
+
+// Ciphertext framing. Every sealed message is
+//
+//	version(1) || cipher(1) || nonce(12) || ciphertext || tag(16)
+//
+// The version and cipher bytes are authenticated as a prefix of the AEAD
+// additional data, so they cannot be rewritten without failing decryption,
+// and a peer that receives a format it does not know rejects it outright
+// instead of misparsing it.
+//
+// Sessions with Config.SequenceNumbers use CiphertextVersion2, whose
+// plaintext is prefixed with an 8-byte big-endian sequence number inside
+// the AEAD; see sequence.go.
+const (
+	// CiphertextVersion1 is the default framing version.
+	CiphertextVersion1 byte = 0x01
+	// CiphertextVersion2 is version 1 with a sequence number in the plaintext.
+	CiphertextVersion2 byte = 0x02
+	// CipherChaCha20Poly1305 identifies ChaCha20-Poly1305 with a 96-bit nonce.
+	CipherChaCha20Poly1305 byte = 0x01
+	// FrameHeaderSize is the length of the version and cipher bytes.
+	FrameHeaderSize = 2
+)
+
+// MinCiphertextSize is the size of sealed data for an empty plaintext:
+// the frame header, a nonce and the authentication tag. Anything shorter
+// cannot be valid and is rejected with ErrCiphertextTooShort.
+const MinCiphertextSize = FrameHeaderSize + chacha20poly1305.NonceSize + chacha20poly1305.Overhead
+
+// frameAAD returns the AEAD additional data for a frame: header || aad.
+func frameAAD(header, aad []byte) []byte {
+	out := make([]byte, 0, len(header)+len(aad))
+	out = append(out, header...)
+	return append(out, aad...)
+}
+
+// nextNonce reserves the next send counter and returns its nonce: a random
+// per-session IV XORed with the big-endian counter in its last 8 bytes, as in
+// TLS 1.3. Nonces are therefore unique for every message this session seals,
+// and the IV keeps two sessions that share a key (the legacy single-AEAD
+// mode, or a session re-created from the same seed) from colliding. The
+// counter never wraps: once it is exhausted every call fails with
+// ErrNonceExhausted and the session must be re-established. The counter is
+// also returned, as the message's sequence number when the session embeds
+// one (see Config.SequenceNumbers).
+func (s *SecureSession) nextNonce() (nonce []byte, seq uint64, sequenced bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sendCounter == math.MaxUint64 {
+		return nil, 0, false, ErrNonceExhausted
+	}
+	if !s.nonceIVSet {
+		if _, err := io.ReadFull(rand.Reader, s.nonceIV[:]); err != nil {
+			return nil, 0, false, fmt.Errorf("failed to generate nonce IV: %w", err)
+		}
+		s.nonceIVSet = true
+	}
+
+	nonce = make([]byte, chacha20poly1305.NonceSize)
+	binary.BigEndian.PutUint64(nonce[chacha20poly1305.NonceSize-8:], s.sendCounter)
+	for i := range nonce {
+		nonce[i] ^= s.nonceIV[i]
+	}
+	seq = s.sendCounter
+	s.sendCounter++
+	return nonce, seq, s.config.SequenceNumbers, nil
+}
+
+// seal seals plaintext with aead under the session's next nonce, prefixed
+// with its sequence number when the session embeds them.
+func (s *SecureSession) seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce, seq, sequenced, err := s.nextNonce()
+	if err != nil {
+		return nil, err
+	}
+	if sequenced {
+		return sealFramed(aead, CiphertextVersion2, nonce, sequencedPlaintext(seq, plaintext), aad), nil
+	}
+	return sealFramed(aead, CiphertextVersion1, nonce, plaintext, aad), nil
+}
+
+// open opens a frame produced by seal. A session with sequence numbers only
+// accepts CiphertextVersion2 frames and rejects duplicate or stale sequence
+// numbers; one without only accepts CiphertextVersion1.
+func (s *SecureSession) open(aead cipher.AEAD, data, aad []byte) ([]byte, error) {
+	pt, commit, err := s.openPending(aead, data, aad)
+	if err != nil {
+		return nil, err
+	}
+	if err := commit(); err != nil {
+		return nil, err
+	}
+	return pt, nil
+}
+
+// openPending is open without accepting the frame's sequence number: the
+// returned commit does that, so a caller that abandons the message leaves
+// the inbound window untouched.
+func (s *SecureSession) openPending(aead cipher.AEAD, data, aad []byte) ([]byte, func() error, error) {
+	if !s.GetConfig().SequenceNumbers {
+		pt, err := openSealed(aead, CiphertextVersion1, data, aad)
+		return pt, noCommit, err
+	}
+	pt, err := openSealed(aead, CiphertextVersion2, data, aad)
+	if err != nil {
+		return nil, nil, err
+	}
+	seq, body, err := splitSequence(pt)
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, func() error { return s.acceptSequence(seq) }, nil
+}
+
+func noCommit() error { return nil }
+
+// sealFramed seals plaintext under nonce and returns the framed message
+// version || cipher || nonce || ciphertext || tag.
+func sealFramed(aead cipher.AEAD, version byte, nonce, plaintext, aad []byte) []byte {
+	out := make([]byte, 0, FrameHeaderSize+len(nonce)+len(plaintext)+aead.Overhead())
+	out = append(out, version, CipherChaCha20Poly1305)
+	out = append(out, nonce...)
+	// #nosec G407 - nonce comes from nextNonce and is never reused
+	return aead.Seal(out, nonce, plaintext, frameAAD(out[:FrameHeaderSize], aad))
+}
+
+// openSealed opens a frame produced by sealFramed with the given version.
+// An empty plaintext decrypts to a non-nil empty slice.
+func openSealed(aead cipher.AEAD, version byte, data, aad []byte) ([]byte, error) {
+	if len(data) < MinCiphertextSize {
+		return nil, fmt.Errorf("%w: got %d bytes, need at least %d", ErrCiphertextTooShort, len(data), MinCiphertextSize)
+	}
+	if data[0] != version {
+		return nil, fmt.Errorf("%w: 0x%02x", ErrUnsupportedCiphertextVersion, data[0])
+	}
+	if data[1] != CipherChaCha20Poly1305 {
+		return nil, fmt.Errorf("%w: 0x%02x", ErrUnsupportedCipher, data[1])
+	}
+	header := data[:FrameHeaderSize]
+	nonce := data[FrameHeaderSize : FrameHeaderSize+chacha20poly1305.NonceSize]
+	ct := data[FrameHeaderSize+chacha20poly1305.NonceSize:]
+
+	pt, err := aead.Open(nil, nonce, ct, frameAAD(header, aad)) // #nosec G407 -- nonce extracted from data, not hardcoded
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed: %w", err)
+	}
+	if pt == nil {
+		pt = []byte{}
+	}
+	return pt, nil
+}
+
+// isMalformed reports whether err rejects the framing rather than failing
+// authentication.
+func isMalformed(err error) bool {
+	return errors.Is(err, ErrCiphertextTooShort) ||
+		errors.Is(err, ErrUnsupportedCiphertextVersion) ||
+		errors.Is(err, ErrUnsupportedCipher)
+}
+
 // Encrypt encrypts plaintext using ChaCha20-Poly1305.
-// Output format: nonce || ciphertext.
+// Output format: version || cipher || nonce || ciphertext (see FrameHeaderSize).
+//
+// Empty and nil plaintexts are valid: they produce MinCiphertextSize bytes
+// of authenticated ciphertext, which Decrypt turns back into an empty,
+// non-nil slice.
 func (s *SecureSession) Encrypt(plaintext []byte) ([]byte, error) {
+	return s.EncryptContext(context.Background(), plaintext)
+}
+
+// EncryptContext is Encrypt honoring ctx cancellation and deadline. It is
+// meant for sessions whose cipher is backed by a remote HSM or KMS that may
+// block: once ctx is done it returns ctx's error without waiting for the
+// cipher. The abandoned operation still runs to completion in the
+// background and may read plaintext, so callers must not modify the buffer
+// after a cancellation.
+func (s *SecureSession) EncryptContext(ctx context.Context, plaintext []byte) ([]byte, error) {
+	return runWithContext(ctx, "encrypt", func() ([]byte, func() error, error) {
+		out, err := s.encrypt(plaintext)
+		return out, nil, err
+	})
+}
+
+func (s *SecureSession) encrypt(plaintext []byte) ([]byte, error) {
 	if s.IsExpired() {
 		metrics.CryptoOperations.WithLabelValues("encrypt", "expired").Inc()
 		return nil, fmt.Errorf("session expired")
 	}
+	if err := sagechaos.Fail(sagechaos.SessionEncrypt); err != nil {
+		return nil, err
+	}
 
-	if s.aeadOut != nil { // directional path
+	aead, aeadOut, _ := s.ciphers()
+	if aeadOut != nil { // directional path
 		return s.EncryptOutbound(plaintext)
 	}
 	// legacy single-AEAD path
-	if s.aead == nil {
+	if aead == nil {
 		metrics.CryptoOperations.WithLabelValues("encrypt", "not_initialized").Inc()
 		return nil, fmt.Errorf("session not initialized: AEAD is nil")
 	}
-	// Generate random 12-byte nonce
-	nonce := make([]byte, chacha20poly1305.NonceSize)
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		metrics.CryptoOperations.WithLabelValues("encrypt", "nonce_error").Inc()
-		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	out, err := s.seal(aead, plaintext, nil)
+	if err != nil {
+		if errors.Is(err, ErrNonceExhausted) {
+			metrics.CryptoOperations.WithLabelValues("encrypt", "nonce_exhausted").Inc()
+		} else {
+			metrics.CryptoOperations.WithLabelValues("encrypt", "nonce_error").Inc()
+		}
+		return nil, err
 	}
 
-	// Seal appends the ciphertext and authentication tag
-	// #nosec G407 - nonce is randomly generated using crypto/rand above
-	ciphertext := s.aead.Seal(nil, nonce, plaintext, nil)
-
-	// Prepend nonce
-	out := make([]byte, len(nonce)+len(ciphertext))
-	copy(out, nonce)
-	copy(out[len(nonce):], ciphertext)
-
 	s.UpdateLastUsed()
 	metrics.CryptoOperations.WithLabelValues("encrypt", "success").Inc()
 	metrics.SessionMessageSize.WithLabelValues("encrypted").Observe(float64(len(out)))
@@ -516,95 +804,158 @@ func (s *SecureSession) Encrypt(plaintext []byte) ([]byte, error) {
 }
 
 // Decrypt decrypts data produced by Encrypt.
-// Expects input format: nonce || ciphertext.
+// Expects input format: version || cipher || nonce || ciphertext; unknown
+// versions are rejected with ErrUnsupportedCiphertextVersion.
 func (s *SecureSession) Decrypt(data []byte) ([]byte, error) {
+	return s.DecryptContext(context.Background(), data)
+}
+
+// DecryptContext is Decrypt honoring ctx cancellation and deadline; see
+// EncryptContext. A canceled decrypt does not consume the message's
+// sequence number, so the same frame can be retried.
+func (s *SecureSession) DecryptContext(ctx context.Context, data []byte) ([]byte, error) {
+	return runWithContext(ctx, "decrypt", func() ([]byte, func() error, error) { return s.decrypt(data) })
+}
+
+// decrypt opens data and returns a commit that accepts its sequence number
+// and records the message as used; see runWithContext.
+func (s *SecureSession) decrypt(data []byte) ([]byte, func() error, error) {
 	if s.IsExpired() {
 		metrics.CryptoOperations.WithLabelValues("decrypt", "expired").Inc()
-		return nil, fmt.Errorf("session expired")
+		return nil, nil, fmt.Errorf("session expired")
 	}
-
-	if s.aeadIn != nil { // directional path
-		return s.DecryptInbound(data)
+	if err := sagechaos.Fail(sagechaos.SessionDecrypt); err != nil {
+		return nil, nil, err
 	}
-	// legacy single-AEAD path
-	if s.aead == nil {
+
+	aead, _, aeadIn := s.ciphers()
+	if aeadIn != nil { // directional path
+		aead = aeadIn
+	} else if aead == nil { // legacy single-AEAD path
 		metrics.CryptoOperations.WithLabelValues("decrypt", "not_initialized").Inc()
-		return nil, fmt.Errorf("session not initialized: AEAD is nil")
+		return nil, nil, fmt.Errorf("session not initialized: AEAD is nil")
 	}
-	if len(data) < chacha20poly1305.NonceSize {
+	// Open verifies authenticity and decrypts
+	plaintext, commit, err := s.openPending(aead, data, nil)
+	if err != nil {
+		recordDecryptFailure(err)
+		return nil, nil, err
+	}
+	return plaintext, func() error {
+		if err := commit(); err != nil {
+			recordDecryptFailure(err)
+			return err
+		}
+		s.UpdateLastUsed()
+		metrics.CryptoOperations.WithLabelValues("decrypt", "success").Inc()
+		metrics.SessionMessageSize.WithLabelValues("decrypted").Observe(float64(len(plaintext)))
+		return nil
+	}, nil
+}
+
+func recordDecryptFailure(err error) {
+	switch {
+	case isMalformed(err):
 		metrics.CryptoOperations.WithLabelValues("decrypt", "invalid_data").Inc()
-		return nil, fmt.Errorf("data too short")
+	case isReplay(err):
+		metrics.CryptoOperations.WithLabelValues("decrypt", "replay").Inc()
+	default:
+		metrics.CryptoOperations.WithLabelValues("decrypt", "failure").Inc()
 	}
+}
 
-	nonce := data[:chacha20poly1305.NonceSize]
-	ciphertext := data[chacha20poly1305.NonceSize:]
+// runWithContext runs op directly when ctx can never be done, and otherwise
+// on its own goroutine so the caller can return as soon as ctx is done.
+// A non-nil commit returned by op runs on the caller's goroutine once its
+// result is taken, so an abandoned operation never commits.
+func runWithContext(ctx context.Context, name string, op func() ([]byte, func() error, error)) ([]byte, error) {
+	if ctx.Done() == nil {
+		return commitResult(op())
+	}
+	if err := ctx.Err(); err != nil {
+		metrics.CryptoOperations.WithLabelValues(name, "canceled").Inc()
+		return nil, fmt.Errorf("%s canceled: %w", name, err)
+	}
+
+	type result struct {
+		out    []byte
+		commit func() error
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		out, commit, err := op()
+		done <- result{out, commit, err}
+	}()
+
+	select {
+	case r := <-done:
+		return commitResult(r.out, r.commit, r.err)
+	case <-ctx.Done():
+		metrics.CryptoOperations.WithLabelValues(name, "canceled").Inc()
+		return nil, fmt.Errorf("%s canceled: %w", name, ctx.Err())
+	}
+}
 
-	// Open verifies authenticity and decrypts
-	plaintext, err := s.aead.Open(nil, nonce, ciphertext, nil) // #nosec G407 -- nonce extracted from data, not hardcoded
+// commitResult runs commit, if any, for a successful op.
+func commitResult(out []byte, commit func() error, err error) ([]byte, error) {
 	if err != nil {
-		metrics.CryptoOperations.WithLabelValues("decrypt", "failure").Inc()
-		return nil, fmt.Errorf("decryption failed: %w", err)
+		return nil, err
 	}
-	s.UpdateLastUsed()
-	metrics.CryptoOperations.WithLabelValues("decrypt", "success").Inc()
-	metrics.SessionMessageSize.WithLabelValues("decrypted").Observe(float64(len(plaintext)))
-	return plaintext, nil
+	if commit != nil {
+		if err := commit(); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
 }
 
 // EncryptAndSign encrypts plaintext and returns (cipher, mac) where:
-//   - cipher = nonce || ciphertext (ChaCha20-Poly1305)
+//   - cipher = version || cipher || nonce || ciphertext (ChaCha20-Poly1305)
 //   - mac    = HMAC-SHA256(signingKey, covered)
 func (s *SecureSession) EncryptAndSign(plaintext []byte, covered []byte) (cipher []byte, mac []byte, err error) {
 	if s.IsExpired() {
 		return nil, nil, fmt.Errorf("session expired")
 	}
+	aead, _, _ := s.ciphers()
+	if aead == nil {
+		return nil, nil, fmt.Errorf("session not initialized: AEAD is nil")
+	}
 
 	// Encrypt
-	nonce := make([]byte, chacha20poly1305.NonceSize)
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, nil, fmt.Errorf("failed to generate nonce: %w", err)
+	out, err := s.seal(aead, plaintext, nil)
+	if err != nil {
+		return nil, nil, err
 	}
-	// #nosec G407 - nonce is randomly generated using crypto/rand above
-	ct := s.aead.Seal(nil, nonce, plaintext, nil)
-
-	out := make([]byte, len(nonce)+len(ct))
-	copy(out, nonce)
-	copy(out[len(nonce):], ct)
 
 	// HMAC over your covered bytes
-	h := hmac.New(sha256.New, s.signingKey)
-	h.Write(covered)
-	tag := h.Sum(nil)
+	tag := s.mac(covered)
 
 	s.UpdateLastUsed()
 	return out, tag, nil
 }
 
 // DecryptAndVerify verifies mac = HMAC-SHA256(signingKey, covered) and then decrypts cipher.
-// cipher = nonce || ciphertext
+// cipher = version || cipher || nonce || ciphertext
 func (s *SecureSession) DecryptAndVerify(cipher []byte, covered []byte, mac []byte) ([]byte, error) {
 	if s.IsExpired() {
 		return nil, fmt.Errorf("session expired")
 	}
 
 	// Verify HMAC first
-	h := hmac.New(sha256.New, s.signingKey)
-	h.Write(covered)
-	want := h.Sum(nil)
-	if !hmac.Equal(want, mac) {
+	want := s.mac(covered)
+	if !sagecrypto.SecureCompare(want, mac) {
 		return nil, fmt.Errorf("signature verify failed")
 	}
 
 	// Then decrypt
-	if len(cipher) < chacha20poly1305.NonceSize {
-		return nil, fmt.Errorf("cipher too short")
+	aead, _, _ := s.ciphers()
+	if aead == nil {
+		return nil, fmt.Errorf("session not initialized: AEAD is nil")
 	}
-	nonce := cipher[:chacha20poly1305.NonceSize]
-	ct := cipher[chacha20poly1305.NonceSize:]
-
-	plain, err := s.aead.Open(nil, nonce, ct, nil) // #nosec G407 -- nonce extracted from cipher data, not hardcoded
+	plain, err := s.open(aead, cipher, nil)
 	if err != nil {
-		return nil, fmt.Errorf("decryption/verification failed: %w", err)
+		return nil, err
 	}
 
 	s.UpdateLastUsed()
@@ -612,63 +963,52 @@ func (s *SecureSession) DecryptAndVerify(cipher []byte, covered []byte, mac []by
 }
 
 // EncryptWithAAD encrypts plaintext with optional AEAD AAD.
-// Output: nonce || ciphertext
+// Output: version || cipher || nonce || ciphertext
 func (s *SecureSession) EncryptWithAAD(plaintext, aad []byte) ([]byte, error) {
-	if s.aeadOut != nil {
+	aead, aeadOut, _ := s.ciphers()
+	if aeadOut != nil {
 		return s.EncryptWithAADOutbound(plaintext, aad)
 	}
-	if s.aead == nil {
+	if aead == nil {
 		return nil, fmt.Errorf("session not initialized: AEAD is nil")
 	}
 
-	nonce := make([]byte, chacha20poly1305.NonceSize)
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	out, err := s.seal(aead, plaintext, aad)
+	if err != nil {
+		return nil, err
 	}
-	// #nosec G407 - nonce is randomly generated using crypto/rand above
-	ct := s.aead.Seal(nil, nonce, plaintext, aad)
-	out := make([]byte, len(nonce)+len(ct))
-	copy(out, nonce)
-	copy(out[len(nonce):], ct)
 	s.UpdateLastUsed()
 	return out, nil
 }
 
 // DecryptWithAAD decrypts data produced by EncryptWithAAD.
-// Input: nonce || ciphertext
+// Input: version || cipher || nonce || ciphertext
 func (s *SecureSession) DecryptWithAAD(data, aad []byte) ([]byte, error) {
-	if s.aeadIn != nil {
+	aead, _, aeadIn := s.ciphers()
+	if aeadIn != nil {
 		return s.DecryptWithAADInbound(data, aad)
 	}
-	if s.aead == nil {
+	if aead == nil {
 		return nil, fmt.Errorf("session not initialized: AEAD is nil")
 	}
 
-	if len(data) < chacha20poly1305.NonceSize {
-		return nil, fmt.Errorf("data too short")
-	}
-	nonce := data[:chacha20poly1305.NonceSize]
-	ct := data[chacha20poly1305.NonceSize:]
-	pt, err := s.aead.Open(nil, nonce, ct, aad) // #nosec G407 -- nonce extracted from data, not hardcoded
+	pt, err := s.open(aead, data, aad)
 	if err != nil {
-		return nil, fmt.Errorf("decryption failed: %w", err)
+		return nil, err
 	}
 	s.UpdateLastUsed()
 	return pt, nil
 }
 
 func (s *SecureSession) SignCovered(covered []byte) []byte {
-	m := hmac.New(sha256.New, s.signingKey)
-	m.Write(covered)
+	tag := s.mac(covered)
 	s.UpdateLastUsed()
-	return m.Sum(nil)
+	return tag
 }
 
 func (s *SecureSession) VerifyCovered(covered, sig []byte) error {
-	m := hmac.New(sha256.New, s.signingKey)
-	m.Write(covered)
-	exp := m.Sum(nil)
-	if !hmac.Equal(exp, sig) {
+	exp := s.mac(covered)
+	if !sagecrypto.SecureCompare(exp, sig) {
 		return fmt.Errorf("bad signature")
 	}
 	s.UpdateLastUsed()
@@ -676,41 +1016,32 @@ func (s *SecureSession) VerifyCovered(covered, sig []byte) error {
 }
 
 // EncryptOutbound encrypts plaintext using the *outbound* AEAD.
-// Output: nonce || ciphertext
+// Output: version || cipher || nonce || ciphertext
 func (s *SecureSession) EncryptOutbound(plaintext []byte) ([]byte, error) {
-	if s.aeadOut == nil {
+	_, aeadOut, _ := s.ciphers()
+	if aeadOut == nil {
 		return nil, fmt.Errorf("session not initialized: outbound AEAD is nil")
 	}
-	nonce := make([]byte, chacha20poly1305.NonceSize)
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	out, err := s.seal(aeadOut, plaintext, nil)
+	if err != nil {
+		return nil, err
 	}
-	// #nosec G407 - nonce is randomly generated using crypto/rand above
-	ct := s.aeadOut.Seal(nil, nonce, plaintext, nil)
-
-	out := make([]byte, len(nonce)+len(ct))
-	copy(out, nonce)
-	copy(out[len(nonce):], ct)
 
 	s.UpdateLastUsed()
 	return out, nil
 }
 
 // DecryptInbound decrypts data using the *inbound* AEAD.
-// Input: nonce || ciphertext
+// Input: version || cipher || nonce || ciphertext
 func (s *SecureSession) DecryptInbound(data []byte) ([]byte, error) {
-	if s.aeadIn == nil {
+	_, _, aeadIn := s.ciphers()
+	if aeadIn == nil {
 		return nil, fmt.Errorf("session not initialized: inbound AEAD is nil")
 	}
-	if len(data) < chacha20poly1305.NonceSize {
-		return nil, fmt.Errorf("data too short")
-	}
-	nonce := data[:chacha20poly1305.NonceSize]
-	ct := data[chacha20poly1305.NonceSize:]
 
-	pt, err := s.aeadIn.Open(nil, nonce, ct, nil) // #nosec G407 -- nonce extracted from data, not hardcoded
+	pt, err := s.open(aeadIn, data, nil)
 	if err != nil {
-		return nil, fmt.Errorf("decryption failed: %w", err)
+		return nil, err
 	}
 	s.UpdateLastUsed()
 	return pt, nil
@@ -718,19 +1049,14 @@ func (s *SecureSession) DecryptInbound(data []byte) ([]byte, error) {
 
 // EncryptWithAADOutbound encrypts with AAD using *outbound* AEAD.
 func (s *SecureSession) EncryptWithAADOutbound(plaintext, aad []byte) ([]byte, error) {
-	if s.aeadOut == nil {
+	_, aeadOut, _ := s.ciphers()
+	if aeadOut == nil {
 		return nil, fmt.Errorf("session not initialized: outbound AEAD is nil")
 	}
-	nonce := make([]byte, chacha20poly1305.NonceSize)
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	out, err := s.seal(aeadOut, plaintext, aad)
+	if err != nil {
+		return nil, err
 	}
-	// #nosec G407 - nonce is randomly generated using crypto/rand above
-	ct := s.aeadOut.Seal(nil, nonce, plaintext, aad)
-
-	out := make([]byte, len(nonce)+len(ct))
-	copy(out, nonce)
-	copy(out[len(nonce):], ct)
 
 	s.UpdateLastUsed()
 	return out, nil
@@ -738,18 +1064,14 @@ func (s *SecureSession) EncryptWithAADOutbound(plaintext, aad []byte) ([]byte, e
 
 // DecryptWithAADInbound decrypts with AAD using *inbound* AEAD.
 func (s *SecureSession) DecryptWithAADInbound(data, aad []byte) ([]byte, error) {
-	if s.aeadIn == nil {
+	_, _, aeadIn := s.ciphers()
+	if aeadIn == nil {
 		return nil, fmt.Errorf("session not initialized: inbound AEAD is nil")
 	}
-	if len(data) < chacha20poly1305.NonceSize {
-		return nil, fmt.Errorf("data too short")
-	}
-	nonce := data[:chacha20poly1305.NonceSize]
-	ct := data[chacha20poly1305.NonceSize:]
 
-	pt, err := s.aeadIn.Open(nil, nonce, ct, aad) // #nosec G407 -- nonce extracted from data, not hardcoded
+	pt, err := s.open(aeadIn, data, aad)
 	if err != nil {
-		return nil, fmt.Errorf("decryption failed: %w", err)
+		return nil, err
 	}
 	s.UpdateLastUsed()
 	return pt, nil
@@ -20,11 +20,16 @@ package session
 
 import (
 	"bytes"
+	"context"
+	"crypto/cipher"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"math"
+	"sync"
 	"testing"
 	"time"
 
@@ -81,8 +86,8 @@ func TestSecureSessionLifecycle(t *testing.T) {
 		helpers.LogDetail(t, "MAC (hex): %s", hex.EncodeToString(mac)[:32]+"...")
 
 		// Specification Requirement: Nonce size validation (ChaCha20-Poly1305)
-		assert.GreaterOrEqual(t, len(ct), chacha20poly1305.NonceSize, "Ciphertext must include nonce")
-		nonce := ct[:chacha20poly1305.NonceSize]
+		assert.GreaterOrEqual(t, len(ct), FrameHeaderSize+chacha20poly1305.NonceSize, "Ciphertext must include nonce")
+		nonce := ct[FrameHeaderSize : FrameHeaderSize+chacha20poly1305.NonceSize]
 		helpers.LogDetail(t, "Nonce size: %d bytes", len(nonce))
 		helpers.LogDetail(t, "Nonce (hex): %s", hex.EncodeToString(nonce))
 
@@ -361,11 +366,11 @@ func TestSecureSession_WithParamsSuite(t *testing.T) {
 		require.NoError(t, err)
 
 		require.NotEqual(t, ct1, ct2)
-		require.True(t, len(ct1) > chacha20poly1305.NonceSize)
-		require.True(t, len(ct2) > chacha20poly1305.NonceSize)
+		require.True(t, len(ct1) > FrameHeaderSize+chacha20poly1305.NonceSize)
+		require.True(t, len(ct2) > FrameHeaderSize+chacha20poly1305.NonceSize)
 
-		nonce1 := ct1[:chacha20poly1305.NonceSize]
-		nonce2 := ct2[:chacha20poly1305.NonceSize]
+		nonce1 := ct1[FrameHeaderSize : FrameHeaderSize+chacha20poly1305.NonceSize]
+		nonce2 := ct2[FrameHeaderSize : FrameHeaderSize+chacha20poly1305.NonceSize]
 		require.NotEqual(t, nonce1, nonce2)
 	})
 
@@ -428,9 +433,10 @@ func TestSecureSession_WithParamsSuite(t *testing.T) {
 		pt := []byte("format-check")
 		ct, mac, err := s.EncryptAndSign(pt, covered)
 		require.NoError(t, err)
-		require.Greater(t, len(ct), chacha20poly1305.NonceSize)
+		require.Greater(t, len(ct), FrameHeaderSize+chacha20poly1305.NonceSize)
+		require.Equal(t, []byte{CiphertextVersion1, CipherChaCha20Poly1305}, ct[:FrameHeaderSize])
 
-		nonce := ct[:chacha20poly1305.NonceSize]
+		nonce := ct[FrameHeaderSize : FrameHeaderSize+chacha20poly1305.NonceSize]
 		require.Len(t, nonce, chacha20poly1305.NonceSize)
 		require.Len(t, mac, sha256.Size)
 
@@ -1173,7 +1179,7 @@ func TestSessionEncryption(t *testing.T) {
 	helpers.LogDetail(t, "  MAC 크기: %d bytes", len(mac))
 
 	// Verify ciphertext is different from plaintext
-	assert.NotEqual(t, sensitiveData, ciphertext[chacha20poly1305.NonceSize:])
+	assert.NotEqual(t, sensitiveData, ciphertext[FrameHeaderSize+chacha20poly1305.NonceSize:])
 	helpers.LogSuccess(t, "암호문이 평문과 다름 확인")
 
 	// Decrypt
@@ -1379,3 +1385,558 @@ func TestSessionSync(t *testing.T) {
 	}
 	helpers.SaveTestData(t, "session/session_sync.json", testData)
 }
+
+func TestSecureSession_ConcurrentUseAndSnapshot(t *testing.T) {
+	// Meaningful under `go test -race`: one session hammered from many goroutines
+	exporter := b(32)
+	cfg := Config{MaxAge: time.Hour, IdleTimeout: time.Hour}
+	alice, err := NewSecureSessionFromExporterWithRole("race-sid", exporter, true, cfg)
+	require.NoError(t, err)
+	bob, err := NewSecureSessionFromExporterWithRole("race-sid", exporter, false, cfg)
+	require.NoError(t, err)
+
+	const workers, perWorker = 16, 50
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*perWorker)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				msg := []byte(fmt.Sprintf("msg-%d-%d", w, i))
+				ct, err := alice.Encrypt(msg)
+				if err != nil {
+					errs <- err
+					return
+				}
+				pt, err := bob.Decrypt(ct)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if !bytes.Equal(msg, pt) {
+					errs <- fmt.Errorf("plaintext mismatch for %s", msg)
+					return
+				}
+				sig := alice.SignCovered(msg)
+				if err := alice.VerifyCovered(msg, sig); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				snap := alice.Snapshot()
+				if snap.ID != "race-sid" || snap.MessageCount < 0 {
+					errs <- fmt.Errorf("inconsistent snapshot: %+v", snap)
+					return
+				}
+				_ = alice.GetLastUsedAt()
+				_ = alice.GetMessageCount()
+				_ = alice.IsExpired()
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	// Each iteration counts one Encrypt, one SignCovered and one VerifyCovered
+	snap := alice.Snapshot()
+	assert.Equal(t, workers*perWorker*3, snap.MessageCount)
+	assert.Equal(t, workers*perWorker, bob.Snapshot().MessageCount)
+	assert.True(t, snap.Initiator)
+	assert.False(t, snap.Closed)
+	assert.False(t, snap.Expired)
+
+	// Snapshots are copies: later activity does not change them
+	_, err = alice.Encrypt([]byte("later"))
+	require.NoError(t, err)
+	assert.Equal(t, workers*perWorker*3, snap.MessageCount)
+
+	t.Run("close during traffic", func(t *testing.T) {
+		sess, err := NewSecureSessionFromExporterWithRole("close-sid", exporter, true, cfg)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < perWorker; i++ {
+					// Errors are expected once Close wins; only races matter here
+					_, _ = sess.Encrypt([]byte("payload"))
+					_ = sess.SignCovered([]byte("covered"))
+				}
+			}()
+		}
+		require.NoError(t, sess.Close())
+		wg.Wait()
+
+		snap := sess.Snapshot()
+		assert.True(t, snap.Closed)
+		assert.True(t, snap.Expired)
+		_, err = sess.Encrypt([]byte("after close"))
+		assert.Error(t, err)
+	})
+}
+
+// TestSecureSession_EmptyPlaintext pins down the contract for empty input:
+// nil and zero-length plaintexts seal to a frame header, nonce and tag and open to an
+// empty slice, while empty or truncated ciphertexts are rejected.
+func TestSecureSession_EmptyPlaintext(t *testing.T) {
+	exporter := b(32)
+	legacy, err := NewSecureSession("empty-legacy", b(32), Config{})
+	require.NoError(t, err)
+	initiator, err := NewSecureSessionFromExporterWithRole("empty-dir", exporter, true, Config{})
+	require.NoError(t, err)
+	responder, err := NewSecureSessionFromExporterWithRole("empty-dir", exporter, false, Config{})
+	require.NoError(t, err)
+
+	pairs := []struct {
+		name     string
+		sender   *SecureSession
+		receiver *SecureSession
+	}{
+		{"legacy", legacy, legacy},
+		{"directional", initiator, responder},
+	}
+
+	for _, p := range pairs {
+		for _, pt := range [][]byte{nil, {}} {
+			name := fmt.Sprintf("%s/plaintext=%#v", p.name, pt)
+
+			t.Run(name+"/Encrypt", func(t *testing.T) {
+				ct, err := p.sender.Encrypt(pt)
+				require.NoError(t, err)
+				require.Len(t, ct, MinCiphertextSize, "empty plaintext still carries nonce and tag")
+
+				got, err := p.receiver.Decrypt(ct)
+				require.NoError(t, err)
+				require.NotNil(t, got)
+				require.Empty(t, got)
+
+				// The tag authenticates the empty message
+				ct[len(ct)-1] ^= 0x01
+				_, err = p.receiver.Decrypt(ct)
+				require.Error(t, err)
+			})
+
+			t.Run(name+"/EncryptWithAAD", func(t *testing.T) {
+				ct, err := p.sender.EncryptWithAAD(pt, []byte("aad"))
+				require.NoError(t, err)
+				require.Len(t, ct, MinCiphertextSize)
+
+				got, err := p.receiver.DecryptWithAAD(ct, []byte("aad"))
+				require.NoError(t, err)
+				require.NotNil(t, got)
+				require.Empty(t, got)
+
+				_, err = p.receiver.DecryptWithAAD(ct, []byte("other"))
+				require.Error(t, err)
+			})
+		}
+	}
+
+	t.Run("EncryptAndSign", func(t *testing.T) {
+		ct, mac, err := legacy.EncryptAndSign(nil, []byte("covered"))
+		require.NoError(t, err)
+		require.Len(t, ct, MinCiphertextSize)
+
+		got, err := legacy.DecryptAndVerify(ct, []byte("covered"), mac)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		require.Empty(t, got)
+	})
+
+	t.Run("short ciphertext", func(t *testing.T) {
+		valid, err := initiator.Encrypt([]byte("hello"))
+		require.NoError(t, err)
+
+		for _, data := range [][]byte{
+			nil,
+			{},
+			b(FrameHeaderSize + chacha20poly1305.NonceSize),    // header and nonce only
+			b(MinCiphertextSize - 1),                           // one byte short of header+nonce+tag
+			valid[:FrameHeaderSize+chacha20poly1305.NonceSize], // truncated to its nonce
+		} {
+			_, err := legacy.Decrypt(data)
+			require.ErrorIs(t, err, ErrCiphertextTooShort, "legacy len=%d", len(data))
+			_, err = responder.Decrypt(data)
+			require.ErrorIs(t, err, ErrCiphertextTooShort, "directional len=%d", len(data))
+			_, err = responder.DecryptWithAAD(data, nil)
+			require.ErrorIs(t, err, ErrCiphertextTooShort)
+		}
+
+		// Long enough to parse but cut short: authentication fails
+		_, err = responder.Decrypt(valid[:len(valid)-1])
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrCiphertextTooShort)
+	})
+}
+
+// TestSecureSession_CiphertextFraming checks the version/cipher header: the
+// current version round-trips on every path, and bumped or unknown header
+// bytes are rejected before any decryption is attempted.
+func TestSecureSession_CiphertextFraming(t *testing.T) {
+	exporter := b(32)
+	legacy, err := NewSecureSession("frame-legacy", b(32), Config{})
+	require.NoError(t, err)
+	initiator, err := NewSecureSessionFromExporterWithRole("frame-dir", exporter, true, Config{})
+	require.NoError(t, err)
+	responder, err := NewSecureSessionFromExporterWithRole("frame-dir", exporter, false, Config{})
+	require.NoError(t, err)
+
+	pt := []byte("framed message")
+	aad := []byte("aad")
+
+	type path struct {
+		name string
+		seal func() ([]byte, error)
+		open func([]byte) ([]byte, error)
+	}
+	paths := []path{
+		{"legacy/Encrypt", func() ([]byte, error) { return legacy.Encrypt(pt) }, legacy.Decrypt},
+		{"legacy/EncryptWithAAD",
+			func() ([]byte, error) { return legacy.EncryptWithAAD(pt, aad) },
+			func(ct []byte) ([]byte, error) { return legacy.DecryptWithAAD(ct, aad) }},
+		{"directional/Encrypt", func() ([]byte, error) { return initiator.Encrypt(pt) }, responder.Decrypt},
+		{"directional/EncryptWithAAD",
+			func() ([]byte, error) { return initiator.EncryptWithAAD(pt, aad) },
+			func(ct []byte) ([]byte, error) { return responder.DecryptWithAAD(ct, aad) }},
+	}
+
+	for _, p := range paths {
+		t.Run(p.name+"/current version round-trip", func(t *testing.T) {
+			ct, err := p.seal()
+			require.NoError(t, err)
+			require.Equal(t, CiphertextVersion1, ct[0])
+			require.Equal(t, CipherChaCha20Poly1305, ct[1])
+			require.Len(t, ct, MinCiphertextSize+len(pt))
+
+			got, err := p.open(ct)
+			require.NoError(t, err)
+			require.Equal(t, pt, got)
+		})
+
+		t.Run(p.name+"/unknown version rejected", func(t *testing.T) {
+			for _, v := range []byte{0x00, CiphertextVersion1 + 1, 0xff} {
+				ct, err := p.seal()
+				require.NoError(t, err)
+				ct[0] = v
+
+				_, err = p.open(ct)
+				require.ErrorIs(t, err, ErrUnsupportedCiphertextVersion, "version 0x%02x", v)
+			}
+		})
+
+		t.Run(p.name+"/unknown cipher rejected", func(t *testing.T) {
+			ct, err := p.seal()
+			require.NoError(t, err)
+			ct[1] = 0x7f
+
+			_, err = p.open(ct)
+			require.ErrorIs(t, err, ErrUnsupportedCipher)
+		})
+	}
+
+	t.Run("EncryptAndSign", func(t *testing.T) {
+		ct, mac, err := legacy.EncryptAndSign(pt, []byte("covered"))
+		require.NoError(t, err)
+		require.Equal(t, []byte{CiphertextVersion1, CipherChaCha20Poly1305}, ct[:FrameHeaderSize])
+
+		got, err := legacy.DecryptAndVerify(ct, []byte("covered"), mac)
+		require.NoError(t, err)
+		require.Equal(t, pt, got)
+
+		ct[0]++
+		_, err = legacy.DecryptAndVerify(ct, []byte("covered"), mac)
+		require.ErrorIs(t, err, ErrUnsupportedCiphertextVersion)
+	})
+
+	t.Run("Pre-framing ciphertext rejected", func(t *testing.T) {
+		// nonce || ciphertext as produced before framing was introduced
+		legacyAEAD, err := chacha20poly1305.New(b(32))
+		require.NoError(t, err)
+		nonce := b(chacha20poly1305.NonceSize)
+		old := legacyAEAD.Seal(append([]byte(nil), nonce...), nonce, pt, nil)
+
+		_, err = legacy.Decrypt(old)
+		require.Error(t, err)
+	})
+}
+
+// blockingAEAD stands in for an HSM-backed cipher that hangs until released.
+// If opened is set, Open signals it once it returns.
+type blockingAEAD struct {
+	cipher.AEAD
+	release chan struct{}
+	opened  chan struct{}
+}
+
+func (b *blockingAEAD) Seal(dst, nonce, plaintext, aad []byte) []byte {
+	<-b.release
+	return b.AEAD.Seal(dst, nonce, plaintext, aad)
+}
+
+func (b *blockingAEAD) Open(dst, nonce, ciphertext, aad []byte) ([]byte, error) {
+	<-b.release
+	if b.opened != nil {
+		defer func() { b.opened <- struct{}{} }()
+	}
+	return b.AEAD.Open(dst, nonce, ciphertext, aad)
+}
+
+func TestSecureSession_ContextCancellation(t *testing.T) {
+	seed := make([]byte, 32)
+	_, err := rand.Read(seed)
+	require.NoError(t, err)
+	sess, err := NewSecureSession("ctx-session", seed, Config{MaxAge: time.Hour, IdleTimeout: time.Hour, MaxMessages: 100})
+	require.NoError(t, err)
+
+	ct, err := sess.EncryptContext(context.Background(), []byte("hello"))
+	require.NoError(t, err)
+
+	slow := &blockingAEAD{AEAD: sess.aead, release: make(chan struct{})}
+	defer close(slow.release)
+	sess.mu.Lock()
+	sess.aead = slow
+	sess.mu.Unlock()
+
+	t.Run("Deadline interrupts a blocked encrypt", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := sess.EncryptContext(ctx, []byte("hello"))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("Cancel interrupts a blocked decrypt", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+		start := time.Now()
+		_, err := sess.DecryptContext(ctx, ct)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("Already cancelled context fails fast", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := sess.DecryptContext(ctx, ct)
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
+
+// setSendCounter is a test hook that moves the session's nonce counter.
+func setSendCounter(s *SecureSession, n uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sendCounter = n
+}
+
+func TestSecureSession_NonceCounter(t *testing.T) {
+	nonceOf := func(ct []byte) []byte {
+		return ct[FrameHeaderSize : FrameHeaderSize+chacha20poly1305.NonceSize]
+	}
+
+	t.Run("Nonces follow a big-endian counter", func(t *testing.T) {
+		sess, err := NewSecureSession("nonce-seq", b(32), Config{})
+		require.NoError(t, err)
+
+		var prev []byte
+		for i := 0; i < 300; i++ {
+			ct, err := sess.Encrypt([]byte("m"))
+			require.NoError(t, err)
+			if prev != nil {
+				// Consecutive counters c and c+1 differ only in their low bytes
+				diff := make([]byte, chacha20poly1305.NonceSize)
+				for j := range diff {
+					diff[j] = prev[j] ^ nonceOf(ct)[j]
+				}
+				require.Zero(t, diff[0]|diff[1]|diff[2]|diff[3], "IV prefix must not change")
+				require.NotEqual(t, make([]byte, chacha20poly1305.NonceSize), diff)
+			}
+			prev = append([]byte(nil), nonceOf(ct)...)
+		}
+	})
+
+	exporter := b(32)
+	legacy, err := NewSecureSession("nonce-legacy", b(32), Config{})
+	require.NoError(t, err)
+	initiator, err := NewSecureSessionFromExporterWithRole("nonce-dir", exporter, true, Config{})
+	require.NoError(t, err)
+	responder, err := NewSecureSessionFromExporterWithRole("nonce-dir", exporter, false, Config{})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		sess *SecureSession
+		open func([]byte) ([]byte, error)
+	}{
+		"legacy":      {legacy, legacy.Decrypt},
+		"directional": {initiator, responder.Decrypt},
+	} {
+		t.Run(name+"/refuses to encrypt once exhausted", func(t *testing.T) {
+			setSendCounter(tc.sess, math.MaxUint64-1)
+
+			// The last counter value is still usable
+			ct, err := tc.sess.Encrypt([]byte("last"))
+			require.NoError(t, err)
+			pt, err := tc.open(ct)
+			require.NoError(t, err)
+			require.Equal(t, []byte("last"), pt)
+
+			// Every sealing path then fails rather than wrapping to a used nonce
+			_, err = tc.sess.Encrypt([]byte("wrapped"))
+			require.ErrorIs(t, err, ErrNonceExhausted)
+			_, err = tc.sess.EncryptWithAAD([]byte("wrapped"), []byte("aad"))
+			require.ErrorIs(t, err, ErrNonceExhausted)
+			_, err = tc.sess.EncryptContext(context.Background(), []byte("wrapped"))
+			require.ErrorIs(t, err, ErrNonceExhausted)
+
+			// Decryption of already-received traffic is unaffected
+			pt, err = tc.open(ct)
+			require.NoError(t, err)
+			require.Equal(t, []byte("last"), pt)
+		})
+	}
+
+	t.Run("EncryptAndSign refuses once exhausted", func(t *testing.T) {
+		sess, err := NewSecureSession("nonce-sign", b(32), Config{})
+		require.NoError(t, err)
+		setSendCounter(sess, math.MaxUint64)
+		_, _, err = sess.EncryptAndSign([]byte("m"), []byte("covered"))
+		require.ErrorIs(t, err, ErrNonceExhausted)
+	})
+}
+
+func TestSecureSession_SequenceNumbers(t *testing.T) {
+	cfg := Config{SequenceNumbers: true, ReorderWindow: 4}
+	pair := func(t *testing.T) (*SecureSession, *SecureSession) {
+		exporter := b(32)
+		initiator, err := NewSecureSessionFromExporterWithRole("seq", exporter, true, cfg)
+		require.NoError(t, err)
+		responder, err := NewSecureSessionFromExporterWithRole("seq", exporter, false, cfg)
+		require.NoError(t, err)
+		return initiator, responder
+	}
+	seal := func(t *testing.T, s *SecureSession, n int) [][]byte {
+		out := make([][]byte, n)
+		for i := range out {
+			ct, err := s.Encrypt([]byte(fmt.Sprintf("msg-%d", i)))
+			require.NoError(t, err)
+			require.Equal(t, CiphertextVersion2, ct[0])
+			out[i] = ct
+		}
+		return out
+	}
+
+	t.Run("In-order messages are accepted", func(t *testing.T) {
+		initiator, responder := pair(t)
+		for i, ct := range seal(t, initiator, 10) {
+			pt, err := responder.Decrypt(ct)
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprintf("msg-%d", i), string(pt))
+		}
+	})
+
+	t.Run("Duplicates are rejected", func(t *testing.T) {
+		initiator, responder := pair(t)
+		cts := seal(t, initiator, 3)
+		for _, ct := range cts {
+			_, err := responder.Decrypt(ct)
+			require.NoError(t, err)
+		}
+		for _, ct := range cts {
+			_, err := responder.Decrypt(ct)
+			require.ErrorIs(t, err, ErrDuplicateSequence)
+		}
+	})
+
+	t.Run("Reordering within the window is accepted", func(t *testing.T) {
+		initiator, responder := pair(t)
+		cts := seal(t, initiator, 4)
+		for _, i := range []int{3, 1, 0, 2} {
+			_, err := responder.Decrypt(cts[i])
+			require.NoError(t, err, "message %d", i)
+		}
+	})
+
+	t.Run("Messages older than the window are rejected", func(t *testing.T) {
+		initiator, responder := pair(t)
+		cts := seal(t, initiator, 6)
+		_, err := responder.Decrypt(cts[5])
+		require.NoError(t, err)
+		_, err = responder.Decrypt(cts[1])
+		require.ErrorIs(t, err, ErrStaleSequence)
+		_, err = responder.Decrypt(cts[2])
+		require.NoError(t, err, "3 behind still fits the window")
+	})
+
+	t.Run("Forged messages do not move the window", func(t *testing.T) {
+		initiator, responder := pair(t)
+		cts := seal(t, initiator, 2)
+		forged := append([]byte(nil), cts[1]...)
+		forged[len(forged)-1] ^= 0xff
+		_, err := responder.Decrypt(forged)
+		require.Error(t, err)
+		for _, ct := range cts {
+			_, err := responder.Decrypt(ct)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("A canceled decrypt can be retried", func(t *testing.T) {
+		initiator, responder := pair(t)
+		ct := seal(t, initiator, 1)[0]
+
+		in := responder.aeadIn
+		slow := &blockingAEAD{AEAD: in, release: make(chan struct{}), opened: make(chan struct{}, 1)}
+		responder.mu.Lock()
+		responder.aeadIn = slow
+		responder.mu.Unlock()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+		_, err := responder.DecryptContext(ctx, ct)
+		require.ErrorIs(t, err, context.Canceled)
+
+		// Let the abandoned decrypt finish before retrying
+		close(slow.release)
+		<-slow.opened
+		responder.mu.Lock()
+		responder.aeadIn = in
+		responder.mu.Unlock()
+
+		pt, err := responder.Decrypt(ct)
+		require.NoError(t, err)
+		require.Equal(t, "msg-0", string(pt))
+		_, err = responder.Decrypt(ct)
+		require.ErrorIs(t, err, ErrDuplicateSequence)
+	})
+
+	t.Run("Both peers must enable sequence numbers", func(t *testing.T) {
+		exporter := b(32)
+		plain, err := NewSecureSessionFromExporterWithRole("seq", exporter, true, Config{})
+		require.NoError(t, err)
+		sequenced, err := NewSecureSessionFromExporterWithRole("seq", exporter, false, cfg)
+		require.NoError(t, err)
+
+		ct, err := plain.Encrypt([]byte("unsequenced"))
+		require.NoError(t, err)
+		_, err = sequenced.Decrypt(ct)
+		require.ErrorIs(t, err, ErrUnsupportedCiphertextVersion)
+	})
+}
@@ -0,0 +1,122 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package session
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+)
+
+// SessionStats describes one active session for monitoring. Like Snapshot it
+// carries no key material.
+type SessionStats struct {
+	SessionID    string        `json:"sessionId"`
+	KeyIDs       []string      `json:"keyIds,omitempty"`
+	PeerDID      string        `json:"peerDid,omitempty"`
+	Initiator    bool          `json:"initiator"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	LastUsedAt   time.Time     `json:"lastUsedAt"`
+	Age          time.Duration `json:"age"`
+	Idle         time.Duration `json:"idle"`
+	MessageCount int           `json:"messageCount"`
+	Expired      bool          `json:"expired"`
+}
+
+// StatsEncoder serializes session stats for an external monitoring system
+type StatsEncoder interface {
+	Encode(w io.Writer, takenAt time.Time, stats []SessionStats) error
+}
+
+// JSONStatsEncoder writes {"takenAt": ..., "sessions": [...]} as one JSON document
+type JSONStatsEncoder struct{}
+
+// Encode implements StatsEncoder
+func (JSONStatsEncoder) Encode(w io.Writer, takenAt time.Time, stats []SessionStats) error {
+	return json.NewEncoder(w).Encode(struct {
+		TakenAt  time.Time      `json:"takenAt"`
+		Sessions []SessionStats `json:"sessions"`
+	}{takenAt, stats})
+}
+
+// SnapshotAll returns stats for every session, ordered by session ID.
+//
+// The session set, key IDs and DIDs are captured together under the
+// manager's read lock, which is released before the sessions are read; each
+// session is then read under its own read lock and Age and Idle are measured
+// against a single instant. Encrypt and Decrypt are therefore never blocked
+// for longer than one session read.
+func (m *Manager) SnapshotAll() []SessionStats {
+	stats, _ := m.snapshotAll()
+	return stats
+}
+
+// WriteSnapshot takes SnapshotAll and serializes it with enc. A nil enc uses
+// JSONStatsEncoder.
+func (m *Manager) WriteSnapshot(w io.Writer, enc StatsEncoder) error {
+	if enc == nil {
+		enc = JSONStatsEncoder{}
+	}
+	stats, takenAt := m.snapshotAll()
+	return enc.Encode(w, takenAt, stats)
+}
+
+func (m *Manager) snapshotAll() ([]SessionStats, time.Time) {
+	type entry struct {
+		sess   Session
+		keyIDs []string
+		did    string
+	}
+
+	m.mu.RLock()
+	entries := make([]entry, 0, len(m.sessions))
+	for sid, sess := range m.sessions {
+		e := entry{sess: sess, did: m.didBySID[sid]}
+		for kid := range m.keyIDsBySID[sid] {
+			e.keyIDs = append(e.keyIDs, kid)
+		}
+		entries = append(entries, e)
+	}
+	m.mu.RUnlock()
+
+	now := time.Now()
+	stats := make([]SessionStats, 0, len(entries))
+	for _, e := range entries {
+		snap := e.sess.Snapshot()
+		if snap.Closed {
+			continue
+		}
+		sort.Strings(e.keyIDs)
+		stats = append(stats, SessionStats{
+			SessionID:    snap.ID,
+			KeyIDs:       e.keyIDs,
+			PeerDID:      e.did,
+			Initiator:    snap.Initiator,
+			CreatedAt:    snap.CreatedAt,
+			LastUsedAt:   snap.LastUsedAt,
+			Age:          now.Sub(snap.CreatedAt),
+			Idle:         now.Sub(snap.LastUsedAt),
+			MessageCount: snap.MessageCount,
+			Expired:      snap.Expired,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].SessionID < stats[j].SessionID })
+	return stats, now
+}
@@ -0,0 +1,132 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package session
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_SnapshotAll(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Close() }()
+
+	const sessions = 4
+	secrets := make([][]byte, sessions)
+	for i := 0; i < sessions; i++ {
+		secrets[i] = make([]byte, 32)
+		_, err := rand.Read(secrets[i])
+		require.NoError(t, err)
+		sid := fmt.Sprintf("sess-%d", i)
+		_, err = mgr.CreateSession(sid, secrets[i])
+		require.NoError(t, err)
+		mgr.BindKeyID(fmt.Sprintf("kid-%d", i), sid)
+		mgr.BindDID(fmt.Sprintf("did:sage:test:peer-%d", i), sid)
+	}
+
+	t.Run("Reports every session without secrets", func(t *testing.T) {
+		stats := mgr.SnapshotAll()
+		require.Len(t, stats, sessions)
+		for i, s := range stats {
+			require.Equal(t, fmt.Sprintf("sess-%d", i), s.SessionID)
+			require.Equal(t, []string{fmt.Sprintf("kid-%d", i)}, s.KeyIDs)
+			require.Equal(t, fmt.Sprintf("did:sage:test:peer-%d", i), s.PeerDID)
+			require.GreaterOrEqual(t, s.Age, s.Idle)
+			require.False(t, s.Expired)
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, mgr.WriteSnapshot(&buf, nil))
+		var doc struct {
+			TakenAt  time.Time      `json:"takenAt"`
+			Sessions []SessionStats `json:"sessions"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		require.Len(t, doc.Sessions, sessions)
+		require.False(t, doc.TakenAt.IsZero())
+		for _, secret := range secrets {
+			require.NotContains(t, buf.String(), hex.EncodeToString(secret))
+		}
+	})
+
+	t.Run("Snapshots run concurrently with traffic", func(t *testing.T) {
+		const perSession = 200
+		var wg sync.WaitGroup
+		stop := make(chan struct{})
+
+		for i := 0; i < sessions; i++ {
+			sess, ok := mgr.GetByKeyID(fmt.Sprintf("kid-%d", i))
+			require.True(t, ok)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < perSession; j++ {
+					ct, err := sess.Encrypt([]byte("payload"))
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					if _, err := sess.Decrypt(ct); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}()
+		}
+
+		var snapshotter sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			snapshotter.Add(1)
+			go func() {
+				defer snapshotter.Done()
+				var last = make(map[string]int)
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					for _, s := range mgr.SnapshotAll() {
+						// Counters only grow between snapshots
+						if s.MessageCount < last[s.SessionID] {
+							t.Errorf("message count went backwards for %s", s.SessionID)
+						}
+						last[s.SessionID] = s.MessageCount
+					}
+				}
+			}()
+		}
+
+		wg.Wait()
+		close(stop)
+		snapshotter.Wait()
+
+		for _, s := range mgr.SnapshotAll() {
+			require.Equal(t, 2*perSession, s.MessageCount)
+		}
+	})
+}
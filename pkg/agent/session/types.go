@@ -19,12 +19,46 @@
 package session
 
 import (
+	"context"
+	"errors"
 	"time"
 )
 
 const GeneralPrefix = "session"
 
-// Session represents an active cryptographic session between two agents
+var (
+	// ErrSessionNotFound is returned when no session was ever established for
+	// a lookup key (or its end has since been forgotten).
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrSessionRevoked is returned when the session was killed by a revocation.
+	ErrSessionRevoked = errors.New("session revoked")
+	// ErrSessionExpired is returned when the session outlived its MaxAge or
+	// IdleTimeout.
+	ErrSessionExpired = errors.New("session expired")
+	// ErrSessionExhausted is returned when the session reached MaxMessages.
+	ErrSessionExhausted = errors.New("session message limit exhausted")
+	// ErrCiphertextTooShort is returned when sealed data is shorter than the
+	// frame header, nonce and authentication tag (see MinCiphertextSize).
+	ErrCiphertextTooShort = errors.New("ciphertext too short")
+	// ErrUnsupportedCiphertextVersion is returned when sealed data carries a
+	// framing version this build does not understand.
+	ErrUnsupportedCiphertextVersion = errors.New("unsupported ciphertext version")
+	// ErrUnsupportedCipher is returned when sealed data names a cipher this
+	// session does not use.
+	ErrUnsupportedCipher = errors.New("unsupported cipher")
+	// ErrNonceExhausted is returned by Encrypt once the session's nonce
+	// counter is used up. The session must be replaced by a new handshake.
+	ErrNonceExhausted = errors.New("session nonce space exhausted")
+	// ErrDuplicateSequence is returned when a message's sequence number was
+	// already accepted by the session.
+	ErrDuplicateSequence = errors.New("duplicate message sequence number")
+	// ErrStaleSequence is returned when a message's sequence number is older
+	// than the session's reorder window.
+	ErrStaleSequence = errors.New("message sequence number outside reorder window")
+)
+
+// Session represents an active cryptographic session between two agents.
+// Implementations must be safe for concurrent use by multiple goroutines.
 type Session interface {
 	// Identification
 	GetID() string
@@ -39,6 +73,10 @@ type Session interface {
 	// Cryptographic operations
 	Encrypt(plaintext []byte) ([]byte, error)
 	Decrypt(data []byte) ([]byte, error)
+	// EncryptContext and DecryptContext return once ctx is done, for
+	// ciphers backed by a remote HSM or KMS
+	EncryptContext(ctx context.Context, plaintext []byte) ([]byte, error)
+	DecryptContext(ctx context.Context, data []byte) ([]byte, error)
 	EncryptAndSign(plaintext []byte, covered []byte) ([]byte, []byte, error)
 	DecryptAndVerify(cipher []byte, covered []byte, mac []byte) ([]byte, error)
 	SignCovered(covered []byte) []byte
@@ -46,6 +84,20 @@ type Session interface {
 	// Statistics
 	GetMessageCount() int
 	GetConfig() Config
+	Snapshot() Snapshot
+}
+
+// Snapshot is an immutable, point-in-time view of a session's state.
+// It carries no key material and is safe to log or export as metrics.
+type Snapshot struct {
+	ID           string    `json:"id"`
+	CreatedAt    time.Time `json:"createdAt"`
+	LastUsedAt   time.Time `json:"lastUsedAt"`
+	MessageCount int       `json:"messageCount"`
+	Config       Config    `json:"config"`
+	Initiator    bool      `json:"initiator"`
+	Closed       bool      `json:"closed"`
+	Expired      bool      `json:"expired"`
 }
 
 // Config defines session policies and limits
@@ -53,6 +105,17 @@ type Config struct {
 	MaxAge      time.Duration `json:"maxAge"`      // absolute expiration (ex: 1 hour)
 	IdleTimeout time.Duration `json:"idleTimeout"` // idle timeout (ex: 10munutes)
 	MaxMessages int           `json:"maxMessages"`
+
+	// SequenceNumbers embeds a per-direction sequence number in every sealed
+	// message and makes Decrypt reject duplicates and messages older than
+	// ReorderWindow. Both peers must enable it. It is off by default; an HPKE
+	// client proposes it when its manager's default config enables it (see
+	// Manager.SetDefaultConfig), and the server's session follows the proposal.
+	SequenceNumbers bool `json:"sequenceNumbers,omitempty"`
+	// ReorderWindow is how far behind the newest accepted sequence number a
+	// message may arrive; 0 means DefaultReorderWindow, and values above
+	// MaxReorderWindow are capped.
+	ReorderWindow int `json:"reorderWindow,omitempty"`
 }
 
 // Status provides information about session status
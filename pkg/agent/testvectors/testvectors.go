@@ -0,0 +1,541 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+// Package testvectors generates and checks the conformance vectors shared
+// with the Rust, Python, TypeScript and Java bindings.
+//
+// Every input is derived from a fixed label, so regenerating the vectors
+// reproduces the same keys, signatures, session IDs and MACs. HPKE packets
+// (and the exporters bound to their ephemeral key) and session ciphertexts
+// change on every run; bindings must open the committed bytes rather than
+// reproduce them.
+//
+// The golden files live in pkg/agent/testvectors/vectors. Regenerate them with:
+//
+//	go test ./pkg/agent/testvectors -update
+package testvectors
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	sagecrypto "github.com/sage-x-project/sage/pkg/agent/crypto"
+	"github.com/sage-x-project/sage/pkg/agent/crypto/keys"
+	"github.com/sage-x-project/sage/pkg/agent/session"
+)
+
+// File names of the golden vector files
+const (
+	SignaturesFile = "signatures.json"
+	HPKEFile       = "hpke.json"
+	SessionFile    = "session.json"
+)
+
+// Version identifies the vector format; bump it on incompatible changes
+const Version = 1
+
+// SignatureVector is a (key, message, signature) tuple. Ed25519 signs the
+// raw message, secp256k1 signs its Keccak-256 hash and returns r||s||v,
+// P-256 signs its SHA-256 hash (RFC 6979) and returns r||s.
+type SignatureVector struct {
+	Name          string `json:"name"`
+	Algorithm     string `json:"algorithm"`
+	PrivateKeyHex string `json:"private_key_hex"`
+	PublicKeyHex  string `json:"public_key_hex"`
+	MessageHex    string `json:"message_hex"`
+	SignatureHex  string `json:"signature_hex"`
+}
+
+// HPKEVector is an HPKE Base mode seal to the recipient key. PacketHex is
+// enc||ciphertext with info as the AAD; ExporterHex is the 32-byte secret
+// exported under the export context.
+type HPKEVector struct {
+	Name                   string `json:"name"`
+	Suite                  string `json:"suite"`
+	RecipientPrivateKeyHex string `json:"recipient_private_key_hex"`
+	RecipientPublicKeyHex  string `json:"recipient_public_key_hex"`
+	InfoHex                string `json:"info_hex"`
+	ExportContextHex       string `json:"export_context_hex"`
+	PlaintextHex           string `json:"plaintext_hex"`
+	PacketHex              string `json:"packet_hex"`
+	ExporterHex            string `json:"exporter_hex"`
+}
+
+// SessionVector is one message on a secure session. Directional vectors
+// derive the session from an HPKE exporter and are sealed by the initiator;
+// the others derive it from a shared secret and ephemeral keys
+// (session.NewSecureSessionWithParams) and also carry the HMAC of CoveredHex.
+type SessionVector struct {
+	Name            string `json:"name"`
+	Directional     bool   `json:"directional"`
+	SharedSecretHex string `json:"shared_secret_hex,omitempty"`
+	ContextID       string `json:"context_id,omitempty"`
+	SelfEphHex      string `json:"self_eph_hex,omitempty"`
+	PeerEphHex      string `json:"peer_eph_hex,omitempty"`
+	Label           string `json:"label,omitempty"`
+	ExporterHex     string `json:"exporter_hex,omitempty"`
+	SessionID       string `json:"session_id"`
+	PlaintextHex    string `json:"plaintext_hex"`
+	AADHex          string `json:"aad_hex"`
+	CiphertextHex   string `json:"ciphertext_hex"`
+	CoveredHex      string `json:"covered_hex,omitempty"`
+	MACHex          string `json:"mac_hex,omitempty"`
+}
+
+// SignatureVectors is the content of SignaturesFile
+type SignatureVectors struct {
+	Version int               `json:"version"`
+	Vectors []SignatureVector `json:"vectors"`
+}
+
+// HPKEVectors is the content of HPKEFile
+type HPKEVectors struct {
+	Version int          `json:"version"`
+	Vectors []HPKEVector `json:"vectors"`
+}
+
+// SessionVectors is the content of SessionFile
+type SessionVectors struct {
+	Version int             `json:"version"`
+	Vectors []SessionVector `json:"vectors"`
+}
+
+// Set is a complete collection of vectors
+type Set struct {
+	Signatures SignatureVectors
+	HPKE       HPKEVectors
+	Session    SessionVectors
+}
+
+// seed derives deterministic key material from a label
+func seed(label string) []byte {
+	sum := sha256.Sum256([]byte("sage-testvectors/" + label))
+	return sum[:]
+}
+
+var messages = []struct {
+	name string
+	data []byte
+}{
+	{"empty", []byte{}},
+	{"ascii", []byte("SAGE conformance vector")},
+	{"binary", bytes.Repeat([]byte{0x00, 0xff, 0x5a}, 43)},
+}
+
+// Generate builds a fresh vector set
+func Generate() (*Set, error) {
+	set := &Set{
+		Signatures: SignatureVectors{Version: Version},
+		HPKE:       HPKEVectors{Version: Version},
+		Session:    SessionVectors{Version: Version},
+	}
+
+	for _, alg := range []string{"ed25519", "secp256k1", "p256"} {
+		priv := seed("sign/" + alg)
+		pub, err := signaturePublicKey(alg, priv)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range messages {
+			sig, err := sign(alg, priv, m.data)
+			if err != nil {
+				return nil, fmt.Errorf("%s/%s: %w", alg, m.name, err)
+			}
+			set.Signatures.Vectors = append(set.Signatures.Vectors, SignatureVector{
+				Name:          alg + "/" + m.name,
+				Algorithm:     alg,
+				PrivateKeyHex: hex.EncodeToString(priv),
+				PublicKeyHex:  hex.EncodeToString(pub),
+				MessageHex:    hex.EncodeToString(m.data),
+				SignatureHex:  hex.EncodeToString(sig),
+			})
+		}
+	}
+
+	for _, suite := range []string{"x25519", "p256"} {
+		priv, err := hpkePrivateKey(suite, seed("hpke/"+suite))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range messages {
+			info := []byte("sage/hpke-info|" + suite + "|" + m.name)
+			exportCtx := []byte("sage/export-ctx|" + suite)
+			packet, exporter, err := keys.HPKESealAndExportToPeer(priv.PublicKey(), m.data, info, exportCtx, 32)
+			if err != nil {
+				return nil, fmt.Errorf("hpke %s/%s: %w", suite, m.name, err)
+			}
+			set.HPKE.Vectors = append(set.HPKE.Vectors, HPKEVector{
+				Name:                   suite + "/" + m.name,
+				Suite:                  suite,
+				RecipientPrivateKeyHex: hex.EncodeToString(priv.Bytes()),
+				RecipientPublicKeyHex:  hex.EncodeToString(priv.PublicKey().Bytes()),
+				InfoHex:                hex.EncodeToString(info),
+				ExportContextHex:       hex.EncodeToString(exportCtx),
+				PlaintextHex:           hex.EncodeToString(m.data),
+				PacketHex:              hex.EncodeToString(packet),
+				ExporterHex:            hex.EncodeToString(exporter),
+			})
+		}
+	}
+
+	for _, m := range messages {
+		aad := []byte("sage/aad|" + m.name)
+
+		v := SessionVector{
+			Name:            "params/" + m.name,
+			SharedSecretHex: hex.EncodeToString(seed("session/shared")),
+			ContextID:       "ctx-" + m.name,
+			SelfEphHex:      hex.EncodeToString(seed("session/eph-a")),
+			PeerEphHex:      hex.EncodeToString(seed("session/eph-b")),
+			Label:           "a2a/handshake v1",
+			PlaintextHex:    hex.EncodeToString(m.data),
+			AADHex:          hex.EncodeToString(aad),
+			CoveredHex:      hex.EncodeToString(append([]byte("covered|"), m.data...)),
+		}
+		sender, err := paramsSession(v)
+		if err != nil {
+			return nil, err
+		}
+		ct, err := sender.EncryptWithAAD(m.data, aad)
+		if err != nil {
+			return nil, fmt.Errorf("session %s: %w", v.Name, err)
+		}
+		v.SessionID = sender.GetID()
+		v.CiphertextHex = hex.EncodeToString(ct)
+		v.MACHex = hex.EncodeToString(sender.SignCovered(unhex(v.CoveredHex)))
+		set.Session.Vectors = append(set.Session.Vectors, v)
+
+		d := SessionVector{
+			Name:         "directional/" + m.name,
+			Directional:  true,
+			ExporterHex:  hex.EncodeToString(seed("session/exporter")),
+			SessionID:    "sid-" + m.name,
+			PlaintextHex: hex.EncodeToString(m.data),
+			AADHex:       hex.EncodeToString(aad),
+		}
+		initiator, err := session.NewSecureSessionFromExporterWithRole(d.SessionID, unhex(d.ExporterHex), true, session.Config{})
+		if err != nil {
+			return nil, fmt.Errorf("session %s: %w", d.Name, err)
+		}
+		ct, err = initiator.EncryptWithAADOutbound(m.data, aad)
+		if err != nil {
+			return nil, fmt.Errorf("session %s: %w", d.Name, err)
+		}
+		d.CiphertextHex = hex.EncodeToString(ct)
+		set.Session.Vectors = append(set.Session.Vectors, d)
+	}
+
+	return set, nil
+}
+
+// Verify recomputes every deterministic output of set from its inputs and
+// opens every randomized one, returning the first mismatch.
+func Verify(set *Set) error {
+	for _, v := range set.Signatures.Vectors {
+		if err := verifySignature(v); err != nil {
+			return fmt.Errorf("signature vector %s: %w", v.Name, err)
+		}
+	}
+	for _, v := range set.HPKE.Vectors {
+		if err := verifyHPKE(v); err != nil {
+			return fmt.Errorf("hpke vector %s: %w", v.Name, err)
+		}
+	}
+	for _, v := range set.Session.Vectors {
+		if err := verifySession(v); err != nil {
+			return fmt.Errorf("session vector %s: %w", v.Name, err)
+		}
+	}
+	return nil
+}
+
+// Stable returns a copy of set with the randomized outputs (HPKE packets
+// and exporters, session ciphertexts) cleared, for comparing two generations.
+func Stable(set *Set) *Set {
+	out := &Set{
+		Signatures: SignatureVectors{Version: set.Signatures.Version, Vectors: append([]SignatureVector(nil), set.Signatures.Vectors...)},
+		HPKE:       HPKEVectors{Version: set.HPKE.Version, Vectors: append([]HPKEVector(nil), set.HPKE.Vectors...)},
+		Session:    SessionVectors{Version: set.Session.Version, Vectors: append([]SessionVector(nil), set.Session.Vectors...)},
+	}
+	for i := range out.HPKE.Vectors {
+		out.HPKE.Vectors[i].PacketHex = ""
+		out.HPKE.Vectors[i].ExporterHex = ""
+	}
+	for i := range out.Session.Vectors {
+		out.Session.Vectors[i].CiphertextHex = ""
+	}
+	return out
+}
+
+// Write stores set as indented JSON files in dir
+func Write(dir string, set *Set) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil { // #nosec G301 -- vectors are public test data
+		return fmt.Errorf("failed to create vector directory: %w", err)
+	}
+	files := map[string]interface{}{
+		SignaturesFile: set.Signatures,
+		HPKEFile:       set.HPKE,
+		SessionFile:    set.Session,
+	}
+	for name, content := range files {
+		data, err := json.MarshalIndent(content, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", name, err)
+		}
+		// #nosec G306 -- vectors are public test data
+		if err := os.WriteFile(filepath.Join(dir, name), append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Load reads a vector set written by Write
+func Load(dir string) (*Set, error) {
+	set := &Set{}
+	files := map[string]interface{}{
+		SignaturesFile: &set.Signatures,
+		HPKEFile:       &set.HPKE,
+		SessionFile:    &set.Session,
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(dir, name)) // #nosec G304 -- caller-chosen vector directory
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if err := json.Unmarshal(data, content); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+	}
+	return set, nil
+}
+
+func signaturePublicKey(alg string, priv []byte) ([]byte, error) {
+	switch alg {
+	case "ed25519":
+		return ed25519.NewKeyFromSeed(priv).Public().(ed25519.PublicKey), nil
+	case "secp256k1":
+		return secp256k1.PrivKeyFromBytes(priv).PubKey().SerializeUncompressed(), nil
+	case "p256":
+		key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), priv)
+		if err != nil {
+			return nil, err
+		}
+		return key.PublicKey.Bytes()
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", alg)
+	}
+}
+
+func keyPair(alg string, priv []byte) (sagecrypto.KeyPair, error) {
+	switch alg {
+	case "ed25519":
+		return keys.NewEd25519KeyPair(ed25519.NewKeyFromSeed(priv), "")
+	case "secp256k1":
+		return keys.NewSecp256k1KeyPair(secp256k1.PrivKeyFromBytes(priv), "")
+	case "p256":
+		key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), priv)
+		if err != nil {
+			return nil, err
+		}
+		return keys.NewP256KeyPair(key, "")
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", alg)
+	}
+}
+
+func sign(alg string, priv, message []byte) ([]byte, error) {
+	if alg != "p256" {
+		kp, err := keyPair(alg, priv)
+		if err != nil {
+			return nil, err
+		}
+		return kp.Sign(message)
+	}
+
+	// keys.p256KeyPair signs with fresh randomness; a nil source selects
+	// RFC 6979 so the vector is reproducible
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), priv)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(message)
+	der, err := key.Sign(nil, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	var sig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, err
+	}
+	raw := make([]byte, 64)
+	sig.R.FillBytes(raw[:32])
+	sig.S.FillBytes(raw[32:])
+	return raw, nil
+}
+
+func verifySignature(v SignatureVector) error {
+	priv, err := hex.DecodeString(v.PrivateKeyHex)
+	if err != nil {
+		return err
+	}
+	message, err := hex.DecodeString(v.MessageHex)
+	if err != nil {
+		return err
+	}
+	signature, err := hex.DecodeString(v.SignatureHex)
+	if err != nil {
+		return err
+	}
+
+	pub, err := signaturePublicKey(v.Algorithm, priv)
+	if err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(pub); got != v.PublicKeyHex {
+		return fmt.Errorf("public key mismatch: got %s", got)
+	}
+	kp, err := keyPair(v.Algorithm, priv)
+	if err != nil {
+		return err
+	}
+	if err := kp.Verify(message, signature); err != nil {
+		return fmt.Errorf("signature does not verify: %w", err)
+	}
+	expected, err := sign(v.Algorithm, priv, message)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(expected, signature) {
+		return fmt.Errorf("signature mismatch: got %x", expected)
+	}
+	return nil
+}
+
+func hpkePrivateKey(suite string, priv []byte) (*ecdh.PrivateKey, error) {
+	switch suite {
+	case "x25519":
+		return ecdh.X25519().NewPrivateKey(priv)
+	case "p256":
+		return ecdh.P256().NewPrivateKey(priv)
+	default:
+		return nil, fmt.Errorf("unsupported suite: %s", suite)
+	}
+}
+
+func verifyHPKE(v HPKEVector) error {
+	privBytes, err := hex.DecodeString(v.RecipientPrivateKeyHex)
+	if err != nil {
+		return err
+	}
+	priv, err := hpkePrivateKey(v.Suite, privBytes)
+	if err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(priv.PublicKey().Bytes()); got != v.RecipientPublicKeyHex {
+		return fmt.Errorf("recipient public key mismatch: got %s", got)
+	}
+
+	plaintext, exporter, err := keys.HPKEOpenAndExportWithPriv(priv, unhex(v.PacketHex), unhex(v.InfoHex), unhex(v.ExportContextHex), 32)
+	if err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(plaintext); got != v.PlaintextHex {
+		return fmt.Errorf("plaintext mismatch: got %s", got)
+	}
+	if got := hex.EncodeToString(exporter); got != v.ExporterHex {
+		return fmt.Errorf("exporter mismatch: got %s", got)
+	}
+	return nil
+}
+
+func paramsSession(v SessionVector) (*session.SecureSession, error) {
+	sess, err := session.NewSecureSessionWithParams(unhex(v.SharedSecretHex), session.Params{
+		ContextID: v.ContextID,
+		SelfEph:   unhex(v.SelfEphHex),
+		PeerEph:   unhex(v.PeerEphHex),
+		Label:     v.Label,
+	}, session.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("session %s: %w", v.Name, err)
+	}
+	return sess, nil
+}
+
+func verifySession(v SessionVector) error {
+	var plaintext []byte
+	ciphertext := unhex(v.CiphertextHex)
+	aad := unhex(v.AADHex)
+
+	if v.Directional {
+		responder, err := session.NewSecureSessionFromExporterWithRole(v.SessionID, unhex(v.ExporterHex), false, session.Config{})
+		if err != nil {
+			return err
+		}
+		plaintext, err = responder.DecryptWithAADInbound(ciphertext, aad)
+		if err != nil {
+			return err
+		}
+	} else {
+		// The receiver sees the ephemeral keys swapped
+		swapped := v
+		swapped.SelfEphHex, swapped.PeerEphHex = v.PeerEphHex, v.SelfEphHex
+		receiver, err := paramsSession(swapped)
+		if err != nil {
+			return err
+		}
+		if receiver.GetID() != v.SessionID {
+			return fmt.Errorf("session ID mismatch: got %s", receiver.GetID())
+		}
+		plaintext, err = receiver.DecryptWithAAD(ciphertext, aad)
+		if err != nil {
+			return err
+		}
+		if err := receiver.VerifyCovered(unhex(v.CoveredHex), unhex(v.MACHex)); err != nil {
+			return fmt.Errorf("mac mismatch: %w", err)
+		}
+	}
+
+	if got := hex.EncodeToString(plaintext); got != v.PlaintextHex {
+		return fmt.Errorf("plaintext mismatch: got %s", got)
+	}
+	return nil
+}
+
+// unhex decodes a hex field; malformed input yields nil, which the
+// checks that consume it then reject
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil
+	}
+	return b
+}
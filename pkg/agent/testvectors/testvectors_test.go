@@ -0,0 +1,96 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package testvectors
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden vector files")
+
+// goldenDir holds the committed vectors that the bindings test against
+var goldenDir = "vectors"
+
+func TestGoldenVectors(t *testing.T) {
+	generated, err := Generate()
+	require.NoError(t, err)
+	require.NoError(t, Verify(generated))
+
+	if *update {
+		require.NoError(t, Write(goldenDir, generated))
+	}
+
+	golden, err := Load(goldenDir)
+	require.NoError(t, err, "run with -update to create the golden files")
+	require.NoError(t, Verify(golden))
+	assert.Equal(t, Stable(generated), Stable(golden), "golden vectors are stale; run with -update")
+
+	assert.Len(t, golden.Signatures.Vectors, 9)
+	assert.Len(t, golden.HPKE.Vectors, 6)
+	assert.Len(t, golden.Session.Vectors, 6)
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	set, err := Generate()
+	require.NoError(t, err)
+
+	flip := func(h string) string {
+		if h[0] == '0' {
+			return "1" + h[1:]
+		}
+		return "0" + h[1:]
+	}
+
+	t.Run("signature", func(t *testing.T) {
+		tampered := Stable(set)
+		tampered.HPKE.Vectors = nil
+		tampered.Session.Vectors = nil
+		tampered.Signatures.Vectors[1].SignatureHex = flip(tampered.Signatures.Vectors[1].SignatureHex)
+		assert.Error(t, Verify(tampered))
+	})
+
+	t.Run("hpke exporter", func(t *testing.T) {
+		tampered := &Set{HPKE: HPKEVectors{Vectors: append([]HPKEVector(nil), set.HPKE.Vectors...)}}
+		tampered.HPKE.Vectors[0].ExporterHex = flip(tampered.HPKE.Vectors[0].ExporterHex)
+		assert.Error(t, Verify(tampered))
+	})
+
+	t.Run("session mac", func(t *testing.T) {
+		tampered := &Set{Session: SessionVectors{Vectors: append([]SessionVector(nil), set.Session.Vectors...)}}
+		tampered.Session.Vectors[0].MACHex = flip(tampered.Session.Vectors[0].MACHex)
+		assert.Error(t, Verify(tampered))
+	})
+
+	t.Run("session exporter", func(t *testing.T) {
+		var directional SessionVector
+		for _, v := range set.Session.Vectors {
+			if v.Directional {
+				directional = v
+				break
+			}
+		}
+		// A different exporter derives different keys
+		directional.ExporterHex = flip(directional.ExporterHex)
+		assert.Error(t, Verify(&Set{Session: SessionVectors{Vectors: []SessionVector{directional}}}))
+	})
+}
@@ -27,6 +27,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/sage-x-project/sage/pkg/agent/session"
 	"github.com/sage-x-project/sage/pkg/agent/transport"
 )
 
@@ -45,6 +46,7 @@ import (
 type HTTPTransport struct {
 	baseURL    string       // Base URL of the remote agent (e.g., https://agent.example.com)
 	httpClient *http.Client // HTTP client for making requests
+	retry      RetryPolicy  // Retries for idempotent sends (see WithRetry)
 }
 
 // NewHTTPTransport creates a new HTTP transport client.
@@ -72,10 +74,17 @@ func NewHTTPTransportWithClient(baseURL string, httpClient *http.Client) *HTTPTr
 	}
 }
 
+// Endpoint implements transport.Endpointer.
+func (t *HTTPTransport) Endpoint() string {
+	return t.baseURL
+}
+
 // Send implements the MessageTransport interface.
 //
 // Sends the SecureMessage via HTTP POST to {baseURL}/messages and
-// returns the Response from the server.
+// returns the Response from the server. A non-200 status is reported as a
+// *StatusError in Response.Error. With WithRetry, transient failures of
+// messages that set IdempotencyKey are retried; see RetryPolicy.
 func (t *HTTPTransport) Send(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
 	if msg == nil {
 		return nil, fmt.Errorf("message cannot be nil")
@@ -90,11 +99,34 @@ func (t *HTTPTransport) Send(ctx context.Context, msg *transport.SecureMessage)
 		return nil, fmt.Errorf("failed to marshal message: %w", err)
 	}
 
+	attempts := t.retry.attempts()
+	if msg.IdempotencyKey == "" {
+		// Only the sender knows a message is safe to deliver twice
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, transient, err := t.sendOnce(ctx, msg, jsonData)
+		if attempt >= attempts || !transient || ctx.Err() != nil {
+			return resp, err
+		}
+
+		select {
+		case <-time.After(t.retry.backoff(attempt)):
+		case <-ctx.Done():
+			return resp, fmt.Errorf("retry aborted: %w", ctx.Err())
+		}
+	}
+}
+
+// sendOnce performs a single POST of the marshaled message. transient
+// reports whether the failure may clear up on a retry.
+func (t *HTTPTransport) sendOnce(ctx context.Context, msg *transport.SecureMessage, jsonData []byte) (resp *transport.Response, transient bool, err error) {
 	// Create HTTP request
 	url := t.baseURL + "/messages"
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
@@ -107,6 +139,12 @@ func (t *HTTPTransport) Send(ctx context.Context, msg *transport.SecureMessage)
 	if msg.TaskID != "" {
 		req.Header.Set("X-SAGE-Task-ID", msg.TaskID)
 	}
+	if msg.IdempotencyKey != "" {
+		req.Header.Set(session.IdempotencyKeyHeader, msg.IdempotencyKey)
+	}
+	if requestID := transport.OutgoingRequestID(ctx, msg); requestID != "" {
+		req.Header.Set(transport.RequestIDHeader, requestID)
+	}
 
 	// Add custom metadata as headers
 	for key, value := range msg.Metadata {
@@ -114,42 +152,43 @@ func (t *HTTPTransport) Send(ctx context.Context, msg *transport.SecureMessage)
 	}
 
 	// Send request
-	resp, err := t.httpClient.Do(req)
+	httpResp, err := t.httpClient.Do(req)
 	if err != nil {
 		return &transport.Response{
 			Success:   false,
 			MessageID: msg.ID,
 			TaskID:    msg.TaskID,
 			Error:     fmt.Errorf("HTTP request failed: %w", err),
-		}, err
+		}, true, err
 	}
 	defer func() {
-		if err := resp.Body.Close(); err != nil {
+		if err := httpResp.Body.Close(); err != nil {
 			// Log error but don't fail the request since body was already read
 			fmt.Printf("Warning: failed to close response body: %v\n", err)
 		}
 	}()
 
 	// Read response body
-	respBody, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(httpResp.Body)
 	if err != nil {
 		return &transport.Response{
 			Success:   false,
 			MessageID: msg.ID,
 			TaskID:    msg.TaskID,
 			Error:     fmt.Errorf("failed to read response: %w", err),
-		}, err
+		}, true, err
 	}
 
 	// Check HTTP status
-	if resp.StatusCode != http.StatusOK {
+	if httpResp.StatusCode != http.StatusOK {
+		statusErr := &StatusError{StatusCode: httpResp.StatusCode, Body: respBody}
 		return &transport.Response{
 			Success:   false,
 			MessageID: msg.ID,
 			TaskID:    msg.TaskID,
 			Data:      respBody,
-			Error:     fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody)),
-		}, nil
+			Error:     statusErr,
+		}, statusErr.Retryable(), nil
 	}
 
 	// Parse response
@@ -161,11 +200,11 @@ func (t *HTTPTransport) Send(ctx context.Context, msg *transport.SecureMessage)
 			TaskID:    msg.TaskID,
 			Data:      respBody,
 			Error:     fmt.Errorf("failed to parse response: %w", err),
-		}, err
+		}, false, err
 	}
 
 	// Convert to transport.Response
-	return fromWireResponse(&wireResp, msg.ID, msg.TaskID), nil
+	return fromWireResponse(&wireResp, msg.ID, msg.TaskID), false, nil
 }
 
 // wireMessage is the JSON representation of SecureMessage for HTTP transport
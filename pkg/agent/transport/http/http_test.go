@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/sage-x-project/sage/pkg/agent/transport"
@@ -270,3 +271,49 @@ func TestHTTPServer_Validation(t *testing.T) {
 		}
 	})
 }
+
+func TestHTTPTransport_RequestID(t *testing.T) {
+	var seen []string
+	handler := func(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
+		if got := msg.Metadata[transport.RequestIDMetadataKey]; got != transport.RequestIDFromContext(ctx) {
+			t.Errorf("metadata request ID %q differs from context %q", got, transport.RequestIDFromContext(ctx))
+		}
+		seen = append(seen, transport.RequestIDFromContext(ctx))
+		return &transport.Response{Success: true, MessageID: msg.ID}, nil
+	}
+	testServer := httptest.NewServer(NewHTTPServer(handler).MessagesHandler())
+	defer testServer.Close()
+	client := NewHTTPTransport(testServer.URL)
+
+	msg := &transport.SecureMessage{ID: "msg-1", DID: "did:sage:ethereum:0x123", Payload: []byte("payload")}
+	ctx := transport.WithRequestID(context.Background(), "req-123")
+	if _, err := client.Send(ctx, msg); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if _, err := client.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if len(seen) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(seen))
+	}
+	if seen[0] != "req-123" {
+		t.Errorf("expected client request ID, got %q", seen[0])
+	}
+	if seen[1] == "" || seen[1] == "req-123" {
+		t.Errorf("expected a server-generated request ID, got %q", seen[1])
+	}
+
+	// The ID is echoed on the response
+	req, _ := http.NewRequest(http.MethodPost, testServer.URL+"/messages",
+		strings.NewReader(`{"id":"msg-2","did":"did:sage:ethereum:0x123","payload":"cGF5bG9hZA=="}`))
+	req.Header.Set(transport.RequestIDHeader, "req-456")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if got := resp.Header.Get(transport.RequestIDHeader); got != "req-456" {
+		t.Errorf("expected echoed request ID, got %q", got)
+	}
+}
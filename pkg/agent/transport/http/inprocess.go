@@ -0,0 +1,47 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+)
+
+// inProcessBaseURL is the URL the in-process client uses; the host is never
+// resolved because every connection goes to the in-process listener.
+const inProcessBaseURL = "http://in-process"
+
+// NewInProcessTransport serves handler over an in-process connection and
+// returns a client transport connected to it, without binding any socket.
+// Call the returned close function to stop the server.
+//
+// Example usage:
+//
+//	client, stop := http.NewInProcessTransport(hpkeServer.HandleMessage)
+//	defer stop()
+//	cli := hpke.NewClient(client, resolver, key, did, nil, sessMgr)
+func NewInProcessTransport(handler MessageHandler) (*HTTPTransport, func() error) {
+	p := transport.NewInProcess()
+	srv := &http.Server{Handler: NewHTTPServer(handler), ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = srv.Serve(p.Listener()) }()
+
+	return NewHTTPTransportWithClient(inProcessBaseURL, p.HTTPClient()), srv.Close
+}
@@ -0,0 +1,102 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy bounds how Send retries a message after a transient failure:
+// a network error or an HTTP 429, 502, 503 or 504. Application errors in the
+// response and other statuses are never retried. Only messages that set
+// SecureMessage.IdempotencyKey are retried, and every attempt carries the key
+// in session.IdempotencyKeyHeader.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry; it doubles on each
+	// further retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps a single wait. Zero means no cap.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy returns a policy of 3 attempts with 100ms backoff,
+// doubling up to 2s.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+	}
+}
+
+// attempts returns the effective number of attempts
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// backoff returns the wait after the given (1-based) failed attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// WithRetry enables retries of sends that opt in with
+// SecureMessage.IdempotencyKey, where a transient failure would otherwise
+// abort the exchange. It returns t for chaining.
+func (t *HTTPTransport) WithRetry(policy RetryPolicy) *HTTPTransport {
+	t.retry = policy
+	return t
+}
+
+// StatusError reports a non-200 response from the peer. It is set as
+// Response.Error so callers can branch on the status with errors.As.
+type StatusError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, string(e.Body))
+}
+
+// Retryable reports whether the status indicates a transient condition
+func (e *StatusError) Retryable() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sage-x-project/sage/pkg/agent/session"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+)
+
+// flakyPeer fails the first failures requests with status, then serves
+// messages normally, recording every Idempotency-Key it sees.
+type flakyPeer struct {
+	mu       sync.Mutex
+	failures int
+	status   int
+	keys     []string
+	next     http.Handler
+}
+
+func (p *flakyPeer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.keys = append(p.keys, r.Header.Get(session.IdempotencyKeyHeader))
+	fail := len(p.keys) <= p.failures
+	p.mu.Unlock()
+
+	if fail {
+		http.Error(w, "try again", p.status)
+		return
+	}
+	p.next.ServeHTTP(w, r)
+}
+
+func (p *flakyPeer) attempts() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.keys...)
+}
+
+func newFlakyPeer(t *testing.T, failures, status int) (*flakyPeer, *httptest.Server) {
+	handler := func(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
+		return &transport.Response{Success: true, MessageID: msg.ID, Data: []byte("ack")}, nil
+	}
+	peer := &flakyPeer{failures: failures, status: status, next: NewHTTPServer(handler).MessagesHandler()}
+	server := httptest.NewServer(peer)
+	t.Cleanup(server.Close)
+	return peer, server
+}
+
+func testMessage(idempotencyKey string) *transport.SecureMessage {
+	return &transport.SecureMessage{
+		ID:             "msg-" + idempotencyKey,
+		DID:            "did:sage:ethereum:0x123",
+		Payload:        []byte("server ephemeral"),
+		IdempotencyKey: idempotencyKey,
+	}
+}
+
+func TestHTTPTransport_Retry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+	t.Run("Succeeds on the second attempt", func(t *testing.T) {
+		peer, server := newFlakyPeer(t, 1, http.StatusServiceUnavailable)
+		client := NewHTTPTransport(server.URL).WithRetry(policy)
+
+		resp, err := client.Send(context.Background(), testMessage("push-1"))
+		if err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+		if !resp.Success || string(resp.Data) != "ack" {
+			t.Fatalf("Expected successful ack, got %+v", resp)
+		}
+
+		keys := peer.attempts()
+		if len(keys) != 2 {
+			t.Fatalf("Expected 2 attempts, got %d", len(keys))
+		}
+		for _, key := range keys {
+			if key != "push-1" {
+				t.Errorf("Expected Idempotency-Key 'push-1', got '%s'", key)
+			}
+		}
+	})
+
+	t.Run("Stops after MaxAttempts with a typed error", func(t *testing.T) {
+		peer, server := newFlakyPeer(t, 10, http.StatusBadGateway)
+		client := NewHTTPTransport(server.URL).WithRetry(policy)
+
+		resp, err := client.Send(context.Background(), testMessage("push-2"))
+		if err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+		var statusErr *StatusError
+		if !errors.As(resp.Error, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+			t.Fatalf("Expected StatusError 502, got %v", resp.Error)
+		}
+		if n := len(peer.attempts()); n != 3 {
+			t.Errorf("Expected 3 attempts, got %d", n)
+		}
+	})
+
+	t.Run("Does not retry permanent failures", func(t *testing.T) {
+		peer, server := newFlakyPeer(t, 1, http.StatusBadRequest)
+		client := NewHTTPTransport(server.URL).WithRetry(policy)
+
+		resp, _ := client.Send(context.Background(), testMessage("push-3"))
+		var statusErr *StatusError
+		if !errors.As(resp.Error, &statusErr) || statusErr.Retryable() {
+			t.Fatalf("Expected non-retryable StatusError, got %v", resp.Error)
+		}
+		if n := len(peer.attempts()); n != 1 {
+			t.Errorf("Expected 1 attempt, got %d", n)
+		}
+	})
+
+	t.Run("Does not retry messages without an idempotency key", func(t *testing.T) {
+		peer, server := newFlakyPeer(t, 1, http.StatusServiceUnavailable)
+		client := NewHTTPTransport(server.URL).WithRetry(policy)
+
+		_, _ = client.Send(context.Background(), testMessage(""))
+		keys := peer.attempts()
+		if len(keys) != 1 || keys[0] != "" {
+			t.Errorf("Expected one attempt without Idempotency-Key, got %q", keys)
+		}
+	})
+
+	t.Run("Retries network errors", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		url := server.URL
+		server.Close()
+
+		client := NewHTTPTransport(url).WithRetry(policy)
+		start := time.Now()
+		_, err := client.Send(context.Background(), testMessage("push-4"))
+		if err == nil {
+			t.Fatal("Expected network error")
+		}
+		// Two backoffs of 1ms and 2ms were taken
+		if time.Since(start) < 3*time.Millisecond {
+			t.Errorf("Expected retries with backoff, returned after %v", time.Since(start))
+		}
+	})
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		peer, server := newFlakyPeer(t, 1, http.StatusServiceUnavailable)
+		client := NewHTTPTransport(server.URL)
+
+		resp, _ := client.Send(context.Background(), testMessage("push-5"))
+		if resp.Success {
+			t.Fatal("Expected failure without retries")
+		}
+		// The key is still sent so the receiver can deduplicate
+		if keys := peer.attempts(); len(keys) != 1 || keys[0] != "push-5" {
+			t.Errorf("Expected one attempt with Idempotency-Key 'push-5', got %q", keys)
+		}
+	})
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	for attempt, want := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 300 * time.Millisecond,
+		9: 300 * time.Millisecond,
+	} {
+		if got := p.backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
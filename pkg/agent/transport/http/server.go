@@ -25,6 +25,7 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/sage-x-project/sage/pkg/agent/session"
 	"github.com/sage-x-project/sage/pkg/agent/transport"
 )
 
@@ -105,6 +106,10 @@ func (s *HTTPServer) MessagesHandler() http.Handler {
 		// Convert to SecureMessage
 		secureMsg := fromWireMessage(&wireMsg, r.Header)
 
+		// Adopt the caller's request ID, or assign one, and echo it back
+		ctx, requestID := transport.EnsureRequestID(r.Context(), secureMsg)
+		w.Header().Set(transport.RequestIDHeader, requestID)
+
 		// Validate required fields
 		if secureMsg.ID == "" {
 			s.sendErrorResponse(w, "", "", fmt.Errorf("message ID is required"))
@@ -120,8 +125,15 @@ func (s *HTTPServer) MessagesHandler() http.Handler {
 		}
 
 		// Call application handler
-		resp, err := s.handler(r.Context(), secureMsg)
+		resp, err := s.handler(ctx, secureMsg)
 		if err != nil {
+			if resp != nil {
+				// Keep the body of a failure response, e.g. a structured
+				// handshake error, alongside the error message
+				resp.Error = err
+				s.sendSuccessResponse(w, resp)
+				return
+			}
 			s.sendErrorResponse(w, secureMsg.ID, secureMsg.TaskID, err)
 			return
 		}
@@ -162,6 +174,10 @@ func fromWireMessage(wire *wireMessage, headers http.Header) *transport.SecureMe
 	if taskID := headers.Get("X-SAGE-Task-ID"); taskID != "" {
 		msg.TaskID = taskID
 	}
+	msg.IdempotencyKey = headers.Get(session.IdempotencyKeyHeader)
+	if requestID := headers.Get(transport.RequestIDHeader); requestID != "" && msg.Metadata[transport.RequestIDMetadataKey] == "" {
+		msg.Metadata[transport.RequestIDMetadataKey] = requestID
+	}
 
 	// Extract custom metadata from X-SAGE-Meta- headers
 	for key := range headers {
@@ -0,0 +1,128 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrInProcessClosed is returned when dialing or accepting on a closed
+// in-process connection pair.
+var ErrInProcessClosed = errors.New("transport: in-process listener closed")
+
+// InProcess connects a client and a server inside one process without
+// opening sockets, in the spirit of gRPC's bufconn.
+//
+// The server end is a net.Listener that any server (net/http, gRPC, ...) can
+// serve on; the client end dials connections that arrive on that listener.
+// It is intended for tests, where binding real TCP ports causes conflicts.
+//
+// Example usage:
+//
+//	p := transport.NewInProcess()
+//	defer p.Close()
+//
+//	srv := &http.Server{Handler: sagehttp.NewHTTPServer(handler)}
+//	go srv.Serve(p.Listener())
+//
+//	client := sagehttp.NewHTTPTransportWithClient("http://in-process", p.HTTPClient())
+type InProcess struct {
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewInProcess creates an in-process client/server connection pair.
+func NewInProcess() *InProcess {
+	return &InProcess{
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+// Listener returns the server end.
+func (p *InProcess) Listener() net.Listener {
+	return (*inProcessListener)(p)
+}
+
+// DialContext returns the client end of a new connection. The network and
+// address are ignored; they exist so DialContext fits http.Transport.
+func (p *InProcess) DialContext(ctx context.Context, _, _ string) (net.Conn, error) {
+	client, server := net.Pipe()
+	select {
+	case p.conns <- server:
+		return client, nil
+	case <-p.done:
+		_ = client.Close()
+		_ = server.Close()
+		return nil, ErrInProcessClosed
+	case <-ctx.Done():
+		_ = client.Close()
+		_ = server.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// HTTPClient returns an HTTP client whose connections all go to the
+// server end, whatever host the request URL names.
+func (p *InProcess) HTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			DialContext: p.DialContext,
+		},
+	}
+}
+
+// Close stops the listener; pending and future dials fail.
+func (p *InProcess) Close() error {
+	p.closeOnce.Do(func() { close(p.done) })
+	return nil
+}
+
+// inProcessListener is the net.Listener view of an InProcess.
+type inProcessListener InProcess
+
+func (l *inProcessListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.done:
+		return nil, ErrInProcessClosed
+	}
+}
+
+func (l *inProcessListener) Close() error {
+	return (*InProcess)(l).Close()
+}
+
+func (l *inProcessListener) Addr() net.Addr {
+	return inProcessAddr{}
+}
+
+// inProcessAddr is the address of an in-process listener.
+type inProcessAddr struct{}
+
+func (inProcessAddr) Network() string { return "inprocess" }
+func (inProcessAddr) String() string  { return "inprocess" }
@@ -0,0 +1,90 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestInProcess_DialAccept(t *testing.T) {
+	p := NewInProcess()
+	defer p.Close()
+
+	lis := p.Listener()
+	if lis.Addr().Network() != "inprocess" {
+		t.Fatalf("unexpected network %q", lis.Addr().Network())
+	}
+
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn) // echo
+	}()
+
+	conn, err := p.DialContext(context.Background(), "tcp", "ignored:0")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(buf) != "ping" {
+		t.Fatalf("expected echo, got %q", buf)
+	}
+}
+
+func TestInProcess_Close(t *testing.T) {
+	p := NewInProcess()
+	if err := p.Listener().Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	_ = p.Close() // idempotent
+
+	if _, err := p.Listener().Accept(); !errors.Is(err, ErrInProcessClosed) {
+		t.Fatalf("accept after close: %v", err)
+	}
+	if _, err := p.DialContext(context.Background(), "", ""); !errors.Is(err, ErrInProcessClosed) {
+		t.Fatalf("dial after close: %v", err)
+	}
+}
+
+func TestInProcess_DialHonoursContext(t *testing.T) {
+	p := NewInProcess()
+	defer p.Close()
+
+	// Nobody accepts, so the dial must give up with the context
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := p.DialContext(ctx, "", ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
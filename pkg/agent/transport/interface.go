@@ -53,6 +53,14 @@ type MessageTransport interface {
 	Send(ctx context.Context, msg *SecureMessage) (*Response, error)
 }
 
+// Endpointer is implemented by transports bound to one remote endpoint. It
+// lets callers check the peer they are talking to against the endpoint the
+// peer's DID advertises.
+type Endpointer interface {
+	// Endpoint returns the URL the transport connects to.
+	Endpoint() string
+}
+
 // SecureMessage represents a secure message prepared by SAGE.
 //
 // This message contains all security-related information (encryption,
@@ -76,6 +84,13 @@ type SecureMessage struct {
 
 	// Message role
 	Role string // "user" or "agent"
+
+	// IdempotencyKey marks the message as safe to deliver more than once.
+	// Transports only retry messages that set it, and send it so the receiver
+	// can process a retry once (see session.Manager.CheckReplay). Leave it
+	// empty for messages the receiver must see exactly once, such as
+	// handshake inits whose nonce a second delivery would replay.
+	IdempotencyKey string
 }
 
 // Response represents the transport layer response.
@@ -0,0 +1,95 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package transport
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID on HTTP requests and responses
+const RequestIDHeader = "X-SAGE-Request-ID"
+
+// RequestIDMetadataKey carries the request ID in SecureMessage.Metadata
+const RequestIDMetadataKey = "request_id"
+
+type requestIDKey struct{}
+
+// NewRequestID returns a fresh random request ID
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// WithRequestID returns ctx carrying id. Clients set it to correlate a
+// request across transports, handshake, HPKE and session layers; transports
+// forward it to the peer.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// OutgoingRequestID returns the request ID to send with msg: the one in its
+// metadata, else the one carried by ctx, else "".
+func OutgoingRequestID(ctx context.Context, msg *SecureMessage) string {
+	if id := msg.Metadata[RequestIDMetadataKey]; id != "" {
+		return id
+	}
+	return RequestIDFromContext(ctx)
+}
+
+// EnsureRequestID is called by servers on receipt of msg. It adopts the
+// request ID from msg's metadata or ctx, generating one if neither has it,
+// and records it in both. It is idempotent, so each layer may call it.
+func EnsureRequestID(ctx context.Context, msg *SecureMessage) (context.Context, string) {
+	id := OutgoingRequestID(ctx, msg)
+	if id == "" {
+		id = NewRequestID()
+	}
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]string)
+	}
+	msg.Metadata[RequestIDMetadataKey] = id
+	if RequestIDFromContext(ctx) != id {
+		ctx = WithRequestID(ctx, id)
+	}
+	return ctx, id
+}
+
+// MetadataWithRequestID returns md with the request ID carried by ctx added
+// under RequestIDMetadataKey, for transports that carry it in metadata. md
+// is copied rather than modified; it is returned as is when ctx has no ID or
+// md already has one.
+func MetadataWithRequestID(ctx context.Context, md map[string]string) map[string]string {
+	id := RequestIDFromContext(ctx)
+	if id == "" || md[RequestIDMetadataKey] != "" {
+		return md
+	}
+	out := make(map[string]string, len(md)+1)
+	for k, v := range md {
+		out[k] = v
+	}
+	out[RequestIDMetadataKey] = id
+	return out
+}
@@ -82,6 +82,11 @@ func NewWSTransportWithTimeouts(url string, dialTimeout, readTimeout, writeTimeo
 	}
 }
 
+// Endpoint implements transport.Endpointer.
+func (t *WSTransport) Endpoint() string {
+	return t.url
+}
+
 // Connect establishes the WebSocket connection.
 func (t *WSTransport) Connect(ctx context.Context) error {
 	t.mu.Lock()
@@ -130,6 +135,7 @@ func (t *WSTransport) Send(ctx context.Context, msg *transport.SecureMessage) (*
 
 	// Convert to wire format
 	wireMsg := toWireMessage(msg)
+	wireMsg.Metadata = transport.MetadataWithRequestID(ctx, wireMsg.Metadata)
 
 	// Create response channel
 	respChan := make(chan *wireResponse, 1)
@@ -219,9 +219,19 @@ func (s *WSServer) handleConnection(ctx context.Context, conn *websocket.Conn) {
 			continue
 		}
 
+		// Adopt the caller's request ID, or assign one
+		msgCtx, _ := transport.EnsureRequestID(ctx, secureMsg)
+
 		// Call application handler
-		resp, err := s.handler(ctx, secureMsg)
+		resp, err := s.handler(msgCtx, secureMsg)
 		if err != nil {
+			if resp != nil {
+				// Keep the body of a failure response, e.g. a structured
+				// handshake error, alongside the error message
+				resp.Error = err
+				s.sendSuccessResponse(conn, resp)
+				continue
+			}
 			s.sendErrorResponse(conn, secureMsg.ID, secureMsg.TaskID, err)
 			continue
 		}
@@ -0,0 +1,36 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+//go:build !sagechaos
+
+package sagechaos
+
+// Enabled reports whether the injection hooks are compiled in.
+const Enabled = false
+
+// Inject panics: failures can only be injected in builds with the
+// sagechaos tag.
+func Inject(point Point, err error) {
+	panic("sagechaos: Inject(" + string(point) + ") requires building with -tags sagechaos")
+}
+
+// Reset does nothing without the sagechaos tag.
+func Reset() {}
+
+// Fail always returns nil without the sagechaos tag.
+func Fail(Point) error { return nil }
@@ -0,0 +1,57 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+//go:build sagechaos
+
+package sagechaos
+
+import "sync"
+
+// Enabled reports whether the injection hooks are compiled in.
+const Enabled = true
+
+var (
+	mu       sync.RWMutex
+	injected = map[Point]error{}
+)
+
+// Inject makes every later Fail(point) return err; a nil err clears the
+// point.
+func Inject(point Point, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if err == nil {
+		delete(injected, point)
+		return
+	}
+	injected[point] = err
+}
+
+// Reset clears every injected failure.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	injected = map[Point]error{}
+}
+
+// Fail returns the error injected at point, or nil.
+func Fail(point Point) error {
+	mu.RLock()
+	defer mu.RUnlock()
+	return injected[point]
+}
@@ -0,0 +1,50 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+//go:build sagechaos
+
+package sagechaos
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInject(t *testing.T) {
+	t.Cleanup(Reset)
+	timeout := errors.New("rpc timeout")
+
+	assert.True(t, Enabled)
+	assert.NoError(t, Fail(DIDResolve))
+
+	Inject(DIDResolve, timeout)
+	assert.ErrorIs(t, Fail(DIDResolve), timeout)
+	assert.ErrorIs(t, Fail(DIDResolve), timeout, "stays injected until cleared")
+	assert.NoError(t, Fail(SessionDecrypt), "other points are unaffected")
+
+	Inject(DIDResolve, nil)
+	assert.NoError(t, Fail(DIDResolve))
+
+	Inject(SessionEncrypt, timeout)
+	Inject(SignatureVerify, timeout)
+	Reset()
+	assert.NoError(t, Fail(SessionEncrypt))
+	assert.NoError(t, Fail(SignatureVerify))
+}
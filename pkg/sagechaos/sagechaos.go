@@ -0,0 +1,54 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+// Package sagechaos injects failures at named points in the resolver,
+// session and signature verification paths, so integration tests can
+// exercise error handling deterministically instead of monkey-patching.
+//
+// The hooks are compiled in only with the sagechaos build tag:
+//
+//	go test -tags sagechaos ./...
+//
+// Without the tag Fail always returns nil and is inlined away, so release
+// builds carry no injection code; Inject panics to flag a test that relies
+// on the tag but was built without it.
+//
+// A test enables a failure, runs the code under test and clears it:
+//
+//	sagechaos.Inject(sagechaos.DIDResolve, context.DeadlineExceeded)
+//	defer sagechaos.Reset()
+//
+// Injected failures are process-wide, so tests using them must not run in
+// parallel with tests that expect the real behavior.
+package sagechaos
+
+// Point names a place where a failure can be injected.
+type Point string
+
+const (
+	// DIDResolve fails DID resolution through did.MultiChainResolver,
+	// standing in for an RPC timeout or outage
+	DIDResolve Point = "did.resolve"
+	// SessionEncrypt fails session.SecureSession encryption
+	SessionEncrypt Point = "session.encrypt"
+	// SessionDecrypt fails session.SecureSession decryption
+	SessionDecrypt Point = "session.decrypt"
+	// SignatureVerify fails RFC 9421 HTTP signature and HPKE handshake
+	// signature verification
+	SignatureVerify Point = "signature.verify"
+)
@@ -0,0 +1,116 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/sage-x-project/sage/pkg/agent/did"
+	"github.com/sage-x-project/sage/pkg/agent/relay"
+	"github.com/sage-x-project/sage/pkg/agent/transport"
+	sagehttp "github.com/sage-x-project/sage/pkg/agent/transport/http"
+	"github.com/stretchr/testify/require"
+)
+
+// relayKEMResolver serves fixed KEM keys by DID.
+type relayKEMResolver map[did.AgentDID]*ecdh.PublicKey
+
+func (r relayKEMResolver) ResolveKEMKey(_ context.Context, agentDID did.AgentDID) (interface{}, error) {
+	key, ok := r[agentDID]
+	if !ok {
+		return nil, fmt.Errorf("no KEM key for %s", agentDID)
+	}
+	return key, nil
+}
+
+// TestRelay_EndToEnd sends a message from agent A to agent B through a relay
+// over HTTP. The relay forwards the envelope but holds no KEM key and never
+// sees the plaintext.
+func TestRelay_EndToEnd(t *testing.T) {
+	const (
+		aDID = "did:sage:ethereum:0xagent-a"
+		bDID = "did:sage:ethereum:0xagent-b"
+	)
+	ctx := context.Background()
+	secret := []byte("quarterly numbers: confidential")
+
+	bKEM, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	// Agent B: only reachable from the relay
+	var mu sync.Mutex
+	var received []string
+	receiver, err := relay.NewReceiver(bDID, bKEM, func(_ context.Context, sender string, plaintext []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, sender+": "+string(plaintext))
+		return nil
+	})
+	require.NoError(t, err)
+	toB, stopB := sagehttp.NewInProcessTransport(receiver.HandleMessage)
+	defer func() { _ = stopB() }()
+
+	// Relay: records everything it forwards
+	var seen []*transport.SecureMessage
+	relayServer := relay.NewServer()
+	relayServer.Register(bDID, toB)
+	toRelay, stopRelay := sagehttp.NewInProcessTransport(func(ctx context.Context, msg *transport.SecureMessage) (*transport.Response, error) {
+		mu.Lock()
+		seen = append(seen, msg)
+		mu.Unlock()
+		return relayServer.HandleMessage(ctx, msg)
+	})
+	defer func() { _ = stopRelay() }()
+
+	// Agent A: talks only to the relay
+	a := relay.NewClient(toRelay, relayKEMResolver{bDID: bKEM.PublicKey()}, aDID)
+	resp, err := a.Send(ctx, bDID, secret)
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+
+	mu.Lock()
+	gotReceived := append([]string(nil), received...)
+	gotSeen := append([]*transport.SecureMessage(nil), seen...)
+	mu.Unlock()
+	require.Equal(t, []string{aDID + ": " + string(secret)}, gotReceived)
+
+	// The relay saw only ciphertext and cannot open it without B's key
+	require.Len(t, gotSeen, 1)
+	require.Equal(t, bDID, gotSeen[0].Metadata[relay.MetadataRecipient])
+	require.False(t, bytes.Contains(gotSeen[0].Payload, secret))
+	relayKEM, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, _, err = relay.Open(gotSeen[0], bDID, relayKEM)
+	require.Error(t, err)
+
+	t.Run("Unknown recipient is reported to the sender", func(t *testing.T) {
+		const cDID = "did:sage:ethereum:0xagent-c"
+		cKEM, err := ecdh.X25519().GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		a := relay.NewClient(toRelay, relayKEMResolver{cDID: cKEM.PublicKey()}, aDID)
+		_, err = a.Send(ctx, cDID, secret)
+		require.ErrorContains(t, err, "unknown recipient")
+	})
+}
@@ -0,0 +1,84 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package random
+
+import "time"
+
+// categoryTally accumulates per-category statistics while results stream in
+type categoryTally map[TestCategory]*categoryEntry
+
+type categoryEntry struct {
+	stats    CategoryStatistics
+	duration time.Duration
+}
+
+func (t categoryTally) add(result TestResult) {
+	category := result.TestCase.Category
+	entry, ok := t[category]
+	if !ok {
+		entry = &categoryEntry{stats: CategoryStatistics{Category: category}}
+		t[category] = entry
+	}
+	entry.stats.TotalTests++
+	switch {
+	case result.Passed:
+		entry.stats.PassedTests++
+	case result.Skipped:
+		entry.stats.SkippedTests++
+	default:
+		entry.stats.FailedTests++
+	}
+	entry.duration += result.Duration
+}
+
+// resultCollector keeps the results a report lists. With a limit it holds at
+// most that many: once full, a failure displaces the oldest kept non-failing
+// result and anything else is only counted, so memory stays flat however
+// many iterations run while defects are still recorded.
+type resultCollector struct {
+	limit   int
+	results []TestResult
+	// kept indexes of non-failing results in results, oldest first
+	passing []int
+	dropped int64
+	tally   categoryTally
+}
+
+func newResultCollector(limit int) *resultCollector {
+	return &resultCollector{limit: limit, tally: make(categoryTally)}
+}
+
+func (c *resultCollector) add(result TestResult) {
+	c.tally.add(result)
+
+	failed := !result.Passed && !result.Skipped
+	switch {
+	case c.limit <= 0 || len(c.results) < c.limit:
+		if !failed {
+			c.passing = append(c.passing, len(c.results))
+		}
+		c.results = append(c.results, result)
+	case failed && len(c.passing) > 0:
+		c.results[c.passing[0]] = result
+		c.passing = c.passing[1:]
+		c.dropped++
+	default:
+		c.dropped++
+	}
+}
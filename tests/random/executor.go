@@ -19,6 +19,7 @@ package random
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 	// Import SAGE packages for actual testing
@@ -28,6 +29,13 @@ import (
 	// "github.com/sage/did"
 )
 
+var (
+	// ErrTestTimeout is returned when a test exceeds the executor timeout
+	ErrTestTimeout = errors.New("test execution timeout")
+	// ErrTestPanic is returned when a test hook panics
+	ErrTestPanic = errors.New("panic in test hook")
+)
+
 // TestResult represents the result of a test execution
 type TestResult struct {
 	TestCase    TestCase
@@ -38,6 +46,12 @@ type TestResult struct {
 	Duration    time.Duration
 	Output      map[string]interface{}
 	ExecutedAt  time.Time
+
+	// MinimizedInput is the smallest input found that still reproduces the
+	// failure, and ShrinkSteps the number of reductions that led to it.
+	// Both are unset when the result passed or shrinking was disabled.
+	MinimizedInput *TestInput
+	ShrinkSteps    int
 }
 
 // TestExecutor executes test cases
@@ -75,6 +89,17 @@ func (e *TestExecutor) Execute(ctx context.Context, testCase TestCase) TestResul
 
 	// Execute test in goroutine
 	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				resultChan <- TestResult{
+					TestCase:    testCase,
+					Passed:      false,
+					Error:       fmt.Errorf("%w: %v", ErrTestPanic, r),
+					ErrorDetail: "Test hook panicked",
+				}
+			}
+		}()
+
 		// Check if we have a specific hook for this category
 		if hook, exists := e.hooks[testCase.Category]; exists {
 			resultChan <- hook(execCtx, testCase)
@@ -96,7 +121,7 @@ func (e *TestExecutor) Execute(ctx context.Context, testCase TestCase) TestResul
 		return TestResult{
 			TestCase:    testCase,
 			Passed:      false,
-			Error:       fmt.Errorf("test execution timeout after %v", e.timeout),
+			Error:       fmt.Errorf("%w after %v", ErrTestTimeout, e.timeout),
 			ErrorDetail: "Test exceeded maximum allowed execution time",
 			Duration:    time.Since(startTime),
 			ExecutedAt:  time.Now(),
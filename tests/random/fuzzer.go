@@ -53,6 +53,28 @@ type FuzzerConfig struct {
 	VerboseMode     bool
 	ReportPath      string
 	StopOnFirstFail bool
+
+	// ReplayDefect, when set, skips random exploration and re-executes only
+	// the test case identified by the ID, making a recorded defect a
+	// deterministic regression check. Categories must match the original run.
+	ReplayDefect *DefectID
+
+	// ShrinkBudget caps the executions spent minimizing each failing case
+	// (DefaultShrinkBudget when zero). DisableShrink records raw inputs.
+	ShrinkBudget  int
+	DisableShrink bool
+
+	// ResultBuffer is how many finished results may wait for the collector;
+	// workers block when it is full. Zero means two per worker.
+	ResultBuffer int
+
+	// MaxResults caps the results kept in the report, failures first, so
+	// long runs do not grow memory with the iteration count. Statistics
+	// still cover every result. Zero keeps all results.
+	MaxResults int
+
+	// ReportSink receives the serialized report. Defaults to FileSink.
+	ReportSink ReportSink `json:"-"`
 }
 
 // TestCategory represents different test categories
@@ -87,36 +109,59 @@ func NewFuzzer(config *FuzzerConfig) *Fuzzer {
 	return &Fuzzer{
 		generator: NewTestCaseGenerator(config.Seed),
 		executor:  NewTestExecutor(config.Timeout),
-		reporter:  NewResultReporter(config.ReportPath),
+		reporter:  NewResultReporterWithSink(config.ReportPath, config.ReportSink),
 		config:    config,
 	}
 }
 
-// Run executes the fuzzing tests
+// RegisterHook overrides the executor hook for a test category
+func (f *Fuzzer) RegisterHook(category TestCategory, hook TestHook) {
+	f.executor.RegisterHook(category, hook)
+}
+
+// Run executes the fuzzing tests. Cancelling ctx stops the run early; the
+// results collected so far are reported with Interrupted set.
 func (f *Fuzzer) Run(ctx context.Context) (*FuzzReport, error) {
+	if f.config.ReplayDefect != nil {
+		return f.replay(ctx, *f.config.ReplayDefect)
+	}
+
 	startTime := time.Now()
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	parallel := f.config.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	resultBuffer := f.config.ResultBuffer
+	if resultBuffer <= 0 {
+		resultBuffer = 2 * parallel
+	}
 
 	// Create worker pool
 	var wg sync.WaitGroup
-	testChan := make(chan TestCase, f.config.Parallel)
-	resultChan := make(chan TestResult, f.config.Iterations)
+	testChan := make(chan TestCase, parallel)
+	resultChan := make(chan TestResult, resultBuffer)
 
 	// Start workers
-	for i := 0; i < f.config.Parallel; i++ {
+	for i := 0; i < parallel; i++ {
 		wg.Add(1)
-		go f.worker(ctx, &wg, testChan, resultChan)
+		go f.worker(runCtx, &wg, testChan, resultChan)
 	}
 
 	// Generate and send test cases
 	go func() {
 		defer close(testChan)
 		for i := 0; i < f.config.Iterations; i++ {
+			if runCtx.Err() != nil {
+				return
+			}
+			testCase := f.generator.Generate(f.config.Categories)
 			select {
-			case <-ctx.Done():
+			case testChan <- testCase:
+			case <-runCtx.Done():
 				return
-			default:
-				testCase := f.generator.Generate(f.config.Categories)
-				testChan <- testCase
 			}
 		}
 	}()
@@ -128,34 +173,86 @@ func (f *Fuzzer) Run(ctx context.Context) (*FuzzReport, error) {
 	}()
 
 	// Process results
-	var results []TestResult
+	collector := newResultCollector(f.config.MaxResults)
 	for result := range resultChan {
-		results = append(results, result)
+		collector.add(result)
 		f.updateStatistics(result)
 
 		if f.config.StopOnFirstFail && !result.Passed {
 			// Cancel context to stop all workers
+			cancel()
 			break
 		}
 	}
+	// Let workers still holding a result exit after an early stop
+	go func() {
+		for range resultChan {
+		}
+	}()
 
 	// Generate report
 	duration := time.Since(startTime)
+	report := &FuzzReport{
+		StartTime:      startTime,
+		EndTime:        time.Now(),
+		Duration:       duration,
+		TotalTests:     f.totalTests.Load(),
+		PassedTests:    f.passedTests.Load(),
+		FailedTests:    f.failedTests.Load(),
+		SkippedTests:   f.skippedTests.Load(),
+		SuccessRate:    f.successRate(),
+		Configuration:  f.config,
+		Results:        collector.results,
+		DroppedResults: collector.dropped,
+		Statistics:     f.buildStatistics(collector.tally),
+		Interrupted:    ctx.Err() != nil,
+	}
+
+	return f.finish(ctx, report)
+}
+
+// replay regenerates and executes the single test case behind a defect
+func (f *Fuzzer) replay(ctx context.Context, id DefectID) (*FuzzReport, error) {
+	if id.Iteration < 1 {
+		return nil, fmt.Errorf("invalid replay iteration: %d", id.Iteration)
+	}
+
+	startTime := time.Now()
+	generator := NewTestCaseGenerator(id.Seed)
+	testCase := generator.GenerateAt(id.Iteration, f.config.Categories)
+	if id.Category != "" && testCase.Category != id.Category {
+		return nil, fmt.Errorf("replay of %s diverged: generated %s case, defect was %s (categories must match the original run)",
+			id, testCase.Category, id.Category)
+	}
+
+	result := f.executor.Execute(ctx, testCase)
+	f.updateStatistics(result)
+	results := []TestResult{result}
+
 	report := &FuzzReport{
 		StartTime:     startTime,
 		EndTime:       time.Now(),
-		Duration:      duration,
+		Duration:      time.Since(startTime),
 		TotalTests:    f.totalTests.Load(),
 		PassedTests:   f.passedTests.Load(),
 		FailedTests:   f.failedTests.Load(),
 		SkippedTests:  f.skippedTests.Load(),
-		SuccessRate:   float64(f.passedTests.Load()) / float64(f.totalTests.Load()) * 100,
+		SuccessRate:   f.successRate(),
 		Configuration: f.config,
 		Results:       results,
 		Statistics:    f.calculateStatistics(results),
+		Replayed:      &id,
+	}
+
+	return f.finish(ctx, report)
+}
+
+// finish minimizes failing inputs and saves the report through the configured reporter
+func (f *Fuzzer) finish(ctx context.Context, report *FuzzReport) (*FuzzReport, error) {
+	if !f.config.DisableShrink && ctx.Err() == nil {
+		f.shrinkFailures(ctx, report.Results)
 	}
 
-	// Save report
 	if err := f.reporter.Save(report); err != nil {
 		return report, fmt.Errorf("failed to save report: %w", err)
 	}
@@ -163,17 +260,46 @@ func (f *Fuzzer) Run(ctx context.Context) (*FuzzReport, error) {
 	return report, nil
 }
 
+// shrinkFailures replaces each failing result's error with the one produced by
+// its minimized input and records that input for the defect report
+func (f *Fuzzer) shrinkFailures(ctx context.Context, results []TestResult) {
+	budget := f.config.ShrinkBudget
+	if budget <= 0 {
+		budget = DefaultShrinkBudget
+	}
+	s := &shrinker{executor: f.executor, budget: budget}
+
+	for i := range results {
+		result := &results[i]
+		if result.Passed || result.Skipped || result.Error == nil {
+			continue
+		}
+
+		minimized, last, steps := s.shrink(ctx, *result)
+		if steps == 0 {
+			continue
+		}
+		result.MinimizedInput = &minimized
+		result.ShrinkSteps = steps
+		result.Error = last.Error
+		result.ErrorDetail = last.ErrorDetail
+	}
+}
+
 // worker processes test cases
 func (f *Fuzzer) worker(ctx context.Context, wg *sync.WaitGroup, testChan <-chan TestCase, resultChan chan<- TestResult) {
 	defer wg.Done()
 
 	for testCase := range testChan {
+		if ctx.Err() != nil {
+			return
+		}
+		result := f.executor.Execute(ctx, testCase)
+		// Block while the collector is behind rather than buffer without bound
 		select {
+		case resultChan <- result:
 		case <-ctx.Done():
 			return
-		default:
-			result := f.executor.Execute(ctx, testCase)
-			resultChan <- result
 		}
 	}
 }
@@ -194,53 +320,44 @@ func (f *Fuzzer) updateStatistics(result TestResult) {
 	f.totalDuration.Add(result.Duration.Nanoseconds())
 }
 
+// successRate returns the percentage of passed tests, zero before any ran
+func (f *Fuzzer) successRate() float64 {
+	total := f.totalTests.Load()
+	if total == 0 {
+		return 0
+	}
+	return float64(f.passedTests.Load()) / float64(total) * 100
+}
+
 // calculateStatistics calculates detailed statistics
 func (f *Fuzzer) calculateStatistics(results []TestResult) Statistics {
-	stats := Statistics{
-		CategoryStats: make(map[TestCategory]*CategoryStatistics),
+	tally := make(categoryTally)
+	for _, result := range results {
+		tally.add(result)
 	}
+	return f.buildStatistics(tally)
+}
 
-	// Group by category
-	categoryResults := make(map[TestCategory][]TestResult)
-	for _, result := range results {
-		categoryResults[result.TestCase.Category] = append(
-			categoryResults[result.TestCase.Category],
-			result,
-		)
+// buildStatistics turns per-category counts into the report statistics
+func (f *Fuzzer) buildStatistics(tally categoryTally) Statistics {
+	stats := Statistics{
+		CategoryStats: make(map[TestCategory]*CategoryStatistics),
 	}
 
 	// Calculate per-category statistics
-	for category, catResults := range categoryResults {
-		catStats := &CategoryStatistics{
-			Category:     category,
-			TotalTests:   len(catResults),
-			PassedTests:  0,
-			FailedTests:  0,
-			SkippedTests: 0,
-		}
-
-		var totalDuration time.Duration
-		for _, result := range catResults {
-			if result.Passed {
-				catStats.PassedTests++
-			} else if result.Skipped {
-				catStats.SkippedTests++
-			} else {
-				catStats.FailedTests++
-			}
-			totalDuration += result.Duration
-		}
-
+	total := 0
+	for category, entry := range tally {
+		catStats := entry.stats
 		catStats.SuccessRate = float64(catStats.PassedTests) / float64(catStats.TotalTests) * 100
-		catStats.AverageDuration = totalDuration / time.Duration(len(catResults))
-
-		stats.CategoryStats[category] = catStats
+		catStats.AverageDuration = entry.duration / time.Duration(catStats.TotalTests)
+		stats.CategoryStats[category] = &catStats
+		total += catStats.TotalTests
 	}
 
 	// Calculate overall statistics
-	if len(results) > 0 {
-		stats.AverageDuration = time.Duration(f.totalDuration.Load()) / time.Duration(len(results))
-		stats.TestsPerSecond = float64(len(results)) / time.Duration(f.totalDuration.Load()).Seconds()
+	if total > 0 {
+		stats.AverageDuration = time.Duration(f.totalDuration.Load()) / time.Duration(total)
+		stats.TestsPerSecond = float64(total) / time.Duration(f.totalDuration.Load()).Seconds()
 	}
 
 	return stats
@@ -0,0 +1,300 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package random
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// badSessionHook fails for a deterministic subset of generated session IDs
+func badSessionHook(_ context.Context, tc TestCase) TestResult {
+	if strings.ContainsAny(tc.Input.SessionID[:1], "ABCDEFGHIJKLMNOP") {
+		return TestResult{
+			TestCase: tc,
+			Error:    fmt.Errorf("validation failed for session %s", tc.Input.SessionID),
+		}
+	}
+	return TestResult{TestCase: tc, Passed: true}
+}
+
+func TestFuzzer_ReplayDefect(t *testing.T) {
+	categories := []TestCategory{CategorySession, CategoryHPKE}
+	newFuzzer := func(replay *DefectID) *Fuzzer {
+		f := NewFuzzer(&FuzzerConfig{
+			Iterations:   40,
+			Parallel:     4,
+			Timeout:      5 * time.Second,
+			Seed:         1393,
+			Categories:   categories,
+			ReportPath:   "replay.json",
+			ReportSink:   newMemorySink(),
+			ReplayDefect: replay,
+		})
+		f.RegisterHook(CategorySession, badSessionHook)
+		return f
+	}
+
+	report, err := newFuzzer(nil).Run(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, report.Defects, "seed should produce at least one defect")
+
+	defect := report.Defects[0]
+	assert.Equal(t, int64(1393), defect.ID.Seed)
+	assert.Equal(t, CategorySession, defect.ID.Category)
+
+	for i := 0; i < 3; i++ {
+		id := defect.ID
+		replayed, err := newFuzzer(&id).Run(context.Background())
+		require.NoError(t, err)
+
+		require.Len(t, replayed.Results, 1)
+		require.Len(t, replayed.Defects, 1)
+		assert.Equal(t, int64(1), replayed.TotalTests)
+		assert.Equal(t, defect.ID, *replayed.Replayed)
+		assert.Equal(t, defect.ID, replayed.Defects[0].ID)
+		assert.Equal(t, defect.Error, replayed.Defects[0].Error)
+		assert.Equal(t, defect.Input, replayed.Defects[0].Input)
+	}
+
+	t.Run("diverging categories are rejected", func(t *testing.T) {
+		id := defect.ID
+		f := NewFuzzer(&FuzzerConfig{
+			Timeout:      time.Second,
+			Categories:   []TestCategory{CategoryHPKE},
+			ReportSink:   newMemorySink(),
+			ReplayDefect: &id,
+		})
+		_, err := f.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "diverged")
+	})
+}
+
+func TestParseDefectID(t *testing.T) {
+	id, err := ParseDefectID("42:7")
+	require.NoError(t, err)
+	assert.Equal(t, DefectID{Seed: 42, Iteration: 7}, id)
+	assert.Equal(t, "42:7", id.String())
+
+	for _, bad := range []string{"", "42", "x:1", "1:0", "1:y"} {
+		_, err := ParseDefectID(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestFuzzer_ShrinksFailingInput(t *testing.T) {
+	f := NewFuzzer(&FuzzerConfig{
+		Iterations: 3,
+		Parallel:   1,
+		Timeout:    5 * time.Second,
+		Seed:       1395,
+		Categories: []TestCategory{CategoryHPKE},
+		ReportSink: newMemorySink(),
+	})
+	// Fails for any plaintext of 8 bytes or more, regardless of content or AAD
+	f.RegisterHook(CategoryHPKE, func(_ context.Context, tc TestCase) TestResult {
+		if len(tc.Input.PlainText) >= 8 {
+			return TestResult{TestCase: tc, Error: fmt.Errorf("plaintext too long: %d", len(tc.Input.PlainText))}
+		}
+		return TestResult{TestCase: tc, Passed: true}
+	})
+
+	report, err := f.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, report.Defects, 3)
+
+	for _, defect := range report.Defects {
+		original, ok := defect.OriginalInput.(TestInput)
+		require.True(t, ok)
+		require.GreaterOrEqual(t, len(original.PlainText), 10)
+		assert.NotEmpty(t, original.AAD)
+
+		assert.Equal(t, TestInput{PlainText: make([]byte, 8)}, defect.Input)
+		assert.Equal(t, "plaintext too long: 8", defect.Error)
+		assert.Positive(t, defect.ShrinkSteps)
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		f := NewFuzzer(&FuzzerConfig{
+			Iterations:    1,
+			Parallel:      1,
+			Timeout:       5 * time.Second,
+			Seed:          1395,
+			Categories:    []TestCategory{CategoryHPKE},
+			ReportSink:    newMemorySink(),
+			DisableShrink: true,
+		})
+		f.RegisterHook(CategoryHPKE, func(_ context.Context, tc TestCase) TestResult {
+			return TestResult{TestCase: tc, Error: fmt.Errorf("always fails")}
+		})
+
+		report, err := f.Run(context.Background())
+		require.NoError(t, err)
+		require.Len(t, report.Defects, 1)
+		assert.Nil(t, report.Defects[0].OriginalInput)
+		assert.Equal(t, report.Results[0].TestCase.Input, report.Defects[0].Input)
+	})
+}
+
+func TestExecutor_RecoversHookPanic(t *testing.T) {
+	e := NewTestExecutor(time.Second)
+	e.RegisterHook(CategorySession, func(context.Context, TestCase) TestResult {
+		panic("boom")
+	})
+
+	result := e.Execute(context.Background(), TestCase{Category: CategorySession})
+	assert.False(t, result.Passed)
+	require.ErrorIs(t, result.Error, ErrTestPanic)
+	assert.Contains(t, result.Error.Error(), "boom")
+}
+
+// requireGoroutinesBelow waits for the goroutine count to drop back to n.
+// It polls inline because assert.Eventually runs its own goroutines.
+func requireGoroutinesBelow(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutines leaked: %d running, want <= %d", runtime.NumGoroutine(), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFuzzer_BoundedResults(t *testing.T) {
+	const iterations = 20000
+	f := NewFuzzer(&FuzzerConfig{
+		Iterations:    iterations,
+		Parallel:      8,
+		Timeout:       time.Second,
+		Seed:          1470,
+		Categories:    []TestCategory{CategorySession},
+		ReportSink:    newMemorySink(),
+		ResultBuffer:  1,
+		MaxResults:    50,
+		DisableShrink: true,
+	})
+	// One failure per 1000 iterations
+	f.RegisterHook(CategorySession, func(_ context.Context, tc TestCase) TestResult {
+		if tc.Iteration%1000 == 0 {
+			return TestResult{TestCase: tc, Error: fmt.Errorf("failure at %d", tc.Iteration)}
+		}
+		return TestResult{TestCase: tc, Passed: true}
+	})
+
+	report, err := f.Run(context.Background())
+	require.NoError(t, err)
+	assert.False(t, report.Interrupted)
+	assert.Equal(t, int64(iterations), report.TotalTests)
+	assert.Equal(t, int64(iterations/1000), report.FailedTests)
+
+	// Memory is bounded by MaxResults, and every failure is kept
+	require.Len(t, report.Results, 50)
+	assert.Equal(t, int64(iterations-50), report.DroppedResults)
+	assert.Len(t, report.Defects, iterations/1000)
+
+	// Statistics cover all results, not only the kept ones
+	stats := report.Statistics.CategoryStats[CategorySession]
+	require.NotNil(t, stats)
+	assert.Equal(t, iterations, stats.TotalTests)
+	assert.Equal(t, iterations/1000, stats.FailedTests)
+}
+
+func TestFuzzer_Cancellation(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var executed atomic.Int64
+	f := NewFuzzer(&FuzzerConfig{
+		Iterations:   1000000,
+		Parallel:     4,
+		Timeout:      time.Second,
+		Seed:         1470,
+		Categories:   []TestCategory{CategorySession},
+		ReportSink:   newMemorySink(),
+		ResultBuffer: 1,
+	})
+	f.RegisterHook(CategorySession, func(_ context.Context, tc TestCase) TestResult {
+		if executed.Add(1) == 100 {
+			cancel()
+		}
+		return TestResult{TestCase: tc, Passed: true}
+	})
+
+	done := make(chan struct{})
+	var report *FuzzReport
+	var err error
+	go func() {
+		defer close(done)
+		report, err = f.Run(ctx)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+
+	require.NoError(t, err)
+	assert.True(t, report.Interrupted)
+	assert.Less(t, report.TotalTests, int64(1000))
+
+	// Workers and the generator exit once the run is cancelled
+	requireGoroutinesBelow(t, before)
+}
+
+func TestFuzzer_StopOnFirstFail(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	f := NewFuzzer(&FuzzerConfig{
+		Iterations:      10000,
+		Parallel:        4,
+		Timeout:         time.Second,
+		Seed:            1470,
+		Categories:      []TestCategory{CategorySession},
+		ReportSink:      newMemorySink(),
+		ResultBuffer:    1,
+		StopOnFirstFail: true,
+		DisableShrink:   true,
+	})
+	f.RegisterHook(CategorySession, func(_ context.Context, tc TestCase) TestResult {
+		if tc.Iteration == 10 {
+			return TestResult{TestCase: tc, Error: fmt.Errorf("stop here")}
+		}
+		return TestResult{TestCase: tc, Passed: true}
+	})
+
+	report, err := f.Run(context.Background())
+	require.NoError(t, err)
+	assert.False(t, report.Interrupted)
+	assert.Equal(t, int64(1), report.FailedTests)
+	assert.Less(t, report.TotalTests, int64(10000))
+
+	// Workers blocked on the result buffer are released
+	requireGoroutinesBelow(t, before)
+}
@@ -18,16 +18,17 @@
 package random
 
 import (
-	"crypto/rand"
 	"encoding/base64"
 	"fmt"
-	"math/big"
+	"math/rand/v2"
 	"time"
 )
 
 // TestCase represents a single test case
 type TestCase struct {
 	ID          string
+	Seed        int64
+	Iteration   int64
 	Category    TestCategory
 	Name        string
 	Description string
@@ -98,10 +99,14 @@ type ValidationRule struct {
 	Value    interface{}
 }
 
-// TestCaseGenerator generates random test cases
+// TestCaseGenerator generates random test cases.
+//
+// Each case is drawn from a PRNG derived from (seed, iteration) alone, so any
+// case can be regenerated exactly without replaying the ones before it.
 type TestCaseGenerator struct {
 	seed    int64
 	counter int64
+	rng     *rand.Rand
 }
 
 // NewTestCaseGenerator creates a new test case generator
@@ -114,12 +119,23 @@ func NewTestCaseGenerator(seed int64) *TestCaseGenerator {
 // Generate creates a random test case for specified categories
 func (g *TestCaseGenerator) Generate(categories []TestCategory) TestCase {
 	g.counter++
+	return g.GenerateAt(g.counter, categories)
+}
+
+// GenerateAt regenerates the test case for the given iteration (1-based).
+// The result is identical to the one Generate produced for that iteration
+// with the same seed and categories.
+func (g *TestCaseGenerator) GenerateAt(iteration int64, categories []TestCategory) TestCase {
+	// #nosec G404 - Deterministic PRNG is required for reproducible test cases
+	g.rng = rand.New(rand.NewPCG(uint64(g.seed), uint64(iteration)))
 
 	// Select random category
 	category := categories[g.randomInt(0, len(categories)-1)]
 
 	testCase := TestCase{
-		ID:        fmt.Sprintf("test-%d-%d", g.seed, g.counter),
+		ID:        fmt.Sprintf("test-%d-%d", g.seed, iteration),
+		Seed:      g.seed,
+		Iteration: iteration,
 		Category:  category,
 		CreatedAt: time.Now(),
 	}
@@ -344,13 +360,14 @@ func (g *TestCaseGenerator) randomInt(min, max int) int {
 	if min >= max {
 		return min
 	}
-	n, _ := rand.Int(rand.Reader, big.NewInt(int64(max-min+1)))
-	return int(n.Int64()) + min
+	return g.rng.IntN(max-min+1) + min
 }
 
 func (g *TestCaseGenerator) randomString(length int) string {
 	bytes := make([]byte, length)
-	_, _ = rand.Read(bytes)
+	for i := range bytes {
+		bytes[i] = byte(g.rng.Uint32())
+	}
 	return base64.RawURLEncoding.EncodeToString(bytes)[:length]
 }
 
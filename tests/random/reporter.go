@@ -18,11 +18,13 @@
 package random
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
-	"os"
+	"io"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -42,7 +44,13 @@ type FuzzReport struct {
 	Results       []TestResult  `json:"results"`
 	Statistics    Statistics    `json:"statistics"`
 	Defects       []Defect      `json:"defects,omitempty"`
+	Replayed      *DefectID     `json:"replayed,omitempty"`
 	Summary       string        `json:"summary"`
+
+	// DroppedResults counts results left out of Results by MaxResults
+	DroppedResults int64 `json:"dropped_results,omitempty"`
+	// Interrupted is set when the run was cancelled before all iterations
+	Interrupted bool `json:"interrupted,omitempty"`
 }
 
 // Statistics contains statistical analysis of test results
@@ -65,21 +73,57 @@ type CategoryStatistics struct {
 	CommonErrors    []string      `json:"common_errors,omitempty"`
 }
 
+// DefectID identifies the generated test case behind a defect. Together with
+// the run's category configuration it is enough to regenerate the exact input
+// via FuzzerConfig.ReplayDefect.
+type DefectID struct {
+	Seed      int64        `json:"seed"`
+	Iteration int64        `json:"iteration"`
+	Category  TestCategory `json:"category,omitempty"`
+}
+
+// String formats the ID as "seed:iteration"
+func (id DefectID) String() string {
+	return fmt.Sprintf("%d:%d", id.Seed, id.Iteration)
+}
+
+// ParseDefectID parses an ID in the "seed:iteration" form produced by String
+func ParseDefectID(s string) (DefectID, error) {
+	seedStr, iterStr, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return DefectID{}, fmt.Errorf("invalid defect id %q: expected seed:iteration", s)
+	}
+	seed, err := strconv.ParseInt(seedStr, 10, 64)
+	if err != nil {
+		return DefectID{}, fmt.Errorf("invalid defect seed %q: %w", seedStr, err)
+	}
+	iteration, err := strconv.ParseInt(iterStr, 10, 64)
+	if err != nil || iteration < 1 {
+		return DefectID{}, fmt.Errorf("invalid defect iteration %q", iterStr)
+	}
+	return DefectID{Seed: seed, Iteration: iteration}, nil
+}
+
 // Defect represents a test defect or failure
 type Defect struct {
+	ID          DefectID     `json:"id"`
 	TestID      string       `json:"test_id"`
 	Category    TestCategory `json:"category"`
 	Error       string       `json:"error"`
 	ErrorDetail string       `json:"error_detail"`
 	Input       interface{}  `json:"input"`
-	Severity    string       `json:"severity"`
-	Timestamp   time.Time    `json:"timestamp"`
+	// OriginalInput holds the generated input when Input was minimized
+	OriginalInput interface{} `json:"original_input,omitempty"`
+	ShrinkSteps   int         `json:"shrink_steps,omitempty"`
+	Severity      string      `json:"severity"`
+	Timestamp     time.Time   `json:"timestamp"`
 }
 
 // ResultReporter handles test result reporting
 type ResultReporter struct {
 	reportPath string
 	format     ReportFormat
+	sink       ReportSink
 }
 
 // ReportFormat defines the output format for reports
@@ -92,8 +136,20 @@ const (
 	FormatText     ReportFormat = "text"
 )
 
-// NewResultReporter creates a new result reporter
+// NewResultReporter creates a new result reporter that writes to the local filesystem
 func NewResultReporter(reportPath string) *ResultReporter {
+	return NewResultReporterWithSink(reportPath, nil)
+}
+
+// NewResultReporterWithSink creates a result reporter that delivers serialized
+// reports to sink. The report format is still derived from reportPath's
+// extension, and reportPath is passed to the sink as the report name.
+// A nil sink falls back to FileSink.
+func NewResultReporterWithSink(reportPath string, sink ReportSink) *ResultReporter {
+	if sink == nil {
+		sink = FileSink{}
+	}
+
 	var format ReportFormat
 
 	// Determine format from file extension
@@ -111,10 +167,11 @@ func NewResultReporter(reportPath string) *ResultReporter {
 	return &ResultReporter{
 		reportPath: reportPath,
 		format:     format,
+		sink:       sink,
 	}
 }
 
-// Save saves the report to file
+// Save renders the report in the configured format and hands it to the sink
 func (r *ResultReporter) Save(report *FuzzReport) error {
 	// Analyze defects
 	report.Defects = r.analyzeDefects(report.Results)
@@ -122,35 +179,31 @@ func (r *ResultReporter) Save(report *FuzzReport) error {
 	// Generate summary
 	report.Summary = r.generateSummary(report)
 
-	// Ensure directory exists
-	dir := filepath.Dir(r.reportPath)
-	if err := os.MkdirAll(dir, 0750); err != nil {
-		return fmt.Errorf("failed to create report directory: %w", err)
-	}
-
+	var buf bytes.Buffer
+	var err error
 	switch r.format {
-	case FormatJSON:
-		return r.saveJSON(report)
 	case FormatHTML:
-		return r.saveHTML(report)
+		err = r.renderHTML(&buf, report)
 	case FormatMarkdown:
-		return r.saveMarkdown(report)
+		err = r.renderMarkdown(&buf, report)
 	case FormatText:
-		return r.saveText(report)
+		err = r.renderText(&buf, report)
 	default:
-		return r.saveJSON(report)
+		err = r.renderJSON(&buf, report)
 	}
-}
-
-// saveJSON saves report as JSON
-func (r *ResultReporter) saveJSON(report *FuzzReport) error {
-	file, err := os.Create(r.reportPath)
 	if err != nil {
-		return fmt.Errorf("failed to create report file: %w", err)
+		return err
+	}
+
+	if err := r.sink.Write(r.reportPath, r.format, buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
 	}
-	defer func() { _ = file.Close() }()
+	return nil
+}
 
-	encoder := json.NewEncoder(file)
+// renderJSON renders report as JSON
+func (r *ResultReporter) renderJSON(w io.Writer, report *FuzzReport) error {
+	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
 
 	if err := encoder.Encode(report); err != nil {
@@ -160,8 +213,8 @@ func (r *ResultReporter) saveJSON(report *FuzzReport) error {
 	return nil
 }
 
-// saveHTML saves report as HTML
-func (r *ResultReporter) saveHTML(report *FuzzReport) error {
+// renderHTML renders report as HTML
+func (r *ResultReporter) renderHTML(w io.Writer, report *FuzzReport) error {
 	htmlTemplate := `<!DOCTYPE html>
 <html>
 <head>
@@ -272,40 +325,27 @@ func (r *ResultReporter) saveHTML(report *FuzzReport) error {
 	if err != nil {
 		return fmt.Errorf("failed to parse HTML template: %w", err)
 	}
-
-	file, err := os.Create(r.reportPath)
-	if err != nil {
-		return fmt.Errorf("failed to create report file: %w", err)
-	}
-	defer func() { _ = file.Close() }()
-
-	return tmpl.Execute(file, report)
+	return tmpl.Execute(w, report)
 }
 
-// saveMarkdown saves report as Markdown
-func (r *ResultReporter) saveMarkdown(report *FuzzReport) error {
-	file, err := os.Create(r.reportPath)
-	if err != nil {
-		return fmt.Errorf("failed to create report file: %w", err)
-	}
-	defer func() { _ = file.Close() }()
-
-	_, _ = fmt.Fprintf(file, "# SAGE Random Test Report\n\n")
-	_, _ = fmt.Fprintf(file, "## Executive Summary\n\n")
-	_, _ = fmt.Fprintf(file, "%s\n\n", report.Summary)
-	_, _ = fmt.Fprintf(file, "- **Duration**: %v\n", report.Duration)
-	_, _ = fmt.Fprintf(file, "- **Total Tests**: %d\n", report.TotalTests)
-	_, _ = fmt.Fprintf(file, "- **Passed**: %d\n", report.PassedTests)
-	_, _ = fmt.Fprintf(file, "- **Failed**: %d\n", report.FailedTests)
-	_, _ = fmt.Fprintf(file, "- **Success Rate**: %.2f%%\n\n", report.SuccessRate)
-
-	_, _ = fmt.Fprintf(file, "## Performance Metrics\n\n")
-	_, _ = fmt.Fprintf(file, "- **Average Duration**: %v\n", report.Statistics.AverageDuration)
-	_, _ = fmt.Fprintf(file, "- **Tests per Second**: %.2f\n\n", report.Statistics.TestsPerSecond)
-
-	_, _ = fmt.Fprintf(file, "## Category Results\n\n")
-	_, _ = fmt.Fprintf(file, "| Category | Total | Passed | Failed | Success Rate | Avg Duration |\n")
-	_, _ = fmt.Fprintf(file, "|----------|-------|--------|--------|--------------|-------------|\n")
+// renderMarkdown renders report as Markdown
+func (r *ResultReporter) renderMarkdown(w io.Writer, report *FuzzReport) error {
+	_, _ = fmt.Fprintf(w, "# SAGE Random Test Report\n\n")
+	_, _ = fmt.Fprintf(w, "## Executive Summary\n\n")
+	_, _ = fmt.Fprintf(w, "%s\n\n", report.Summary)
+	_, _ = fmt.Fprintf(w, "- **Duration**: %v\n", report.Duration)
+	_, _ = fmt.Fprintf(w, "- **Total Tests**: %d\n", report.TotalTests)
+	_, _ = fmt.Fprintf(w, "- **Passed**: %d\n", report.PassedTests)
+	_, _ = fmt.Fprintf(w, "- **Failed**: %d\n", report.FailedTests)
+	_, _ = fmt.Fprintf(w, "- **Success Rate**: %.2f%%\n\n", report.SuccessRate)
+
+	_, _ = fmt.Fprintf(w, "## Performance Metrics\n\n")
+	_, _ = fmt.Fprintf(w, "- **Average Duration**: %v\n", report.Statistics.AverageDuration)
+	_, _ = fmt.Fprintf(w, "- **Tests per Second**: %.2f\n\n", report.Statistics.TestsPerSecond)
+
+	_, _ = fmt.Fprintf(w, "## Category Results\n\n")
+	_, _ = fmt.Fprintf(w, "| Category | Total | Passed | Failed | Success Rate | Avg Duration |\n")
+	_, _ = fmt.Fprintf(w, "|----------|-------|--------|--------|--------------|-------------|\n")
 
 	// Sort categories for consistent output
 	var categories []TestCategory
@@ -318,74 +358,68 @@ func (r *ResultReporter) saveMarkdown(report *FuzzReport) error {
 
 	for _, cat := range categories {
 		stats := report.Statistics.CategoryStats[cat]
-		_, _ = fmt.Fprintf(file, "| %s | %d | %d | %d | %.2f%% | %v |\n",
+		_, _ = fmt.Fprintf(w, "| %s | %d | %d | %d | %.2f%% | %v |\n",
 			cat, stats.TotalTests, stats.PassedTests, stats.FailedTests,
 			stats.SuccessRate, stats.AverageDuration)
 	}
 
 	if len(report.Defects) > 0 {
-		_, _ = fmt.Fprintf(file, "\n## Defects Found\n\n")
-		_, _ = fmt.Fprintf(file, "| Test ID | Category | Error | Severity |\n")
-		_, _ = fmt.Fprintf(file, "|---------|----------|-------|----------|\n")
+		_, _ = fmt.Fprintf(w, "\n## Defects Found\n\n")
+		_, _ = fmt.Fprintf(w, "| Test ID | Category | Error | Severity |\n")
+		_, _ = fmt.Fprintf(w, "|---------|----------|-------|----------|\n")
 		for _, defect := range report.Defects {
-			_, _ = fmt.Fprintf(file, "| %s | %s | %s | %s |\n",
+			_, _ = fmt.Fprintf(w, "| %s | %s | %s | %s |\n",
 				defect.TestID, defect.Category,
 				strings.ReplaceAll(defect.Error, "|", "\\|"),
 				defect.Severity)
 		}
 	}
 
-	_, _ = fmt.Fprintf(file, "\n---\n\n")
-	_, _ = fmt.Fprintf(file, "_Generated: %s_\n", report.EndTime.Format("2006-01-02 15:04:05"))
+	_, _ = fmt.Fprintf(w, "\n---\n\n")
+	_, _ = fmt.Fprintf(w, "_Generated: %s_\n", report.EndTime.Format("2006-01-02 15:04:05"))
 
 	return nil
 }
 
-// saveText saves report as plain text
-func (r *ResultReporter) saveText(report *FuzzReport) error {
-	file, err := os.Create(r.reportPath)
-	if err != nil {
-		return fmt.Errorf("failed to create report file: %w", err)
-	}
-	defer func() { _ = file.Close() }()
-
-	_, _ = fmt.Fprintln(file, "================================================================================")
-	_, _ = fmt.Fprintln(file, "                        SAGE RANDOM TEST REPORT")
-	_, _ = fmt.Fprintln(file, "================================================================================")
-	_, _ = fmt.Fprintln(file)
-	_, _ = fmt.Fprintln(file, report.Summary)
-	_, _ = fmt.Fprintln(file)
-	_, _ = fmt.Fprintf(file, "Test Duration:  %v\n", report.Duration)
-	_, _ = fmt.Fprintf(file, "Total Tests:    %d\n", report.TotalTests)
-	_, _ = fmt.Fprintf(file, "Passed:         %d\n", report.PassedTests)
-	_, _ = fmt.Fprintf(file, "Failed:         %d\n", report.FailedTests)
-	_, _ = fmt.Fprintf(file, "Success Rate:   %.2f%%\n", report.SuccessRate)
-	_, _ = fmt.Fprintln(file)
-	_, _ = fmt.Fprintln(file, "CATEGORY BREAKDOWN:")
-	_, _ = fmt.Fprintln(file, "-------------------")
+// renderText renders report as plain text
+func (r *ResultReporter) renderText(w io.Writer, report *FuzzReport) error {
+	_, _ = fmt.Fprintln(w, "================================================================================")
+	_, _ = fmt.Fprintln(w, "                        SAGE RANDOM TEST REPORT")
+	_, _ = fmt.Fprintln(w, "================================================================================")
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, report.Summary)
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintf(w, "Test Duration:  %v\n", report.Duration)
+	_, _ = fmt.Fprintf(w, "Total Tests:    %d\n", report.TotalTests)
+	_, _ = fmt.Fprintf(w, "Passed:         %d\n", report.PassedTests)
+	_, _ = fmt.Fprintf(w, "Failed:         %d\n", report.FailedTests)
+	_, _ = fmt.Fprintf(w, "Success Rate:   %.2f%%\n", report.SuccessRate)
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "CATEGORY BREAKDOWN:")
+	_, _ = fmt.Fprintln(w, "-------------------")
 
 	for cat, stats := range report.Statistics.CategoryStats {
-		_, _ = fmt.Fprintf(file, "\n%s:\n", cat)
-		_, _ = fmt.Fprintf(file, "  Total:        %d\n", stats.TotalTests)
-		_, _ = fmt.Fprintf(file, "  Passed:       %d\n", stats.PassedTests)
-		_, _ = fmt.Fprintf(file, "  Failed:       %d\n", stats.FailedTests)
-		_, _ = fmt.Fprintf(file, "  Success Rate: %.2f%%\n", stats.SuccessRate)
+		_, _ = fmt.Fprintf(w, "\n%s:\n", cat)
+		_, _ = fmt.Fprintf(w, "  Total:        %d\n", stats.TotalTests)
+		_, _ = fmt.Fprintf(w, "  Passed:       %d\n", stats.PassedTests)
+		_, _ = fmt.Fprintf(w, "  Failed:       %d\n", stats.FailedTests)
+		_, _ = fmt.Fprintf(w, "  Success Rate: %.2f%%\n", stats.SuccessRate)
 	}
 
 	if len(report.Defects) > 0 {
-		_, _ = fmt.Fprintln(file)
-		_, _ = fmt.Fprintln(file, "DEFECTS FOUND:")
-		_, _ = fmt.Fprintln(file, "--------------")
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "DEFECTS FOUND:")
+		_, _ = fmt.Fprintln(w, "--------------")
 		for i, defect := range report.Defects {
-			_, _ = fmt.Fprintf(file, "\n%d. %s (Category: %s, Severity: %s)\n",
+			_, _ = fmt.Fprintf(w, "\n%d. %s (Category: %s, Severity: %s)\n",
 				i+1, defect.TestID, defect.Category, defect.Severity)
-			_, _ = fmt.Fprintf(file, "   Error: %s\n", defect.Error)
+			_, _ = fmt.Fprintf(w, "   Error: %s\n", defect.Error)
 		}
 	}
 
-	_, _ = fmt.Fprintln(file)
-	_, _ = fmt.Fprintln(file, "================================================================================")
-	_, _ = fmt.Fprintf(file, "Generated: %s\n", report.EndTime.Format("2006-01-02 15:04:05"))
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "================================================================================")
+	_, _ = fmt.Fprintf(w, "Generated: %s\n", report.EndTime.Format("2006-01-02 15:04:05"))
 
 	return nil
 }
@@ -408,7 +442,12 @@ func (r *ResultReporter) analyzeDefects(results []TestResult) []Defect {
 				severity = "MEDIUM"
 			}
 
-			defects = append(defects, Defect{
+			defect := Defect{
+				ID: DefectID{
+					Seed:      result.TestCase.Seed,
+					Iteration: result.TestCase.Iteration,
+					Category:  result.TestCase.Category,
+				},
 				TestID:      result.TestCase.ID,
 				Category:    result.TestCase.Category,
 				Error:       errorStr,
@@ -416,7 +455,13 @@ func (r *ResultReporter) analyzeDefects(results []TestResult) []Defect {
 				Input:       result.TestCase.Input,
 				Severity:    severity,
 				Timestamp:   result.ExecutedAt,
-			})
+			}
+			if result.MinimizedInput != nil {
+				defect.Input = *result.MinimizedInput
+				defect.OriginalInput = result.TestCase.Input
+				defect.ShrinkSteps = result.ShrinkSteps
+			}
+			defects = append(defects, defect)
 		}
 	}
 
@@ -0,0 +1,124 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package random
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySink captures serialized reports in memory
+type memorySink struct {
+	mu      sync.Mutex
+	reports map[string][]byte
+	formats map[string]ReportFormat
+}
+
+func newMemorySink() *memorySink {
+	return &memorySink{
+		reports: make(map[string][]byte),
+		formats: make(map[string]ReportFormat),
+	}
+}
+
+func (s *memorySink) Write(name string, format ReportFormat, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reports[name] = append([]byte(nil), data...)
+	s.formats[name] = format
+	return nil
+}
+
+func TestResultReporter_Sink(t *testing.T) {
+	t.Run("fuzzer delivers JSON report to sink", func(t *testing.T) {
+		sink := newMemorySink()
+		fuzzer := NewFuzzer(&FuzzerConfig{
+			Iterations: 5,
+			Parallel:   1,
+			Timeout:    5 * time.Second,
+			Seed:       42,
+			Categories: []TestCategory{CategorySession},
+			ReportPath: "reports/run.json",
+			ReportSink: sink,
+		})
+
+		report, err := fuzzer.Run(context.Background())
+		require.NoError(t, err)
+
+		data, ok := sink.reports["reports/run.json"]
+		require.True(t, ok, "sink did not receive report")
+		assert.Equal(t, FormatJSON, sink.formats["reports/run.json"])
+
+		var decoded FuzzReport
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, report.TotalTests, decoded.TotalTests)
+		assert.Equal(t, report.Summary, decoded.Summary)
+		assert.Equal(t, int64(42), decoded.Configuration.Seed)
+	})
+
+	t.Run("format follows report name", func(t *testing.T) {
+		sink := newMemorySink()
+		reporter := NewResultReporterWithSink("out/report.md", sink)
+
+		require.NoError(t, reporter.Save(&FuzzReport{TotalTests: 1, PassedTests: 1, SuccessRate: 100}))
+		assert.Equal(t, FormatMarkdown, sink.formats["out/report.md"])
+		assert.Contains(t, string(sink.reports["out/report.md"]), "# SAGE Random Test Report")
+	})
+
+	t.Run("HTTP sink uploads report", func(t *testing.T) {
+		var gotBody []byte
+		var gotType, gotName string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPut, r.Method)
+			gotType = r.Header.Get("Content-Type")
+			gotName = r.Header.Get("X-Report-Name")
+			gotBody, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer server.Close()
+
+		reporter := NewResultReporterWithSink("reports/run.json", HTTPSink{URL: server.URL})
+		require.NoError(t, reporter.Save(&FuzzReport{TotalTests: 2, PassedTests: 2, SuccessRate: 100}))
+
+		assert.Equal(t, "application/json", gotType)
+		assert.Equal(t, "run.json", gotName)
+		assert.Contains(t, string(gotBody), `"total_tests": 2`)
+	})
+
+	t.Run("HTTP sink reports upload failure", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		reporter := NewResultReporterWithSink("run.json", HTTPSink{URL: server.URL})
+		err := reporter.Save(&FuzzReport{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "403")
+	})
+}
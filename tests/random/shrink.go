@@ -0,0 +1,245 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package random
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"sort"
+)
+
+// DefaultShrinkBudget is the maximum number of executions spent minimizing a
+// single failing test case when FuzzerConfig.ShrinkBudget is unset.
+const DefaultShrinkBudget = 500
+
+// shrinker reduces failing inputs toward a minimal reproducer.
+//
+// It performs greedy delta debugging over every field of TestInput: strings,
+// byte slices and slices lose chunks of decreasing size, maps lose keys,
+// integers move toward zero, and finally the remaining bytes are replaced by a
+// canonical value ('a' for strings, 0 for bytes). A candidate is kept only if
+// it fails in the same way as the original (plain error, panic or timeout).
+type shrinker struct {
+	executor *TestExecutor
+	budget   int
+}
+
+// shrink returns the minimized input, the failing result it produced and the
+// number of accepted reductions.
+func (s *shrinker) shrink(ctx context.Context, original TestResult) (TestInput, TestResult, int) {
+	kind := failureKind(original)
+	current := original.TestCase
+	last := original
+	steps, runs := 0, 0
+
+	for runs < s.budget && ctx.Err() == nil {
+		improved := false
+		for candidate := range inputCandidates(current.Input) {
+			if runs >= s.budget || ctx.Err() != nil {
+				break
+			}
+			runs++
+
+			trial := current
+			trial.Input = candidate
+			result := s.executor.Execute(ctx, trial)
+			if result.Passed || result.Skipped || failureKind(result) != kind {
+				continue
+			}
+
+			current, last = trial, result
+			steps++
+			improved = true
+			break
+		}
+		if !improved {
+			break
+		}
+	}
+
+	return current.Input, last, steps
+}
+
+// failureKind classifies a failure so shrinking does not wander to a different bug
+func failureKind(result TestResult) string {
+	switch {
+	case errors.Is(result.Error, ErrTestTimeout):
+		return "timeout"
+	case errors.Is(result.Error, ErrTestPanic):
+		return "panic"
+	default:
+		return "error"
+	}
+}
+
+// inputCandidates yields simpler variants of input, one field change at a time
+func inputCandidates(input TestInput) iter.Seq[TestInput] {
+	return func(yield func(TestInput) bool) {
+		for c := range valueCandidates(reflect.ValueOf(input)) {
+			if !yield(c.Interface().(TestInput)) {
+				return
+			}
+		}
+	}
+}
+
+// valueCandidates yields simpler variants of v, ordered from most to least aggressive
+func valueCandidates(v reflect.Value) iter.Seq[reflect.Value] {
+	return func(yield func(reflect.Value) bool) {
+		switch v.Kind() {
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				if !v.Type().Field(i).IsExported() {
+					continue
+				}
+				for c := range valueCandidates(v.Field(i)) {
+					cp := reflect.New(v.Type()).Elem()
+					cp.Set(v)
+					cp.Field(i).Set(c)
+					if !yield(cp) {
+						return
+					}
+				}
+			}
+
+		case reflect.String:
+			for c := range sequenceCandidates([]byte(v.String()), 'a') {
+				if !yield(reflect.ValueOf(string(c)).Convert(v.Type())) {
+					return
+				}
+			}
+
+		case reflect.Slice:
+			if v.Type().Elem().Kind() == reflect.Uint8 {
+				for c := range sequenceCandidates(v.Bytes(), 0) {
+					if !yield(reflect.ValueOf(c).Convert(v.Type())) {
+						return
+					}
+				}
+				return
+			}
+			for _, c := range sliceRemovals(v) {
+				if !yield(c) {
+					return
+				}
+			}
+
+		case reflect.Map:
+			if v.Len() == 0 {
+				return
+			}
+			if !yield(reflect.Zero(v.Type())) {
+				return
+			}
+			keys := v.MapKeys()
+			sort.Slice(keys, func(i, j int) bool {
+				return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+			})
+			for _, skip := range keys {
+				cp := reflect.MakeMapWithSize(v.Type(), v.Len()-1)
+				for _, k := range keys {
+					if k.Interface() != skip.Interface() {
+						cp.SetMapIndex(k, v.MapIndex(k))
+					}
+				}
+				if !yield(cp) {
+					return
+				}
+			}
+
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if n := v.Int(); n != 0 {
+				for _, c := range []int64{0, n / 2} {
+					if c == n {
+						continue
+					}
+					cp := reflect.New(v.Type()).Elem()
+					cp.SetInt(c)
+					if !yield(cp) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// sequenceCandidates yields shorter and then more canonical versions of b.
+// An empty candidate is always nil so minimized inputs compare equal to zero values.
+func sequenceCandidates(b []byte, canonical byte) iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		n := len(b)
+		if n == 0 {
+			return
+		}
+		if !yield(nil) {
+			return
+		}
+
+		// Remove chunks of decreasing size
+		for chunk := n / 2; chunk >= 1; chunk /= 2 {
+			for start := 0; start < n; start += chunk {
+				end := min(start+chunk, n)
+				c := make([]byte, 0, n-(end-start))
+				c = append(c, b[:start]...)
+				c = append(c, b[end:]...)
+				if len(c) == 0 {
+					c = nil
+				}
+				if !yield(c) {
+					return
+				}
+			}
+		}
+
+		// Canonicalize remaining elements one at a time
+		for i := 0; i < n; i++ {
+			if b[i] == canonical {
+				continue
+			}
+			c := append([]byte(nil), b...)
+			c[i] = canonical
+			if !yield(c) {
+				return
+			}
+		}
+	}
+}
+
+// sliceRemovals returns copies of a non-byte slice with one element removed
+func sliceRemovals(v reflect.Value) []reflect.Value {
+	n := v.Len()
+	if n == 0 {
+		return nil
+	}
+	out := []reflect.Value{reflect.Zero(v.Type())}
+	for skip := 0; skip < n; skip++ {
+		cp := reflect.MakeSlice(v.Type(), 0, n-1)
+		for i := 0; i < n; i++ {
+			if i != skip {
+				cp = reflect.Append(cp, v.Index(i))
+			}
+		}
+		out = append(out, cp)
+	}
+	return out
+}
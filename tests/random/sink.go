@@ -0,0 +1,122 @@
+// SAGE - Secure Agent Guarantee Engine
+// Copyright (C) 2025 SAGE-X-project
+//
+// This file is part of SAGE.
+//
+// SAGE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// SAGE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with SAGE. If not, see <https://www.gnu.org/licenses/>.
+
+package random
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ReportSink receives serialized fuzz reports.
+//
+// Implementations may persist reports locally (FileSink) or forward them to
+// object storage or a CI dashboard so results can be aggregated across runs.
+// name is the report path configured on the ResultReporter; remote sinks are
+// free to use it as an object key.
+type ReportSink interface {
+	Write(name string, format ReportFormat, data []byte) error
+}
+
+// FileSink writes reports to the local filesystem. It is the default sink.
+type FileSink struct {
+	// Dir, when set, is joined with the report name.
+	Dir string
+}
+
+// Write writes data to the report path, creating parent directories as needed
+func (s FileSink) Write(name string, _ ReportFormat, data []byte) error {
+	path := name
+	if s.Dir != "" {
+		path = filepath.Join(s.Dir, name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	return nil
+}
+
+// HTTPSink uploads reports to an HTTP endpoint with a PUT request.
+// Pre-signed S3/GCS URLs and most CI artifact APIs accept this form.
+type HTTPSink struct {
+	URL     string
+	Headers map[string]string
+	Client  *http.Client
+	Timeout time.Duration
+}
+
+// Write uploads data to the configured URL
+func (s HTTPSink) Write(name string, format ReportFormat, data []byte) error {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.URL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentTypeFor(format))
+	req.Header.Set("X-Report-Name", filepath.Base(name))
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload report: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("report upload failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// contentTypeFor returns the MIME type for a report format
+func contentTypeFor(format ReportFormat) string {
+	switch format {
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	case FormatText:
+		return "text/plain; charset=utf-8"
+	default:
+		return "application/json"
+	}
+}